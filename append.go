@@ -0,0 +1,173 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// appendStagingTable is the temporary table AppendFile stages the rows of a file in
+const appendStagingTable = metadataTablePrefix + "append"
+
+// AppendFile loads the file at path into table, an existing table of db, with
+// INSERT OR REPLACE semantics on keyColumns.
+//
+// Daily or hourly exports often hold only the rows that were added or changed since
+// the previous file. AppendFile merges such a file into a table without a manual
+// staging table: every row of the table whose key matches a row of the file is
+// replaced by the row of the file, and the other rows of the file are inserted. When
+// the file holds several rows with the same key, the last one wins. Without key
+// columns, the rows of the file are appended.
+//
+// Columns are matched by name, ignoring case. Columns of the file that the table does
+// not have are added to the table, and columns of the table that the file does not
+// have are NULL in the merged rows. The merge runs in one transaction, so the table
+// is either fully merged or left unchanged.
+//
+// Example:
+//
+//	db, err := filesql.Open("orders.csv")
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+//
+//	// Orders that changed today replace their previous version; new orders are added
+//	if err := filesql.AppendFile(ctx, db, "orders-2024-06-02.csv", "orders", "order_id"); err != nil {
+//		return err
+//	}
+//
+// AppendFile returns an error with ErrCodeInvalidConfig if the table does not exist
+// or a key column is missing from the table or the file.
+func AppendFile(ctx context.Context, db *sql.DB, path, table string, keyColumns ...string) error {
+	targetColumns, err := tableColumnTypes(ctx, db, table)
+	if err != nil {
+		return err
+	}
+	if len(targetColumns) == 0 {
+		return newCodedError(ErrCodeInvalidConfig, "cannot append %s: table %s does not exist", path, table)
+	}
+
+	source, sourceTable, err := openFileTable(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	sourceColumns, err := tableColumnTypes(ctx, source, sourceTable)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, len(keyColumns))
+	for i, key := range keyColumns {
+		column, ok := findDeclaredColumn(sourceColumns, key)
+		if !ok {
+			return newCodedError(ErrCodeInvalidConfig, "cannot append %s: key column %s is not in the file", path, key)
+		}
+		if _, ok := findDeclaredColumn(targetColumns, key); !ok {
+			return newCodedError(ErrCodeInvalidConfig, "cannot append %s: key column %s is not in table %s", path, key, table)
+		}
+		keys[i] = quoteIdentifier(column.name)
+	}
+
+	if err := mergeTable(ctx, db, source, sourceTable, sourceColumns, table, targetColumns, keys); err != nil {
+		return withErrorCode(fmt.Errorf("failed to append %s to table %s: %w", path, table, err), ErrCodeLoadFailed)
+	}
+	return nil
+}
+
+// mergeTable stages the rows of table source of sourceDB and merges them into table
+// target of db, replacing the rows with the same quoted keys
+func mergeTable(ctx context.Context, db, sourceDB *sql.DB, source string, sourceColumns []declaredColumn,
+	target string, targetColumns []declaredColumn, keys []string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after Commit is a no-op
+
+	definitions := make([]string, len(sourceColumns))
+	names := make([]string, len(sourceColumns))
+	for i, column := range sourceColumns {
+		definitions[i] = column.definition()
+		names[i] = quoteIdentifier(column.name)
+		if _, ok := findDeclaredColumn(targetColumns, column.name); ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", //nolint:gosec // Identifiers are quoted
+			quoteIdentifier(target), column.definition())); err != nil {
+			return err
+		}
+	}
+
+	staging := "temp." + quoteIdentifier(appendStagingTable)
+	if _, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS "+staging); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (%s)", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(appendStagingTable), strings.Join(definitions, ", "))); err != nil {
+		return err
+	}
+	if err := insertTableRows(ctx, sourceDB, source, tx, staging, len(sourceColumns)); err != nil {
+		return err
+	}
+
+	selectRows := fmt.Sprintf("SELECT %s FROM %s", strings.Join(names, ", "), staging)
+	if len(keys) > 0 {
+		keyList := strings.Join(keys, ", ")
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE (%s) IN (SELECT %s FROM %s)", //nolint:gosec // Identifiers are quoted
+			quoteIdentifier(target), keyList, keyList, staging)); err != nil {
+			return err
+		}
+		selectRows += fmt.Sprintf(" WHERE rowid IN (SELECT MAX(rowid) FROM %s GROUP BY %s)", staging, keyList)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) %s", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(target), strings.Join(names, ", "), selectRows)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DROP TABLE "+staging); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// declaredColumn is a column of a table and its declared type
+type declaredColumn struct {
+	name     string
+	declType string
+}
+
+// definition returns the column definition of c for CREATE TABLE
+func (c declaredColumn) definition() string {
+	return strings.TrimSpace(quoteIdentifier(c.name) + " " + c.declType)
+}
+
+// tableColumnTypes returns the columns of table in order; none if the table does not exist
+func tableColumnTypes(ctx context.Context, db *sql.DB, table string) ([]declaredColumn, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, type FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns for table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []declaredColumn
+	for rows.Next() {
+		var column declaredColumn
+		if err := rows.Scan(&column.name, &column.declType); err != nil {
+			return nil, fmt.Errorf("failed to get columns for table %s: %w", table, err)
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// findDeclaredColumn returns the column of columns named name, ignoring case
+func findDeclaredColumn(columns []declaredColumn, name string) (declaredColumn, bool) {
+	for _, column := range columns {
+		if strings.EqualFold(column.name, name) {
+			return column, true
+		}
+	}
+	return declaredColumn{}, false
+}
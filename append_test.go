@@ -0,0 +1,104 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendFile(t *testing.T) {
+	t.Parallel()
+
+	// setup opens a database with orders.csv holding base, and writes the other files
+	setup := func(t *testing.T, base string, files map[string]string) (string, *sql.DB) {
+		t.Helper()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv"), []byte(base), 0600))
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+		}
+		return dir, openTestDB(t, NewBuilder().AddPath(filepath.Join(dir, "orders.csv")))
+	}
+	tests := []struct {
+		name  string
+		base  string
+		file  string
+		data  string
+		keys  []string
+		query string
+		want  []string
+	}{
+		{
+			name:  "rows with the same key are replaced and new rows are inserted",
+			base:  "order_id,status,amount\n1,new,100\n2,new,200\n",
+			file:  "orders-2024-06-02.csv",
+			data:  "order_id,status,amount\n2,shipped,200\n3,new,300\n3,paid,300\n",
+			keys:  []string{"ORDER_ID"},
+			query: "SELECT order_id, status, amount FROM orders ORDER BY order_id",
+			want:  []string{"1:new:100", "2:shipped:200", "3:paid:300"},
+		},
+		{
+			name:  "composite keys",
+			base:  "region,day,sales\neu,1,10\nus,1,20\n",
+			file:  "update.tsv",
+			data:  "region\tday\tsales\neu\t1\t15\neu\t2\t30\n",
+			keys:  []string{"region", "day"},
+			query: "SELECT region, day, sales FROM orders ORDER BY region, day",
+			want:  []string{"eu:1:15", "eu:2:30", "us:1:20"},
+		},
+		{
+			name:  "without keys rows are appended",
+			base:  "id,name\n1,alice\n",
+			file:  "more.csv",
+			data:  "id,name\n1,alice\n2,bob\n",
+			query: "SELECT name FROM orders ORDER BY id, rowid",
+			want:  []string{"alice", "alice", "bob"},
+		},
+		{
+			name:  "columns are matched by name and new columns are added",
+			base:  "id,name,note\n1,alice,vip\n",
+			file:  "more.jsonl",
+			data:  `{"name": "bob", "id": 2, "email": "bob@example.com"}` + "\n",
+			keys:  []string{"id"},
+			query: "SELECT id, name, note, email FROM orders ORDER BY id",
+			want:  []string{"1:alice:vip:NULL", "2:bob:NULL:bob@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, db := setup(t, tt.base, map[string]string{tt.file: tt.data})
+			require.NoError(t, AppendFile(context.Background(), db, filepath.Join(dir, tt.file), "orders", tt.keys...))
+			assert.Equal(t, tt.want, queryRows(t, db, tt.query))
+		})
+	}
+
+	t.Run("invalid arguments are rejected and leave the table unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		dir, db := setup(t, "id,name\n1,alice\n", map[string]string{"more.csv": "id,name\n1,bob\n"})
+		more := filepath.Join(dir, "more.csv")
+		for _, tt := range []struct {
+			path  string
+			table string
+			keys  []string
+			code  ErrorCode
+		}{
+			{path: more, table: "missing", keys: []string{"id"}, code: ErrCodeInvalidConfig},
+			{path: more, table: "orders", keys: []string{"email"}, code: ErrCodeInvalidConfig},
+			{path: filepath.Join(dir, "missing.csv"), table: "orders", keys: []string{"id"}, code: ErrCodePathNotFound},
+		} {
+			err := AppendFile(context.Background(), db, tt.path, tt.table, tt.keys...)
+			assert.Equal(t, tt.code, ErrorCodeOf(err), tt.path)
+		}
+		assert.Equal(t, []string{"alice"}, queryRows(t, db, "SELECT name FROM orders"))
+	})
+}
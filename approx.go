@@ -0,0 +1,351 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// Approximate aggregate functions.
+//
+// Exact aggregates over tables with hundreds of millions of rows take minutes, which
+// is too slow for exploring data. Every database opened by filesql provides this SQL
+// function, which trades a small, stated error for speed:
+//
+//   - APPROX_COUNT_DISTINCT(x) estimates COUNT(DISTINCT x) with a HyperLogLog sketch
+//     of fixed size, with a relative standard error of about 0.8%, instead of keeping
+//     every distinct value in memory.
+//
+// Like COUNT(DISTINCT x), NULL values are ignored:
+//
+//	SELECT APPROX_COUNT_DISTINCT(customer_id) FROM orders
+//
+// SUM and AVG need little memory, but an aggregate function is called for every row,
+// so approximate sums and averages cannot be SQL functions. ApproxAggregates computes
+// them from a sample of rows instead.
+const (
+	// hllPrecision is the number of hash bits that select a HyperLogLog register
+	hllPrecision = 14
+	// hllRegisters is the number of HyperLogLog registers
+	hllRegisters = 1 << hllPrecision
+)
+
+// approxCountDistinct is the name of the APPROX_COUNT_DISTINCT function
+const approxCountDistinct = "approx_count_distinct"
+
+func init() {
+	// modernc.org/sqlite only adds Go functions to connections through the functions
+	// registered on its "sqlite" driver, which memoryConnector opens connections with.
+	// The registry is not safe for use while connections are opened, so the function
+	// is registered once here rather than when a database is opened.
+	sqlite.MustRegisterFunction(approxCountDistinct, &sqlite.FunctionImpl{
+		NArgs: 1,
+		MakeAggregate: func(sqlite.FunctionContext) (sqlite.AggregateFunction, error) {
+			return &hyperLogLog{}, nil
+		},
+	})
+}
+
+// errNotWindowFunction is returned when an approximate aggregate is used as a window function
+func errNotWindowFunction(name string) error {
+	return fmt.Errorf("%s cannot be used as a window function", strings.ToUpper(name))
+}
+
+// hyperLogLog implements APPROX_COUNT_DISTINCT
+type hyperLogLog struct {
+	registers [hllRegisters]uint8
+}
+
+// Step implements sqlite.AggregateFunction
+func (h *hyperLogLog) Step(_ *sqlite.FunctionContext, args []driver.Value) error {
+	if args[0] == nil {
+		return nil
+	}
+	hash := hashValue(args[0])
+	index := hash >> (64 - hllPrecision)
+	// The rank is the position of the first 1 bit of the remaining bits
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1) //nolint:gosec // At most 64 - hllPrecision + 1
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+	return nil
+}
+
+// WindowInverse implements sqlite.AggregateFunction
+func (h *hyperLogLog) WindowInverse(*sqlite.FunctionContext, []driver.Value) error {
+	return errNotWindowFunction(approxCountDistinct)
+}
+
+// WindowValue implements sqlite.AggregateFunction
+func (h *hyperLogLog) WindowValue(*sqlite.FunctionContext) (driver.Value, error) {
+	return h.estimate(), nil
+}
+
+// Final implements sqlite.AggregateFunction
+func (h *hyperLogLog) Final(*sqlite.FunctionContext) {}
+
+// estimate returns the estimated number of distinct values
+func (h *hyperLogLog) estimate() int64 {
+	const m = float64(hllRegisters)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	sum := 0.0
+	zeros := 0
+	for _, register := range h.registers {
+		sum += math.Ldexp(1, -int(register))
+		if register == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha * m * m / sum
+	// Linear counting is more accurate for small cardinalities
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(estimate))
+}
+
+// hashValue returns a well-mixed 64-bit hash of a value. Values that SQLite considers
+// equal, such as the integer 1 and the real 1.0, have the same hash.
+func hashValue(value driver.Value) uint64 {
+	hasher := fnv.New64a()
+	var buf [9]byte
+	switch v := value.(type) {
+	case int64:
+		buf[0] = 'n'
+		binary.LittleEndian.PutUint64(buf[1:], uint64(v)) //nolint:gosec // Reinterpreting the bits
+		_, _ = hasher.Write(buf[:])
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<63 {
+			return hashValue(int64(v))
+		}
+		buf[0] = 'f'
+		binary.LittleEndian.PutUint64(buf[1:], math.Float64bits(v))
+		_, _ = hasher.Write(buf[:])
+	case string:
+		_, _ = hasher.Write([]byte{'s'})
+		_, _ = hasher.Write([]byte(v))
+	case []byte:
+		_, _ = hasher.Write([]byte{'b'})
+		_, _ = hasher.Write(v)
+	default:
+		_, _ = fmt.Fprintf(hasher, "o%v", v)
+	}
+	// FNV does not spread similar inputs over the high bits, so mix them
+	// (the splitmix64 finalizer)
+	hash := hasher.Sum64()
+	hash ^= hash >> 30
+	hash *= 0xbf58476d1ce4e5b9
+	hash ^= hash >> 27
+	hash *= 0x94d049bb133111eb
+	hash ^= hash >> 31
+	return hash
+}
+
+// approxSampleSeed seeds the rowids ApproxAggregates draws, so that results are repeatable
+const approxSampleSeed = 0x66696c6573716c
+
+// approxZ95 is the z-score of a two-sided 95% confidence interval
+const approxZ95 = 1.959964
+
+// approxBatchSize is the number of rowids looked up by one query of ApproxAggregates
+const approxBatchSize = 500
+
+// ApproxResult is an estimate of aggregates of a column computed by ApproxAggregates.
+// Each error is the half-width of the 95% confidence interval of its estimate: the
+// exact value lies within the estimate plus or minus the error for 95% of samples.
+type ApproxResult struct {
+	// Count estimates COUNT(column), the number of values that are not NULL
+	Count float64
+	// CountError is the error of Count
+	CountError float64
+	// Sum estimates SUM(column)
+	Sum float64
+	// SumError is the error of Sum
+	SumError float64
+	// Avg estimates AVG(column); it is 0 when no sampled value is set
+	Avg float64
+	// AvgError is the error of Avg
+	AvgError float64
+	// SampledValues is the number of sampled values that are not NULL
+	SampledValues int
+	// Exact reports whether every row was read because the table is not larger than
+	// the sample, in which case the estimates are exact and the errors are 0
+	Exact bool
+}
+
+// ApproxAggregates estimates COUNT, SUM, and AVG of a column from a random sample of
+// about sampleSize rows of a table.
+//
+// SQL aggregates read every row, even APPROX_COUNT_DISTINCT. ApproxAggregates draws
+// sampleSize rowids at random and looks each one up by its key, so it reads only the
+// sampled rows and takes about the same time for a table of a thousand rows and one of
+// a hundred million. Rowids that no row has (after deletes) count as empty draws, which
+// keeps every row equally likely to be sampled. The error shrinks with the square root
+// of sampleSize: 10000 rows give an AVG within about 2% of the standard deviation of
+// the column.
+//
+// Values are converted to numbers like SUM does, and NULL values are ignored. The
+// rowids are drawn with a fixed seed, so the same table gives the same estimates. A
+// table with at most sampleSize rowids is aggregated exactly.
+//
+// Example:
+//
+//	result, err := filesql.ApproxAggregates(ctx, db, "orders", "amount", 10000)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("average %.2f ± %.2f\n", result.Avg, result.AvgError)
+//
+// Returns an error with ErrCodeNotFound if the table or the column does not exist,
+// and ErrCodeInvalidConfig if sampleSize is less than 2.
+func ApproxAggregates(ctx context.Context, db *sql.DB, table, column string, sampleSize int) (*ApproxResult, error) {
+	if sampleSize < 2 {
+		return nil, newCodedError(ErrCodeInvalidConfig, "sample size must be at least 2: %d", sampleSize)
+	}
+	columns, err := tableColumnTypes(ctx, db, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, newCodedError(ErrCodeNotFound, "table %s does not exist", table)
+	}
+	if !slices.ContainsFunc(columns, func(c declaredColumn) bool { return strings.EqualFold(c.name, column) }) {
+		return nil, newCodedError(ErrCodeNotFound, "column %s does not exist in table %s", column, table)
+	}
+
+	var first, last sql.NullInt64
+	if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT MIN(rowid), MAX(rowid) FROM %s", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(table))).Scan(&first, &last); err != nil {
+		return nil, fmt.Errorf("failed to get the rowids of table %s: %w", table, err)
+	}
+	span := last.Int64 - first.Int64 + 1
+	if !first.Valid || span <= int64(sampleSize) {
+		return exactAggregates(ctx, db, table, column)
+	}
+
+	random := rand.New(rand.NewPCG(approxSampleSeed, uint64(span))) //nolint:gosec // Sampling, not security
+	draws := make([]int64, sampleSize)
+	for i := range draws {
+		draws[i] = first.Int64 + random.Int64N(span)
+	}
+	values, err := sampleValues(ctx, db, table, column, draws)
+	if err != nil {
+		return nil, err
+	}
+	return estimateAggregates(draws, values, float64(span)), nil
+}
+
+// exactAggregates aggregates every row of a table for ApproxAggregates
+func exactAggregates(ctx context.Context, db *sql.DB, table, column string) (*ApproxResult, error) {
+	var count int
+	var sum float64
+	query := fmt.Sprintf("SELECT COUNT(%s), TOTAL(%s) FROM %s", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(column), quoteIdentifier(column), quoteIdentifier(table))
+	if err := db.QueryRowContext(ctx, query).Scan(&count, &sum); err != nil {
+		return nil, fmt.Errorf("failed to aggregate column %s of table %s: %w", column, table, err)
+	}
+	result := &ApproxResult{Count: float64(count), Sum: sum, SampledValues: count, Exact: true}
+	if count > 0 {
+		result.Avg = sum / float64(count)
+	}
+	return result, nil
+}
+
+// sampleValues returns the numeric values of a column in the rows with the drawn
+// rowids. Rowids without a row or with a NULL value are missing from the map.
+func sampleValues(ctx context.Context, db *sql.DB, table, column string, draws []int64) (map[int64]float64, error) {
+	rowids := slices.Compact(slices.Sorted(slices.Values(draws)))
+	values := make(map[int64]float64, len(rowids))
+	for batch := range slices.Chunk(rowids, approxBatchSize) {
+		args := make([]any, len(batch))
+		for i, rowid := range batch {
+			args[i] = rowid
+		}
+		query := fmt.Sprintf("SELECT rowid, CAST(%s AS REAL) FROM %s WHERE rowid IN (%s) AND %s IS NOT NULL", //nolint:gosec // Identifiers are quoted
+			quoteIdentifier(column), quoteIdentifier(table), strings.TrimSuffix(strings.Repeat("?,", len(batch)), ","), quoteIdentifier(column))
+		if err := readSampleValues(ctx, db, query, args, values); err != nil {
+			return nil, fmt.Errorf("failed to sample column %s of table %s: %w", column, table, err)
+		}
+	}
+	return values, nil
+}
+
+// readSampleValues adds the rowids and values returned by query to values
+func readSampleValues(ctx context.Context, db *sql.DB, query string, args []any, values map[int64]float64) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowid int64
+		var value float64
+		if err := rows.Scan(&rowid, &value); err != nil {
+			return err
+		}
+		values[rowid] = value
+	}
+	return rows.Err()
+}
+
+// estimateAggregates estimates the aggregates of a table with span rowids from the
+// values of the drawn rowids. Each draw contributes its value and 1 to the sum and
+// count, or 0 to both when it has no value, so the estimates scale the means of the
+// draws by span (the Hansen-Hurwitz estimator for sampling with replacement). The
+// error of the average, a ratio of two estimates, uses the delta method.
+func estimateAggregates(draws []int64, values map[int64]float64, span float64) *ApproxResult {
+	n := float64(len(draws))
+	var sum, hits float64
+	for _, rowid := range draws {
+		if value, ok := values[rowid]; ok {
+			sum += value
+			hits++
+		}
+	}
+	meanValue, meanHit := sum/n, hits/n
+
+	var avg float64
+	if hits > 0 {
+		avg = sum / hits
+	}
+	var valueSquares, hitSquares, residualSquares float64
+	for _, rowid := range draws {
+		value, ok := values[rowid]
+		hit := 0.0
+		if ok {
+			hit = 1
+		}
+		valueSquares += (value - meanValue) * (value - meanValue)
+		hitSquares += (hit - meanHit) * (hit - meanHit)
+		residualSquares += (value - avg*hit) * (value - avg*hit)
+	}
+	// standardError returns the standard error of a mean of the draws from their sum of squares
+	standardError := func(squares float64) float64 {
+		return math.Sqrt(squares / (n - 1) / n)
+	}
+
+	result := &ApproxResult{
+		Count:         span * meanHit,
+		CountError:    approxZ95 * span * standardError(hitSquares),
+		Sum:           span * meanValue,
+		SumError:      approxZ95 * span * standardError(valueSquares),
+		Avg:           avg,
+		SampledValues: int(hits),
+	}
+	if hits > 0 {
+		result.AvgError = approxZ95 * standardError(residualSquares) / meanHit
+	}
+	return result
+}
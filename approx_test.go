@@ -0,0 +1,125 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApproximateAggregates(t *testing.T) {
+	t.Parallel()
+
+	// 20000 rows: id 1..20000, amount = id % 100, code has 5000 distinct values
+	var csv strings.Builder
+	csv.WriteString("id,amount,code\n")
+	for i := 1; i <= 20000; i++ {
+		fmt.Fprintf(&csv, "%d,%d,C%05d\n", i, i%100, i%5000)
+	}
+	db := openTestDB(t, NewBuilder().
+		AddReader(strings.NewReader(csv.String()), "orders", FileTypeCSV))
+
+	queryFloat := func(t *testing.T, query string) sql.NullFloat64 {
+		t.Helper()
+		var value sql.NullFloat64
+		require.NoError(t, db.QueryRowContext(context.Background(), query).Scan(&value))
+		return value
+	}
+
+	t.Run("APPROX_COUNT_DISTINCT is within a few percent", func(t *testing.T) {
+		t.Parallel()
+
+		estimate := queryFloat(t, "SELECT APPROX_COUNT_DISTINCT(code) FROM orders")
+		require.True(t, estimate.Valid)
+		assert.InDelta(t, 5000, estimate.Float64, 5000*0.03)
+
+		assert.InDelta(t, 100, queryFloat(t, "SELECT APPROX_COUNT_DISTINCT(amount) FROM orders").Float64, 2)
+	})
+
+	t.Run("APPROX_COUNT_DISTINCT treats equal numbers as one value", func(t *testing.T) {
+		t.Parallel()
+
+		estimate := queryFloat(t, "SELECT APPROX_COUNT_DISTINCT(v) FROM (SELECT 1 AS v UNION ALL SELECT 1.0 UNION ALL SELECT '1' UNION ALL SELECT NULL)")
+		assert.Equal(t, 2.0, estimate.Float64)
+	})
+
+	t.Run("no rows gives 0", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, 0.0, queryFloat(t, "SELECT APPROX_COUNT_DISTINCT(code) FROM orders WHERE id < 0").Float64)
+	})
+
+	t.Run("ApproxAggregates estimates within the error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		// Every other rowid has no row, and every tenth value is NULL
+		_, err := db.ExecContext(ctx, `CREATE TABLE sparse (amount REAL);
+			INSERT INTO sparse (rowid, amount) SELECT id * 2, CASE WHEN id % 10 = 0 THEN NULL ELSE amount END FROM orders`)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name  string
+			table string
+		}{
+			{name: "contiguous rowids", table: "orders"},
+			{name: "rowids with gaps and NULL values", table: "sparse"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var count, sum, avg float64
+				require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(amount), SUM(amount), AVG(amount) FROM "+tt.table).Scan(&count, &sum, &avg))
+
+				result, err := ApproxAggregates(ctx, db, tt.table, "amount", 2000)
+				require.NoError(t, err)
+				assert.False(t, result.Exact)
+				assert.InDelta(t, count, result.Count, result.CountError)
+				assert.InDelta(t, sum, result.Sum, result.SumError)
+				assert.InDelta(t, avg, result.Avg, result.AvgError)
+				assert.Less(t, result.AvgError, avg*0.1)
+				assert.Positive(t, result.SampledValues)
+
+				again, err := ApproxAggregates(ctx, db, tt.table, "amount", 2000)
+				require.NoError(t, err)
+				assert.Equal(t, result, again)
+			})
+		}
+	})
+
+	t.Run("ApproxAggregates is exact for tables not larger than the sample", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := ApproxAggregates(context.Background(), db, "orders", "amount", 20000)
+		require.NoError(t, err)
+		assert.Equal(t, &ApproxResult{Count: 20000, Sum: 990000, Avg: 49.5, SampledValues: 20000, Exact: true}, result)
+	})
+
+	t.Run("ApproxAggregates rejects invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name       string
+			table      string
+			column     string
+			sampleSize int
+			want       ErrorCode
+		}{
+			{name: "missing table", table: "missing", column: "amount", sampleSize: 100, want: ErrCodeNotFound},
+			{name: "missing column", table: "orders", column: "missing", sampleSize: 100, want: ErrCodeNotFound},
+			{name: "sample too small", table: "orders", column: "amount", sampleSize: 1, want: ErrCodeInvalidConfig},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := ApproxAggregates(context.Background(), db, tt.table, tt.column, tt.sampleSize)
+				require.Error(t, err)
+				assert.Equal(t, tt.want, ErrorCodeOf(err))
+			})
+		}
+	})
+}
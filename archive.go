@@ -0,0 +1,63 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"path"
+	"strings"
+)
+
+// isArchiveDataFile reports whether the file at name in a zip or tar archive is a data
+// file that can be loaded. macOS resource forks (__MACOSX), hidden files, files of
+// unsupported types, and nested archives are not data files.
+func isArchiveDataFile(name string) bool {
+	name = strings.TrimPrefix(name, "./")
+	if strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+		return false
+	}
+	// Nested archives are not extracted
+	switch detectFileType(name).baseType() {
+	case FileTypeUnsupported, FileTypeZIP, FileTypeTAR:
+		return false
+	default:
+		return true
+	}
+}
+
+// isArchive reports whether files of the base file type are archives of data files
+func isArchive(fileType FileType) bool {
+	return fileType == FileTypeZIP || fileType == FileTypeTAR
+}
+
+// streamArchiveToDatabase loads the data files of the zip or tar archive at filePath
+func (sp *streamProcessor) streamArchiveToDatabase(ctx context.Context, db *sql.DB, reader io.Reader, filePath string) error {
+	if newFile(filePath).getFileType().baseType() == FileTypeTAR {
+		return sp.streamTARFileToDatabase(ctx, db, reader, filePath)
+	}
+	return sp.streamZIPFileToDatabase(ctx, db, reader, filePath)
+}
+
+// streamArchiveEntryToDatabase loads the data file at name in the archive at archivePath
+// as a table named after the file, the way a file found in a directory is loaded.
+// Excel and ODS workbooks become a table per sheet.
+func (sp *streamProcessor) streamArchiveEntryToDatabase(ctx context.Context, db *sql.DB, entry io.Reader, archivePath, name string) error {
+	reader, closer, err := sp.createDecompressedReader(entry, name)
+	if err != nil {
+		return newCodedError(ErrCodeInvalidData, "failed to decompress %s in %s: %w", name, archivePath, err)
+	}
+	if closer != nil {
+		defer func() { _ = closer() }() // Ignore close error of the read-only entry
+	}
+
+	fileType := detectFileType(name).baseType()
+	if isWorkbook(fileType) {
+		return sp.streamWorkbookToDatabase(ctx, db, reader, name, fileType)
+	}
+	return sp.streamReaderToDatabase(ctx, db, readerInput{
+		reader:    reader,
+		tableName: tableFromFilePath(name),
+		fileType:  fileType,
+		options:   sp.fileOptions[archivePath],
+	})
+}
@@ -0,0 +1,129 @@
+package filesql
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// dumpLockFileName is the name of the lock file created in the output directory
+const dumpLockFileName = ".filesql.lock"
+
+// dumpTempDirPrefix is the prefix of the directory that files are written to before
+// they are moved into the output directory
+const dumpTempDirPrefix = ".filesql-tmp-"
+
+// WithAtomicWrites writes each output file to a temporary file and then renames it
+// into place.
+//
+// Without it, DumpDatabase truncates and rewrites the output files in place, so a
+// reader (or a concurrent dump) can see a half-written file, and a failed dump leaves
+// one behind. With atomic writes, each file is written to a uniquely named hidden
+// directory in the output directory (".filesql-tmp-*") and renamed over the existing
+// file once it is complete. An output file is therefore always either the previous
+// version or the new one, and a failed or interrupted dump can simply be retried.
+// The temporary directory is removed when the dump ends.
+//
+// Renaming is atomic only within one file system, which is why the temporary files
+// are created inside the output directory.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().WithAtomicWrites().WithLockFile()
+//	err := filesql.DumpDatabase(db, "./output", options)
+func (o DumpOptions) WithAtomicWrites() DumpOptions {
+	o.AtomicWrites = true
+	return o
+}
+
+// WithLockFile makes DumpDatabase hold an advisory lock on the output directory.
+//
+// Before writing, DumpDatabase creates ".filesql.lock" in the output directory and
+// removes it when it is done. If the lock file already exists, another process is
+// dumping into the same directory and DumpDatabase returns an error wrapping
+// ErrOutputLocked without writing anything, so the caller can retry later. Combined
+// with WithAtomicWrites, two simultaneous exports can never interleave partial files.
+//
+// The lock is advisory: it only excludes processes that use WithLockFile as well.
+// A lock file left behind by a process that crashed must be removed manually; it
+// contains the process ID of its owner.
+//
+// Example:
+//
+//	err := filesql.DumpDatabase(db, "./output", filesql.NewDumpOptions().WithLockFile())
+//	if errors.Is(err, filesql.ErrOutputLocked) {
+//		// another export is running; try again later
+//	}
+func (o DumpOptions) WithLockFile() DumpOptions {
+	o.LockFile = true
+	return o
+}
+
+// lockOutputDir creates the lock file of outputDir and returns the function that
+// removes it. Without WithLockFile, it does nothing.
+func (o DumpOptions) lockOutputDir(outputDir string) (func(), error) {
+	if !o.LockFile {
+		return func() {}, nil
+	}
+
+	fsys := o.fileSystem()
+	lockPath := filepath.Join(outputDir, dumpLockFileName)
+	lock, err := fsys.CreateNew(lockPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("%w: %s exists; another dump is running, or remove it if a dump crashed", ErrOutputLocked, lockPath)
+		}
+		return nil, fmt.Errorf("failed to create lock file %s: %w", lockPath, err)
+	}
+
+	unlock := func() {
+		_ = fsys.Remove(lockPath) // Ignore remove error; the dump itself is done
+	}
+	_, writeErr := fmt.Fprintf(lock, "%d\n", os.Getpid())
+	if err := errors.Join(writeErr, lock.Close()); err != nil {
+		unlock()
+		return nil, fmt.Errorf("failed to write lock file %s: %w", lockPath, err)
+	}
+	return unlock, nil
+}
+
+// createTempDir creates the directory that output files are written to before they
+// are renamed into outputDir and returns the function that removes it. Without
+// WithAtomicWrites, it returns an empty path.
+func (o DumpOptions) createTempDir(outputDir string) (string, func(), error) {
+	if !o.AtomicWrites {
+		return "", func() {}, nil
+	}
+
+	fsys := o.fileSystem()
+	tempDir := filepath.Join(outputDir, dumpTempDirPrefix+rand.Text())
+	if err := fsys.MkdirAll(tempDir, 0750); err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	return tempDir, func() {
+		_ = fsys.Remove(tempDir) // Ignore remove error; the directory is hidden
+	}, nil
+}
+
+// writeTableFile writes a table to outputPath. With a temporary directory, the file
+// is written there first and renamed to outputPath once it is complete.
+func (o DumpOptions) writeTableFile(tempDir, outputPath string, write func(path string) error) error {
+	fsys := o.fileSystem()
+	if tempDir == "" {
+		return write(outputPath)
+	}
+
+	tempPath := filepath.Join(tempDir, filepath.Base(outputPath))
+	if err := write(tempPath); err != nil {
+		_ = fsys.Remove(tempPath) // Ignore remove error during error handling
+		return err
+	}
+	if err := fsys.Rename(tempPath, outputPath); err != nil {
+		_ = fsys.Remove(tempPath) // Ignore remove error during error handling
+		return fmt.Errorf("failed to move %s into place: %w", outputPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package filesql
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOptions_WithAtomicWrites(t *testing.T) {
+	t.Parallel()
+
+	newDB := func(t *testing.T, users string) *sql.DB {
+		t.Helper()
+		return openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(users), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id\n1\n"), "orders", FileTypeCSV))
+	}
+
+	t.Run("files are renamed into place", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		options := NewDumpOptions().WithAtomicWrites()
+		require.NoError(t, DumpDatabase(newDB(t, "id\n1\n"), outputDir, options))
+		require.NoError(t, DumpDatabase(newDB(t, "id\n2\n"), outputDir, options))
+
+		content, err := os.ReadFile(filepath.Join(outputDir, "users.csv")) //nolint:gosec // Test file path is safe
+		require.NoError(t, err)
+		assert.Equal(t, "id\n2\n", string(content))
+
+		entries, err := os.ReadDir(outputDir)
+		require.NoError(t, err)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.ElementsMatch(t, []string{"orders.csv", "users.csv"}, names)
+	})
+
+	t.Run("a failed dump keeps the previous files", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(newDB(t, "id\n1\n"), outputDir))
+
+		errDiskFull := errors.New("no space left on device")
+		options := NewDumpOptions().
+			WithAtomicWrites().
+			WithFileSystem(failingFileSystem{FileSystem: NewOSFileSystem(), limit: 2, writeErr: errDiskFull})
+		err := DumpDatabase(newDB(t, "id\n2\n"), outputDir, options)
+		require.ErrorIs(t, err, errDiskFull)
+
+		content, err := os.ReadFile(filepath.Join(outputDir, "users.csv")) //nolint:gosec // Test file path is safe
+		require.NoError(t, err)
+		assert.Equal(t, "id\n1\n", string(content))
+	})
+
+	for _, format := range []OutputFormat{OutputFormatTSV, OutputFormatLTSV, OutputFormatParquet, OutputFormatXLSX} {
+		t.Run("works with "+format.String(), func(t *testing.T) {
+			t.Parallel()
+
+			outputDir := t.TempDir()
+			options := NewDumpOptions().WithFormat(format).WithAtomicWrites()
+			require.NoError(t, DumpDatabase(newDB(t, "id\n1\n"), outputDir, options))
+			assert.FileExists(t, filepath.Join(outputDir, "users"+format.Extension()))
+		})
+	}
+
+	t.Run("the sheet of an XLSX file is named after the table", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		options := NewDumpOptions().WithFormat(OutputFormatXLSX).WithAtomicWrites()
+		require.NoError(t, DumpDatabase(newDB(t, "id\n1\n"), outputDir, options))
+
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join(outputDir, "users.xlsx")))
+		assert.Equal(t, []string{"1"}, queryRows(t, db, "SELECT id FROM users_users"))
+	})
+}
+
+func TestDumpOptions_WithLockFile(t *testing.T) {
+	t.Parallel()
+
+	newDB := func(t *testing.T) *sql.DB {
+		t.Helper()
+		return openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n2\n3\n"), "users", FileTypeCSV))
+	}
+
+	t.Run("the lock is removed after the dump", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(newDB(t), outputDir, NewDumpOptions().WithLockFile()))
+		assert.FileExists(t, filepath.Join(outputDir, "users.csv"))
+		assert.NoFileExists(t, filepath.Join(outputDir, dumpLockFileName))
+	})
+
+	t.Run("a locked directory is not written", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		lockPath := filepath.Join(outputDir, dumpLockFileName)
+		require.NoError(t, os.WriteFile(lockPath, []byte("1\n"), 0600))
+
+		err := DumpDatabase(newDB(t), outputDir, NewDumpOptions().WithLockFile())
+		require.ErrorIs(t, err, ErrOutputLocked)
+		assert.Equal(t, ErrCodeOutputLocked, ErrorCodeOf(err))
+		assert.NoFileExists(t, filepath.Join(outputDir, "users.csv"))
+		assert.FileExists(t, lockPath, "the lock of the other process must be kept")
+	})
+
+	t.Run("the lock is created through the file system", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		_, err := fsys.CreateNew(filepath.Join("out", dumpLockFileName))
+		require.NoError(t, err)
+
+		err = DumpDatabase(newDB(t), "out", NewDumpOptions().WithLockFile().WithFileSystem(fsys))
+		require.ErrorIs(t, err, ErrOutputLocked)
+	})
+
+	t.Run("concurrent dumps never interleave", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		options := NewDumpOptions().WithLockFile().WithAtomicWrites()
+
+		var wg sync.WaitGroup
+		errs := make([]error, 4)
+		for i := range errs {
+			db := newDB(t)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = DumpDatabase(db, outputDir, options)
+			}()
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			require.ErrorIs(t, err, ErrOutputLocked)
+		}
+		assert.Positive(t, succeeded)
+
+		content, err := os.ReadFile(filepath.Join(outputDir, "users.csv")) //nolint:gosec // Test file path is safe
+		require.NoError(t, err)
+		assert.Equal(t, "id\n1\n2\n3\n", string(content))
+		assert.NoFileExists(t, filepath.Join(outputDir, dumpLockFileName))
+	})
+}
@@ -0,0 +1,466 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// opflagP2IsReg is the P5 flag of OpenWrite whose P2 is a register holding the root
+// page of a table being created, instead of the root page itself
+const opflagP2IsReg = 0x10
+
+// maxCachedStatements bounds the statements whose changed tables are remembered, so
+// that statements with inlined values do not grow the cache without limit
+const maxCachedStatements = 1024
+
+// changedTables records the tables changed since the previous auto-save, so that a
+// save writes only those tables instead of every table of the database.
+//
+// The tables a statement changes are read from its EXPLAIN output: the tables and
+// indexes it opens for writing, including those written by triggers and foreign key
+// actions. A statement that writes the schema, such as CREATE TABLE or ALTER TABLE,
+// changes the tables whose definition is new or different afterwards. When the
+// tables cannot be determined, every table is treated as changed.
+type changedTables struct {
+	mu sync.Mutex
+	// tracking is true once the inputs are loaded; loaded rows are not changes
+	tracking bool
+	// generation increases with every change, so a save forgets only the changes it persisted
+	generation uint64
+	// all is the generation in which every table changed; 0 if they did not
+	all uint64
+	// tables maps lower-case table names to the generation in which they last changed
+	tables map[string]uint64
+	// schema maps lower-case table names to their CREATE TABLE statements
+	schema map[string]string
+	// statements caches the tables changed by statements until the schema changes
+	statements map[string]tableChanges
+}
+
+// tableChanges are the tables changed by one or more statements
+type tableChanges struct {
+	// all is true if any table may have changed
+	all bool
+	// schema is true if the schema was written, so the changed tables are found by
+	// comparing table definitions
+	schema bool
+	// tables are the lower-case names of the changed tables
+	tables []string
+}
+
+// add adds the changes of other to the changes
+func (c *tableChanges) add(other tableChanges) {
+	c.all = c.all || other.all
+	c.schema = c.schema || other.schema
+	c.tables = append(c.tables, other.tables...)
+}
+
+// newChangedTables returns a tracker; with allChanged, every table is treated as
+// changed until the first save, such as when saving to an empty output directory
+func newChangedTables(allChanged bool) *changedTables {
+	t := &changedTables{tables: make(map[string]uint64), statements: make(map[string]tableChanges)}
+	if allChanged {
+		t.generation = 1
+		t.all = 1
+	}
+	return t
+}
+
+// start records schema as the schema of the loaded database and starts tracking
+// changes. It does nothing on a nil receiver.
+func (t *changedTables) start(schema map[string]string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.schema = schema
+	t.tracking = true
+}
+
+// isTracking reports whether changes are tracked; false on a nil receiver
+func (t *changedTables) isTracking() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tracking
+}
+
+// statement returns the cached tables changed by query
+func (t *changedTables) statement(query string) (tableChanges, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changes, ok := t.statements[query]
+	return changes, ok
+}
+
+// cacheStatement remembers the tables changed by query
+func (t *changedTables) cacheStatement(query string, changes tableChanges) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.statements) >= maxCachedStatements {
+		clear(t.statements)
+	}
+	t.statements[query] = changes
+}
+
+// updateSchema replaces the recorded schema with schema and returns the tables whose
+// definition is new or different. The cached statements are forgotten, because
+// table names may now refer to other tables.
+func (t *changedTables) updateSchema(schema map[string]string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var changed []string
+	for name, definition := range schema {
+		if previous, ok := t.schema[name]; !ok || previous != definition {
+			changed = append(changed, name)
+		}
+	}
+	t.schema = schema
+	clear(t.statements)
+	return changed
+}
+
+// mark records changes as made now
+func (t *changedTables) mark(changes tableChanges) {
+	if !changes.all && len(changes.tables) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.generation++
+	if changes.all {
+		t.all = t.generation
+	}
+	for _, table := range changes.tables {
+		t.tables[table] = t.generation
+	}
+}
+
+// markIfTracking is mark for callers that may run before tracking starts
+func (t *changedTables) markIfTracking(changes tableChanges) {
+	if t.isTracking() {
+		t.mark(changes)
+	}
+}
+
+// snapshot returns the lower-case names of the tables changed so far, or nil if every
+// table may have changed, and the generation to pass to saved once they are saved.
+// Before tracking starts and on a nil receiver, every table is changed.
+func (t *changedTables) snapshot() (map[string]bool, uint64) {
+	if t == nil {
+		return nil, 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.all > 0 || !t.tracking {
+		return nil, t.generation
+	}
+	tables := make(map[string]bool, len(t.tables))
+	for table := range t.tables {
+		tables[table] = true
+	}
+	return tables, t.generation
+}
+
+// saved forgets the changes up to generation, which a save persisted. Changes made
+// while the save was running are kept for the next save. Saves while the inputs are
+// loaded persist partly loaded tables, so they forget nothing.
+func (t *changedTables) saved(generation uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.tracking {
+		return
+	}
+	if t.all <= generation {
+		t.all = 0
+	}
+	maps.DeleteFunc(t.tables, func(_ string, changed uint64) bool {
+		return changed <= generation
+	})
+}
+
+// startTrackingChanges starts tracking the tables changed after the inputs were loaded
+func (c *autoSaveConnector) startTrackingChanges(ctx context.Context) error {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	if c.anchorDB == nil {
+		return sql.ErrConnDone
+	}
+	// saveMu also guards the anchor connection that anchorDB reads through
+	schema, err := readTableSchema(ctx, c.memory.anchor)
+	if err != nil {
+		return fmt.Errorf("failed to track changed tables: %w", err)
+	}
+	c.changed.start(schema)
+	return nil
+}
+
+// statementChanges returns the tables that query, executed with args, changes. It
+// must be called before the statement is executed, because statements such as
+// CREATE TABLE cannot be explained once they ran.
+func (c *autoSaveConnection) statementChanges(ctx context.Context, query string, args []driver.NamedValue) tableChanges {
+	tracker := c.connector.changed
+	if !tracker.isTracking() {
+		return tableChanges{}
+	}
+	changes, ok := tracker.statement(query)
+	if !ok {
+		changes = c.explainChanges(ctx, query, args)
+		tracker.cacheStatement(query, changes)
+	}
+	return changes
+}
+
+// recordChanges records the changes of an executed statement. Changes made in a
+// transaction are recorded when it is committed.
+func (c *autoSaveConnection) recordChanges(ctx context.Context, changes tableChanges) {
+	tracker := c.connector.changed
+	if !tracker.isTracking() {
+		return
+	}
+	if changes.schema {
+		if schema, err := readTableSchema(ctx, c.conn); err == nil {
+			changes.tables = slices.Concat(changes.tables, tracker.updateSchema(schema))
+		} else {
+			changes.all = true
+		}
+	}
+
+	if c.inTransaction {
+		c.pending.add(changes)
+		return
+	}
+	tracker.mark(changes)
+}
+
+// commitChanges records the changes of the committed transaction
+func (c *autoSaveConnection) commitChanges() {
+	c.connector.changed.markIfTracking(c.pending)
+	c.pending = tableChanges{}
+}
+
+// explainChanges returns the tables that query changes according to its EXPLAIN output.
+// Statements and opcodes that may write rows in a way it does not recognize change
+// every table.
+func (c *autoSaveConnection) explainChanges(ctx context.Context, query string, args []driver.NamedValue) tableChanges {
+	// EXPLAIN only applies to the first of several statements
+	if !isSingleStatement(query) {
+		return tableChanges{all: true}
+	}
+	switch statementKeyword(query) {
+	case "SELECT", "VALUES":
+		return tableChanges{} // Queries change no table, so they need not be explained
+	case "INSERT", "REPLACE", "UPDATE", "DELETE", "WITH", "CREATE", "ALTER", "DROP":
+	default:
+		return tableChanges{all: true}
+	}
+
+	rows, err := c.queryContext(ctx, "EXPLAIN "+query, args)
+	if err != nil {
+		return tableChanges{all: true}
+	}
+	defer rows.Close()
+
+	var changes tableChanges
+	rootPages := make(map[int64]bool)
+	// cursors are the cursors the program opens, and written the cursors it writes
+	// rows through. Rows written through a cursor it does not open go to unknown
+	// tables. A cursor may be opened at a higher address than where it is used.
+	cursors := make(map[int64]bool)
+	var written []int64
+	columns := rows.Columns()
+	values := make([]driver.Value, len(columns))
+	for {
+		if err := rows.Next(values); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return tableChanges{all: true}
+		}
+		// The columns are addr, opcode, p1, p2, p3, p4, p5, and comment
+		opcode, _ := values[1].(string) //nolint:errcheck // Unknown opcodes change every table
+		p1, _ := values[2].(int64)      //nolint:errcheck // Operands of other opcodes are ignored
+		p2, _ := values[3].(int64)      //nolint:errcheck // Operands of other opcodes are ignored
+		p3, _ := values[4].(int64)      //nolint:errcheck // Operands of other opcodes are ignored
+		p5, _ := values[6].(int64)      //nolint:errcheck // Operands of other opcodes are ignored
+		switch opcode {
+		case "OpenWrite":
+			cursors[p1] = true
+			switch {
+			case p5&opflagP2IsReg != 0:
+				// A table created by the statement, which is found by comparing the schema
+			case p3 != 0:
+				// P3 is the database; only the tables of main (0) are saved
+			case p2 == 1:
+				changes.schema = true // Page 1 is the root page of sqlite_schema
+			default:
+				rootPages[p2] = true // P2 is the root page
+			}
+		case "OpenRead", "ReopenIdx", "OpenEphemeral", "OpenAutoindex", "OpenDup", "OpenPseudo", "SorterOpen":
+			cursors[p1] = true // Rows written through these cursors are not stored in tables
+		case "Insert", "Delete", "IdxInsert", "IdxDelete":
+			written = append(written, p1)
+		case "Clear": // DELETE without WHERE; P1 is the root page, P2 the database
+			if p2 == 0 {
+				rootPages[p1] = true
+			}
+		case "CreateBtree", "Destroy", "DropTable", "DropIndex", "DropTrigger", "ParseSchema":
+			changes.schema = true
+		case "VUpdate", "VCreate", "VDestroy", "SqlExec", "Vacuum", "IncrVacuum":
+			// The tables behind virtual tables and the statements these run are unknown
+			return tableChanges{all: true}
+		}
+	}
+	for _, cursor := range written {
+		if !cursors[cursor] {
+			return tableChanges{all: true}
+		}
+	}
+	if len(rootPages) == 0 {
+		return changes
+	}
+
+	tables, err := c.tablesOfRootPages(ctx, rootPages)
+	if err != nil {
+		return tableChanges{all: true}
+	}
+	changes.tables = tables
+	return changes
+}
+
+// statementKeyword returns the first keyword of query in upper case, skipping
+// whitespace, comments, and opening parentheses
+func statementKeyword(query string) string {
+	for {
+		query = strings.TrimLeft(query, " \t\r\n(")
+		switch {
+		case strings.HasPrefix(query, "--"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return ""
+			}
+			query = query[end:]
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query, "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+2:]
+		default:
+			end := strings.IndexFunc(query, func(r rune) bool { return (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') })
+			if end < 0 {
+				end = len(query)
+			}
+			return strings.ToUpper(query[:end])
+		}
+	}
+}
+
+// tablesOfRootPages returns the lower-case names of the tables whose data or indexes
+// are stored at the root pages
+func (c *autoSaveConnection) tablesOfRootPages(ctx context.Context, rootPages map[int64]bool) ([]string, error) {
+	rows, err := c.queryContext(ctx, "SELECT rootpage, tbl_name FROM sqlite_schema WHERE type IN ('table', 'index')", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	values := make([]driver.Value, 2)
+	for {
+		if err := rows.Next(values); err != nil {
+			if errors.Is(err, io.EOF) {
+				return tables, nil
+			}
+			return nil, err
+		}
+		rootPage, _ := values[0].(int64) //nolint:errcheck // Views have no root page
+		table, _ := values[1].(string)   //nolint:errcheck // tbl_name is always text
+		if rootPages[rootPage] {
+			tables = append(tables, strings.ToLower(table))
+		}
+	}
+}
+
+// readTableSchema returns the CREATE TABLE statements of the tables of the main
+// database by lower-case table name
+func readTableSchema(ctx context.Context, conn driver.Conn) (map[string]string, error) {
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return nil, errors.New("connection does not support queries")
+	}
+	rows, err := queryer.QueryContext(ctx, "SELECT name, sql FROM sqlite_schema WHERE type = 'table'", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schema := make(map[string]string)
+	values := make([]driver.Value, 2)
+	for {
+		if err := rows.Next(values); err != nil {
+			if errors.Is(err, io.EOF) {
+				return schema, nil
+			}
+			return nil, err
+		}
+		name, _ := values[0].(string)       //nolint:errcheck // name is always text
+		definition, _ := values[1].(string) //nolint:errcheck // sql is always text for tables
+		schema[strings.ToLower(name)] = definition
+	}
+}
+
+// isSingleStatement reports whether query holds at most one SQL statement, ignoring
+// semicolons in literals, quoted identifiers, and comments
+func isSingleStatement(query string) bool {
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '\'', '"', '`':
+			end := strings.IndexByte(query[i+1:], query[i])
+			if end < 0 {
+				return true
+			}
+			i += end + 1 // Doubled quotes are two adjacent literals
+		case '[':
+			end := strings.IndexByte(query[i+1:], ']')
+			if end < 0 {
+				return true
+			}
+			i += end + 1
+		case '-':
+			if strings.HasPrefix(query[i:], "--") {
+				end := strings.IndexByte(query[i:], '\n')
+				if end < 0 {
+					return true
+				}
+				i += end
+			}
+		case '/':
+			if strings.HasPrefix(query[i:], "/*") {
+				end := strings.Index(query[i+2:], "*/")
+				if end < 0 {
+					return true
+				}
+				i += end + 3
+			}
+		case ';':
+			return strings.TrimLeft(query[i+1:], " \t\r\n;") == ""
+		}
+	}
+	return true
+}
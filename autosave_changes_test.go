@@ -0,0 +1,249 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoSave_ChangedTables(t *testing.T) {
+	t.Parallel()
+
+	t.Run("only changed tables are saved", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,alice\n2,bob\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id,user_id\n1,1\n"), "orders", FileTypeCSV).
+			AddReader(strings.NewReader("code\njp\n"), "countries", FileTypeCSV).
+			EnableAutoSaveOnCommit("backup").
+			WithFileSystem(fsys))
+
+		// saved returns the saved tables and removes their files for the next check
+		saved := func(t *testing.T) []string {
+			t.Helper()
+
+			var tables []string
+			for _, table := range []string{"users", "orders", "countries", "notes"} {
+				path := filepath.Join("backup", table+".csv")
+				if _, ok := fsys.content(path); ok {
+					tables = append(tables, table)
+					require.NoError(t, fsys.Remove(path))
+				}
+			}
+			sort.Strings(tables)
+			return tables
+		}
+		commit := func(t *testing.T, queries ...string) {
+			t.Helper()
+
+			tx, err := db.BeginTx(context.Background(), nil)
+			require.NoError(t, err)
+			for _, query := range queries {
+				_, err := tx.ExecContext(context.Background(), query)
+				require.NoError(t, err)
+			}
+			require.NoError(t, tx.Commit())
+		}
+		exec := func(t *testing.T, query string) {
+			t.Helper()
+			_, err := db.ExecContext(context.Background(), query)
+			require.NoError(t, err)
+		}
+
+		// The output directory holds no table yet, so the first save writes all
+		commit(t, "UPDATE users SET name = 'carol' WHERE id = 2")
+		assert.Equal(t, []string{"countries", "orders", "users"}, saved(t))
+
+		commit(t, "INSERT INTO orders VALUES (2, 2)")
+		assert.Equal(t, []string{"orders"}, saved(t))
+
+		tx, err := db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(context.Background(), "DELETE FROM countries")
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+		commit(t, "SELECT COUNT(*) FROM users")
+		assert.Empty(t, saved(t), "rolled back changes and unchanged tables are not saved")
+
+		exec(t, "ALTER TABLE countries ADD COLUMN name TEXT")
+		assert.Equal(t, []string{"countries"}, saved(t), "schema changes are saved")
+		exec(t, "CREATE TABLE notes AS SELECT name FROM users")
+		assert.Equal(t, []string{"notes"}, saved(t))
+
+		// Tables written by triggers are changed, too. The statements of the trigger
+		// body cannot be told apart from several statements, which may change any table.
+		exec(t, "CREATE TRIGGER order_users AFTER INSERT ON orders BEGIN UPDATE users SET name = 'dave' WHERE id = new.user_id; END")
+		assert.Equal(t, []string{"countries", "notes", "orders", "users"}, saved(t))
+		commit(t, "INSERT INTO orders VALUES (3, 1)")
+		assert.Equal(t, []string{"orders", "users"}, saved(t))
+
+		// DELETE without WHERE clears the table instead of deleting rows one by one
+		commit(t, "DELETE FROM countries")
+		assert.Equal(t, []string{"countries"}, saved(t))
+
+		tx, err = db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		stmt, err := tx.PrepareContext(context.Background(), "INSERT INTO notes VALUES (?)")
+		require.NoError(t, err)
+		_, err = stmt.ExecContext(context.Background(), "memo")
+		require.NoError(t, err)
+		require.NoError(t, stmt.Close())
+		require.NoError(t, tx.Commit())
+		assert.Equal(t, []string{"notes"}, saved(t), "prepared statements are tracked")
+
+		// Several statements in one call may change any table
+		commit(t, "UPDATE users SET name = 'erin' WHERE id = 1; UPDATE users SET name = 'frank' WHERE id = 2")
+		assert.Equal(t, []string{"countries", "notes", "orders", "users"}, saved(t))
+	})
+
+	t.Run("unchanged original files are not overwritten", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		usersPath := filepath.Join(dir, "users.csv")
+		countriesPath := filepath.Join(dir, "countries.csv")
+		require.NoError(t, os.WriteFile(usersPath, []byte("id,name\r\n1,alice\r\n"), 0600))
+		require.NoError(t, os.WriteFile(countriesPath, []byte("code\r\njp\r\n"), 0600))
+
+		validatedBuilder, err := NewBuilder().
+			AddPaths(usersPath, countriesPath).
+			EnableAutoSave("").
+			Build(context.Background())
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(context.Background())
+		require.NoError(t, err)
+		_, err = db.ExecContext(context.Background(), "UPDATE users SET name = 'bob'")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		users, err := os.ReadFile(usersPath) //nolint:gosec // The path is created by the test
+		require.NoError(t, err)
+		assert.Equal(t, "id,name\n1,bob\n", string(users))
+		countries, err := os.ReadFile(countriesPath) //nolint:gosec // The path is created by the test
+		require.NoError(t, err)
+		assert.Equal(t, "code\r\njp\r\n", string(countries), "the unchanged table keeps its file")
+	})
+
+	t.Run("rows changed by queries are tracked", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("code\njp\n"), "countries", FileTypeCSV).
+			EnableAutoSaveOnCommit("backup").
+			WithFileSystem(fsys))
+
+		tx, err := db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit()) // Writes every table to the empty output directory
+		require.NoError(t, fsys.Remove(filepath.Join("backup", "users.csv")))
+		require.NoError(t, fsys.Remove(filepath.Join("backup", "countries.csv")))
+
+		tx, err = db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		var id int
+		require.NoError(t, tx.QueryRowContext(context.Background(), "INSERT INTO users VALUES (2) RETURNING id").Scan(&id))
+		require.NoError(t, tx.Commit())
+		_, ok := fsys.content(filepath.Join("backup", "users.csv"))
+		assert.True(t, ok)
+		_, ok = fsys.content(filepath.Join("backup", "countries.csv"))
+		assert.False(t, ok)
+	})
+}
+
+func TestAutoSave_ExplainedStatements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "insert with a common table expression", query: "WITH n AS (SELECT 2 AS id) INSERT INTO users SELECT id FROM n", want: []string{"users"}},
+		{name: "update with a common table expression", query: "WITH n AS (SELECT 1 AS id) UPDATE users SET id = 3 WHERE id IN (SELECT id FROM n)", want: []string{"users"}},
+		{name: "replace", query: "REPLACE INTO countries VALUES ('fr')", want: []string{"countries"}},
+		{name: "insert or replace after a comment", query: "/* fix */ INSERT OR REPLACE INTO countries VALUES ('jp')", want: []string{"countries"}},
+		{name: "query", query: "SELECT * FROM users", want: nil},
+		{name: "unrecognized statement", query: "PRAGMA user_version = 1", want: []string{"countries", "users"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fsys := newMemoryFileSystem()
+			db := openTestDB(t, NewBuilder().
+				AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+				AddReader(strings.NewReader("code\njp\n"), "countries", FileTypeCSV).
+				EnableAutoSaveOnCommit("backup").
+				WithFileSystem(fsys))
+
+			tx, err := db.BeginTx(context.Background(), nil)
+			require.NoError(t, err)
+			require.NoError(t, tx.Commit()) // Writes every table to the empty output directory
+			require.NoError(t, fsys.Remove(filepath.Join("backup", "users.csv")))
+			require.NoError(t, fsys.Remove(filepath.Join("backup", "countries.csv")))
+
+			tx, err = db.BeginTx(context.Background(), nil)
+			require.NoError(t, err)
+			_, err = tx.ExecContext(context.Background(), tt.query)
+			require.NoError(t, err)
+			require.NoError(t, tx.Commit())
+
+			var saved []string
+			for _, table := range []string{"countries", "users"} {
+				if _, ok := fsys.content(filepath.Join("backup", table+".csv")); ok {
+					saved = append(saved, table)
+				}
+			}
+			assert.Equal(t, tt.want, saved)
+		})
+	}
+}
+
+func TestStatementKeyword(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "select 1", want: "SELECT"},
+		{query: "  (SELECT 1) UNION SELECT 2", want: "SELECT"},
+		{query: "-- note\nWITH x AS (SELECT 1) DELETE FROM t", want: "WITH"},
+		{query: "/* a */ /* b */replace INTO t VALUES (1)", want: "REPLACE"},
+		{query: "-- only a comment", want: ""},
+		{query: "", want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, statementKeyword(tt.query), tt.query)
+	}
+}
+
+func TestIsSingleStatement(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "UPDATE t SET a = 1", want: true},
+		{query: "UPDATE t SET a = 1;\n", want: true},
+		{query: "UPDATE t SET a = ';'", want: true},
+		{query: `UPDATE "x;y" SET [a;b] = 1 -- ; comment`, want: true},
+		{query: "UPDATE t SET a = 1 /* ; */", want: true},
+		{query: "UPDATE t SET a = 'it''s'; DELETE FROM t", want: false},
+		{query: "UPDATE t SET a = 1; DELETE FROM t", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isSingleStatement(tt.query), tt.query)
+	}
+}
@@ -0,0 +1,207 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithAutoSaveInterval also saves the database every interval while it is open.
+//
+// Auto-save normally runs when the database is closed (EnableAutoSave) or when a
+// transaction is committed (EnableAutoSaveOnCommit). Long-running servers and
+// interactive applications may keep a database open for days, so a crash loses every
+// change made since it was opened. With an interval, the database is also saved on a
+// timer, which bounds the data lost by a crash to one interval. The close and commit
+// triggers keep working as configured.
+//
+// A periodic save is skipped when no row and no table changed since the previous
+// periodic save, so an idle database is not rewritten. The interval is measured with
+// the clock set with WithClock. A save only persists committed data, so it waits for
+// transactions that changed a table to finish; set SetAutoSaveTimeout to bound how
+// long each save may take.
+//
+// A failed periodic save is retried at the next interval. If the last periodic save
+// failed, db.Close returns its error.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("notes.csv").
+//		EnableAutoSave("./backup").
+//		WithAutoSaveInterval(5 * time.Minute)
+//
+// Build fails with an error with ErrCodeInvalidConfig if auto-save is not enabled or
+// the interval is negative. An interval of 0 disables periodic saving (default).
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithAutoSaveInterval(interval time.Duration) *DBBuilder {
+	b.autoSaveInterval = interval
+	return b
+}
+
+// EnableAutoSaveInterval saves the database every interval while it is open and when
+// it is closed.
+//
+// It is a shorthand for EnableAutoSave followed by WithAutoSaveInterval. Saving on
+// every commit is too chatty for workloads with many small transactions, and saving
+// only on close loses every change if the process crashes; saving on a timer bounds
+// the data lost by a crash to one interval. Changes made after the last periodic save
+// are saved when the database is closed. Combine it with WithAutoSaveRowThreshold to
+// also save after many rows change.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("events.csv").
+//		EnableAutoSaveInterval("./backup", 30*time.Second)
+//
+// Build fails with an error with ErrCodeInvalidConfig if the interval is negative. An
+// interval of 0 saves only on close, like EnableAutoSave.
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableAutoSaveInterval(outputDir string, interval time.Duration, options ...DumpOptions) *DBBuilder {
+	return b.EnableAutoSave(outputDir, options...).WithAutoSaveInterval(interval)
+}
+
+// validateAutoSaveInterval checks that the interval can be used with the auto-save configuration
+func validateAutoSaveInterval(interval time.Duration, config *autoSaveConfig) error {
+	if interval < 0 {
+		return newCodedError(ErrCodeInvalidConfig, "auto-save interval must not be negative: %s", interval)
+	}
+	if interval > 0 && (config == nil || !config.enabled) {
+		return newCodedError(ErrCodeInvalidConfig, "WithAutoSaveInterval requires EnableAutoSave or EnableAutoSaveOnCommit")
+	}
+	return nil
+}
+
+// periodicAutoSave saves a database on a timer, and after a number of changed rows,
+// until the database is closed
+type periodicAutoSave struct {
+	connector *autoSaveConnector
+	// interval is the time between saves; 0 saves only after rowThreshold rows
+	interval time.Duration
+	clock    Clock
+	// rowThreshold is the number of changed rows that triggers a save; 0 disables it
+	rowThreshold int64
+	// counting is true once the inputs are loaded and changed rows are counted
+	counting atomic.Bool
+	// modifiedRows is the number of rows changed since the previous save
+	modifiedRows atomic.Int64
+
+	mu sync.Mutex
+	// stopTimer cancels the pending tick; nil until start is called
+	stopTimer func() bool
+	// closed is true once the database is closing
+	closed bool
+	// saved is the data version of the database at the previous periodic save
+	saved int64
+	// err is the error of the previous periodic save
+	err error
+}
+
+// newPeriodicAutoSave returns a periodic save through connector; nil clock uses the system clock
+func newPeriodicAutoSave(connector *autoSaveConnector, interval time.Duration, clock Clock) *periodicAutoSave {
+	if clock == nil {
+		clock = NewSystemClock()
+	}
+	return &periodicAutoSave{connector: connector, interval: interval, clock: clock}
+}
+
+// start records the loaded database as saved and schedules the first save. It does
+// nothing on a nil receiver, so callers need not check whether periodic saving is enabled.
+func (p *periodicAutoSave) start(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	version, err := p.connector.dataVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.saved = version
+	p.counting.Store(true)
+	if !p.closed && p.interval > 0 {
+		p.stopTimer = p.clock.AfterFunc(p.interval, p.tick)
+	}
+	return nil
+}
+
+// tick saves the database if it changed and schedules the next save
+func (p *periodicAutoSave) tick() {
+	p.mu.Lock()
+	saved := p.saved
+	p.mu.Unlock()
+
+	version, err := p.connector.saveChanges(context.Background(), saved)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.saved = version
+	p.err = err
+	p.stopTimer = p.clock.AfterFunc(p.interval, p.tick)
+}
+
+// stop cancels the pending save and returns the error of the previous periodic save.
+// It does nothing on a nil receiver.
+func (p *periodicAutoSave) stop() error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	if p.stopTimer != nil {
+		p.stopTimer()
+	}
+	return p.err
+}
+
+// dataVersion returns SQLite's data version as seen by the anchor connection, which
+// changes whenever another connection commits a change
+func (c *autoSaveConnector) dataVersion(ctx context.Context) (int64, error) {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	return c.dataVersionLocked(ctx)
+}
+
+// dataVersionLocked is dataVersion for callers that hold saveMu
+func (c *autoSaveConnector) dataVersionLocked(ctx context.Context) (int64, error) {
+	if c.anchorDB == nil {
+		return 0, sql.ErrConnDone
+	}
+	var version int64
+	if err := c.anchorDB.QueryRowContext(ctx, "PRAGMA data_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read data version: %w", err)
+	}
+	return version, nil
+}
+
+// saveChanges saves the database unless its data version is still version, and
+// returns the data version of the saved database
+func (c *autoSaveConnector) saveChanges(ctx context.Context, version int64) (int64, error) {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	current, err := c.dataVersionLocked(ctx)
+	if err != nil {
+		return version, err
+	}
+	if current == version {
+		return version, nil
+	}
+	if err := c.saveLocked(ctx); err != nil {
+		return version, fmt.Errorf("periodic auto-save failed: %w", err)
+	}
+	return current, nil
+}
@@ -0,0 +1,231 @@
+package filesql
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// switchableFileSystem is a memoryFileSystem whose file creation fails while fail is set
+type switchableFileSystem struct {
+	*memoryFileSystem
+	fail *atomic.Bool
+}
+
+func (s switchableFileSystem) Create(name string) (io.WriteCloser, error) {
+	if s.fail.Load() {
+		return nil, errors.New("disk unavailable")
+	}
+	return s.memoryFileSystem.Create(name)
+}
+
+func TestDBBuilder_WithAutoSaveInterval(t *testing.T) {
+	t.Parallel()
+
+	t.Run("changes are saved when the interval elapses", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fsys := newMemoryFileSystem()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithAutoSaveInterval(time.Minute).
+			WithFileSystem(fsys).
+			WithClock(clock))
+		defer db.Close()
+
+		_, err := db.ExecContext(context.Background(), "INSERT INTO notes VALUES (2)")
+		require.NoError(t, err)
+
+		clock.advance(59 * time.Second)
+		_, ok := fsys.content(filepath.Join("backup", "notes.csv"))
+		assert.False(t, ok)
+
+		clock.advance(time.Second)
+		content, ok := fsys.content(filepath.Join("backup", "notes.csv"))
+		require.True(t, ok)
+		assert.Equal(t, "id\n1\n2\n", content)
+	})
+
+	t.Run("unchanged databases are not saved again", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fsys := newMemoryFileSystem()
+		path := filepath.Join("backup", "notes.csv")
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithAutoSaveInterval(time.Minute).
+			WithFileSystem(fsys).
+			WithClock(clock))
+		defer db.Close()
+
+		// Nothing changed since the load
+		clock.advance(time.Minute)
+		_, ok := fsys.content(path)
+		assert.False(t, ok)
+
+		_, err := db.ExecContext(context.Background(), "UPDATE notes SET id = 3")
+		require.NoError(t, err)
+		clock.advance(time.Minute)
+		_, ok = fsys.content(path)
+		require.True(t, ok)
+
+		require.NoError(t, fsys.Remove(path))
+		clock.advance(time.Minute)
+		_, ok = fsys.content(path)
+		assert.False(t, ok)
+	})
+
+	t.Run("closing stops periodic saves", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fsys := newMemoryFileSystem()
+		path := filepath.Join("backup", "notes.csv")
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithAutoSaveInterval(time.Minute).
+			WithFileSystem(fsys).
+			WithClock(clock))
+
+		require.NoError(t, db.Close())
+		require.NoError(t, fsys.Remove(path))
+
+		clock.advance(time.Hour)
+		_, ok := fsys.content(path)
+		assert.False(t, ok)
+	})
+
+	t.Run("close returns the error of a failed periodic save", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fail := &atomic.Bool{}
+		fsys := switchableFileSystem{memoryFileSystem: newMemoryFileSystem(), fail: fail}
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithAutoSaveInterval(time.Minute).
+			WithFileSystem(fsys).
+			WithClock(clock))
+
+		_, err := db.ExecContext(context.Background(), "INSERT INTO notes VALUES (2)")
+		require.NoError(t, err)
+		fail.Store(true)
+		clock.advance(time.Minute)
+		fail.Store(false)
+
+		err = db.Close()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "periodic auto-save failed")
+		assert.Contains(t, err.Error(), "disk unavailable")
+	})
+
+	t.Run("a later successful save clears the error", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fail := &atomic.Bool{}
+		fsys := switchableFileSystem{memoryFileSystem: newMemoryFileSystem(), fail: fail}
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithAutoSaveInterval(time.Minute).
+			WithFileSystem(fsys).
+			WithClock(clock))
+
+		_, err := db.ExecContext(context.Background(), "INSERT INTO notes VALUES (2)")
+		require.NoError(t, err)
+		fail.Store(true)
+		clock.advance(time.Minute)
+		fail.Store(false)
+		clock.advance(time.Minute)
+
+		require.NoError(t, db.Close())
+		content, ok := fsys.content(filepath.Join("backup", "notes.csv"))
+		require.True(t, ok)
+		assert.Equal(t, "id\n1\n2\n", content)
+	})
+
+	t.Run("background loads start saving once loaded", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fsys := newMemoryFileSystem()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithAutoSaveInterval(time.Minute).
+			WithFileSystem(fsys).
+			WithClock(clock).
+			Build(context.Background())
+		require.NoError(t, err)
+		job, err := validatedBuilder.StartLoad(context.Background())
+		require.NoError(t, err)
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+
+		_, err = db.ExecContext(context.Background(), "INSERT INTO notes VALUES (2)")
+		require.NoError(t, err)
+		clock.advance(time.Minute)
+		content, ok := fsys.content(filepath.Join("backup", "notes.csv"))
+		require.True(t, ok)
+		assert.Equal(t, "id\n1\n2\n", content)
+	})
+
+	t.Run("EnableAutoSaveInterval saves periodically and on close", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fsys := newMemoryFileSystem()
+		path := filepath.Join("backup", "notes.csv")
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).
+			EnableAutoSaveInterval("backup", 30*time.Second).
+			WithFileSystem(fsys).
+			WithClock(clock))
+
+		_, err := db.ExecContext(context.Background(), "INSERT INTO notes VALUES (2)")
+		require.NoError(t, err)
+		clock.advance(30 * time.Second)
+		content, ok := fsys.content(path)
+		require.True(t, ok)
+		assert.Equal(t, "id\n1\n2\n", content)
+
+		_, err = db.ExecContext(context.Background(), "INSERT INTO notes VALUES (3)")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+		content, _ = fsys.content(path)
+		assert.Equal(t, "id\n1\n2\n3\n", content)
+	})
+
+	t.Run("invalid intervals are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name    string
+			builder *DBBuilder
+		}{
+			{name: "without auto-save", builder: NewBuilder().WithAutoSaveInterval(time.Minute)},
+			{name: "negative", builder: NewBuilder().EnableAutoSave("").WithAutoSaveInterval(-time.Minute)},
+		}
+		for _, tt := range tests {
+			_, err := tt.builder.AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).Build(context.Background())
+			require.Error(t, err, tt.name)
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err), tt.name)
+		}
+	})
+}
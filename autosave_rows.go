@@ -0,0 +1,194 @@
+package filesql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+)
+
+// WithAutoSaveRowThreshold also saves the database once rows rows were inserted,
+// updated, or deleted since the previous save.
+//
+// An interval (see WithAutoSaveInterval) bounds the time a crash can lose, but a
+// bulk import may change millions of rows between two ticks. With a row threshold,
+// the database is also saved as soon as enough rows changed, which bounds the data a
+// crash can lose in rows. Every save, whatever triggered it, starts a new count.
+//
+// Rows changed in a transaction are counted when it is committed, and rows of a
+// rolled back transaction are not counted. The save runs in the statement or commit
+// that reaches the threshold, which returns its error; the change itself is kept.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("events.csv").
+//		EnableAutoSaveInterval("./backup", 30*time.Second).
+//		WithAutoSaveRowThreshold(10000)
+//
+// Build fails with an error with ErrCodeInvalidConfig if auto-save is not enabled or
+// the threshold is negative. A threshold of 0 disables it (default).
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithAutoSaveRowThreshold(rows int64) *DBBuilder {
+	b.autoSaveRowThreshold = rows
+	return b
+}
+
+// validateAutoSaveRowThreshold checks that the threshold can be used with the auto-save configuration
+func validateAutoSaveRowThreshold(rows int64, config *autoSaveConfig) error {
+	if rows < 0 {
+		return newCodedError(ErrCodeInvalidConfig, "auto-save row threshold must not be negative: %d", rows)
+	}
+	if rows > 0 && (config == nil || !config.enabled) {
+		return newCodedError(ErrCodeInvalidConfig, "WithAutoSaveRowThreshold requires EnableAutoSave or EnableAutoSaveOnCommit")
+	}
+	return nil
+}
+
+// countsRows reports whether connections count the rows they change, which starts
+// once the inputs are loaded. It is false on a nil receiver.
+func (p *periodicAutoSave) countsRows() bool {
+	return p != nil && p.rowThreshold > 0 && p.counting.Load()
+}
+
+// addModifiedRows adds rows to the rows changed since the previous save and saves the
+// database once they reach the threshold
+func (p *periodicAutoSave) addModifiedRows(ctx context.Context, rows int64) error {
+	if p.modifiedRows.Add(rows) < p.rowThreshold {
+		return nil
+	}
+
+	c := p.connector
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	if p.modifiedRows.Load() < p.rowThreshold {
+		return nil // Another connection saved the rows meanwhile
+	}
+	if err := c.saveLocked(ctx); err != nil {
+		return fmt.Errorf("row threshold auto-save failed: %w", err)
+	}
+
+	// The next tick need not save the rows again; if the version is unknown, it does
+	if version, err := c.dataVersionLocked(ctx); err == nil {
+		p.mu.Lock()
+		p.saved = version
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+// pendingRows returns the rows changed since the previous save; 0 on a nil receiver
+func (p *periodicAutoSave) pendingRows() int64 {
+	if p == nil {
+		return 0
+	}
+	return p.modifiedRows.Load()
+}
+
+// rowsSaved subtracts rows that a save persisted from the rows changed since the previous save
+func (p *periodicAutoSave) rowsSaved(rows int64) {
+	if p == nil || rows == 0 {
+		return
+	}
+	p.modifiedRows.Add(-rows)
+}
+
+// baselineChanges records the rows the connection changed so far, such as the rows
+// of the inputs, so that only rows changed afterwards are counted
+func (c *autoSaveConnection) baselineChanges(ctx context.Context) error {
+	if c.baselined || !c.connector.periodic.countsRows() {
+		return nil
+	}
+	changes, err := c.totalChanges(ctx)
+	if err != nil {
+		return err
+	}
+	c.changes = changes
+	c.baselined = true
+	return nil
+}
+
+// countModifiedRows adds the rows changed since the previous call to the rows changed
+// since the previous save. Rows changed in a transaction are counted on commit.
+func (c *autoSaveConnection) countModifiedRows(ctx context.Context) error {
+	if !c.baselined || c.inTransaction {
+		return nil
+	}
+	changes, err := c.totalChanges(ctx)
+	if err != nil {
+		return err
+	}
+	rows := changes - c.changes
+	c.changes = changes
+	if rows <= 0 {
+		return nil
+	}
+	return c.connector.periodic.addModifiedRows(ctx, rows)
+}
+
+// totalChanges returns the number of rows the connection inserted, updated, or deleted
+func (c *autoSaveConnection) totalChanges(ctx context.Context) (int64, error) {
+	changes, err := c.queryInt64(ctx, "SELECT total_changes()")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count changed rows: %w", err)
+	}
+	return changes, nil
+}
+
+// autoSaveStmt records the tables changed by a prepared statement and counts the rows
+// it changed for WithAutoSaveRowThreshold
+type autoSaveStmt struct {
+	driver.Stmt
+	conn  *autoSaveConnection
+	query string
+}
+
+// ExecContext implements driver.StmtExecContext
+func (s *autoSaveStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.conn.baselineChanges(ctx); err != nil {
+		return nil, err
+	}
+	changes := s.conn.statementChanges(ctx, s.query, args)
+
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		result, err = s.Stmt.Exec(values) //nolint:staticcheck // Need backward compatibility with older drivers
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.conn.recordChanges(ctx, changes)
+
+	if err := s.conn.countModifiedRows(ctx); err != nil {
+		return nil, fmt.Errorf("statement executed successfully, but auto-save failed: %w", err)
+	}
+	return result, nil
+}
+
+// QueryContext implements driver.StmtQueryContext
+func (s *autoSaveStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	changes := s.conn.statementChanges(ctx, s.query, args)
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		rows, err = s.Stmt.Query(values) //nolint:staticcheck // Need backward compatibility with older drivers
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.conn.recordChanges(ctx, changes)
+	return rows, nil
+}
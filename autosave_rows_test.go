@@ -0,0 +1,125 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithAutoSaveRowThreshold(t *testing.T) {
+	t.Parallel()
+
+	// open loads five rows into notes, which are not counted, and saves to backup
+	open := func(t *testing.T, fsys *memoryFileSystem, threshold int64) *sql.DB {
+		t.Helper()
+
+		return openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n2\n3\n4\n5\n"), "notes", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithAutoSaveRowThreshold(threshold).
+			WithFileSystem(fsys))
+	}
+	exec := func(t *testing.T, db interface {
+		ExecContext(context.Context, string, ...any) (sql.Result, error)
+	}, query string) {
+		t.Helper()
+		_, err := db.ExecContext(context.Background(), query)
+		require.NoError(t, err)
+	}
+	path := filepath.Join("backup", "notes.csv")
+
+	t.Run("the database is saved once enough rows changed", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		db := open(t, fsys, 3)
+		_, ok := fsys.content(path)
+		assert.False(t, ok, "loaded rows are not counted")
+
+		exec(t, db, "INSERT INTO notes VALUES (6), (7)")
+		_, ok = fsys.content(path)
+		assert.False(t, ok)
+		exec(t, db, "DELETE FROM notes WHERE id = 1")
+		content, ok := fsys.content(path)
+		require.True(t, ok)
+		assert.Equal(t, "id\n2\n3\n4\n5\n6\n7\n", content)
+
+		// Every save starts a new count
+		exec(t, db, "UPDATE notes SET id = id * 10 WHERE id IN (2, 3)")
+		content, _ = fsys.content(path)
+		assert.Equal(t, "id\n2\n3\n4\n5\n6\n7\n", content)
+		exec(t, db, "UPDATE notes SET id = 40 WHERE id = 4")
+		content, _ = fsys.content(path)
+		assert.Equal(t, "id\n20\n30\n40\n5\n6\n7\n", content)
+	})
+
+	t.Run("rows of transactions are counted on commit", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		db := open(t, fsys, 2)
+
+		tx, err := db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		exec(t, tx, "DELETE FROM notes")
+		require.NoError(t, tx.Rollback())
+		exec(t, db, "INSERT INTO notes VALUES (6)")
+		_, ok := fsys.content(path)
+		assert.False(t, ok, "rows of rolled back transactions are not counted")
+
+		tx, err = db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		exec(t, tx, "INSERT INTO notes VALUES (7)")
+		_, ok = fsys.content(path)
+		assert.False(t, ok)
+		require.NoError(t, tx.Commit())
+		content, ok := fsys.content(path)
+		require.True(t, ok)
+		assert.Equal(t, "id\n1\n2\n3\n4\n5\n6\n7\n", content)
+	})
+
+	t.Run("prepared statements are counted", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		db := open(t, fsys, 2)
+		stmt, err := db.PrepareContext(context.Background(), "INSERT INTO notes VALUES (?)")
+		require.NoError(t, err)
+		defer stmt.Close()
+
+		_, err = stmt.ExecContext(context.Background(), 6)
+		require.NoError(t, err)
+		_, ok := fsys.content(path)
+		assert.False(t, ok)
+		_, err = stmt.ExecContext(context.Background(), 7)
+		require.NoError(t, err)
+		content, ok := fsys.content(path)
+		require.True(t, ok)
+		assert.Equal(t, "id\n1\n2\n3\n4\n5\n6\n7\n", content)
+	})
+
+	t.Run("configuration", func(t *testing.T) {
+		t.Parallel()
+
+		builder, err := NewBuilderFromConfig(BuilderConfig{AutoSave: &AutoSaveSettings{RowThreshold: 500}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(500), builder.autoSaveRowThreshold)
+
+		tests := []struct {
+			name    string
+			builder *DBBuilder
+		}{
+			{name: "without auto-save", builder: NewBuilder().WithAutoSaveRowThreshold(10)},
+			{name: "negative", builder: NewBuilder().EnableAutoSave("").WithAutoSaveRowThreshold(-1)},
+		}
+		for _, tt := range tests {
+			_, err := tt.builder.AddReader(strings.NewReader("id\n1\n"), "notes", FileTypeCSV).Build(context.Background())
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err), tt.name)
+		}
+	})
+}
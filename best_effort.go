@@ -0,0 +1,183 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// InputError is the error of a single input that failed to be collected by Build or
+// loaded by Open.
+//
+// When several inputs fail, Build and Open return every InputError joined with
+// errors.Join rather than only the first one, so all the bad files of a directory can
+// be fixed at once. Use InputErrors to list them:
+//
+//	db, err := filesql.Open("exports/")
+//	for _, inputErr := range filesql.InputErrors(err) {
+//		log.Printf("%s: %v", inputErr.Input, filesql.ErrorCodeOf(inputErr))
+//	}
+//
+// The message of an *InputError is the message of the error it wraps.
+type InputError struct {
+	// Input is the file path of the input, or the table name of a reader input
+	Input string
+	// Err is the error of the input
+	Err error
+}
+
+// Error returns the message of the underlying error
+func (e *InputError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// InputErrors returns the errors of the inputs that failed in err, in the order the
+// inputs were added. It returns nil when err identifies no input, such as a
+// configuration error or nil.
+func InputErrors(err error) []*InputError {
+	var inputErrs []*InputError
+	var collect func(err error)
+	collect = func(err error) {
+		// errors.As would stop at the first input of joined errors
+		switch e := err.(type) { //nolint:errorlint // The error tree is walked explicitly
+		case nil:
+		case *InputError:
+			inputErrs = append(inputErrs, e)
+		case interface{ Unwrap() []error }:
+			for _, joined := range e.Unwrap() {
+				collect(joined)
+			}
+		default:
+			collect(errors.Unwrap(err))
+		}
+	}
+	collect(err)
+	return inputErrs
+}
+
+// WithBestEffort loads the inputs that can be loaded and skips those that fail.
+//
+// Without this option, Open fails when any input fails to load; it still tries every
+// input, so the returned error lists all the failed inputs (see InputErrors). With it,
+// Open drops the tables of an input that fails partway, records a load warning for the
+// input (see GetLoadWarnings), and continues with the next one, so one corrupt file in
+// a directory of hundreds does not block the others. Failures that affect the whole
+// load, such as cancellation or WithMemoryLimit, still fail Open.
+//
+// Build still fails for inputs that cannot be collected, such as paths that do not
+// exist, since they are configuration errors.
+//
+// Example:
+//
+//	db, err := filesql.NewBuilder().
+//		AddPath("exports/"). // one file is truncated
+//		WithBestEffort().
+//		Build(ctx) // then Open(ctx); GetLoadWarnings reports the skipped file
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithBestEffort() *DBBuilder {
+	b.streamProcessor.bestEffort = true
+	return b
+}
+
+// stopsLoad reports whether err affects the whole load rather than a single input
+func stopsLoad(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrContextCancelled) || errors.Is(err, ErrMemoryLimit)
+}
+
+// inputLoader loads inputs one by one and collects the errors of those that fail
+type inputLoader struct {
+	sp       *streamProcessor
+	db       *sql.DB
+	failures []error
+}
+
+// newInputLoader returns a loader of inputs into db
+func (sp *streamProcessor) newInputLoader(db *sql.DB) *inputLoader {
+	return &inputLoader{sp: sp, db: db}
+}
+
+// load loads an input with load. input identifies the input in errors, table is the
+// table its load warnings apply to, and describe adds the input to the error of load.
+// It returns an error only when loading must stop.
+func (l *inputLoader) load(ctx context.Context, input, table string, load func() error, describe func(error) error) error {
+	var tables map[string]bool
+	if l.sp.bestEffort {
+		var err error
+		if tables, err = existingTables(ctx, l.db); err != nil {
+			return err
+		}
+	}
+
+	loadErr := load()
+	if loadErr == nil {
+		return nil
+	}
+	inputErr := &InputError{Input: input, Err: withErrorCode(describe(loadErr), ErrCodeLoadFailed)}
+	if stopsLoad(loadErr) {
+		return inputErr
+	}
+	if !l.sp.bestEffort {
+		l.failures = append(l.failures, inputErr)
+		return nil
+	}
+
+	if err := dropNewTables(ctx, l.db, tables); err != nil {
+		return errors.Join(inputErr, err)
+	}
+	return recordLoadWarning(ctx, l.db, table, fmt.Sprintf("skipped %s, which failed to load: %v", input, loadErr))
+}
+
+// err returns the errors of the inputs that failed, joined, or nil
+func (l *inputLoader) err() error {
+	return errors.Join(l.failures...)
+}
+
+// existingTables returns the names of the tables of db
+func existingTables(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		tables[name] = true
+	}
+	return tables, rows.Err()
+}
+
+// dropNewTables drops the tables, including staging and typing tables, that a failed
+// input created since tables were listed. Metadata tables such as the load warnings are kept.
+func dropNewTables(ctx context.Context, db *sql.DB, tables map[string]bool) error {
+	current, err := existingTables(ctx, db)
+	if err != nil {
+		return err
+	}
+	for name := range current {
+		if tables[name] {
+			continue
+		}
+		if strings.HasPrefix(name, metadataTablePrefix) &&
+			!strings.HasPrefix(name, stagingTablePrefix) && !strings.HasPrefix(name, typingTablePrefix) {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, "DROP TABLE "+quoteIdentifier(name)); err != nil {
+			return fmt.Errorf("failed to drop table %s of a failed input: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithBestEffort(t *testing.T) {
+	t.Parallel()
+
+	// writeDirectory writes users.csv, the malformed broken.csv, and dup.csv with a
+	// duplicate column into a new directory. broken.csv fails after its first rows
+	// were loaded in chunks of one row.
+	writeDirectory := func(t *testing.T) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name\n1,alice\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.csv"), []byte("id,name\n1,a\n2,b\n3,\"c\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "dup.csv"), []byte("id,id\n1,2\n"), 0600))
+		return dir
+	}
+
+	t.Run("every failed input is reported", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeDirectory(t)
+		_, err := tryOpenTestDB(t, NewBuilder().AddPath(dir).SetDefaultChunkSize(1))
+		require.Error(t, err)
+
+		inputErrs := InputErrors(err)
+		require.Len(t, inputErrs, 2)
+		assert.Equal(t, filepath.Join(dir, "broken.csv"), inputErrs[0].Input)
+		assert.Equal(t, ErrCodeLoadFailed, ErrorCodeOf(inputErrs[0]))
+		assert.Equal(t, filepath.Join(dir, "dup.csv"), inputErrs[1].Input)
+		assert.Equal(t, ErrCodeDuplicateColumn, ErrorCodeOf(inputErrs[1]))
+		assert.Equal(t, ErrCodeLoadFailed, ErrorCodeOf(err))
+		assert.Contains(t, err.Error(), "failed to stream file "+filepath.Join(dir, "broken.csv"))
+		assert.Contains(t, err.Error(), "failed to stream file "+filepath.Join(dir, "dup.csv"))
+	})
+
+	t.Run("failed inputs are skipped with a warning", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeDirectory(t)
+		db := openTestDB(t, NewBuilder().AddPath(dir).SetDefaultChunkSize(1).WithBestEffort())
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"users"}, tables)
+		var leftovers int
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT COUNT(*) FROM sqlite_master WHERE name LIKE '"+stagingTablePrefix+"%' OR name LIKE '"+typingTablePrefix+"%'").Scan(&leftovers))
+		assert.Zero(t, leftovers)
+
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		require.Len(t, warnings, 2)
+		assert.Equal(t, "broken", warnings[0].Table)
+		assert.True(t, strings.HasPrefix(warnings[0].Message, "skipped "+filepath.Join(dir, "broken.csv")+", which failed to load: "))
+		assert.Equal(t, "dup", warnings[1].Table)
+	})
+
+	t.Run("failed reader inputs are skipped", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,id\n1,2\n"), "bad", FileTypeCSV).
+			AddReader(strings.NewReader("id\n1\n"), "good", FileTypeCSV).
+			WithBestEffort())
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"good"}, tables)
+	})
+
+	t.Run("every path that cannot be collected is reported by Build", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		first := filepath.Join(dir, "missing1.csv")
+		second := filepath.Join(dir, "missing2.csv")
+		_, err := NewBuilder().AddPaths(first, second).WithBestEffort().Build(context.Background())
+		require.Error(t, err)
+
+		inputErrs := InputErrors(err)
+		require.Len(t, inputErrs, 2)
+		assert.Equal(t, first, inputErrs[0].Input)
+		assert.Equal(t, second, inputErrs[1].Input)
+		assert.Equal(t, ErrCodePathNotFound, ErrorCodeOf(err))
+	})
+
+	t.Run("errors of the configuration identify no input", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewBuilder().Build(context.Background())
+		require.Error(t, err)
+		assert.Empty(t, InputErrors(err))
+		assert.Empty(t, InputErrors(nil))
+	})
+}
@@ -8,12 +8,16 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/xuri/excelize/v2"
-	"modernc.org/sqlite" // Direct SQLite driver usage
 )
 
 // DBBuilder configures and creates database connections from various data sources.
@@ -36,20 +40,100 @@ import (
 //   - File paths (AddPath)
 //   - Embedded filesystems (AddFS)
 //   - io.Reader streams (AddReader)
+//   - Amazon S3 objects (AddS3 or s3:// paths)
+//   - HTTP and HTTPS URLs (AddURL or URL paths)
 //   - Auto-save functionality (EnableAutoSave)
 type DBBuilder struct {
 	// paths contains regular file paths
 	paths []string
+	// pathOptions maps the indexes of paths added with AddPathWithOptions to their input options
+	pathOptions map[int]inputOptions
+	// pathTables maps the indexes of paths added with AddPathsAsTable to their table
+	pathTables map[int]string
+	// inputOptions are the input options applied to the whole builder with Apply
+	inputOptions []InputOption
 	// filesystems contains fs.FS instances
 	filesystems []fs.FS
 	// readers contains reader configurations
 	readers []readerInput
+	// remotes contains objects downloaded while loading, such as S3 objects
+	remotes []remoteInput
+	// s3Config configures the download of S3 objects
+	s3Config S3Config
+	// gcsConfig configures the download of Cloud Storage objects
+	gcsConfig GCSConfig
+	// gcs downloads the Cloud Storage objects of a validated builder; nil until used
+	gcs *gcsClient
+	// httpHeader contains the headers sent with the requests of URL inputs
+	httpHeader http.Header
+	// validated is true for the builders returned by Build
+	validated bool
 	// collectedPaths contains all paths after Build validation
 	collectedPaths []string
+	// skippedPaths are input paths that Build skipped because they reach an already added file
+	skippedPaths []skippedPath
+	// emptyFilePolicy controls how zero-byte files found in directories are loaded
+	emptyFilePolicy EmptyFilePolicy
+	// emptyFiles are zero-byte files found in directories that Build skipped
+	emptyFiles []string
+	// tableNames maps file paths to the names of their tables (see WithTableName)
+	tableNames map[string]string
+	// resources holds the inputs opened by Build until they are loaded
+	resources *buildResources
+	// inputOrder records the kind of every added path, filesystem, and reader in the order they were added
+	inputOrder []inputKind
+	// sourcePriority lets later inputs replace earlier inputs producing the same table
+	sourcePriority bool
+	// replacedInputs are inputs that Build dropped because a later input produces the same table
+	replacedInputs []replacedInput
+	// references are read-only reference data attached to the database
+	references []*ReferenceData
+	// sqliteDBs are existing SQLite databases attached to the database
+	sqliteDBs []sqliteDatabase
 	// parsedTables contains tables parsed from streaming readers
 	parsedTables []*table
+	// derivedTables contains tables created from SQL queries after loading
+	derivedTables []derivedTable
+	// surrogateKeys contains integer keys generated for text columns after loading
+	surrogateKeys []surrogateKey
+	// validationRules contains data quality rules evaluated after loading
+	validationRules []tableRules
+	// strictValidation makes rule violations fail the load
+	strictValidation bool
+	// deterministicOrder loads inputs sorted by table name
+	deterministicOrder bool
+	// loadMetrics records per-input resource accounting in the load metrics table
+	loadMetrics bool
+	// loadSummary records the per-table load summary in the load summary table
+	loadSummary bool
+	// schemaMetadata records the source, header, and type of every column in the schema metadata table
+	schemaMetadata bool
+	// headerMappingFiles contains JSON header mapping files read by Build
+	headerMappingFiles []string
 	// autoSaveConfig contains auto-save settings
 	autoSaveConfig *autoSaveConfig
+	// autoSaveTimeout bounds the duration of each auto-save; 0 means no limit
+	autoSaveTimeout time.Duration
+	// autoSaveInterval is the interval of periodic auto-saves; 0 disables them
+	autoSaveInterval time.Duration
+	// autoSaveRowThreshold is the number of changed rows that triggers an auto-save; 0 disables it
+	autoSaveRowThreshold int64
+	// lazyLoading defers loading files until their tables are referenced
+	lazyLoading bool
+	// persistentDB is the SQLite database file inputs are loaded into; empty uses an in-memory database
+	persistentDB string
+	// watch reloads the tables of files that change after Open
+	watch bool
+	// watchHandler is called after each reload of EnableWatch; nil reports nothing
+	watchHandler func(WatchEvent)
+	// progressHandler is called with the progress of loading; nil reports nothing
+	progressHandler func(Progress)
+	// maxOpenConns limits the open connections of the database; 0 means no limit
+	maxOpenConns int
+	// maxIdleConns is the number of idle connections the database keeps
+	maxIdleConns int
+	// clock measures the auto-save timeout; nil uses the system clock
+	clock Clock
 	// defaultChunkSize is the default chunk size for reading large files (10MB)
 	defaultChunkSize int
 
@@ -67,6 +151,14 @@ type readerInput struct {
 	tableName string
 	// fileType specifies the file format using domain/model types
 	fileType FileType
+	// source is the path of the file a reader was opened for by AddFS; empty for AddReader
+	source string
+	// writeBack is the filesystem of an AddFS reader when auto-save can write back into it
+	writeBack WritableFS
+	// sidecar is the sidecar index state of a file path loaded with WithSidecarIndex
+	sidecar *sidecarLoad
+	// options are the input options set with AddReaderWithOptions or applied to the builder
+	options inputOptions
 }
 
 // NewBuilder creates a new database builder.
@@ -90,8 +182,11 @@ func NewBuilder() *DBBuilder {
 		readers:          make([]readerInput, 0),
 		collectedPaths:   make([]string, 0),
 		parsedTables:     make([]*table, 0),
+		derivedTables:    make([]derivedTable, 0),
+		validationRules:  make([]tableRules, 0),
 		autoSaveConfig:   nil, // Default: no auto-save
 		defaultChunkSize: chunkSize,
+		maxIdleConns:     DefaultMaxIdleConns,
 
 		// Initialize internal processors
 		validator:       newValidator(),
@@ -106,10 +201,28 @@ func NewBuilder() *DBBuilder {
 //   - Single file: AddPath("users.csv")
 //   - Compressed: AddPath("data.tsv.gz")
 //   - Directory: AddPath("/data/") // loads all CSV/TSV/LTSV files
+//   - Glob pattern: AddPath("logs/2024-*.csv") // see AddGlob
+//   - S3 object: AddPath("s3://bucket/data.csv.gz") // see AddS3
+//   - Cloud Storage object or prefix: AddPath("gs://bucket/exports/") // see AddGCS
+//   - URL: AddPath("https://example.com/data.csv") // see AddURL
 //
 // Returns self for chaining.
 func (b *DBBuilder) AddPath(path string) *DBBuilder {
+	if isS3URI(path) {
+		b.remotes = append(b.remotes, newS3InputFromURI(path))
+		b.inputOrder = append(b.inputOrder, inputRemote)
+		return b
+	}
+	if isGCSURI(path) {
+		b.remotes = append(b.remotes, newGCSInputFromURI(path))
+		b.inputOrder = append(b.inputOrder, inputRemote)
+		return b
+	}
+	if isHTTPURL(path) {
+		return b.AddURL(path)
+	}
 	b.paths = append(b.paths, path)
+	b.inputOrder = append(b.inputOrder, inputPath)
 	return b
 }
 
@@ -121,7 +234,9 @@ func (b *DBBuilder) AddPath(path string) *DBBuilder {
 //
 // Returns self for chaining.
 func (b *DBBuilder) AddPaths(paths ...string) *DBBuilder {
-	b.paths = append(b.paths, paths...)
+	for _, path := range paths {
+		b.AddPath(path)
+	}
 	return b
 }
 
@@ -130,7 +245,7 @@ func (b *DBBuilder) AddPaths(paths ...string) *DBBuilder {
 // Parameters:
 //   - reader: Any io.Reader (file, bytes.Buffer, http.Response.Body, etc.)
 //   - tableName: Name for the SQL table (e.g., "users")
-//   - fileType: Data format (FileTypeCSV, FileTypeTSV, FileTypeLTSV, etc.)
+//   - fileType: Data format (FileTypeCSV, FileTypeTSV, FileTypeLTSV, FileTypeJSONL, etc.)
 //
 // Example:
 //
@@ -144,6 +259,7 @@ func (b *DBBuilder) AddReader(reader io.Reader, tableName string, fileType FileT
 		tableName: tableName,
 		fileType:  fileType,
 	})
+	b.inputOrder = append(b.inputOrder, inputReader)
 	return b
 }
 
@@ -163,6 +279,22 @@ func (b *DBBuilder) SetDefaultChunkSize(size int) *DBBuilder {
 	return b
 }
 
+// SetMemoryLimit stops loading when the Go heap grows beyond maxMemoryMB megabytes.
+//
+// The heap is checked once per chunk. When the limit is exceeded, Open fails with
+// an error that wraps ErrMemoryLimit instead of the process running out of memory.
+// Values of 0 or less use the default of 512 MB.
+//
+// Example:
+//
+//	builder.SetMemoryLimit(1024) // Fail the load above 1 GB of heap
+//
+// Returns self for chaining.
+func (b *DBBuilder) SetMemoryLimit(maxMemoryMB int64) *DBBuilder {
+	b.streamProcessor.memoryLimit = NewMemoryLimit(maxMemoryMB)
+	return b
+}
+
 // AddFS adds files from an embedded filesystem (go:embed).
 //
 // Automatically finds all CSV, TSV, and LTSV files in the filesystem.
@@ -177,6 +309,7 @@ func (b *DBBuilder) SetDefaultChunkSize(size int) *DBBuilder {
 // Returns self for chaining.
 func (b *DBBuilder) AddFS(filesystem fs.FS) *DBBuilder {
 	b.filesystems = append(b.filesystems, filesystem)
+	b.inputOrder = append(b.inputOrder, inputFS)
 	return b
 }
 
@@ -192,6 +325,9 @@ func (b *DBBuilder) AddFS(filesystem fs.FS) *DBBuilder {
 //	builder.AddPath("data.csv").
 //		EnableAutoSave("") // Auto-save to original file on db.Close()
 //
+// A save writes only the tables changed since the previous save, so unchanged files
+// are not rewritten. The first save into outputDir writes every table.
+//
 // Returns self for chaining.
 func (b *DBBuilder) EnableAutoSave(outputDir string, options ...DumpOptions) *DBBuilder {
 	opts := NewDumpOptions()
@@ -233,6 +369,30 @@ func (b *DBBuilder) EnableAutoSaveOnCommit(outputDir string, options ...DumpOpti
 	return b
 }
 
+// SetAutoSaveTimeout bounds how long a single auto-save may take.
+//
+// Without a timeout, db.Close() with auto-save enabled blocks until every changed table
+// has been written, which can take a long time for large datasets. With a timeout, the
+// save is aborted once it expires:
+//   - tables that were completely written before the timeout are kept
+//   - the file of the table being written is removed, so no truncated file is left
+//   - the remaining tables are not written
+//
+// The aborted save returns an error wrapping ErrAutoSaveTimeout (and context.DeadlineExceeded)
+// that lists the saved tables. On close, the database is closed regardless.
+//
+// Example:
+//
+//	builder.AddPath("data.csv").
+//		EnableAutoSave("./backup").
+//		SetAutoSaveTimeout(10 * time.Second) // Bound shutdown latency
+//
+// Returns self for chaining.
+func (b *DBBuilder) SetAutoSaveTimeout(timeout time.Duration) *DBBuilder {
+	b.autoSaveTimeout = timeout
+	return b
+}
+
 // DisableAutoSave disables automatic saving (default behavior).
 // Returns the builder for method chaining.
 func (b *DBBuilder) DisableAutoSave() *DBBuilder {
@@ -240,6 +400,136 @@ func (b *DBBuilder) DisableAutoSave() *DBBuilder {
 	return b
 }
 
+// EnableDeterministicOrder loads inputs sorted by table name instead of in the order
+// they were added.
+//
+// Tables are then created in the same order on every platform, regardless of how
+// paths, directories, readers, and embedded filesystems were combined. This keeps
+// golden-file tests of table listings and load progress from flaking.
+// Directory scans are always ordered lexically, and table listings produced by
+// filesql (e.g. GetSchema and DumpDatabase) are always sorted by name.
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableDeterministicOrder() *DBBuilder {
+	b.deterministicOrder = true
+	return b
+}
+
+// Clone returns an independent copy of the builder's configuration.
+//
+// Use it to prepare a base configuration once (chunk size, options, common
+// reference files) and derive per-request builders from it. Adding inputs or
+// changing options on the clone never affects the original, and vice versa,
+// so clones can be built and opened concurrently:
+//
+//	base := filesql.NewBuilder().
+//		AddPath("reference/countries.csv").
+//		SetDefaultChunkSize(5000)
+//
+//	// In each request handler
+//	validatedBuilder, err := base.Clone().
+//		AddReader(r.Body, "upload", filesql.FileTypeCSV).
+//		Build(ctx)
+//
+// io.Reader inputs are shared with the clone, not copied. A reader can only be
+// consumed once, so add readers to the clone rather than to the base configuration.
+func (b *DBBuilder) Clone() *DBBuilder {
+	clone := *b
+
+	clone.paths = append([]string(nil), b.paths...)
+	if b.pathTables != nil {
+		clone.pathTables = make(map[int]string, len(b.pathTables))
+		for i, table := range b.pathTables {
+			clone.pathTables[i] = table
+		}
+	}
+	if b.tableNames != nil {
+		clone.tableNames = make(map[string]string, len(b.tableNames))
+		for path, name := range b.tableNames {
+			clone.tableNames[path] = name
+		}
+	}
+	if b.pathOptions != nil {
+		clone.pathOptions = make(map[int]inputOptions, len(b.pathOptions))
+		for i, options := range b.pathOptions {
+			clone.pathOptions[i] = options
+		}
+	}
+	clone.inputOptions = append([]InputOption(nil), b.inputOptions...)
+	clone.filesystems = append([]fs.FS(nil), b.filesystems...)
+	clone.readers = append([]readerInput(nil), b.readers...)
+	clone.remotes = append([]remoteInput(nil), b.remotes...)
+	clone.httpHeader = b.httpHeader.Clone()
+	clone.collectedPaths = append([]string(nil), b.collectedPaths...)
+	clone.skippedPaths = append([]skippedPath(nil), b.skippedPaths...)
+	clone.emptyFiles = append([]string(nil), b.emptyFiles...)
+	clone.inputOrder = append([]inputKind(nil), b.inputOrder...)
+	clone.replacedInputs = append([]replacedInput(nil), b.replacedInputs...)
+	clone.references = append([]*ReferenceData(nil), b.references...)
+	clone.sqliteDBs = append([]sqliteDatabase(nil), b.sqliteDBs...)
+	clone.parsedTables = append([]*table(nil), b.parsedTables...)
+	clone.derivedTables = append([]derivedTable(nil), b.derivedTables...)
+	clone.surrogateKeys = make([]surrogateKey, len(b.surrogateKeys))
+	for i, key := range b.surrogateKeys {
+		clone.surrogateKeys[i] = surrogateKey{mappingTable: key.mappingTable, columns: append([]KeyColumn(nil), key.columns...)}
+	}
+	clone.headerMappingFiles = append([]string(nil), b.headerMappingFiles...)
+
+	clone.validationRules = make([]tableRules, len(b.validationRules))
+	for i, tr := range b.validationRules {
+		clone.validationRules[i] = tableRules{table: tr.table, rules: append([]Rule(nil), tr.rules...)}
+	}
+
+	if b.autoSaveConfig != nil {
+		config := *b.autoSaveConfig
+		clone.autoSaveConfig = &config
+	}
+
+	fileProcessor := *b.fileProcessor
+	clone.fileProcessor = &fileProcessor
+
+	streamProcessor := *b.streamProcessor
+	if b.streamProcessor.headerMapping != nil {
+		streamProcessor.headerMapping = make(map[string]string, len(b.streamProcessor.headerMapping))
+		for from, to := range b.streamProcessor.headerMapping {
+			streamProcessor.headerMapping[from] = to
+		}
+	}
+	streamProcessor.skipColumns = append([]string(nil), b.streamProcessor.skipColumns...)
+	if b.streamProcessor.droppedColumns != nil {
+		streamProcessor.droppedColumns = make(map[string][]string, len(b.streamProcessor.droppedColumns))
+		for table, columns := range b.streamProcessor.droppedColumns {
+			streamProcessor.droppedColumns[table] = append([]string(nil), columns...)
+		}
+	}
+	if b.streamProcessor.defaultValues != nil {
+		streamProcessor.defaultValues = make(map[string][]columnDefault, len(b.streamProcessor.defaultValues))
+		for table, defaults := range b.streamProcessor.defaultValues {
+			streamProcessor.defaultValues[table] = append([]columnDefault(nil), defaults...)
+		}
+	}
+	if b.streamProcessor.timeWindows != nil {
+		streamProcessor.timeWindows = make(map[string][]timeWindow, len(b.streamProcessor.timeWindows))
+		for table, windows := range b.streamProcessor.timeWindows {
+			streamProcessor.timeWindows[table] = append([]timeWindow(nil), windows...)
+		}
+	}
+	streamProcessor.fileOptions = maps.Clone(b.streamProcessor.fileOptions)
+	streamProcessor.tableNames = maps.Clone(b.streamProcessor.tableNames)
+	streamProcessor.unionTables = maps.Clone(b.streamProcessor.unionTables)
+	streamProcessor.emptyFileTables = maps.Clone(b.streamProcessor.emptyFileTables)
+	streamProcessor.nullValues = slices.Clone(b.streamProcessor.nullValues)
+	if b.streamProcessor.columnNullValues != nil {
+		streamProcessor.columnNullValues = make(map[string][]columnNullValues, len(b.streamProcessor.columnNullValues))
+		for table, columns := range b.streamProcessor.columnNullValues {
+			streamProcessor.columnNullValues[table] = slices.Clone(columns)
+		}
+	}
+	clone.streamProcessor = &streamProcessor
+
+	return &clone
+}
+
 // Build validates all configured inputs and prepares the builder for opening a database.
 // This method must be called before Open(). It performs the following operations:
 //
@@ -248,34 +538,127 @@ func (b *DBBuilder) DisableAutoSave() *DBBuilder {
 // 3. Processes embedded filesystems by converting files to streaming readers
 // 4. Validates that all files have supported extensions
 //
-// After successful validation, the builder is ready to create database connections
+// After successful validation, the returned builder is ready to create database connections
 // with Open(). The context is used for file operations and can be used for cancellation.
 //
-// Returns the same builder instance for method chaining, or an error if validation fails.
+// Build validates a snapshot of the configuration (see Clone) and never modifies the
+// receiver, so the same builder can be built repeatedly or from multiple goroutines at
+// once. Configuration methods such as AddPath are not safe for concurrent use; finish
+// configuring the builder before sharing it between goroutines.
+//
+// Because the receiver is left as it is, call Open on the returned builder: Open and
+// StartLoad on a builder that did not come from Build return an ErrCodeInvalidConfig
+// error.
+//
+// Build opens the files of filesystems added with AddFS. Open and StartLoad close them
+// once they are loaded, and they are closed when ctx is done before that, so there is
+// nothing to clean up; Open then returns the error of ctx.
+//
+// Returns a validated builder, or an error if validation fails.
 func (b *DBBuilder) Build(ctx context.Context) (*DBBuilder, error) {
+	clone := b.Clone()
+	validatedBuilder, err := clone.build(ctx)
+	if err != nil {
+		clone.resources.release()
+		return nil, withErrorCode(err, ErrCodeInvalidConfig)
+	}
+	validatedBuilder.resources.bind(ctx)
+	return validatedBuilder, nil
+}
+
+// build validates the configuration and collects the inputs for Build
+func (b *DBBuilder) build(ctx context.Context) (*DBBuilder, error) {
 	// Validate that we have at least one input
-	if len(b.paths) == 0 && len(b.filesystems) == 0 && len(b.readers) == 0 {
-		return nil, errors.New("at least one path must be provided")
+	if len(b.paths) == 0 && len(b.filesystems) == 0 && len(b.readers) == 0 && len(b.remotes) == 0 {
+		return nil, newCodedError(ErrCodeInvalidConfig, "at least one path must be provided")
 	}
 
 	// Use validator to validate auto-save config
 	if err := b.validator.validateAutoSaveConfig(b.autoSaveConfig); err != nil {
 		return nil, err
 	}
+	if err := validateAutoSaveInterval(b.autoSaveInterval, b.autoSaveConfig); err != nil {
+		return nil, err
+	}
+	if err := validateAutoSaveRowThreshold(b.autoSaveRowThreshold, b.autoSaveConfig); err != nil {
+		return nil, err
+	}
+	if err := validateLazyLoading(b.lazyLoading, b.autoSaveConfig); err != nil {
+		return nil, err
+	}
+	if err := validatePoolSize(b.maxOpenConns, b.maxIdleConns); err != nil {
+		return nil, err
+	}
+	if err := b.validatePersistentDB(); err != nil {
+		return nil, err
+	}
+	if err := b.validateWatch(); err != nil {
+		return nil, err
+	}
+	if err := validateReferenceData(b.references); err != nil {
+		return nil, err
+	}
+	if err := validateSQLiteDBs(b.sqliteDBs, b.references); err != nil {
+		return nil, err
+	}
+	if err := validateSurrogateKeys(b.surrogateKeys); err != nil {
+		return nil, err
+	}
+	if err := b.validateInputOptions(); err != nil {
+		return nil, err
+	}
+	if err := validateTimeWindows(b.streamProcessor.timeWindows); err != nil {
+		return nil, err
+	}
+
+	if err := b.validator.validateTempDir(b.streamProcessor.tempDir); err != nil {
+		return nil, err
+	}
+	if b.streamProcessor.noTempFiles && b.streamProcessor.tempDir != "" {
+		return nil, newCodedError(ErrCodeInvalidConfig, "DisableTempFiles cannot be combined with WithTempDir")
+	}
+
+	if err := b.loadHeaderMappingFiles(); err != nil {
+		return nil, err
+	}
 
 	// Use file processor to collect paths
-	collectedPaths, err := b.fileProcessor.collectFilesFromPaths(b.paths)
+	collected, err := b.fileProcessor.collectFilesFromPaths(b.paths)
 	if err != nil {
 		return nil, err
 	}
-	b.collectedPaths = collectedPaths
+	if err := b.applyEmptyFilePolicy(collected); err != nil {
+		return nil, err
+	}
+	if err := b.resolveTableNames(collected); err != nil {
+		return nil, err
+	}
+	b.collectedPaths = collected.paths
+	b.skippedPaths = collected.skipped
 
 	// Use file processor to handle filesystems
 	fsReaders, err := b.fileProcessor.processFilesystemsToReaders(ctx, b.filesystems)
 	if err != nil {
 		return nil, err
 	}
-	b.readers = append(b.readers, fsReaders...)
+	b.resources = newBuildResources(fsReaders...)
+	remoteReaders, remoteOrigins, err := b.remoteReaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if b.sourcePriority {
+		b.readers, err = b.applySourcePriority(collected, b.readers, fsReaders, remoteReaders, remoteOrigins)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, readers := range fsReaders {
+			b.readers = append(b.readers, readers...)
+		}
+		b.readers = append(b.readers, remoteReaders...)
+	}
+
+	b.resolveInputOptions(collected)
 
 	// Use validator to validate reader inputs
 	for _, readerInput := range b.readers {
@@ -289,6 +672,23 @@ func (b *DBBuilder) Build(ctx context.Context) (*DBBuilder, error) {
 		return nil, err
 	}
 
+	for _, derived := range b.derivedTables {
+		if err := b.validator.validateDerivedTable(derived); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tr := range b.validationRules {
+		if err := b.validator.validateTableRules(tr); err != nil {
+			return nil, err
+		}
+	}
+
+	// Open and StartLoad only read the inputs, so one validated builder can be opened
+	// from several goroutines
+	b.prepareInputs()
+
+	b.validated = true
 	return b, nil
 }
 
@@ -306,58 +706,239 @@ func (b *DBBuilder) Build(ctx context.Context) (*DBBuilder, error) {
 //
 // Returns a *sql.DB connection or an error if the database cannot be created.
 func (b *DBBuilder) Open(ctx context.Context) (*sql.DB, error) {
+	db, err := b.open(ctx)
+	if err != nil {
+		return nil, withErrorCode(err, ErrCodeLoadFailed)
+	}
+	return db, nil
+}
+
+// open creates the database and loads every input for Open
+func (b *DBBuilder) open(ctx context.Context) (*sql.DB, error) {
+	if err := b.resources.claim(); err != nil {
+		return nil, err
+	}
+	defer b.resources.release()
+
+	if err := b.validator.validateBuilt(b.validated); err != nil {
+		return nil, err
+	}
 	// Use validator to validate inputs availability
 	if err := b.validator.validateInputsAvailable(b.collectedPaths, b.readers); err != nil {
 		return nil, err
 	}
 
-	// Use file processor to deduplicate compressed files
-	b.collectedPaths = b.fileProcessor.deduplicateCompressedFiles(b.collectedPaths)
+	references, err := loadReferences(ctx, b.references)
+	if err != nil {
+		return nil, err
+	}
+	references = append(references, b.attachedSQLiteDBs()...)
 
-	db, err := b.createInMemoryDatabase()
+	fresh, sources, err := b.checkPersistentDB(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Use stream processor for all streaming operations (now includes XLSX support)
-	if err := b.streamProcessor.streamAllFilesToDatabase(ctx, db, b.collectedPaths); err != nil {
-		_ = db.Close() // Ignore close error during error handling
+	watcher := b.newTableWatcher()
+	db, autoSave, err := b.createDatabase(references, watcher)
+	if err != nil {
 		return nil, err
 	}
 
-	if err := b.streamProcessor.streamAllReadersToDatabase(ctx, db, b.readers); err != nil {
+	if !fresh {
+		if err := b.loadPersistentInputs(ctx, db, sources); err != nil {
+			_ = db.Close() // Ignore close error during error handling
+			return nil, err
+		}
+	}
+	if err := checkReferenceTables(ctx, db, b.references); err != nil {
+		_ = db.Close() // Ignore close error during error handling
+		return nil, err
+	}
+	if err := checkSQLiteDBTables(ctx, db, b.sqliteDBs, b.references); err != nil {
 		_ = db.Close() // Ignore close error during error handling
 		return nil, err
 	}
 
 	if err := b.validateDatabaseConnection(ctx, db); err != nil {
-		_ = db.Close() // Ignore close error during error handling
 		return nil, err
 	}
 
-	db, err = b.setupAutoSaveIfNeeded(ctx, db)
-	if err != nil {
+	if err := autoSave.start(ctx); err != nil {
+		_ = db.Close() // Ignore close error during error handling
+		return nil, fmt.Errorf("failed to start auto-save: %w", err)
+	}
+	if err := watcher.start(db); err != nil {
+		_ = db.Close() // Ignore close error during error handling
 		return nil, err
 	}
 
 	return db, nil
 }
 
+// prepareInputs deduplicates collected paths and applies the configured load order for build
+func (b *DBBuilder) prepareInputs() {
+	// Use file processor to deduplicate compressed files
+	if b.streamProcessor.tableCollision == TableCollisionDefault {
+		b.collectedPaths = b.fileProcessor.deduplicateCompressedFilesBy(b.collectedPaths, func(path string) string {
+			// Every file of AddPathsAsTable is loaded, except compressed copies
+			if table := b.streamProcessor.fileTableName(path); !b.streamProcessor.isUnionTable(table) {
+				return table
+			}
+			return filepath.Join(filepath.Dir(path), tableFromFilePath(path))
+		})
+	} else {
+		b.collectedPaths = b.fileProcessor.deduplicateCompressedCopies(b.collectedPaths)
+	}
+
+	if b.deterministicOrder {
+		sort.SliceStable(b.collectedPaths, func(i, j int) bool {
+			return b.streamProcessor.fileTableName(b.collectedPaths[i]) < b.streamProcessor.fileTableName(b.collectedPaths[j])
+		})
+		sort.SliceStable(b.readers, func(i, j int) bool {
+			return b.readers[i].tableName < b.readers[j].tableName
+		})
+	}
+}
+
+// loadInputs streams every collected file path and reader input into db using sp.
+func (b *DBBuilder) loadInputs(ctx context.Context, db *sql.DB, sp *streamProcessor) error {
+	var recorder *loadMetricsRecorder
+	if b.loadMetrics {
+		recorder = newLoadMetricsRecorder(sp.observer)
+		sp = sp.withObserver(recorder)
+	}
+	var summary *loadSummaryRecorder
+	if b.loadSummary {
+		summary = newLoadSummaryRecorder(sp.observer, b.clock)
+		sp = sp.withObserver(summary)
+	}
+	var schema *schemaMetadataRecorder
+	if b.schemaMetadata {
+		schema = newSchemaMetadataRecorder(sp.observer, sp.headerMapping, b.headerlessInputs())
+		sp = sp.withObserver(schema)
+	}
+
+	for _, skipped := range b.skippedPaths {
+		message := fmt.Sprintf("skipped %s, which is the same file as %s", skipped.path, skipped.duplicateOf)
+		if err := recordLoadWarning(ctx, db, tableFromFilePath(skipped.path), message); err != nil {
+			return err
+		}
+	}
+	if err := b.recordEmptyFileWarnings(ctx, db); err != nil {
+		return err
+	}
+	for _, replaced := range b.replacedInputs {
+		message := fmt.Sprintf("skipped %s, which is replaced by %s", replaced.input, replaced.replacedBy)
+		if err := recordLoadWarning(ctx, db, replaced.table, message); err != nil {
+			return err
+		}
+	}
+
+	paths, lazy, err := b.registerLazyTables(ctx, db)
+	if err != nil {
+		return err
+	}
+	if b.progressHandler != nil {
+		totalBytes := sp.inputSizes(paths, b.readers)
+		sp = sp.withObserver(newProgressReporter(sp.observer, b.clock, b.progressHandler, len(paths)+len(b.readers), totalBytes))
+	}
+	// Use stream processor for all streaming operations (now includes XLSX support).
+	// Reader inputs are loaded after failed file paths, so the error lists every failed input.
+	filesErr := sp.streamAllFilesToDatabase(ctx, db, paths)
+	if filesErr != nil && stopsLoad(filesErr) {
+		return filesErr
+	}
+	readersErr := sp.streamAllReadersToDatabase(ctx, db, b.readers)
+	if err := errors.Join(filesErr, readersErr); err != nil {
+		return err
+	}
+	if lazy != nil {
+		if err := lazy.resume(ctx); err != nil {
+			return err
+		}
+	}
+	if recorder != nil {
+		if err := recorder.save(ctx, db); err != nil {
+			return err
+		}
+	}
+	if summary != nil {
+		if err := summary.save(ctx, db); err != nil {
+			return err
+		}
+	}
+	if schema != nil {
+		if err := schema.save(ctx, db); err != nil {
+			return err
+		}
+	}
+	if err := b.createSurrogateKeys(ctx, db); err != nil {
+		return err
+	}
+	if err := b.createDerivedTables(ctx, db); err != nil {
+		return err
+	}
+	return b.runValidation(ctx, db)
+}
+
+// createDatabase creates the database handle that inputs are loaded into.
+// When auto-save is enabled the handle is backed by an autoSaveConnector so that
+// data only has to be loaded once, and it also returns the connector to start once the
+// inputs are loaded; otherwise that is nil. The references are attached to every connection,
+// and watcher, when not nil, is stopped when the database is closed.
+func (b *DBBuilder) createDatabase(references []attachedReference, watcher *tableWatcher) (*sql.DB, *autoSaveConnector, error) {
+	if b.autoSaveConfig == nil || !b.autoSaveConfig.enabled {
+		db, err := b.createInMemoryDatabase(watcher, references...)
+		return db, nil, err
+	}
+
+	memory, err := b.newDatabaseConnector(references)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create SQLite connection for auto-save: %w", err)
+	}
+	memory.watcher = watcher
+
+	config := *b.autoSaveConfig
+	config.timeout = b.autoSaveTimeout
+	config.clock = b.clock
+	if config.options.FileSystem == nil {
+		config.options.FileSystem = b.streamProcessor.inputFS
+	}
+
+	connector := newAutoSaveConnector(memory, &config, b.collectOriginalPaths())
+	connector.writeBack = b.collectWriteBackTargets()
+	if b.autoSaveInterval > 0 || b.autoSaveRowThreshold > 0 {
+		connector.periodic = newPeriodicAutoSave(connector, b.autoSaveInterval, b.clock)
+		connector.periodic.rowThreshold = b.autoSaveRowThreshold
+	}
+	db := sql.OpenDB(connector)
+	b.configurePool(db)
+	return db, connector, nil
+}
+
 // deduplicateCompressedFiles removes compressed duplicates when uncompressed versions exist.
 // DEPRECATED: This method has been moved to fileProcessor.deduplicateCompressedFiles()
 func (b *DBBuilder) deduplicateCompressedFiles(files []string) []string {
 	return b.fileProcessor.deduplicateCompressedFiles(files)
 }
 
-// createInMemoryDatabase creates a new in-memory SQLite database connection.
-func (b *DBBuilder) createInMemoryDatabase() (*sql.DB, error) {
-	sqliteDriver := &sqlite.Driver{}
-	conn, err := sqliteDriver.Open(":memory:")
+// createInMemoryDatabase creates a new in-memory SQLite database, or opens the database
+// file of WithPersistentDB, with the references attached to every connection. watcher,
+// when not nil, is stopped when the database is closed.
+func (b *DBBuilder) createInMemoryDatabase(watcher *tableWatcher, references ...attachedReference) (*sql.DB, error) {
+	memory, err := b.newDatabaseConnector(references)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create in-memory database: %w", err)
+		return nil, err
+	}
+	memory.watcher = watcher
+	if b.lazyLoading {
+		memory.lazy = newLazyTables(memory, b.streamProcessor)
 	}
 
-	return sql.OpenDB(&directConnector{conn: conn}), nil
+	db := sql.OpenDB(memory)
+	b.configurePool(db)
+	return db, nil
 }
 
 // validateDatabaseConnection validates the database connection is working.
@@ -376,43 +957,6 @@ func (b *DBBuilder) validateDatabaseConnection(ctx context.Context, db *sql.DB)
 	return nil
 }
 
-// setupAutoSaveIfNeeded sets up auto-save functionality if enabled.
-func (b *DBBuilder) setupAutoSaveIfNeeded(ctx context.Context, db *sql.DB) (*sql.DB, error) {
-	if b.autoSaveConfig == nil || !b.autoSaveConfig.enabled {
-		return db, nil
-	}
-
-	if err := db.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close intermediate database: %w", err)
-	}
-
-	sqliteDriver := &sqlite.Driver{}
-	freshConn, err := sqliteDriver.Open(":memory:")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create fresh SQLite connection for auto-save: %w", err)
-	}
-
-	connector := &autoSaveConnector{
-		sqliteConn:     freshConn,
-		autoSaveConfig: b.autoSaveConfig,
-		originalPaths:  b.collectOriginalPaths(),
-	}
-	db = sql.OpenDB(connector)
-
-	// Use stream processor for all streaming operations (now includes XLSX support)
-	if err := b.streamProcessor.streamAllFilesToDatabase(ctx, db, b.collectedPaths); err != nil {
-		_ = db.Close() // Ignore close error during error handling
-		return nil, err
-	}
-
-	if err := b.streamProcessor.streamAllReadersToDatabase(ctx, db, b.readers); err != nil {
-		_ = db.Close() // Ignore close error during error handling
-		return nil, err
-	}
-
-	return db, nil
-}
-
 // processFSToReaders processes all supported files from an fs.FS and creates ReaderInput
 
 func (b *DBBuilder) processFSToReaders(_ context.Context, filesystem fs.FS) ([]readerInput, error) {
@@ -543,7 +1087,7 @@ func (b *DBBuilder) streamXLSXFileToSQLite(ctx context.Context, db *sql.DB, read
 		}
 
 		// Create table name: filename_sheetname
-		tableName := fmt.Sprintf("%s_%s", baseTableName, sanitizeTableName(sheetName))
+		tableName := sheetTableName(baseTableName, sheetName)
 
 		// Check if table already exists
 		var tableExists int
@@ -556,7 +1100,7 @@ func (b *DBBuilder) streamXLSXFileToSQLite(ctx context.Context, db *sql.DB, read
 		}
 
 		if tableExists > 0 {
-			return fmt.Errorf("table '%s' already exists, duplicate table names are not allowed", tableName)
+			return newCodedError(ErrCodeDuplicateTable, "table '%s' already exists, duplicate table names are not allowed", tableName)
 		}
 
 		// Process sheet data
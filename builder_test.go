@@ -2,6 +2,7 @@ package filesql
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"database/sql"
 	"embed"
@@ -13,6 +14,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"testing/fstest"
 	"time"
@@ -468,8 +470,26 @@ func TestDBBuilder_Open(t *testing.T) {
 			_ = db.Close()
 		}
 		assert.Error(t, err, "Open() without Build() should return error")
-		expectedErrMsg := "no valid input files found, did you call Build()?"
+		expectedErrMsg := "call Open on the builder returned by Build()"
 		assert.Contains(t, err.Error(), expectedErrMsg, "error message should mention Build() requirement")
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+
+	t.Run("open on the receiver of build should fail", func(t *testing.T) {
+		tempDir := t.TempDir()
+		csvFile := filepath.Join(tempDir, "test.csv")
+		require.NoError(t, os.WriteFile(csvFile, []byte("col1\nval1\n"), 0600))
+
+		builder := NewBuilder().AddPath(csvFile)
+		_, err := builder.Build(ctx)
+		require.NoError(t, err)
+		db, err := builder.Open(ctx)
+		if db != nil {
+			_ = db.Close()
+		}
+		require.Error(t, err, "Build() leaves its receiver unvalidated")
+		assert.Contains(t, err.Error(), "call Open on the builder returned by Build()")
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
 	})
 
 	t.Run("successful open with CSV file", func(t *testing.T) {
@@ -1895,3 +1915,302 @@ func TestStreamXLSXFileToSQLite(t *testing.T) {
 		}
 	})
 }
+
+func TestDBBuilder_EnableDeterministicOrder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inputs are loaded sorted by table name", func(t *testing.T) {
+		t.Parallel()
+
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "b_orders.csv"), []byte("id\n1\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "c_items.csv"), []byte("id\n1\n"), 0600))
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "d_reader", FileTypeCSV).
+			AddPath(filepath.Join(tmpDir, "c_items.csv")).
+			AddReader(strings.NewReader("id\n1\n"), "a_reader", FileTypeCSV).
+			AddPath(filepath.Join(tmpDir, "b_orders.csv")).
+			EnableDeterministicOrder().
+			Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+
+		// Files are loaded before readers, each group sorted by table name
+		assert.Equal(t, []string{"b_orders", "c_items", "a_reader", "d_reader"}, job.Progress().TablesLoaded)
+	})
+
+	t.Run("table listings are sorted by name", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "zeta", FileTypeCSV).
+			AddReader(strings.NewReader("id\n1\n"), "alpha", FileTypeCSV).
+			Build(ctx)
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+		defer db.Close()
+
+		tableNames, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alpha", "zeta"}, tableNames)
+	})
+
+	t.Run("compressed duplicates are removed without reordering files", func(t *testing.T) {
+		t.Parallel()
+
+		fp := newFileProcessor(DefaultChunkSize)
+		files := []string{"z.csv.gz", "b.csv", "a.csv", "z.csv", "c.tsv.gz"}
+		assert.Equal(t, []string{"b.csv", "a.csv", "z.csv", "c.tsv.gz"}, fp.deduplicateCompressedFiles(files))
+	})
+}
+
+func TestDBBuilder_Clone(t *testing.T) {
+	t.Parallel()
+
+	t.Run("changes to the clone do not affect the original", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			WithHeaderMapping(map[string]string{"name": "full_name"}).
+			WithValidation("sample", NotNull("id")).
+			SetDefaultChunkSize(123)
+
+		clone := base.Clone().
+			AddPath(filepath.Join("testdata", "users.csv")).
+			WithHeaderMapping(map[string]string{"age": "years"}).
+			WithValidation("sample", Unique("id")).
+			EnableAutoSave(t.TempDir())
+
+		assert.Equal(t, []string{filepath.Join("testdata", "sample.csv")}, base.paths)
+		assert.Equal(t, map[string]string{"name": "full_name"}, base.streamProcessor.headerMapping)
+		assert.Len(t, base.validationRules[0].rules, 1)
+		assert.Nil(t, base.autoSaveConfig)
+
+		assert.Len(t, clone.paths, 2)
+		assert.Equal(t, map[string]string{"name": "full_name", "age": "years"}, clone.streamProcessor.headerMapping)
+		assert.Equal(t, 123, clone.defaultChunkSize)
+		assert.NotSame(t, base.streamProcessor, clone.streamProcessor)
+		assert.NotSame(t, base.fileProcessor, clone.fileProcessor)
+	})
+
+	t.Run("null values set on the clone do not affect the original", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewBuilder().
+			WithNullValues("N/A").
+			WithColumnNullValues("products", "price", "-").
+			WithColumnNullValues("products", "note")
+
+		clone := base.Clone().
+			WithNullValues("NULL").
+			WithColumnNullValues("products", "price", "?").
+			WithColumnNullValues("orders", "total", "-")
+
+		assert.Equal(t, []string{"N/A"}, base.streamProcessor.nullValues)
+		assert.Equal(t, map[string][]columnNullValues{
+			"products": {{column: "price", values: []string{"-"}}, {column: "note"}},
+		}, base.streamProcessor.columnNullValues)
+		assert.Equal(t, []string{"NULL"}, clone.streamProcessor.nullValues)
+		assert.Equal(t, []columnNullValues{{column: "note"}, {column: "price", values: []string{"?"}}},
+			clone.streamProcessor.columnNullValues["products"])
+	})
+
+	t.Run("collected inputs of a validated builder are copied", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		a := filepath.Join(dir, "a.csv")
+		b := filepath.Join(dir, "b.csv")
+		empty := filepath.Join(dir, "empty", "c.csv")
+		require.NoError(t, os.WriteFile(a, []byte("id\n1\n"), 0600))
+		require.NoError(t, os.WriteFile(b, []byte("id\n2\n"), 0600))
+		require.NoError(t, os.Mkdir(filepath.Dir(empty), 0750))
+		require.NoError(t, os.WriteFile(empty, nil, 0600))
+
+		validatedBuilder, err := NewBuilder().
+			AddPathsAsTable("ids", a).
+			AddPathWithOptions(b, WithDelimiter(';')).
+			AddPath(filepath.Dir(empty)).
+			WithEmptyFilePolicy(EmptyFileCreateTable).
+			Build(context.Background())
+		require.NoError(t, err)
+		sp := validatedBuilder.streamProcessor
+		require.NotEmpty(t, sp.fileOptions)
+		require.NotEmpty(t, sp.tableNames)
+		require.NotEmpty(t, sp.unionTables)
+		require.NotEmpty(t, sp.emptyFileTables)
+
+		clone := validatedBuilder.Clone()
+		clone.streamProcessor.fileOptions[a] = inputOptions{delimiter: '|'}
+		clone.streamProcessor.tableNames[b] = "renamed"
+		clone.streamProcessor.unionTables["renamed"] = true
+		clone.streamProcessor.emptyFileTables[a] = true
+
+		assert.NotContains(t, sp.fileOptions, a)
+		assert.NotContains(t, sp.tableNames, b)
+		assert.NotContains(t, sp.unionTables, "renamed")
+		assert.NotContains(t, sp.emptyFileTables, a)
+	})
+
+	t.Run("clones can be built and opened concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewBuilder().AddPath(filepath.Join("testdata", "sample.csv"))
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 8)
+		for i := range 8 {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				ctx := context.Background()
+				tableName := fmt.Sprintf("upload%d", i)
+				validatedBuilder, err := base.Clone().
+					AddReader(strings.NewReader("id\n1\n"), tableName, FileTypeCSV).
+					Build(ctx)
+				if err != nil {
+					errs <- err
+					return
+				}
+				db, err := validatedBuilder.Open(ctx)
+				if err != nil {
+					errs <- err
+					return
+				}
+				defer db.Close()
+
+				tableNames, err := getSQLiteTableNames(db)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if len(tableNames) != 2 {
+					errs <- fmt.Errorf("unexpected tables %v", tableNames)
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Empty(t, base.readers)
+	})
+}
+
+func TestDBBuilder_ConcurrentBuild(t *testing.T) {
+	t.Parallel()
+
+	t.Run("build does not modify the receiver", func(t *testing.T) {
+		t.Parallel()
+
+		builder := NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			AddFS(fstest.MapFS{"users.csv": &fstest.MapFile{Data: []byte("id,name\n1,alice\n")}})
+
+		ctx := context.Background()
+		for range 2 {
+			validatedBuilder, err := builder.Build(ctx)
+			require.NoError(t, err)
+			assert.NotSame(t, builder, validatedBuilder)
+			assert.Len(t, validatedBuilder.readers, 1, "fs inputs should be collected once per build")
+		}
+		assert.Empty(t, builder.readers)
+		assert.Empty(t, builder.collectedPaths)
+	})
+
+	t.Run("the same builder can be built from multiple goroutines", func(t *testing.T) {
+		t.Parallel()
+
+		builder := NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			AddFS(fstest.MapFS{"users.csv": &fstest.MapFile{Data: []byte("id,name\n1,alice\n2,bob\n")}}).
+			WithHeaderMapping(map[string]string{"name": "full_name"})
+
+		ctx := context.Background()
+		const goroutines = 8
+		var wg sync.WaitGroup
+		errs := make([]error, goroutines)
+		counts := make([]int, goroutines)
+		for i := range goroutines {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				validatedBuilder, err := builder.Build(ctx)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				db, err := validatedBuilder.Open(ctx)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				defer db.Close()
+				errs[i] = db.QueryRowContext(ctx, "SELECT COUNT(full_name) FROM users").Scan(&counts[i])
+			}()
+		}
+		wg.Wait()
+
+		for i := range goroutines {
+			require.NoError(t, errs[i])
+			assert.Equal(t, 2, counts[i])
+		}
+	})
+
+	t.Run("the same validated builder can be opened from multiple goroutines", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b.csv"), []byte("id\n1\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.csv"), []byte("id\n1\n2\n"), 0600))
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		_, err := gz.Write([]byte("id\n9\n"))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.csv.gz"), compressed.Bytes(), 0600))
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().AddPath(dir).EnableDeterministicOrder().Build(ctx)
+		require.NoError(t, err)
+
+		const goroutines = 8
+		var wg sync.WaitGroup
+		errs := make([]error, goroutines)
+		counts := make([]int, goroutines)
+		for i := range goroutines {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				db, err := validatedBuilder.Open(ctx)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				defer db.Close()
+				errs[i] = db.QueryRowContext(ctx, "SELECT (SELECT COUNT(*) FROM a) + (SELECT COUNT(*) FROM b)").Scan(&counts[i])
+			}()
+		}
+		wg.Wait()
+
+		for i := range goroutines {
+			require.NoError(t, errs[i])
+			// The uncompressed a.csv is loaded instead of a.csv.gz
+			assert.Equal(t, 3, counts[i])
+		}
+		assert.Equal(t, []string{filepath.Join(dir, "a.csv"), filepath.Join(dir, "b.csv")}, validatedBuilder.collectedPaths)
+	})
+}
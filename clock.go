@@ -0,0 +1,73 @@
+package filesql
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time used by filesql.
+//
+// By default, filesql uses the system clock. Inject another implementation with
+// DBBuilder.WithClock to control time-based behavior such as the auto-save timeout
+// (SetAutoSaveTimeout) deterministically in tests, without sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc waits for the duration to elapse and then calls f. The returned stop
+	// function prevents the call and reports whether it did, like time.Timer.Stop.
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+// NewSystemClock returns the Clock backed by the time package, which is the default.
+func NewSystemClock() Clock {
+	return systemClock{}
+}
+
+// systemClock implements Clock with the time package
+type systemClock struct{}
+
+// Now implements Clock
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc implements Clock
+func (systemClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+// WithClock sets the clock used by the database.
+//
+// The auto-save timeout set with SetAutoSaveTimeout is measured with clock, so a test
+// can expire it at a chosen point of the save, for example from a FileSystem
+// injected with WithFileSystem that simulates a slow disk.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("data.csv").
+//		EnableAutoSave("./backup").
+//		SetAutoSaveTimeout(10 * time.Second).
+//		WithClock(fakeClock)
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithClock(clock Clock) *DBBuilder {
+	b.clock = clock
+	return b
+}
+
+// withClockTimeout returns a copy of parent that is canceled once timeout has elapsed
+// on clock. Like context.WithTimeout, context.Cause of the expired context is
+// context.DeadlineExceeded.
+func withClockTimeout(parent context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if clock == nil {
+		return context.WithTimeout(parent, timeout)
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	stop := clock.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
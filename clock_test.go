@@ -0,0 +1,193 @@
+package filesql
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock that only moves when advanced
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	deadline time.Time
+	f        func()
+	stopped  bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) func() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{deadline: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		wasActive := !timer.stopped
+		timer.stopped = true
+		return wasActive
+	}
+}
+
+// advance moves the clock forward and calls the functions of expired timers
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var expired []func()
+	for _, timer := range c.timers {
+		if !timer.stopped && !timer.deadline.After(c.now) {
+			timer.stopped = true
+			expired = append(expired, timer.f)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, f := range expired {
+		f()
+	}
+}
+
+// slowFileSystem is a FileSystem whose file creation takes delay on clock
+type slowFileSystem struct {
+	*memoryFileSystem
+	clock *fakeClock
+	delay time.Duration
+}
+
+func (s slowFileSystem) Create(name string) (io.WriteCloser, error) {
+	s.clock.advance(s.delay)
+	return s.memoryFileSystem.Create(name)
+}
+
+func TestWithClockTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("expires when the clock passes the timeout", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		ctx, cancel := withClockTimeout(context.Background(), clock, time.Minute)
+		defer cancel()
+
+		clock.advance(59 * time.Second)
+		require.NoError(t, ctx.Err())
+
+		clock.advance(time.Second)
+		require.Error(t, ctx.Err())
+		assert.ErrorIs(t, context.Cause(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("cancel stops the timer", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		ctx, cancel := withClockTimeout(context.Background(), clock, time.Minute)
+		cancel()
+		clock.advance(time.Hour)
+
+		assert.ErrorIs(t, context.Cause(ctx), context.Canceled)
+	})
+
+	t.Run("nil clock uses the system clock", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := withClockTimeout(context.Background(), nil, time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), context.DeadlineExceeded)
+	})
+}
+
+func TestSystemClock(t *testing.T) {
+	t.Parallel()
+
+	clock := NewSystemClock()
+	before := time.Now()
+	assert.False(t, clock.Now().Before(before))
+
+	called := make(chan struct{})
+	clock.AfterFunc(time.Millisecond, func() { close(called) })
+	<-called
+
+	stop := clock.AfterFunc(time.Hour, func() { t.Error("stopped timer fired") })
+	assert.True(t, stop())
+}
+
+func TestDBBuilder_WithClock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("auto-save times out on the injected clock", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fsys := slowFileSystem{memoryFileSystem: newMemoryFileSystem(), clock: clock, delay: 10 * time.Second}
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "a", FileTypeCSV).
+			AddReader(strings.NewReader("id\n2\n"), "b", FileTypeCSV).
+			EnableAutoSave("backup").
+			SetAutoSaveTimeout(5 * time.Second).
+			WithFileSystem(fsys).
+			WithClock(clock).
+			Build(ctx)
+		require.NoError(t, err)
+
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+
+		err = db.Close()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrAutoSaveTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// The disk was too slow for the second table to be started
+		_, ok := fsys.content(filepath.Join("backup", "b.csv"))
+		assert.False(t, ok)
+	})
+
+	t.Run("auto-save within the timeout succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		fsys := slowFileSystem{memoryFileSystem: newMemoryFileSystem(), clock: clock, delay: time.Second}
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "a", FileTypeCSV).
+			AddReader(strings.NewReader("id\n2\n"), "b", FileTypeCSV).
+			EnableAutoSave("backup").
+			SetAutoSaveTimeout(5 * time.Second).
+			WithFileSystem(fsys).
+			WithClock(clock).
+			Build(ctx)
+		require.NoError(t, err)
+
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		content, ok := fsys.content(filepath.Join("backup", "b.csv"))
+		require.True(t, ok)
+		assert.Equal(t, "id\n2\n", content)
+	})
+}
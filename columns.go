@@ -0,0 +1,437 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// reorderTablePrefix is prepended to the name of the table that is built while reordering columns
+const reorderTablePrefix = "_filesql_reorder_"
+
+// RenameColumn renames a column of a table.
+//
+// Indexes, triggers, and views that refer to the column are updated by SQLite, and
+// so are the column lineage (GetLineage), the recorded violations (GetViolations),
+// and the schema metadata (GetSchemaMetadata) that filesql keeps about the table.
+// DumpDatabase writes the new column name.
+//
+// Example:
+//
+//	// Give a cryptic upstream column a meaningful name before exporting
+//	if err := filesql.RenameColumn(db, "orders", "fld_17", "customer_id"); err != nil {
+//		return err
+//	}
+func RenameColumn(db *sql.DB, table, oldName, newName string) error {
+	return RenameColumnContext(context.Background(), db, table, oldName, newName)
+}
+
+// RenameColumnContext is like RenameColumn but uses ctx for the statements it runs.
+//
+// Returns an error with ErrCodeNotFound if the table or the column does not exist,
+// ErrCodeDuplicateColumn if the table already has a column named newName, and
+// ErrCodeInvalidConfig if newName is empty.
+func RenameColumnContext(ctx context.Context, db *sql.DB, table, oldName, newName string) error {
+	if strings.TrimSpace(newName) == "" {
+		return newCodedError(ErrCodeInvalidConfig, "new column name must not be empty")
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	columns, err := tableColumnDefinitions(ctx, conn, table)
+	if err != nil {
+		return err
+	}
+	if _, ok := findColumn(columns, oldName); !ok {
+		return newCodedError(ErrCodeNotFound, "column %s does not exist in table %s", oldName, table)
+	}
+	if _, ok := findColumn(columns, newName); ok && !strings.EqualFold(oldName, newName) {
+		return newCodedError(ErrCodeDuplicateColumn, "%w: %s already exists in table %s", errDuplicateColumnName, newName, table)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is a no-op
+
+	query := fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(table), quoteIdentifier(oldName), quoteIdentifier(newName))
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to rename column %s of table %s: %w", oldName, table, err)
+	}
+	if err := renameColumnMetadata(ctx, tx, table, oldName, newName); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit column rename: %w", err)
+	}
+	return nil
+}
+
+// ReorderColumns changes the order of the columns of a table.
+//
+// The named columns are moved to the front in the given order; the remaining columns
+// follow in their current order. SQLite cannot reorder columns with ALTER TABLE, so
+// the table is rebuilt in a transaction: a new table is created with the new column
+// order, the rows are copied with their rowids, the old table is dropped, and the new
+// table is renamed. Column types, NOT NULL constraints, default values, the primary
+// key, indexes, triggers, and the views that refer to the table are kept. Tables with
+// CHECK, UNIQUE, or foreign key constraints or with generated columns are not
+// reordered, because the rebuilt table could not keep them; reorder tables before
+// adding such constraints.
+//
+// DumpDatabase writes the columns in the new order.
+//
+// Example:
+//
+//	// Export "id" and "name" as the first two columns
+//	if err := filesql.ReorderColumns(db, "users", "id", "name"); err != nil {
+//		return err
+//	}
+func ReorderColumns(db *sql.DB, table string, order ...string) error {
+	return ReorderColumnsContext(context.Background(), db, table, order...)
+}
+
+// ReorderColumnsContext is like ReorderColumns but uses ctx for the statements it runs.
+//
+// Returns an error with ErrCodeNotFound if the table or a named column does not exist,
+// ErrCodeDuplicateColumn if a column is named more than once, and ErrCodeInvalidConfig
+// if no column is named or the table has constraints that cannot be kept.
+func ReorderColumnsContext(ctx context.Context, db *sql.DB, table string, order ...string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	columns, err := tableColumnDefinitions(ctx, conn, table)
+	if err != nil {
+		return err
+	}
+	if err := checkRebuildableTable(ctx, conn, table); err != nil {
+		return err
+	}
+	reordered, err := reorderColumnDefinitions(columns, table, order)
+	if err != nil {
+		return err
+	}
+
+	// Indexes and triggers are dropped with the old table and recreated afterwards
+	dependents, err := tableDependents(ctx, conn, table)
+	if err != nil {
+		return err
+	}
+	var withoutRowID bool
+	if err := conn.QueryRowContext(ctx, `SELECT wr FROM pragma_table_list WHERE schema = 'main' AND name = ?`, table).Scan(&withoutRowID); err != nil {
+		return fmt.Errorf("failed to read table %s: %w", table, err)
+	}
+
+	// Views that refer to the table must not be validated while it is briefly missing
+	if _, err := conn.ExecContext(ctx, "PRAGMA legacy_alter_table = ON"); err != nil {
+		return fmt.Errorf("failed to prepare table rebuild: %w", err)
+	}
+	defer conn.ExecContext(ctx, "PRAGMA legacy_alter_table = OFF") //nolint:errcheck // Restoring the default is best effort
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is a no-op
+
+	if err := rebuildTable(ctx, tx, table, reordered, withoutRowID); err != nil {
+		return err
+	}
+	for _, dependent := range dependents {
+		if _, err := tx.ExecContext(ctx, dependent); err != nil {
+			return fmt.Errorf("failed to recreate index or trigger of table %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit column reorder: %w", err)
+	}
+	return nil
+}
+
+// columnDefinition is a column of a table as reported by PRAGMA table_info
+type columnDefinition struct {
+	name         string
+	dataType     string
+	notNull      bool
+	defaultValue sql.NullString
+	// primaryKey is the 1-based position of the column in the primary key; 0 if not part of it
+	primaryKey int
+}
+
+// sql returns the column definition used in CREATE TABLE
+func (c columnDefinition) sql() string {
+	var sb strings.Builder
+	sb.WriteString(quoteIdentifier(c.name))
+	if c.dataType != "" {
+		sb.WriteString(" " + c.dataType)
+	}
+	if c.notNull {
+		sb.WriteString(" NOT NULL")
+	}
+	if c.defaultValue.Valid {
+		sb.WriteString(" DEFAULT " + c.defaultValue.String)
+	}
+	return sb.String()
+}
+
+// tableColumnDefinitions returns the columns of a table in their current order
+func tableColumnDefinitions(ctx context.Context, conn *sql.Conn, table string) ([]columnDefinition, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns for table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []columnDefinition
+	for rows.Next() {
+		var c columnDefinition
+		if err := rows.Scan(&c.name, &c.dataType, &c.notNull, &c.defaultValue, &c.primaryKey); err != nil {
+			return nil, fmt.Errorf("failed to get columns for table %s: %w", table, err)
+		}
+		columns = append(columns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get columns for table %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, newCodedError(ErrCodeNotFound, "table %s does not exist", table)
+	}
+	return columns, nil
+}
+
+// findColumn returns the index of the named column, compared case-insensitively like SQLite
+func findColumn(columns []columnDefinition, name string) (int, bool) {
+	for i, c := range columns {
+		if strings.EqualFold(c.name, name) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// reorderColumnDefinitions moves the columns of table named in order to the front
+func reorderColumnDefinitions(columns []columnDefinition, table string, order []string) ([]columnDefinition, error) {
+	if len(order) == 0 {
+		return nil, newCodedError(ErrCodeInvalidConfig, "no columns of table %s to reorder", table)
+	}
+
+	moved := make([]bool, len(columns))
+	reordered := make([]columnDefinition, 0, len(columns))
+	for _, name := range order {
+		i, ok := findColumn(columns, name)
+		if !ok {
+			return nil, newCodedError(ErrCodeNotFound, "column %s does not exist in table %s", name, table)
+		}
+		if moved[i] {
+			return nil, newCodedError(ErrCodeDuplicateColumn, "%w: %s is listed more than once", errDuplicateColumnName, name)
+		}
+		moved[i] = true
+		reordered = append(reordered, columns[i])
+	}
+	for i, c := range columns {
+		if !moved[i] {
+			reordered = append(reordered, c)
+		}
+	}
+	return reordered, nil
+}
+
+// tableDependents returns the CREATE statements of the indexes and triggers of a table
+func tableDependents(ctx context.Context, conn *sql.Conn, table string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx,
+		`SELECT sql FROM sqlite_master WHERE type IN ('index', 'trigger') AND tbl_name = ? AND sql IS NOT NULL ORDER BY rowid`,
+		table,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes and triggers of table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var statements []string
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			return nil, fmt.Errorf("failed to read indexes and triggers of table %s: %w", table, err)
+		}
+		statements = append(statements, stmt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read indexes and triggers of table %s: %w", table, err)
+	}
+	return statements, nil
+}
+
+// checkRebuildableTable returns an error if table has constraints or columns that
+// rebuildTable cannot keep: it recreates the table from its column definitions and
+// primary key only
+func checkRebuildableTable(ctx context.Context, conn *sql.Conn, table string) error {
+	var createSQL string
+	var unique, foreignKeys, generated int
+	if err := conn.QueryRowContext(ctx, `SELECT
+		(SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?1),
+		(SELECT COUNT(*) FROM pragma_index_list(?1) WHERE origin = 'u'),
+		(SELECT COUNT(*) FROM pragma_foreign_key_list(?1)),
+		(SELECT COUNT(*) FROM pragma_table_xinfo(?1) WHERE hidden IN (2, 3))`, table,
+	).Scan(&createSQL, &unique, &foreignKeys, &generated); err != nil {
+		return fmt.Errorf("failed to read constraints of table %s: %w", table, err)
+	}
+
+	var unsupported []string
+	if hasKeyword(createSQL, "CHECK") {
+		unsupported = append(unsupported, "CHECK constraints")
+	}
+	if unique > 0 {
+		unsupported = append(unsupported, "UNIQUE constraints")
+	}
+	if foreignKeys > 0 {
+		unsupported = append(unsupported, "foreign keys")
+	}
+	if generated > 0 {
+		unsupported = append(unsupported, "generated columns")
+	}
+	if len(unsupported) > 0 {
+		return newCodedError(ErrCodeInvalidConfig, "cannot reorder columns of table %s: rebuilding it would drop its %s",
+			table, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// hasKeyword reports whether the SQL statement contains keyword outside of string
+// literals, quoted identifiers, and comments. keyword is compared case-insensitively.
+func hasKeyword(statement, keyword string) bool {
+	for i := 0; i < len(statement); {
+		c := statement[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(statement, i, c)
+		case c == '[':
+			end := strings.IndexByte(statement[i:], ']')
+			if end < 0 {
+				return false
+			}
+			i += end + 1
+		case strings.HasPrefix(statement[i:], "--"):
+			end := strings.IndexByte(statement[i:], '\n')
+			if end < 0 {
+				return false
+			}
+			i += end
+		case strings.HasPrefix(statement[i:], "/*"):
+			end := strings.Index(statement[i+2:], "*/")
+			if end < 0 {
+				return false
+			}
+			i += end + 4
+		case isIdentifierStart(c):
+			end := i + 1
+			for end < len(statement) && isIdentifierPart(statement[end]) {
+				end++
+			}
+			if strings.EqualFold(statement[i:end], keyword) {
+				return true
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return false
+}
+
+// rebuildTable replaces a table with a copy whose columns are in the given order
+func rebuildTable(ctx context.Context, tx *sql.Tx, table string, columns []columnDefinition, withoutRowID bool) error {
+	definitions := make([]string, 0, len(columns)+1)
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		definitions = append(definitions, c.sql())
+		names = append(names, quoteIdentifier(c.name))
+	}
+
+	// Keep the primary key columns in their key order, which may differ from the column order
+	var primaryKey []string
+	for position := 1; ; position++ {
+		found := false
+		for _, c := range columns {
+			if c.primaryKey == position {
+				primaryKey = append(primaryKey, quoteIdentifier(c.name))
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	if len(primaryKey) > 0 {
+		definitions = append(definitions, "PRIMARY KEY ("+strings.Join(primaryKey, ", ")+")")
+	}
+
+	rebuilt := reorderTablePrefix + table
+	createQuery := fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(rebuilt), strings.Join(definitions, ", "))
+	copiedColumns := strings.Join(names, ", ")
+	copyQuery := fmt.Sprintf("INSERT INTO %s (rowid, %s) SELECT rowid, %s FROM %s", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(rebuilt), copiedColumns, copiedColumns, quoteIdentifier(table))
+	if withoutRowID {
+		createQuery += " WITHOUT ROWID"
+		copyQuery = fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", //nolint:gosec // Identifiers are quoted
+			quoteIdentifier(rebuilt), copiedColumns, copiedColumns, quoteIdentifier(table))
+	}
+
+	for _, query := range []string{
+		createQuery,
+		copyQuery,
+		"DROP TABLE " + quoteIdentifier(table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteIdentifier(rebuilt), quoteIdentifier(table)),
+	} {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to rebuild table %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// renameColumnMetadata updates the lineage, violation, and schema metadata of a renamed column
+func renameColumnMetadata(ctx context.Context, tx *sql.Tx, table, oldName, newName string) error {
+	updates := map[string][]string{
+		lineageTableName: {
+			`UPDATE "%s" SET derived_column = ? WHERE derived_table = ? AND derived_column = ?`,
+			`UPDATE "%s" SET source_column = ? WHERE source_table = ? AND source_column = ?`,
+		},
+		violationsTableName: {
+			`UPDATE "%s" SET column_name = ? WHERE table_name = ? AND column_name = ?`,
+		},
+		schemaMetadataTableName: {
+			`UPDATE "%s" SET column_name = ? WHERE table_name = ? AND column_name = ?`,
+		},
+	}
+
+	for metadataTable, queries := range updates {
+		var exists int
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+			metadataTable,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check metadata table %s: %w", metadataTable, err)
+		}
+		if exists == 0 {
+			continue
+		}
+		for _, query := range queries {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(query, metadataTable), newName, table, oldName); err != nil {
+				return fmt.Errorf("failed to update metadata table %s: %w", metadataTable, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openColumnsTestDB loads a users table with id, name, and age columns
+func openColumnsTestDB(t *testing.T, builder *DBBuilder) *sql.DB {
+	t.Helper()
+
+	return openTestDB(t, builder.
+		AddReader(strings.NewReader("id,name,age\n1,alice,30\n2,bob,\n"), "users", FileTypeCSV))
+}
+
+func TestRenameColumn(t *testing.T) {
+	t.Parallel()
+
+	t.Run("renames the column and dumps the new name", func(t *testing.T) {
+		t.Parallel()
+
+		db := openColumnsTestDB(t, NewBuilder())
+		_, err := db.ExecContext(context.Background(), `CREATE INDEX users_name ON users (name)`)
+		require.NoError(t, err)
+
+		require.NoError(t, RenameColumn(db, "users", "name", "full_name"))
+
+		columns, err := getSQLiteTableColumns(db, "users")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "full_name", "age"}, columns)
+
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir))
+		data, err := os.ReadFile(filepath.Join(outputDir, "users.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "id,full_name,age\n1,alice,30\n2,bob,\n", string(data))
+	})
+
+	t.Run("updates lineage, violations, and schema metadata", func(t *testing.T) {
+		t.Parallel()
+
+		db := openColumnsTestDB(t, NewBuilder().
+			WithDerivedTable("names", "SELECT name FROM users").
+			WithValidation("users", NotNull("age")).
+			EnableSchemaMetadata())
+
+		require.NoError(t, RenameColumn(db, "users", "name", "full_name"))
+		require.NoError(t, RenameColumn(db, "users", "age", "years"))
+		require.NoError(t, RenameColumn(db, "names", "name", "label"))
+
+		lineage, err := GetLineage(db)
+		require.NoError(t, err)
+		require.Len(t, lineage, 1)
+		assert.Equal(t, "label", lineage[0].DerivedColumn)
+		assert.Equal(t, "full_name", lineage[0].SourceColumn)
+
+		violations, err := GetViolations(db)
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, "years", violations[0].Column)
+
+		metadata, err := GetSchemaMetadata(db)
+		require.NoError(t, err)
+		var columns, headers []string
+		for _, column := range metadata {
+			columns = append(columns, column.Column)
+			headers = append(headers, column.Header)
+		}
+		assert.Equal(t, []string{"id", "full_name", "years"}, columns)
+		assert.Equal(t, []string{"id", "name", "age"}, headers)
+	})
+
+	t.Run("uses the context", func(t *testing.T) {
+		t.Parallel()
+
+		db := openColumnsTestDB(t, NewBuilder())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.Error(t, RenameColumnContext(ctx, db, "users", "name", "full_name"))
+		require.NoError(t, RenameColumnContext(context.Background(), db, "users", "name", "full_name"))
+	})
+
+	errorTests := []struct {
+		name    string
+		table   string
+		column  string
+		newName string
+		want    ErrorCode
+	}{
+		{name: "unknown column", table: "users", column: "missing", newName: "other", want: ErrCodeNotFound},
+		{name: "unknown table", table: "missing", column: "id", newName: "other", want: ErrCodeNotFound},
+		{name: "empty name", table: "users", column: "id", newName: "", want: ErrCodeInvalidConfig},
+		{name: "duplicate column", table: "users", column: "id", newName: "NAME", want: ErrCodeDuplicateColumn},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := openColumnsTestDB(t, NewBuilder())
+			err := RenameColumn(db, tt.table, tt.column, tt.newName)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, ErrorCodeOf(err))
+		})
+	}
+}
+
+func TestReorderColumns(t *testing.T) {
+	t.Parallel()
+
+	t.Run("moves the named columns to the front", func(t *testing.T) {
+		t.Parallel()
+
+		db := openColumnsTestDB(t, NewBuilder())
+		require.NoError(t, ReorderColumns(db, "users", "age", "NAME"))
+
+		columns, err := getSQLiteTableColumns(db, "users")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"age", "name", "id"}, columns)
+
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir))
+		data, err := os.ReadFile(filepath.Join(outputDir, "users.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "age,name,id\n30,alice,1\n,bob,2\n", string(data))
+	})
+
+	t.Run("keeps types, constraints, rowids, indexes, triggers, and views", func(t *testing.T) {
+		t.Parallel()
+
+		db := openColumnsTestDB(t, NewBuilder())
+		ctx := context.Background()
+		for _, query := range []string{
+			`CREATE TABLE accounts (code TEXT NOT NULL, region TEXT DEFAULT 'eu', balance REAL, PRIMARY KEY (region, code))`,
+			`INSERT INTO accounts (rowid, code, region, balance) VALUES (10, 'a', 'us', 1.5), (20, 'b', 'eu', 2.5)`,
+			`CREATE INDEX accounts_balance ON accounts (balance)`,
+			`CREATE TABLE audit (code TEXT)`,
+			`CREATE TRIGGER accounts_audit AFTER INSERT ON accounts BEGIN INSERT INTO audit VALUES (new.code); END`,
+			`CREATE VIEW rich AS SELECT code FROM accounts WHERE balance > 2`,
+		} {
+			_, err := db.ExecContext(ctx, query)
+			require.NoError(t, err, query)
+		}
+
+		require.NoError(t, ReorderColumns(db, "accounts", "balance"))
+
+		var createSQL string
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT sql FROM sqlite_master WHERE name = 'accounts'`).Scan(&createSQL))
+		assert.Equal(t, `CREATE TABLE "accounts" ("balance" REAL, "code" TEXT NOT NULL, "region" TEXT DEFAULT 'eu', PRIMARY KEY ("region", "code"))`, createSQL)
+
+		var rowid int
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT rowid FROM accounts WHERE code = 'b'`).Scan(&rowid))
+		assert.Equal(t, 20, rowid)
+
+		var indexes int
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE tbl_name = 'accounts' AND name IN ('accounts_balance', 'accounts_audit')`).Scan(&indexes))
+		assert.Equal(t, 2, indexes)
+
+		_, err := db.ExecContext(ctx, `INSERT INTO accounts (code) VALUES ('c')`)
+		require.NoError(t, err)
+		var audited, rich int
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit`).Scan(&audited))
+		assert.Equal(t, 1, audited)
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM rich`).Scan(&rich))
+		assert.Equal(t, 1, rich)
+
+		_, err = db.ExecContext(ctx, `INSERT INTO accounts (code, region) VALUES ('a', 'us')`)
+		require.Error(t, err, "primary key must still be enforced")
+	})
+
+	errorTests := []struct {
+		name    string
+		table   string
+		columns []string
+		want    ErrorCode
+	}{
+		{name: "no columns", table: "users", want: ErrCodeInvalidConfig},
+		{name: "unknown column", table: "users", columns: []string{"missing"}, want: ErrCodeNotFound},
+		{name: "unknown table", table: "missing", columns: []string{"id"}, want: ErrCodeNotFound},
+		{name: "duplicate column", table: "users", columns: []string{"name", "name"}, want: ErrCodeDuplicateColumn},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := openColumnsTestDB(t, NewBuilder())
+			err := ReorderColumns(db, tt.table, tt.columns...)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, ErrorCodeOf(err))
+
+			columns, err := getSQLiteTableColumns(db, "users")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"id", "name", "age"}, columns)
+		})
+	}
+
+	t.Run("rejects tables with constraints the rebuild would drop", func(t *testing.T) {
+		t.Parallel()
+
+		db := openColumnsTestDB(t, NewBuilder())
+		ctx := context.Background()
+		tests := []struct {
+			table  string
+			query  string
+			column string
+		}{
+			{table: "checked", query: `CREATE TABLE checked (a INTEGER, b INTEGER CHECK (b > 0))`, column: "b"},
+			{table: "unique_key", query: `CREATE TABLE unique_key (a INTEGER, b TEXT, UNIQUE (b))`, column: "b"},
+			{table: "child", query: `CREATE TABLE child (a INTEGER, user_id INTEGER REFERENCES users (id))`, column: "user_id"},
+			{table: "generated", query: `CREATE TABLE generated (a INTEGER, b INTEGER AS (a * 2))`, column: "b"},
+		}
+		for _, tt := range tests {
+			_, err := db.ExecContext(ctx, tt.query)
+			require.NoError(t, err, tt.query)
+
+			err = ReorderColumnsContext(ctx, db, tt.table, tt.column)
+			require.Error(t, err, tt.table)
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err), tt.table)
+
+			var createSQL string
+			require.NoError(t, db.QueryRowContext(ctx, `SELECT sql FROM sqlite_master WHERE name = ?`, tt.table).Scan(&createSQL))
+			assert.Equal(t, tt.query, createSQL, "the table is unchanged")
+		}
+
+		// The keyword in a default value or a quoted name is not a constraint
+		_, err := db.ExecContext(ctx, `CREATE TABLE notes (body TEXT DEFAULT 'CHECK', "check" TEXT)`)
+		require.NoError(t, err)
+		require.NoError(t, ReorderColumnsContext(ctx, db, "notes", "check"))
+	})
+}
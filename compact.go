@@ -0,0 +1,47 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime/debug"
+)
+
+// Compact returns the memory held by deleted rows and dropped tables of db to the
+// operating system.
+//
+// SQLite keeps the pages of deleted rows for reuse, so a long-running service that
+// loads, processes, and deletes large working tables keeps its peak memory forever.
+// Compact rebuilds the database without the unused pages (VACUUM), releases the
+// cached pages SQLite no longer needs (PRAGMA shrink_memory), and returns the freed
+// Go heap to the operating system.
+//
+// Example:
+//
+//	if _, err := db.ExecContext(ctx, "DROP TABLE staging"); err != nil {
+//		return err
+//	}
+//	if err := filesql.Compact(ctx, db); err != nil {
+//		return err
+//	}
+//
+// Rebuilding the database needs exclusive access, so Compact fails while a
+// transaction or the rows of a query are open on another connection of db. Compact
+// does not change the data and takes time proportional to the size of the database,
+// so call it after large deletes rather than after every statement.
+func Compact(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA shrink_memory"); err != nil {
+		return fmt.Errorf("failed to release database memory: %w", err)
+	}
+	debug.FreeOSMemory()
+	return nil
+}
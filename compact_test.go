@@ -0,0 +1,78 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompact(t *testing.T) {
+	t.Parallel()
+
+	// open loads a small table and fills a large working table
+	open := func(t *testing.T, builder *DBBuilder) *sql.DB {
+		t.Helper()
+
+		db := openTestDB(t, builder.
+			AddReader(strings.NewReader("id\n1\n2\n"), "users", FileTypeCSV))
+
+		_, err := db.ExecContext(context.Background(), `CREATE TABLE staging AS
+			WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 5000)
+			SELECT i, randomblob(200) AS payload FROM n`)
+		require.NoError(t, err)
+		return db
+	}
+	pageCount := func(t *testing.T, db *sql.DB) int64 {
+		t.Helper()
+
+		var pages int64
+		require.NoError(t, db.QueryRowContext(context.Background(), "PRAGMA page_count").Scan(&pages))
+		return pages
+	}
+
+	tests := []struct {
+		name    string
+		builder *DBBuilder
+		// release frees the pages of the working table
+		release string
+	}{
+		{
+			name:    "pages of deleted rows are released",
+			builder: NewBuilder(),
+			release: "DELETE FROM staging",
+		},
+		{
+			name:    "databases with auto-save are compacted",
+			builder: NewBuilder().EnableAutoSaveOnCommit("backup").WithFileSystem(newMemoryFileSystem()),
+			release: "DROP TABLE staging",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := open(t, tt.builder)
+			_, err := db.ExecContext(context.Background(), tt.release)
+			require.NoError(t, err)
+			before := pageCount(t, db)
+
+			require.NoError(t, Compact(context.Background(), db))
+			assert.Less(t, pageCount(t, db), before/10)
+			assert.Equal(t, []string{"2"}, queryRows(t, db, "SELECT COUNT(*) FROM users"), "the data is kept")
+		})
+	}
+
+	t.Run("a cancelled context stops compacting", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, NewBuilder())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, Compact(ctx, db), context.Canceled)
+	})
+}
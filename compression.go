@@ -10,7 +10,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/andybalholm/brotli"
 	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 	"github.com/ulikunitz/xz"
 )
 
@@ -64,6 +66,13 @@ func (h *compressionHandlerImpl) CreateReader(reader io.Reader) (io.Reader, func
 			return nil
 		}, nil
 
+	case CompressionLZ4:
+		// lz4.Reader and brotli.Reader don't have a Close method
+		return lz4.NewReader(reader), func() error { return nil }, nil
+
+	case CompressionBR:
+		return brotli.NewReader(reader), func() error { return nil }, nil
+
 	default:
 		return nil, nil, fmt.Errorf("unsupported compression type for reading: %v", h.compressionType)
 	}
@@ -97,6 +106,14 @@ func (h *compressionHandlerImpl) CreateWriter(writer io.Writer) (io.Writer, func
 		}
 		return zstdWriter, zstdWriter.Close, nil
 
+	case CompressionLZ4:
+		lz4Writer := lz4.NewWriter(writer)
+		return lz4Writer, lz4Writer.Close, nil
+
+	case CompressionBR:
+		brWriter := brotli.NewWriter(writer)
+		return brWriter, brWriter.Close, nil
+
 	default:
 		return nil, nil, fmt.Errorf("unsupported compression type for writing: %v", h.compressionType)
 	}
@@ -127,7 +144,7 @@ func (f *CompressionFactory) DetectCompressionType(path string) CompressionType
 	path = strings.ToLower(path)
 
 	switch {
-	case strings.HasSuffix(path, extGZ):
+	case strings.HasSuffix(path, extGZ), strings.HasSuffix(path, extTGZ):
 		return CompressionGZ
 	case strings.HasSuffix(path, extBZ2):
 		return CompressionBZ2
@@ -135,6 +152,10 @@ func (f *CompressionFactory) DetectCompressionType(path string) CompressionType
 		return CompressionXZ
 	case strings.HasSuffix(path, extZSTD):
 		return CompressionZSTD
+	case strings.HasSuffix(path, extLZ4):
+		return CompressionLZ4
+	case strings.HasSuffix(path, extBR):
+		return CompressionBR
 	default:
 		return CompressionNone
 	}
@@ -209,7 +230,7 @@ func (f *CompressionFactory) CreateWriterForFile(path string, compressionType Co
 
 // RemoveCompressionExtension removes the compression extension from a file path if present
 func (f *CompressionFactory) RemoveCompressionExtension(path string) string {
-	for _, ext := range []string{extGZ, extBZ2, extXZ, extZSTD} {
+	for _, ext := range []string{extGZ, extBZ2, extXZ, extZSTD, extLZ4, extBR} {
 		if strings.HasSuffix(strings.ToLower(path), ext) {
 			return path[:len(path)-len(ext)]
 		}
@@ -227,12 +248,26 @@ func (f *CompressionFactory) GetBaseFileType(path string) FileType {
 		return FileTypeCSV
 	case extTSV:
 		return FileTypeTSV
+	case extSSV:
+		return FileTypeSSV
+	case extPSV:
+		return FileTypePSV
 	case extLTSV:
 		return FileTypeLTSV
 	case extParquet:
 		return FileTypeParquet
 	case extXLSX:
 		return FileTypeXLSX
+	case extXLS:
+		return FileTypeXLS
+	case extODS:
+		return FileTypeODS
+	case extJSONL, extNDJSON:
+		return FileTypeJSONL
+	case extZIP:
+		return FileTypeZIP
+	case extTAR, extTGZ:
+		return FileTypeTAR
 	default:
 		return FileTypeUnsupported
 	}
@@ -10,7 +10,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/andybalholm/brotli"
 	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 	"github.com/ulikunitz/xz"
 )
 
@@ -54,6 +56,18 @@ func TestCompressionHandlerInterface(t *testing.T) {
 			extension:       ".zst",
 			canWrite:        true,
 		},
+		{
+			name:            "LZ4 compression",
+			compressionType: CompressionLZ4,
+			extension:       ".lz4",
+			canWrite:        true,
+		},
+		{
+			name:            "Brotli compression",
+			compressionType: CompressionBR,
+			extension:       ".br",
+			canWrite:        true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -97,6 +111,14 @@ func TestCompressionHandlerInterface(t *testing.T) {
 				}
 				_, _ = zstdWriter.Write(testData)
 				_ = zstdWriter.Close()
+			case CompressionLZ4:
+				lz4Writer := lz4.NewWriter(&compressedData)
+				_, _ = lz4Writer.Write(testData)
+				_ = lz4Writer.Close()
+			case CompressionBR:
+				brWriter := brotli.NewWriter(&compressedData)
+				_, _ = brWriter.Write(testData)
+				_ = brWriter.Close()
 			}
 
 			reader, cleanup, err := handler.CreateReader(&compressedData)
@@ -179,6 +201,8 @@ func TestCompressionFactory(t *testing.T) {
 			{"data.tsv.bz2", CompressionBZ2},
 			{"data.ltsv.xz", CompressionXZ},
 			{"data.parquet.zst", CompressionZSTD},
+			{"logs.csv.lz4", CompressionLZ4},
+			{"export.tsv.br", CompressionBR},
 			{"path/to/file.csv", CompressionNone},
 			{"path/to/file.csv.gz", CompressionGZ},
 		}
@@ -314,6 +338,16 @@ func TestCompressionEndToEnd(t *testing.T) {
 				compressionType: CompressionZSTD,
 				extension:       ".zst",
 			},
+			{
+				name:            "LZ4 compression",
+				compressionType: CompressionLZ4,
+				extension:       ".lz4",
+			},
+			{
+				name:            "Brotli compression",
+				compressionType: CompressionBR,
+				extension:       ".br",
+			},
 		}
 
 		for _, tt := range tests {
@@ -365,6 +399,65 @@ func TestCompressionEndToEnd(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Load and dump lz4 and brotli tables", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		factory := NewCompressionFactory()
+		inputs := map[string]string{
+			"logs.csv.lz4":  "id,level\n1,info\n2,error\n",
+			"export.tsv.br": "id\tpage\n1\t/home\n",
+		}
+		for name, content := range inputs {
+			writer, cleanup, err := factory.CreateWriterForFile(filepath.Join(dir, name), factory.DetectCompressionType(name))
+			if err != nil {
+				t.Fatalf("CreateWriterForFile() error = %v", err)
+			}
+			if _, err := writer.Write([]byte(content)); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := cleanup(); err != nil {
+				t.Fatalf("cleanup() error = %v", err)
+			}
+		}
+
+		db, err := Open(filepath.Join(dir, "logs.csv.lz4"), filepath.Join(dir, "export.tsv.br"))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer db.Close()
+
+		var level, page string
+		if err := db.QueryRow("SELECT level FROM logs WHERE id = 2").Scan(&level); err != nil {
+			t.Fatalf("query logs error = %v", err)
+		}
+		if err := db.QueryRow("SELECT page FROM export").Scan(&page); err != nil {
+			t.Fatalf("query export error = %v", err)
+		}
+		if level != "error" || page != "/home" {
+			t.Errorf("level = %q, page = %q, want \"error\", \"/home\"", level, page)
+		}
+
+		for _, compression := range []CompressionType{CompressionLZ4, CompressionBR} {
+			outputDir := filepath.Join(dir, "dump_"+compression.String())
+			if err := DumpDatabase(db, outputDir, NewDumpOptions().WithCompression(compression)); err != nil {
+				t.Fatalf("DumpDatabase(%s) error = %v", compression, err)
+			}
+			dumped, err := Open(filepath.Join(outputDir, "logs.csv"+compression.Extension()))
+			if err != nil {
+				t.Fatalf("Open(dumped %s) error = %v", compression, err)
+			}
+			var count int
+			if err := dumped.QueryRow("SELECT COUNT(*) FROM logs").Scan(&count); err != nil {
+				t.Fatalf("query dumped %s error = %v", compression, err)
+			}
+			_ = dumped.Close()
+			if count != 2 {
+				t.Errorf("dumped %s rows = %d, want 2", compression, count)
+			}
+		}
+	})
 }
 
 // TestCompressionFactoryErrors tests error handling in the compression factory
@@ -406,6 +499,8 @@ func TestCompressionTypeConstants(t *testing.T) {
 		{CompressionBZ2, "bz2", ".bz2"},
 		{CompressionXZ, "xz", ".xz"},
 		{CompressionZSTD, "zstd", ".zst"},
+		{CompressionLZ4, "lz4", ".lz4"},
+		{CompressionBR, "br", ".br"},
 	}
 
 	for _, tt := range tests {
@@ -582,6 +677,8 @@ func TestCreateReaderForFileIntegration(t *testing.T) {
 		{"gzip", CompressionGZ, ".gz"},
 		{"xz", CompressionXZ, ".xz"},
 		{"zstd", CompressionZSTD, ".zst"},
+		{"lz4", CompressionLZ4, ".lz4"},
+		{"brotli", CompressionBR, ".br"},
 	}
 
 	for _, ct := range compressionTypes {
@@ -0,0 +1,585 @@
+package filesql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BuilderConfig is a serializable description of a DBBuilder.
+//
+// It lets CLIs and services declare inputs and options in a JSON or YAML file
+// instead of code. Load it with LoadBuilderConfig and turn it into a builder with
+// NewBuilderFromConfig:
+//
+//	# filesql.yaml
+//	paths:
+//	  - data/users.csv
+//	  - data/orders.tsv.gz
+//	chunk_size: 5000
+//	header_mapping:
+//	  fld_17: customer_id
+//	auto_save:
+//	  output_dir: ./backup
+//	  format: tsv
+//	  compression: gz
+//	validation:
+//	  - table: users
+//	    rules:
+//	      - type: not_null
+//	        column: email
+//
+// io.Reader and fs.FS inputs cannot be expressed in a file; add them to the
+// returned builder in code.
+type BuilderConfig struct {
+	// Paths are files, directories, or glob patterns to load
+	Paths []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	// PathTables maps table names to paths whose files are appended into the table (see AddPathsAsTable)
+	PathTables map[string][]string `json:"path_tables,omitempty" yaml:"path_tables,omitempty"`
+	// SQLiteDBs are existing SQLite database files attached read-only (see AddSQLiteDB)
+	SQLiteDBs []string `json:"sqlite_dbs,omitempty" yaml:"sqlite_dbs,omitempty"`
+	// ChunkSize is the number of rows per chunk; 0 keeps the default
+	ChunkSize int `json:"chunk_size,omitempty" yaml:"chunk_size,omitempty"`
+	// MaxMemoryMB fails the load above this heap size in MB; 0 disables the limit (see SetMemoryLimit)
+	MaxMemoryMB int64 `json:"max_memory_mb,omitempty" yaml:"max_memory_mb,omitempty"`
+	// MaxOpenConns limits the open connections of the database; 0 means no limit (see WithMaxOpenConns)
+	MaxOpenConns int `json:"max_open_conns,omitempty" yaml:"max_open_conns,omitempty"`
+	// MaxIdleConns is the number of idle connections to keep; 0 keeps the default (see WithMaxIdleConns)
+	MaxIdleConns int `json:"max_idle_conns,omitempty" yaml:"max_idle_conns,omitempty"`
+	// AutoSave enables auto-save when set
+	AutoSave *AutoSaveSettings `json:"auto_save,omitempty" yaml:"auto_save,omitempty"`
+	// DeterministicOrder loads inputs sorted by table name (see EnableDeterministicOrder)
+	DeterministicOrder bool `json:"deterministic_order,omitempty" yaml:"deterministic_order,omitempty"`
+	// SourcePriority lets later paths replace earlier paths producing the same table (see WithSourcePriority)
+	SourcePriority bool `json:"source_priority,omitempty" yaml:"source_priority,omitempty"`
+	// ColumnOverflow stores columns beyond SQLite's limit as JSON (see EnableColumnOverflow)
+	ColumnOverflow bool `json:"column_overflow,omitempty" yaml:"column_overflow,omitempty"`
+	// CellTruncation truncates oversized values (see EnableCellTruncation)
+	CellTruncation bool `json:"cell_truncation,omitempty" yaml:"cell_truncation,omitempty"`
+	// ExcelTableDetection splits sheets into tables (see WithExcelTableDetection)
+	ExcelTableDetection bool `json:"excel_table_detection,omitempty" yaml:"excel_table_detection,omitempty"`
+	// HeaderMapping renames source columns (see WithHeaderMapping)
+	HeaderMapping map[string]string `json:"header_mapping,omitempty" yaml:"header_mapping,omitempty"`
+	// HeaderMappingFiles are JSON header mapping files (see WithHeaderMappingFile)
+	HeaderMappingFiles []string `json:"header_mapping_files,omitempty" yaml:"header_mapping_files,omitempty"`
+	// DerivedTables are created from SQL queries after loading (see WithDerivedTable)
+	DerivedTables []DerivedTableConfig `json:"derived_tables,omitempty" yaml:"derived_tables,omitempty"`
+	// Validation contains data quality rules per table (see WithValidation)
+	Validation []ValidationConfig `json:"validation,omitempty" yaml:"validation,omitempty"`
+	// StrictValidation makes rule violations fail the load (see EnableStrictValidation)
+	StrictValidation bool `json:"strict_validation,omitempty" yaml:"strict_validation,omitempty"`
+	// LoadMetrics records per-input resource accounting (see EnableLoadMetrics)
+	LoadMetrics bool `json:"load_metrics,omitempty" yaml:"load_metrics,omitempty"`
+	// LoadSummary records the per-table load summary (see EnableLoadSummary)
+	LoadSummary bool `json:"load_summary,omitempty" yaml:"load_summary,omitempty"`
+	// SchemaMetadata records the source, header, and type of every column (see EnableSchemaMetadata)
+	SchemaMetadata bool `json:"schema_metadata,omitempty" yaml:"schema_metadata,omitempty"`
+	// TempDir is the directory for temporary files created while loading (see WithTempDir)
+	TempDir string `json:"temp_dir,omitempty" yaml:"temp_dir,omitempty"`
+	// NoTempFiles forbids temporary files while loading (see DisableTempFiles)
+	NoTempFiles bool `json:"no_temp_files,omitempty" yaml:"no_temp_files,omitempty"`
+	// PreserveNumericText keeps number-like identifiers as TEXT (see EnableNumericTextPreservation)
+	PreserveNumericText bool `json:"preserve_numeric_text,omitempty" yaml:"preserve_numeric_text,omitempty"`
+	// DetectDelimiter detects the delimiter of CSV and TSV inputs (see WithDelimiterDetection)
+	DetectDelimiter bool `json:"detect_delimiter,omitempty" yaml:"detect_delimiter,omitempty"`
+	// DetectHeader decides whether the first row of CSV and TSV inputs is a header (see WithHeaderDetection)
+	DetectHeader bool `json:"detect_header,omitempty" yaml:"detect_header,omitempty"`
+	// Encoding is the character encoding of text inputs, such as "shift-jis" (see WithEncoding)
+	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	// DetectEncoding detects the character encoding of text inputs (see WithEncodingDetection)
+	DetectEncoding bool `json:"detect_encoding,omitempty" yaml:"detect_encoding,omitempty"`
+	// NoHeader loads the first row of CSV and TSV inputs as data (see WithNoHeader)
+	NoHeader bool `json:"no_header,omitempty" yaml:"no_header,omitempty"`
+	// ColumnNames names the columns of CSV and TSV inputs without a header row (see WithColumnNames)
+	ColumnNames []string `json:"column_names,omitempty" yaml:"column_names,omitempty"`
+	// IgnoreEmptyHeaders drops columns without a header (see WithIgnoreEmptyHeaders)
+	IgnoreEmptyHeaders bool `json:"ignore_empty_headers,omitempty" yaml:"ignore_empty_headers,omitempty"`
+	// SkipColumns are source column names that are not loaded (see WithSkipColumns)
+	SkipColumns []string `json:"skip_columns,omitempty" yaml:"skip_columns,omitempty"`
+	// DroppedColumns maps table names to source columns that are not loaded (see WithDroppedColumns)
+	DroppedColumns map[string][]string `json:"dropped_columns,omitempty" yaml:"dropped_columns,omitempty"`
+	// DefaultValues maps table names to the values loaded into their columns where values are missing (see WithDefaultValue)
+	DefaultValues map[string]map[string]string `json:"default_values,omitempty" yaml:"default_values,omitempty"`
+	// SidecarIndex writes and uses index files next to CSV and TSV files (see WithSidecarIndex)
+	SidecarIndex bool `json:"sidecar_index,omitempty" yaml:"sidecar_index,omitempty"`
+	// TypeInference is "sample" (default), "full_scan", or "none" (see WithTypeInference)
+	TypeInference string `json:"type_inference,omitempty" yaml:"type_inference,omitempty"`
+	// EmptyNumericAsNull loads empty numeric values as NULL (see EnableEmptyNumericAsNull)
+	EmptyNumericAsNull bool `json:"empty_numeric_as_null,omitempty" yaml:"empty_numeric_as_null,omitempty"`
+	// NullValues are the values loaded as NULL in every column (see WithNullValues)
+	NullValues []string `json:"null_values,omitempty" yaml:"null_values,omitempty"`
+	// ColumnNullValues maps table names to the values loaded as NULL in their columns (see WithColumnNullValues)
+	ColumnNullValues map[string]map[string][]string `json:"column_null_values,omitempty" yaml:"column_null_values,omitempty"`
+	// NullConvention is "none" (default) or "quoted_empty" (see WithNullConvention)
+	NullConvention string `json:"null_convention,omitempty" yaml:"null_convention,omitempty"`
+	// LazyLoading loads files when their tables are first referenced (see EnableLazyLoading)
+	LazyLoading bool `json:"lazy_loading,omitempty" yaml:"lazy_loading,omitempty"`
+	// EmptyFiles is "skip" (default), "create_table", or "error" (see WithEmptyFilePolicy)
+	EmptyFiles string `json:"empty_files,omitempty" yaml:"empty_files,omitempty"`
+	// BestEffort skips inputs that fail to load (see WithBestEffort)
+	BestEffort bool `json:"best_effort,omitempty" yaml:"best_effort,omitempty"`
+	// TableNames maps file paths to the names of their tables (see WithTableName)
+	TableNames map[string]string `json:"table_names,omitempty" yaml:"table_names,omitempty"`
+	// TableCollision is "default", "error", "suffix", or "merge" (see WithTableCollisionPolicy)
+	TableCollision string `json:"table_collision,omitempty" yaml:"table_collision,omitempty"`
+	// PersistentDB is the SQLite database file to load the inputs into (see WithPersistentDB)
+	PersistentDB string `json:"persistent_db,omitempty" yaml:"persistent_db,omitempty"`
+	// Watch reloads the tables of files that change (see EnableWatch)
+	Watch bool `json:"watch,omitempty" yaml:"watch,omitempty"`
+}
+
+// AutoSaveSettings is the serializable form of EnableAutoSave and EnableAutoSaveOnCommit.
+type AutoSaveSettings struct {
+	// OutputDir is the output directory; empty overwrites the original files
+	OutputDir string `json:"output_dir,omitempty" yaml:"output_dir,omitempty"`
+	// Timing is "close" (default) or "commit"
+	Timing string `json:"timing,omitempty" yaml:"timing,omitempty"`
+	// Format is "csv" (default), "tsv", "ltsv", "parquet", "xlsx", or "jsonl"
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Compression is "none" (default), "gz", "bz2", "xz", "zstd", "lz4", or "br"
+	Compression string `json:"compression,omitempty" yaml:"compression,omitempty"`
+	// TableCompression maps table names to compression names overriding Compression (see DumpOptions.WithTableCompression)
+	TableCompression map[string]string `json:"table_compression,omitempty" yaml:"table_compression,omitempty"`
+	// ParquetCompression is "none" (default), "snappy", "gzip", "zstd", "brotli", or "lz4" (see DumpOptions.WithParquetCompression)
+	ParquetCompression string `json:"parquet_compression,omitempty" yaml:"parquet_compression,omitempty"`
+	// OutputRoot restricts output files to this directory tree (see DumpOptions.WithOutputRoot)
+	OutputRoot string `json:"output_root,omitempty" yaml:"output_root,omitempty"`
+	// Timeout bounds each auto-save, e.g. "30s" (see SetAutoSaveTimeout)
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Interval also saves periodically while the database is open, e.g. "5m" (see WithAutoSaveInterval)
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// RowThreshold also saves after this many rows changed (see WithAutoSaveRowThreshold)
+	RowThreshold int64 `json:"row_threshold,omitempty" yaml:"row_threshold,omitempty"`
+	// FloatFormat is the fmt format of floating-point values, e.g. "%.2f" (see DumpOptions.WithFloatFormat)
+	FloatFormat string `json:"float_format,omitempty" yaml:"float_format,omitempty"`
+	// ColumnFloatFormats maps column names to float formats (see DumpOptions.WithColumnFloatFormat)
+	ColumnFloatFormats map[string]string `json:"column_float_formats,omitempty" yaml:"column_float_formats,omitempty"`
+	// SafeJSONIntegers writes integers beyond 2^53-1 as JSON strings (see DumpOptions.WithSafeJSONIntegers)
+	SafeJSONIntegers bool `json:"safe_json_integers,omitempty" yaml:"safe_json_integers,omitempty"`
+	// NullConvention is "none" (default) or "quoted_empty" (see DumpOptions.WithNullConvention)
+	NullConvention string `json:"null_convention,omitempty" yaml:"null_convention,omitempty"`
+	// SchemaComments starts CSV and TSV files with comments describing the table (see DumpOptions.WithSchemaComments)
+	SchemaComments bool `json:"schema_comments,omitempty" yaml:"schema_comments,omitempty"`
+	// Workbook is the name of the Excel workbook every table is saved to as a sheet (see DumpOptions.WithWorkbook)
+	Workbook string `json:"workbook,omitempty" yaml:"workbook,omitempty"`
+	// EmptyTables is "header_only" (default) or "skip" (see DumpOptions.WithEmptyTablePolicy)
+	EmptyTables string `json:"empty_tables,omitempty" yaml:"empty_tables,omitempty"`
+	// Tables are the only tables that are saved (see DumpOptions.WithTables)
+	Tables []string `json:"tables,omitempty" yaml:"tables,omitempty"`
+	// ExcludeTables are SQL LIKE patterns of tables that are not saved (see DumpOptions.WithExcludedTables)
+	ExcludeTables []string `json:"exclude_tables,omitempty" yaml:"exclude_tables,omitempty"`
+	// AtomicWrites renames completely written files into place (see DumpOptions.WithAtomicWrites)
+	AtomicWrites bool `json:"atomic_writes,omitempty" yaml:"atomic_writes,omitempty"`
+	// LockFile locks the output directory while saving (see DumpOptions.WithLockFile)
+	LockFile bool `json:"lock_file,omitempty" yaml:"lock_file,omitempty"`
+}
+
+// DerivedTableConfig is the serializable form of WithDerivedTable.
+type DerivedTableConfig struct {
+	// Name is the name of the derived table
+	Name string `json:"name" yaml:"name"`
+	// Query is the SELECT statement that produces the table
+	Query string `json:"query" yaml:"query"`
+}
+
+// ValidationConfig is the serializable form of WithValidation.
+type ValidationConfig struct {
+	// Table is the validated table
+	Table string `json:"table" yaml:"table"`
+	// Rules are the rules applied to the table
+	Rules []RuleConfig `json:"rules" yaml:"rules"`
+}
+
+// RuleConfig is the serializable form of a Rule.
+type RuleConfig struct {
+	// Type is "not_null", "unique", "regex", "range", or "references"
+	Type string `json:"type" yaml:"type"`
+	// Column is the validated column
+	Column string `json:"column" yaml:"column"`
+	// Pattern is the regular expression for "regex"
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// Min and Max are the inclusive bounds for "range"
+	Min float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max float64 `json:"max,omitempty" yaml:"max,omitempty"`
+	// RefTable and RefColumn are the referenced column for "references"
+	RefTable  string `json:"ref_table,omitempty" yaml:"ref_table,omitempty"`
+	RefColumn string `json:"ref_column,omitempty" yaml:"ref_column,omitempty"`
+}
+
+// LoadBuilderConfig reads a BuilderConfig from a JSON (.json) or YAML (.yaml, .yml) file.
+// Unknown fields are rejected so that typos do not silently disable options.
+func LoadBuilderConfig(path string) (BuilderConfig, error) {
+	var cfg BuilderConfig
+
+	data, err := os.ReadFile(path) //nolint:gosec // Config file path is provided by the caller
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&cfg)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&cfg)
+	default:
+		return cfg, newCodedError(ErrCodeUnsupportedFormat, "unsupported config file type: %s", path)
+	}
+	if err != nil {
+		return cfg, newCodedError(ErrCodeInvalidConfig, "failed to decode config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// NewBuilderFromConfig creates a builder configured as described by cfg.
+//
+// The returned builder can be extended in code before calling Build:
+//
+//	cfg, err := filesql.LoadBuilderConfig("filesql.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	builder, err := filesql.NewBuilderFromConfig(cfg)
+//	if err != nil {
+//		return err
+//	}
+//	validatedBuilder, err := builder.AddReader(r.Body, "upload", filesql.FileTypeCSV).Build(ctx)
+//
+// Returns an error if cfg contains unknown option values such as an unsupported
+// output format or rule type.
+func NewBuilderFromConfig(cfg BuilderConfig) (*DBBuilder, error) {
+	b := NewBuilder().AddPaths(cfg.Paths...)
+	tables := make([]string, 0, len(cfg.PathTables))
+	for table := range cfg.PathTables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		b.AddPathsAsTable(table, cfg.PathTables[table]...)
+	}
+	for _, path := range cfg.SQLiteDBs {
+		b.AddSQLiteDB(path)
+	}
+
+	if cfg.ChunkSize != 0 {
+		b.SetDefaultChunkSize(cfg.ChunkSize)
+	}
+
+	if cfg.MaxMemoryMB > 0 {
+		b.SetMemoryLimit(cfg.MaxMemoryMB)
+	}
+	if cfg.MaxOpenConns != 0 {
+		b.WithMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns != 0 {
+		b.WithMaxIdleConns(cfg.MaxIdleConns)
+	}
+
+	if cfg.AutoSave != nil {
+		options, err := cfg.AutoSave.dumpOptions()
+		if err != nil {
+			return nil, err
+		}
+		switch cfg.AutoSave.Timing {
+		case "", "close":
+			b.EnableAutoSave(cfg.AutoSave.OutputDir, options)
+		case "commit":
+			b.EnableAutoSaveOnCommit(cfg.AutoSave.OutputDir, options)
+		default:
+			return nil, newCodedError(ErrCodeInvalidConfig, "unsupported auto-save timing: %s", cfg.AutoSave.Timing)
+		}
+		if cfg.AutoSave.Timeout != "" {
+			timeout, err := time.ParseDuration(cfg.AutoSave.Timeout)
+			if err != nil || timeout < 0 {
+				return nil, newCodedError(ErrCodeInvalidConfig, "invalid auto-save timeout: %s", cfg.AutoSave.Timeout)
+			}
+			b.SetAutoSaveTimeout(timeout)
+		}
+		if cfg.AutoSave.Interval != "" {
+			interval, err := time.ParseDuration(cfg.AutoSave.Interval)
+			if err != nil || interval < 0 {
+				return nil, newCodedError(ErrCodeInvalidConfig, "invalid auto-save interval: %s", cfg.AutoSave.Interval)
+			}
+			b.WithAutoSaveInterval(interval)
+		}
+		if cfg.AutoSave.RowThreshold != 0 {
+			b.WithAutoSaveRowThreshold(cfg.AutoSave.RowThreshold)
+		}
+	}
+
+	if cfg.DeterministicOrder {
+		b.EnableDeterministicOrder()
+	}
+	if cfg.SourcePriority {
+		b.WithSourcePriority()
+	}
+	if cfg.ColumnOverflow {
+		b.EnableColumnOverflow()
+	}
+	if cfg.CellTruncation {
+		b.EnableCellTruncation()
+	}
+	if cfg.ExcelTableDetection {
+		b.WithExcelTableDetection()
+	}
+	if len(cfg.HeaderMapping) > 0 {
+		b.WithHeaderMapping(cfg.HeaderMapping)
+	}
+	for _, path := range cfg.HeaderMappingFiles {
+		b.WithHeaderMappingFile(path)
+	}
+	for _, derived := range cfg.DerivedTables {
+		b.WithDerivedTable(derived.Name, derived.Query)
+	}
+
+	for _, v := range cfg.Validation {
+		rules := make([]Rule, 0, len(v.Rules))
+		for _, rc := range v.Rules {
+			rule, err := rc.rule()
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+		b.WithValidation(v.Table, rules...)
+	}
+	if cfg.StrictValidation {
+		b.EnableStrictValidation()
+	}
+	if cfg.LoadMetrics {
+		b.EnableLoadMetrics()
+	}
+	if cfg.LoadSummary {
+		b.EnableLoadSummary()
+	}
+	if cfg.SchemaMetadata {
+		b.EnableSchemaMetadata()
+	}
+	if cfg.TempDir != "" {
+		b.WithTempDir(cfg.TempDir)
+	}
+	if cfg.NoTempFiles {
+		b.DisableTempFiles()
+	}
+	if cfg.PreserveNumericText {
+		b.EnableNumericTextPreservation()
+	}
+	if cfg.DetectDelimiter {
+		b.WithDelimiterDetection()
+	}
+	if cfg.DetectHeader {
+		b.WithHeaderDetection()
+	}
+	if cfg.Encoding != "" {
+		b.WithEncoding(cfg.Encoding)
+	}
+	if cfg.DetectEncoding {
+		b.WithEncodingDetection()
+	}
+	if cfg.NoHeader {
+		b.WithNoHeader()
+	}
+	if len(cfg.ColumnNames) > 0 {
+		b.WithColumnNames(cfg.ColumnNames...)
+	}
+	if cfg.IgnoreEmptyHeaders {
+		b.WithIgnoreEmptyHeaders()
+	}
+	if len(cfg.SkipColumns) > 0 {
+		b.WithSkipColumns(cfg.SkipColumns...)
+	}
+	for table, columns := range cfg.DroppedColumns {
+		b.WithDroppedColumns(table, columns...)
+	}
+	for table, values := range cfg.DefaultValues {
+		for column, value := range values {
+			b.WithDefaultValue(table, column, value)
+		}
+	}
+	if cfg.SidecarIndex {
+		b.WithSidecarIndex()
+	}
+	if cfg.TypeInference != "" {
+		mode, err := parseTypeInference(cfg.TypeInference)
+		if err != nil {
+			return nil, err
+		}
+		b.WithTypeInference(mode)
+	}
+	if cfg.EmptyNumericAsNull {
+		b.EnableEmptyNumericAsNull()
+	}
+	if cfg.NullValues != nil {
+		b.WithNullValues(cfg.NullValues...)
+	}
+	for table, columns := range cfg.ColumnNullValues {
+		for column, values := range columns {
+			b.WithColumnNullValues(table, column, values...)
+		}
+	}
+	if cfg.NullConvention != "" {
+		convention, err := parseNullConvention(cfg.NullConvention)
+		if err != nil {
+			return nil, err
+		}
+		b.WithNullConvention(convention)
+	}
+	if cfg.LazyLoading {
+		b.EnableLazyLoading()
+	}
+	if cfg.EmptyFiles != "" {
+		policy, err := parseEmptyFilePolicy(cfg.EmptyFiles)
+		if err != nil {
+			return nil, err
+		}
+		b.WithEmptyFilePolicy(policy)
+	}
+	if cfg.BestEffort {
+		b.WithBestEffort()
+	}
+	for path, name := range cfg.TableNames {
+		b.WithTableName(path, name)
+	}
+	if cfg.TableCollision != "" {
+		policy, err := parseTableCollisionPolicy(cfg.TableCollision)
+		if err != nil {
+			return nil, err
+		}
+		b.WithTableCollisionPolicy(policy)
+	}
+	if cfg.PersistentDB != "" {
+		b.WithPersistentDB(cfg.PersistentDB)
+	}
+	if cfg.Watch {
+		b.EnableWatch(nil)
+	}
+
+	return b, nil
+}
+
+// dumpOptions converts the format and compression names into DumpOptions
+func (s AutoSaveSettings) dumpOptions() (DumpOptions, error) {
+	options := NewDumpOptions().
+		WithOutputRoot(s.OutputRoot).
+		WithFloatFormat(s.FloatFormat).
+		WithTables(s.Tables...).
+		WithExcludedTables(s.ExcludeTables...)
+	for column, format := range s.ColumnFloatFormats {
+		options = options.WithColumnFloatFormat(column, format)
+	}
+	if s.AtomicWrites {
+		options = options.WithAtomicWrites()
+	}
+	if s.LockFile {
+		options = options.WithLockFile()
+	}
+	if s.SafeJSONIntegers {
+		options = options.WithSafeJSONIntegers()
+	}
+	if s.SchemaComments {
+		options = options.WithSchemaComments()
+	}
+	if s.Workbook != "" {
+		options = options.WithWorkbook(s.Workbook)
+	}
+	if s.NullConvention != "" {
+		convention, err := parseNullConvention(s.NullConvention)
+		if err != nil {
+			return options, err
+		}
+		options = options.WithNullConvention(convention)
+	}
+
+	if s.Format != "" {
+		found := false
+		for _, format := range []OutputFormat{OutputFormatCSV, OutputFormatTSV, OutputFormatLTSV, OutputFormatParquet, OutputFormatXLSX, OutputFormatJSONL, OutputFormatSSV, OutputFormatPSV} {
+			if strings.EqualFold(s.Format, format.String()) {
+				options = options.WithFormat(format)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return options, newCodedError(ErrCodeInvalidConfig, "unsupported output format: %s", s.Format)
+		}
+	}
+
+	if s.Compression != "" {
+		compression, err := parseCompressionType(s.Compression)
+		if err != nil {
+			return options, err
+		}
+		options = options.WithCompression(compression)
+	}
+	if len(s.TableCompression) > 0 {
+		compressions := make(map[string]CompressionType, len(s.TableCompression))
+		for table, name := range s.TableCompression {
+			compression, err := parseCompressionType(name)
+			if err != nil {
+				return options, err
+			}
+			compressions[table] = compression
+		}
+		options = options.WithTableCompression(compressions)
+	}
+
+	if s.ParquetCompression != "" {
+		compression, err := parseParquetCompression(s.ParquetCompression)
+		if err != nil {
+			return options, err
+		}
+		options = options.WithParquetCompression(compression)
+	}
+
+	if s.EmptyTables != "" {
+		policy, err := parseEmptyTablePolicy(s.EmptyTables)
+		if err != nil {
+			return options, err
+		}
+		options = options.WithEmptyTablePolicy(policy)
+	}
+
+	if err := options.validateFloatFormats(); err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
+
+// rule converts the configuration into a Rule
+func (rc RuleConfig) rule() (Rule, error) {
+	switch rc.Type {
+	case ruleNotNull.String():
+		return NotNull(rc.Column), nil
+	case ruleUnique.String():
+		return Unique(rc.Column), nil
+	case ruleRegex.String():
+		return MatchRegex(rc.Column, rc.Pattern), nil
+	case ruleRange.String():
+		return InRange(rc.Column, rc.Min, rc.Max), nil
+	case ruleReferences.String():
+		return References(rc.Column, rc.RefTable, rc.RefColumn), nil
+	default:
+		return Rule{}, newCodedError(ErrCodeInvalidConfig, "unsupported validation rule type: %s", rc.Type)
+	}
+}
+
+// parseCompressionType converts a compression name such as "zstd" into a CompressionType
+func parseCompressionType(name string) (CompressionType, error) {
+	for _, compression := range []CompressionType{CompressionNone, CompressionGZ, CompressionBZ2, CompressionXZ, CompressionZSTD, CompressionLZ4, CompressionBR} {
+		if strings.EqualFold(name, compression.String()) {
+			return compression, nil
+		}
+	}
+	return CompressionNone, newCodedError(ErrCodeInvalidConfig, "unsupported compression type: %s", name)
+}
@@ -0,0 +1,221 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBuilderConfig(t *testing.T) {
+	t.Parallel()
+
+	want := BuilderConfig{
+		Paths:     []string{"data/users.csv"},
+		ChunkSize: 5000,
+		AutoSave: &AutoSaveSettings{
+			OutputDir:   "./backup",
+			Timing:      "commit",
+			Format:      "tsv",
+			Compression: "gz",
+		},
+		HeaderMapping: map[string]string{"fld_17": "customer_id"},
+		Validation: []ValidationConfig{
+			{Table: "users", Rules: []RuleConfig{{Type: "range", Column: "age", Min: 0, Max: 150}}},
+		},
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "filesql.yaml")
+		data := `paths:
+  - data/users.csv
+chunk_size: 5000
+auto_save:
+  output_dir: ./backup
+  timing: commit
+  format: tsv
+  compression: gz
+header_mapping:
+  fld_17: customer_id
+validation:
+  - table: users
+    rules:
+      - type: range
+        column: age
+        min: 0
+        max: 150
+`
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+		cfg, err := LoadBuilderConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, cfg)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "filesql.json")
+		data := `{
+  "paths": ["data/users.csv"],
+  "chunk_size": 5000,
+  "auto_save": {"output_dir": "./backup", "timing": "commit", "format": "tsv", "compression": "gz"},
+  "header_mapping": {"fld_17": "customer_id"},
+  "validation": [{"table": "users", "rules": [{"type": "range", "column": "age", "min": 0, "max": 150}]}]
+}`
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+		cfg, err := LoadBuilderConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, cfg)
+	})
+
+	t.Run("unknown fields are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		jsonPath := filepath.Join(dir, "typo.json")
+		require.NoError(t, os.WriteFile(jsonPath, []byte(`{"chunksize": 10}`), 0600))
+		_, err := LoadBuilderConfig(jsonPath)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+
+		yamlPath := filepath.Join(dir, "typo.yml")
+		require.NoError(t, os.WriteFile(yamlPath, []byte("chunksize: 10\n"), 0600))
+		_, err = LoadBuilderConfig(yamlPath)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "filesql.toml")
+		require.NoError(t, os.WriteFile(path, []byte(""), 0600))
+		_, err := LoadBuilderConfig(path)
+		assert.Equal(t, ErrCodeUnsupportedFormat, ErrorCodeOf(err))
+	})
+}
+
+func TestNewBuilderFromConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("applies inputs and options", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		persistentDB := filepath.Join(t.TempDir(), "cache.db")
+		sqliteDB := filepath.Join(t.TempDir(), "reference.db")
+		require.NoError(t, os.WriteFile(sqliteDB, nil, 0600))
+		builder, err := NewBuilderFromConfig(BuilderConfig{
+			Paths:              []string{filepath.Join("testdata", "sample.csv")},
+			PathTables:         map[string][]string{"people": {filepath.Join("testdata", "users.csv")}},
+			SQLiteDBs:          []string{sqliteDB},
+			ChunkSize:          10,
+			MaxOpenConns:       4,
+			MaxIdleConns:       4,
+			AutoSave:           &AutoSaveSettings{OutputDir: outputDir, Format: "tsv", Compression: "gz", FloatFormat: "%.2f", AtomicWrites: true, LockFile: true, Interval: "5m", NullConvention: "quoted_empty"},
+			DeterministicOrder: true,
+			SourcePriority:     true,
+			DetectDelimiter:    true,
+			DetectHeader:       true,
+			IgnoreEmptyHeaders: true,
+			SkipColumns:        []string{"notes"},
+			DroppedColumns:     map[string][]string{"sample": {"email"}},
+			SidecarIndex:       true,
+			TypeInference:      "full_scan",
+			EmptyNumericAsNull: true,
+			EmptyFiles:         "create_table",
+			BestEffort:         true,
+			TableNames:         map[string]string{filepath.Join("testdata", "sample.csv"): "sample"},
+			TableCollision:     "suffix",
+			PersistentDB:       persistentDB,
+			Watch:              true,
+			NullValues:         []string{"N/A"},
+			ColumnNullValues:   map[string]map[string][]string{"sample": {"name": {"-"}}},
+			NullConvention:     "quoted_empty",
+			LoadSummary:        true,
+			DetectEncoding:     true,
+			HeaderMapping:      map[string]string{"name": "full_name"},
+			DerivedTables:      []DerivedTableConfig{{Name: "names", Query: "SELECT full_name FROM sample"}},
+			Validation: []ValidationConfig{
+				{Table: "sample", Rules: []RuleConfig{{Type: "not_null", Column: "id"}}},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 10, builder.defaultChunkSize)
+		assert.Equal(t, 4, builder.maxOpenConns)
+		assert.Equal(t, 4, builder.maxIdleConns)
+		assert.True(t, builder.deterministicOrder)
+		assert.True(t, builder.sourcePriority)
+		assert.True(t, builder.streamProcessor.detectDelimiter)
+		assert.True(t, builder.streamProcessor.detectHeader)
+		assert.True(t, builder.streamProcessor.ignoreEmptyHeaders)
+		assert.Equal(t, []string{"notes"}, builder.streamProcessor.skipColumns)
+		assert.Equal(t, map[string][]string{"sample": {"email"}}, builder.streamProcessor.droppedColumns)
+		assert.True(t, builder.streamProcessor.sidecarIndex)
+		assert.Equal(t, TypeInferenceFullScan, builder.streamProcessor.typeInference)
+		assert.True(t, builder.streamProcessor.emptyNumericAsNull)
+		assert.Equal(t, EmptyFileCreateTable, builder.emptyFilePolicy)
+		assert.True(t, builder.streamProcessor.bestEffort)
+		assert.Equal(t, map[string]string{filepath.Join("testdata", "sample.csv"): "sample"}, builder.tableNames)
+		assert.Equal(t, map[int]string{1: "people"}, builder.pathTables)
+		assert.Equal(t, []sqliteDatabase{{path: sqliteDB, name: "reference"}}, builder.sqliteDBs)
+		assert.Equal(t, TableCollisionSuffix, builder.streamProcessor.tableCollision)
+		assert.Equal(t, persistentDB, builder.persistentDB)
+		assert.True(t, builder.watch)
+		assert.Equal(t, []string{"N/A"}, builder.streamProcessor.nullValues)
+		assert.Equal(t, map[string][]columnNullValues{"sample": {{column: "name", values: []string{"-"}}}}, builder.streamProcessor.columnNullValues)
+		assert.Equal(t, NullConventionQuotedEmpty, builder.streamProcessor.nullConvention)
+		assert.True(t, builder.loadSummary)
+		assert.True(t, builder.streamProcessor.detectEncoding)
+		require.NotNil(t, builder.autoSaveConfig)
+		assert.Equal(t, autoSaveOnClose, builder.autoSaveConfig.timing)
+		assert.Equal(t, OutputFormatTSV, builder.autoSaveConfig.options.Format)
+		assert.Equal(t, CompressionGZ, builder.autoSaveConfig.options.Compression)
+		assert.Equal(t, "%.2f", builder.autoSaveConfig.options.FloatFormat)
+		assert.True(t, builder.autoSaveConfig.options.AtomicWrites)
+		assert.True(t, builder.autoSaveConfig.options.LockFile)
+		assert.Equal(t, NullConventionQuotedEmpty, builder.autoSaveConfig.options.NullConvention)
+		assert.Equal(t, 5*time.Minute, builder.autoSaveInterval)
+
+		ctx := context.Background()
+		db := openTestDB(t, builder)
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM names").Scan(&count))
+		assert.Positive(t, count)
+	})
+
+	t.Run("invalid option values are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name string
+			cfg  BuilderConfig
+		}{
+			{name: "format", cfg: BuilderConfig{AutoSave: &AutoSaveSettings{Format: "xml"}}},
+			{name: "compression", cfg: BuilderConfig{AutoSave: &AutoSaveSettings{Compression: "rar"}}},
+			{name: "parquet compression", cfg: BuilderConfig{AutoSave: &AutoSaveSettings{ParquetCompression: "lzo"}}},
+			{name: "timing", cfg: BuilderConfig{AutoSave: &AutoSaveSettings{Timing: "hourly"}}},
+			{name: "interval", cfg: BuilderConfig{AutoSave: &AutoSaveSettings{Interval: "often"}}},
+			{name: "type inference", cfg: BuilderConfig{TypeInference: "guess"}},
+			{name: "empty files", cfg: BuilderConfig{EmptyFiles: "ignore"}},
+			{name: "table collision", cfg: BuilderConfig{TableCollision: "rename"}},
+			{name: "null convention", cfg: BuilderConfig{NullConvention: "quoted"}},
+			{name: "empty tables", cfg: BuilderConfig{AutoSave: &AutoSaveSettings{EmptyTables: "drop"}}},
+			{name: "float format", cfg: BuilderConfig{AutoSave: &AutoSaveSettings{ColumnFloatFormats: map[string]string{"price": "%d"}}}},
+			{name: "rule type", cfg: BuilderConfig{Validation: []ValidationConfig{{Table: "t", Rules: []RuleConfig{{Type: "email", Column: "c"}}}}}},
+		}
+
+		for _, tt := range tests {
+			_, err := NewBuilderFromConfig(tt.cfg)
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err), tt.name)
+		}
+	})
+}
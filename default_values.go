@@ -0,0 +1,116 @@
+package filesql
+
+import (
+	"strings"
+)
+
+// columnDefault is a value loaded into a column whose value is missing
+type columnDefault struct {
+	column string
+	value  string
+}
+
+// WithDefaultValue sets the value loaded into a column of a table where the data has none.
+//
+// Rows of CSV and TSV files can be shorter than the header, JSON Lines objects can leave
+// out keys, and cells can simply be empty; such values are normally loaded as empty
+// strings, which breaks NOT NULL expectations and skews aggregations downstream. With
+// WithDefaultValue, every missing or empty value of the column is loaded as value
+// instead. If an input has no such column at all, the column is added to its table
+// with value in every row, so the table always has the columns queries expect. With
+// NullConventionQuotedEmpty, the unquoted empty fields that would be loaded as NULL
+// are missing values too.
+//
+// The table is the name the input is loaded as (see the table naming rules), and the
+// column is the name the column is loaded as, after WithHeaderMapping renames it; both
+// are compared case-insensitively. Setting a default for the same column again
+// replaces it.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("orders.csv"). // some exports have no discount column
+//		WithDefaultValue("orders", "discount", "0").
+//		WithDefaultValue("orders", "status", "unknown")
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithDefaultValue(table, column, value string) *DBBuilder {
+	if b.streamProcessor.defaultValues == nil {
+		b.streamProcessor.defaultValues = make(map[string][]columnDefault)
+	}
+	key := strings.ToLower(table)
+	defaults := b.streamProcessor.defaultValues[key]
+	for i, def := range defaults {
+		if strings.EqualFold(def.column, column) {
+			defaults[i].value = value
+			return b
+		}
+	}
+	b.streamProcessor.defaultValues[key] = append(defaults, columnDefault{column: column, value: value})
+	return b
+}
+
+// fillDefaultValues replaces the missing and empty values of columns with a default
+// value, adding the columns the chunk does not have
+func (sp *streamProcessor) fillDefaultValues(chunk *tableChunk) *tableChunk {
+	table := strings.TrimPrefix(chunk.getTableName(), stagingTablePrefix)
+	defaults := sp.defaultValues[strings.ToLower(table)]
+	if len(defaults) == 0 {
+		return chunk
+	}
+
+	headers := append(header(nil), chunk.getHeaders()...)
+	infos := append([]columnInfo(nil), chunk.getColumnInfo()...)
+	indexes := make([]int, len(defaults))
+	for i, def := range defaults {
+		indexes[i] = -1
+		for j, name := range headers {
+			if strings.EqualFold(name, def.column) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			indexes[i] = len(headers)
+			headers = append(headers, def.column)
+			if len(infos) == len(headers)-1 {
+				infos = append(infos, newColumnInfo(def.column, []string{def.value}))
+			}
+		}
+	}
+
+	records := make([]Record, len(chunk.getRecords()))
+	// nullsFilled holds whether NULL fields of a column were filled, which were typed as text
+	nullsFilled := make([]bool, len(defaults))
+	for i, record := range chunk.getRecords() {
+		filled := make(Record, max(len(headers), len(record)))
+		copy(filled, record)
+		for j, def := range defaults {
+			value := strings.TrimSpace(filled[indexes[j]])
+			if value == nullFieldMarker {
+				nullsFilled[j] = true
+			}
+			if value == "" || value == nullFieldMarker {
+				filled[indexes[j]] = def.value
+			}
+		}
+		records[i] = filled
+	}
+	for j, index := range indexes {
+		if !nullsFilled[j] || index >= len(infos) {
+			continue
+		}
+		values := make([]string, 0, len(records))
+		for _, record := range records {
+			values = append(values, record[index])
+		}
+		infos[index] = newColumnInfo(infos[index].Name, values)
+	}
+
+	return &tableChunk{
+		tableName:  chunk.getTableName(),
+		headers:    headers,
+		records:    records,
+		columnInfo: infos,
+	}
+}
@@ -0,0 +1,93 @@
+package filesql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDefaultValue(t *testing.T) {
+	t.Parallel()
+
+	const discountType = "SELECT type FROM pragma_table_info('orders') WHERE name = 'discount'"
+	runLoadTests(t, []loadTest{
+		{
+			name: "empty and missing values are loaded as the default",
+			builder: func(*testing.T) *DBBuilder {
+				jsonl := `{"id": 1, "status": "shipped", "discount": 5}
+{"id": 2, "discount": null}
+{"id": 3, "status": ""}
+`
+				return NewBuilder().
+					AddReader(strings.NewReader(jsonl), "orders", FileTypeJSONL).
+					WithDefaultValue("ORDERS", "Status", "unknown").
+					WithDefaultValue("orders", "discount", "0")
+			},
+			want: map[string][]string{
+				"SELECT status, discount FROM orders ORDER BY id": {"shipped:5", "unknown:0", "unknown:0"},
+			},
+		},
+		{
+			name: "NULL fields of the null convention are missing values",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,status,discount\n1,,\n2,\"\",5\n"), "orders", FileTypeCSV).
+					WithNullConvention(NullConventionQuotedEmpty).
+					WithDefaultValue("orders", "status", "unknown").
+					WithDefaultValue("orders", "discount", "0")
+			},
+			want: map[string][]string{
+				"SELECT status, discount FROM orders ORDER BY id": {"unknown:0", "unknown:5"},
+				discountType: {"INTEGER"},
+			},
+		},
+		{
+			name: "a column missing from the file is added",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,amount\n1,100\n2,250\n"), "orders", FileTypeCSV).
+					WithDefaultValue("orders", "discount", "0").
+					WithDefaultValue("other", "ignored", "x")
+			},
+			want: map[string][]string{
+				"SELECT SUM(discount), SUM(amount) FROM orders": {"0:350"},
+				discountType: {"INTEGER"},
+			},
+		},
+		{
+			name: "defaults apply to mapped column names and header-only files",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,fld_9\n"), "orders", FileTypeCSV).
+					WithHeaderMapping(map[string]string{"fld_9": "status"}).
+					WithDefaultValue("orders", "status", "unknown").
+					WithDefaultValue("orders", "region", "eu")
+			},
+			want: map[string][]string{
+				"SELECT name FROM pragma_table_info('orders') ORDER BY name": {"id", "region", "status"},
+			},
+		},
+		{
+			name: "setting a default again replaces it",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,status\n1,\n"), "orders", FileTypeCSV).
+					WithDefaultValue("orders", "status", "unknown").
+					WithDefaultValue("orders", "STATUS", "pending")
+			},
+			want: map[string][]string{"SELECT status FROM orders": {"pending"}},
+		},
+		{
+			name: "configuration files set defaults",
+			builder: func(t *testing.T) *DBBuilder {
+				builder, err := NewBuilderFromConfig(BuilderConfig{
+					DefaultValues: map[string]map[string]string{"orders": {"status": "unknown"}},
+				})
+				require.NoError(t, err)
+				return builder.AddReader(strings.NewReader("id,status\n1,\n"), "orders", FileTypeCSV)
+			},
+			want: map[string][]string{"SELECT status FROM orders": {"unknown"}},
+		},
+	})
+}
@@ -0,0 +1,166 @@
+package filesql
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// delimiterSampleSize is the number of bytes sampled to detect the delimiter of an input
+const delimiterSampleSize = 8 * 1024
+
+// WithDelimiterDetection detects the delimiter of CSV and TSV inputs from their content.
+//
+// By default, the delimiter follows the extension: a comma for .csv and a tab for
+// .tsv. Files exported with regional settings often use other separators, so a .csv
+// file with semicolons is loaded as a single column. With delimiter detection, the
+// first 8 KB of each CSV and TSV input (after decompression) are parsed with a comma,
+// semicolon, tab, and pipe, and the delimiter that splits the sampled lines into the
+// most consistent number of columns is used. The extension's delimiter wins ties and
+// is kept when no candidate splits the header into more than one column.
+//
+// When the detected delimiter differs from the extension's, the decision is recorded
+// as a load warning (see GetLoadWarnings).
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("sales.csv"). // date;amount
+//		WithDelimiterDetection()
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithDelimiterDetection() *DBBuilder {
+	b.streamProcessor.detectDelimiter = true
+	return b
+}
+
+// delimiterCandidates returns the delimiters WithDelimiterDetection chooses from
+func delimiterCandidates() []rune {
+	return []rune{csvDelimiter, ssvDelimiter, tsvDelimiter, psvDelimiter}
+}
+
+// sniffDelimiter returns the delimiter of the data read from reader, defaulting to
+// fallback, and a reader that still yields all data
+func sniffDelimiter(reader io.Reader, fallback rune) (io.Reader, rune, error) {
+	buffered := bufio.NewReaderSize(reader, delimiterSampleSize)
+	sample, err := buffered.Peek(delimiterSampleSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fallback, fmt.Errorf("failed to sample delimiter: %w", err)
+	}
+	if len(sample) == delimiterSampleSize {
+		// Do not judge the last line by the part that fits into the sample
+		if i := bytes.LastIndexByte(sample, '\n'); i > 0 {
+			sample = sample[:i+1]
+		}
+	}
+	return buffered, detectDelimiter(sample, fallback), nil
+}
+
+// detectDelimiter returns the candidate delimiter that splits sample into the most
+// consistent records with more than one field, preferring fallback on ties
+func detectDelimiter(sample []byte, fallback rune) rune {
+	best := fallback
+	bestConsistent, bestFields := delimiterScore(sample, fallback)
+	for _, candidate := range delimiterCandidates() {
+		if candidate == fallback {
+			continue
+		}
+		consistent, fields := delimiterScore(sample, candidate)
+		if consistent > bestConsistent || (consistent == bestConsistent && fields > bestFields) {
+			best, bestConsistent, bestFields = candidate, consistent, fields
+		}
+	}
+	if bestFields < 2 {
+		return fallback
+	}
+	return best
+}
+
+// delimiterScore parses sample with delimiter and returns the number of records that
+// have as many fields as the header, and the number of header fields. A delimiter
+// that does not split the header scores zero.
+func delimiterScore(sample []byte, delimiter rune) (int, int) {
+	reader := csv.NewReader(bytes.NewReader(sample))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil || len(header) < 2 {
+		return 0, 0
+	}
+	consistent := 1
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			// A parse error means the delimiter does not fit the rest of the sample
+			break
+		}
+		if len(record) == len(header) {
+			consistent++
+		}
+	}
+	return consistent, len(header)
+}
+
+// delimiterName returns a readable name of a delimiter for messages
+func delimiterName(delimiter rune) string {
+	switch delimiter {
+	case csvDelimiter:
+		return "comma"
+	case ssvDelimiter:
+		return "semicolon"
+	case tsvDelimiter:
+		return "tab"
+	case psvDelimiter:
+		return "pipe"
+	default:
+		return fmt.Sprintf("%q", delimiter)
+	}
+}
+
+// WithDelimiter sets the field delimiter of an input, such as ';' or '|'.
+//
+// Many European CSV exports separate fields with semicolons and would otherwise be
+// loaded as a single column. The delimiter applies to CSV, TSV, SSV, and PSV files, to
+// such files of a directory, and to readers of FileTypeDelimited; other formats
+// ignore it. It takes precedence over WithDelimiterDetection.
+//
+// The delimiter must be a valid rune other than a double quote, carriage return, or
+// line feed; Build fails with ErrCodeInvalidConfig otherwise.
+//
+// An InputOption is also an Option: passed to OpenWithOptions or DBBuilder.Apply, it
+// applies to every input that does not set its own delimiter.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPathWithOptions("sales.csv", filesql.WithDelimiter(';'))
+func WithDelimiter(delimiter rune) InputOption {
+	return func(o *inputOptions) {
+		o.delimiter = delimiter
+	}
+}
+
+// validateDelimiter checks that a delimiter set with WithDelimiter can separate fields
+func validateDelimiter(delimiter rune) error {
+	if delimiter == 0 {
+		return nil
+	}
+	if delimiter == '"' || delimiter == '\r' || delimiter == '\n' || delimiter == utf8.RuneError || !utf8.ValidRune(delimiter) {
+		return newCodedError(ErrCodeInvalidConfig, "invalid delimiter: %q", delimiter)
+	}
+	return nil
+}
+
+// fileDelimiter returns the delimiter a CSV or TSV file path is parsed with, unless it is detected
+func (sp *streamProcessor) fileDelimiter(filePath string) rune {
+	if delimiter := sp.fileOptions[filePath].delimiter; delimiter != 0 {
+		return delimiter
+	}
+	return defaultDelimiter(newFile(filePath).getFileType())
+}
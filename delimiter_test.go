@@ -0,0 +1,218 @@
+package filesql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDelimiter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		sample   string
+		fallback rune
+		want     rune
+	}{
+		{name: "comma", sample: "id,name\n1,Gina\n", fallback: ',', want: ','},
+		{name: "semicolon in csv", sample: "id;price\n1;2,5\n2;3,0\n", fallback: ',', want: ';'},
+		{name: "tab in csv", sample: "id\tname\n1\tGina\n", fallback: ',', want: '\t'},
+		{name: "pipe", sample: "id|name|age\n1|Gina|30\n", fallback: '\t', want: '|'},
+		{name: "comma in tsv", sample: "id,name\n1,Gina\n", fallback: '\t', want: ','},
+		{name: "semicolons inside comma separated values", sample: "id,note\n1,a;b\n2,c\n", fallback: ',', want: ','},
+		{name: "quoted delimiters", sample: "id;note\n1;\"a,b,c\"\n2;\"d,e\"\n", fallback: ',', want: ';'},
+		{name: "tie keeps the fallback", sample: "a,b;c\n1,2;3\n", fallback: ',', want: ','},
+		{name: "single column keeps the fallback", sample: "name\nGina\n", fallback: ',', want: ','},
+		{name: "empty sample keeps the fallback", sample: "", fallback: '\t', want: '\t'},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, string(tt.want), string(detectDelimiter([]byte(tt.sample), tt.fallback)))
+		})
+	}
+}
+
+func TestSniffDelimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("the reader still yields all data", func(t *testing.T) {
+		t.Parallel()
+
+		data := "id;name\n" + strings.Repeat("1;Gina\n", 2000)
+		reader, delimiter, err := sniffDelimiter(strings.NewReader(data), ',')
+		require.NoError(t, err)
+		assert.Equal(t, ';', delimiter)
+
+		all, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, data, string(all))
+	})
+
+	t.Run("a line cut off by the sample is ignored", func(t *testing.T) {
+		t.Parallel()
+
+		line := "1;Gina;" + strings.Repeat("x", 93) + "\n"
+		data := "id;name;note\n" + strings.Repeat(line, delimiterSampleSize/len(line)+1)
+		_, delimiter, err := sniffDelimiter(strings.NewReader(data), ',')
+		require.NoError(t, err)
+		assert.Equal(t, ';', delimiter)
+	})
+}
+
+func TestDBBuilder_WithDelimiterDetection(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a semicolon separated csv file is split into columns", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "sales.csv")
+		require.NoError(t, os.WriteFile(path, []byte("date;amount\n2024-01-01;100\n2024-01-02;250\n"), 0600))
+
+		db := openTestDB(t, NewBuilder().AddPath(path).WithDelimiterDetection())
+		var total int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT SUM(amount) FROM sales").Scan(&total))
+		assert.Equal(t, 350, total)
+
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.Equal(t, []LoadWarning{{
+			Table:   "sales",
+			Message: "detected semicolon delimiter instead of the comma delimiter of the file type",
+		}}, warnings)
+	})
+
+	t.Run("compressed readers are detected after decompression", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte("id|name\n1|Gina\n"))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+
+		db := openTestDB(t, NewBuilder().AddReader(&buf, "users", FileTypeCSVGZ).WithDelimiterDetection())
+		var name string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT name FROM users").Scan(&name))
+		assert.Equal(t, "Gina", name)
+	})
+
+	t.Run("matching delimiters raise no warning", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\tname\n1\tGina\n"), "users", FileTypeTSV).
+			WithDelimiterDetection())
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("without detection the extension decides", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(strings.NewReader("date;amount\n2024-01-01;100\n"), "sales", FileTypeCSV))
+		columns, err := getSQLiteTableColumns(db, "sales")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"date;amount"}, columns)
+	})
+
+	t.Run("warnings are not dumped", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("date;amount\n2024-01-01;100\n"), "sales", FileTypeCSV).
+			WithDelimiterDetection())
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir))
+
+		entries, err := os.ReadDir(outputDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "sales.csv", entries[0].Name())
+	})
+}
+
+func TestDBBuilder_WithDelimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a semicolon separated csv file is split into columns", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "sales.csv")
+		require.NoError(t, os.WriteFile(path, []byte("date;amount\n2024-01-01;100\n2024-01-02;250\n"), 0600))
+
+		db := openTestDB(t, NewBuilder().AddPathWithOptions(path, WithDelimiter(';')))
+		var total int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT SUM(amount) FROM sales").Scan(&total))
+		assert.Equal(t, 350, total)
+
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("the delimiter applies to the files of a directory only", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv"), []byte("id|amount\n1|100\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "products.tsv.gz"), gzipData(t, "id|name\n1|pen\n"), 0600))
+		users := filepath.Join(t.TempDir(), "users.csv")
+		require.NoError(t, os.WriteFile(users, []byte("id,name\n1,Gina\n"), 0600))
+
+		db := openTestDB(t, NewBuilder().AddPathWithOptions(dir, WithDelimiter('|')).AddPath(users))
+		for table, want := range map[string][]string{
+			"orders":   {"id", "amount"},
+			"products": {"id", "name"},
+			"users":    {"id", "name"},
+		} {
+			columns, err := getSQLiteTableColumns(db, table)
+			require.NoError(t, err)
+			assert.Equal(t, want, columns, table)
+		}
+	})
+
+	t.Run("the delimiter takes precedence over detection", func(t *testing.T) {
+		t.Parallel()
+
+		data := "a;b|c\n1;2|3\n"
+		db := openTestDB(t, NewBuilder().
+			AddReaderWithOptions(strings.NewReader(data), "values", FileTypeDelimited, WithDelimiter('|')).
+			WithDelimiterDetection())
+		columns, err := getSQLiteTableColumns(db, "values")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a;b", "c"}, columns)
+	})
+
+	t.Run("delimited readers default to a comma", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id,name\n1,Gina\n"), "users", FileTypeDelimited))
+		columns, err := getSQLiteTableColumns(db, "users")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name"}, columns)
+	})
+
+	t.Run("invalid delimiters fail the build", func(t *testing.T) {
+		t.Parallel()
+
+		for _, delimiter := range []rune{'"', '\n', '\r', utf8.RuneError, -1} {
+			_, err := NewBuilder().
+				AddReaderWithOptions(strings.NewReader("a,b\n"), "values", FileTypeCSV, WithDelimiter(delimiter)).
+				Build(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+		}
+	})
+}
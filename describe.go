@@ -0,0 +1,163 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// describeScanRows is the number of rows Describe reads from each table to infer
+	// the column types
+	describeScanRows = 1000
+	// describeSampleRows is the number of rows Describe prints for each table
+	describeSampleRows = 5
+)
+
+// Describe writes a human-readable summary of the tables of the given inputs to w:
+// every table with its source, its columns and their inferred types, and its first
+// rows. It accepts the same paths as Open.
+//
+// Describe reads only the first rows of each table instead of loading the inputs, so
+// it is quick even for very large files, and CLI tools can offer a "head and schema"
+// command without reimplementing it. The types are inferred from those rows, so they
+// may differ from the types a full load infers with TypeInferenceFullScan.
+//
+// Example:
+//
+//	if err := filesql.Describe(os.Stdout, "users.csv", "sales.xlsx"); err != nil {
+//		return err
+//	}
+//
+// prints
+//
+//	table users
+//	  source: users.csv
+//	  rows: 2
+//	  columns:
+//	    id    INTEGER
+//	    name  TEXT
+//	  first rows:
+//	    id  name
+//	    1   alice
+//	    2   bob
+//
+// The row count is "at least 1000" for tables with more rows than Describe reads.
+func Describe(w io.Writer, paths ...string) error {
+	return DescribeContext(context.Background(), w, paths...)
+}
+
+// DescribeContext is like Describe with a context that cancels reading the inputs.
+func DescribeContext(ctx context.Context, w io.Writer, paths ...string) error {
+	builder := NewBuilder().
+		AddPaths(paths...).
+		SetDefaultChunkSize(describeScanRows).
+		EnableLoadSummary()
+	builder.streamProcessor.firstChunkOnly = true
+
+	validatedBuilder, err := builder.Build(ctx)
+	if err != nil {
+		return err
+	}
+	db, err := validatedBuilder.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	summary, err := GetLoadSummary(db)
+	if err != nil {
+		return err
+	}
+	for i, table := range summary.Tables {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := describeTable(ctx, w, db, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describeTable writes the summary of one table of db to w
+func describeTable(ctx context.Context, w io.Writer, db *sql.DB, table TableLoadSummary) error {
+	columns, err := tableColumnTypes(ctx, db, table.Name)
+	if err != nil {
+		return err
+	}
+	rows := fmt.Sprint(table.Rows)
+	if table.Rows >= describeScanRows {
+		rows = fmt.Sprintf("at least %d", table.Rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "table %s\n", table.Name)
+	fmt.Fprintf(tw, "  source: %s\n", table.Input)
+	fmt.Fprintf(tw, "  rows: %s\n", rows)
+	fmt.Fprintln(tw, "  columns:")
+	for _, column := range columns {
+		fmt.Fprintf(tw, "    %s\t%s\n", describeValue(column.name), column.declType)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if table.Rows == 0 {
+		return nil
+	}
+
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+	}
+	sample, err := db.QueryContext(ctx, fmt.Sprintf("%s LIMIT %d", selectColumnsQuery(table.Name, names), describeSampleRows)) //nolint:gosec // Identifiers are quoted
+	if err != nil {
+		return fmt.Errorf("failed to read rows of table %s: %w", table.Name, err)
+	}
+	defer sample.Close()
+
+	fmt.Fprintln(tw, "  first rows:")
+	fmt.Fprintf(tw, "    %s\n", describeRow(names))
+	values := make([]any, len(names))
+	scanArgs := make([]any, len(names))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	formatter := newValueFormatter(names, NewDumpOptions())
+	for sample.Next() {
+		if err := sample.Scan(scanArgs...); err != nil {
+			return fmt.Errorf("failed to read rows of table %s: %w", table.Name, err)
+		}
+		record := make([]string, len(values))
+		for i, value := range values {
+			record[i] = "NULL"
+			if value != nil {
+				record[i] = formatter.format(i, value)
+			}
+		}
+		fmt.Fprintf(tw, "    %s\n", describeRow(record))
+	}
+	if err := sample.Err(); err != nil {
+		return fmt.Errorf("failed to read rows of table %s: %w", table.Name, err)
+	}
+	return tw.Flush()
+}
+
+// describeRow joins the values of a row into tab-separated cells
+func describeRow(values []string) string {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = describeValue(value)
+	}
+	return strings.Join(cells, "\t")
+}
+
+// describeValue escapes the characters of value that would break the layout
+func describeValue(value string) string {
+	return strings.NewReplacer("\t", `\t`, "\r", `\r`, "\n", `\n`).Replace(value)
+}
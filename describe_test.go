@@ -0,0 +1,83 @@
+package filesql
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tables are described with their columns and first rows", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		usersPath := filepath.Join(dir, "users.csv")
+		require.NoError(t, os.WriteFile(usersPath, []byte("id,name,note\n1,alice,\"line1\nline2\"\n2,bob,\n"), 0600))
+		salesPath := filepath.Join(dir, "sales.xlsx")
+		f := excelize.NewFile()
+		require.NoError(t, f.SetSheetName("Sheet1", "Q1"))
+		require.NoError(t, f.SetSheetRow("Q1", "A1", &[]any{"amount"}))
+		require.NoError(t, f.SetSheetRow("Q1", "A2", &[]any{1.5}))
+		require.NoError(t, f.SaveAs(salesPath))
+		require.NoError(t, f.Close())
+
+		var out bytes.Buffer
+		require.NoError(t, Describe(&out, usersPath, salesPath))
+		assert.Equal(t, "table users\n"+
+			"  source: "+usersPath+"\n"+
+			"  rows: 2\n"+
+			"  columns:\n"+
+			"    id    INTEGER\n"+
+			"    name  TEXT\n"+
+			"    note  TEXT\n"+
+			"  first rows:\n"+
+			"    id  name   note\n"+
+			"    1   alice  line1\\nline2\n"+
+			"    2   bob    \n"+
+			"\n"+
+			"table sales_Q1\n"+
+			"  source: "+salesPath+"\n"+
+			"  rows: 1\n"+
+			"  columns:\n"+
+			"    amount  REAL\n"+
+			"  first rows:\n"+
+			"    amount\n"+
+			"    1.5\n", out.String())
+	})
+
+	t.Run("large files are read only partly", func(t *testing.T) {
+		t.Parallel()
+
+		var content strings.Builder
+		content.WriteString("id\n")
+		for i := range describeScanRows * 3 {
+			fmt.Fprintf(&content, "%d\n", i)
+		}
+		path := filepath.Join(t.TempDir(), "events.csv")
+		require.NoError(t, os.WriteFile(path, []byte(content.String()), 0600))
+
+		var out bytes.Buffer
+		require.NoError(t, Describe(&out, path))
+		assert.Contains(t, out.String(), "  rows: at least 1000\n")
+		assert.Contains(t, out.String(), "    0\n    1\n    2\n    3\n    4\n")
+		assert.NotContains(t, out.String(), "    5\n", "only the first rows are printed")
+	})
+
+	t.Run("missing files fail", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		err := Describe(&out, filepath.Join(t.TempDir(), "missing.csv"))
+		require.Error(t, err)
+		assert.Empty(t, out.String())
+	})
+}
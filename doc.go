@@ -1,5 +1,5 @@
 // Package filesql provides a file-based SQL driver implementation that enables
-// querying CSV, TSV, LTSV, Parquet, and Excel (XLSX) files using SQLite3 SQL syntax.
+// querying CSV, TSV, LTSV, JSON Lines, Parquet, Excel (XLSX, XLS), and ODS files using SQLite3 SQL syntax.
 //
 // filesql allows you to treat structured text files as SQL databases without
 // any data import or transformation steps. It uses SQLite3 as an in-memory
@@ -8,8 +8,9 @@
 //
 // # Features
 //
-//   - Query CSV, TSV, LTSV, Parquet, and Excel (XLSX) files using standard SQL
-//   - Automatic handling of compressed files (gzip, bzip2, xz, zstandard)
+//   - Query CSV, TSV, LTSV, JSON Lines, Parquet, Excel (XLSX, XLS), and ODS files using standard SQL
+//   - Automatic handling of compressed files (gzip, bzip2, xz, zstandard, lz4, brotli)
+//     and zip and tar archives
 //   - Support for multiple input sources (files, directories, io.Reader, embed.FS)
 //   - Efficient streaming for large files with configurable chunk sizes
 //   - Cross-platform compatibility (Linux, macOS, Windows)
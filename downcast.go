@@ -0,0 +1,266 @@
+package filesql
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DowncastKind is the reason why values of a column are ambiguous or lossy in a text file.
+type DowncastKind int
+
+const (
+	// DowncastRoundedFloat means the float format rounded REAL values, so the file
+	// holds less precision than the table
+	DowncastRoundedFloat DowncastKind = iota + 1
+	// DowncastWholeFloat means REAL values were written without a fractional part,
+	// such as "3", so they are loaded back as INTEGER
+	DowncastWholeFloat
+	// DowncastExponentFloat means REAL values were written in exponent notation, such
+	// as "1e+21", which many spreadsheets and CSV tools read as text
+	DowncastExponentFloat
+	// DowncastTimeLayout means time values were written in Go's default layout, such
+	// as "2024-01-02 15:04:05 +0000 UTC", which is not ISO 8601
+	DowncastTimeLayout
+	// DowncastAmbiguousDate means dates such as "03/04/2024" were written, which read
+	// as a different date when day and month are swapped
+	DowncastAmbiguousDate
+	// DowncastBinary means BLOB values were written as lists of bytes, such as
+	// "[104 105]", which cannot be loaded back as BLOB
+	DowncastBinary
+)
+
+// String returns the string representation of DowncastKind
+func (k DowncastKind) String() string {
+	switch k {
+	case DowncastRoundedFloat:
+		return "rounded float"
+	case DowncastWholeFloat:
+		return "whole float"
+	case DowncastExponentFloat:
+		return "exponent float"
+	case DowncastTimeLayout:
+		return "time layout"
+	case DowncastAmbiguousDate:
+		return "ambiguous date"
+	case DowncastBinary:
+		return "binary"
+	default:
+		return "unknown"
+	}
+}
+
+// DowncastIssue reports values of one column whose text form in an exported file is
+// ambiguous or lossy.
+type DowncastIssue struct {
+	// Table is the exported table
+	Table string
+	// Column is the column of the values
+	Column string
+	// Kind is why the values are ambiguous or lossy
+	Kind DowncastKind
+	// Count is the number of affected values in the column
+	Count int
+	// Value is the first affected value as stored in the table
+	Value string
+	// Written is the text the first affected value was written as
+	Written string
+}
+
+// String returns a human-readable description of the issue
+func (i DowncastIssue) String() string {
+	return fmt.Sprintf("%s.%s: %d %s value(s), e.g. %s written as %q", i.Table, i.Column, i.Count, i.Kind, i.Value, i.Written)
+}
+
+// WithDowncastHandler reports values whose text form is ambiguous or lossy when
+// tables are exported to CSV, TSV, or LTSV.
+//
+// Typed data loses information in text formats: a float format may round REAL
+// values, a REAL 3.0 is written as "3" and loaded back as INTEGER, and a date such as
+// "03/04/2024" means different days in different countries. With a handler, every
+// exported table is checked, and handler is called once per column and kind of issue
+// after the file of the table is written, so the format options can be adjusted
+// before the files are distributed. The files are written as usual.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().
+//		WithFloatFormat("%.2f").
+//		WithDowncastHandler(func(issue filesql.DowncastIssue) {
+//			log.Printf("check before publishing: %s", issue)
+//		})
+//	err := filesql.DumpDatabase(db, "./output", options)
+//
+// Other formats keep the types of values, so they are not checked.
+func (o DumpOptions) WithDowncastHandler(handler func(DowncastIssue)) DumpOptions {
+	o.DowncastHandler = handler
+	return o
+}
+
+// checksDowncasts reports whether values exported with the options are checked for downcasts
+func (o DumpOptions) checksDowncasts() bool {
+	if o.DowncastHandler == nil {
+		return false
+	}
+	switch o.Format {
+	case OutputFormatCSV, OutputFormatTSV, OutputFormatSSV, OutputFormatPSV, OutputFormatLTSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// downcastTracker collects the downcast issues of the values of one exported table
+type downcastTracker struct {
+	columns []string
+	issues  []downcastIssue
+}
+
+// downcastIssue is a DowncastIssue with the index of its column
+type downcastIssue struct {
+	DowncastIssue
+	column int
+}
+
+// newDowncastTracker returns a tracker for the values of columns
+func newDowncastTracker(columns []string) *downcastTracker {
+	return &downcastTracker{columns: columns}
+}
+
+// check records an issue if value of the column at index i, written as text, is
+// ambiguous or lossy
+func (t *downcastTracker) check(i int, value any, text string) {
+	if kind, ok := downcastKindOf(value, text); ok {
+		t.record(i, kind, value, text)
+	}
+}
+
+// downcastKindOf returns the kind of issue of value written as text, if any
+func downcastKindOf(value any, text string) (DowncastKind, bool) {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, false
+		}
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err != nil || parsed != v {
+			return DowncastRoundedFloat, true
+		}
+		if strings.ContainsAny(text, "eE") {
+			return DowncastExponentFloat, true
+		}
+		if _, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return DowncastWholeFloat, true
+		}
+	case time.Time:
+		return DowncastTimeLayout, true
+	case []byte:
+		return DowncastBinary, true
+	case string:
+		if isAmbiguousDate(v) {
+			return DowncastAmbiguousDate, true
+		}
+	}
+	return 0, false
+}
+
+// isAmbiguousDate reports whether value is a date whose day and month can be swapped:
+// two numbers from 1 to 12 that differ, each followed by "/", ".", or "-", and a year
+// of 2 to 4 digits that ends the value or is followed by a character that is not a
+// letter, digit, or underscore
+func isAmbiguousDate(value string) bool {
+	if len(value) < len("1/2/24") || value[0] < '0' || value[0] > '9' {
+		return false
+	}
+	first, rest, ok := cutDatePart(value)
+	if !ok {
+		return false
+	}
+	second, rest, ok := cutDatePart(rest)
+	if !ok || first == second || first < 1 || first > 12 || second < 1 || second > 12 {
+		return false
+	}
+	year := digitPrefixLength(rest)
+	if year < 2 || year > 4 {
+		return false
+	}
+	if year < len(rest) {
+		next := rest[year]
+		return next != '_' && (next < 'a' || next > 'z') && (next < 'A' || next > 'Z')
+	}
+	return true
+}
+
+// cutDatePart parses the number of 1 or 2 digits and the separator at the start of a
+// date and returns the number and the rest of the date
+func cutDatePart(date string) (int, string, bool) {
+	digits := digitPrefixLength(date)
+	if digits < 1 || digits > 2 || digits == len(date) || !strings.ContainsRune("/.-", rune(date[digits])) {
+		return 0, "", false
+	}
+	number, _ := strconv.Atoi(date[:digits]) //nolint:errcheck // The prefix is digits
+	return number, date[digits+1:], true
+}
+
+// digitPrefixLength returns the number of ASCII digits at the start of s
+func digitPrefixLength(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// record counts a value of the column at index i with an issue of kind
+func (t *downcastTracker) record(i int, kind DowncastKind, value any, text string) {
+	for j := range t.issues {
+		if t.issues[j].column == i && t.issues[j].Kind == kind {
+			t.issues[j].Count++
+			return
+		}
+	}
+	t.issues = append(t.issues, downcastIssue{
+		DowncastIssue: DowncastIssue{
+			Column:  t.columns[i],
+			Kind:    kind,
+			Count:   1,
+			Value:   downcastValueString(value),
+			Written: text,
+		},
+		column: i,
+	})
+}
+
+// downcastValueString returns the exact text of a value as stored in the table
+func downcastValueString(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return fmt.Sprintf("x'%x'", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// report calls handler with the issues of table in column order. It does nothing on
+// a nil receiver.
+func (t *downcastTracker) report(table string, handler func(DowncastIssue)) {
+	if t == nil {
+		return
+	}
+	slices.SortStableFunc(t.issues, func(a, b downcastIssue) int {
+		if a.column != b.column {
+			return a.column - b.column
+		}
+		return int(a.Kind - b.Kind)
+	})
+	for _, issue := range t.issues {
+		issue.Table = table
+		handler(issue.DowncastIssue)
+	}
+}
@@ -0,0 +1,108 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOptions_WithDowncastHandler(t *testing.T) {
+	t.Parallel()
+
+	// setup opens a database with a measurements table of typed values
+	setup := func(t *testing.T) *sql.DB {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "sites.csv")
+		require.NoError(t, os.WriteFile(path, []byte("id,name\n1,tokyo\n"), 0600))
+		db := openTestDB(t, NewBuilder().AddPath(path))
+
+		for _, query := range []string{
+			"CREATE TABLE measurements (value REAL, measured_at DATETIME, raw BLOB, day TEXT)",
+			"INSERT INTO measurements VALUES (2.345, '2024-01-02 03:04:05', x'ff00', '03/04/2024')",
+			"INSERT INTO measurements VALUES (3.0, NULL, x'6869', '13/04/2024')",
+			"INSERT INTO measurements VALUES (1e21, NULL, NULL, '2024-04-03')",
+			"INSERT INTO measurements VALUES (4.0, NULL, NULL, '05.06.2024')",
+		} {
+			_, err := db.ExecContext(context.Background(), query)
+			require.NoError(t, err)
+		}
+		return db
+	}
+	dump := func(t *testing.T, db *sql.DB, options DumpOptions) []DowncastIssue {
+		t.Helper()
+
+		var issues []DowncastIssue
+		require.NoError(t, DumpDatabase(db, t.TempDir(), options.WithDowncastHandler(func(issue DowncastIssue) {
+			issues = append(issues, issue)
+		})))
+		return issues
+	}
+
+	t.Run("ambiguous and lossy values are reported per column", func(t *testing.T) {
+		t.Parallel()
+
+		issues := dump(t, setup(t), NewDumpOptions())
+		assert.Equal(t, []DowncastIssue{
+			{Table: "measurements", Column: "value", Kind: DowncastWholeFloat, Count: 2, Value: "3", Written: "3"},
+			{Table: "measurements", Column: "value", Kind: DowncastExponentFloat, Count: 1, Value: "1e+21", Written: "1e+21"},
+			{Table: "measurements", Column: "measured_at", Kind: DowncastTimeLayout, Count: 1,
+				Value: "2024-01-02T03:04:05Z", Written: "2024-01-02 03:04:05 +0000 UTC"},
+			{Table: "measurements", Column: "raw", Kind: DowncastBinary, Count: 2, Value: "x'ff00'", Written: "[255 0]"},
+			{Table: "measurements", Column: "day", Kind: DowncastAmbiguousDate, Count: 2, Value: "03/04/2024", Written: "03/04/2024"},
+		}, issues)
+		assert.Equal(t, `measurements.day: 2 ambiguous date value(s), e.g. 03/04/2024 written as "03/04/2024"`, issues[4].String())
+	})
+
+	t.Run("values rounded by the float format are reported", func(t *testing.T) {
+		t.Parallel()
+
+		issues := dump(t, setup(t), NewDumpOptions().WithFormat(OutputFormatTSV).WithColumnFloatFormat("value", "%.2f"))
+		require.NotEmpty(t, issues)
+		assert.Equal(t, DowncastIssue{
+			Table: "measurements", Column: "value", Kind: DowncastRoundedFloat, Count: 1, Value: "2.345", Written: "2.35",
+		}, issues[0])
+		assert.Equal(t, DowncastTimeLayout, issues[1].Kind)
+	})
+
+	t.Run("formats that keep types are not checked", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, dump(t, setup(t), NewDumpOptions().WithFormat(OutputFormatJSONL)))
+	})
+}
+
+func TestIsAmbiguousDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "03/04/2024", want: true},
+		{value: "3.4.24", want: true},
+		{value: "12-11-2024 10:00", want: true},
+		{value: "04/04/2024", want: false},
+		{value: "4/04/2024", want: false},
+		{value: "13/04/2024", want: false},
+		{value: "00/04/2024", want: false},
+		{value: "2024-03-04", want: false},
+		{value: "03/04/20245", want: false},
+		{value: "03/04/2", want: false},
+		{value: "03/04/2024x", want: false},
+		{value: "03/04", want: false},
+		{value: "003/04/2024", want: false},
+		{value: "03:04:2024", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, isAmbiguousDate(tt.value))
+		})
+	}
+}
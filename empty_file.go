@@ -0,0 +1,185 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// EmptyFilePolicy controls how zero-byte files found in directories are loaded.
+type EmptyFilePolicy int
+
+const (
+	// EmptyFileSkip skips zero-byte files and records a load warning for each (default)
+	EmptyFileSkip EmptyFilePolicy = iota
+	// EmptyFileCreateTable creates a table without rows for zero-byte files. The files
+	// have no header, so the columns are the ones named with WithColumnNames, or a
+	// single TEXT column named column1.
+	EmptyFileCreateTable
+	// EmptyFileError fails the load with ErrCodeEmptyData
+	EmptyFileError
+)
+
+// String returns the string representation of EmptyFilePolicy
+func (p EmptyFilePolicy) String() string {
+	switch p {
+	case EmptyFileSkip:
+		return "skip"
+	case EmptyFileCreateTable:
+		return "create_table"
+	case EmptyFileError:
+		return "error"
+	default:
+		return "skip"
+	}
+}
+
+// parseEmptyFilePolicy converts a policy name into an EmptyFilePolicy
+func parseEmptyFilePolicy(name string) (EmptyFilePolicy, error) {
+	for _, policy := range []EmptyFilePolicy{EmptyFileSkip, EmptyFileCreateTable, EmptyFileError} {
+		if strings.EqualFold(name, policy.String()) {
+			return policy, nil
+		}
+	}
+	return EmptyFileSkip, newCodedError(ErrCodeInvalidConfig, "unsupported empty file policy: %s", name)
+}
+
+// WithEmptyFilePolicy sets how zero-byte files found in directories are loaded.
+//
+// A directory of exports often holds a file that an upstream job left empty. By
+// default (EmptyFileSkip) such files are skipped with a load warning (see
+// GetLoadWarnings), so one empty file does not block the other tables of the
+// directory. EmptyFileCreateTable creates a table without rows instead, so queries
+// that join or count it keep working, and EmptyFileError fails Build.
+//
+// The policy applies to files found in directories added with AddPath, AddPaths, or
+// AddPathWithOptions. A zero-byte file added by its own path fails the load, since
+// the caller asked for exactly that file.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("exports/"). // 200 files, one of them empty
+//		WithEmptyFilePolicy(filesql.EmptyFileCreateTable)
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithEmptyFilePolicy(policy EmptyFilePolicy) *DBBuilder {
+	b.emptyFilePolicy = policy
+	return b
+}
+
+// applyEmptyFilePolicy removes the zero-byte files found in directories from collected
+// and records them as skipped, marks them to be loaded as empty tables, or fails,
+// depending on the policy
+func (b *DBBuilder) applyEmptyFilePolicy(collected *collectedFiles) error {
+	empty, err := collected.emptyDirectoryFiles(b.paths)
+	if err != nil || len(empty) == 0 {
+		return err
+	}
+
+	switch b.emptyFilePolicy {
+	case EmptyFileCreateTable:
+		b.streamProcessor.emptyFileTables = make(map[string]bool, len(empty))
+		for _, path := range empty {
+			b.streamProcessor.emptyFileTables[path] = true
+		}
+	case EmptyFileError:
+		return newCodedError(ErrCodeEmptyData, "file %s is empty", empty[0])
+	default:
+		collected.remove(empty)
+		b.emptyFiles = empty
+	}
+	return nil
+}
+
+// emptyDirectoryFiles returns the collected zero-byte files that were found in
+// directories of inputs or by glob patterns rather than added by their own path
+func (c *collectedFiles) emptyDirectoryFiles(inputs []string) ([]string, error) {
+	var empty []string
+	for i, path := range c.paths {
+		if path == inputs[c.origins[i]] {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat path %s: %w", path, err)
+		}
+		if info.Size() == 0 {
+			empty = append(empty, path)
+		}
+	}
+	return empty, nil
+}
+
+// remove removes paths from the collected files
+func (c *collectedFiles) remove(paths []string) {
+	var (
+		kept    []string
+		origins []int
+	)
+	for i, path := range c.paths {
+		if !slices.Contains(paths, path) {
+			kept = append(kept, path)
+			origins = append(origins, c.origins[i])
+		}
+	}
+	c.paths, c.origins = kept, origins
+}
+
+// recordEmptyFileWarnings records a load warning for every zero-byte file that was skipped
+func (b *DBBuilder) recordEmptyFileWarnings(ctx context.Context, db *sql.DB) error {
+	for _, path := range b.emptyFiles {
+		message := fmt.Sprintf("skipped %s, which is empty", path)
+		if err := recordLoadWarning(ctx, db, b.streamProcessor.fileTableName(path), message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createEmptyFileTable creates the table without rows of a zero-byte file found in a
+// directory with EmptyFileCreateTable
+func (sp *streamProcessor) createEmptyFileTable(ctx context.Context, db *sql.DB, filePath string) error {
+	tableName := sp.fileTableName(filePath)
+	if sp.mergesInto(tableName) {
+		// An empty file has no rows to append to an existing table
+		exists, err := tableExists(ctx, db, tableName)
+		if err != nil || exists {
+			return err
+		}
+	}
+	tableName, loadingName, err := sp.claimTable(ctx, db, tableName)
+	if err != nil {
+		return err
+	}
+
+	names := sp.fileOptions[filePath].columnNames
+	if len(names) == 0 {
+		names = generatedColumnNames(1)
+	}
+	columns := make([]columnInfo, len(names))
+	for i, name := range names {
+		columns[i] = newColumnInfoWithType(name, columnTypeText)
+	}
+	chunk, err := sp.prepareChunk(&tableChunk{
+		tableName:  loadingName,
+		headers:    names,
+		columnInfo: columns,
+	}, 1)
+	if err != nil {
+		return err
+	}
+	if err := sp.createTableFromChunk(ctx, db, chunk); err != nil {
+		return fmt.Errorf("failed to create empty table: %w", err)
+	}
+	if err := sp.publishTable(ctx, db, loadingName, tableName); err != nil {
+		return err
+	}
+	if sp.observer != nil {
+		sp.observer.tableLoaded(tableName)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithEmptyFilePolicy(t *testing.T) {
+	t.Parallel()
+
+	// writeDirectory writes users.csv and the zero-byte file empty.csv into a new directory
+	writeDirectory := func(t *testing.T) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name\n1,alice\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.csv"), nil, 0600))
+		return dir
+	}
+
+	t.Run("empty files are skipped with a warning by default", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeDirectory(t)
+		db := openTestDB(t, NewBuilder().AddPath(dir))
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"users"}, tables)
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.Equal(t, []LoadWarning{
+			{Table: "empty", Message: "skipped " + filepath.Join(dir, "empty.csv") + ", which is empty"},
+		}, warnings)
+	})
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "empty files become tables without rows",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().AddPath(writeDirectory(t)).WithEmptyFilePolicy(EmptyFileCreateTable)
+			},
+			tables: []string{"empty", "users"},
+			want: map[string][]string{
+				"SELECT COUNT(*) FROM empty":                                 {"0"},
+				"SELECT name || ' ' || type FROM pragma_table_info('empty')": {"column1 TEXT"},
+			},
+		},
+		{
+			name: "empty tables take the given column names",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := t.TempDir()
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "day1.csv"), []byte("1,alice\n"), 0600))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "day2.csv"), nil, 0600))
+				return NewBuilder().
+					AddPathWithOptions(dir, WithColumnNames("id", "name")).
+					WithEmptyFilePolicy(EmptyFileCreateTable)
+			},
+			want: map[string][]string{
+				"SELECT COUNT(*) FROM (SELECT id, name FROM day1 UNION ALL SELECT id, name FROM day2)": {"1"},
+			},
+		},
+		{
+			name: "the policy is an option of OpenWithOptions",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().AddPath(writeDirectory(t)).Apply(WithEmptyFilePolicy(EmptyFileCreateTable))
+			},
+			tables: []string{"empty", "users"},
+		},
+		{
+			name: "empty files fail the build with the error policy",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().AddPath(writeDirectory(t)).WithEmptyFilePolicy(EmptyFileError)
+			},
+			wantErr:    ErrCodeEmptyData,
+			wantErrMsg: "empty.csv is empty",
+		},
+		{
+			name: "empty files added by their own path fail",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().
+					AddPath(filepath.Join(writeDirectory(t), "empty.csv")).
+					WithEmptyFilePolicy(EmptyFileCreateTable)
+			},
+			wantErr: ErrCodeEmptyData,
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// EmptyTablePolicy controls how DumpDatabase exports tables without rows.
+type EmptyTablePolicy int
+
+const (
+	// EmptyTableHeaderOnly writes a file with the column names only (default).
+	// CSV, TSV, and XLSX files contain the header row, and Parquet files contain the
+	// schema without rows. LTSV and JSON Lines have no header, so the file is empty.
+	EmptyTableHeaderOnly EmptyTablePolicy = iota
+	// EmptyTableSkip writes no file for tables without rows
+	EmptyTableSkip
+)
+
+// String returns the string representation of EmptyTablePolicy
+func (p EmptyTablePolicy) String() string {
+	switch p {
+	case EmptyTableHeaderOnly:
+		return "header_only"
+	case EmptyTableSkip:
+		return "skip"
+	default:
+		return "header_only"
+	}
+}
+
+// WithEmptyTablePolicy sets how tables without rows are exported.
+//
+// By default every table is exported, and a table without rows produces a file with
+// its header only, so loaders that expect a fixed set of files and columns keep
+// working when a day's data is empty. Use EmptyTableSkip to write no file instead.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().WithEmptyTablePolicy(filesql.EmptyTableSkip)
+//	err := filesql.DumpDatabase(db, "./output", options)
+func (o DumpOptions) WithEmptyTablePolicy(policy EmptyTablePolicy) DumpOptions {
+	o.EmptyTables = policy
+	return o
+}
+
+// skipsTable reports whether the table is not exported because it has no rows
+func (o DumpOptions) skipsTable(ctx context.Context, db *sql.DB, tableName string) (bool, error) {
+	if o.EmptyTables != EmptyTableSkip {
+		return false, nil
+	}
+
+	var hasRows bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s)", quoteIdentifier(tableName)) //nolint:gosec // Table name is quoted
+	if err := db.QueryRowContext(ctx, query).Scan(&hasRows); err != nil {
+		return false, fmt.Errorf("failed to check rows of table %s: %w", tableName, err)
+	}
+	return !hasRows, nil
+}
+
+// parseEmptyTablePolicy converts a policy name into an EmptyTablePolicy
+func parseEmptyTablePolicy(name string) (EmptyTablePolicy, error) {
+	for _, policy := range []EmptyTablePolicy{EmptyTableHeaderOnly, EmptyTableSkip} {
+		if strings.EqualFold(name, policy.String()) {
+			return policy, nil
+		}
+	}
+	return EmptyTableHeaderOnly, newCodedError(ErrCodeInvalidConfig, "unsupported empty table policy: %s", name)
+}
@@ -0,0 +1,100 @@
+package filesql
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOptions_WithEmptyTablePolicy(t *testing.T) {
+	t.Parallel()
+
+	// openEmptyTableDB loads a table with rows and a table with a header only
+	openEmptyTableDB := func(t *testing.T) *sql.DB {
+		t.Helper()
+
+		return openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,alice\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id,amount\n"), "orders", FileTypeCSV))
+	}
+
+	t.Run("empty tables are written with their header by default", func(t *testing.T) {
+		t.Parallel()
+
+		db := openEmptyTableDB(t)
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir))
+
+		data, err := os.ReadFile(filepath.Join(outputDir, "orders.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "id,amount\n", string(data))
+	})
+
+	t.Run("every format writes a file for empty tables", func(t *testing.T) {
+		t.Parallel()
+
+		for _, format := range []OutputFormat{OutputFormatTSV, OutputFormatLTSV, OutputFormatParquet, OutputFormatXLSX} {
+			db := openEmptyTableDB(t)
+			outputDir := t.TempDir()
+			options := NewDumpOptions().WithFormat(format)
+			require.NoError(t, DumpDatabase(db, outputDir, options), format.String())
+			assert.FileExists(t, filepath.Join(outputDir, "orders"+options.FileExtension()), format.String())
+		}
+	})
+
+	t.Run("empty files load back with their columns", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			format OutputFormat
+			table  string
+		}{
+			{format: OutputFormatCSV, table: "orders"},
+			{format: OutputFormatParquet, table: "orders"},
+			{format: OutputFormatXLSX, table: "orders_orders"},
+		}
+		for _, tt := range tests {
+			db := openEmptyTableDB(t)
+			outputDir := t.TempDir()
+			options := NewDumpOptions().WithFormat(tt.format)
+			require.NoError(t, DumpDatabase(db, outputDir, options), tt.format.String())
+
+			reloaded, err := Open(filepath.Join(outputDir, "orders"+options.FileExtension()))
+			require.NoError(t, err, tt.format.String())
+			columns, err := getSQLiteTableColumns(reloaded, tt.table)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"id", "amount"}, columns, tt.format.String())
+			require.NoError(t, reloaded.Close())
+		}
+	})
+
+	t.Run("empty tables can be skipped", func(t *testing.T) {
+		t.Parallel()
+
+		db := openEmptyTableDB(t)
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir, NewDumpOptions().WithEmptyTablePolicy(EmptyTableSkip)))
+
+		assert.FileExists(t, filepath.Join(outputDir, "users.csv"))
+		assert.NoFileExists(t, filepath.Join(outputDir, "orders.csv"))
+	})
+
+	t.Run("policy names", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "header_only", EmptyTableHeaderOnly.String())
+		assert.Equal(t, "skip", EmptyTableSkip.String())
+
+		policy, err := parseEmptyTablePolicy("SKIP")
+		require.NoError(t, err)
+		assert.Equal(t, EmptyTableSkip, policy)
+
+		_, err = parseEmptyTablePolicy("drop")
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
@@ -0,0 +1,285 @@
+package filesql
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// encodingSampleSize is the number of bytes sampled to detect the encoding of an input
+const encodingSampleSize = 8 * 1024
+
+// WithEncoding sets the character encoding of a text input, such as "shift-jis",
+// "euc-jp", "latin-1", or "utf-16le".
+//
+// filesql stores text as UTF-8, so a file in another encoding is loaded with mojibake
+// column names and values. With WithEncoding, CSV, TSV, LTSV, and JSON Lines inputs are
+// transcoded to UTF-8 while they are loaded; other formats ignore the option. Names
+// are the labels of the WHATWG Encoding Standard and are case-insensitive, with "-"
+// and "_" interchangeable. A byte order mark at the start of the data takes precedence.
+//
+// Text inputs that start with a UTF-16 byte order mark, such as the "Unicode Text"
+// files Excel saves, are transcoded without any option. Set "utf-16le" or "utf-16be"
+// for UTF-16 data without a byte order mark.
+//
+// Build fails with ErrCodeInvalidConfig if the encoding is unknown. Sidecar indexes
+// (see WithSidecarIndex) are not used for transcoded inputs.
+//
+// Like every InputOption, it applies to one input when passed to AddPathWithOptions or
+// AddReaderWithOptions, and to every input without its own encoding when passed to
+// DBBuilder.Apply.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPathWithOptions("uriage.csv", filesql.WithEncoding("shift-jis"))
+func WithEncoding(name string) InputOption {
+	return func(o *inputOptions) {
+		o.encoding = name
+	}
+}
+
+// WithEncoding sets the character encoding of every text input without its own
+// encoding, like Apply(filesql.WithEncoding(name)).
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithEncoding(name string) *DBBuilder {
+	return b.Apply(WithEncoding(name))
+}
+
+// WithEncodingDetection detects the character encoding of text inputs that do not set
+// one with WithEncoding.
+//
+// The first 8 KB of each CSV, TSV, LTSV, and JSON Lines input (after decompression)
+// are examined. Data with a UTF-16 byte order mark, or with mostly zero bytes in every
+// other position, is decoded as UTF-16, and valid UTF-8 is loaded as is. Otherwise the
+// data is decoded as Shift_JIS or EUC-JP if it decodes to Japanese text,
+// and as Latin-1 (windows-1252) if not. Detection is a guess from a sample: set the
+// encoding with WithEncoding when it is known.
+//
+// Every input that is transcoded is recorded as a load warning (see GetLoadWarnings).
+// Sidecar indexes (see WithSidecarIndex) are not used while encoding detection is
+// enabled.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("exports/"). // UTF-8, Shift_JIS, and Latin-1 files
+//		WithEncodingDetection()
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithEncodingDetection() *DBBuilder {
+	b.streamProcessor.detectEncoding = true
+	return b
+}
+
+// lookupEncoding returns the encoding named name; nil means UTF-8, which needs no transcoding
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	candidates := []string{
+		name,
+		strings.ReplaceAll(name, "-", "_"),
+		strings.ReplaceAll(name, "_", "-"),
+		strings.NewReplacer("-", "", "_", "").Replace(name),
+	}
+	for _, candidate := range candidates {
+		enc, err := htmlindex.Get(candidate)
+		if err != nil {
+			continue
+		}
+		if enc == unicode.UTF8 {
+			return nil, nil
+		}
+		return enc, nil
+	}
+	return nil, newCodedError(ErrCodeInvalidConfig, "unsupported encoding: %s", name)
+}
+
+// validateEncoding checks that an encoding set with WithEncoding is known
+func validateEncoding(name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := lookupEncoding(name)
+	return err
+}
+
+// encodingName returns the canonical name of an encoding for messages
+func encodingName(enc encoding.Encoding) string {
+	if name, err := htmlindex.Name(enc); err == nil {
+		return name
+	}
+	return fmt.Sprint(enc)
+}
+
+// isTextFileType reports whether inputs of fileType are text that can be transcoded
+func isTextFileType(fileType FileType) bool {
+	switch fileType.baseType() {
+	case FileTypeCSV, FileTypeTSV, FileTypeSSV, FileTypePSV, FileTypeDelimited, FileTypeLTSV, FileTypeJSONL:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeText returns a reader that yields the text read from reader as UTF-8, using the
+// encoding of the parser or the encoding detected from the data
+func (p *streamingParser) decodeText(reader io.Reader) (io.Reader, error) {
+	if !isTextFileType(p.fileType) {
+		return reader, nil
+	}
+	enc := p.encoding
+	if enc == nil && !p.detectEncoding {
+		// UTF-16 data is only recognized by its byte order mark unless detection is enabled
+		sampled, detected, err := sniffUTF16BOM(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader, enc = sampled, detected
+		if enc != nil {
+			// Sidecar index offsets would be offsets into the transcoded data
+			p.sidecar = nil
+		}
+	}
+	if enc == nil && p.detectEncoding {
+		sampled, detected, err := sniffEncoding(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader, enc = sampled, detected
+		if enc != nil && p.encodingDetected != nil {
+			if err := p.encodingDetected(encodingName(enc)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if enc == nil {
+		return reader, nil
+	}
+	return transform.NewReader(reader, unicode.BOMOverride(enc.NewDecoder())), nil
+}
+
+// sniffEncoding returns the encoding of the data read from reader, nil for UTF-8, and a
+// reader that still yields all data
+func sniffEncoding(reader io.Reader) (io.Reader, encoding.Encoding, error) {
+	buffered := bufio.NewReaderSize(reader, encodingSampleSize)
+	sample, err := buffered.Peek(encodingSampleSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("failed to sample encoding: %w", err)
+	}
+	if len(sample) == encodingSampleSize {
+		// Do not judge the last line by the part that fits into the sample
+		if i := bytes.LastIndexByte(sample, '\n'); i > 0 {
+			sample = sample[:i+1]
+		}
+	}
+	return buffered, detectEncoding(sample), nil
+}
+
+// sniffUTF16BOM returns the UTF-16 encoding of the data read from reader if it starts
+// with a UTF-16 byte order mark, nil otherwise, and a reader that still yields all data
+func sniffUTF16BOM(reader io.Reader) (io.Reader, encoding.Encoding, error) {
+	buffered := bufio.NewReader(reader)
+	bom, err := buffered.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("failed to read byte order mark: %w", err)
+	}
+	return buffered, utf16BOMEncoding(bom), nil
+}
+
+// utf16BOMEncoding returns the UTF-16 encoding of data that starts with a UTF-16 byte
+// order mark, or nil
+func utf16BOMEncoding(data []byte) encoding.Encoding {
+	// The byte order mark itself is removed by the decoder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	default:
+		return nil
+	}
+}
+
+// detectEncoding returns the encoding of sample; nil means UTF-8
+func detectEncoding(sample []byte) encoding.Encoding {
+	if enc := utf16BOMEncoding(sample); enc != nil {
+		return enc
+	}
+	// ASCII text in UTF-16 is valid UTF-8 with zero bytes, so check UTF-16 first
+	if enc := detectUTF16(sample); enc != nil {
+		return enc
+	}
+	if utf8.Valid(sample) {
+		return nil
+	}
+
+	var best encoding.Encoding = charmap.Windows1252
+	bestScore := 0
+	for _, candidate := range []encoding.Encoding{japanese.ShiftJIS, japanese.EUCJP} {
+		if score := japaneseScore(candidate, sample); score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best
+}
+
+// detectUTF16 returns the UTF-16 encoding of sample without a byte order mark if most
+// of its odd (little-endian) or even (big-endian) bytes are zero, as with ASCII text
+func detectUTF16(sample []byte) encoding.Encoding {
+	if len(sample) < 2 {
+		return nil
+	}
+	var evenZeros, oddZeros int
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	half := len(sample) / 2
+	switch {
+	case oddZeros*10 >= half*7 && evenZeros*10 < half:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case evenZeros*10 >= half*7 && oddZeros*10 < half:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	default:
+		return nil
+	}
+}
+
+// japaneseScore returns the number of Japanese characters sample decodes to with enc,
+// or 0 if sample is not valid in enc
+func japaneseScore(enc encoding.Encoding, sample []byte) int {
+	decoded, err := enc.NewDecoder().Bytes(sample)
+	if err != nil {
+		return 0
+	}
+	score := 0
+	for _, r := range string(decoded) {
+		switch {
+		case r == utf8.RuneError:
+			return 0
+		case r >= 0x3040 && r <= 0x30FF, // Hiragana and Katakana
+			r >= 0x4E00 && r <= 0x9FFF, // CJK ideographs
+			r >= 0xFF01 && r <= 0xFF5E, // Full-width ASCII
+			r >= 0x3000 && r <= 0x303F: // CJK punctuation
+			score++
+		}
+	}
+	return score
+}
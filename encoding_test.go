@@ -0,0 +1,194 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encodeText returns text encoded with enc
+func encodeText(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+
+	encoded, err := enc.NewEncoder().Bytes([]byte(text))
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestWithEncoding(t *testing.T) {
+	t.Parallel()
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "Shift_JIS data is transcoded to UTF-8",
+			builder: func(t *testing.T) *DBBuilder {
+				path := filepath.Join(t.TempDir(), "uriage.csv")
+				require.NoError(t, os.WriteFile(path, encodeText(t, japanese.ShiftJIS, "商品名,価格\nりんご,100\nみかん,50\n"), 0600))
+				return NewBuilder().AddPathWithOptions(path, WithEncoding("Shift_JIS"))
+			},
+			want: map[string][]string{`SELECT "商品名" FROM uriage WHERE "価格" = 100`: {"りんご"}},
+		},
+		{
+			name: "builder-wide encodings apply to readers",
+			builder: func(t *testing.T) *DBBuilder {
+				data := encodeText(t, charmap.ISO8859_1, "city,country\nZürich,Schweiz\nMálaga,España\n")
+				return NewBuilder().
+					AddReader(strings.NewReader(string(data)), "cities", FileTypeCSV).
+					WithEncoding("latin-1")
+			},
+			want: map[string][]string{"SELECT country FROM cities WHERE city = 'Málaga'": {"España"}},
+		},
+		{
+			name: "unknown encodings fail the build",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReaderWithOptions(strings.NewReader("a\n1\n"), "t", FileTypeCSV, WithEncoding("klingon"))
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+	})
+}
+
+func TestWithEncodingDetection(t *testing.T) {
+	t.Parallel()
+
+	text := "名前,都市\n山田太郎,東京\n佐藤花子,大阪\n"
+	query := `SELECT "都市" FROM people WHERE "名前" = '佐藤花子'`
+	tests := []struct {
+		name     string
+		data     []byte
+		query    string
+		want     string
+		detected string
+	}{
+		{
+			name:     "Shift_JIS",
+			data:     encodeText(t, japanese.ShiftJIS, text),
+			query:    query,
+			want:     "大阪",
+			detected: "shift_jis",
+		},
+		{
+			name:     "EUC-JP",
+			data:     encodeText(t, japanese.EUCJP, text),
+			query:    query,
+			want:     "大阪",
+			detected: "euc-jp",
+		},
+		{
+			name:     "UTF-16 with BOM",
+			data:     encodeText(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), text),
+			query:    query,
+			want:     "大阪",
+			detected: "utf-16le",
+		},
+		{
+			name:  "UTF-8",
+			data:  []byte(text),
+			query: query,
+			want:  "大阪",
+		},
+		{
+			name:     "ASCII text in UTF-16 without BOM",
+			data:     encodeText(t, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "name,city\nalice,Tokyo\n"),
+			query:    "SELECT city FROM people WHERE name = 'alice'",
+			want:     "Tokyo",
+			detected: "utf-16be",
+		},
+		{
+			name:     "other data is decoded as Latin-1",
+			data:     encodeText(t, charmap.Windows1252, "name,price\ncafé,3€\n"),
+			query:    "SELECT price FROM people WHERE name = 'café'",
+			want:     "3€",
+			detected: "windows-1252",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := openTestDB(t, NewBuilder().
+				AddReader(strings.NewReader(string(tt.data)), "people", FileTypeCSV).
+				WithEncodingDetection())
+			assert.Equal(t, []string{tt.want}, queryRows(t, db, tt.query))
+
+			warnings, err := GetLoadWarnings(db)
+			require.NoError(t, err)
+			if tt.detected == "" {
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, warnings, 1)
+			assert.Equal(t, "people", warnings[0].Table)
+			assert.Contains(t, warnings[0].Message, tt.detected)
+		})
+	}
+
+	t.Run("explicit encodings take precedence", func(t *testing.T) {
+		t.Parallel()
+
+		data := encodeText(t, charmap.ISO8859_1, "name\nü\n")
+		db := openTestDB(t, NewBuilder().
+			AddReaderWithOptions(strings.NewReader(string(data)), "names", FileTypeCSV, WithEncoding("iso-8859-1")).
+			WithEncodingDetection())
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+}
+
+func TestUTF16Inputs(t *testing.T) {
+	t.Parallel()
+
+	// Excel saves "Unicode Text" as tab-separated UTF-16LE with a byte order mark
+	text := "product\tprice\r\nコーヒー\t450\r\ntea\t300\r\n"
+	query := "SELECT price FROM unicode_text WHERE product = 'コーヒー'"
+
+	t.Run("data with a byte order mark is transcoded without options", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "unicode_text.tsv")
+		data := encodeText(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), text)
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		db := openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex())
+		assert.Equal(t, []string{"450"}, queryRows(t, db, query))
+		assert.NoFileExists(t, path+sidecarIndexSuffix)
+	})
+
+	tests := []struct {
+		name    string
+		enc     encoding.Encoding
+		options []InputOption
+	}{
+		{
+			name: "big-endian data with a byte order mark is transcoded",
+			enc:  unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+		},
+		{
+			name:    "data without a byte order mark needs the encoding",
+			enc:     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+			options: []InputOption{WithEncoding("utf-16le")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data := encodeText(t, tt.enc, text)
+			db := openTestDB(t, NewBuilder().
+				AddReaderWithOptions(strings.NewReader(string(data)), "unicode_text", FileTypeTSV, tt.options...))
+			assert.Equal(t, []string{"450"}, queryRows(t, db, query))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package filesql
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by NewBuilderFromEnv
+const (
+	// EnvChunkSize sets the number of rows per chunk (see SetDefaultChunkSize)
+	EnvChunkSize = "FILESQL_CHUNK_SIZE"
+	// EnvAutoSaveDir enables auto-save on close into the given directory (see EnableAutoSave)
+	EnvAutoSaveDir = "FILESQL_AUTOSAVE_DIR"
+	// EnvMaxMemory sets the memory limit in MB (see SetMemoryLimit)
+	EnvMaxMemory = "FILESQL_MAX_MEMORY"
+)
+
+// NewBuilderFromEnv creates a builder whose defaults are read from environment variables.
+//
+// It is opt-in: NewBuilder never reads the environment. This lets container
+// deployments tune filesql without code changes:
+//
+//	FILESQL_CHUNK_SIZE=5000       # rows per chunk
+//	FILESQL_AUTOSAVE_DIR=/backup  # auto-save on close into /backup
+//	FILESQL_MAX_MEMORY=1024       # fail the load above 1024 MB of heap
+//
+// Unset or empty variables keep the builder defaults. Inputs and further options
+// are added to the returned builder as usual:
+//
+//	builder, err := filesql.NewBuilderFromEnv()
+//	if err != nil {
+//		return err
+//	}
+//	validatedBuilder, err := builder.AddPath("data.csv").Build(ctx)
+//
+// Returns an error if a variable cannot be parsed.
+func NewBuilderFromEnv() (*DBBuilder, error) {
+	cfg, err := builderConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewBuilderFromConfig(cfg)
+}
+
+// builderConfigFromEnv reads the environment variables into a BuilderConfig
+func builderConfigFromEnv() (BuilderConfig, error) {
+	var cfg BuilderConfig
+
+	if value := strings.TrimSpace(os.Getenv(EnvChunkSize)); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return cfg, newCodedError(ErrCodeInvalidConfig, "%s must be a positive integer: %q", EnvChunkSize, value)
+		}
+		cfg.ChunkSize = size
+	}
+
+	if dir := strings.TrimSpace(os.Getenv(EnvAutoSaveDir)); dir != "" {
+		cfg.AutoSave = &AutoSaveSettings{OutputDir: dir}
+	}
+
+	if value := strings.TrimSpace(os.Getenv(EnvMaxMemory)); value != "" {
+		mb, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || mb <= 0 {
+			return cfg, newCodedError(ErrCodeInvalidConfig, "%s must be a positive number of megabytes: %q", EnvMaxMemory, value)
+		}
+		cfg.MaxMemoryMB = mb
+	}
+
+	return cfg, nil
+}
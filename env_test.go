@@ -0,0 +1,77 @@
+package filesql
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBuilderFromEnv(t *testing.T) {
+	t.Run("reads defaults from the environment", func(t *testing.T) {
+		outputDir := t.TempDir()
+		t.Setenv(EnvChunkSize, "250")
+		t.Setenv(EnvAutoSaveDir, outputDir)
+		t.Setenv(EnvMaxMemory, "2048")
+
+		builder, err := NewBuilderFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, 250, builder.defaultChunkSize)
+		require.NotNil(t, builder.autoSaveConfig)
+		assert.Equal(t, outputDir, builder.autoSaveConfig.outputDir)
+		assert.Equal(t, autoSaveOnClose, builder.autoSaveConfig.timing)
+		require.NotNil(t, builder.streamProcessor.memoryLimit)
+		assert.Equal(t, int64(2048), builder.streamProcessor.memoryLimit.maxMemoryMB)
+	})
+
+	t.Run("unset variables keep the defaults", func(t *testing.T) {
+		t.Setenv(EnvChunkSize, "")
+		t.Setenv(EnvAutoSaveDir, "")
+		t.Setenv(EnvMaxMemory, "")
+
+		builder, err := NewBuilderFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultChunkSize, builder.defaultChunkSize)
+		assert.Nil(t, builder.autoSaveConfig)
+		assert.Nil(t, builder.streamProcessor.memoryLimit)
+	})
+
+	invalidTests := []struct {
+		name      string
+		chunkSize string
+		maxMemory string
+	}{
+		{name: "invalid chunk size is rejected", chunkSize: "many"},
+		{name: "invalid memory limit is rejected", maxMemory: "1GB"},
+	}
+
+	for _, tt := range invalidTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvAutoSaveDir, "")
+			t.Setenv(EnvChunkSize, tt.chunkSize)
+			t.Setenv(EnvMaxMemory, tt.maxMemory)
+
+			_, err := NewBuilderFromEnv()
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+		})
+	}
+}
+
+func TestDBBuilder_SetMemoryLimit(t *testing.T) {
+	t.Parallel()
+
+	openTestDB(t, NewBuilder().
+		AddReader(strings.NewReader("id\n1\n"), "small", FileTypeCSV).
+		AddPath(filepath.Join("testdata", "sample.csv")).
+		SetMemoryLimit(64*1024))
+
+	// A limit below any heap size fails the load
+	sp := newStreamProcessor(DefaultChunkSize)
+	sp.memoryLimit = NewMemoryLimit(1)
+	sp.memoryLimit.maxMemoryMB = 0
+	_, err := sp.prepareChunk(&tableChunk{tableName: "t", headers: header{"id"}}, 1)
+	require.ErrorIs(t, err, ErrMemoryLimit)
+	assert.Equal(t, ErrCodeMemoryLimit, ErrorCodeOf(err))
+}
@@ -0,0 +1,187 @@
+package filesql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ErrorCode is a stable, machine-readable identifier for a class of filesql errors.
+//
+// Error codes never change between releases, so programs can branch on them
+// (or show localized messages with LocalizeError) without parsing error strings.
+type ErrorCode string
+
+const (
+	// ErrCodeUnknown is returned by ErrorCodeOf for errors that filesql does not classify
+	ErrCodeUnknown ErrorCode = "FILESQL_E_UNKNOWN"
+	// ErrCodePathNotFound indicates that an input file or directory does not exist
+	ErrCodePathNotFound ErrorCode = "FILESQL_E_PATH_NOT_FOUND"
+	// ErrCodePermissionDenied indicates that a file could not be accessed
+	ErrCodePermissionDenied ErrorCode = "FILESQL_E_PERMISSION_DENIED"
+	// ErrCodeUnsupportedFormat indicates an unsupported file format or extension
+	ErrCodeUnsupportedFormat ErrorCode = "FILESQL_E_UNSUPPORTED_FORMAT"
+	// ErrCodeEmptyData indicates that an input contains no data
+	ErrCodeEmptyData ErrorCode = "FILESQL_E_EMPTY_DATA"
+	// ErrCodeInvalidData indicates malformed input data
+	ErrCodeInvalidData ErrorCode = "FILESQL_E_INVALID_DATA"
+	// ErrCodeDuplicateColumn indicates that an input has duplicate column names
+	ErrCodeDuplicateColumn ErrorCode = "FILESQL_E_DUP_COLUMN"
+	// ErrCodeNoTables indicates that a database has no tables
+	ErrCodeNoTables ErrorCode = "FILESQL_E_NO_TABLES"
+	// ErrCodeMemoryLimit indicates that the memory limit was exceeded
+	ErrCodeMemoryLimit ErrorCode = "FILESQL_E_MEMORY_LIMIT"
+	// ErrCodeCancelled indicates that the operation was cancelled or timed out
+	ErrCodeCancelled ErrorCode = "FILESQL_E_CANCELLED"
+	// ErrCodeValidationFailed indicates that data violated validation rules
+	ErrCodeValidationFailed ErrorCode = "FILESQL_E_VALIDATION_FAILED"
+	// ErrCodeTooManyColumns indicates that an input exceeds SQLite's column limit
+	ErrCodeTooManyColumns ErrorCode = "FILESQL_E_TOO_MANY_COLUMNS"
+	// ErrCodeCellTooLarge indicates that a value exceeds SQLite's value size limit
+	ErrCodeCellTooLarge ErrorCode = "FILESQL_E_CELL_TOO_LARGE"
+	// ErrCodeDuplicateTable indicates that two inputs produce the same table name
+	ErrCodeDuplicateTable ErrorCode = "FILESQL_E_DUP_TABLE"
+	// ErrCodeInvalidConfig indicates an invalid builder or option configuration
+	ErrCodeInvalidConfig ErrorCode = "FILESQL_E_INVALID_CONFIG"
+	// ErrCodeLoadFailed indicates that loading inputs into the database failed
+	ErrCodeLoadFailed ErrorCode = "FILESQL_E_LOAD_FAILED"
+	// ErrCodeDumpFailed indicates that exporting the database failed
+	ErrCodeDumpFailed ErrorCode = "FILESQL_E_DUMP_FAILED"
+	// ErrCodeSaveTimeout indicates that auto-save did not finish within its timeout
+	ErrCodeSaveTimeout ErrorCode = "FILESQL_E_SAVE_TIMEOUT"
+	// ErrCodeOutputPathNotAllowed indicates an output path outside of the allowed output root
+	ErrCodeOutputPathNotAllowed ErrorCode = "FILESQL_E_OUTPUT_PATH_NOT_ALLOWED"
+	// ErrCodeSnapshotVersion indicates a snapshot in an unsupported format version
+	ErrCodeSnapshotVersion ErrorCode = "FILESQL_E_SNAPSHOT_VERSION"
+	// ErrCodeOutputLocked indicates that another dump holds the lock of the output directory
+	ErrCodeOutputLocked ErrorCode = "FILESQL_E_OUTPUT_LOCKED"
+	// ErrCodeNotFound indicates that a table or column of the database does not exist
+	ErrCodeNotFound ErrorCode = "FILESQL_E_NOT_FOUND"
+)
+
+// Error is an error with a machine-readable code.
+//
+// Every error returned by Build, Open, StartLoad, LoadJob.Wait, and DumpDatabase
+// is (or wraps) an *Error, so services embedding filesql can map failures to
+// responses without parsing error strings:
+//
+//	var fsErr *filesql.Error
+//	if errors.As(err, &fsErr) {
+//		switch fsErr.Code {
+//		case filesql.ErrCodePathNotFound:
+//			http.Error(w, err.Error(), http.StatusNotFound)
+//		case filesql.ErrCodeDuplicateColumn, filesql.ErrCodeInvalidData:
+//			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+//		default:
+//			http.Error(w, err.Error(), http.StatusInternalServerError)
+//		}
+//	}
+//
+// The message of an *Error is the message of the error it wraps.
+type Error struct {
+	// Code is the stable error code
+	Code ErrorCode
+	// Err is the underlying error
+	Err error
+}
+
+// Error returns the message of the underlying error
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Code.String()
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newCodedError formats an error message and attaches code to it
+func newCodedError(code ErrorCode, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// withErrorCode makes sure err carries an error code. Errors that already carry
+// one are returned unchanged; others are classified by ErrorCodeOf and fall back
+// to the given code.
+func withErrorCode(err error, fallback ErrorCode) error {
+	if err == nil {
+		return nil
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return err
+	}
+	code := ErrorCodeOf(err)
+	if code == ErrCodeUnknown {
+		code = fallback
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// String returns the code as a string
+func (c ErrorCode) String() string {
+	return string(c)
+}
+
+// sentinelErrorCode is a sentinel error and its error code
+type sentinelErrorCode struct {
+	err  error
+	code ErrorCode
+}
+
+// sentinelErrorCodes returns the error codes of sentinel errors, checked in order
+func sentinelErrorCodes() []sentinelErrorCode {
+	return []sentinelErrorCode{
+		{ErrFileNotFound, ErrCodePathNotFound},
+		{fs.ErrNotExist, ErrCodePathNotFound},
+		{ErrPermissionDenied, ErrCodePermissionDenied},
+		{fs.ErrPermission, ErrCodePermissionDenied},
+		{ErrUnsupportedFormat, ErrCodeUnsupportedFormat},
+		{ErrEmptyData, ErrCodeEmptyData},
+		{ErrInvalidData, ErrCodeInvalidData},
+		{errDuplicateColumnName, ErrCodeDuplicateColumn},
+		{ErrNoTables, ErrCodeNoTables},
+		{ErrMemoryLimit, ErrCodeMemoryLimit},
+		{ErrAutoSaveTimeout, ErrCodeSaveTimeout},
+		{ErrAutoSaveNotEnabled, ErrCodeInvalidConfig},
+		{ErrReferenceDataClosed, ErrCodeInvalidConfig},
+		{ErrOutputPathNotAllowed, ErrCodeOutputPathNotAllowed},
+		{ErrSnapshotVersion, ErrCodeSnapshotVersion},
+		{ErrOutputLocked, ErrCodeOutputLocked},
+		{ErrContextCancelled, ErrCodeCancelled},
+		{context.Canceled, ErrCodeCancelled},
+		{context.DeadlineExceeded, ErrCodeCancelled},
+		{ErrValidationFailed, ErrCodeValidationFailed},
+		{ErrTooManyColumns, ErrCodeTooManyColumns},
+		{ErrCellTooLarge, ErrCodeCellTooLarge},
+	}
+}
+
+// ErrorCodeOf returns the error code of err, or ErrCodeUnknown if err is not
+// a classified filesql error. It is a shorthand for errors.As with *Error. It returns an empty code for a nil error.
+//
+// Example:
+//
+//	db, err := filesql.Open("missing.csv")
+//	if filesql.ErrorCodeOf(err) == filesql.ErrCodePathNotFound {
+//		// ...
+//	}
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	for _, sentinel := range sentinelErrorCodes() {
+		if errors.Is(err, sentinel.err) {
+			return sentinel.code
+		}
+	}
+	return ErrCodeUnknown
+}
@@ -0,0 +1,101 @@
+package filesql
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing path is path not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Open(filepath.Join("testdata", "missing.csv"))
+		require.Error(t, err)
+
+		var fsErr *Error
+		require.True(t, errors.As(err, &fsErr))
+		assert.Equal(t, ErrCodePathNotFound, fsErr.Code)
+		assert.Equal(t, "failed to load file: path does not exist: "+filepath.Join("testdata", "missing.csv"), err.Error())
+	})
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "duplicate table names",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+					AddReader(strings.NewReader("id\n2\n"), "users", FileTypeCSV)
+			},
+			wantErr: ErrCodeDuplicateTable,
+		},
+		{
+			name: "duplicate column names",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().AddReader(strings.NewReader("id,id\n1,2\n"), "dup", FileTypeCSV)
+			},
+			wantErr: ErrCodeDuplicateColumn,
+		},
+		{
+			name:    "no inputs",
+			builder: func(*testing.T) *DBBuilder { return NewBuilder() },
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name:    "nil reader",
+			builder: func(*testing.T) *DBBuilder { return NewBuilder().AddReader(nil, "t", FileTypeCSV) },
+			wantErr: ErrCodeInvalidConfig,
+		},
+	})
+
+	codeTests := []struct {
+		name     string
+		err      error
+		fallback ErrorCode
+		want     ErrorCode
+	}{
+		{name: "sentinel errors keep their code", err: ErrEmptyData, fallback: ErrCodeLoadFailed, want: ErrCodeEmptyData},
+		{name: "unclassified errors get the fallback code", err: errors.New("boom"), fallback: ErrCodeDumpFailed, want: ErrCodeDumpFailed},
+	}
+	for _, tt := range codeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := withErrorCode(tt.err, tt.fallback)
+			require.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, ErrorCodeOf(err))
+			assert.Equal(t, tt.err.Error(), err.Error())
+		})
+	}
+
+	t.Run("nil errors stay nil", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, withErrorCode(nil, ErrCodeDumpFailed))
+	})
+
+	t.Run("existing codes are kept", func(t *testing.T) {
+		t.Parallel()
+
+		inner := newCodedError(ErrCodePathNotFound, "missing")
+		assert.Same(t, inner, withErrorCode(inner, ErrCodeLoadFailed))
+	})
+
+	t.Run("dump of empty database", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+		_, err := db.ExecContext(context.Background(), "DROP TABLE sample")
+		require.NoError(t, err)
+
+		err = DumpDatabase(db, t.TempDir())
+		assert.Equal(t, ErrCodeNoTables, ErrorCodeOf(err))
+	})
+}
@@ -0,0 +1,114 @@
+package filesql
+
+import (
+	"os"
+	"strings"
+)
+
+// Locale selects the language of messages returned by LocalizeError.
+type Locale string
+
+const (
+	// LocaleEnglish selects English messages
+	LocaleEnglish Locale = "en"
+	// LocaleJapanese selects Japanese messages
+	LocaleJapanese Locale = "ja"
+)
+
+// errorMessageCatalog returns the localized message for each error code
+func errorMessageCatalog() map[Locale]map[ErrorCode]string {
+	return map[Locale]map[ErrorCode]string{
+		LocaleEnglish: {
+			ErrCodeUnknown:              "an unexpected error occurred",
+			ErrCodePathNotFound:         "the file or directory does not exist",
+			ErrCodePermissionDenied:     "permission denied",
+			ErrCodeUnsupportedFormat:    "the file format is not supported",
+			ErrCodeEmptyData:            "the input contains no data",
+			ErrCodeInvalidData:          "the input data is malformed",
+			ErrCodeDuplicateColumn:      "the input has duplicate column names",
+			ErrCodeNoTables:             "the database has no tables",
+			ErrCodeMemoryLimit:          "the memory limit was exceeded",
+			ErrCodeCancelled:            "the operation was cancelled",
+			ErrCodeValidationFailed:     "the data violates validation rules",
+			ErrCodeTooManyColumns:       "the input has more columns than SQLite supports",
+			ErrCodeCellTooLarge:         "a value is larger than SQLite supports",
+			ErrCodeDuplicateTable:       "two inputs produce the same table name",
+			ErrCodeInvalidConfig:        "the configuration is invalid",
+			ErrCodeLoadFailed:           "failed to load the input into the database",
+			ErrCodeDumpFailed:           "failed to export the database",
+			ErrCodeSaveTimeout:          "auto-save did not finish in time; some tables were not saved",
+			ErrCodeOutputPathNotAllowed: "the output path is outside of the allowed directory",
+			ErrCodeSnapshotVersion:      "the snapshot was written by an incompatible version of filesql",
+			ErrCodeOutputLocked:         "another process is writing to the output directory",
+			ErrCodeNotFound:             "the table or column does not exist",
+		},
+		LocaleJapanese: {
+			ErrCodeUnknown:              "予期しないエラーが発生しました",
+			ErrCodePathNotFound:         "ファイルまたはディレクトリが存在しません",
+			ErrCodePermissionDenied:     "アクセス権限がありません",
+			ErrCodeUnsupportedFormat:    "サポートされていないファイル形式です",
+			ErrCodeEmptyData:            "入力データが空です",
+			ErrCodeInvalidData:          "入力データの形式が不正です",
+			ErrCodeDuplicateColumn:      "列名が重複しています",
+			ErrCodeNoTables:             "データベースにテーブルがありません",
+			ErrCodeMemoryLimit:          "メモリ上限を超えました",
+			ErrCodeCancelled:            "処理がキャンセルされました",
+			ErrCodeValidationFailed:     "データが検証ルールに違反しています",
+			ErrCodeTooManyColumns:       "列数がSQLiteの上限を超えています",
+			ErrCodeCellTooLarge:         "値のサイズがSQLiteの上限を超えています",
+			ErrCodeDuplicateTable:       "テーブル名が重複しています",
+			ErrCodeInvalidConfig:        "設定が不正です",
+			ErrCodeLoadFailed:           "データベースへの読み込みに失敗しました",
+			ErrCodeDumpFailed:           "データベースの出力に失敗しました",
+			ErrCodeSaveTimeout:          "自動保存が時間内に完了せず、一部のテーブルが保存されていません",
+			ErrCodeOutputPathNotAllowed: "出力先が許可されたディレクトリの外にあります",
+			ErrCodeSnapshotVersion:      "スナップショットが互換性のないバージョンの filesql で作成されています",
+			ErrCodeOutputLocked:         "別のプロセスが出力先ディレクトリに書き込み中です",
+			ErrCodeNotFound:             "テーブルまたは列が存在しません",
+		},
+	}
+}
+
+// LocalizeError returns a user-facing message for err in the given locale.
+//
+// The message is chosen by the error code of err (see ErrorCodeOf), followed by
+// the original error text for details. Unknown locales fall back to English.
+// It returns an empty string for a nil error.
+//
+// Example:
+//
+//	db, err := filesql.Open("missing.csv")
+//	if err != nil {
+//		fmt.Fprintln(os.Stderr, filesql.LocalizeError(err, filesql.DetectLocale()))
+//		// ファイルまたはディレクトリが存在しません (FILESQL_E_PATH_NOT_FOUND): ...
+//	}
+func LocalizeError(err error, locale Locale) string {
+	if err == nil {
+		return ""
+	}
+
+	catalog := errorMessageCatalog()
+	messages, ok := catalog[locale]
+	if !ok {
+		messages = catalog[LocaleEnglish]
+	}
+
+	code := ErrorCodeOf(err)
+	return messages[code] + " (" + code.String() + "): " + err.Error()
+}
+
+// DetectLocale returns the locale configured in the environment through the
+// LC_ALL, LC_MESSAGES, or LANG variables, falling back to English.
+func DetectLocale() Locale {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(value), "ja") {
+			return LocaleJapanese
+		}
+		return LocaleEnglish
+	}
+	return LocaleEnglish
+}
@@ -0,0 +1,87 @@
+package filesql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{name: "nil error has no code", err: nil, want: ""},
+		{name: "wrapped sentinel", err: fmt.Errorf("load: %w", ErrEmptyData), want: ErrCodeEmptyData},
+		{name: "duplicate column", err: fmt.Errorf("%w: id", errDuplicateColumnName), want: ErrCodeDuplicateColumn},
+		{name: "context deadline", err: context.DeadlineExceeded, want: ErrCodeCancelled},
+		{name: "unclassified error", err: errors.New("boom"), want: ErrCodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, ErrorCodeOf(tt.err))
+		})
+	}
+}
+
+func TestLocalizeError(t *testing.T) {
+	t.Parallel()
+
+	err := fmt.Errorf("failed to stream file a.csv: %w", ErrEmptyData)
+
+	t.Run("english message with code and details", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t,
+			"the input contains no data (FILESQL_E_EMPTY_DATA): failed to stream file a.csv: filesql: empty data source",
+			LocalizeError(err, LocaleEnglish))
+	})
+
+	t.Run("japanese message", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t,
+			"入力データが空です (FILESQL_E_EMPTY_DATA): failed to stream file a.csv: filesql: empty data source",
+			LocalizeError(err, LocaleJapanese))
+	})
+
+	t.Run("unknown locale falls back to english", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, LocalizeError(err, LocaleEnglish), LocalizeError(err, Locale("xx")))
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		t.Parallel()
+		assert.Empty(t, LocalizeError(nil, LocaleEnglish))
+	})
+
+	t.Run("every code has a message in every locale", func(t *testing.T) {
+		t.Parallel()
+		catalog := errorMessageCatalog()
+		for locale, messages := range catalog {
+			for code := range catalog[LocaleEnglish] {
+				assert.NotEmpty(t, messages[code], "%s/%s", locale, code)
+			}
+		}
+	})
+}
+
+func TestDetectLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+
+	t.Setenv("LANG", "ja_JP.UTF-8")
+	assert.Equal(t, LocaleJapanese, DetectLocale())
+
+	t.Setenv("LANG", "en_US.UTF-8")
+	assert.Equal(t, LocaleEnglish, DetectLocale())
+
+	t.Setenv("LANG", "")
+	assert.Equal(t, LocaleEnglish, DetectLocale())
+}
@@ -11,6 +11,9 @@ var (
 	// errDuplicateColumnName is returned when a file contains duplicate column names
 	errDuplicateColumnName = errors.New("duplicate column name")
 
+	// errXLSTruncated is returned when XLS record data ends in the middle of a value
+	errXLSTruncated = errors.New("truncated XLS string")
+
 	// ErrEmptyData indicates that the data source contains no records
 	ErrEmptyData = errors.New("filesql: empty data source")
 
@@ -32,8 +35,35 @@ var (
 	// ErrMemoryLimit indicates memory limit exceeded
 	ErrMemoryLimit = errors.New("filesql: memory limit exceeded")
 
+	// ErrOutputPathNotAllowed indicates an output path outside of the allowed output root
+	ErrOutputPathNotAllowed = errors.New("filesql: output path not allowed")
+
+	// ErrAutoSaveTimeout indicates that auto-save did not finish within the configured timeout
+	ErrAutoSaveTimeout = errors.New("filesql: auto-save timed out")
+
+	// ErrAutoSaveNotEnabled indicates that Save was called on a database without auto-save
+	ErrAutoSaveNotEnabled = errors.New("filesql: auto-save is not enabled")
+
+	// ErrReferenceDataClosed indicates that a database was opened with closed reference data
+	ErrReferenceDataClosed = errors.New("filesql: reference data is closed")
+
+	// ErrOutputLocked indicates that another dump holds the lock of the output directory
+	ErrOutputLocked = errors.New("filesql: output directory is locked")
+
+	// ErrSnapshotVersion indicates a snapshot written in a format version this filesql cannot read
+	ErrSnapshotVersion = errors.New("filesql: unsupported snapshot version")
+
 	// ErrContextCancelled indicates context was cancelled
 	ErrContextCancelled = errors.New("filesql: context cancelled")
+
+	// ErrTooManyColumns indicates that a file has more columns than SQLite supports
+	ErrTooManyColumns = errors.New("filesql: too many columns")
+
+	// ErrCellTooLarge indicates that a single value exceeds SQLite's maximum value size
+	ErrCellTooLarge = errors.New("filesql: value too large")
+
+	// ErrValidationFailed indicates that loaded data violated validation rules in strict mode
+	ErrValidationFailed = errors.New("filesql: data validation failed")
 )
 
 // ErrorContext provides context for where an error occurred
@@ -0,0 +1,85 @@
+package filesql
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// WithExcludedTables keeps tables whose names match any of the patterns out of the export.
+//
+// Patterns use the syntax of SQL LIKE: "%" matches any sequence of characters, "_"
+// matches a single character, and ASCII letters are compared case-insensitively. Use
+// it to stop scratch tables created during analysis from leaking into DumpDatabase
+// output and auto-saved files. Calling it again adds more patterns.
+//
+// Tables created with CREATE TEMP TABLE live in SQLite's temp schema and are never
+// exported, with or without this option.
+//
+// Example:
+//
+//	// Export everything except tables named tmp_... and work_...
+//	options := filesql.NewDumpOptions().WithExcludedTables("tmp_%", "work\\_%")
+//	builder.EnableAutoSave("./output", options)
+func (o DumpOptions) WithExcludedTables(patterns ...string) DumpOptions {
+	// Copy the slice so that options derived from the same base do not share it
+	excluded := make([]string, 0, len(o.ExcludeTables)+len(patterns))
+	excluded = append(excluded, o.ExcludeTables...)
+	excluded = append(excluded, patterns...)
+	o.ExcludeTables = excluded
+	return o
+}
+
+// excludesTable reports whether the table matches one of the excluded table patterns
+func (o DumpOptions) excludesTable(tableName string) bool {
+	for _, pattern := range o.ExcludeTables {
+		if matchLikePattern(pattern, tableName) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLikePattern reports whether s matches the SQL LIKE pattern.
+// A backslash escapes the next character of the pattern, so "tmp\_%" matches "tmp_a" but not "tmpa".
+func matchLikePattern(pattern, s string) bool {
+	for pattern != "" {
+		p, size := utf8.DecodeRuneInString(pattern)
+		pattern = pattern[size:]
+
+		switch p {
+		case '%':
+			// Try every remaining suffix of s
+			for i := 0; i <= len(s); {
+				if matchLikePattern(pattern, s[i:]) {
+					return true
+				}
+				if i == len(s) {
+					break
+				}
+				_, n := utf8.DecodeRuneInString(s[i:])
+				i += n
+			}
+			return false
+		case '_':
+			if s == "" {
+				return false
+			}
+			_, n := utf8.DecodeRuneInString(s)
+			s = s[n:]
+		default:
+			if p == '\\' && pattern != "" {
+				p, size = utf8.DecodeRuneInString(pattern)
+				pattern = pattern[size:]
+			}
+			if s == "" {
+				return false
+			}
+			r, n := utf8.DecodeRuneInString(s)
+			if r != p && !(r < utf8.RuneSelf && p < utf8.RuneSelf && strings.EqualFold(string(r), string(p))) {
+				return false
+			}
+			s = s[n:]
+		}
+	}
+	return s == ""
+}
@@ -0,0 +1,103 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchLikePattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{pattern: "tmp_%", s: "tmp_orders", want: true},
+		{pattern: "tmp_%", s: "TMP_orders", want: true},
+		{pattern: "tmp_%", s: "tmpx", want: true},
+		{pattern: "tmp_%", s: "tmp", want: false},
+		{pattern: `tmp\_%`, s: "tmp_orders", want: true},
+		{pattern: `tmp\_%`, s: "tmpxorders", want: false},
+		{pattern: "%_scratch", s: "orders_scratch", want: true},
+		{pattern: "%_scratch", s: "orders_scratch_v2", want: false},
+		{pattern: "%", s: "", want: true},
+		{pattern: "a%b%c", s: "aXXbYYc", want: true},
+		{pattern: "a%b%c", s: "aXXbYY", want: false},
+		{pattern: "売上_", s: "売上1", want: true},
+		{pattern: "users", s: "users", want: true},
+		{pattern: "users", s: "users2", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.s, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, matchLikePattern(tt.pattern, tt.s))
+		})
+	}
+}
+
+func TestDumpOptions_WithExcludedTables(t *testing.T) {
+	t.Parallel()
+
+	t.Run("matching tables are not exported", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV))
+
+		for _, query := range []string{
+			"CREATE TABLE tmp_counts AS SELECT COUNT(*) AS n FROM users",
+			"CREATE TABLE Work_Join AS SELECT * FROM users",
+			"CREATE TEMP TABLE scratch AS SELECT * FROM users",
+		} {
+			_, err := db.ExecContext(ctx, query)
+			require.NoError(t, err, query)
+		}
+
+		outputDir := t.TempDir()
+		options := NewDumpOptions().WithExcludedTables("tmp_%").WithExcludedTables("work_%")
+		require.NoError(t, DumpDatabase(db, outputDir, options))
+
+		entries, err := os.ReadDir(outputDir)
+		require.NoError(t, err)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.Equal(t, []string{"users.csv"}, names)
+	})
+
+	t.Run("excluding every table writes nothing", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outputDir := t.TempDir()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "tmp_users", FileTypeCSV).
+			EnableAutoSave(outputDir, NewDumpOptions().WithExcludedTables("tmp_%")).
+			Build(ctx)
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		assert.NoFileExists(t, filepath.Join(outputDir, "tmp_users.csv"))
+	})
+
+	t.Run("derived options do not share patterns", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewDumpOptions().WithExcludedTables("tmp_%")
+		derived := base.WithExcludedTables("work_%")
+		assert.Equal(t, []string{"tmp_%"}, base.ExcludeTables)
+		assert.Equal(t, []string{"tmp_%", "work_%"}, derived.ExcludeTables)
+	})
+}
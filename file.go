@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding"
 )
 
 // FileType represents supported file types including compression variants
@@ -65,6 +66,115 @@ const (
 	FileTypeXLSXXZ
 	// FileTypeXLSXZSTD represents zstd-compressed Excel XLSX file type
 	FileTypeXLSXZSTD
+	// FileTypeXLS represents legacy Excel 97-2003 XLS file type (read-only)
+	FileTypeXLS
+	// FileTypeXLSGZ represents gzip-compressed legacy Excel XLS file type
+	FileTypeXLSGZ
+	// FileTypeXLSBZ2 represents bzip2-compressed legacy Excel XLS file type
+	FileTypeXLSBZ2
+	// FileTypeXLSXZ represents xz-compressed legacy Excel XLS file type
+	FileTypeXLSXZ
+	// FileTypeXLSZSTD represents zstd-compressed legacy Excel XLS file type
+	FileTypeXLSZSTD
+	// FileTypeZIP represents a zip archive of data files, such as the CSV bundles
+	// exported by Apple Numbers and Google Sheets
+	FileTypeZIP
+	// FileTypeCSVLZ4 represents lz4-compressed CSV file type
+	FileTypeCSVLZ4
+	// FileTypeTSVLZ4 represents lz4-compressed TSV file type
+	FileTypeTSVLZ4
+	// FileTypeLTSVLZ4 represents lz4-compressed LTSV file type
+	FileTypeLTSVLZ4
+	// FileTypeParquetLZ4 represents lz4-compressed Parquet file type
+	FileTypeParquetLZ4
+	// FileTypeXLSXLZ4 represents lz4-compressed Excel XLSX file type
+	FileTypeXLSXLZ4
+	// FileTypeXLSLZ4 represents lz4-compressed legacy Excel XLS file type
+	FileTypeXLSLZ4
+	// FileTypeCSVBR represents brotli-compressed CSV file type
+	FileTypeCSVBR
+	// FileTypeTSVBR represents brotli-compressed TSV file type
+	FileTypeTSVBR
+	// FileTypeLTSVBR represents brotli-compressed LTSV file type
+	FileTypeLTSVBR
+	// FileTypeParquetBR represents brotli-compressed Parquet file type
+	FileTypeParquetBR
+	// FileTypeXLSXBR represents brotli-compressed Excel XLSX file type
+	FileTypeXLSXBR
+	// FileTypeXLSBR represents brotli-compressed legacy Excel XLS file type
+	FileTypeXLSBR
+	// FileTypeJSONL represents JSON Lines (NDJSON) file type
+	FileTypeJSONL
+	// FileTypeJSONLGZ represents gzip-compressed JSON Lines file type
+	FileTypeJSONLGZ
+	// FileTypeJSONLBZ2 represents bzip2-compressed JSON Lines file type
+	FileTypeJSONLBZ2
+	// FileTypeJSONLXZ represents xz-compressed JSON Lines file type
+	FileTypeJSONLXZ
+	// FileTypeJSONLZSTD represents zstd-compressed JSON Lines file type
+	FileTypeJSONLZSTD
+	// FileTypeJSONLLZ4 represents lz4-compressed JSON Lines file type
+	FileTypeJSONLLZ4
+	// FileTypeJSONLBR represents brotli-compressed JSON Lines file type
+	FileTypeJSONLBR
+	// FileTypeDelimited represents delimited text whose delimiter is set with WithDelimiter (comma by default)
+	FileTypeDelimited
+	// FileTypeSSV represents semicolon-separated values (SSV) file type
+	FileTypeSSV
+	// FileTypeSSVGZ represents gzip-compressed SSV file type
+	FileTypeSSVGZ
+	// FileTypeSSVBZ2 represents bzip2-compressed SSV file type
+	FileTypeSSVBZ2
+	// FileTypeSSVXZ represents xz-compressed SSV file type
+	FileTypeSSVXZ
+	// FileTypeSSVZSTD represents zstd-compressed SSV file type
+	FileTypeSSVZSTD
+	// FileTypeSSVLZ4 represents lz4-compressed SSV file type
+	FileTypeSSVLZ4
+	// FileTypeSSVBR represents brotli-compressed SSV file type
+	FileTypeSSVBR
+	// FileTypePSV represents pipe-separated values (PSV) file type
+	FileTypePSV
+	// FileTypePSVGZ represents gzip-compressed PSV file type
+	FileTypePSVGZ
+	// FileTypePSVBZ2 represents bzip2-compressed PSV file type
+	FileTypePSVBZ2
+	// FileTypePSVXZ represents xz-compressed PSV file type
+	FileTypePSVXZ
+	// FileTypePSVZSTD represents zstd-compressed PSV file type
+	FileTypePSVZSTD
+	// FileTypePSVLZ4 represents lz4-compressed PSV file type
+	FileTypePSVLZ4
+	// FileTypePSVBR represents brotli-compressed PSV file type
+	FileTypePSVBR
+	// FileTypeODS represents OpenDocument Spreadsheet (ODS) file type (read-only)
+	FileTypeODS
+	// FileTypeODSGZ represents gzip-compressed ODS file type
+	FileTypeODSGZ
+	// FileTypeODSBZ2 represents bzip2-compressed ODS file type
+	FileTypeODSBZ2
+	// FileTypeODSXZ represents xz-compressed ODS file type
+	FileTypeODSXZ
+	// FileTypeODSZSTD represents zstd-compressed ODS file type
+	FileTypeODSZSTD
+	// FileTypeODSLZ4 represents lz4-compressed ODS file type
+	FileTypeODSLZ4
+	// FileTypeODSBR represents brotli-compressed ODS file type
+	FileTypeODSBR
+	// FileTypeTAR represents a tar archive of data files
+	FileTypeTAR
+	// FileTypeTARGZ represents gzip-compressed tar archive file type (.tar.gz or .tgz)
+	FileTypeTARGZ
+	// FileTypeTARBZ2 represents bzip2-compressed tar archive file type
+	FileTypeTARBZ2
+	// FileTypeTARXZ represents xz-compressed tar archive file type
+	FileTypeTARXZ
+	// FileTypeTARZSTD represents zstd-compressed tar archive file type
+	FileTypeTARZSTD
+	// FileTypeTARLZ4 represents lz4-compressed tar archive file type
+	FileTypeTARLZ4
+	// FileTypeTARBR represents brotli-compressed tar archive file type
+	FileTypeTARBR
 	// FileTypeUnsupported represents unsupported file type
 	FileTypeUnsupported
 )
@@ -75,12 +185,30 @@ const (
 	extCSV = ".csv"
 	// extTSV is the TSV file extension
 	extTSV = ".tsv"
+	// extSSV is the semicolon-separated values file extension
+	extSSV = ".ssv"
+	// extPSV is the pipe-separated values file extension
+	extPSV = ".psv"
 	// extLTSV is the LTSV file extension
 	extLTSV = ".ltsv"
 	// extParquet is the Parquet file extension
 	extParquet = ".parquet"
 	// extXLSX is the Excel XLSX file extension
 	extXLSX = ".xlsx"
+	// extXLS is the legacy Excel XLS file extension
+	extXLS = ".xls"
+	// extODS is the OpenDocument Spreadsheet file extension
+	extODS = ".ods"
+	// extJSONL is the JSON Lines file extension
+	extJSONL = ".jsonl"
+	// extNDJSON is the alternative JSON Lines file extension
+	extNDJSON = ".ndjson"
+	// extZIP is the zip archive extension
+	extZIP = ".zip"
+	// extTAR is the tar archive extension
+	extTAR = ".tar"
+	// extTGZ is the extension of gzip-compressed tar archives, short for ".tar.gz"
+	extTGZ = ".tgz"
 	// extGZ is the gzip compression extension
 	extGZ = ".gz"
 	// extBZ2 is the bzip2 compression extension
@@ -89,6 +217,10 @@ const (
 	extXZ = ".xz"
 	// extZSTD is the zstd compression extension
 	extZSTD = ".zst"
+	// extLZ4 is the lz4 compression extension
+	extLZ4 = ".lz4"
+	// extBR is the brotli compression extension
+	extBR = ".br"
 )
 
 // file represents a file that can be converted to table
@@ -130,11 +262,38 @@ type chunkProcessor func(chunk *tableChunk) error
 
 // streamingParser represents a parser that can read from io.Reader directly
 type streamingParser struct {
-	fileType    FileType
-	tableName   string
-	chunkSize   ChunkSize
-	memoryPool  *MemoryPool  // Pool for reusable memory allocations
-	memoryLimit *MemoryLimit // Configurable memory limits
+	fileType     FileType
+	tableName    string
+	chunkSize    ChunkSize
+	memoryPool   *MemoryPool  // Pool for reusable memory allocations
+	memoryLimit  *MemoryLimit // Configurable memory limits
+	tempDir      string       // Directory for temporary files; empty buffers in memory
+	keepInMemory bool         // Never unzip Excel worksheets to the OS temporary directory
+	// detectDelimiter detects the delimiter of CSV and TSV data from its content
+	detectDelimiter bool
+	// delimiterDetected is called when the detected delimiter differs from the expected one; may be nil
+	delimiterDetected func(detected, expected rune) error
+	// headerDetected decides whether the first row of CSV and TSV data is a header and is
+	// called with the decision; nil always takes the first row as the header
+	headerDetected func(isHeader bool, columns int) error
+	// dropsColumn reports whether a column is dropped before loading; nil keeps every column
+	dropsColumn func(name string) bool
+	// sidecar reads CSV and TSV data with a sidecar index or records one; nil does neither
+	sidecar *sidecarLoad
+	// delimiter overrides the delimiter of CSV and TSV data; 0 uses the delimiter of the file type
+	delimiter rune
+	// encoding transcodes text to UTF-8; nil reads UTF-8 or detects the encoding
+	encoding encoding.Encoding
+	// detectEncoding detects the encoding of text when encoding is nil
+	detectEncoding bool
+	// encodingDetected is called with the name of a detected encoding other than UTF-8; may be nil
+	encodingDetected func(name string) error
+	// noHeader loads the first row of CSV and TSV data as data
+	noHeader bool
+	// columnNames name the columns of CSV and TSV data without a header row; nil generates names
+	columnNames []string
+	// nullConvention tells NULL and empty strings apart in CSV and TSV data
+	nullConvention NullConvention
 }
 
 // newFile creates a new file
@@ -147,8 +306,8 @@ func newFile(path string) *file {
 
 // supportedFileExtPatterns returns all supported file patterns for glob matching
 func supportedFileExtPatterns() []string {
-	baseExts := []string{extCSV, extTSV, extLTSV, extParquet, extXLSX}
-	compressionExts := []string{"", extGZ, extBZ2, extXZ, extZSTD}
+	baseExts := []string{extCSV, extTSV, extSSV, extPSV, extLTSV, extParquet, extXLSX, extXLS, extODS, extJSONL, extNDJSON, extTAR}
+	compressionExts := []string{"", extGZ, extBZ2, extXZ, extZSTD, extLZ4, extBR}
 
 	var patterns []string
 	for _, baseExt := range baseExts {
@@ -157,6 +316,8 @@ func supportedFileExtPatterns() []string {
 			patterns = append(patterns, pattern)
 		}
 	}
+	// Zip archives are compressed by themselves
+	patterns = append(patterns, "*"+extZIP, "*"+extTGZ)
 	return patterns
 }
 
@@ -164,8 +325,13 @@ func supportedFileExtPatterns() []string {
 func isSupportedFile(fileName string) bool {
 	fileName = strings.ToLower(fileName)
 
+	// Zip archives are compressed by themselves and cannot be compressed again
+	if strings.HasSuffix(fileName, extZIP) || strings.HasSuffix(fileName, extTGZ) {
+		return true
+	}
+
 	// Remove compression extensions
-	for _, ext := range []string{extGZ, extBZ2, extXZ, extZSTD} {
+	for _, ext := range []string{extGZ, extBZ2, extXZ, extZSTD, extLZ4, extBR} {
 		if strings.HasSuffix(fileName, ext) {
 			fileName = strings.TrimSuffix(fileName, ext)
 			break
@@ -175,9 +341,16 @@ func isSupportedFile(fileName string) bool {
 	// Check for supported file extensions
 	return strings.HasSuffix(fileName, extCSV) ||
 		strings.HasSuffix(fileName, extTSV) ||
+		strings.HasSuffix(fileName, extSSV) ||
+		strings.HasSuffix(fileName, extPSV) ||
 		strings.HasSuffix(fileName, extLTSV) ||
 		strings.HasSuffix(fileName, extParquet) ||
-		strings.HasSuffix(fileName, extXLSX)
+		strings.HasSuffix(fileName, extXLSX) ||
+		strings.HasSuffix(fileName, extXLS) ||
+		strings.HasSuffix(fileName, extODS) ||
+		strings.HasSuffix(fileName, extJSONL) ||
+		strings.HasSuffix(fileName, extNDJSON) ||
+		strings.HasSuffix(fileName, extTAR)
 }
 
 // isSupportedExtension checks if the given extension is supported
@@ -242,6 +415,112 @@ func (ft FileType) extension() string {
 		return extXLSX + extXZ
 	case FileTypeXLSXZSTD:
 		return extXLSX + extZSTD
+	case FileTypeXLS:
+		return extXLS
+	case FileTypeXLSGZ:
+		return extXLS + extGZ
+	case FileTypeXLSBZ2:
+		return extXLS + extBZ2
+	case FileTypeXLSXZ:
+		return extXLS + extXZ
+	case FileTypeXLSZSTD:
+		return extXLS + extZSTD
+	case FileTypeZIP:
+		return extZIP
+	case FileTypeCSVLZ4:
+		return extCSV + extLZ4
+	case FileTypeTSVLZ4:
+		return extTSV + extLZ4
+	case FileTypeLTSVLZ4:
+		return extLTSV + extLZ4
+	case FileTypeParquetLZ4:
+		return extParquet + extLZ4
+	case FileTypeXLSXLZ4:
+		return extXLSX + extLZ4
+	case FileTypeXLSLZ4:
+		return extXLS + extLZ4
+	case FileTypeCSVBR:
+		return extCSV + extBR
+	case FileTypeTSVBR:
+		return extTSV + extBR
+	case FileTypeLTSVBR:
+		return extLTSV + extBR
+	case FileTypeParquetBR:
+		return extParquet + extBR
+	case FileTypeXLSXBR:
+		return extXLSX + extBR
+	case FileTypeXLSBR:
+		return extXLS + extBR
+	case FileTypeJSONL:
+		return extJSONL
+	case FileTypeJSONLGZ:
+		return extJSONL + extGZ
+	case FileTypeJSONLBZ2:
+		return extJSONL + extBZ2
+	case FileTypeJSONLXZ:
+		return extJSONL + extXZ
+	case FileTypeJSONLZSTD:
+		return extJSONL + extZSTD
+	case FileTypeJSONLLZ4:
+		return extJSONL + extLZ4
+	case FileTypeJSONLBR:
+		return extJSONL + extBR
+	case FileTypeSSV:
+		return extSSV
+	case FileTypeSSVGZ:
+		return extSSV + extGZ
+	case FileTypeSSVBZ2:
+		return extSSV + extBZ2
+	case FileTypeSSVXZ:
+		return extSSV + extXZ
+	case FileTypeSSVZSTD:
+		return extSSV + extZSTD
+	case FileTypeSSVLZ4:
+		return extSSV + extLZ4
+	case FileTypeSSVBR:
+		return extSSV + extBR
+	case FileTypePSV:
+		return extPSV
+	case FileTypePSVGZ:
+		return extPSV + extGZ
+	case FileTypePSVBZ2:
+		return extPSV + extBZ2
+	case FileTypePSVXZ:
+		return extPSV + extXZ
+	case FileTypePSVZSTD:
+		return extPSV + extZSTD
+	case FileTypePSVLZ4:
+		return extPSV + extLZ4
+	case FileTypePSVBR:
+		return extPSV + extBR
+	case FileTypeODS:
+		return extODS
+	case FileTypeODSGZ:
+		return extODS + extGZ
+	case FileTypeODSBZ2:
+		return extODS + extBZ2
+	case FileTypeODSXZ:
+		return extODS + extXZ
+	case FileTypeODSZSTD:
+		return extODS + extZSTD
+	case FileTypeODSLZ4:
+		return extODS + extLZ4
+	case FileTypeODSBR:
+		return extODS + extBR
+	case FileTypeTAR:
+		return extTAR
+	case FileTypeTARGZ:
+		return extTAR + extGZ
+	case FileTypeTARBZ2:
+		return extTAR + extBZ2
+	case FileTypeTARXZ:
+		return extTAR + extXZ
+	case FileTypeTARZSTD:
+		return extTAR + extZSTD
+	case FileTypeTARLZ4:
+		return extTAR + extLZ4
+	case FileTypeTARBR:
+		return extTAR + extBR
 	default:
 		return ""
 	}
@@ -250,16 +529,32 @@ func (ft FileType) extension() string {
 // baseType returns the base file type without compression
 func (ft FileType) baseType() FileType {
 	switch ft {
-	case FileTypeCSV, FileTypeCSVGZ, FileTypeCSVBZ2, FileTypeCSVXZ, FileTypeCSVZSTD:
+	case FileTypeCSV, FileTypeCSVGZ, FileTypeCSVBZ2, FileTypeCSVXZ, FileTypeCSVZSTD, FileTypeCSVLZ4, FileTypeCSVBR:
 		return FileTypeCSV
-	case FileTypeTSV, FileTypeTSVGZ, FileTypeTSVBZ2, FileTypeTSVXZ, FileTypeTSVZSTD:
+	case FileTypeTSV, FileTypeTSVGZ, FileTypeTSVBZ2, FileTypeTSVXZ, FileTypeTSVZSTD, FileTypeTSVLZ4, FileTypeTSVBR:
 		return FileTypeTSV
-	case FileTypeLTSV, FileTypeLTSVGZ, FileTypeLTSVBZ2, FileTypeLTSVXZ, FileTypeLTSVZSTD:
+	case FileTypeLTSV, FileTypeLTSVGZ, FileTypeLTSVBZ2, FileTypeLTSVXZ, FileTypeLTSVZSTD, FileTypeLTSVLZ4, FileTypeLTSVBR:
 		return FileTypeLTSV
-	case FileTypeParquet, FileTypeParquetGZ, FileTypeParquetBZ2, FileTypeParquetXZ, FileTypeParquetZSTD:
+	case FileTypeParquet, FileTypeParquetGZ, FileTypeParquetBZ2, FileTypeParquetXZ, FileTypeParquetZSTD, FileTypeParquetLZ4, FileTypeParquetBR:
 		return FileTypeParquet
-	case FileTypeXLSX, FileTypeXLSXGZ, FileTypeXLSXBZ2, FileTypeXLSXXZ, FileTypeXLSXZSTD:
+	case FileTypeXLSX, FileTypeXLSXGZ, FileTypeXLSXBZ2, FileTypeXLSXXZ, FileTypeXLSXZSTD, FileTypeXLSXLZ4, FileTypeXLSXBR:
 		return FileTypeXLSX
+	case FileTypeXLS, FileTypeXLSGZ, FileTypeXLSBZ2, FileTypeXLSXZ, FileTypeXLSZSTD, FileTypeXLSLZ4, FileTypeXLSBR:
+		return FileTypeXLS
+	case FileTypeJSONL, FileTypeJSONLGZ, FileTypeJSONLBZ2, FileTypeJSONLXZ, FileTypeJSONLZSTD, FileTypeJSONLLZ4, FileTypeJSONLBR:
+		return FileTypeJSONL
+	case FileTypeSSV, FileTypeSSVGZ, FileTypeSSVBZ2, FileTypeSSVXZ, FileTypeSSVZSTD, FileTypeSSVLZ4, FileTypeSSVBR:
+		return FileTypeSSV
+	case FileTypePSV, FileTypePSVGZ, FileTypePSVBZ2, FileTypePSVXZ, FileTypePSVZSTD, FileTypePSVLZ4, FileTypePSVBR:
+		return FileTypePSV
+	case FileTypeODS, FileTypeODSGZ, FileTypeODSBZ2, FileTypeODSXZ, FileTypeODSZSTD, FileTypeODSLZ4, FileTypeODSBR:
+		return FileTypeODS
+	case FileTypeDelimited:
+		return FileTypeDelimited
+	case FileTypeZIP:
+		return FileTypeZIP
+	case FileTypeTAR, FileTypeTARGZ, FileTypeTARBZ2, FileTypeTARXZ, FileTypeTARZSTD, FileTypeTARLZ4, FileTypeTARBR:
+		return FileTypeTAR
 	default:
 		return FileTypeUnsupported
 	}
@@ -309,12 +604,12 @@ func (f *file) isXLSX() bool {
 
 // isCompressed returns true if file is compressed
 func (f *file) isCompressed() bool {
-	return f.isGZ() || f.isBZ2() || f.isXZ() || f.isZSTD()
+	return f.isGZ() || f.isBZ2() || f.isXZ() || f.isZSTD() || f.isLZ4() || f.isBR()
 }
 
 // isGZ returns true if file is gzip compressed
 func (f *file) isGZ() bool {
-	return strings.HasSuffix(f.path, extGZ)
+	return strings.HasSuffix(f.path, extGZ) || strings.HasSuffix(f.path, extTGZ)
 }
 
 // isBZ2 returns true if file is bzip2 compressed
@@ -332,6 +627,16 @@ func (f *file) isZSTD() bool {
 	return strings.HasSuffix(f.path, extZSTD)
 }
 
+// isLZ4 returns true if file is lz4 compressed
+func (f *file) isLZ4() bool {
+	return strings.HasSuffix(f.path, extLZ4)
+}
+
+// isBR returns true if file is brotli compressed
+func (f *file) isBR() bool {
+	return strings.HasSuffix(f.path, extBR)
+}
+
 // toTable converts file to table structure
 func (f *file) toTable() (*table, error) {
 	switch f.getFileType().baseType() {
@@ -339,12 +644,26 @@ func (f *file) toTable() (*table, error) {
 		return f.parseCSV()
 	case FileTypeTSV:
 		return f.parseTSV()
+	case FileTypeSSV:
+		return f.parseDelimitedFile(ssvDelimiter)
+	case FileTypePSV:
+		return f.parseDelimitedFile(psvDelimiter)
 	case FileTypeLTSV:
 		return f.parseLTSV()
 	case FileTypeParquet:
 		return f.parseParquet()
 	case FileTypeXLSX:
 		return f.parseXLSX()
+	case FileTypeXLS:
+		return f.parseXLS()
+	case FileTypeODS:
+		return f.parseODS()
+	case FileTypeJSONL:
+		return f.parseJSONL()
+	case FileTypeZIP:
+		return f.parseZIP()
+	case FileTypeTAR:
+		return f.parseTAR()
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", f.getPath())
 	}
@@ -368,6 +687,12 @@ func detectFileType(path string) FileType {
 	} else if strings.HasSuffix(path, extZSTD) {
 		basePath = strings.TrimSuffix(path, extZSTD)
 		compressionType = compressionZSTDStr
+	} else if strings.HasSuffix(path, extLZ4) {
+		basePath = strings.TrimSuffix(path, extLZ4)
+		compressionType = compressionLZ4Str
+	} else if strings.HasSuffix(path, extBR) {
+		basePath = strings.TrimSuffix(path, extBR)
+		compressionType = compressionBRStr
 	}
 
 	ext := strings.ToLower(filepath.Ext(basePath))
@@ -382,6 +707,10 @@ func detectFileType(path string) FileType {
 			return FileTypeCSVXZ
 		case compressionZSTDStr:
 			return FileTypeCSVZSTD
+		case compressionLZ4Str:
+			return FileTypeCSVLZ4
+		case compressionBRStr:
+			return FileTypeCSVBR
 		default:
 			return FileTypeCSV
 		}
@@ -395,9 +724,47 @@ func detectFileType(path string) FileType {
 			return FileTypeTSVXZ
 		case compressionZSTDStr:
 			return FileTypeTSVZSTD
+		case compressionLZ4Str:
+			return FileTypeTSVLZ4
+		case compressionBRStr:
+			return FileTypeTSVBR
 		default:
 			return FileTypeTSV
 		}
+	case extSSV:
+		switch compressionType {
+		case compressionGZStr:
+			return FileTypeSSVGZ
+		case compressionBZ2Str:
+			return FileTypeSSVBZ2
+		case compressionXZStr:
+			return FileTypeSSVXZ
+		case compressionZSTDStr:
+			return FileTypeSSVZSTD
+		case compressionLZ4Str:
+			return FileTypeSSVLZ4
+		case compressionBRStr:
+			return FileTypeSSVBR
+		default:
+			return FileTypeSSV
+		}
+	case extPSV:
+		switch compressionType {
+		case compressionGZStr:
+			return FileTypePSVGZ
+		case compressionBZ2Str:
+			return FileTypePSVBZ2
+		case compressionXZStr:
+			return FileTypePSVXZ
+		case compressionZSTDStr:
+			return FileTypePSVZSTD
+		case compressionLZ4Str:
+			return FileTypePSVLZ4
+		case compressionBRStr:
+			return FileTypePSVBR
+		default:
+			return FileTypePSV
+		}
 	case extLTSV:
 		switch compressionType {
 		case compressionGZStr:
@@ -408,6 +775,10 @@ func detectFileType(path string) FileType {
 			return FileTypeLTSVXZ
 		case compressionZSTDStr:
 			return FileTypeLTSVZSTD
+		case compressionLZ4Str:
+			return FileTypeLTSVLZ4
+		case compressionBRStr:
+			return FileTypeLTSVBR
 		default:
 			return FileTypeLTSV
 		}
@@ -421,6 +792,10 @@ func detectFileType(path string) FileType {
 			return FileTypeParquetXZ
 		case compressionZSTDStr:
 			return FileTypeParquetZSTD
+		case compressionLZ4Str:
+			return FileTypeParquetLZ4
+		case compressionBRStr:
+			return FileTypeParquetBR
 		default:
 			return FileTypeParquet
 		}
@@ -434,9 +809,91 @@ func detectFileType(path string) FileType {
 			return FileTypeXLSXXZ
 		case compressionZSTDStr:
 			return FileTypeXLSXZSTD
+		case compressionLZ4Str:
+			return FileTypeXLSXLZ4
+		case compressionBRStr:
+			return FileTypeXLSXBR
 		default:
 			return FileTypeXLSX
 		}
+	case extXLS:
+		switch compressionType {
+		case compressionGZStr:
+			return FileTypeXLSGZ
+		case compressionBZ2Str:
+			return FileTypeXLSBZ2
+		case compressionXZStr:
+			return FileTypeXLSXZ
+		case compressionZSTDStr:
+			return FileTypeXLSZSTD
+		case compressionLZ4Str:
+			return FileTypeXLSLZ4
+		case compressionBRStr:
+			return FileTypeXLSBR
+		default:
+			return FileTypeXLS
+		}
+	case extODS:
+		switch compressionType {
+		case compressionGZStr:
+			return FileTypeODSGZ
+		case compressionBZ2Str:
+			return FileTypeODSBZ2
+		case compressionXZStr:
+			return FileTypeODSXZ
+		case compressionZSTDStr:
+			return FileTypeODSZSTD
+		case compressionLZ4Str:
+			return FileTypeODSLZ4
+		case compressionBRStr:
+			return FileTypeODSBR
+		default:
+			return FileTypeODS
+		}
+	case extJSONL, extNDJSON:
+		switch compressionType {
+		case compressionGZStr:
+			return FileTypeJSONLGZ
+		case compressionBZ2Str:
+			return FileTypeJSONLBZ2
+		case compressionXZStr:
+			return FileTypeJSONLXZ
+		case compressionZSTDStr:
+			return FileTypeJSONLZSTD
+		case compressionLZ4Str:
+			return FileTypeJSONLLZ4
+		case compressionBRStr:
+			return FileTypeJSONLBR
+		default:
+			return FileTypeJSONL
+		}
+	case extZIP:
+		if compressionType != "" {
+			return FileTypeUnsupported
+		}
+		return FileTypeZIP
+	case extTAR:
+		switch compressionType {
+		case compressionGZStr:
+			return FileTypeTARGZ
+		case compressionBZ2Str:
+			return FileTypeTARBZ2
+		case compressionXZStr:
+			return FileTypeTARXZ
+		case compressionZSTDStr:
+			return FileTypeTARZSTD
+		case compressionLZ4Str:
+			return FileTypeTARLZ4
+		case compressionBRStr:
+			return FileTypeTARBR
+		default:
+			return FileTypeTAR
+		}
+	case extTGZ:
+		if compressionType != "" {
+			return FileTypeUnsupported
+		}
+		return FileTypeTARGZ
 	default:
 		return FileTypeUnsupported
 	}
@@ -14,6 +14,8 @@ import (
 type fileProcessor struct {
 	chunkSize int
 	validator *validator
+	// filter selects the files found by walking directories (see OpenDir)
+	filter dirFilter
 }
 
 // newFileProcessor creates a new file processor instance
@@ -24,47 +26,149 @@ func newFileProcessor(chunkSize int) *fileProcessor {
 	}
 }
 
-// collectFilesFromPaths validates and collects all files from the given paths
-func (fp *fileProcessor) collectFilesFromPaths(paths []string) ([]string, error) {
-	var collectedPaths []string
-	processedFiles := make(map[string]bool)
+// skippedPath is an input path that was not loaded because the same file was
+// already added through another path
+type skippedPath struct {
+	// path is the skipped path
+	path string
+	// duplicateOf is the path the file is loaded from
+	duplicateOf string
+}
+
+// collectedFiles collects input files and skips files that were already added,
+// also when they are reached through a different path
+type collectedFiles struct {
+	// paths are the collected files in the order they were added
+	paths []string
+	// origins are the indexes of the input paths that the collected files were found through
+	origins []int
+	// byLocation maps the absolute path of each file, with symbolic links resolved,
+	// to the path the file was added with
+	byLocation map[string]string
+	// byTable holds the collected files by table name, to detect hard links and
+	// case-insensitive file systems, where two locations can be the same file
+	byTable map[string][]collectedFile
+	// skipped are the paths that were skipped as duplicates of another path
+	skipped []skippedPath
+}
+
+// collectedFile is a collected path with its file info
+type collectedFile struct {
+	path string
+	info os.FileInfo
+}
+
+// newCollectedFiles returns an empty collection
+func newCollectedFiles() *collectedFiles {
+	return &collectedFiles{
+		byLocation: make(map[string]string),
+		byTable:    make(map[string][]collectedFile),
+	}
+}
+
+// add adds filePath unless the same file was already added. A file added again with
+// the same cleaned path, as happens when a directory and a file in it are both added,
+// is skipped silently; other duplicates are recorded in skipped.
+func (c *collectedFiles) add(filePath string, origin int) error {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for %s: %w", filePath, err)
+	}
+	location := absPath
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		location = resolved
+	}
+	if first, ok := c.byLocation[location]; ok {
+		c.skip(filePath, first)
+		return nil
+	}
 
-	for _, path := range paths {
-		if err := fp.validator.validatePath(path); err != nil {
-			return nil, err
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat path %s: %w", filePath, err)
+	}
+	tableName := tableFromFilePath(filePath)
+	for _, other := range c.byTable[tableName] {
+		if os.SameFile(info, other.info) {
+			c.byLocation[location] = other.path
+			c.skip(filePath, other.path)
+			return nil
 		}
+	}
 
-		info, err := os.Stat(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to stat path %s: %w", path, err)
+	c.byLocation[location] = filePath
+	c.byTable[tableName] = append(c.byTable[tableName], collectedFile{path: filePath, info: info})
+	c.paths = append(c.paths, filePath)
+	c.origins = append(c.origins, origin)
+	return nil
+}
+
+// skip records that filePath is not loaded because it is the same file as first
+func (c *collectedFiles) skip(filePath, first string) {
+	firstAbs, errFirst := filepath.Abs(first)
+	pathAbs, errPath := filepath.Abs(filePath)
+	if errFirst == nil && errPath == nil && firstAbs == pathAbs {
+		return
+	}
+	c.skipped = append(c.skipped, skippedPath{path: filePath, duplicateOf: first})
+}
+
+// collectFilesFromPaths validates and collects all files from the given paths.
+// Files that are reached through more than one path are collected once.
+func (fp *fileProcessor) collectFilesFromPaths(paths []string) (*collectedFiles, error) {
+	collected := newCollectedFiles()
+
+	// Every path is checked, so the error lists all the paths that cannot be collected
+	var errs []error
+	for i, path := range paths {
+		if err := fp.collectFilesFromPath(path, i, collected); err != nil {
+			errs = append(errs, &InputError{Input: path, Err: withErrorCode(err, ErrCodeInvalidConfig)})
 		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
 
-		if info.IsDir() {
-			dirFiles, err := fp.collectFilesFromDirectory(path, processedFiles)
-			if err != nil {
-				return nil, err
-			}
-			collectedPaths = append(collectedPaths, dirFiles...)
-		} else {
-			if err := fp.addSingleFile(path, processedFiles, &collectedPaths); err != nil {
-				return nil, err
-			}
+	return collected, nil
+}
+
+// collectFilesFromPath collects the file at path, or the supported files in the directory
+// at path or that match the glob pattern path
+func (fp *fileProcessor) collectFilesFromPath(path string, origin int, collected *collectedFiles) error {
+	if isGlobPattern(path) {
+		if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+			return fp.collectFilesFromGlob(path, origin, collected)
 		}
 	}
+	if err := fp.validator.validatePath(path); err != nil {
+		return err
+	}
 
-	return collectedPaths, nil
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat path %s: %w", path, err)
+	}
+
+	if info.IsDir() {
+		return fp.collectFilesFromDirectory(path, origin, collected)
+	}
+	return fp.addSingleFile(path, origin, collected)
 }
 
 // collectFilesFromDirectory recursively collects all supported files from a directory
-func (fp *fileProcessor) collectFilesFromDirectory(dirPath string, processedFiles map[string]bool) ([]string, error) {
-	var collectedPaths []string
-
+func (fp *fileProcessor) collectFilesFromDirectory(dirPath string, origin int, collected *collectedFiles) error {
 	err := filepath.WalkDir(dirPath, func(filePath string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if d.IsDir() || !isSupportedFile(filePath) {
+		if d.IsDir() {
+			if filePath != dirPath && fp.filter.excludes(dirPath, filePath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isSupportedFile(filePath) || !fp.filter.includes(dirPath, filePath) {
 			return nil
 		}
 
@@ -73,47 +177,29 @@ func (fp *fileProcessor) collectFilesFromDirectory(dirPath string, processedFile
 			return nil
 		}
 
-		absPath, err := filepath.Abs(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to get absolute path for %s: %w", filePath, err)
-		}
-
-		if !processedFiles[absPath] {
-			processedFiles[absPath] = true
-			collectedPaths = append(collectedPaths, filePath)
-		}
-		return nil
+		return collected.add(filePath, origin)
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory %s: %w", dirPath, err)
+		return fmt.Errorf("failed to walk directory %s: %w", dirPath, err)
 	}
 
-	return collectedPaths, nil
+	return nil
 }
 
 // addSingleFile validates and adds a single file to the collected paths
-func (fp *fileProcessor) addSingleFile(filePath string, processedFiles map[string]bool, collectedPaths *[]string) error {
+func (fp *fileProcessor) addSingleFile(filePath string, origin int, collected *collectedFiles) error {
 	if !isSupportedFile(filePath) {
-		return fmt.Errorf("unsupported file type: %s", filePath)
+		return newCodedError(ErrCodeUnsupportedFormat, "unsupported file type: %s", filePath)
 	}
 
-	absPath, err := filepath.Abs(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute path for %s: %w", filePath, err)
-	}
-
-	if !processedFiles[absPath] {
-		processedFiles[absPath] = true
-		*collectedPaths = append(*collectedPaths, filePath)
-	}
-
-	return nil
+	return collected.add(filePath, origin)
 }
 
-// processFilesystemsToReaders processes embedded filesystems and converts them to readers
-func (fp *fileProcessor) processFilesystemsToReaders(ctx context.Context, filesystems []fs.FS) ([]readerInput, error) {
-	var allReaders []readerInput
+// processFilesystemsToReaders processes embedded filesystems and converts them to readers,
+// one group of readers per filesystem
+func (fp *fileProcessor) processFilesystemsToReaders(ctx context.Context, filesystems []fs.FS) ([][]readerInput, error) {
+	var allReaders [][]readerInput
 
 	for _, filesystem := range filesystems {
 		if filesystem == nil {
@@ -124,7 +210,7 @@ func (fp *fileProcessor) processFilesystemsToReaders(ctx context.Context, filesy
 		if err != nil {
 			return nil, fmt.Errorf("failed to process FS input: %w", err)
 		}
-		allReaders = append(allReaders, fsReaders...)
+		allReaders = append(allReaders, fsReaders)
 	}
 
 	return allReaders, nil
@@ -205,6 +291,10 @@ func (fp *fileProcessor) processFSToReaders(_ context.Context, filesystem fs.FS)
 			reader:    file,
 			tableName: tableName,
 			fileType:  fileType,
+			source:    match,
+		}
+		if writable, ok := filesystem.(WritableFS); ok {
+			readerInput.writeBack = writable
 		}
 
 		readers = append(readers, readerInput)
@@ -213,32 +303,78 @@ func (fp *fileProcessor) processFSToReaders(_ context.Context, filesystem fs.FS)
 	return readers, nil
 }
 
-// deduplicateCompressedFiles removes compressed files when their uncompressed versions exist
+// deduplicateCompressedFiles removes compressed files when their uncompressed versions exist.
+// The remaining files keep their input order so that tables are loaded deterministically.
 func (fp *fileProcessor) deduplicateCompressedFiles(files []string) []string {
+	return fp.deduplicateCompressedFilesBy(files, tableFromFilePath)
+}
+
+// deduplicateCompressedFilesBy is deduplicateCompressedFiles for the table names given by tableName
+func (fp *fileProcessor) deduplicateCompressedFilesBy(files []string, tableName func(string) string) []string {
 	// Create a map of table names to file paths, prioritizing uncompressed files
 	tableToFile := make(map[string]string)
 
 	// First pass: collect all uncompressed files
 	for _, file := range files {
-		tableName := tableFromFilePath(file)
+		name := tableName(file)
 		if !fp.isCompressedFile(file) {
-			tableToFile[tableName] = file
+			tableToFile[name] = file
 		}
 	}
 
 	// Second pass: add compressed files only if uncompressed version doesn't exist
 	for _, file := range files {
-		tableName := tableFromFilePath(file)
+		name := tableName(file)
 		if fp.isCompressedFile(file) {
-			if _, exists := tableToFile[tableName]; !exists {
-				tableToFile[tableName] = file
+			if _, exists := tableToFile[name]; !exists {
+				tableToFile[name] = file
 			}
 		}
 	}
 
-	// Convert map back to slice
+	// Convert map back to slice in input order (map iteration order is random)
 	result := make([]string, 0, len(tableToFile))
-	for _, file := range tableToFile {
+	for _, file := range files {
+		name := tableName(file)
+		if tableToFile[name] == file {
+			result = append(result, file)
+			delete(tableToFile, name)
+		}
+	}
+
+	return result
+}
+
+// deduplicateCompressedCopies removes compressed copies of files: a compressed file is
+// removed when a file of the same directory produces the same table and is
+// uncompressed, or is compressed and comes first. Files of different directories that
+// produce the same table are kept, so the table collision policy applies to them.
+// The remaining files keep their input order so that tables are loaded deterministically.
+func (fp *fileProcessor) deduplicateCompressedCopies(files []string) []string {
+	// copyKey identifies the files that are copies of each other
+	copyKey := func(file string) string {
+		return filepath.Join(filepath.Dir(file), tableFromFilePath(file))
+	}
+
+	// First pass: collect all uncompressed files
+	uncompressed := make(map[string]bool)
+	for _, file := range files {
+		if !fp.isCompressedFile(file) {
+			uncompressed[copyKey(file)] = true
+		}
+	}
+
+	// Second pass: keep compressed files only if no uncompressed or earlier compressed copy exists
+	result := make([]string, 0, len(files))
+	compressed := make(map[string]bool)
+	for _, file := range files {
+		if fp.isCompressedFile(file) {
+			key := copyKey(file)
+			if uncompressed[key] || compressed[key] {
+				continue
+			}
+			compressed[key] = true
+		}
 		result = append(result, file)
 	}
 
@@ -251,5 +387,7 @@ func (fp *fileProcessor) isCompressedFile(filePath string) bool {
 	return strings.HasSuffix(p, extGZ) ||
 		strings.HasSuffix(p, extBZ2) ||
 		strings.HasSuffix(p, extXZ) ||
-		strings.HasSuffix(p, extZSTD)
+		strings.HasSuffix(p, extZSTD) ||
+		strings.HasSuffix(p, extLZ4) ||
+		strings.HasSuffix(p, extBR)
 }
@@ -0,0 +1,109 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileProcessor_collectFilesFromPaths(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "data"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "users.csv"), []byte("id\n1\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "orders.csv"), []byte("id\n1\n"), 0600))
+		return dir
+	}
+
+	t.Run("a file added again through its directory is collected once silently", func(t *testing.T) {
+		t.Parallel()
+
+		dir := setup(t)
+		users := filepath.Join(dir, "data", "users.csv")
+		collected, err := newFileProcessor(DefaultChunkSize).collectFilesFromPaths([]string{
+			users,
+			filepath.Join(dir, "data"),
+			filepath.Join(dir, "data", ".", "users.csv"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{users, filepath.Join(dir, "data", "orders.csv")}, collected.paths)
+		assert.Empty(t, collected.skipped)
+	})
+
+	t.Run("a file reached through a symbolic link is collected once", func(t *testing.T) {
+		t.Parallel()
+
+		dir := setup(t)
+		link := filepath.Join(dir, "link")
+		if err := os.Symlink(filepath.Join(dir, "data"), link); err != nil {
+			t.Skipf("symbolic links are not supported: %v", err)
+		}
+
+		collected, err := newFileProcessor(DefaultChunkSize).collectFilesFromPaths([]string{
+			filepath.Join(dir, "data"),
+			filepath.Join(link, "users.csv"),
+		})
+		require.NoError(t, err)
+		assert.Len(t, collected.paths, 2)
+		assert.Equal(t, []skippedPath{{
+			path:        filepath.Join(link, "users.csv"),
+			duplicateOf: filepath.Join(dir, "data", "users.csv"),
+		}}, collected.skipped)
+	})
+
+	t.Run("a hard link is collected once", func(t *testing.T) {
+		t.Parallel()
+
+		dir := setup(t)
+		other := filepath.Join(dir, "other")
+		require.NoError(t, os.MkdirAll(other, 0750))
+		if err := os.Link(filepath.Join(dir, "data", "users.csv"), filepath.Join(other, "users.csv")); err != nil {
+			t.Skipf("hard links are not supported: %v", err)
+		}
+
+		collected, err := newFileProcessor(DefaultChunkSize).collectFilesFromPaths([]string{
+			filepath.Join(dir, "data", "users.csv"),
+			filepath.Join(other, "users.csv"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(dir, "data", "users.csv")}, collected.paths)
+		require.Len(t, collected.skipped, 1)
+		assert.Equal(t, filepath.Join(other, "users.csv"), collected.skipped[0].path)
+	})
+}
+
+func TestDBBuilder_DuplicatePaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "data"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "users.csv"), []byte("id\n1\n2\n"), 0600))
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(filepath.Join(dir, "data"), link); err != nil {
+		t.Skipf("symbolic links are not supported: %v", err)
+	}
+
+	ctx := context.Background()
+	db := openTestDB(t, NewBuilder().
+		AddPath(filepath.Join(dir, "data")).
+		AddPaths(filepath.Join(link, "users.csv"), filepath.Join(dir, "data", "users.csv")))
+
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	warnings, err := GetLoadWarnings(db)
+	require.NoError(t, err)
+	assert.Equal(t, []LoadWarning{{
+		Table: "users",
+		Message: "skipped " + filepath.Join(link, "users.csv") + ", which is the same file as " +
+			filepath.Join(dir, "data", "users.csv"),
+	}}, warnings)
+}
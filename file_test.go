@@ -81,6 +81,16 @@ func TestNewFile(t *testing.T) {
 			path:     "test.xlsx.zst",
 			expected: FileTypeXLSXZSTD,
 		},
+		{
+			name:     "Compressed tar archive",
+			path:     "test.tar.gz",
+			expected: FileTypeTARGZ,
+		},
+		{
+			name:     "Compressed tar archive with the short extension",
+			path:     "test.tgz",
+			expected: FileTypeTARGZ,
+		},
 		{
 			name:     "Unsupported file",
 			path:     "test.txt",
@@ -743,6 +753,10 @@ func TestFileTypeExtension(t *testing.T) {
 		{"XLSX BZ2", FileTypeXLSXBZ2, ".xlsx.bz2"},
 		{"XLSX XZ", FileTypeXLSXXZ, ".xlsx.xz"},
 		{"XLSX ZSTD", FileTypeXLSXZSTD, ".xlsx.zst"},
+		{"XLS", FileTypeXLS, ".xls"},
+		{"XLS GZ", FileTypeXLSGZ, ".xls.gz"},
+		{"ODS", FileTypeODS, ".ods"},
+		{"ODS BZ2", FileTypeODSBZ2, ".ods.bz2"},
 		{"Parquet GZ", FileTypeParquetGZ, ".parquet.gz"},
 		{"Parquet BZ2", FileTypeParquetBZ2, ".parquet.bz2"},
 		{"Parquet XZ", FileTypeParquetXZ, ".parquet.xz"},
@@ -805,19 +819,27 @@ func TestGetSupportedFilePatterns(t *testing.T) {
 
 	patterns := supportedFileExtPatterns()
 
-	// Should have 25 patterns: 5 base extensions × 5 compression variants (including none)
-	expectedCount := 25
+	// Should have 86 patterns: 12 base extensions × 7 compression variants (including none), plus zip and tgz
+	expectedCount := 86
 	if len(patterns) != expectedCount {
 		t.Errorf("GetSupportedFilePatterns() returned %d patterns, want %d", len(patterns), expectedCount)
 	}
 
 	// Check that all expected patterns are present
 	expectedPatterns := []string{
-		"*.csv", "*.csv.gz", "*.csv.bz2", "*.csv.xz", "*.csv.zst",
-		"*.tsv", "*.tsv.gz", "*.tsv.bz2", "*.tsv.xz", "*.tsv.zst",
-		"*.ltsv", "*.ltsv.gz", "*.ltsv.bz2", "*.ltsv.xz", "*.ltsv.zst",
-		"*.parquet", "*.parquet.gz", "*.parquet.bz2", "*.parquet.xz", "*.parquet.zst",
-		"*.xlsx", "*.xlsx.gz", "*.xlsx.bz2", "*.xlsx.xz", "*.xlsx.zst",
+		"*.csv", "*.csv.gz", "*.csv.bz2", "*.csv.xz", "*.csv.zst", "*.csv.lz4", "*.csv.br",
+		"*.tsv", "*.tsv.gz", "*.tsv.bz2", "*.tsv.xz", "*.tsv.zst", "*.tsv.lz4", "*.tsv.br",
+		"*.ssv", "*.ssv.gz", "*.ssv.bz2", "*.ssv.xz", "*.ssv.zst", "*.ssv.lz4", "*.ssv.br",
+		"*.psv", "*.psv.gz", "*.psv.bz2", "*.psv.xz", "*.psv.zst", "*.psv.lz4", "*.psv.br",
+		"*.ltsv", "*.ltsv.gz", "*.ltsv.bz2", "*.ltsv.xz", "*.ltsv.zst", "*.ltsv.lz4", "*.ltsv.br",
+		"*.parquet", "*.parquet.gz", "*.parquet.bz2", "*.parquet.xz", "*.parquet.zst", "*.parquet.lz4", "*.parquet.br",
+		"*.xlsx", "*.xlsx.gz", "*.xlsx.bz2", "*.xlsx.xz", "*.xlsx.zst", "*.xlsx.lz4", "*.xlsx.br",
+		"*.xls", "*.xls.gz", "*.xls.bz2", "*.xls.xz", "*.xls.zst", "*.xls.lz4", "*.xls.br",
+		"*.ods", "*.ods.gz", "*.ods.bz2", "*.ods.xz", "*.ods.zst", "*.ods.lz4", "*.ods.br",
+		"*.jsonl", "*.jsonl.gz", "*.jsonl.bz2", "*.jsonl.xz", "*.jsonl.zst", "*.jsonl.lz4", "*.jsonl.br",
+		"*.ndjson", "*.ndjson.gz", "*.ndjson.bz2", "*.ndjson.xz", "*.ndjson.zst", "*.ndjson.lz4", "*.ndjson.br",
+		"*.tar", "*.tar.gz", "*.tar.bz2", "*.tar.xz", "*.tar.zst", "*.tar.lz4", "*.tar.br",
+		"*.zip", "*.tgz",
 	}
 
 	for _, expected := range expectedPatterns {
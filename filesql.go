@@ -1,7 +1,6 @@
 package filesql
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
 	"encoding/csv"
@@ -38,6 +37,7 @@ import (
 //   - Files: "users.csv", "products.tsv", "logs.ltsv"
 //   - Compressed: "data.csv.gz", "archive.tsv.bz2"
 //   - Directories: "/data/" (loads all CSV/TSV/LTSV files recursively)
+//   - Glob patterns: "logs/2024-*.csv", "data/**/*.tsv.gz" (see DBBuilder.AddGlob)
 //
 // Table names:
 //   - "users.csv" → table "users"
@@ -183,37 +183,87 @@ func DumpDatabase(db *sql.DB, outputDir string, opts ...DumpOptions) error {
 		options = opts[0]
 	}
 
-	// Get the underlying connection
-	conn, err := db.Conn(context.Background())
-	if err != nil {
-		return fmt.Errorf("failed to get connection: %w", err)
+	// Check the database is usable; the dump itself uses db, so no connection is held
+	if err := db.PingContext(context.Background()); err != nil {
+		return withErrorCode(fmt.Errorf("failed to get connection: %w", err), ErrCodeDumpFailed)
 	}
-	defer conn.Close()
 
 	// Use generic dump functionality for all connections
-	return dumpSQLiteDatabase(db, outputDir, options)
+	return withErrorCode(dumpSQLiteDatabase(context.Background(), db, outputDir, options), ErrCodeDumpFailed)
 }
 
-// dumpSQLiteDatabase implements generic dump functionality for SQLite databases
-func dumpSQLiteDatabase(db *sql.DB, outputDir string, options DumpOptions) error {
+// dumpSQLiteDatabase implements generic dump functionality for SQLite databases.
+// When ctx is done, tables that have already been written are kept, the table
+// being written is removed, and the remaining tables are skipped.
+func dumpSQLiteDatabase(ctx context.Context, db *sql.DB, outputDir string, options DumpOptions) error {
+	if err := checkOutputPath(options.OutputRoot, outputDir); err != nil {
+		return err
+	}
+	if err := options.validateFloatFormats(); err != nil {
+		return err
+	}
+
 	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(outputDir, 0750); err != nil {
+	if err := options.fileSystem().MkdirAll(outputDir, 0750); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
+	unlock, err := options.lockOutputDir(outputDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	tempDir, removeTempDir, err := options.createTempDir(outputDir)
+	if err != nil {
+		return err
+	}
+	defer removeTempDir()
 
 	// Get all table names
-	tableNames, err := getSQLiteTableNames(db)
+	allTableNames, err := getSQLiteTableNames(db)
 	if err != nil {
 		return fmt.Errorf("failed to get table names: %w", err)
 	}
+	// Like the table names, the keys are read even if ctx is done, so an interrupted
+	// dump reports how many tables were saved
+	keys, err := readGeneratedKeys(context.WithoutCancel(ctx), db)
+	if err != nil {
+		return err
+	}
+	if options.Workbook != "" {
+		// The workbook holds every table, so it is rewritten whole when any table changed
+		options.changed = nil
+	}
+	tableNames := make([]string, 0, len(allTableNames))
+	for _, tableName := range allTableNames {
+		if options.selectsTable(tableName) && !options.excludesTable(tableName) && !keys.isMappingTable(tableName) {
+			tableNames = append(tableNames, tableName)
+		}
+	}
 
-	if len(tableNames) == 0 {
-		return errors.New("no tables found in database")
+	// Excluding every table is not an error, but a database without tables is
+	if len(allTableNames) == 0 {
+		return newCodedError(ErrCodeNoTables, "no tables found in database")
+	}
+	if name, ok := options.missingTable(allTableNames); ok {
+		return newCodedError(ErrCodeNoTables, "table %q selected for export not found in database", name)
+	}
+
+	if options.Workbook != "" {
+		return dumpSQLiteWorkbook(ctx, db, tableNames, outputDir, tempDir, options, keys)
 	}
 
 	// Export each table
-	for _, tableName := range tableNames {
-		if err := dumpSQLiteTable(db, tableName, outputDir, options); err != nil {
+	for i, tableName := range tableNames {
+		// The cause tells an expired auto-save timeout apart from a cancellation
+		if err := context.Cause(ctx); err != nil {
+			return fmt.Errorf("dump interrupted after %d of %d tables (saved: %s): %w",
+				i, len(tableNames), strings.Join(tableNames[:i], ", "), err)
+		}
+		if err := dumpSQLiteTable(ctx, db, tableName, outputDir, tempDir, options, keys); err != nil {
+			if ctxErr := context.Cause(ctx); ctxErr != nil {
+				return fmt.Errorf("dump interrupted after %d of %d tables (saved: %s): %w",
+					i, len(tableNames), strings.Join(tableNames[:i], ", "), ctxErr)
+			}
 			return fmt.Errorf("failed to export table %s: %w", tableName, err)
 		}
 	}
@@ -224,7 +274,8 @@ func dumpSQLiteDatabase(db *sql.DB, outputDir string, options DumpOptions) error
 // getSQLiteTableNames retrieves all user-defined table names from SQLite database
 func getSQLiteTableNames(db *sql.DB) ([]string, error) {
 	ctx := context.Background()
-	query := "SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"
+	// Internal metadata tables are not user data and must not be dumped
+	query := `SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' AND name NOT LIKE '\_filesql\_%' ESCAPE '\' ORDER BY name`
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -247,18 +298,31 @@ func getSQLiteTableNames(db *sql.DB) ([]string, error) {
 	return tableNames, nil
 }
 
-// dumpSQLiteTable exports a single table from SQLite database
-func dumpSQLiteTable(db *sql.DB, tableName, outputDir string, options DumpOptions) error {
+// dumpSQLiteTable exports a single table from SQLite database without its surrogate key columns.
+// With a temporary directory, the file is written there and then moved into outputDir.
+func dumpSQLiteTable(ctx context.Context, db *sql.DB, tableName, outputDir, tempDir string, options DumpOptions, keys generatedKeys) error {
+	skip, err := options.skipsTable(ctx, db, tableName)
+	if err != nil {
+		return err
+	}
+	if skip {
+		return nil
+	}
+	options = options.forTable(tableName)
+
 	// Get table columns
 	columns, err := getSQLiteTableColumns(db, tableName)
 	if err != nil {
 		return fmt.Errorf("failed to get columns for table %s: %w", tableName, err)
 	}
+	columns = keys.dataColumns(tableName, columns)
+	options, err = options.withSchemaCommentLines(ctx, db, tableName, columns)
+	if err != nil {
+		return err
+	}
 
 	// Query all data from table
-	ctx := context.Background()
-	query := fmt.Sprintf("SELECT * FROM `%s`", tableName) //nolint:gosec // Table name is validated and comes from database metadata
-	rows, err := db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, selectColumnsQuery(tableName, columns))
 	if err != nil {
 		return err
 	}
@@ -267,8 +331,28 @@ func dumpSQLiteTable(db *sql.DB, tableName, outputDir string, options DumpOption
 	// Create output file
 	fileName := tableName + options.FileExtension()
 	outputPath := filepath.Join(outputDir, fileName)
+	// Table names become file names, so they must not lead out of the output root either
+	if err := checkOutputPath(options.OutputRoot, outputPath); err != nil {
+		return err
+	}
 
-	return writeSQLiteTableData(outputPath, columns, rows, options)
+	var downcasts *downcastTracker
+	if options.checksDowncasts() {
+		downcasts = newDowncastTracker(columns)
+	}
+	err = options.writeTableFile(tempDir, outputPath, func(path string) error {
+		return writeSQLiteTableData(path, columns, rows, options, downcasts)
+	})
+	if err != nil {
+		if ctx.Err() != nil && tempDir == "" {
+			// Do not leave a truncated file behind when the dump was interrupted;
+			// atomic writes never replace the file in the first place
+			_ = options.fileSystem().Remove(outputPath) // Ignore remove error during error handling
+		}
+		return err
+	}
+	downcasts.report(tableName, options.DowncastHandler)
+	return nil
 }
 
 // getSQLiteTableColumns retrieves column names for a specific table
@@ -300,52 +384,98 @@ func getSQLiteTableColumns(db *sql.DB, tableName string) ([]string, error) {
 	return columns, nil
 }
 
-// writeSQLiteTableData writes table data to file with specified format
-func writeSQLiteTableData(outputPath string, columns []string, rows *sql.Rows, options DumpOptions) error {
-	// Create the file
-	file, err := os.Create(outputPath) //nolint:gosec // Output path is constructed from validated directory and table name
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", outputPath, err)
-	}
-	defer file.Close()
-
-	// Create writer with compression if needed
-	writer, closeWriter, err := createCompressedWriter(file, options.Compression)
-	if err != nil {
-		return fmt.Errorf("failed to create writer: %w", err)
-	}
-	defer closeWriter()
+// writeSQLiteTableData writes table data to file with specified format, recording
+// values whose text is ambiguous or lossy in downcasts (may be nil)
+func writeSQLiteTableData(outputPath string, columns []string, rows *sql.Rows, options DumpOptions, downcasts *downcastTracker) error {
+	fsys := options.fileSystem()
+	formatter := newValueFormatter(columns, options)
+	formatter.downcasts = downcasts
 
 	// Write data based on format
 	switch options.Format {
 	case OutputFormatCSV:
-		return writeCSVData(writer, columns, rows)
+		return writeFile(fsys, outputPath, options.Compression, func(writer io.Writer) error {
+			if err := writeSchemaComments(writer, options.schemaComments); err != nil {
+				return err
+			}
+			return writeCSVData(writer, columns, rows, formatter)
+		})
 	case OutputFormatTSV:
-		return writeTSVData(writer, columns, rows)
+		return writeFile(fsys, outputPath, options.Compression, func(writer io.Writer) error {
+			if err := writeSchemaComments(writer, options.schemaComments); err != nil {
+				return err
+			}
+			return writeTSVData(writer, columns, rows, formatter)
+		})
+	case OutputFormatSSV:
+		return writeFile(fsys, outputPath, options.Compression, func(writer io.Writer) error {
+			return writeSSVData(writer, columns, rows, formatter)
+		})
+	case OutputFormatPSV:
+		return writeFile(fsys, outputPath, options.Compression, func(writer io.Writer) error {
+			return writePSVData(writer, columns, rows, formatter)
+		})
 	case OutputFormatLTSV:
-		return writeLTSVData(writer, columns, rows)
+		return writeFile(fsys, outputPath, options.Compression, func(writer io.Writer) error {
+			return writeLTSVData(writer, columns, rows, formatter)
+		})
 	case OutputFormatParquet:
-		return writeParquetTableData(outputPath, columns, rows, options.Compression)
+		return writeParquetTableData(fsys, outputPath, columns, rows, options, formatter)
 	case OutputFormatXLSX:
-		return writeXLSXTableData(outputPath, columns, rows, options.Compression)
+		return writeXLSXTableData(fsys, outputPath, columns, rows, options.Compression, formatter)
+	case OutputFormatJSONL:
+		return writeFile(fsys, outputPath, options.Compression, func(writer io.Writer) error {
+			return writeJSONLData(writer, columns, rows, formatter)
+		})
 	default:
 		return fmt.Errorf("unsupported output format: %v", options.Format)
 	}
 }
 
+// writeFile creates outputPath in fsys and calls write with a writer that compresses
+// into it. Errors from finishing the compression and closing the file are returned,
+// so a failed write is never reported as success.
+func writeFile(fsys FileSystem, outputPath string, compression CompressionType, write func(io.Writer) error) (err error) {
+	file, err := fsys.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", outputPath, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file %s: %w", outputPath, closeErr)
+		}
+	}()
+
+	// Create writer with compression if needed
+	writer, closeWriter, err := createCompressedWriter(file, compression)
+	if err != nil {
+		return fmt.Errorf("failed to create writer: %w", err)
+	}
+	if err := write(writer); err != nil {
+		_ = closeWriter() // Ignore close error during error handling
+		return err
+	}
+	if err := closeWriter(); err != nil {
+		return fmt.Errorf("failed to finish writing %s: %w", outputPath, err)
+	}
+	return nil
+}
+
 // createCompressedWriter creates an appropriate writer based on compression type
-func createCompressedWriter(file *os.File, compression CompressionType) (io.Writer, func() error, error) {
+func createCompressedWriter(file io.Writer, compression CompressionType) (io.Writer, func() error, error) {
 	handler := NewCompressionHandler(compression)
 	return handler.CreateWriter(file)
 }
 
 // writeDelimitedData writes data in CSV or TSV format based on delimiter
-func writeDelimitedData(writer io.Writer, columns []string, rows *sql.Rows, delimiter rune) error {
+func writeDelimitedData(writer io.Writer, columns []string, rows *sql.Rows, delimiter rune, formatter valueFormatter) error {
+	if formatter.quoteEmptyStrings {
+		return writeQuotedEmptyData(writer, columns, rows, delimiter, formatter)
+	}
 	csvWriter := csv.NewWriter(writer)
 	if delimiter != csvDelimiter {
 		csvWriter.Comma = delimiter
 	}
-	defer csvWriter.Flush()
 
 	// Write header
 	if err := csvWriter.Write(columns); err != nil {
@@ -367,33 +497,44 @@ func writeDelimitedData(writer io.Writer, columns []string, rows *sql.Rows, deli
 
 		record := make([]string, len(columns))
 		for i, value := range values {
-			if value == nil {
-				record[i] = ""
-			} else {
-				record[i] = fmt.Sprintf("%v", value)
-			}
+			record[i] = formatter.format(i, value)
 		}
 
 		if err := csvWriter.Write(record); err != nil {
 			return err
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-	return rows.Err()
+	// The CSV writer is buffered, so write errors may only surface on flush
+	csvWriter.Flush()
+	return csvWriter.Error()
 }
 
 // writeCSVData writes data in CSV format
-func writeCSVData(writer io.Writer, columns []string, rows *sql.Rows) error {
-	return writeDelimitedData(writer, columns, rows, csvDelimiter)
+func writeCSVData(writer io.Writer, columns []string, rows *sql.Rows, formatter valueFormatter) error {
+	return writeDelimitedData(writer, columns, rows, csvDelimiter, formatter)
 }
 
 // writeTSVData writes data in TSV format
-func writeTSVData(writer io.Writer, columns []string, rows *sql.Rows) error {
-	return writeDelimitedData(writer, columns, rows, tsvDelimiter)
+func writeTSVData(writer io.Writer, columns []string, rows *sql.Rows, formatter valueFormatter) error {
+	return writeDelimitedData(writer, columns, rows, tsvDelimiter, formatter)
+}
+
+// writeSSVData writes data in semicolon-separated format
+func writeSSVData(writer io.Writer, columns []string, rows *sql.Rows, formatter valueFormatter) error {
+	return writeDelimitedData(writer, columns, rows, ssvDelimiter, formatter)
+}
+
+// writePSVData writes data in pipe-separated format
+func writePSVData(writer io.Writer, columns []string, rows *sql.Rows, formatter valueFormatter) error {
+	return writeDelimitedData(writer, columns, rows, psvDelimiter, formatter)
 }
 
 // writeLTSVData writes data in LTSV format
-func writeLTSVData(writer io.Writer, columns []string, rows *sql.Rows) error {
+func writeLTSVData(writer io.Writer, columns []string, rows *sql.Rows, formatter valueFormatter) error {
 	// Prepare for scanning
 	values := make([]any, len(columns))
 	scanArgs := make([]any, len(columns))
@@ -410,11 +551,7 @@ func writeLTSVData(writer io.Writer, columns []string, rows *sql.Rows) error {
 		// Build LTSV record
 		var parts []string
 		for i, col := range columns {
-			value := ""
-			if values[i] != nil {
-				value = fmt.Sprintf("%v", values[i])
-			}
-			parts = append(parts, fmt.Sprintf("%s:%s", col, value))
+			parts = append(parts, fmt.Sprintf("%s:%s", col, formatter.format(i, values[i])))
 		}
 
 		line := strings.Join(parts, "\t") + "\n"
@@ -707,15 +844,14 @@ func extractValueFromArrowArray(arr arrow.Array, index int64) string {
 }
 
 // writeParquetTableData writes SQLite table data to Parquet format
-func writeParquetTableData(outputPath string, columns []string, rows *sql.Rows, compression CompressionType) error {
+func writeParquetTableData(fsys FileSystem, outputPath string, columns []string, rows *sql.Rows, options DumpOptions, formatter valueFormatter) error {
 	if len(columns) == 0 {
 		return errors.New("no columns defined")
 	}
 
-	// For Parquet format, compression is handled at the file level, not stream level
-	// We ignore the compression parameter for now as Parquet has its own compression
-	if compression != CompressionNone {
-		return errors.New("external compression not supported for Parquet format - use Parquet's built-in compression instead")
+	// Parquet compresses column data internally, with the codec of ParquetCompression
+	if options.Compression != CompressionNone {
+		return errors.New("external compression not supported for Parquet format - use Parquet's built-in compression instead (see WithParquetCompression)")
 	}
 
 	// Read all rows into memory first
@@ -735,11 +871,7 @@ func writeParquetTableData(outputPath string, columns []string, rows *sql.Rows,
 
 		row := make([]string, len(columns))
 		for i, value := range values {
-			if value == nil {
-				row[i] = ""
-			} else {
-				row[i] = fmt.Sprintf("%v", value)
-			}
+			row[i] = formatter.format(i, value)
 		}
 		allRows = append(allRows, row)
 	}
@@ -748,25 +880,16 @@ func writeParquetTableData(outputPath string, columns []string, rows *sql.Rows,
 		return fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	return writeParquetData(outputPath, columns, allRows)
+	return writeParquetData(fsys, outputPath, columns, allRows, options.ParquetCompression)
 }
 
-// writeParquetData writes data to Parquet format
-func writeParquetData(outputPath string, columns []string, rows [][]string) error {
-	if len(rows) == 0 {
-		return errors.New("no data to write")
-	}
+// writeParquetData writes data to Parquet format.
+// Without rows, the file contains the schema only.
+func writeParquetData(fsys FileSystem, outputPath string, columns []string, rows [][]string, compression ParquetCompression) error {
 	if len(columns) == 0 {
 		return errors.New("no columns defined")
 	}
 
-	// Create output file
-	file, err := os.Create(outputPath) //nolint:gosec
-	if err != nil {
-		return fmt.Errorf("failed to create parquet file: %w", err)
-	}
-	defer file.Close()
-
 	// Create Arrow schema - for simplicity, treat all columns as strings
 	fields := make([]arrow.Field, len(columns))
 	for i, col := range columns {
@@ -799,73 +922,30 @@ func writeParquetData(outputPath string, columns []string, rows [][]string) erro
 	record := builder.NewRecord()
 	defer record.Release()
 
-	// Create Parquet writer
-	arrowProps := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
-	writer, err := pqarrow.NewFileWriter(schema, file, nil, arrowProps)
-	if err != nil {
-		return fmt.Errorf("failed to create parquet writer: %w", err)
-	}
-	defer writer.Close()
-
-	// Write record to Parquet file
-	if err := writer.Write(record); err != nil {
-		return fmt.Errorf("failed to write record to parquet: %w", err)
-	}
-
-	// Flush and close writer explicitly
-	if err := writer.Close(); err != nil {
-		return fmt.Errorf("failed to close parquet writer: %w", err)
-	}
-
-	return nil
-}
-
-// writeXLSXTableData writes SQLite table data to Excel XLSX format
-func writeXLSXTableData(outputPath string, columns []string, rows *sql.Rows, compression CompressionType) error {
-	if len(columns) == 0 {
-		return errors.New("no columns defined")
-	}
-
-	// Create new Excel file
-	f := excelize.NewFile()
-	defer func() {
-		_ = f.Close() // Ignore close error
-	}()
-
-	// For Excel, we use the table name as sheet name
-	// Extract table name from output path (remove directory and extension)
-	fileName := filepath.Base(outputPath)
-
-	// First remove compression extension if present (case-insensitive)
-	compressionExts := []string{".gz", ".bz2", ".xz", ".zst"}
-	for _, ext := range compressionExts {
-		if strings.HasSuffix(strings.ToLower(fileName), ext) {
-			fileName = strings.TrimSuffix(fileName, ext)
-			break
+	return writeFile(fsys, outputPath, CompressionNone, func(file io.Writer) error {
+		// Hide Close from the Parquet writer, which would otherwise close the file itself
+		arrowProps := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
+		writer, err := pqarrow.NewFileWriter(schema, struct{ io.Writer }{file}, compression.writerProperties(), arrowProps)
+		if err != nil {
+			return fmt.Errorf("failed to create parquet writer: %w", err)
 		}
-	}
-
-	// Then remove the file extension (e.g., .xlsx)
-	tableName := strings.TrimSuffix(fileName, filepath.Ext(fileName))
-
-	// Create a sheet with the table name, or use default if invalid
-	sheetName := tableName
-	if sheetName == "" {
-		sheetName = "Sheet1"
-	}
+		defer writer.Close()
 
-	// Create new sheet (replace default Sheet1)
-	if sheetName != "Sheet1" {
-		_, err := f.NewSheet(sheetName)
-		if err != nil {
-			return fmt.Errorf("failed to create sheet %s: %w", sheetName, err)
+		// Write record to Parquet file
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write record to parquet: %w", err)
 		}
-		// Delete default sheet
-		if err := f.DeleteSheet("Sheet1"); err != nil {
-			return fmt.Errorf("failed to delete default sheet: %w", err)
+
+		// Flush and close writer explicitly
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("failed to close parquet writer: %w", err)
 		}
-	}
+		return nil
+	})
+}
 
+// writeXLSXSheet writes the columns and rows of a table into the existing sheet of f
+func writeXLSXSheet(f *excelize.File, sheetName string, columns []string, rows *sql.Rows, formatter valueFormatter) error {
 	// Set headers
 	for i, col := range columns {
 		cell, err := excelize.CoordinatesToCellName(i+1, 1)
@@ -908,7 +988,7 @@ func writeXLSXTableData(outputPath string, columns []string, rows *sql.Rows, com
 				case string:
 					cellValue = v
 				default:
-					cellValue = fmt.Sprintf("%v", v)
+					cellValue = formatter.format(i, v)
 				}
 			}
 
@@ -922,41 +1002,63 @@ func writeXLSXTableData(outputPath string, columns []string, rows *sql.Rows, com
 	if err := rows.Err(); err != nil {
 		return fmt.Errorf("error reading rows: %w", err)
 	}
+	return nil
+}
 
-	// Handle compression by saving to buffer first if needed
-	if compression != CompressionNone {
-		// For compressed output, we need to save to a buffer first
-		var buf bytes.Buffer
-		if err := f.Write(&buf); err != nil {
-			return fmt.Errorf("failed to write Excel file to buffer: %w", err)
-		}
+// writeXLSXTableData writes SQLite table data to Excel XLSX format
+func writeXLSXTableData(fsys FileSystem, outputPath string, columns []string, rows *sql.Rows, compression CompressionType, formatter valueFormatter) error {
+	if len(columns) == 0 {
+		return errors.New("no columns defined")
+	}
 
-		// Create compressed output file
-		file, err := os.Create(outputPath) //nolint:gosec // Output path is constructed from validated directory and table name
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
+	// Create new Excel file
+	f := excelize.NewFile()
+	defer func() {
+		_ = f.Close() // Ignore close error
+	}()
+
+	// For Excel, we use the table name as sheet name
+	// Extract table name from output path (remove directory and extension)
+	fileName := filepath.Base(outputPath)
+
+	// First remove compression extension if present (case-insensitive)
+	compressionExts := []string{".gz", ".bz2", ".xz", ".zst"}
+	for _, ext := range compressionExts {
+		if strings.HasSuffix(strings.ToLower(fileName), ext) {
+			fileName = strings.TrimSuffix(fileName, ext)
+			break
 		}
-		defer file.Close()
+	}
 
-		// Create compressed writer
-		compressedWriter, closeWriter, err := createCompressedWriter(file, compression)
+	// Then remove the file extension (e.g., .xlsx)
+	tableName := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+
+	// Create a sheet with the table name, or use default if invalid
+	sheetName := tableName
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+
+	// Create new sheet (replace default Sheet1)
+	if sheetName != "Sheet1" {
+		_, err := f.NewSheet(sheetName)
 		if err != nil {
-			return fmt.Errorf("failed to create compressed writer: %w", err)
+			return fmt.Errorf("failed to create sheet %s: %w", sheetName, err)
 		}
-		defer closeWriter()
-
-		// Write compressed data
-		if _, err := compressedWriter.Write(buf.Bytes()); err != nil {
-			return fmt.Errorf("failed to write compressed data: %w", err)
+		// Delete default sheet
+		if err := f.DeleteSheet("Sheet1"); err != nil {
+			return fmt.Errorf("failed to delete default sheet: %w", err)
 		}
-
-		return nil
 	}
 
-	// Save directly to file for uncompressed output
-	if err := f.SaveAs(outputPath); err != nil {
-		return fmt.Errorf("failed to save Excel file: %w", err)
+	if err := writeXLSXSheet(f, sheetName, columns, rows, formatter); err != nil {
+		return err
 	}
 
-	return nil
+	return writeFile(fsys, outputPath, compression, func(writer io.Writer) error {
+		if err := f.Write(writer); err != nil {
+			return fmt.Errorf("failed to save Excel file: %w", err)
+		}
+		return nil
+	})
 }
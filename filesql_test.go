@@ -3021,7 +3021,7 @@ func TestSQLiteDumpFunctions(t *testing.T) {
 		tempDir := t.TempDir()
 		options := NewDumpOptions()
 
-		err = dumpSQLiteDatabase(db, tempDir, options)
+		err = dumpSQLiteDatabase(context.Background(), db, tempDir, options)
 		require.NoError(t, err, "dumpSQLiteDatabase failed")
 
 		// Verify file was created
@@ -3561,7 +3561,7 @@ func TestWriteXLSXTableData(t *testing.T) {
 		outputPath := filepath.Join(tempDir, "output.xlsx")
 
 		// Test writeXLSXTableData
-		err = writeXLSXTableData(outputPath, columns, rows, CompressionNone)
+		err = writeXLSXTableData(NewOSFileSystem(), outputPath, columns, rows, CompressionNone, valueFormatter{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -3635,7 +3635,7 @@ func TestWriteXLSXTableData(t *testing.T) {
 		outputPath := filepath.Join(tempDir, "output.xlsx.gz")
 
 		// Test writeXLSXTableData with compression
-		err = writeXLSXTableData(outputPath, columns, rows, CompressionGZ)
+		err = writeXLSXTableData(NewOSFileSystem(), outputPath, columns, rows, CompressionGZ, valueFormatter{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -3697,7 +3697,7 @@ func TestWriteXLSXTableData(t *testing.T) {
 		outputPath := filepath.Join(tempDir, "empty.xlsx")
 
 		// Test with no columns
-		err := writeXLSXTableData(outputPath, []string{}, nil, CompressionNone)
+		err := writeXLSXTableData(NewOSFileSystem(), outputPath, []string{}, nil, CompressionNone, valueFormatter{})
 		if err == nil {
 			t.Error("Expected error for no columns")
 		}
@@ -3731,7 +3731,7 @@ func TestWriteXLSXTableData(t *testing.T) {
 		outputPath := filepath.Join(tempDir, "output.xlsx.bz2")
 
 		// Test writeXLSXTableData with bz2 compression (should fail)
-		err = writeXLSXTableData(outputPath, columns, rows, CompressionBZ2)
+		err = writeXLSXTableData(NewOSFileSystem(), outputPath, columns, rows, CompressionBZ2, valueFormatter{})
 		if err == nil {
 			t.Error("Expected error for unsupported bz2 compression")
 		}
@@ -3765,7 +3765,7 @@ func TestWriteXLSXTableData(t *testing.T) {
 		outputPath := filepath.Join(tempDir, "output.xlsx.xz")
 
 		// Test writeXLSXTableData with xz compression
-		err = writeXLSXTableData(outputPath, columns, rows, CompressionXZ)
+		err = writeXLSXTableData(NewOSFileSystem(), outputPath, columns, rows, CompressionXZ, valueFormatter{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -0,0 +1,250 @@
+package filesqltest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nao1215/filesql"
+)
+
+// maxReportedDifferences is the number of differences listed in a failure message
+const maxReportedDifferences = 10
+
+// Option changes how AssertTableEquals and AssertQueryEquals compare rows.
+type Option func(*compareOptions)
+
+// compareOptions holds the settings changed by Option
+type compareOptions struct {
+	// ignoreRowOrder compares rows as a multiset instead of in order
+	ignoreRowOrder bool
+}
+
+// IgnoreRowOrder compares rows regardless of their order. Use it for tables and
+// queries whose row order is not defined, such as the result of a GROUP BY without
+// ORDER BY.
+func IgnoreRowOrder() Option {
+	return func(o *compareOptions) {
+		o.ignoreRowOrder = true
+	}
+}
+
+// rowSet is the column names and the formatted values of a table or query result
+type rowSet struct {
+	columns []string
+	rows    [][]string
+}
+
+// AssertTableEquals checks that a table of db has the same columns and rows as a
+// fixture file, and reports the differences with tb.Errorf otherwise.
+//
+// The fixture can be any file filesql.Open can load into a single table, usually a
+// CSV file. Columns must have the same names in the same order. Values are compared
+// as text, except that numbers are equal when their values are, so 1.50 in a fixture
+// matches a REAL value 1.5. NULL matches an empty field. Rows are compared in table
+// order unless IgnoreRowOrder is given.
+//
+// It returns whether the table matches, so a test can stop early:
+//
+//	func TestMonthlyReport(t *testing.T) {
+//		db := filesqltest.NewDB(t, map[string]string{"sales.csv": salesCSV})
+//		if _, err := db.Exec(reportSQL); err != nil {
+//			t.Fatal(err)
+//		}
+//		filesqltest.AssertTableEquals(t, db, "testdata/monthly_report.csv", "monthly_report")
+//	}
+//
+// A failure lists the first differences by row and column:
+//
+//	filesqltest: table monthly_report does not match testdata/monthly_report.csv:
+//	  row 2, column total: expected "250", actual "240"
+//	  row 4: unexpected row [2024-04 90]
+func AssertTableEquals(tb testing.TB, db *sql.DB, expectedPath, table string, opts ...Option) bool {
+	tb.Helper()
+
+	query := fmt.Sprintf(`SELECT * FROM "%s"`, strings.ReplaceAll(table, `"`, `""`))
+	return assertRowsEqual(tb, db, expectedPath, query, "table "+table, opts)
+}
+
+// AssertQueryEquals checks that the result of query has the same columns and rows as
+// a fixture file, and reports the differences with tb.Errorf otherwise.
+//
+// Values are compared like AssertTableEquals does. Give the query an ORDER BY clause
+// or use IgnoreRowOrder, since SQL does not define the order of rows otherwise.
+//
+// Example:
+//
+//	filesqltest.AssertQueryEquals(t, db, "testdata/top_customers.csv",
+//		"SELECT name, SUM(total) AS total FROM orders GROUP BY name ORDER BY total DESC LIMIT 3")
+func AssertQueryEquals(tb testing.TB, db *sql.DB, expectedPath, query string, opts ...Option) bool {
+	tb.Helper()
+
+	return assertRowsEqual(tb, db, expectedPath, query, "query", opts)
+}
+
+// assertRowsEqual compares the result of query with the fixture and reports differences
+func assertRowsEqual(tb testing.TB, db *sql.DB, expectedPath, query, subject string, opts []Option) bool {
+	tb.Helper()
+
+	var options compareOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	expected, err := readFixture(expectedPath)
+	if err != nil {
+		tb.Errorf("filesqltest: failed to read %s: %v", expectedPath, err)
+		return false
+	}
+	actual, err := queryRows(db, query)
+	if err != nil {
+		tb.Errorf("filesqltest: failed to read %s: %v", subject, err)
+		return false
+	}
+
+	differences := compareRowSets(expected, actual, options)
+	if len(differences) == 0 {
+		return true
+	}
+	if len(differences) > maxReportedDifferences {
+		omitted := len(differences) - maxReportedDifferences
+		differences = append(differences[:maxReportedDifferences], fmt.Sprintf("... and %d more differences", omitted))
+	}
+	tb.Errorf("filesqltest: %s does not match %s:\n  %s", subject, expectedPath, strings.Join(differences, "\n  "))
+	return false
+}
+
+// readFixture loads the fixture file and returns the rows of its only table
+func readFixture(path string) (rowSet, error) {
+	db, err := filesql.OpenContext(context.Background(), path)
+	if err != nil {
+		return rowSet{}, err
+	}
+	defer db.Close()
+
+	tables, err := queryRows(db, `SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return rowSet{}, err
+	}
+	if len(tables.rows) != 1 {
+		return rowSet{}, fmt.Errorf("fixture must contain exactly one table, found %d", len(tables.rows))
+	}
+	return queryRows(db, fmt.Sprintf(`SELECT * FROM "%s"`, strings.ReplaceAll(tables.rows[0][0], `"`, `""`)))
+}
+
+// queryRows runs query and returns its columns and values formatted as text
+func queryRows(db *sql.DB, query string) (rowSet, error) {
+	rows, err := db.QueryContext(context.Background(), query)
+	if err != nil {
+		return rowSet{}, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return rowSet{}, err
+	}
+
+	result := rowSet{columns: columns}
+	values := make([]any, len(columns))
+	scanArgs := make([]any, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return rowSet{}, err
+		}
+		row := make([]string, len(columns))
+		for i, value := range values {
+			row[i] = formatValue(value)
+		}
+		result.rows = append(result.rows, row)
+	}
+	return result, rows.Err()
+}
+
+// formatValue returns the text form of a scanned value; NULL is empty
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareRowSets returns the differences between expected and actual, one per line
+func compareRowSets(expected, actual rowSet, options compareOptions) []string {
+	if !slices.Equal(expected.columns, actual.columns) {
+		return []string{fmt.Sprintf("columns: expected %v, actual %v", expected.columns, actual.columns)}
+	}
+	if options.ignoreRowOrder {
+		return compareUnordered(expected.rows, actual.rows)
+	}
+
+	var differences []string
+	for i := 0; i < max(len(expected.rows), len(actual.rows)); i++ {
+		// Rows are numbered like lines of a CSV fixture, after the header
+		rowNumber := i + 1
+		switch {
+		case i >= len(actual.rows):
+			differences = append(differences, fmt.Sprintf("row %d: missing row %v", rowNumber, expected.rows[i]))
+		case i >= len(expected.rows):
+			differences = append(differences, fmt.Sprintf("row %d: unexpected row %v", rowNumber, actual.rows[i]))
+		default:
+			for j, column := range expected.columns {
+				if !valuesEqual(expected.rows[i][j], actual.rows[i][j]) {
+					differences = append(differences, fmt.Sprintf("row %d, column %s: expected %q, actual %q",
+						rowNumber, column, expected.rows[i][j], actual.rows[i][j]))
+				}
+			}
+		}
+	}
+	return differences
+}
+
+// compareUnordered returns the rows that only appear in expected or actual,
+// counting duplicates
+func compareUnordered(expected, actual [][]string) []string {
+	remaining := slices.Clone(actual)
+	var differences []string
+	for _, want := range expected {
+		index := slices.IndexFunc(remaining, func(got []string) bool { return rowsEqual(want, got) })
+		if index < 0 {
+			differences = append(differences, fmt.Sprintf("missing row %v", want))
+			continue
+		}
+		remaining = slices.Delete(remaining, index, index+1)
+	}
+	for _, got := range remaining {
+		differences = append(differences, fmt.Sprintf("unexpected row %v", got))
+	}
+	return differences
+}
+
+// rowsEqual reports whether all values of two rows are equal
+func rowsEqual(a, b []string) bool {
+	for i := range a {
+		if !valuesEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// valuesEqual reports whether two values are the same text or the same number
+func valuesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	return errA == nil && errB == nil && x == y
+}
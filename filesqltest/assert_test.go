@@ -0,0 +1,148 @@
+package filesqltest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB captures the failures reported by the assertion helpers
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func writeFixture(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestAssertTableEquals(t *testing.T) {
+	t.Parallel()
+
+	t.Run("matching table passes", func(t *testing.T) {
+		t.Parallel()
+
+		db := NewDB(t, map[string]string{"prices.csv": "id,price,note\n1,1.5,\n2,3,x\n"})
+		expected := writeFixture(t, "expected.csv", "id,price,note\n1,1.50,\n2,3.0,x\n")
+
+		rec := &recordingTB{TB: t}
+		assert.True(t, AssertTableEquals(rec, db, expected, "prices"))
+		assert.Empty(t, rec.errors)
+	})
+
+	t.Run("changed values are reported by row and column", func(t *testing.T) {
+		t.Parallel()
+
+		db := NewDB(t, map[string]string{"users.csv": "id,name\n1,Alice\n2,Bobby\n3,Dan\n"})
+		expected := writeFixture(t, "expected.csv", "id,name\n1,Alice\n2,Bob\n")
+
+		rec := &recordingTB{TB: t}
+		assert.False(t, AssertTableEquals(rec, db, expected, "users"))
+		require.Len(t, rec.errors, 1)
+		assert.Equal(t, "filesqltest: table users does not match "+expected+":\n"+
+			"  row 2, column name: expected \"Bob\", actual \"Bobby\"\n"+
+			"  row 3: unexpected row [3 Dan]", rec.errors[0])
+	})
+
+	t.Run("different columns are reported", func(t *testing.T) {
+		t.Parallel()
+
+		db := NewDB(t, map[string]string{"users.csv": "id,name\n1,Alice\n"})
+		expected := writeFixture(t, "expected.csv", "name,id\nAlice,1\n")
+
+		rec := &recordingTB{TB: t}
+		assert.False(t, AssertTableEquals(rec, db, expected, "users"))
+		require.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], "columns: expected [name id], actual [id name]")
+	})
+
+	t.Run("long reports are truncated", func(t *testing.T) {
+		t.Parallel()
+
+		content := "id\n"
+		for i := range 15 {
+			content += fmt.Sprintf("%d\n", i)
+		}
+		db := NewDB(t, map[string]string{"numbers.csv": content})
+		expected := writeFixture(t, "expected.csv", "id\n")
+
+		rec := &recordingTB{TB: t}
+		assert.False(t, AssertTableEquals(rec, db, expected, "numbers"))
+		require.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], "... and 5 more differences")
+	})
+
+	t.Run("missing table is reported", func(t *testing.T) {
+		t.Parallel()
+
+		db := NewDB(t, map[string]string{"users.csv": "id\n1\n"})
+		expected := writeFixture(t, "expected.csv", "id\n1\n")
+
+		rec := &recordingTB{TB: t}
+		assert.False(t, AssertTableEquals(rec, db, expected, "orders"))
+		require.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], "filesqltest: failed to read table orders")
+	})
+
+	t.Run("missing fixture is reported", func(t *testing.T) {
+		t.Parallel()
+
+		db := NewDB(t, map[string]string{"users.csv": "id\n1\n"})
+
+		rec := &recordingTB{TB: t}
+		assert.False(t, AssertTableEquals(rec, db, filepath.Join(t.TempDir(), "missing.csv"), "users"))
+		require.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], "filesqltest: failed to read")
+	})
+}
+
+func TestAssertQueryEquals(t *testing.T) {
+	t.Parallel()
+
+	db := NewDB(t, map[string]string{"orders.csv": "name,total\nAlice,100\nBob,50\nAlice,20\n"})
+	query := "SELECT name, SUM(total) AS total FROM orders GROUP BY name"
+
+	t.Run("ordered comparison detects swapped rows", func(t *testing.T) {
+		t.Parallel()
+
+		expected := writeFixture(t, "expected.csv", "name,total\nBob,50\nAlice,120\n")
+		rec := &recordingTB{TB: t}
+		assert.False(t, AssertQueryEquals(rec, db, expected, query+" ORDER BY name"))
+		require.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], `row 1, column name: expected "Bob", actual "Alice"`)
+	})
+
+	t.Run("IgnoreRowOrder compares rows as a multiset", func(t *testing.T) {
+		t.Parallel()
+
+		expected := writeFixture(t, "expected.csv", "name,total\nBob,50\nAlice,120\n")
+		rec := &recordingTB{TB: t}
+		assert.True(t, AssertQueryEquals(rec, db, expected, query, IgnoreRowOrder()))
+		assert.Empty(t, rec.errors)
+	})
+
+	t.Run("IgnoreRowOrder reports missing and unexpected rows", func(t *testing.T) {
+		t.Parallel()
+
+		expected := writeFixture(t, "expected.tsv", "name\ttotal\nBob\t50\nCarol\t10\n")
+		rec := &recordingTB{TB: t}
+		assert.False(t, AssertQueryEquals(rec, db, expected, query, IgnoreRowOrder()))
+		require.Len(t, rec.errors, 1)
+		assert.Equal(t, "filesqltest: query does not match "+expected+":\n"+
+			"  missing row [Carol 10]\n"+
+			"  unexpected row [Alice 120]", rec.errors[0])
+	})
+}
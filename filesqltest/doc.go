@@ -0,0 +1,24 @@
+// Package filesqltest provides helpers for tests that run SQL against filesql databases.
+//
+// Instead of committing fixture files or writing temporary files by hand, tests can
+// describe their input files inline:
+//
+//	func TestActiveUsers(t *testing.T) {
+//		db := filesqltest.NewDB(t, map[string]string{
+//			"users.csv": "id,name,active\n1,Alice,1\n2,Bob,0\n",
+//		})
+//
+//		var count int
+//		if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE active = 1").Scan(&count); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+//
+// The database is closed automatically when the test finishes.
+//
+// AssertTableEquals and AssertQueryEquals compare a table or a query result against a
+// fixture file and report the rows and columns that differ, for regression tests of
+// SQL transformations:
+//
+//	filesqltest.AssertTableEquals(t, db, "testdata/monthly_report.csv", "monthly_report")
+package filesqltest
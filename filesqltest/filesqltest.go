@@ -0,0 +1,57 @@
+package filesqltest
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nao1215/filesql"
+)
+
+// NewDB creates an in-memory filesql database from inline file contents.
+//
+// The keys of files are file names and decide both the table name and the format,
+// exactly as they do for filesql.Open (e.g. "users.csv" becomes table "users",
+// "logs.ltsv" is parsed as LTSV). The values are the file contents.
+//
+// The files are written to a temporary directory owned by tb. NewDB fails the test
+// immediately if the database cannot be created, and closes the database during cleanup.
+//
+// Example:
+//
+//	db := filesqltest.NewDB(t, map[string]string{
+//		"users.csv":  "id,name\n1,Alice\n",
+//		"orders.tsv": "id\tuser_id\n10\t1\n",
+//	})
+func NewDB(tb testing.TB, files map[string]string) *sql.DB {
+	tb.Helper()
+
+	if len(files) == 0 {
+		tb.Fatal("filesqltest: at least one file is required")
+	}
+
+	dir := tb.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			tb.Fatalf("filesqltest: failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			tb.Fatalf("filesqltest: failed to write %s: %v", name, err)
+		}
+	}
+
+	db, err := filesql.OpenContext(context.Background(), dir)
+	if err != nil {
+		tb.Fatalf("filesqltest: failed to open database: %v", err)
+	}
+	tb.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			tb.Errorf("filesqltest: failed to close database: %v", err)
+		}
+	})
+
+	return db
+}
@@ -0,0 +1,44 @@
+package filesqltest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDB(t *testing.T) {
+	t.Parallel()
+
+	t.Run("creates one table per inline file", func(t *testing.T) {
+		t.Parallel()
+
+		db := NewDB(t, map[string]string{
+			"users.csv":        "id,name\n1,Alice\n2,Bob\n",
+			"orders.tsv":       "id\tuser_id\n10\t1\n",
+			"nested/logs.ltsv": "level:info\tmsg:started\n",
+		})
+
+		var users, orders, logs int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM users").Scan(&users))
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM orders").Scan(&orders))
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM logs").Scan(&logs))
+		assert.Equal(t, 2, users)
+		assert.Equal(t, 1, orders)
+		assert.Equal(t, 1, logs)
+	})
+
+	t.Run("supports joins across inline files", func(t *testing.T) {
+		t.Parallel()
+
+		db := NewDB(t, map[string]string{
+			"users.csv":  "id,name\n1,Alice\n",
+			"orders.csv": "id,user_id\n10,1\n",
+		})
+
+		var name string
+		require.NoError(t, db.QueryRow(
+			"SELECT u.name FROM orders o JOIN users u ON u.id = o.user_id WHERE o.id = 10").Scan(&name))
+		assert.Equal(t, "Alice", name)
+	})
+}
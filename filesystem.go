@@ -0,0 +1,128 @@
+package filesql
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// FileSystem is the file system that input files are read from and output files are
+// written to.
+//
+// By default, filesql uses the operating system file system. Inject another
+// implementation with DBBuilder.WithFileSystem and DumpOptions.WithFileSystem to
+// simulate slow disks, partial writes, or full disks in tests. An implementation
+// usually wraps the one returned by NewOSFileSystem and changes the behavior of
+// selected files.
+//
+// Paths are still resolved on the operating system: Build checks that added paths
+// exist and walks directories with the os package, and temporary files (see
+// WithTempDir) are created there too. The FileSystem opens the collected files and
+// creates, removes, and creates the directories of output files.
+type FileSystem interface {
+	// Open opens the named file for reading.
+	Open(name string) (fs.File, error)
+	// Create creates or truncates the named file for writing.
+	// Errors returned by Write or Close of the file fail the export.
+	Create(name string) (io.WriteCloser, error)
+	// CreateNew creates the named file for writing. It fails with an error wrapping
+	// fs.ErrExist if the file already exists, which must be checked atomically.
+	CreateNew(name string) (io.WriteCloser, error)
+	// MkdirAll creates a directory, along with any necessary parents.
+	MkdirAll(path string, perm fs.FileMode) error
+	// Rename renames (moves) oldpath to newpath, replacing newpath if it exists.
+	Rename(oldpath, newpath string) error
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+}
+
+// NewOSFileSystem returns the FileSystem backed by the operating system, which is the
+// default of the loader and DumpDatabase.
+func NewOSFileSystem() FileSystem {
+	return osFileSystem{}
+}
+
+// osFileSystem implements FileSystem with the os package
+type osFileSystem struct{}
+
+// Open implements FileSystem
+func (osFileSystem) Open(name string) (fs.File, error) {
+	return os.Open(name) //nolint:gosec // File path is validated and comes from user input
+}
+
+// Create implements FileSystem
+func (osFileSystem) Create(name string) (io.WriteCloser, error) {
+	return os.Create(name) //nolint:gosec // Output path is constructed from validated directory and table name
+}
+
+// CreateNew implements FileSystem
+func (osFileSystem) CreateNew(name string) (io.WriteCloser, error) {
+	return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) //nolint:gosec // Output path is constructed from validated directory
+}
+
+// MkdirAll implements FileSystem
+func (osFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// Rename implements FileSystem
+func (osFileSystem) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// Remove implements FileSystem
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// WithFileSystem sets the file system that input files are read from.
+//
+// Files added with AddPath and AddPaths are opened through fsys instead of the os
+// package, so tests can inject slow reads or read errors deterministically. When
+// auto-save is enabled and its DumpOptions have no file system, auto-save writes
+// through fsys as well. See FileSystem for the operations that still use the
+// operating system.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("data.csv").
+//		WithFileSystem(slowFileSystem{FileSystem: filesql.NewOSFileSystem()})
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithFileSystem(fsys FileSystem) *DBBuilder {
+	b.streamProcessor.inputFS = fsys
+	return b
+}
+
+// WithFileSystem sets the file system that output files are written to.
+//
+// DumpDatabase creates the output directory and the output files, and renames and
+// removes files, through fsys. An error returned by a write or by closing a file
+// fails the export of the table, so partial writes can be simulated. Output paths are still checked against OutputRoot on the operating
+// system. A nil file system restores the default.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().WithFileSystem(fullDisk)
+//	err := filesql.DumpDatabase(db, "./output", options)
+func (o DumpOptions) WithFileSystem(fsys FileSystem) DumpOptions {
+	o.FileSystem = fsys
+	return o
+}
+
+// fileSystem returns the file system of the options, defaulting to the operating system
+func (o DumpOptions) fileSystem() FileSystem {
+	if o.FileSystem == nil {
+		return NewOSFileSystem()
+	}
+	return o.FileSystem
+}
+
+// fileSystem returns the file system inputs are read from, defaulting to the operating system
+func (sp *streamProcessor) fileSystem() FileSystem {
+	if sp.inputFS == nil {
+		return NewOSFileSystem()
+	}
+	return sp.inputFS
+}
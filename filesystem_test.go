@@ -0,0 +1,278 @@
+package filesql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryFileSystem is a FileSystem that keeps created files in memory and opens
+// files from the operating system
+type memoryFileSystem struct {
+	FileSystem
+	mu    sync.Mutex
+	files map[string]*bytes.Buffer
+	dirs  []string
+}
+
+func newMemoryFileSystem() *memoryFileSystem {
+	return &memoryFileSystem{FileSystem: NewOSFileSystem(), files: make(map[string]*bytes.Buffer)}
+}
+
+func (m *memoryFileSystem) Create(name string) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	buf := &bytes.Buffer{}
+	m.files[name] = buf
+	return nopWriteCloser{buf}, nil
+}
+
+func (m *memoryFileSystem) CreateNew(name string) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	}
+	buf := &bytes.Buffer{}
+	m.files[name] = buf
+	return nopWriteCloser{buf}, nil
+}
+
+func (m *memoryFileSystem) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	buf, ok := m.files[oldpath]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: fs.ErrNotExist}
+	}
+	delete(m.files, oldpath)
+	m.files[newpath] = buf
+	return nil
+}
+
+func (m *memoryFileSystem) MkdirAll(path string, _ fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dirs = append(m.dirs, path)
+	return nil
+}
+
+func (m *memoryFileSystem) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, name)
+	return nil
+}
+
+func (m *memoryFileSystem) content(name string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	buf, ok := m.files[name]
+	if !ok {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// failingFileSystem is a FileSystem whose files accept limit bytes and then fail with writeErr
+type failingFileSystem struct {
+	FileSystem
+	limit    int
+	writeErr error
+	closeErr error
+}
+
+func (f failingFileSystem) Create(string) (io.WriteCloser, error) {
+	return &failingFile{remaining: f.limit, writeErr: f.writeErr, closeErr: f.closeErr}, nil
+}
+
+func (failingFileSystem) MkdirAll(string, fs.FileMode) error { return nil }
+
+type failingFile struct {
+	remaining int
+	writeErr  error
+	closeErr  error
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	if len(p) > f.remaining {
+		n := f.remaining
+		f.remaining = 0
+		return n, f.writeErr
+	}
+	f.remaining -= len(p)
+	return len(p), nil
+}
+
+func (f *failingFile) Close() error { return f.closeErr }
+
+// countingFileSystem counts the files opened through it
+type countingFileSystem struct {
+	FileSystem
+	mu     sync.Mutex
+	opened []string
+}
+
+func (c *countingFileSystem) Open(name string) (fs.File, error) {
+	c.mu.Lock()
+	c.opened = append(c.opened, filepath.Base(name))
+	c.mu.Unlock()
+	return c.FileSystem.Open(name)
+}
+
+// errorFileSystem fails to open every file
+type errorFileSystem struct{ FileSystem }
+
+func (errorFileSystem) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestDumpOptions_WithFileSystem(t *testing.T) {
+	t.Parallel()
+
+	newDB := func(t *testing.T) *sql.DB {
+		t.Helper()
+		return openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,Gina\n2,Yulia\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id\n1\n"), "orders", FileTypeCSV))
+	}
+
+	t.Run("files are written to the injected file system", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		outputDir := filepath.Join("virtual", "out")
+		options := NewDumpOptions().WithFileSystem(fsys)
+		require.NoError(t, DumpDatabase(newDB(t), outputDir, options))
+
+		assert.Equal(t, []string{outputDir}, fsys.dirs)
+		users, ok := fsys.content(filepath.Join(outputDir, "users.csv"))
+		require.True(t, ok)
+		assert.Equal(t, "id,name\n1,Gina\n2,Yulia\n", users)
+		_, ok = fsys.content(filepath.Join(outputDir, "orders.csv"))
+		assert.True(t, ok)
+		assert.NoDirExists(t, outputDir)
+	})
+
+	t.Run("every format is written to the injected file system", func(t *testing.T) {
+		t.Parallel()
+
+		for _, format := range []OutputFormat{OutputFormatTSV, OutputFormatLTSV, OutputFormatParquet, OutputFormatXLSX} {
+			fsys := newMemoryFileSystem()
+			options := NewDumpOptions().WithFormat(format).WithFileSystem(fsys)
+			require.NoError(t, DumpDatabase(newDB(t), "out", options), format.String())
+
+			content, ok := fsys.content(filepath.Join("out", "users"+format.Extension()))
+			require.True(t, ok, format.String())
+			assert.NotEmpty(t, content, format.String())
+		}
+	})
+
+	errDiskFull := errors.New("no space left on device")
+
+	failureTests := []struct {
+		name    string
+		options DumpOptions
+	}{
+		{
+			name:    "partial writes fail the dump",
+			options: NewDumpOptions().WithFileSystem(failingFileSystem{FileSystem: NewOSFileSystem(), limit: 4, writeErr: errDiskFull}),
+		},
+		{
+			name: "compressed partial writes fail the dump",
+			options: NewDumpOptions().
+				WithCompression(CompressionGZ).
+				WithFileSystem(failingFileSystem{FileSystem: NewOSFileSystem(), limit: 4, writeErr: errDiskFull}),
+		},
+		{
+			name: "close errors fail the dump",
+			options: NewDumpOptions().WithFileSystem(failingFileSystem{
+				FileSystem: NewOSFileSystem(),
+				limit:      1 << 20,
+				closeErr:   errDiskFull,
+			}),
+		},
+	}
+
+	for _, tt := range failureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := DumpDatabase(newDB(t), "out", tt.options)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, errDiskFull)
+			assert.Equal(t, ErrCodeDumpFailed, ErrorCodeOf(err))
+		})
+	}
+
+	t.Run("nil restores the operating system", func(t *testing.T) {
+		t.Parallel()
+
+		outputDir := t.TempDir()
+		options := NewDumpOptions().WithFileSystem(newMemoryFileSystem()).WithFileSystem(nil)
+		require.NoError(t, DumpDatabase(newDB(t), outputDir, options))
+		assert.FileExists(t, filepath.Join(outputDir, "users.csv"))
+	})
+}
+
+func TestDBBuilder_WithFileSystem(t *testing.T) {
+	t.Parallel()
+
+	t.Run("path inputs are opened through the file system", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := &countingFileSystem{FileSystem: NewOSFileSystem()}
+		db := openTestDB(t, NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			WithFileSystem(fsys))
+
+		assert.Equal(t, []string{"sample.csv"}, fsys.opened)
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM sample").Scan(&count))
+		assert.Positive(t, count)
+	})
+
+	t.Run("open errors fail the load", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := tryOpenTestDB(t, NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			WithFileSystem(errorFileSystem{NewOSFileSystem()}))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, fs.ErrPermission)
+	})
+
+	t.Run("auto-save writes through the file system", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "a", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithFileSystem(fsys).
+			Build(context.Background())
+		require.NoError(t, err)
+
+		db, err := validatedBuilder.Open(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		content, ok := fsys.content(filepath.Join("backup", "a.csv"))
+		require.True(t, ok)
+		assert.Equal(t, "id\n1\n", content)
+	})
+}
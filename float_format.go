@@ -0,0 +1,130 @@
+package filesql
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// WithFloatFormat sets the fmt format used to export floating-point values.
+//
+// By default, floating-point values are written in their shortest form, so a price
+// loaded as "25.50" is exported as "25.5". Use a format such as "%.2f" to keep a
+// fixed number of decimal places. The format applies to every REAL value of every
+// table unless WithColumnFloatFormat sets a format for the column. Integer and text
+// values are never changed.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().WithFloatFormat("%.2f")
+//	err := filesql.DumpDatabase(db, "./output", options)
+//
+// The format must contain exactly one verb that accepts a float64, such as %f, %e,
+// or %g; DumpDatabase returns an error with ErrCodeInvalidConfig otherwise.
+// An empty format restores the default.
+func (o DumpOptions) WithFloatFormat(format string) DumpOptions {
+	o.FloatFormat = format
+	return o
+}
+
+// WithColumnFloatFormat sets the fmt format used to export floating-point values
+// of the named column, overriding WithFloatFormat for that column.
+//
+// The column name applies to every table that has a column of that name and is
+// compared case-insensitively, like SQLite column names.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().
+//		WithFloatFormat("%.2f").
+//		WithColumnFloatFormat("exchange_rate", "%.6f")
+func (o DumpOptions) WithColumnFloatFormat(column, format string) DumpOptions {
+	// Copy the map so that options derived from the same base do not share it
+	formats := make(map[string]string, len(o.ColumnFloatFormats)+1)
+	maps.Copy(formats, o.ColumnFloatFormats)
+	formats[column] = format
+	o.ColumnFloatFormats = formats
+	return o
+}
+
+// validateFloatFormats returns an error if a float format of the options is invalid
+func (o DumpOptions) validateFloatFormats() error {
+	if err := validateFloatFormat(o.FloatFormat); err != nil {
+		return err
+	}
+	for column, format := range o.ColumnFloatFormats {
+		if err := validateFloatFormat(format); err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// validateFloatFormat returns an error if format is not empty and does not format
+// a single float64 cleanly
+func validateFloatFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	// fmt reports wrong verbs and missing or extra operands inline with "%!"
+	if strings.Contains(fmt.Sprintf(format, 1.5), "%!") {
+		return newCodedError(ErrCodeInvalidConfig, "invalid float format %q: it must contain a single floating-point verb such as %%.2f", format)
+	}
+	return nil
+}
+
+// valueFormatter converts scanned column values to the strings written on export.
+// The zero value formats every value with %v.
+type valueFormatter struct {
+	// floatFormats holds the float format of each column; empty means %v
+	floatFormats []string
+	// safeJSONIntegers writes integers beyond 2^53-1 as JSON strings
+	safeJSONIntegers bool
+	// quoteEmptyStrings writes empty strings of delimited files as quoted fields, so
+	// they differ from NULL (see NullConventionQuotedEmpty)
+	quoteEmptyStrings bool
+	// downcasts collects the values whose text is ambiguous or lossy; nil checks nothing
+	downcasts *downcastTracker
+}
+
+// newValueFormatter returns the value formatter of the columns for the options
+func newValueFormatter(columns []string, options DumpOptions) valueFormatter {
+	quoteEmptyStrings := options.NullConvention == NullConventionQuotedEmpty
+	if options.FloatFormat == "" && len(options.ColumnFloatFormats) == 0 {
+		return valueFormatter{safeJSONIntegers: options.SafeJSONIntegers, quoteEmptyStrings: quoteEmptyStrings}
+	}
+
+	floatFormats := make([]string, len(columns))
+	for i, column := range columns {
+		floatFormats[i] = options.FloatFormat
+		for name, format := range options.ColumnFloatFormats {
+			if strings.EqualFold(name, column) {
+				floatFormats[i] = format
+				break
+			}
+		}
+	}
+	return valueFormatter{floatFormats: floatFormats, safeJSONIntegers: options.SafeJSONIntegers, quoteEmptyStrings: quoteEmptyStrings}
+}
+
+// format returns the exported string of the value of the column at index i
+func (f valueFormatter) format(i int, value any) string {
+	text := f.text(i, value)
+	if f.downcasts != nil {
+		f.downcasts.check(i, value, text)
+	}
+	return text
+}
+
+// text formats the value of the column at index i
+func (f valueFormatter) text(i int, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		if i < len(f.floatFormats) && f.floatFormats[i] != "" {
+			return fmt.Sprintf(f.floatFormats[i], v)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,98 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOptions_WithFloatFormat(t *testing.T) {
+	t.Parallel()
+
+	writePrices := func(t *testing.T) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "prices.csv")
+		require.NoError(t, os.WriteFile(path, []byte("item,price,rate,qty\napple,25.50,1.5,3\npear,10.00,0.125,4\n"), 0o600))
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		options []DumpOptions
+		file    string
+		want    string
+	}{
+		{
+			name: "floats keep their shortest form by default",
+			file: "prices.csv",
+			want: "item,price,rate,qty\napple,25.5,1.5,3\npear,10,0.125,4\n",
+		},
+		{
+			name:    "float format applies to every float column and per-column formats override it",
+			options: []DumpOptions{NewDumpOptions().WithFloatFormat("%.2f").WithColumnFloatFormat("RATE", "%.4f")},
+			file:    "prices.csv",
+			want:    "item,price,rate,qty\napple,25.50,1.5000,3\npear,10.00,0.1250,4\n",
+		},
+		{
+			name:    "float format applies to LTSV output",
+			options: []DumpOptions{NewDumpOptions().WithFormat(OutputFormatLTSV).WithColumnFloatFormat("price", "%.2f")},
+			file:    "prices.ltsv",
+			want:    "item:apple\tprice:25.50\trate:1.5\tqty:3\nitem:pear\tprice:10.00\trate:0.125\tqty:4\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := openTestDB(t, NewBuilder().AddPath(writePrices(t)))
+			outputDir := t.TempDir()
+			require.NoError(t, DumpDatabase(db, outputDir, tt.options...))
+
+			data, err := os.ReadFile(filepath.Join(outputDir, tt.file))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+
+	t.Run("invalid format is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddPath(writePrices(t)))
+		outputDir := filepath.Join(t.TempDir(), "out")
+		for _, options := range []DumpOptions{
+			NewDumpOptions().WithFloatFormat("%d"),
+			NewDumpOptions().WithFloatFormat("%.2f %.2f"),
+			NewDumpOptions().WithColumnFloatFormat("price", "price"),
+		} {
+			err := DumpDatabase(db, outputDir, options)
+			require.Error(t, err)
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+		}
+		assert.NoDirExists(t, outputDir)
+	})
+
+	t.Run("invalid auto-save format is rejected by Build", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewBuilder().
+			AddPath(writePrices(t)).
+			EnableAutoSave(t.TempDir(), NewDumpOptions().WithFloatFormat("%s")).
+			Build(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+
+	t.Run("derived options do not share column formats", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewDumpOptions().WithColumnFloatFormat("price", "%.2f")
+		derived := base.WithColumnFloatFormat("rate", "%.4f")
+		assert.Len(t, base.ColumnFloatFormats, 1)
+		assert.Len(t, derived.ColumnFloatFormats, 2)
+	})
+}
@@ -0,0 +1,482 @@
+package filesql
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// gcsURIScheme is the prefix of Cloud Storage URIs accepted by AddPath
+	gcsURIScheme = "gs://"
+	// gcsDefaultEndpoint is the endpoint of the Cloud Storage JSON API
+	gcsDefaultEndpoint = "https://storage.googleapis.com"
+	// gcsReadOnlyScope is the OAuth 2.0 scope requested for service accounts
+	gcsReadOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+	// gcsDefaultTokenURI is the token endpoint of Google's OAuth 2.0 server
+	gcsDefaultTokenURI = "https://oauth2.googleapis.com/token"
+	// gcsMetadataTimeout bounds the token request to the metadata server, which does
+	// not exist outside Google Cloud
+	gcsMetadataTimeout = 3 * time.Second
+)
+
+// GCSConfig configures how objects added with AddGCS or as gs:// paths are downloaded.
+// Empty fields fall back to Application Default Credentials.
+type GCSConfig struct {
+	// Endpoint is the URL of the Cloud Storage JSON API. Defaults to
+	// STORAGE_EMULATOR_HOST, which also disables authentication, then to
+	// "https://storage.googleapis.com".
+	Endpoint string
+	// CredentialsFile is a service account key or authorized user credentials file in
+	// JSON. Defaults to GOOGLE_APPLICATION_CREDENTIALS, then to the file written by
+	// "gcloud auth application-default login", then to the service account of the
+	// metadata server on Google Cloud. Without any credentials, objects are requested
+	// anonymously, which works for public buckets.
+	CredentialsFile string
+	// AccessToken is an OAuth 2.0 access token sent as is instead of credentials.
+	AccessToken string
+	// Anonymous requests objects without credentials.
+	Anonymous bool
+	// HTTPClient sends the requests; nil uses http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// WithGCSConfig sets how Cloud Storage objects are downloaded (see AddGCS).
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("gs://analytics/events/2024-06.csv.gz").
+//		WithGCSConfig(filesql.GCSConfig{CredentialsFile: "reader-key.json"})
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithGCSConfig(config GCSConfig) *DBBuilder {
+	b.gcsConfig = config
+	return b
+}
+
+// AddGCS adds an object of Google Cloud Storage, or every object under a prefix.
+//
+// The object is downloaded while the database is opened and streamed through the
+// same decompression and parsing as local files, so large CSV, TSV, and LTSV objects
+// are never held in memory as a whole. The object name determines the table name and
+// file type like a file path does: "events/2024-06.csv.gz" becomes the table "2024-06".
+// Paths passed to AddPath, AddPaths, Open, and OpenContext can also be Cloud Storage
+// URIs of the form "gs://bucket/object".
+//
+// An object name that is empty or ends with "/" is a prefix, like a directory: Build
+// lists the objects under it and adds every object of a supported file type. Build
+// fails with an error with ErrCodePathNotFound if there is none.
+//
+// Credentials are set with WithGCSConfig or found as Application Default Credentials.
+// Open fails with an error with ErrCodePathNotFound if the object does not exist and
+// with ErrCodePermissionDenied if access is denied.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddGCS("analytics", "users.csv").
+//		AddGCS("analytics", "events/2024/") // every file under the prefix
+//
+// Returns self for chaining.
+func (b *DBBuilder) AddGCS(bucket, object string) *DBBuilder {
+	b.remotes = append(b.remotes, newGCSInput(bucket, object))
+	b.inputOrder = append(b.inputOrder, inputRemote)
+	return b
+}
+
+// isGCSURI reports whether path is a Cloud Storage URI
+func isGCSURI(path string) bool {
+	return strings.HasPrefix(path, gcsURIScheme)
+}
+
+// newGCSInputFromURI returns the remote input of a gs://bucket/object URI
+func newGCSInputFromURI(uri string) remoteInput {
+	bucket, object, _ := strings.Cut(strings.TrimPrefix(uri, gcsURIScheme), "/")
+	input := newGCSInput(bucket, object)
+	input.uri = uri
+	return input
+}
+
+// newGCSInput returns the remote input of a Cloud Storage object or prefix
+func newGCSInput(bucket, object string) remoteInput {
+	uri := gcsURIScheme + bucket + "/" + object
+	input := remoteInput{uri: uri, name: object, gcs: &gcsObject{bucket: bucket, name: object}}
+	if bucket == "" {
+		input.err = newCodedError(ErrCodeInvalidConfig, "invalid Cloud Storage object %s: a bucket is required", uri)
+	}
+	return input
+}
+
+// gcsObject is an object in a bucket, or a prefix of objects
+type gcsObject struct {
+	bucket string
+	name   string
+}
+
+// isPrefix reports whether the object names a prefix of objects
+func (o gcsObject) isPrefix() bool {
+	return o.name == "" || strings.HasSuffix(o.name, "/")
+}
+
+// get starts downloading the object
+func (o gcsObject) get(ctx context.Context, client *gcsClient) (io.ReadCloser, error) {
+	endpoint := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", client.endpoint, url.PathEscape(o.bucket), url.PathEscape(o.name))
+	response, err := client.do(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// list returns the names of the objects under the prefix
+func (o gcsObject) list(ctx context.Context, client *gcsClient) ([]string, error) {
+	var names []string
+	pageToken := ""
+	for {
+		query := url.Values{"prefix": {o.name}, "fields": {"items(name),nextPageToken"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		endpoint := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", client.endpoint, url.PathEscape(o.bucket), query.Encode())
+		response, err := client.do(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(response.Body).Decode(&page)
+		_ = response.Body.Close() // The page has been read
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object list: %w", err)
+		}
+		for _, item := range page.Items {
+			names = append(names, item.Name)
+		}
+		if page.NextPageToken == "" {
+			return names, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// expandGCSPrefixes replaces the prefix inputs among remotes with inputs of the objects under
+// them. origins holds the index of the input in remotes each returned input comes from.
+func (b *DBBuilder) expandGCSPrefixes(ctx context.Context, remotes []remoteInput) (objects []remoteInput, origins []int, err error) {
+	for i, remote := range remotes {
+		if remote.err != nil || remote.gcs == nil || !remote.gcs.isPrefix() {
+			objects = append(objects, remote)
+			origins = append(origins, i)
+			continue
+		}
+
+		names, err := remote.gcs.list(ctx, b.gcsClient())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list %s: %w", remote.uri, err)
+		}
+		found := false
+		for _, name := range names {
+			if strings.HasSuffix(name, "/") || !isSupportedFile(name) {
+				continue
+			}
+			object := newGCSInput(remote.gcs.bucket, name)
+			object.options = remote.options
+			objects = append(objects, object)
+			origins = append(origins, i)
+			found = true
+		}
+		if !found {
+			return nil, nil, newCodedError(ErrCodePathNotFound, "no supported files found under %s", remote.uri)
+		}
+	}
+	return objects, origins, nil
+}
+
+// gcsClient returns the client that downloads the Cloud Storage inputs of the
+// builder, creating it on first use so all inputs share one access token
+func (b *DBBuilder) gcsClient() *gcsClient {
+	if b.gcs == nil {
+		b.gcs = newGCSClient(b.gcsConfig)
+	}
+	return b.gcs
+}
+
+// gcsClient sends authenticated requests to the Cloud Storage JSON API
+type gcsClient struct {
+	config   GCSConfig
+	endpoint string
+	client   *http.Client
+
+	// mu guards the cached token; an empty token with an expiry means anonymous requests
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// newGCSClient returns a client configured by config and the environment
+func newGCSClient(config GCSConfig) *gcsClient {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+			endpoint = host
+			if !strings.Contains(host, "://") {
+				endpoint = "http://" + host
+			}
+			config.Anonymous = config.Anonymous || config.AccessToken == ""
+		}
+	}
+	if endpoint == "" {
+		endpoint = gcsDefaultEndpoint
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &gcsClient{config: config, endpoint: strings.TrimSuffix(endpoint, "/"), client: client}
+}
+
+// do sends a GET request with the access token and checks the response
+func (c *gcsClient) do(ctx context.Context, endpoint string) (*http.Response, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// accessToken returns the access token requests are sent with; empty means anonymous
+func (c *gcsClient) accessToken(ctx context.Context) (string, error) {
+	if c.config.AccessToken != "" || c.config.Anonymous {
+		return c.config.AccessToken, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Renew tokens a minute early, so they do not expire during a request
+	if !c.expires.IsZero() && time.Now().Add(time.Minute).Before(c.expires) {
+		return c.token, nil
+	}
+	token, lifetime, err := c.fetchToken(ctx)
+	if err != nil {
+		return "", newCodedError(ErrCodePermissionDenied, "failed to get Cloud Storage credentials: %w", err)
+	}
+	c.token, c.expires = token, time.Now().Add(lifetime)
+	return c.token, nil
+}
+
+// fetchToken gets an access token with Application Default Credentials
+func (c *gcsClient) fetchToken(ctx context.Context) (string, time.Duration, error) {
+	path := c.config.CredentialsFile
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path == "" {
+		if wellKnown := gcloudCredentialsPath(); wellKnown != "" {
+			if _, err := os.Stat(wellKnown); err == nil {
+				path = wellKnown
+			}
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path) //nolint:gosec // Credentials file path is provided by the caller or the environment
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		return c.tokenFromCredentials(ctx, data)
+	}
+	return c.tokenFromMetadataServer(ctx)
+}
+
+// gcloudCredentialsPath returns the path of the credentials written by
+// "gcloud auth application-default login"
+func gcloudCredentialsPath() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return filepath.Join(dir, "application_default_credentials.json")
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// gcsCredentials is a service account key or authorized user credentials file
+type gcsCredentials struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// tokenFromCredentials exchanges the credentials of a credentials file for an access token
+func (c *gcsClient) tokenFromCredentials(ctx context.Context, data []byte) (string, time.Duration, error) {
+	var credentials gcsCredentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return "", 0, fmt.Errorf("invalid credentials file: %w", err)
+	}
+	tokenURI := credentials.TokenURI
+	if tokenURI == "" {
+		tokenURI = gcsDefaultTokenURI
+	}
+
+	switch credentials.Type {
+	case "service_account":
+		assertion, err := signServiceAccountJWT(credentials, tokenURI, time.Now())
+		if err != nil {
+			return "", 0, err
+		}
+		return c.requestToken(ctx, tokenURI, url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		})
+	case "authorized_user":
+		return c.requestToken(ctx, tokenURI, url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {credentials.ClientID},
+			"client_secret": {credentials.ClientSecret},
+			"refresh_token": {credentials.RefreshToken},
+		})
+	default:
+		return "", 0, fmt.Errorf("unsupported credentials type %q", credentials.Type)
+	}
+}
+
+// requestToken posts form to an OAuth 2.0 token endpoint
+func (c *gcsClient) requestToken(ctx context.Context, tokenURI string, form url.Values) (string, time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.decodeToken(request)
+}
+
+// tokenFromMetadataServer gets an access token of the service account of the
+// Google Cloud environment. Outside Google Cloud, there is no token.
+func (c *gcsClient) tokenFromMetadataServer(ctx context.Context) (string, time.Duration, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	ctx, cancel := context.WithTimeout(ctx, gcsMetadataTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create metadata request: %w", err)
+	}
+	request.Header.Set("Metadata-Flavor", "Google")
+
+	token, lifetime, err := c.decodeToken(request)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// No metadata server: request public objects anonymously
+		return "", time.Hour, nil
+	}
+	return token, lifetime, err
+}
+
+// decodeToken sends a token request and decodes the token of the response
+func (c *gcsClient) decodeToken(request *http.Request) (string, time.Duration, error) {
+	response, err := c.client.Do(request)
+	if err != nil {
+		return "", 0, err
+	}
+	defer response.Body.Close()
+	if err := checkResponse(response); err != nil {
+		return "", 0, err
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", 0, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", 0, errors.New("token response has no access token")
+	}
+	return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
+
+// signServiceAccountJWT returns the JWT a service account exchanges for an access token
+func signServiceAccountJWT(credentials gcsCredentials, audience string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(credentials.PrivateKey))
+	if block == nil {
+		return "", errors.New("invalid service account private key")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if pkcs8Err != nil {
+			return "", fmt.Errorf("invalid service account private key: %w", pkcs8Err)
+		}
+		var ok bool
+		if key, ok = parsed.(*rsa.PrivateKey); !ok {
+			return "", errors.New("service account private key is not an RSA key")
+		}
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   credentials.ClientEmail,
+		"scope": gcsReadOnlyScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign service account assertion: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,323 @@
+package filesql
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGCS serves objects of buckets through the Cloud Storage JSON API
+type fakeGCS struct {
+	*httptest.Server
+	// objects maps "bucket/object" to the content of the object
+	objects map[string]string
+	// token is the access token requests must be sent with; empty accepts any request
+	token string
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// newFakeGCS starts a fake Cloud Storage server that requires token
+func newFakeGCS(t *testing.T, token string, objects map[string]string) *fakeGCS {
+	t.Helper()
+
+	server := &fakeGCS{objects: objects, token: token}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serve))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *fakeGCS) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	s.mu.Unlock()
+
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		http.Error(w, `{"error": {"message": "Anonymous caller does not have access"}}`, http.StatusUnauthorized)
+		return
+	}
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/storage/v1/b/"), "/")
+	bucket, _ := url.PathUnescape(segments[0])
+	if strings.HasPrefix(bucket, "private") {
+		http.Error(w, `{"error": {"message": "Forbidden"}}`, http.StatusForbidden)
+		return
+	}
+
+	if len(segments) == 2 && segments[1] == "o" {
+		s.list(w, r, bucket)
+		return
+	}
+	object, _ := url.PathUnescape(strings.Join(segments[2:], "/"))
+	content, ok := s.objects[bucket+"/"+object]
+	if !ok || r.URL.Query().Get("alt") != "media" {
+		http.Error(w, `{"error": {"message": "No such object"}}`, http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write([]byte(content))
+}
+
+// list serves the objects of a bucket with a prefix, two per page
+func (s *fakeGCS) list(w http.ResponseWriter, r *http.Request, bucket string) {
+	var names []string
+	for key := range s.objects {
+		if name, ok := strings.CutPrefix(key, bucket+"/"); ok && strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+	end := min(start+2, len(names))
+	page := map[string]any{"items": []map[string]string{}}
+	for _, name := range names[start:end] {
+		page["items"] = append(page["items"].([]map[string]string), map[string]string{"name": name})
+	}
+	if end < len(names) {
+		page["nextPageToken"] = strconv.Itoa(end)
+	}
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func TestGCS(t *testing.T) {
+	t.Parallel()
+
+	open := func(t *testing.T, builder *DBBuilder) (*DBBuilder, error) {
+		t.Helper()
+		return builder.Build(context.Background())
+	}
+
+	t.Run("objects are loaded with AddGCS and gs URIs", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFakeGCS(t, "token-1", map[string]string{
+			"analytics/users.csv":          "id,name\n1,Alice\n2,Bob\n",
+			"analytics/events/2024-06.tsv": "id\tkind\n1\tclick\n2\tview\n3\tclick\n",
+		})
+		validatedBuilder, err := open(t, NewBuilder().
+			AddGCS("analytics", "users.csv").
+			AddPath("gs://analytics/events/2024-06.tsv").
+			WithGCSConfig(GCSConfig{Endpoint: server.URL, AccessToken: "token-1"}))
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(context.Background())
+		require.NoError(t, err)
+		defer db.Close()
+
+		var users, events int
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			`SELECT (SELECT COUNT(*) FROM users), (SELECT COUNT(*) FROM "2024-06")`).Scan(&users, &events))
+		assert.Equal(t, 2, users)
+		assert.Equal(t, 3, events)
+
+		// Slashes of object names are escaped in the request path
+		assert.Contains(t, server.requests, "/storage/v1/b/analytics/o/events%2F2024-06.tsv?alt=media")
+	})
+
+	t.Run("prefixes load every supported object under them", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFakeGCS(t, "", map[string]string{
+			"lake/exports/":              "",
+			"lake/exports/a.csv":         "id\n1\n",
+			"lake/exports/b.csv":         "id\n1\n2\n",
+			"lake/exports/readme.txt":    "not data",
+			"lake/exports/2024/c.ltsv":   "id:1\nid:2\nid:3\n",
+			"lake/other/ignored.csv":     "id\n1\n",
+			"lake/exports-old/stale.csv": "id\n1\n",
+			"lake/exports/2024/d.jsonl":  `{"id": 1}` + "\n",
+		})
+		validatedBuilder, err := open(t, NewBuilder().
+			AddPath("gs://lake/exports/").
+			WithGCSConfig(GCSConfig{Endpoint: server.URL, Anonymous: true}))
+		require.NoError(t, err)
+
+		db, err := validatedBuilder.Open(context.Background())
+		require.NoError(t, err)
+		defer db.Close()
+		tables, err := db.QueryContext(context.Background(), "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE '_filesql_%' ORDER BY name")
+		require.NoError(t, err)
+		defer tables.Close()
+		var names []string
+		for tables.Next() {
+			var name string
+			require.NoError(t, tables.Scan(&name))
+			names = append(names, name)
+		}
+		require.NoError(t, tables.Err())
+		assert.Equal(t, []string{"a", "b", "c", "d"}, names)
+	})
+
+	t.Run("prefixes without supported objects fail the build", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFakeGCS(t, "", map[string]string{"lake/docs/readme.txt": "text"})
+		_, err := open(t, NewBuilder().AddGCS("lake", "docs/").WithGCSConfig(GCSConfig{Endpoint: server.URL, Anonymous: true}))
+		assert.Equal(t, ErrCodePathNotFound, ErrorCodeOf(err))
+	})
+
+	t.Run("failed downloads are reported with error codes", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFakeGCS(t, "", map[string]string{})
+		config := GCSConfig{Endpoint: server.URL, Anonymous: true}
+		for path, code := range map[string]ErrorCode{
+			"gs://analytics/missing.csv": ErrCodePathNotFound,
+			"gs://private/users.csv":     ErrCodePermissionDenied,
+		} {
+			validatedBuilder, err := open(t, NewBuilder().AddPath(path).WithGCSConfig(config))
+			require.NoError(t, err)
+			_, err = validatedBuilder.Open(context.Background())
+			assert.Equal(t, code, ErrorCodeOf(err), path)
+		}
+
+		_, err := open(t, NewBuilder().AddPath("gs:///users.csv"))
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
+
+func TestGCSCredentials(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// newTokenServer exchanges service account assertions signed with key and refresh
+	// tokens for the access token "token-2"
+	newTokenServer := func(t *testing.T) *httptest.Server {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			switch r.PostForm.Get("grant_type") {
+			case "urn:ietf:params:oauth:grant-type:jwt-bearer":
+				parts := strings.Split(r.PostForm.Get("assertion"), ".")
+				require.Len(t, parts, 3)
+				signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+				require.NoError(t, err)
+				digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+				if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+					http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+					return
+				}
+				claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+				require.NoError(t, err)
+				assert.Contains(t, string(claims), `"iss":"reader@project.iam.gserviceaccount.com"`)
+				assert.Contains(t, string(claims), "devstorage.read_only")
+			case "refresh_token":
+				if r.PostForm.Get("refresh_token") != "refresh-1" {
+					http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+					return
+				}
+			default:
+				http.Error(w, `{"error": "unsupported_grant_type"}`, http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token": "token-2", "expires_in": 3600, "token_type": "Bearer"}`))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	writeCredentials := func(t *testing.T, credentials map[string]string) string {
+		t.Helper()
+		data, err := json.Marshal(credentials)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "credentials.json")
+		require.NoError(t, os.WriteFile(path, data, 0600))
+		return path
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+
+	tests := []struct {
+		name        string
+		credentials func(tokenURI string) map[string]string
+	}{
+		{
+			name: "service account",
+			credentials: func(tokenURI string) map[string]string {
+				return map[string]string{
+					"type":         "service_account",
+					"client_email": "reader@project.iam.gserviceaccount.com",
+					"private_key":  privateKey,
+					"token_uri":    tokenURI,
+				}
+			},
+		},
+		{
+			name: "authorized user",
+			credentials: func(tokenURI string) map[string]string {
+				return map[string]string{
+					"type":          "authorized_user",
+					"client_id":     "client",
+					"client_secret": "secret",
+					"refresh_token": "refresh-1",
+					"token_uri":     tokenURI,
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := newFakeGCS(t, "token-2", map[string]string{
+				"analytics/a.csv": "id\n1\n",
+				"analytics/b.csv": "id\n2\n",
+			})
+			path := writeCredentials(t, tt.credentials(newTokenServer(t).URL))
+
+			ctx := context.Background()
+			db := openTestDB(t, NewBuilder().
+				AddGCS("analytics", "").
+				WithGCSConfig(GCSConfig{Endpoint: storage.URL, CredentialsFile: path}))
+
+			var total int
+			require.NoError(t, db.QueryRowContext(ctx, "SELECT (SELECT id FROM a) + (SELECT id FROM b)").Scan(&total))
+			assert.Equal(t, 3, total)
+		})
+	}
+
+	t.Run("invalid credentials deny access", func(t *testing.T) {
+		t.Parallel()
+
+		storage := newFakeGCS(t, "token-2", map[string]string{"analytics/a.csv": "id\n1\n"})
+		path := writeCredentials(t, map[string]string{"type": "external_account"})
+		_, err := NewBuilder().
+			AddGCS("analytics", "").
+			WithGCSConfig(GCSConfig{Endpoint: storage.URL, CredentialsFile: path}).
+			Build(context.Background())
+		assert.Equal(t, ErrCodePermissionDenied, ErrorCodeOf(err))
+	})
+
+	t.Run("credential errors keep their cause", func(t *testing.T) {
+		t.Parallel()
+
+		storage := newFakeGCS(t, "token-2", map[string]string{"analytics/a.csv": "id\n1\n"})
+		_, err := NewBuilder().
+			AddGCS("analytics", "").
+			WithGCSConfig(GCSConfig{Endpoint: storage.URL, CredentialsFile: filepath.Join(t.TempDir(), "missing.json")}).
+			Build(context.Background())
+		assert.Equal(t, ErrCodePermissionDenied, ErrorCodeOf(err))
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
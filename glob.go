@@ -0,0 +1,144 @@
+package filesql
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// AddGlob adds the supported files that match a glob pattern.
+//
+// Patterns use the syntax of path.Match in each path element, with slashes as
+// separators on every platform, and "**" as a whole element matches any number of
+// directories, including none:
+//
+//   - "logs/2024-*.csv" matches the CSV files in logs whose name starts with 2024-
+//   - "data/**/*.tsv.gz" matches the gzip-compressed TSV files in data and all its subdirectories
+//   - "exports/*/users.csv" matches users.csv in each direct subdirectory of exports
+//
+// Only files with a supported extension are loaded, in lexical order. Build fails
+// with ErrCodePathNotFound if no file matches, and with ErrCodeInvalidConfig if the
+// pattern is malformed.
+//
+// AddPath, Open, and the paths of configuration files accept patterns too. A file
+// whose name is the pattern itself, such as "report[1].csv", is loaded as it is. Use
+// AddPathWithOptions to set the options of every matched file.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddGlob("logs/2024-*.csv").
+//		AddGlob("data/**/*.tsv.gz")
+//
+// Returns self for chaining.
+func (b *DBBuilder) AddGlob(pattern string) *DBBuilder {
+	b.paths = append(b.paths, pattern)
+	b.inputOrder = append(b.inputOrder, inputPath)
+	return b
+}
+
+// isGlobPattern reports whether p contains glob metacharacters
+func isGlobPattern(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// collectFilesFromGlob collects the supported files that match pattern
+func (fp *fileProcessor) collectFilesFromGlob(pattern string, origin int, collected *collectedFiles) error {
+	matches, err := expandGlob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if err := collected.add(match, origin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandGlob returns the supported files that match pattern, in lexical order
+func expandGlob(pattern string) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, newCodedError(ErrCodeInvalidConfig, "invalid glob pattern %q: %v", pattern, err)
+		}
+	}
+
+	// The walk starts at the longest leading directory without metacharacters
+	fixed := 0
+	for fixed < len(segments) && !isGlobPattern(segments[fixed]) {
+		fixed++
+	}
+	root := "."
+	if fixed > 0 {
+		root = filepath.FromSlash(strings.Join(segments[:fixed], "/"))
+		if root == "" {
+			root = string(filepath.Separator)
+		}
+	}
+	rest := segments[fixed:]
+	recursive := false
+	for _, segment := range rest {
+		recursive = recursive || segment == "**"
+	}
+
+	var matches []string
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		names := strings.Split(filepath.ToSlash(rel), "/")
+		if d.IsDir() {
+			if !recursive && len(names) >= len(rest) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matchGlobSegments(rest, names) && isSupportedFile(filePath) {
+			matches = append(matches, filePath)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, newCodedError(ErrCodePathNotFound, "no files match pattern %s", pattern)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand pattern %s: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, newCodedError(ErrCodePathNotFound, "no files match pattern %s", pattern)
+	}
+	return matches, nil
+}
+
+// matchGlobSegments reports whether the path elements names match the pattern
+// elements, where a "**" element matches any number of names
+func matchGlobSegments(pattern, names []string) bool {
+	if len(pattern) == 0 {
+		return len(names) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(names); i++ {
+			if matchGlobSegments(pattern[1:], names[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(names) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], names[0])
+	return ok && matchGlobSegments(pattern[1:], names[1:])
+}
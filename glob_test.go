@@ -0,0 +1,148 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_AddGlob(t *testing.T) {
+	t.Parallel()
+
+	newDir := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		files := map[string]string{
+			"2024-01.csv":                         "id\n1\n",
+			"2024-02.csv":                         "id\n2\n",
+			"2023-12.csv":                         "id\n3\n",
+			"notes.txt":                           "not loaded",
+			filepath.Join("data", "users.tsv.gz"): "id\n4\n",
+			filepath.Join("data", "a", "orders.tsv.gz"):     "id\n5\n",
+			filepath.Join("data", "a", "b", "items.tsv.gz"): "id\n6\n",
+			filepath.Join("data", "a", "stock.csv"):         "id\n7\n",
+		}
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+			if strings.HasSuffix(name, ".gz") {
+				content = string(gzipData(t, content))
+			}
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+		return dir
+	}
+	tablesOf := func(t *testing.T, builder *DBBuilder) []string {
+		t.Helper()
+		db := openTestDB(t, builder)
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		return tables
+	}
+
+	t.Run("patterns select files by name", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newDir(t)
+		assert.ElementsMatch(t, []string{"2024-01", "2024-02"},
+			tablesOf(t, NewBuilder().AddGlob(filepath.Join(dir, "2024-*.csv"))))
+	})
+
+	t.Run("double stars match any number of directories", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newDir(t)
+		assert.ElementsMatch(t, []string{"users", "orders", "items"},
+			tablesOf(t, NewBuilder().AddGlob(filepath.Join(dir, "data", "**", "*.tsv.gz"))))
+	})
+
+	t.Run("single stars match one directory", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newDir(t)
+		assert.ElementsMatch(t, []string{"orders", "stock"},
+			tablesOf(t, NewBuilder().AddGlob(filepath.Join(dir, "data", "*", "*"))))
+	})
+
+	t.Run("Open and AddPath accept patterns", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newDir(t)
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join(dir, "*.csv")))
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"2023-12", "2024-01", "2024-02"}, tables)
+
+		assert.ElementsMatch(t, []string{"2023-12"}, tablesOf(t, NewBuilder().AddPath(filepath.Join(dir, "2023-*"))))
+	})
+
+	t.Run("files named like patterns are loaded as they are", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "report[1].csv")
+		require.NoError(t, os.WriteFile(path, []byte("id\n1\n"), 0600))
+		assert.Equal(t, []string{"report[1]"}, tablesOf(t, NewBuilder().AddPath(path)))
+	})
+
+	t.Run("options apply to every matched file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.csv"), []byte("1;x\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b.csv"), []byte("2;y\n"), 0600))
+		db := openTestDB(t, NewBuilder().
+			AddPathWithOptions(filepath.Join(dir, "*.csv"), WithDelimiter(';'), WithColumnNames("id", "name")))
+
+		var name string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT name FROM b WHERE id = 2").Scan(&name))
+		assert.Equal(t, "y", name)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newDir(t)
+		_, err := NewBuilder().AddGlob(filepath.Join(dir, "*.parquet")).Build(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, ErrCodePathNotFound, ErrorCodeOf(err))
+		assert.Contains(t, err.Error(), "no files match pattern")
+
+		_, err = NewBuilder().AddGlob(filepath.Join(dir, "missing", "*.csv")).Build(context.Background())
+		assert.Equal(t, ErrCodePathNotFound, ErrorCodeOf(err))
+
+		_, err = NewBuilder().AddGlob(filepath.Join(dir, "[.csv")).Build(context.Background())
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
+
+func TestMatchGlobSegments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.csv", name: "a.csv", want: true},
+		{pattern: "*.csv", name: "x/a.csv", want: false},
+		{pattern: "**/*.csv", name: "a.csv", want: true},
+		{pattern: "**/*.csv", name: "x/y/a.csv", want: true},
+		{pattern: "x/**/a.csv", name: "x/a.csv", want: true},
+		{pattern: "x/**/a.csv", name: "y/a.csv", want: false},
+		{pattern: "**", name: "x/y/a.csv", want: true},
+		{pattern: "x/?.csv", name: "x/ab.csv", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, matchGlobSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/")))
+		})
+	}
+}
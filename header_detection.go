@@ -0,0 +1,68 @@
+package filesql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithHeaderDetection decides for each CSV and TSV input whether its first row is a
+// header or already data.
+//
+// By default, the first row of CSV and TSV data is always the header. Exports of some
+// systems have no header row, so their first record becomes the column names and is
+// missing from the table. With header detection, the first two rows are sampled and
+// the first row is taken as the header only if its values are non-empty, unique, and
+// neither numbers nor dates, and none of them repeats the value below it in the
+// second row. Otherwise the first row is loaded as data and the columns are named
+// column1, column2, and so on.
+//
+// The decision for every input is recorded as a load warning (see GetLoadWarnings),
+// so unattended loads of many heterogeneous files can be audited afterwards. Sidecar
+// indexes (see WithSidecarIndex) are not used while header detection is enabled.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("exports/"). // some files have a header row, some do not
+//		WithHeaderDetection()
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithHeaderDetection() *DBBuilder {
+	b.streamProcessor.detectHeader = true
+	return b
+}
+
+// looksLikeHeader reports whether first, the first row of delimited data, is a header.
+// second is the row after it, or nil if there is none.
+func looksLikeHeader(first, second []string) bool {
+	seen := make(map[string]bool, len(first))
+	for i, value := range first {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] || classifyValue(value) != columnTypeText {
+			return false
+		}
+		seen[value] = true
+		if i < len(second) && strings.TrimSpace(second[i]) == value {
+			return false
+		}
+	}
+	return true
+}
+
+// generatedColumnNames returns the names of the columns of data without a header
+func generatedColumnNames(count int) []string {
+	names := make([]string, count)
+	for i := range names {
+		names[i] = fmt.Sprintf("column%d", i+1)
+	}
+	return names
+}
+
+// headerDecision returns the load warning recording whether the first row of an input
+// with columns columns was taken as the header
+func headerDecision(isHeader bool, columns int) string {
+	if isHeader {
+		return "header detection: first row is the header"
+	}
+	return fmt.Sprintf("header detection: first row is data, columns are named column1 to column%d", columns)
+}
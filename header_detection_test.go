@@ -0,0 +1,99 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLooksLikeHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		first  []string
+		second []string
+		want   bool
+	}{
+		{name: "names above values", first: []string{"id", "name"}, second: []string{"1", "Gina"}, want: true},
+		{name: "text above text", first: []string{"name", "city"}, second: []string{"Gina", "Osaka"}, want: true},
+		{name: "single row of names", first: []string{"id", "name"}, want: true},
+		{name: "numbers", first: []string{"1", "Gina"}, second: []string{"2", "Yuki"}, want: false},
+		{name: "dates", first: []string{"2024-06-01", "open"}, second: []string{"2024-06-02", "closed"}, want: false},
+		{name: "decimals", first: []string{"sensor", "0.5"}, second: []string{"sensor", "0.7"}, want: false},
+		{name: "empty value", first: []string{"id", ""}, second: []string{"1", "x"}, want: false},
+		{name: "duplicate values", first: []string{"yes", "yes"}, second: []string{"no", "yes"}, want: false},
+		{name: "value repeated below", first: []string{"tokyo", "active"}, second: []string{"osaka", "active"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, looksLikeHeader(tt.first, tt.second))
+		})
+	}
+}
+
+func TestDBBuilder_WithHeaderDetection(t *testing.T) {
+	t.Parallel()
+
+	t.Run("files with and without a header row", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name\n1,Gina\n2,Yuki\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "readings.tsv"), []byte("1\t20.5\tok\n2\t21.0\tok\n3\t19.8\tlow\n"), 0600))
+
+		db := openTestDB(t, NewBuilder().AddPath(dir).WithHeaderDetection())
+
+		var name string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT name FROM users WHERE id = 2").Scan(&name))
+		assert.Equal(t, "Yuki", name)
+
+		var count int
+		var total float64
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT COUNT(*), SUM(column2) FROM readings WHERE column3 = 'ok'").Scan(&count, &total))
+		assert.Equal(t, 2, count)
+		assert.InDelta(t, 41.5, total, 1e-9)
+
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []LoadWarning{
+			{Table: "users", Message: "header detection: first row is the header"},
+			{Table: "readings", Message: "header detection: first row is data, columns are named column1 to column3"},
+		}, warnings)
+	})
+
+	t.Run("a single data row", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("42,Gina\n"), "answers", FileTypeCSV).
+			WithHeaderDetection())
+
+		var answer int
+		var name string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT column1, column2 FROM answers").Scan(&answer, &name))
+		assert.Equal(t, 42, answer)
+		assert.Equal(t, "Gina", name)
+	})
+
+	t.Run("without detection the first row is the header", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("1,Gina\n2,Yuki\n"), "users", FileTypeCSV))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM users").Scan(&count))
+		assert.Equal(t, 1, count)
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+}
@@ -0,0 +1,120 @@
+package filesql
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WithHeaderMapping renames columns at load time.
+//
+// The keys of mapping are column names as they appear in the source files and the
+// values are the column names to create instead. The mapping applies to every input,
+// so cryptic upstream headers get the same meaningful names on every run:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("export.csv").
+//		WithHeaderMapping(map[string]string{
+//			"fld_17": "customer_id",
+//			"fld_18": "order_date",
+//		})
+//
+// Columns that are not in the mapping keep their names. Calling WithHeaderMapping
+// several times merges the mappings; later entries win.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithHeaderMapping(mapping map[string]string) *DBBuilder {
+	if b.streamProcessor.headerMapping == nil {
+		b.streamProcessor.headerMapping = make(map[string]string, len(mapping))
+	}
+	for from, to := range mapping {
+		b.streamProcessor.headerMapping[from] = to
+	}
+	return b
+}
+
+// WithHeaderMappingFile renames columns at load time using a JSON mapping file.
+//
+// The file contains a single JSON object whose keys are source column names and
+// whose values are the column names to create, for example:
+//
+//	{
+//	  "fld_17": "customer_id",
+//	  "fld_18": "order_date"
+//	}
+//
+// The file is read by Build. Entries from WithHeaderMapping take precedence over the file.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithHeaderMappingFile(path string) *DBBuilder {
+	b.headerMappingFiles = append(b.headerMappingFiles, path)
+	return b
+}
+
+// loadHeaderMappingFiles reads the configured mapping files into the stream processor
+func (b *DBBuilder) loadHeaderMappingFiles() error {
+	if len(b.headerMappingFiles) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string)
+	for _, path := range b.headerMappingFiles {
+		data, err := os.ReadFile(path) //nolint:gosec // Mapping file path is provided by the caller
+		if err != nil {
+			return fmt.Errorf("failed to read header mapping file %s: %w", path, err)
+		}
+		var mapping map[string]string
+		if err := json.Unmarshal(data, &mapping); err != nil {
+			return fmt.Errorf("failed to decode header mapping file %s: %w", path, err)
+		}
+		for from, to := range mapping {
+			merged[from] = to
+		}
+	}
+
+	// Explicit mappings override the ones read from files
+	for from, to := range b.streamProcessor.headerMapping {
+		merged[from] = to
+	}
+	b.streamProcessor.headerMapping = merged
+	b.headerMappingFiles = nil
+	return nil
+}
+
+// renameColumns applies the header mapping to a chunk
+func (sp *streamProcessor) renameColumns(chunk *tableChunk) (*tableChunk, error) {
+	if len(sp.headerMapping) == 0 {
+		return chunk, nil
+	}
+
+	renamed := false
+	headers := make(header, len(chunk.getHeaders()))
+	for i, name := range chunk.getHeaders() {
+		headers[i] = name
+		if to, ok := sp.headerMapping[name]; ok {
+			headers[i] = to
+			renamed = true
+		}
+	}
+	if !renamed {
+		return chunk, nil
+	}
+	if err := validateColumnNames(headers); err != nil {
+		return nil, fmt.Errorf("header mapping for table %s: %w", chunk.getTableName(), err)
+	}
+
+	infos := make([]columnInfo, len(chunk.getColumnInfo()))
+	for i, info := range chunk.getColumnInfo() {
+		infos[i] = info
+		if i < len(headers) {
+			infos[i].Name = headers[i]
+		}
+	}
+
+	return &tableChunk{
+		tableName:  chunk.getTableName(),
+		headers:    headers,
+		records:    chunk.getRecords(),
+		columnInfo: infos,
+	}, nil
+}
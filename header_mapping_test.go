@@ -0,0 +1,71 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithHeaderMapping(t *testing.T) {
+	t.Parallel()
+
+	data := "fld_1,fld_2,note\n1,Alice,x\n2,Bob,y\n"
+
+	const columns = "SELECT name || ' ' || type FROM pragma_table_info('users') ORDER BY cid"
+	runLoadTests(t, []loadTest{
+		{
+			name: "columns are renamed at load time",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader(data), "users", FileTypeCSV).
+					WithHeaderMapping(map[string]string{"fld_1": "id", "fld_2": "name"})
+			},
+			want: map[string][]string{
+				columns:                               {"id INTEGER", "name TEXT", "note TEXT"},
+				"SELECT name FROM users WHERE id = 2": {"Bob"},
+			},
+		},
+		{
+			name: "mapping file is read at build time and explicit entries win",
+			builder: func(t *testing.T) *DBBuilder {
+				mappingPath := filepath.Join(t.TempDir(), "mapping.json")
+				require.NoError(t, os.WriteFile(mappingPath, []byte(`{"fld_1": "id", "fld_2": "from_file"}`), 0600))
+				return NewBuilder().
+					AddReader(strings.NewReader(data), "users", FileTypeCSV).
+					WithHeaderMappingFile(mappingPath).
+					WithHeaderMapping(map[string]string{"fld_2": "name"})
+			},
+			want: map[string][]string{columns: {"id INTEGER", "name TEXT", "note TEXT"}},
+		},
+	})
+
+	t.Run("missing or invalid mapping file fails build", func(t *testing.T) {
+		t.Parallel()
+
+		invalidPath := filepath.Join(t.TempDir(), "invalid.json")
+		require.NoError(t, os.WriteFile(invalidPath, []byte(`["not", "an", "object"]`), 0600))
+
+		for _, path := range []string{filepath.Join(t.TempDir(), "missing.json"), invalidPath} {
+			_, err := NewBuilder().
+				AddReader(strings.NewReader(data), "users", FileTypeCSV).
+				WithHeaderMappingFile(path).
+				Build(context.Background())
+			assert.Error(t, err, path)
+		}
+	})
+
+	t.Run("mapping that creates duplicate columns fails", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := tryOpenTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(data), "users", FileTypeCSV).
+			WithHeaderMapping(map[string]string{"fld_1": "note"}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate column name")
+	})
+}
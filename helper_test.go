@@ -0,0 +1,110 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestDB builds and opens builder, closing the database when the test ends.
+func openTestDB(t *testing.T, builder *DBBuilder) *sql.DB {
+	t.Helper()
+
+	db, err := tryOpenTestDB(t, builder)
+	require.NoError(t, err)
+	return db
+}
+
+// tryOpenTestDB is like openTestDB but returns the Build or Open error instead of failing the test.
+func tryOpenTestDB(t *testing.T, builder *DBBuilder) (*sql.DB, error) {
+	t.Helper()
+
+	ctx := context.Background()
+	validatedBuilder, err := builder.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, err := validatedBuilder.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db, nil
+}
+
+// queryRows returns the rows of query with their columns joined by ":" and NULL written as "NULL".
+func queryRows(t *testing.T, db *sql.DB, query string) []string {
+	t.Helper()
+
+	rows, err := db.QueryContext(context.Background(), query)
+	require.NoError(t, err)
+	defer rows.Close()
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+
+	var got []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		require.NoError(t, rows.Scan(dest...))
+		fields := make([]string, len(values))
+		for i, value := range values {
+			fields[i] = "NULL"
+			if value.Valid {
+				fields[i] = value.String
+			}
+		}
+		got = append(got, strings.Join(fields, ":"))
+	}
+	require.NoError(t, rows.Err())
+	return got
+}
+
+// loadTest describes a load and the database it produces.
+type loadTest struct {
+	name    string
+	builder func(t *testing.T) *DBBuilder
+	// tables lists the user tables in name order, and is not checked when nil
+	tables []string
+	// want maps queries to the rows queryRows returns for them
+	want map[string][]string
+	// wantErr is the error code of a load that fails
+	wantErr ErrorCode
+	// wantErrMsg is contained in the error message of a load that fails, and is not checked when empty
+	wantErrMsg string
+}
+
+// runLoadTests runs each loadTest as a parallel subtest.
+func runLoadTests(t *testing.T, tests []loadTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db, err := tryOpenTestDB(t, tt.builder(t))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, ErrorCodeOf(err))
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			if tt.tables != nil {
+				tables, err := getSQLiteTableNames(db)
+				require.NoError(t, err)
+				assert.Equal(t, tt.tables, tables)
+			}
+			for query, want := range tt.want {
+				assert.Equal(t, want, queryRows(t, db, query), query)
+			}
+		})
+	}
+}
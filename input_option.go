@@ -0,0 +1,153 @@
+package filesql
+
+import (
+	"io"
+)
+
+// InputOption configures a single input added with AddPathWithOptions or AddReaderWithOptions.
+type InputOption func(*inputOptions)
+
+// inputOptions holds the settings of a single input
+type inputOptions struct {
+	// delimiter is the field delimiter of delimited text; 0 uses the delimiter of the file type
+	delimiter rune
+	// noHeader loads the first row of delimited text as data
+	noHeader bool
+	// columnNames name the columns of delimited text without a header row
+	columnNames []string
+	// encoding is the character encoding of text; empty means UTF-8 or detection
+	encoding string
+}
+
+// newInputOptions applies options to the default input settings
+func newInputOptions(options []InputOption) inputOptions {
+	var settings inputOptions
+	for _, option := range options {
+		if option != nil {
+			option(&settings)
+		}
+	}
+	return settings
+}
+
+// isZero reports whether no option is set
+func (o inputOptions) isZero() bool {
+	return o.delimiter == 0 && !o.headerless() && o.encoding == ""
+}
+
+// withDefaults returns the settings with the unset options taken from defaults
+func (o inputOptions) withDefaults(defaults inputOptions) inputOptions {
+	if o.delimiter == 0 {
+		o.delimiter = defaults.delimiter
+	}
+	if !o.headerless() {
+		o.noHeader, o.columnNames = defaults.noHeader, defaults.columnNames
+	}
+	if o.encoding == "" {
+		o.encoding = defaults.encoding
+	}
+	return o
+}
+
+// AddPathWithOptions adds a file or directory like AddPath with options that apply to
+// it only, such as WithDelimiter.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPathWithOptions("exports/", filesql.WithDelimiter(';')). // every CSV file in exports/
+//		AddPath("users.csv")                                        // comma-separated
+//
+// Returns self for chaining.
+func (b *DBBuilder) AddPathWithOptions(path string, options ...InputOption) *DBBuilder {
+	settings := newInputOptions(options)
+	paths, remotes := len(b.paths), len(b.remotes)
+	b.AddPath(path)
+
+	if settings.isZero() {
+		return b
+	}
+	switch {
+	case len(b.paths) > paths:
+		if b.pathOptions == nil {
+			b.pathOptions = make(map[int]inputOptions)
+		}
+		b.pathOptions[paths] = settings
+	case len(b.remotes) > remotes:
+		b.remotes[remotes].options = settings
+	}
+	return b
+}
+
+// AddReaderWithOptions adds data from an io.Reader like AddReader with options that
+// apply to it only. Use FileTypeDelimited with WithDelimiter for delimited text that
+// is neither comma- nor tab-separated.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddReaderWithOptions(resp.Body, "sales", filesql.FileTypeDelimited, filesql.WithDelimiter('|'))
+//
+// Returns self for chaining.
+func (b *DBBuilder) AddReaderWithOptions(reader io.Reader, tableName string, fileType FileType, options ...InputOption) *DBBuilder {
+	b.AddReader(reader, tableName, fileType)
+	b.readers[len(b.readers)-1].options = newInputOptions(options)
+	return b
+}
+
+// validate checks the settings of an input
+func (o inputOptions) validate() error {
+	if err := validateDelimiter(o.delimiter); err != nil {
+		return err
+	}
+	if err := validateEncoding(o.encoding); err != nil {
+		return err
+	}
+	return validateGivenColumnNames(o.columnNames)
+}
+
+// validateInputOptions checks the options of all inputs and of the builder
+func (b *DBBuilder) validateInputOptions() error {
+	if err := newInputOptions(b.inputOptions).validate(); err != nil {
+		return err
+	}
+	for _, options := range b.pathOptions {
+		if err := options.validate(); err != nil {
+			return err
+		}
+	}
+	for _, remote := range b.remotes {
+		if err := remote.options.validate(); err != nil {
+			return err
+		}
+	}
+	for _, reader := range b.readers {
+		if err := reader.options.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveInputOptions maps the collected paths to the options of the path they were
+// found through and completes the options of the readers. Options an input does not
+// set itself are taken from the options applied to the whole builder, if any.
+func (b *DBBuilder) resolveInputOptions(collected *collectedFiles) {
+	defaults := newInputOptions(b.inputOptions)
+	for i := range b.readers {
+		b.readers[i].options = b.readers[i].options.withDefaults(defaults)
+	}
+
+	if len(b.pathOptions) == 0 && defaults.isZero() {
+		b.streamProcessor.fileOptions = nil
+		return
+	}
+	fileOptions := make(map[string]inputOptions)
+	for i, path := range collected.paths {
+		options := b.pathOptions[collected.origins[i]].withDefaults(defaults)
+		if !options.isZero() {
+			fileOptions[path] = options
+		}
+	}
+	b.streamProcessor.fileOptions = fileOptions
+}
@@ -0,0 +1,40 @@
+package filesql
+
+import "strconv"
+
+// maxSafeJSONInteger is the largest integer a JSON consumer that parses numbers as
+// IEEE 754 doubles, such as JavaScript, can represent exactly (Number.MAX_SAFE_INTEGER)
+const maxSafeJSONInteger = 1<<53 - 1
+
+// WithSafeJSONIntegers writes integers that JavaScript cannot represent exactly as
+// JSON strings instead of numbers.
+//
+// JSON numbers have no size limit, but JavaScript and many other consumers parse them
+// as 64-bit floating-point values, which represent integers exactly only up to
+// 2^53-1. A 64-bit ID such as 9007199254740993 loaded into an INTEGER column would
+// silently arrive as 9007199254740992. With this option, JSON Lines exports write
+// integers outside of -(2^53-1) to 2^53-1 as strings, such as "9007199254740993",
+// and smaller integers as numbers, so consumers can recognize and keep the exact
+// value. Other formats are not affected.
+//
+// Type inference decides which columns hold integers: columns loaded as TEXT, for
+// example with EnableNumericTextPreservation, are always written as strings.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().
+//		WithFormat(filesql.OutputFormatJSONL).
+//		WithSafeJSONIntegers()
+//	err := filesql.DumpDatabase(db, "./output", options)
+func (o DumpOptions) WithSafeJSONIntegers() DumpOptions {
+	o.SafeJSONIntegers = true
+	return o
+}
+
+// jsonInteger returns the JSON encoding of an integer value
+func (f valueFormatter) jsonInteger(v int64) []byte {
+	if f.safeJSONIntegers && (v > maxSafeJSONInteger || v < -maxSafeJSONInteger) {
+		return strconv.AppendQuote(nil, strconv.FormatInt(v, 10))
+	}
+	return strconv.AppendInt(nil, v, 10)
+}
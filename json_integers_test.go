@@ -0,0 +1,57 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOptions_WithSafeJSONIntegers(t *testing.T) {
+	t.Parallel()
+
+	csv := "id,parent,amount\n9007199254740993,9007199254740991,10\n-9007199254740993,-9007199254740991,20\n"
+	db := openTestDB(t, NewBuilder().AddReader(strings.NewReader(csv), "events", FileTypeCSV))
+
+	tests := []struct {
+		name    string
+		options DumpOptions
+		want    string
+	}{
+		{
+			name:    "integers beyond 2^53-1 are written as strings",
+			options: NewDumpOptions().WithSafeJSONIntegers(),
+			want: `{"id":"9007199254740993","parent":9007199254740991,"amount":10}` + "\n" +
+				`{"id":"-9007199254740993","parent":-9007199254740991,"amount":20}` + "\n",
+		},
+		{
+			name:    "integers are numbers by default",
+			options: NewDumpOptions(),
+			want: `{"id":9007199254740993,"parent":9007199254740991,"amount":10}` + "\n" +
+				`{"id":-9007199254740993,"parent":-9007199254740991,"amount":20}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			outputDir := t.TempDir()
+			require.NoError(t, DumpDatabase(db, outputDir, tt.options.WithFormat(OutputFormatJSONL)))
+			content, err := os.ReadFile(filepath.Join(outputDir, "events.jsonl")) //nolint:gosec // Test file
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+
+	t.Run("configuration files enable the option", func(t *testing.T) {
+		t.Parallel()
+
+		options, err := AutoSaveSettings{SafeJSONIntegers: true}.dumpOptions()
+		require.NoError(t, err)
+		assert.True(t, options.SafeJSONIntegers)
+	})
+}
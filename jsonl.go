@@ -0,0 +1,353 @@
+package filesql
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// jsonlLine is a non-empty line of JSON Lines data with its line number
+type jsonlLine struct {
+	number int
+	text   []byte
+}
+
+// jsonlLines returns the non-empty lines of JSON Lines content
+func jsonlLines(content []byte) []jsonlLine {
+	var lines []jsonlLine
+	number := 0
+	for len(content) > 0 {
+		number++
+		line := content
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			line, content = content[:i], content[i+1:]
+		} else {
+			content = nil
+		}
+		line = bytes.TrimSpace(line)
+		if number == 1 {
+			line = bytes.TrimPrefix(line, []byte("\ufeff"))
+		}
+		if len(line) > 0 {
+			lines = append(lines, jsonlLine{number: number, text: line})
+		}
+	}
+	return lines
+}
+
+// jsonlObject is a JSON object flattened into columns, in the order of its keys
+type jsonlObject struct {
+	keys   []string
+	values map[string]string
+}
+
+// parseJSONLObject flattens the JSON object of a line. Nested objects become columns
+// named with dot notation, such as "user.name"; arrays are kept as compact JSON text
+// that the SQLite JSON functions can query. Numbers keep their exact text, true and
+// false become "true" and "false", and null becomes an empty value.
+func parseJSONLObject(line jsonlLine) (*jsonlObject, error) {
+	object := &jsonlObject{values: make(map[string]string)}
+	if err := object.add("", line.text); err != nil {
+		return nil, fmt.Errorf("line %d: %w", line.number, err)
+	}
+	return object, nil
+}
+
+// add adds the members of the JSON object raw, prefixing their names with prefix
+func (o *jsonlObject) add(prefix string, raw []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return errors.New("not a JSON object")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		name := prefix + token.(string) // Object keys are always strings
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("invalid JSON value of %q: %w", name, err)
+		}
+
+		switch value[0] {
+		case '{':
+			if err := o.add(name+".", value); err != nil {
+				return err
+			}
+			continue
+		case '[':
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, value); err != nil {
+				return fmt.Errorf("invalid JSON value of %q: %w", name, err)
+			}
+			o.set(name, compact.String())
+		case '"':
+			var text string
+			if err := json.Unmarshal(value, &text); err != nil {
+				return fmt.Errorf("invalid JSON value of %q: %w", name, err)
+			}
+			o.set(name, text)
+		case 'n':
+			o.set(name, "")
+		default:
+			// Numbers, true, and false
+			o.set(name, string(value))
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("more than one JSON value on the line")
+	}
+	return nil
+}
+
+// set sets the value of a column, keeping the position of a repeated key
+func (o *jsonlObject) set(name, value string) {
+	if _, exists := o.values[name]; !exists {
+		o.keys = append(o.keys, name)
+	}
+	o.values[name] = value
+}
+
+// jsonlHeader returns the columns of all objects in the order they first appear
+func jsonlHeader(lines []jsonlLine) (header, error) {
+	var columns header
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		object, err := parseJSONLObject(line)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range object.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	return columns, nil
+}
+
+// jsonlRecord returns the values of object in the order of the columns of header.
+// Columns missing from the object are empty.
+func jsonlRecord(header header, object *jsonlObject) Record {
+	record := make(Record, len(header))
+	for i, column := range header {
+		record[i] = object.values[column]
+	}
+	return record
+}
+
+// parseJSONLTable parses a JSON Lines table from content
+func parseJSONLTable(tableName string, content []byte) (*table, error) {
+	lines := jsonlLines(content)
+	if len(lines) == 0 {
+		return nil, errors.New("empty JSONL data")
+	}
+	header, err := jsonlHeader(lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSONL: %w", err)
+	}
+	if err := validateColumnNames(header); err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(lines))
+	for _, line := range lines {
+		object, err := parseJSONLObject(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSONL: %w", err)
+		}
+		records = append(records, jsonlRecord(header, object))
+	}
+	return newTable(tableName, header, records), nil
+}
+
+// parseJSONL parses JSON Lines file with compression support
+func (f *file) parseJSONL() (*table, error) {
+	reader, closer, err := f.openReader()
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONLTable(tableFromFilePath(f.path), content)
+}
+
+// parseJSONLStream parses JSON Lines data from reader using streaming approach
+func (p *streamingParser) parseJSONLStream(reader io.Reader) (*table, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSONL: %w", err)
+	}
+	return parseJSONLTable(p.tableName, content)
+}
+
+// processJSONLInChunks processes JSON Lines data in chunks. Like LTSV, the data is read
+// twice: first to collect the columns of all objects, then to build the rows.
+func (p *streamingParser) processJSONLInChunks(reader io.Reader, processor chunkProcessor) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read JSONL: %w", err)
+	}
+	lines := jsonlLines(content)
+	if len(lines) == 0 {
+		return errors.New("empty JSONL data")
+	}
+
+	header, err := jsonlHeader(lines)
+	if err != nil {
+		return fmt.Errorf("failed to parse JSONL: %w", err)
+	}
+	if len(header) == 0 {
+		return errors.New("no JSONL keys found")
+	}
+	if err := p.validateHeader(header); err != nil {
+		return err
+	}
+
+	chunkSize := p.chunkSize.Int()
+	if chunkSize <= 0 {
+		chunkSize = DefaultRowsPerChunk
+	}
+
+	var columnInfo columnInfoList
+	records := make([]Record, 0, min(chunkSize, len(lines)))
+	flush := func() error {
+		if len(columnInfo) == 0 {
+			columnInfo = newColumnInfoList(header, records)
+		}
+		chunk := &tableChunk{
+			tableName:  p.tableName,
+			headers:    header,
+			records:    records,
+			columnInfo: columnInfo,
+		}
+		if err := processor(chunk); err != nil {
+			return fmt.Errorf("chunk processor error: %w", err)
+		}
+		records = nil
+		return nil
+	}
+
+	for _, line := range lines {
+		object, err := parseJSONLObject(line)
+		if err != nil {
+			return fmt.Errorf("failed to parse JSONL: %w", err)
+		}
+		records = append(records, jsonlRecord(header, object))
+		if len(records) >= chunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(records) > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// writeJSONLData writes data in JSON Lines format, one object per row with the columns
+// as keys. Integers and reals are written as numbers, NULL as null, and everything
+// else as strings.
+func writeJSONLData(writer io.Writer, columns []string, rows *sql.Rows, formatter valueFormatter) error {
+	buffered := bufio.NewWriter(writer)
+
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := marshalJSONString(column)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	// Prepare for scanning
+	values := make([]any, len(columns))
+	scanArgs := make([]any, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	// Write data rows
+	var line []byte
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+
+		line = append(line[:0], '{')
+		for i, value := range values {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			line = append(line, keys[i]...)
+			line = append(line, ':')
+			encoded, err := jsonlValue(i, value, formatter)
+			if err != nil {
+				return err
+			}
+			line = append(line, encoded...)
+		}
+		line = append(line, '}', '\n')
+		if _, err := buffered.Write(line); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// jsonlValue returns the JSON encoding of the value of the column at index i
+func jsonlValue(i int, value any, formatter valueFormatter) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return []byte("null"), nil
+	case int64:
+		return formatter.jsonInteger(v), nil
+	case float64:
+		// A float format may produce text that is not a JSON number, such as "NaN"
+		formatted := formatter.format(i, v)
+		if _, err := strconv.ParseFloat(formatted, 64); err == nil && json.Valid([]byte(formatted)) {
+			return []byte(formatted), nil
+		}
+		return marshalJSONString(formatted)
+	case []byte:
+		return marshalJSONString(string(v))
+	default:
+		return marshalJSONString(formatter.format(i, v))
+	}
+}
+
+// marshalJSONString returns s as a JSON string without escaping HTML characters
+func marshalJSONString(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
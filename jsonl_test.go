@@ -0,0 +1,133 @@
+package filesql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONLTable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flattens nested objects and keeps arrays as JSON text", func(t *testing.T) {
+		t.Parallel()
+
+		content := "\ufeff" + `{"id":1,"user":{"name":"alice","address":{"city":"Tokyo"}},"tags":["a", "b"]}` + "\n" +
+			"\n" +
+			`{"id":2.50,"active":true,"user":{"name":"bob"},"note":null}` + "\r\n"
+
+		tbl, err := parseJSONLTable("events", []byte(content))
+		require.NoError(t, err)
+		assert.Equal(t, "events", tbl.getName())
+		assert.Equal(t, header{"id", "user.name", "user.address.city", "tags", "active", "note"}, tbl.getHeader())
+		assert.Equal(t, []Record{
+			{"1", "alice", "Tokyo", `["a","b"]`, "", ""},
+			{"2.50", "bob", "", "", "true", ""},
+		}, tbl.getRecords())
+	})
+
+	t.Run("reports the line of invalid data", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name    string
+			content string
+			want    string
+		}{
+			{name: "invalid JSON", content: "{\"id\":1}\n{\"id\":\n", want: "line 2"},
+			{name: "not an object", content: "[1,2]\n", want: "not a JSON object"},
+			{name: "several values", content: "{\"id\":1} {\"id\":2}\n", want: "more than one JSON value"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := parseJSONLTable("bad", []byte(tt.content))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.want)
+			})
+		}
+	})
+
+	t.Run("rejects empty data", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parseJSONLTable("empty", []byte("\n  \n"))
+		require.Error(t, err)
+	})
+}
+
+func TestJSONLLoading(t *testing.T) {
+	t.Parallel()
+
+	t.Run("loads jsonl and compressed ndjson files", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.jsonl"),
+			[]byte(`{"id":1,"name":"alice","profile":{"age":30}}`+"\n"+`{"id":2,"name":"bob","profile":{"age":25}}`+"\n"), 0o600))
+
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		_, err := gz.Write([]byte(`{"user_id":1,"amount":100}` + "\n" + `{"user_id":1,"amount":50}` + "\n"))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.ndjson.gz"), compressed.Bytes(), 0o600))
+
+		db := openTestDB(t, NewBuilder().AddPaths(filepath.Join(dir, "users.jsonl"), filepath.Join(dir, "orders.ndjson.gz")))
+
+		var total, age int
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			`SELECT SUM(o.amount), u."profile.age" FROM users u JOIN orders o ON o.user_id = u.id`).Scan(&total, &age))
+		assert.Equal(t, 150, total)
+		assert.Equal(t, 30, age)
+	})
+
+	t.Run("loads jsonl from a reader and queries arrays with JSON functions", func(t *testing.T) {
+		t.Parallel()
+
+		data := `{"id":1,"tags":["go","sql"]}` + "\n" + `{"id":2,"tags":[]}` + "\n"
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(data), "items", FileTypeJSONL).
+			SetDefaultChunkSize(1))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT json_array_length(tags) FROM items WHERE id = 1").Scan(&count))
+		assert.Equal(t, 2, count)
+	})
+}
+
+func TestDumpDatabaseJSONL(t *testing.T) {
+	t.Parallel()
+
+	db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+
+	ctx := context.Background()
+	_, err := db.ExecContext(ctx, "CREATE TABLE mixed (id INTEGER, score REAL, name TEXT, note TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO mixed VALUES (1, 1.5, 'a "quoted" <name>', NULL)`)
+	require.NoError(t, err)
+
+	outputDir := t.TempDir()
+	require.NoError(t, DumpDatabase(db, outputDir, NewDumpOptions().WithFormat(OutputFormatJSONL)))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "mixed.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"score":1.5,"name":"a \"quoted\" <name>","note":null}`+"\n", string(content))
+
+	// The dumped file loads back into the same rows
+	reloaded := openTestDB(t, NewBuilder().AddPath(filepath.Join(outputDir, "mixed.jsonl")))
+
+	var name string
+	require.NoError(t, reloaded.QueryRowContext(ctx, "SELECT name FROM mixed WHERE id = 1").Scan(&name))
+	assert.Equal(t, `a "quoted" <name>`, name)
+}
@@ -0,0 +1,421 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// lazyTablesTableName is the metadata table that lists the tables that are not loaded yet
+const lazyTablesTableName = metadataTablePrefix + "lazy_tables"
+
+// EnableLazyLoading defers loading each file until the first statement that
+// references its table.
+//
+// Open normally parses and inserts every file before it returns, which is slow for a
+// directory of dozens of large files when only one of them is queried. With lazy
+// loading, Open registers the table name of each file and returns right away; a file
+// is loaded when a query, statement, or prepared statement first mentions its table
+// name, and the statement then runs against the loaded table. Tables that are never
+// referenced are never loaded.
+//
+// The tables that are not loaded yet are listed in the "_filesql_lazy_tables"
+// metadata table, and they are missing from sqlite_master until they are loaded. Call
+// LoadLazyTables to load every remaining table, for example before DumpDatabase or
+// GetSchema. Beginning a transaction also loads every remaining table, so that the
+// statements of a transaction never wait for a file to load.
+//
+// Only files added with AddPath or AddPaths are loaded lazily. Excel workbooks, which
+// hold several tables, readers, filesystems, and remote inputs are loaded by Open as
+// usual. Errors of a file, such as malformed rows, are returned by the first
+// statement that references its table instead of by Open.
+//
+// Example:
+//
+//	validatedBuilder, err := filesql.NewBuilder().
+//		AddPath("exports/"). // Dozens of large files
+//		EnableLazyLoading().
+//		Build(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	db, err := validatedBuilder.Open(ctx)
+//	...
+//	// Only orders.csv is parsed
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM orders WHERE total > 100")
+//
+// Build fails with an error with ErrCodeInvalidConfig if auto-save is enabled, because
+// a save would miss the tables that are not loaded.
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableLazyLoading() *DBBuilder {
+	b.lazyLoading = true
+	return b
+}
+
+// LoadLazyTables loads every table of db that EnableLazyLoading has not loaded yet.
+// It does nothing for a database opened without lazy loading.
+func LoadLazyTables(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return withErrorCode(fmt.Errorf("failed to get connection: %w", err), ErrCodeLoadFailed)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		if c, ok := driverConn.(*lazyConnection); ok {
+			return c.tables.loadAll(ctx)
+		}
+		return nil
+	})
+}
+
+// validateLazyLoading checks that lazy loading can be used with the auto-save configuration
+func validateLazyLoading(lazyLoading bool, config *autoSaveConfig) error {
+	if lazyLoading && config != nil && config.enabled {
+		return newCodedError(ErrCodeInvalidConfig, "EnableLazyLoading cannot be combined with EnableAutoSave or EnableAutoSaveOnCommit")
+	}
+	return nil
+}
+
+// registerLazyTables registers the collected paths that are loaded lazily with db and
+// returns the paths to load right away and the lazy tables of db. Without lazy
+// loading, it returns every collected path and nil.
+func (b *DBBuilder) registerLazyTables(ctx context.Context, db *sql.DB) ([]string, *lazyTables, error) {
+	if !b.lazyLoading {
+		return b.collectedPaths, nil, nil
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+	var lazy *lazyTables
+	if err := conn.Raw(func(driverConn any) error {
+		if c, ok := driverConn.(*lazyConnection); ok {
+			lazy = c.tables
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	if lazy == nil {
+		return b.collectedPaths, nil, nil
+	}
+
+	paths, err := lazy.register(ctx, b.collectedPaths)
+	return paths, lazy, err
+}
+
+// isLazyLoadable reports whether the file at path can be loaded lazily: it holds a single table
+func isLazyLoadable(path string) bool {
+	switch newFile(path).getFileType().baseType() {
+	case FileTypeXLSX, FileTypeXLS, FileTypeODS, FileTypeZIP, FileTypeTAR:
+		return false
+	default:
+		return true
+	}
+}
+
+// lazyTables loads the tables of files on demand
+type lazyTables struct {
+	// sp loads the files
+	sp *streamProcessor
+	// db loads the files through connections that do not load tables on demand
+	db *sql.DB
+
+	mu sync.Mutex
+	// pending maps the lowercase names of the tables that are not loaded yet to their files
+	pending map[string]string
+	// suspended is true while Open loads the other inputs, whose statements must not
+	// load tables
+	suspended bool
+}
+
+// newLazyTables returns the lazy tables of memory, loaded with sp
+func newLazyTables(memory *memoryConnector, sp *streamProcessor) *lazyTables {
+	return &lazyTables{
+		sp:      sp.withStaging(),
+		db:      sql.OpenDB(lazyLoaderConnector{memory: memory}),
+		pending: make(map[string]string),
+	}
+}
+
+// register records the files of paths as not loaded yet and returns the paths that
+// must be loaded right away. Tables are not loaded on demand until resume is called.
+func (l *lazyTables) register(ctx context.Context, paths []string) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.suspended = true
+
+	// Files that share a table name are loaded right away when the collision policy
+	// allows it, so the policy applies in the order of the files
+	shared := make(map[string]int)
+	for _, path := range paths {
+		table := l.sp.fileTableName(path)
+		if l.sp.tableCollision == TableCollisionSuffix || l.sp.mergesInto(table) {
+			shared[strings.ToLower(table)]++
+		}
+	}
+
+	var eager, lazy []string
+	for _, path := range paths {
+		table := l.sp.fileTableName(path)
+		if !isLazyLoadable(path) || shared[strings.ToLower(table)] > 1 {
+			eager = append(eager, path)
+			continue
+		}
+		if _, ok := l.pending[strings.ToLower(table)]; ok {
+			return nil, newCodedError(ErrCodeDuplicateTable, "table '%s' already exists from another file, duplicate table names are not allowed", table)
+		}
+		l.pending[strings.ToLower(table)] = path
+		lazy = append(lazy, path)
+	}
+	if len(lazy) == 0 {
+		return eager, nil
+	}
+
+	if _, err := l.db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (table_name TEXT, source TEXT)`, lazyTablesTableName,
+	)); err != nil {
+		return nil, fmt.Errorf("failed to create lazy tables table: %w", err)
+	}
+	for _, path := range lazy {
+		if _, err := l.db.ExecContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+			`INSERT INTO "%s" (table_name, source) VALUES (?, ?)`, lazyTablesTableName,
+		), l.sp.fileTableName(path), path); err != nil {
+			return nil, fmt.Errorf("failed to register lazy table: %w", err)
+		}
+	}
+	return eager, nil
+}
+
+// resume ends the suspension that register started and fails if a table that is not
+// loaded yet has the name of a table that was loaded meanwhile
+func (l *lazyTables) resume(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.suspended = false
+
+	for name := range l.pending {
+		var exists int
+		if err := l.db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=? COLLATE NOCASE`, name,
+		).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check table existence: %w", err)
+		}
+		if exists > 0 {
+			return newCodedError(ErrCodeDuplicateTable, "table '%s' already exists from another file, duplicate table names are not allowed", name)
+		}
+	}
+	return nil
+}
+
+// loadReferenced loads the tables that query references and that are not loaded yet
+func (l *lazyTables) loadReferenced(ctx context.Context, query string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.pending) == 0 || l.suspended {
+		return nil
+	}
+
+	for _, name := range referencedNames(query) {
+		if _, ok := l.pending[name]; !ok {
+			continue
+		}
+		if err := l.loadLocked(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadAll loads every table that is not loaded yet
+func (l *lazyTables) loadAll(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.suspended {
+		return nil
+	}
+
+	for name := range l.pending {
+		if err := l.loadLocked(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadLocked loads the pending table name. The caller must hold mu.
+func (l *lazyTables) loadLocked(ctx context.Context, name string) error {
+	path := l.pending[name]
+	table := l.sp.fileTableName(path)
+	if err := l.sp.streamFileToDatabase(ctx, l.db, path); err != nil {
+		// Drop what was staged, so the load can be retried by the next statement
+		_, _ = l.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdentifier(l.sp.loadingTableName(table)))
+		return withErrorCode(fmt.Errorf("failed to load table %s from %s: %w", table, path, err), ErrCodeLoadFailed)
+	}
+	if _, err := l.db.ExecContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`DELETE FROM "%s" WHERE table_name = ?`, lazyTablesTableName,
+	), table); err != nil {
+		return fmt.Errorf("failed to unregister lazy table: %w", err)
+	}
+	delete(l.pending, name)
+	return nil
+}
+
+// close closes the connections that load tables
+func (l *lazyTables) close() error {
+	return l.db.Close()
+}
+
+// referencedNames returns the lowercase identifiers of query, quoted or not. String
+// literals and comments are skipped.
+func referencedNames(query string) []string {
+	var names []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i, c)
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i, c)
+			name := strings.TrimSuffix(query[i+1:end], string(c))
+			names = append(names, strings.ToLower(strings.ReplaceAll(name, string([]byte{c, c}), string(c))))
+			i = end
+		case c == '[':
+			end := strings.IndexByte(query[i:], ']')
+			if end < 0 {
+				end = len(query) - i - 1
+			}
+			names = append(names, strings.ToLower(query[i+1:i+end]))
+			i += end + 1
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i - 4
+			}
+			i += end + 4
+		case isIdentifierStart(c):
+			end := i + 1
+			for end < len(query) && isIdentifierPart(query[end]) {
+				end++
+			}
+			names = append(names, strings.ToLower(query[i:end]))
+			i = end
+		default:
+			i++
+		}
+	}
+	return names
+}
+
+// lazyLoaderConnector opens connections to the in-memory database that load files.
+// Unlike memoryConnector, it does not drop the database when it is closed.
+type lazyLoaderConnector struct {
+	memory *memoryConnector
+}
+
+// Connect implements driver.Connector
+func (c lazyLoaderConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.memory.connect(ctx)
+}
+
+// Driver implements driver.Connector
+func (c lazyLoaderConnector) Driver() driver.Driver {
+	return c.memory.driver
+}
+
+// lazyConnection loads the tables a statement references before running it
+type lazyConnection struct {
+	conn   driver.Conn
+	tables *lazyTables
+}
+
+// Prepare implements driver.Conn
+func (c *lazyConnection) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements driver.ConnPrepareContext
+func (c *lazyConnection) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.tables.loadReferenced(ctx, query); err != nil {
+		return nil, err
+	}
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.conn.Prepare(query)
+}
+
+// Close implements driver.Conn
+func (c *lazyConnection) Close() error {
+	return c.conn.Close()
+}
+
+// Begin implements driver.Conn (deprecated, use BeginTx instead)
+func (c *lazyConnection) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements driver.ConnBeginTx. It loads every remaining table first, because
+// a table cannot be loaded while a transaction of another connection writes.
+func (c *lazyConnection) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.tables.loadAll(ctx); err != nil {
+		return nil, err
+	}
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.conn.Begin() //nolint:staticcheck // Need backward compatibility with older drivers
+}
+
+// ExecContext implements driver.ExecerContext
+func (c *lazyConnection) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.tables.loadReferenced(ctx, query); err != nil {
+		return nil, err
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+// QueryContext implements driver.QueryerContext
+func (c *lazyConnection) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.tables.loadReferenced(ctx, query); err != nil {
+		return nil, err
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+// CheckNamedValue implements driver.NamedValueChecker
+func (c *lazyConnection) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// ResetSession implements driver.SessionResetter
+func (c *lazyConnection) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableLazyLoading(t *testing.T) {
+	t.Parallel()
+
+	// writeFiles writes files into a new directory and returns it
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+		}
+		return dir
+	}
+	// tables returns the loaded tables and the tables that are not loaded yet
+	tables := func(t *testing.T, db *sql.DB) (loaded, pending []string) {
+		t.Helper()
+
+		return queryRows(t, db, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE '\\_filesql\\_%' ESCAPE '\\' ORDER BY name"),
+			queryRows(t, db, "SELECT table_name FROM _filesql_lazy_tables ORDER BY table_name")
+	}
+
+	t.Run("files are loaded when their table is first referenced", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t, map[string]string{
+			"orders.csv":    "id,total\n1,50\n2,150\n",
+			"customers.tsv": "id\tname\n1\talice\n",
+			"events.jsonl":  `{"id": 1}` + "\n",
+		})
+		db := openTestDB(t, NewBuilder().AddPath(dir).EnableLazyLoading())
+
+		loaded, pending := tables(t, db)
+		assert.Empty(t, loaded)
+		assert.Equal(t, []string{"customers", "events", "orders"}, pending)
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM Orders WHERE total > 100").Scan(&count))
+		assert.Equal(t, 1, count)
+		loaded, pending = tables(t, db)
+		assert.Equal(t, []string{"orders"}, loaded)
+		assert.Equal(t, []string{"customers", "events"}, pending)
+
+		// Quoted names, prepared statements, and statements that write load tables too
+		stmt, err := db.PrepareContext(context.Background(), `SELECT name FROM "customers" WHERE id = ?`)
+		require.NoError(t, err)
+		defer stmt.Close()
+		var name string
+		require.NoError(t, stmt.QueryRowContext(context.Background(), 1).Scan(&name))
+		assert.Equal(t, "alice", name)
+		_, err = db.ExecContext(context.Background(), "INSERT INTO [events] VALUES (2)")
+		require.NoError(t, err)
+
+		loaded, pending = tables(t, db)
+		assert.Equal(t, []string{"customers", "events", "orders"}, loaded)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("names in string literals and comments do not load tables", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t, map[string]string{"orders.csv": "id\n1\n"})
+		db := openTestDB(t, NewBuilder().AddPath(dir).EnableLazyLoading())
+
+		var value string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT 'orders' -- orders\n/* orders */").Scan(&value))
+		loaded, _ := tables(t, db)
+		assert.Empty(t, loaded)
+	})
+
+	t.Run("transactions and LoadLazyTables load every table", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t, map[string]string{"a.csv": "id\n1\n", "b.csv": "id\n2\n"})
+		db := openTestDB(t, NewBuilder().AddPath(dir).EnableLazyLoading())
+		tx, err := db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+		loaded, _ := tables(t, db)
+		assert.Equal(t, []string{"a", "b"}, loaded)
+
+		db = openTestDB(t, NewBuilder().AddPath(dir).EnableLazyLoading())
+		require.NoError(t, LoadLazyTables(context.Background(), db))
+		loaded, pending := tables(t, db)
+		assert.Equal(t, []string{"a", "b"}, loaded)
+		assert.Empty(t, pending)
+
+		// Databases without lazy loading have nothing to load
+		require.NoError(t, LoadLazyTables(context.Background(), openTestDB(t, NewBuilder().AddPath(dir))))
+	})
+
+	t.Run("errors of a file are returned by the statement that loads it", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t, map[string]string{"good.csv": "id\n1\n", "bad.csv": "id\n1\n"})
+		db := openTestDB(t, NewBuilder().AddPath(dir).EnableLazyLoading())
+		require.NoError(t, os.Remove(filepath.Join(dir, "bad.csv")))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM good").Scan(&count))
+		assert.Equal(t, 1, count)
+		err := db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM bad").Scan(&count)
+		assert.Equal(t, ErrCodePathNotFound, ErrorCodeOf(err))
+
+		// The failed table can be retried and is still registered
+		_, pending := tables(t, db)
+		assert.Equal(t, []string{"bad"}, pending)
+	})
+
+	t.Run("configuration", func(t *testing.T) {
+		t.Parallel()
+
+		builder, err := NewBuilderFromConfig(BuilderConfig{LazyLoading: true})
+		require.NoError(t, err)
+		assert.True(t, builder.lazyLoading)
+
+		_, err = NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")).
+			EnableLazyLoading().
+			EnableAutoSave(t.TempDir()).
+			Build(context.Background())
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+
+		dir := writeFiles(t, map[string]string{"users.csv": "id\n1\n"})
+		_, err = tryOpenTestDB(t, NewBuilder().
+			AddPath(filepath.Join(dir, "users.csv")).
+			AddReader(strings.NewReader("id\n2\n"), "users", FileTypeCSV).
+			EnableLazyLoading())
+		assert.Equal(t, ErrCodeDuplicateTable, ErrorCodeOf(err))
+	})
+}
@@ -0,0 +1,69 @@
+package filesql
+
+// Limits of SQLite and filesql that loaded data must stay within
+const (
+	// MaxColumns is the maximum number of columns of a table (SQLITE_MAX_COLUMN of the
+	// SQLite driver). Wider inputs fail to load with ErrTooManyColumns unless
+	// EnableColumnOverflow is set.
+	MaxColumns = maxSQLiteColumns
+	// MaxCellBytes is the maximum size of a single value in bytes (SQLITE_MAX_LENGTH).
+	// Larger values fail to load with ErrCellTooLarge unless EnableCellTruncation is set.
+	MaxCellBytes = maxSQLiteLength
+	// DefaultMemoryLimitMB is the heap limit SetMemoryLimit uses for values of 0 or less
+	DefaultMemoryLimitMB = defaultMemoryLimit
+	// MaxMemoryLimitMB is the largest heap limit SetMemoryLimit accepts; larger values are lowered to it
+	MaxMemoryLimitMB = maxReasonableMemoryLimit
+)
+
+// LoadLimits are the maximums that apply while inputs are loaded.
+type LoadLimits struct {
+	// MaxColumns is the maximum number of columns of a table
+	MaxColumns int `json:"max_columns"`
+	// ColumnOverflow reports whether columns beyond MaxColumns are stored as JSON instead of failing the load
+	ColumnOverflow bool `json:"column_overflow"`
+	// MaxCellBytes is the maximum size of a single value in bytes
+	MaxCellBytes int `json:"max_cell_bytes"`
+	// CellTruncation reports whether larger values are truncated instead of failing the load
+	CellTruncation bool `json:"cell_truncation"`
+	// MemoryLimitMB is the heap size in megabytes above which loading fails; 0 means no limit
+	MemoryLimitMB int64 `json:"memory_limit_mb"`
+	// RowsPerChunk is the number of rows inserted per chunk
+	RowsPerChunk int `json:"rows_per_chunk"`
+	// MinChunkSize is the smallest accepted number of rows per chunk
+	MinChunkSize int `json:"min_chunk_size"`
+}
+
+// Limits returns the limits that apply to a builder without options that change them.
+//
+// Applications that accept uploads can check files against them up front, for example
+// rejecting a CSV file whose header has more than MaxColumns fields, instead of
+// discovering the limits through errors of Open. DBBuilder.Limits returns the limits
+// of a configured builder.
+//
+// Example:
+//
+//	limits := filesql.Limits()
+//	if len(header) > limits.MaxColumns {
+//		return fmt.Errorf("at most %d columns are supported", limits.MaxColumns)
+//	}
+func Limits() LoadLimits {
+	return NewBuilder().Limits()
+}
+
+// Limits returns the limits that apply when the builder loads its inputs, taking
+// options such as EnableColumnOverflow and SetMemoryLimit into account.
+func (b *DBBuilder) Limits() LoadLimits {
+	sp := b.streamProcessor
+	limits := LoadLimits{
+		MaxColumns:     MaxColumns,
+		ColumnOverflow: sp.columnOverflow,
+		MaxCellBytes:   sp.cellSizeLimit(),
+		CellTruncation: sp.truncateCells,
+		RowsPerChunk:   NewChunkSize(sp.chunkSize).Int(),
+		MinChunkSize:   MinChunkSize,
+	}
+	if sp.memoryLimit != nil && sp.memoryLimit.IsEnabled() {
+		limits.MemoryLimitMB = sp.memoryLimit.maxMemoryMB
+	}
+	return limits
+}
@@ -0,0 +1,52 @@
+package filesql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, LoadLimits{
+			MaxColumns:   2000,
+			MaxCellBytes: 1_000_000_000,
+			RowsPerChunk: DefaultRowsPerChunk,
+			MinChunkSize: MinChunkSize,
+		}, Limits())
+	})
+
+	t.Run("builder options change the effective limits", func(t *testing.T) {
+		t.Parallel()
+
+		limits := NewBuilder().
+			EnableColumnOverflow().
+			EnableCellTruncation().
+			SetMemoryLimit(MaxMemoryLimitMB * 2).
+			Limits()
+		assert.True(t, limits.ColumnOverflow)
+		assert.True(t, limits.CellTruncation)
+		assert.Equal(t, int64(MaxMemoryLimitMB), limits.MemoryLimitMB)
+
+		assert.Equal(t, int64(DefaultMemoryLimitMB), NewBuilder().SetMemoryLimit(0).Limits().MemoryLimitMB)
+	})
+
+	t.Run("inputs beyond MaxColumns fail to load", func(t *testing.T) {
+		t.Parallel()
+
+		columns := make([]string, Limits().MaxColumns+1)
+		for i := range columns {
+			columns[i] = fmt.Sprintf("c%d", i)
+		}
+		_, err := tryOpenTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(strings.Join(columns, ",")+"\n"+strings.Repeat("1,", len(columns)-1)+"1\n"), "wide", FileTypeCSV))
+		assert.True(t, errors.Is(err, ErrTooManyColumns), err)
+	})
+}
@@ -0,0 +1,256 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// lineageTableName is the metadata table that stores column lineage of derived tables
+const lineageTableName = "_filesql_lineage"
+
+// metadataTablePrefix is the prefix of internal tables that are hidden from dumps and schemas
+const metadataTablePrefix = "_filesql_"
+
+// derivedTable represents a table created from a SQL query after all inputs are loaded
+type derivedTable struct {
+	// name is the name of the derived table
+	name string
+	// query is the SELECT statement that produces the derived table
+	query string
+}
+
+// ColumnLineage describes one source column that feeds a column of a derived table.
+type ColumnLineage struct {
+	// DerivedTable is the table created by WithDerivedTable
+	DerivedTable string
+	// DerivedColumn is the column of the derived table
+	DerivedColumn string
+	// SourceTable is the loaded table the column is read from
+	SourceTable string
+	// SourceColumn is the column of SourceTable. It is empty when the derived column
+	// is computed from an expression that could not be traced to a single column.
+	SourceColumn string
+	// SourceFile is the file path SourceTable was loaded from.
+	// It is empty for tables loaded with AddReader or AddFS.
+	SourceFile string
+}
+
+// WithDerivedTable creates a table from a SQL query once all inputs have been loaded.
+//
+// filesql records which source tables, columns, and files feed each column of the
+// derived table. Use GetLineage to retrieve this information, for example to satisfy
+// audit requirements. Lineage is traced by column name and "column AS alias" select
+// items; columns computed from other expressions are attributed to every table the
+// query reads with an empty SourceColumn.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("users.csv").
+//		AddPath("orders.csv").
+//		WithDerivedTable("user_orders", `
+//			SELECT u.name AS customer, o.amount
+//			FROM users u JOIN orders o ON o.user_id = u.id`)
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithDerivedTable(name, query string) *DBBuilder {
+	b.derivedTables = append(b.derivedTables, derivedTable{name: name, query: query})
+	return b
+}
+
+// GetLineage returns the column lineage of all derived tables in db.
+// It returns an empty slice when the database has no derived tables.
+//
+// Example:
+//
+//	lineage, err := filesql.GetLineage(db)
+//	for _, l := range lineage {
+//		fmt.Printf("%s.%s <- %s.%s (%s)\n",
+//			l.DerivedTable, l.DerivedColumn, l.SourceTable, l.SourceColumn, l.SourceFile)
+//	}
+func GetLineage(db *sql.DB) ([]ColumnLineage, error) {
+	ctx := context.Background()
+	lineage := make([]ColumnLineage, 0)
+
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+		lineageTableName,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check lineage table: %w", err)
+	}
+	if exists == 0 {
+		return lineage, nil
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`SELECT derived_table, derived_column, source_table, source_column, source_file FROM "%s" ORDER BY rowid`,
+		lineageTableName,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lineage: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l ColumnLineage
+		if err := rows.Scan(&l.DerivedTable, &l.DerivedColumn, &l.SourceTable, &l.SourceColumn, &l.SourceFile); err != nil {
+			return nil, fmt.Errorf("failed to scan lineage: %w", err)
+		}
+		lineage = append(lineage, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lineage: %w", err)
+	}
+	return lineage, nil
+}
+
+// createDerivedTables creates every configured derived table and records its lineage
+func (b *DBBuilder) createDerivedTables(ctx context.Context, db *sql.DB) error {
+	if len(b.derivedTables) == 0 {
+		return nil
+	}
+
+	sourceTables, err := getSQLiteTableNames(db)
+	if err != nil {
+		return fmt.Errorf("failed to get table names: %w", err)
+	}
+	sourceColumns := make(map[string][]string, len(sourceTables))
+	for _, tableName := range sourceTables {
+		columns, err := getSQLiteTableColumns(db, tableName)
+		if err != nil {
+			return fmt.Errorf("failed to get columns for table %s: %w", tableName, err)
+		}
+		sourceColumns[tableName] = columns
+	}
+	sourceFiles := b.sourceFilesByTable(sourceTables)
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (derived_table TEXT, derived_column TEXT, source_table TEXT, source_column TEXT, source_file TEXT)`,
+		lineageTableName,
+	)); err != nil {
+		return fmt.Errorf("failed to create lineage table: %w", err)
+	}
+
+	for _, derived := range b.derivedTables {
+		if _, ok := sourceColumns[derived.name]; ok {
+			return fmt.Errorf("derived table '%s' conflicts with a loaded table", derived.name)
+		}
+
+		query := fmt.Sprintf(`CREATE TABLE "%s" AS %s`, derived.name, derived.query)
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create derived table %s: %w", derived.name, err)
+		}
+
+		derivedColumns, err := getSQLiteTableColumns(db, derived.name)
+		if err != nil {
+			return fmt.Errorf("failed to get columns for derived table %s: %w", derived.name, err)
+		}
+
+		lineage := traceLineage(derived, derivedColumns, sourceColumns, sourceFiles)
+		if err := insertLineage(ctx, db, lineage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceFilesByTable maps each loaded table to the file path it was loaded from
+func (b *DBBuilder) sourceFilesByTable(tableNames []string) map[string]string {
+	sourceFiles := make(map[string]string, len(tableNames))
+	for _, path := range b.collectedPaths {
+		baseName := b.streamProcessor.fileTableName(path)
+		if baseType := newFile(path).getFileType().baseType(); baseType != FileTypeXLSX && baseType != FileTypeXLS && baseType != FileTypeODS {
+			sourceFiles[baseName] = path
+			continue
+		}
+		// Each Excel or ODS sheet becomes a "<file>_<sheet>" table
+		prefix := sanitizeTableName(baseName) + "_"
+		for _, tableName := range tableNames {
+			if strings.HasPrefix(tableName, prefix) {
+				sourceFiles[tableName] = path
+			}
+		}
+	}
+	return sourceFiles
+}
+
+// traceLineage matches the columns of a derived table with the source columns its query reads
+func traceLineage(derived derivedTable, derivedColumns []string, sourceColumns map[string][]string, sourceFiles map[string]string) []ColumnLineage {
+	// identifierPattern matches SQL identifiers, optionally quoted
+	identifierPattern := regexp.MustCompile("[\"`\\[]?([A-Za-z_][A-Za-z0-9_]*)[\"`\\]]?")
+	// aliasPattern matches "column AS alias" and "table.column AS alias" select items
+	aliasPattern := regexp.MustCompile("(?i)(?:[\"`]?[A-Za-z_][A-Za-z0-9_]*[\"`]?\\.)?[\"`]?([A-Za-z_][A-Za-z0-9_]*)[\"`]?\\s+AS\\s+[\"`]?([A-Za-z_][A-Za-z0-9_]*)[\"`]?")
+
+	// Tables referenced by the query, in order of first appearance
+	referenced := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range identifierPattern.FindAllStringSubmatch(derived.query, -1) {
+		name := match[1]
+		if _, ok := sourceColumns[name]; ok && !seen[name] {
+			seen[name] = true
+			referenced = append(referenced, name)
+		}
+	}
+
+	aliases := make(map[string]string)
+	for _, match := range aliasPattern.FindAllStringSubmatch(derived.query, -1) {
+		aliases[match[2]] = match[1]
+	}
+
+	lineage := make([]ColumnLineage, 0, len(derivedColumns))
+	for _, column := range derivedColumns {
+		sourceName := column
+		if original, ok := aliases[column]; ok {
+			sourceName = original
+		}
+
+		traced := false
+		for _, tableName := range referenced {
+			for _, sourceColumn := range sourceColumns[tableName] {
+				if sourceColumn != sourceName {
+					continue
+				}
+				lineage = append(lineage, ColumnLineage{
+					DerivedTable:  derived.name,
+					DerivedColumn: column,
+					SourceTable:   tableName,
+					SourceColumn:  sourceColumn,
+					SourceFile:    sourceFiles[tableName],
+				})
+				traced = true
+			}
+		}
+		if traced {
+			continue
+		}
+
+		// Computed column: it may depend on any table the query reads
+		for _, tableName := range referenced {
+			lineage = append(lineage, ColumnLineage{
+				DerivedTable:  derived.name,
+				DerivedColumn: column,
+				SourceTable:   tableName,
+				SourceFile:    sourceFiles[tableName],
+			})
+		}
+	}
+	return lineage
+}
+
+// insertLineage stores lineage entries in the lineage metadata table
+func insertLineage(ctx context.Context, db *sql.DB, lineage []ColumnLineage) error {
+	query := fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`INSERT INTO "%s" (derived_table, derived_column, source_table, source_column, source_file) VALUES (?, ?, ?, ?, ?)`,
+		lineageTableName,
+	)
+	for _, l := range lineage {
+		if _, err := db.ExecContext(ctx, query, l.DerivedTable, l.DerivedColumn, l.SourceTable, l.SourceColumn, l.SourceFile); err != nil {
+			return fmt.Errorf("failed to record lineage for %s.%s: %w", l.DerivedTable, l.DerivedColumn, err)
+		}
+	}
+	return nil
+}
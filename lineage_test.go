@@ -0,0 +1,95 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithDerivedTable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("derived table records column lineage to source files", func(t *testing.T) {
+		t.Parallel()
+
+		tmpDir := t.TempDir()
+		usersPath := filepath.Join(tmpDir, "users.csv")
+		ordersPath := filepath.Join(tmpDir, "orders.csv")
+		require.NoError(t, os.WriteFile(usersPath, []byte("id,name\n1,Alice\n2,Bob\n"), 0600))
+		require.NoError(t, os.WriteFile(ordersPath, []byte("user_id,amount\n1,100\n2,200\n"), 0600))
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddPath(usersPath).
+			AddPath(ordersPath).
+			WithDerivedTable("user_orders", `
+				SELECT u.name AS customer, o.amount, o.amount * 2 AS doubled
+				FROM users u JOIN orders o ON o.user_id = u.id`))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_orders").Scan(&count))
+		assert.Equal(t, 2, count)
+
+		lineage, err := GetLineage(db)
+		require.NoError(t, err)
+		assert.Equal(t, []ColumnLineage{
+			{DerivedTable: "user_orders", DerivedColumn: "customer", SourceTable: "users", SourceColumn: "name", SourceFile: usersPath},
+			{DerivedTable: "user_orders", DerivedColumn: "amount", SourceTable: "orders", SourceColumn: "amount", SourceFile: ordersPath},
+			{DerivedTable: "user_orders", DerivedColumn: "doubled", SourceTable: "users", SourceFile: usersPath},
+			{DerivedTable: "user_orders", DerivedColumn: "doubled", SourceTable: "orders", SourceFile: ordersPath},
+		}, lineage)
+	})
+
+	t.Run("lineage table is hidden from dumps and schema", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,Alice\n"), "users", FileTypeCSV).
+			WithDerivedTable("names", "SELECT name FROM users"))
+
+		schema, err := GetSchema(db)
+		require.NoError(t, err)
+		require.Len(t, schema.Tables, 2)
+		assert.Equal(t, "names", schema.Tables[0].Name)
+		assert.Equal(t, "users", schema.Tables[1].Name)
+
+		lineage, err := GetLineage(db)
+		require.NoError(t, err)
+		assert.Equal(t, []ColumnLineage{
+			{DerivedTable: "names", DerivedColumn: "name", SourceTable: "users", SourceColumn: "name"},
+		}, lineage)
+	})
+
+	t.Run("database without derived tables has no lineage", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+		lineage, err := GetLineage(db)
+		require.NoError(t, err)
+		assert.Empty(t, lineage)
+	})
+
+	errorTests := []struct {
+		name  string
+		query string
+	}{
+		{name: "empty query is rejected at build time", query: " "},
+		{name: "invalid query fails open", query: "SELECT * FROM missing_table"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tryOpenTestDB(t, NewBuilder().
+				AddPath(filepath.Join("testdata", "sample.csv")).
+				WithDerivedTable("derived", tt.query))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// LoadProgress is a point-in-time snapshot of a background load started with StartLoad.
+type LoadProgress struct {
+	// TotalInputs is the number of file paths and reader inputs to load
+	TotalInputs int
+	// CompletedInputs is the number of inputs that have been fully loaded
+	CompletedInputs int
+	// CurrentInput is the file path (or reader table name) currently being loaded
+	CurrentInput string
+	// RowsInserted is the total number of rows inserted so far across all tables
+	RowsInserted int64
+	// BytesRead is the total number of (decompressed) bytes read from inputs so far
+	BytesRead int64
+	// TablesLoaded lists the tables that have been completely loaded, in load order
+	TablesLoaded []string
+}
+
+// LoadJob is a handle to a database load running in the background.
+//
+// A LoadJob is created by DBBuilder.StartLoad. It lets GUIs and servers show
+// progress, cancel a slow load, or wait for completion without blocking in Open.
+//
+// Tables become queryable through DB() as soon as each of them finishes loading,
+// which keeps interactive sessions over directories with many files responsive.
+// Tables are loaded under a staging name and only published under their final name
+// once complete, so a query never observes a partially loaded table.
+//
+// Example:
+//
+//	job, err := validatedBuilder.StartLoad(ctx)
+//	if err != nil {
+//		return err
+//	}
+//
+//	ticker := time.NewTicker(time.Second)
+//	defer ticker.Stop()
+//	for {
+//		select {
+//		case <-job.Done():
+//			db, err := job.Wait()
+//			// use db...
+//		case <-ticker.C:
+//			p := job.Progress()
+//			fmt.Printf("%d/%d inputs, %d rows\n", p.CompletedInputs, p.TotalInputs, p.RowsInserted)
+//		}
+//	}
+//
+// Thread Safety: All methods are safe for concurrent use by multiple goroutines.
+type LoadJob struct {
+	db     *sql.DB
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.RWMutex
+	progress LoadProgress
+	err      error
+	// ready contains the tables that have been completely loaded
+	ready map[string]bool
+	// tableLoadedCh is closed and replaced every time a table finishes loading
+	tableLoadedCh chan struct{}
+}
+
+// StartLoad starts loading the configured inputs in the background and returns immediately.
+//
+// Like Open, it can only be called after Build() has been successfully executed.
+// The load is bound to ctx: cancelling ctx (or calling LoadJob.Cancel) stops loading
+// at the next input or chunk boundary. When the job fails or is cancelled, the database
+// is closed and Wait returns the error.
+//
+// Example:
+//
+//	job, err := validatedBuilder.StartLoad(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer job.Cancel()
+//
+//	db, err := job.Wait()
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+func (b *DBBuilder) StartLoad(ctx context.Context) (*LoadJob, error) {
+	if b.persistentDB != "" {
+		return nil, newCodedError(ErrCodeInvalidConfig, "StartLoad does not support WithPersistentDB; use Open")
+	}
+	if err := b.validator.validateBuilt(b.validated); err != nil {
+		return nil, withErrorCode(err, ErrCodeInvalidConfig)
+	}
+	if err := b.validator.validateInputsAvailable(b.collectedPaths, b.readers); err != nil {
+		return nil, withErrorCode(err, ErrCodeInvalidConfig)
+	}
+	if err := b.resources.claim(); err != nil {
+		return nil, withErrorCode(err, ErrCodeLoadFailed)
+	}
+
+	references, err := loadReferences(ctx, b.references)
+	if err != nil {
+		b.resources.release()
+		return nil, withErrorCode(err, ErrCodeLoadFailed)
+	}
+	references = append(references, b.attachedSQLiteDBs()...)
+
+	db, autoSave, err := b.createDatabase(references, nil)
+	if err != nil {
+		b.resources.release()
+		return nil, withErrorCode(err, ErrCodeLoadFailed)
+	}
+	jobCtx, cancel := context.WithCancel(ctx)
+	job := &LoadJob{
+		db:     db,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		progress: LoadProgress{
+			TotalInputs: len(b.collectedPaths) + len(b.readers),
+		},
+		ready:         make(map[string]bool),
+		tableLoadedCh: make(chan struct{}),
+	}
+
+	go job.run(jobCtx, b, autoSave)
+
+	return job, nil
+}
+
+// run performs the load and records its outcome
+func (j *LoadJob) run(ctx context.Context, b *DBBuilder, autoSave *autoSaveConnector) {
+	defer close(j.done)
+	defer j.cancel()
+	defer b.resources.release()
+
+	err := b.loadInputs(ctx, j.db, b.streamProcessor.withObserver(j).withStaging())
+	if err == nil {
+		err = checkReferenceTables(ctx, j.db, b.references)
+	}
+	if err == nil {
+		err = checkSQLiteDBTables(ctx, j.db, b.sqliteDBs, b.references)
+	}
+	if err == nil {
+		err = b.validateDatabaseConnection(ctx, j.db)
+	} else {
+		_ = j.db.Close() // Ignore close error during error handling
+	}
+	if err == nil {
+		if err = autoSave.start(ctx); err != nil {
+			_ = j.db.Close() // Ignore close error during error handling
+			err = fmt.Errorf("failed to start auto-save: %w", err)
+		}
+	}
+
+	j.mu.Lock()
+	j.err = withErrorCode(err, ErrCodeLoadFailed)
+	j.progress.CurrentInput = ""
+	j.mu.Unlock()
+}
+
+// Progress returns a snapshot of the current loading progress.
+func (j *LoadJob) Progress() LoadProgress {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	p := j.progress
+	p.TablesLoaded = append([]string(nil), j.progress.TablesLoaded...)
+	return p
+}
+
+// Cancel stops the background load. It is safe to call Cancel multiple times
+// and after the job has finished.
+func (j *LoadJob) Cancel() {
+	j.cancel()
+}
+
+// Done returns a channel that is closed when the load has finished,
+// either successfully, with an error, or because it was cancelled.
+func (j *LoadJob) Done() <-chan struct{} {
+	return j.done
+}
+
+// Err returns the error that ended the load, or nil if the load is still
+// running or finished successfully.
+func (j *LoadJob) Err() error {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.err
+}
+
+// Wait blocks until the load has finished and returns the loaded database.
+// The caller is responsible for closing the returned database.
+func (j *LoadJob) Wait() (*sql.DB, error) {
+	<-j.done
+	if err := j.Err(); err != nil {
+		return nil, err
+	}
+	return j.db, nil
+}
+
+// DB returns the database that is being loaded.
+//
+// Unlike Wait, DB does not block: tables that have finished loading can be
+// queried immediately while the remaining inputs are still being ingested.
+// Use WaitForTables to wait until specific tables are available. Queries and
+// the loader use separate connections; the loader only waits for a query to
+// finish before it publishes a table.
+//
+// If the load fails or is cancelled, the database is closed.
+// The caller is responsible for closing the database after a successful load.
+func (j *LoadJob) DB() *sql.DB {
+	return j.db
+}
+
+// TableReady reports whether the named table has been completely loaded.
+func (j *LoadJob) TableReady(tableName string) bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.ready[tableName]
+}
+
+// WaitForTables blocks until all named tables have been completely loaded.
+//
+// It returns an error if ctx is done first, if the load fails, or if the load
+// finishes without producing one of the tables.
+//
+// Example:
+//
+//	job, _ := validatedBuilder.StartLoad(ctx)
+//	if err := job.WaitForTables(ctx, "users"); err != nil {
+//		return err
+//	}
+//	rows, err := job.DB().QueryContext(ctx, "SELECT * FROM users")
+func (j *LoadJob) WaitForTables(ctx context.Context, tableNames ...string) error {
+	for {
+		j.mu.RLock()
+		missing := ""
+		for _, name := range tableNames {
+			if !j.ready[name] {
+				missing = name
+				break
+			}
+		}
+		changed := j.tableLoadedCh
+		j.mu.RUnlock()
+
+		if missing == "" {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		case <-j.done:
+			if err := j.Err(); err != nil {
+				return err
+			}
+			if !j.TableReady(missing) {
+				return fmt.Errorf("table %s was not loaded", missing)
+			}
+		}
+	}
+}
+
+// inputStarted implements loadObserver
+func (j *LoadJob) inputStarted(input string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress.CurrentInput = input
+}
+
+// bytesRead implements loadObserver
+func (j *LoadJob) bytesRead(n int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress.BytesRead += int64(n)
+}
+
+// rowsInserted implements loadObserver
+func (j *LoadJob) rowsInserted(_ string, rows int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress.RowsInserted += int64(rows)
+}
+
+// tableLoaded implements loadObserver
+func (j *LoadJob) tableLoaded(table string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress.TablesLoaded = append(j.progress.TablesLoaded, table)
+	j.ready[table] = true
+	close(j.tableLoadedCh)
+	j.tableLoadedCh = make(chan struct{})
+}
+
+// inputFinished implements loadObserver
+func (j *LoadJob) inputFinished(_ string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress.CompletedInputs++
+}
@@ -0,0 +1,193 @@
+package filesql
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_StartLoad(t *testing.T) {
+	t.Parallel()
+
+	t.Run("load completes and reports progress", func(t *testing.T) {
+		t.Parallel()
+
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "users.csv"), []byte("id,name\n1,Alice\n2,Bob\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "orders.csv"), []byte("id,amount\n1,100\n"), 0600))
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().AddPath(tmpDir).Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+
+		select {
+		case <-job.Done():
+		default:
+			t.Fatal("Done channel should be closed after Wait returns")
+		}
+
+		progress := job.Progress()
+		assert.Equal(t, 2, progress.TotalInputs)
+		assert.Equal(t, 2, progress.CompletedInputs)
+		assert.Equal(t, int64(3), progress.RowsInserted)
+		assert.ElementsMatch(t, []string{"users", "orders"}, progress.TablesLoaded)
+		assert.Empty(t, progress.CurrentInput)
+		require.NoError(t, job.Err())
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count))
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("cancelled context stops the load", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,Alice\n"), "users", FileTypeCSV).
+			Build(ctx)
+		require.NoError(t, err)
+
+		cancel()
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+
+		db, err := job.Wait()
+		assert.Nil(t, db)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled), "error should wrap context.Canceled: %v", err)
+		assert.Equal(t, err, job.Err())
+	})
+
+	t.Run("cancel is safe after completion", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,Alice\n"), "users", FileTypeCSV).
+			Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+
+		select {
+		case <-job.Done():
+		case <-time.After(30 * time.Second):
+			t.Fatal("load did not finish in time")
+		}
+		job.Cancel()
+		job.Cancel()
+
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+	})
+
+	t.Run("start load without build fails", func(t *testing.T) {
+		t.Parallel()
+
+		job, err := NewBuilder().AddPath("testdata/sample.csv").StartLoad(context.Background())
+		assert.Nil(t, job)
+		require.Error(t, err)
+	})
+}
+
+func TestLoadJob_WaitForTables(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tables are queryable through DB once ready", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,Alice\n2,Bob\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id,amount\n1,100\n"), "orders", FileTypeCSV).
+			Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+		defer job.DB().Close()
+
+		require.NoError(t, job.WaitForTables(ctx, "users"))
+		assert.True(t, job.TableReady("users"))
+
+		var count int
+		require.NoError(t, job.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count))
+		assert.Equal(t, 2, count)
+
+		require.NoError(t, job.WaitForTables(ctx, "users", "orders"))
+		_, err = job.Wait()
+		require.NoError(t, err)
+	})
+
+	t.Run("staging tables are not left behind", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")).Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+
+		assert.Equal(t, []string{"sample"}, queryRows(t, db, "SELECT name FROM sqlite_master WHERE type='table'"))
+	})
+
+	t.Run("unknown table returns error after load finishes", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,Alice\n"), "users", FileTypeCSV).
+			Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+		defer job.DB().Close()
+
+		err = job.WaitForTables(ctx, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing")
+		assert.False(t, job.TableReady("missing"))
+	})
+
+	t.Run("context cancellation stops waiting", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,Alice\n"), "users", FileTypeCSV).
+			Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+		defer job.DB().Close()
+
+		waitCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		err = job.WaitForTables(waitCtx, "missing")
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,211 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// loadSummaryTableName is the metadata table that stores the per-table load summary
+const loadSummaryTableName = "_filesql_load_summary"
+
+// TableLoadSummary describes how one table was loaded.
+type TableLoadSummary struct {
+	// Name is the table name
+	Name string `json:"name"`
+	// Input is the file path, or the table name for reader inputs, the table was loaded from
+	Input string `json:"input"`
+	// Rows is the number of rows inserted into the table
+	Rows int64 `json:"rows"`
+	// Bytes is the number of (decompressed) bytes read from the input while the table was loaded
+	Bytes int64 `json:"bytes"`
+	// StartedAt is when loading the table started
+	StartedAt time.Time `json:"started_at"`
+	// Duration is how long loading the table took, encoded in JSON as nanoseconds
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// LoadSummary is the machine-readable report of one database load.
+//
+// Every field has a stable JSON name, so orchestration systems can log the summary
+// as is and alert on anomalies such as a table with zero rows or new warnings.
+type LoadSummary struct {
+	// Tables contains the tables created from inputs, in load order
+	Tables []TableLoadSummary `json:"tables"`
+	// Rows is the total number of rows inserted into the tables
+	Rows int64 `json:"rows"`
+	// Bytes is the total number of bytes read from the inputs
+	Bytes int64 `json:"bytes"`
+	// Duration is the total time spent loading the tables, encoded in JSON as nanoseconds
+	Duration time.Duration `json:"duration_ns"`
+	// Warnings are the warnings raised while loading (see GetLoadWarnings)
+	Warnings []LoadWarning `json:"warnings"`
+}
+
+// EnableLoadSummary records the tables, rows, bytes, and durations of the load.
+//
+// The summary is stored in the "_filesql_load_summary" table, which is excluded
+// from DumpDatabase and auto-save, and is read with GetLoadSummary.
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableLoadSummary() *DBBuilder {
+	b.loadSummary = true
+	return b
+}
+
+// GetLoadSummary returns the summary of the load that created db.
+//
+// The summary is recorded once while inputs are loaded by Open or StartLoad with
+// EnableLoadSummary; without it, Tables is empty. Warnings are always included.
+//
+// Example:
+//
+//	summary, err := filesql.GetLoadSummary(db)
+//	if err != nil {
+//		return err
+//	}
+//	if err := json.NewEncoder(os.Stdout).Encode(summary); err != nil {
+//		return err
+//	}
+//	for _, table := range summary.Tables {
+//		if table.Rows == 0 {
+//			alert.Send("empty table " + table.Name)
+//		}
+//	}
+func GetLoadSummary(db *sql.DB) (*LoadSummary, error) {
+	ctx := context.Background()
+	summary := &LoadSummary{Tables: make([]TableLoadSummary, 0)}
+
+	warnings, err := GetLoadWarnings(db)
+	if err != nil {
+		return nil, err
+	}
+	summary.Warnings = warnings
+
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+		loadSummaryTableName,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check load summary table: %w", err)
+	}
+	if exists == 0 {
+		return summary, nil
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`SELECT table_name, input, rows_stored, bytes_read, started_at, duration_ns FROM "%s" ORDER BY rowid`,
+		loadSummaryTableName,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query load summary: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table TableLoadSummary
+		var startedAt string
+		var duration int64
+		if err := rows.Scan(&table.Name, &table.Input, &table.Rows, &table.Bytes, &startedAt, &duration); err != nil {
+			return nil, fmt.Errorf("failed to scan load summary: %w", err)
+		}
+		table.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse load summary time: %w", err)
+		}
+		table.Duration = time.Duration(duration)
+		summary.Tables = append(summary.Tables, table)
+		summary.Rows += table.Rows
+		summary.Bytes += table.Bytes
+		summary.Duration += table.Duration
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read load summary: %w", err)
+	}
+	return summary, nil
+}
+
+// loadSummaryRecorder is a loadObserver that summarizes every loaded table.
+// It forwards every notification to next, so it can be combined with other observers.
+type loadSummaryRecorder struct {
+	next   loadObserver
+	clock  Clock
+	tables []TableLoadSummary
+	// current accumulates the table being loaded until tableLoaded names it
+	current TableLoadSummary
+}
+
+// newLoadSummaryRecorder creates a recorder that forwards notifications to next (may be nil)
+func newLoadSummaryRecorder(next loadObserver, clock Clock) *loadSummaryRecorder {
+	if clock == nil {
+		clock = NewSystemClock()
+	}
+	return &loadSummaryRecorder{next: next, clock: clock}
+}
+
+// inputStarted implements loadObserver
+func (r *loadSummaryRecorder) inputStarted(input string) {
+	r.current = TableLoadSummary{Input: input, StartedAt: r.clock.Now()}
+	if r.next != nil {
+		r.next.inputStarted(input)
+	}
+}
+
+// bytesRead implements loadObserver
+func (r *loadSummaryRecorder) bytesRead(n int) {
+	r.current.Bytes += int64(n)
+	if r.next != nil {
+		r.next.bytesRead(n)
+	}
+}
+
+// rowsInserted implements loadObserver
+func (r *loadSummaryRecorder) rowsInserted(table string, rows int) {
+	r.current.Rows += int64(rows)
+	if r.next != nil {
+		r.next.rowsInserted(table, rows)
+	}
+}
+
+// tableLoaded implements loadObserver. The next table of the same input, such as
+// the next sheet of a workbook, starts when this one is loaded.
+func (r *loadSummaryRecorder) tableLoaded(table string) {
+	now := r.clock.Now()
+	r.current.Name = table
+	r.current.Duration = now.Sub(r.current.StartedAt)
+	r.tables = append(r.tables, r.current)
+	r.current = TableLoadSummary{Input: r.current.Input, StartedAt: now}
+	if r.next != nil {
+		r.next.tableLoaded(table)
+	}
+}
+
+// inputFinished implements loadObserver
+func (r *loadSummaryRecorder) inputFinished(input string) {
+	if r.next != nil {
+		r.next.inputFinished(input)
+	}
+}
+
+// save stores the recorded summary in the load summary table
+func (r *loadSummaryRecorder) save(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (table_name TEXT, input TEXT, rows_stored INTEGER, bytes_read INTEGER, started_at TEXT, duration_ns INTEGER)`,
+		loadSummaryTableName,
+	)); err != nil {
+		return fmt.Errorf("failed to create load summary table: %w", err)
+	}
+
+	insertQuery := fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`INSERT INTO "%s" (table_name, input, rows_stored, bytes_read, started_at, duration_ns) VALUES (?, ?, ?, ?, ?, ?)`,
+		loadSummaryTableName,
+	)
+	for _, t := range r.tables {
+		if _, err := db.ExecContext(ctx, insertQuery,
+			t.Name, t.Input, t.Rows, t.Bytes, t.StartedAt.UTC().Format(time.RFC3339Nano), int64(t.Duration)); err != nil {
+			return fmt.Errorf("failed to record load summary: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package filesql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLoadSummary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("summarizes every table", func(t *testing.T) {
+		t.Parallel()
+
+		users := "id,name\n1,a\n2,b\n3,c\n"
+		orders := "id;amount\n1;10\n"
+		clock := newFakeClock()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(users), "users", FileTypeCSV).
+			AddReader(strings.NewReader(orders), "orders", FileTypeCSV).
+			WithDelimiterDetection().
+			WithClock(clock).
+			EnableLoadSummary())
+
+		summary, err := GetLoadSummary(db)
+		require.NoError(t, err)
+		startedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, []TableLoadSummary{
+			{Name: "users", Input: "users", Rows: 3, Bytes: int64(len(users)), StartedAt: startedAt},
+			{Name: "orders", Input: "orders", Rows: 1, Bytes: int64(len(orders)), StartedAt: startedAt},
+		}, summary.Tables)
+		assert.Equal(t, int64(4), summary.Rows)
+		assert.Equal(t, int64(len(users)+len(orders)), summary.Bytes)
+		require.Len(t, summary.Warnings, 1)
+		assert.Equal(t, "orders", summary.Warnings[0].Table)
+
+		tableNames, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.NotContains(t, tableNames, loadSummaryTableName, "summary table must be hidden")
+	})
+
+	t.Run("encodes to stable JSON names", func(t *testing.T) {
+		t.Parallel()
+
+		summary := LoadSummary{
+			Tables:   []TableLoadSummary{{Name: "users", Input: "users.csv", Rows: 3, Bytes: 20, Duration: time.Millisecond}},
+			Rows:     3,
+			Bytes:    20,
+			Duration: time.Millisecond,
+			Warnings: []LoadWarning{{Table: "users", Message: "detected"}},
+		}
+		data, err := json.Marshal(summary)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"tables": [{"name": "users", "input": "users.csv", "rows": 3, "bytes": 20,
+				"started_at": "0001-01-01T00:00:00Z", "duration_ns": 1000000}],
+			"rows": 3,
+			"bytes": 20,
+			"duration_ns": 1000000,
+			"warnings": [{"table": "users", "message": "detected"}]
+		}`, string(data))
+	})
+
+	t.Run("without EnableLoadSummary only warnings are reported", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV))
+
+		summary, err := GetLoadSummary(db)
+		require.NoError(t, err)
+		assert.Empty(t, summary.Tables)
+		assert.Empty(t, summary.Warnings)
+		assert.NotNil(t, summary.Warnings)
+	})
+}
@@ -0,0 +1,90 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// loadWarningsTableName is the metadata table that stores warnings raised while loading
+const loadWarningsTableName = "_filesql_load_warnings"
+
+// LoadWarning describes a decision filesql made on its own while loading an input,
+// such as the delimiter chosen by WithDelimiterDetection. The data was loaded, but
+// it may not have been loaded the way the caller expected.
+type LoadWarning struct {
+	// Table is the table the warning applies to
+	Table string `json:"table"`
+	// Message describes the decision
+	Message string `json:"message"`
+}
+
+// GetLoadWarnings returns the warnings raised while loading the inputs of db, in the
+// order they were raised.
+//
+// Warnings are stored in the "_filesql_load_warnings" table, which is excluded from
+// DumpDatabase and auto-save. A load without warnings returns an empty slice.
+//
+// Example:
+//
+//	warnings, err := filesql.GetLoadWarnings(db)
+//	if err != nil {
+//		return err
+//	}
+//	for _, w := range warnings {
+//		log.Printf("%s: %s", w.Table, w.Message)
+//	}
+func GetLoadWarnings(db *sql.DB) ([]LoadWarning, error) {
+	ctx := context.Background()
+	warnings := make([]LoadWarning, 0)
+
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+		loadWarningsTableName,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check load warnings table: %w", err)
+	}
+	if exists == 0 {
+		return warnings, nil
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`SELECT table_name, message FROM "%s" ORDER BY rowid`,
+		loadWarningsTableName,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query load warnings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w LoadWarning
+		if err := rows.Scan(&w.Table, &w.Message); err != nil {
+			return nil, fmt.Errorf("failed to scan load warning: %w", err)
+		}
+		warnings = append(warnings, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read load warnings: %w", err)
+	}
+	return warnings, nil
+}
+
+// recordLoadWarning stores a warning about table in the load warnings table
+func recordLoadWarning(ctx context.Context, db *sql.DB, table, message string) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (table_name TEXT, message TEXT)`,
+		loadWarningsTableName,
+	)); err != nil {
+		return fmt.Errorf("failed to create load warnings table: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`INSERT INTO "%s" (table_name, message) VALUES (?, ?)`,
+		loadWarningsTableName,
+	), table, message); err != nil {
+		return fmt.Errorf("failed to record load warning: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Save saves db right away, the same way auto-save does.
+//
+// Auto-save persists changes when the database is closed or a transaction is
+// committed. Save lets applications persist at moments that matter to them, for
+// example after a user presses "Save" or a batch import finishes, without closing
+// the database or wrapping the work in a transaction. It writes to the directory,
+// with the options and the timeout configured with EnableAutoSave or
+// EnableAutoSaveOnCommit.
+//
+// Example:
+//
+//	// db was opened from a builder configured with EnableAutoSave("./backup")
+//	if _, err := db.Exec("UPDATE inventory SET stock = stock - 1 WHERE id = ?", id); err != nil {
+//		return err
+//	}
+//	if err := filesql.Save(db); err != nil {
+//		return err
+//	}
+//
+// Save returns an error wrapping ErrAutoSaveNotEnabled, with ErrCodeInvalidConfig, if
+// db was not opened with auto-save enabled. Save only persists committed data, so it
+// waits for open transactions that changed a table; do not call it while the calling
+// goroutine has such a transaction open.
+func Save(db *sql.DB) error {
+	return SaveContext(context.Background(), db)
+}
+
+// SaveContext is like Save, but stops saving when ctx is done. Tables that have already
+// been written are kept, like with the auto-save timeout.
+func SaveContext(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return withErrorCode(fmt.Errorf("failed to get connection: %w", err), ErrCodeDumpFailed)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*autoSaveConnection)
+		if !ok || !c.connector.autoSaveConfig.enabled {
+			return ErrAutoSaveNotEnabled
+		}
+		return c.connector.save(ctx)
+	})
+	return withErrorCode(err, ErrCodeDumpFailed)
+}
@@ -0,0 +1,81 @@
+package filesql
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSave(t *testing.T) {
+	t.Parallel()
+
+	t.Run("saves without closing the database", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,stock\n1,5\n"), "inventory", FileTypeCSV).
+			EnableAutoSave("backup", NewDumpOptions().WithFormat(OutputFormatTSV)).
+			WithFileSystem(fsys))
+
+		_, err := db.ExecContext(context.Background(), "UPDATE inventory SET stock = stock - 1")
+		require.NoError(t, err)
+		require.NoError(t, Save(db))
+
+		content, ok := fsys.content(filepath.Join("backup", "inventory.tsv"))
+		require.True(t, ok)
+		assert.Equal(t, "id\tstock\n1\t4\n", content)
+
+		// The database is still usable
+		var stock int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT stock FROM inventory").Scan(&stock))
+		assert.Equal(t, 4, stock)
+	})
+
+	t.Run("works with auto-save on commit", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "inventory", FileTypeCSV).
+			EnableAutoSaveOnCommit("backup").
+			WithFileSystem(fsys))
+
+		// Statements outside of transactions do not trigger auto-save on commit
+		_, err := db.ExecContext(context.Background(), "INSERT INTO inventory VALUES (2)")
+		require.NoError(t, err)
+		require.NoError(t, Save(db))
+
+		content, ok := fsys.content(filepath.Join("backup", "inventory.csv"))
+		require.True(t, ok)
+		assert.Equal(t, "id\n1\n2\n", content)
+	})
+
+	t.Run("fails without auto-save", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id\n1\n"), "inventory", FileTypeCSV))
+
+		err := Save(db)
+		require.ErrorIs(t, err, ErrAutoSaveNotEnabled)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+
+	t.Run("a cancelled context stops the save", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "inventory", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithFileSystem(newMemoryFileSystem()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := SaveContext(ctx, db)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,236 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// loadMetricsTableName is the metadata table that stores per-input load accounting
+const loadMetricsTableName = "_filesql_load_metrics"
+
+// InputLoadMetrics is the resource accounting of a single file path or reader input.
+type InputLoadMetrics struct {
+	// Input is the file path, or the table name for reader inputs
+	Input string
+	// Tables are the tables created from the input
+	Tables []string
+	// BytesParsed is the number of (decompressed) bytes read from the input
+	BytesParsed int64
+	// RowsStored is the number of rows inserted into the database
+	RowsStored int64
+}
+
+// LoadMetrics is the resource accounting of one database load.
+//
+// Services that load files on behalf of many tenants can use it to enforce quotas
+// and bill usage per build.
+type LoadMetrics struct {
+	// Inputs contains the accounting of every input, in load order
+	Inputs []InputLoadMetrics
+	// BytesParsed is the total number of bytes read from all inputs
+	BytesParsed int64
+	// RowsStored is the total number of rows inserted into the database
+	RowsStored int64
+	// ApproxMemoryBytes is the current size of the in-memory database
+	// (page count multiplied by page size), including rows added after loading
+	ApproxMemoryBytes int64
+}
+
+// EnableLoadMetrics records bytes parsed and rows stored for every input.
+//
+// The accounting is stored in the "_filesql_load_metrics" table, which is excluded
+// from DumpDatabase and auto-save, and is read with GetLoadMetrics.
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableLoadMetrics() *DBBuilder {
+	b.loadMetrics = true
+	return b
+}
+
+// GetLoadMetrics returns the resource accounting of the load that created db.
+//
+// Byte and row counts are recorded once while inputs are loaded by Open or StartLoad
+// with EnableLoadMetrics, and do not change afterwards; without EnableLoadMetrics,
+// Inputs is empty. ApproxMemoryBytes is measured at call time.
+//
+// Example:
+//
+//	validatedBuilder, err := filesql.NewBuilder().
+//		AddReader(upload, "upload", filesql.FileTypeCSV).
+//		EnableLoadMetrics().
+//		Build(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	db, err := validatedBuilder.Open(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	metrics, err := filesql.GetLoadMetrics(db)
+//	if err != nil {
+//		return err
+//	}
+//	if metrics.ApproxMemoryBytes > tenant.MemoryQuota {
+//		db.Close()
+//		return errQuotaExceeded
+//	}
+//	billing.Record(tenant.ID, metrics.BytesParsed, metrics.RowsStored)
+func GetLoadMetrics(db *sql.DB) (LoadMetrics, error) {
+	ctx := context.Background()
+	metrics := LoadMetrics{Inputs: make([]InputLoadMetrics, 0)}
+
+	var pageCount, pageSize int64
+	if err := db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return metrics, fmt.Errorf("failed to get page count: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return metrics, fmt.Errorf("failed to get page size: %w", err)
+	}
+	metrics.ApproxMemoryBytes = pageCount * pageSize
+
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+		loadMetricsTableName,
+	).Scan(&exists); err != nil {
+		return metrics, fmt.Errorf("failed to check load metrics table: %w", err)
+	}
+	if exists == 0 {
+		return metrics, nil
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`SELECT input, tables, bytes_parsed, rows_stored FROM "%s" ORDER BY rowid`,
+		loadMetricsTableName,
+	))
+	if err != nil {
+		return metrics, fmt.Errorf("failed to query load metrics: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m InputLoadMetrics
+		var tables string
+		if err := rows.Scan(&m.Input, &tables, &m.BytesParsed, &m.RowsStored); err != nil {
+			return metrics, fmt.Errorf("failed to scan load metrics: %w", err)
+		}
+		m.Tables = make([]string, 0)
+		if tables != "" {
+			m.Tables = strings.Split(tables, "\n")
+		}
+		metrics.Inputs = append(metrics.Inputs, m)
+		metrics.BytesParsed += m.BytesParsed
+		metrics.RowsStored += m.RowsStored
+	}
+	if err := rows.Err(); err != nil {
+		return metrics, fmt.Errorf("failed to read load metrics: %w", err)
+	}
+	return metrics, nil
+}
+
+// loadMetricsRecorder is a loadObserver that accounts bytes and rows per input.
+// It forwards every notification to next, so it can be combined with a LoadJob.
+type loadMetricsRecorder struct {
+	next    loadObserver
+	inputs  []InputLoadMetrics
+	current *InputLoadMetrics
+}
+
+// newLoadMetricsRecorder creates a recorder that forwards notifications to next (may be nil)
+func newLoadMetricsRecorder(next loadObserver) *loadMetricsRecorder {
+	return &loadMetricsRecorder{next: next}
+}
+
+// inputStarted implements loadObserver
+func (r *loadMetricsRecorder) inputStarted(input string) {
+	r.inputs = append(r.inputs, InputLoadMetrics{Input: input})
+	r.current = &r.inputs[len(r.inputs)-1]
+	if r.next != nil {
+		r.next.inputStarted(input)
+	}
+}
+
+// bytesRead implements loadObserver
+func (r *loadMetricsRecorder) bytesRead(n int) {
+	if r.current != nil {
+		r.current.BytesParsed += int64(n)
+	}
+	if r.next != nil {
+		r.next.bytesRead(n)
+	}
+}
+
+// rowsInserted implements loadObserver
+func (r *loadMetricsRecorder) rowsInserted(table string, rows int) {
+	if r.current != nil {
+		r.current.RowsStored += int64(rows)
+	}
+	if r.next != nil {
+		r.next.rowsInserted(table, rows)
+	}
+}
+
+// tableLoaded implements loadObserver
+func (r *loadMetricsRecorder) tableLoaded(table string) {
+	if r.current != nil {
+		r.current.Tables = append(r.current.Tables, table)
+	}
+	if r.next != nil {
+		r.next.tableLoaded(table)
+	}
+}
+
+// inputFinished implements loadObserver
+func (r *loadMetricsRecorder) inputFinished(input string) {
+	r.current = nil
+	if r.next != nil {
+		r.next.inputFinished(input)
+	}
+}
+
+// save stores the recorded metrics in the load metrics table
+func (r *loadMetricsRecorder) save(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (input TEXT, tables TEXT, bytes_parsed INTEGER, rows_stored INTEGER)`,
+		loadMetricsTableName,
+	)); err != nil {
+		return fmt.Errorf("failed to create load metrics table: %w", err)
+	}
+
+	insertQuery := fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`INSERT INTO "%s" (input, tables, bytes_parsed, rows_stored) VALUES (?, ?, ?, ?)`,
+		loadMetricsTableName,
+	)
+	for _, m := range r.inputs {
+		if _, err := db.ExecContext(ctx, insertQuery, m.Input, strings.Join(m.Tables, "\n"), m.BytesParsed, m.RowsStored); err != nil {
+			return fmt.Errorf("failed to record load metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// countingReader reports the number of bytes read through it to a loadObserver
+type countingReader struct {
+	reader   io.Reader
+	observer loadObserver
+}
+
+// Read implements io.Reader
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	if n > 0 {
+		c.observer.bytesRead(n)
+	}
+	return n, err
+}
+
+// countBytes wraps reader so that bytes read from it are reported to the observer
+func (sp *streamProcessor) countBytes(reader io.Reader) io.Reader {
+	if sp.observer == nil {
+		return reader
+	}
+	return &countingReader{reader: reader, observer: sp.observer}
+}
@@ -0,0 +1,83 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLoadMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("accounts bytes and rows per input", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		samplePath := filepath.Join("testdata", "sample.csv")
+		sampleInfo, err := os.Stat(samplePath)
+		require.NoError(t, err)
+
+		readerData := "id,name\n1,a\n2,b\n3,c\n"
+		db := openTestDB(t, NewBuilder().
+			AddPath(samplePath).
+			AddReader(strings.NewReader(readerData), "upload", FileTypeCSV).
+			EnableLoadMetrics())
+
+		var sampleRows int64
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sample").Scan(&sampleRows))
+
+		metrics, err := GetLoadMetrics(db)
+		require.NoError(t, err)
+		assert.Equal(t, []InputLoadMetrics{
+			{Input: samplePath, Tables: []string{"sample"}, BytesParsed: sampleInfo.Size(), RowsStored: sampleRows},
+			{Input: "upload", Tables: []string{"upload"}, BytesParsed: int64(len(readerData)), RowsStored: 3},
+		}, metrics.Inputs)
+		assert.Equal(t, sampleInfo.Size()+int64(len(readerData)), metrics.BytesParsed)
+		assert.Equal(t, sampleRows+3, metrics.RowsStored)
+		assert.Positive(t, metrics.ApproxMemoryBytes)
+
+		tableNames, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sample", "upload"}, tableNames, "metrics table must be hidden")
+	})
+
+	t.Run("background loads report bytes read", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		readerData := "id\n1\n2\n"
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader(readerData), "upload", FileTypeCSV).
+			EnableLoadMetrics().
+			Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(ctx)
+		require.NoError(t, err)
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+
+		assert.Equal(t, int64(len(readerData)), job.Progress().BytesRead)
+
+		metrics, err := GetLoadMetrics(db)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), metrics.RowsStored)
+	})
+
+	t.Run("without EnableLoadMetrics only memory is reported", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+		metrics, err := GetLoadMetrics(db)
+		require.NoError(t, err)
+		assert.Empty(t, metrics.Inputs)
+		assert.Zero(t, metrics.RowsStored)
+		assert.Positive(t, metrics.ApproxMemoryBytes)
+	})
+}
@@ -0,0 +1,101 @@
+package filesql
+
+import "strings"
+
+// WithNoHeader loads CSV and TSV data whose first row is already data.
+//
+// By default, the first row of CSV and TSV data is the header, so the first record of
+// a file without a header row becomes the column names and is missing from the table.
+// With WithNoHeader, every row is loaded as data and the columns are named column1,
+// column2, and so on, unless they are named with WithColumnNames. Other formats
+// ignore the option. It takes precedence over WithHeaderDetection, and sidecar
+// indexes (see WithSidecarIndex) are not used for such inputs.
+//
+// Like every InputOption, it applies to one input when passed to AddPathWithOptions or
+// AddReaderWithOptions, and to every input when passed to DBBuilder.Apply.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPathWithOptions("sensor_dump.csv", filesql.WithNoHeader())
+//	// SELECT column1, column2 FROM sensor_dump
+func WithNoHeader() InputOption {
+	return func(o *inputOptions) {
+		o.noHeader = true
+	}
+}
+
+// WithColumnNames names the columns of CSV and TSV data without a header row.
+//
+// It implies WithNoHeader: the first row is loaded as data. The data must have exactly
+// as many columns as names; otherwise the load fails with ErrCodeInvalidConfig. Build
+// fails with ErrCodeInvalidConfig if a name is empty or used twice.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPathWithOptions("people.csv", filesql.WithColumnNames("id", "name", "age"))
+func WithColumnNames(names ...string) InputOption {
+	names = append([]string(nil), names...)
+	return func(o *inputOptions) {
+		o.noHeader = true
+		o.columnNames = names
+	}
+}
+
+// WithNoHeader loads the first row of every CSV and TSV input as data, like
+// Apply(filesql.WithNoHeader()). Inputs added with their own header options keep them.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithNoHeader() *DBBuilder {
+	return b.Apply(WithNoHeader())
+}
+
+// WithColumnNames names the columns of every CSV and TSV input, which have no header
+// row, like Apply(filesql.WithColumnNames(names...)). Inputs added with their own
+// header options keep them.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("people.csv"). // 1,alice,30
+//		WithColumnNames("id", "name", "age")
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithColumnNames(names ...string) *DBBuilder {
+	return b.Apply(WithColumnNames(names...))
+}
+
+// headerless reports whether the first row of the input is data
+func (o inputOptions) headerless() bool {
+	return o.noHeader || len(o.columnNames) > 0
+}
+
+// validateGivenColumnNames checks the names set with WithColumnNames
+func validateGivenColumnNames(names []string) error {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return newCodedError(ErrCodeInvalidConfig, "column names must not be empty")
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			return newCodedError(ErrCodeInvalidConfig, "duplicate column name: %s", name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// headerlessColumnNames returns the column names of data without a header row whose
+// first row has columns columns
+func (p *streamingParser) headerlessColumnNames(columns int) ([]string, error) {
+	if len(p.columnNames) == 0 {
+		return generatedColumnNames(columns), nil
+	}
+	if len(p.columnNames) != columns {
+		return nil, newCodedError(ErrCodeInvalidConfig, "%d column names are given for %d columns of table %s",
+			len(p.columnNames), columns, p.tableName)
+	}
+	return p.columnNames, nil
+}
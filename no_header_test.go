@@ -0,0 +1,83 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithNoHeader(t *testing.T) {
+	t.Parallel()
+
+	people := "1,alice,30\n2,bob,40\n"
+	withColumnNames := func(names ...string) func(*testing.T) *DBBuilder {
+		return func(*testing.T) *DBBuilder {
+			return NewBuilder().AddReaderWithOptions(strings.NewReader(people), "people", FileTypeCSV, WithColumnNames(names...))
+		}
+	}
+	runLoadTests(t, []loadTest{
+		{
+			name: "the first row is loaded as data with generated names",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().AddReaderWithOptions(strings.NewReader(people), "people", FileTypeCSV, WithNoHeader())
+			},
+			want: map[string][]string{
+				"SELECT column1, column2, column3 FROM people ORDER BY 1": {"1:alice:30", "2:bob:40"},
+			},
+		},
+		{
+			name: "columns are named with WithColumnNames",
+			builder: func(t *testing.T) *DBBuilder {
+				path := filepath.Join(t.TempDir(), "people.tsv")
+				require.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(people, ",", "\t")), 0600))
+				return NewBuilder().AddPathWithOptions(path, WithColumnNames("id", "name", "age"))
+			},
+			want: map[string][]string{
+				"SELECT id, name, age FROM people ORDER BY 1": {"1:alice:30", "2:bob:40"},
+				"SELECT SUM(age) FROM people":                 {"70"},
+			},
+		},
+		{
+			name: "builder-wide names do not override the options of an input",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader(people), "people", FileTypeCSV).
+					AddReaderWithOptions(strings.NewReader("1,x\n"), "labels", FileTypeCSV, WithNoHeader()).
+					WithColumnNames("id", "name", "age")
+			},
+			want: map[string][]string{
+				"SELECT id, name, age FROM people ORDER BY 1": {"1:alice:30", "2:bob:40"},
+				"SELECT column2 FROM labels":                  {"x"},
+			},
+		},
+		{
+			name: "configuration files set builder-wide names",
+			builder: func(t *testing.T) *DBBuilder {
+				builder, err := NewBuilderFromConfig(BuilderConfig{ColumnNames: []string{"id", "name", "age"}})
+				require.NoError(t, err)
+				return builder.AddReader(strings.NewReader(people), "people", FileTypeCSV)
+			},
+			want: map[string][]string{
+				"SELECT id, name, age FROM people ORDER BY 1": {"1:alice:30", "2:bob:40"},
+			},
+		},
+		{
+			name:    "a column count mismatch fails the load",
+			builder: withColumnNames("id", "name"),
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name:    "an empty column name fails the build",
+			builder: withColumnNames("id", "", "age"),
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name:    "a duplicate column name fails the build",
+			builder: withColumnNames("id", "ID", "age"),
+			wantErr: ErrCodeInvalidConfig,
+		},
+	})
+}
@@ -0,0 +1,324 @@
+package filesql
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NullConvention controls how NULL and empty strings are told apart in CSV, TSV, SSV,
+// and PSV files, which have no NULL value of their own.
+type NullConvention int
+
+const (
+	// NullConventionNone writes NULL and empty strings as empty fields and loads empty
+	// fields as empty strings, so NULL becomes an empty string after a dump and a
+	// reload (default)
+	NullConventionNone NullConvention = iota
+	// NullConventionQuotedEmpty writes NULL as an empty field and an empty string as a
+	// quoted empty field (""), and loads them back as NULL and as an empty string
+	NullConventionQuotedEmpty
+)
+
+// String returns the string representation of NullConvention
+func (c NullConvention) String() string {
+	switch c {
+	case NullConventionNone:
+		return "none"
+	case NullConventionQuotedEmpty:
+		return "quoted_empty"
+	default:
+		return "none"
+	}
+}
+
+// parseNullConvention converts a convention name into a NullConvention
+func parseNullConvention(name string) (NullConvention, error) {
+	for _, convention := range []NullConvention{NullConventionNone, NullConventionQuotedEmpty} {
+		if strings.EqualFold(name, convention.String()) {
+			return convention, nil
+		}
+	}
+	return NullConventionNone, newCodedError(ErrCodeInvalidConfig, "unsupported null convention: %s", name)
+}
+
+// nullFieldMarker replaces the unquoted empty fields of delimited data loaded with
+// NullConventionQuotedEmpty once the records are parsed, so they can be told apart from
+// quoted empty fields. It is a null value of every column.
+const nullFieldMarker = "\x00filesql:null\x00"
+
+// WithNullConvention sets how NULL and empty strings are told apart in the CSV, TSV,
+// SSV, and PSV inputs.
+//
+// These formats have no NULL value, so by default an empty field is loaded as an
+// empty string, and a table dumped and loaded again has empty strings where it had
+// NULL. With NullConventionQuotedEmpty, an unquoted empty field is loaded as NULL and a
+// quoted empty field ("") as an empty string, which is how DumpOptions.WithNullConvention
+// writes them, so NULL survives a dump and reload cycle. NULL values are ignored by
+// type inference like the values of WithNullValues.
+//
+// The header row is read as it is, and a row of a single empty field is an empty line,
+// which is skipped. Inputs using the convention are not read with a sidecar index.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("export/users.csv"). // id,nickname  1,  2,""
+//		WithNullConvention(filesql.NullConventionQuotedEmpty)
+//	// SELECT id FROM users WHERE nickname IS NULL → 1
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithNullConvention(convention NullConvention) *DBBuilder {
+	b.streamProcessor.nullConvention = convention
+	return b
+}
+
+// WithNullConvention sets how NULL and empty strings are written in CSV, TSV, SSV,
+// and PSV files.
+//
+// By default both are written as empty fields, so they cannot be told apart when the
+// file is loaded again. With NullConventionQuotedEmpty, NULL is written as an empty
+// field and an empty string as a quoted empty field (""), which
+// DBBuilder.WithNullConvention loads back as NULL and as an empty string. Other
+// formats are not affected.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().
+//		WithNullConvention(filesql.NullConventionQuotedEmpty)
+//	err := filesql.DumpDatabase(db, "./output", options)
+func (o DumpOptions) WithNullConvention(convention NullConvention) DumpOptions {
+	o.NullConvention = convention
+	return o
+}
+
+// nullFieldTracker reads delimited data as it is, recording the unquoted empty fields
+// of each record, which the parsed records do not tell apart from quoted empty fields
+type nullFieldTracker struct {
+	source    *bufio.Reader
+	delimiter rune
+	// skipHeader records no fields of the first record
+	skipHeader bool
+
+	// pending holds output not read yet
+	pending []byte
+	// nulls holds the indexes of the unquoted empty fields of the records that were
+	// scanned but not marked yet
+	nulls [][]int
+	// current holds the indexes of the unquoted empty fields of the record being scanned
+	current []int
+	// field is the index of the field being scanned
+	field int
+	// records is the number of records that ended
+	records int
+	// inQuotes is true inside the quoted text of a field
+	inQuotes bool
+	// closedQuotes is true right after the quote that ended quoted text, where another
+	// quote is an escaped quote
+	closedQuotes bool
+	// fieldStart is true before the first character of a field
+	fieldStart bool
+	// lineStart is true before the first character of a line
+	lineStart bool
+	// eof is true once the source is exhausted
+	eof bool
+}
+
+// newNullFieldTracker returns a reader of the delimited data of reader that records its
+// unquoted empty fields, recording none of the first record if skipHeader
+func newNullFieldTracker(reader io.Reader, delimiter rune, skipHeader bool) *nullFieldTracker {
+	return &nullFieldTracker{
+		source:     bufio.NewReader(reader),
+		delimiter:  delimiter,
+		skipHeader: skipHeader,
+		fieldStart: true,
+		lineStart:  true,
+	}
+}
+
+// Read implements io.Reader
+func (t *nullFieldTracker) Read(p []byte) (int, error) {
+	for len(t.pending) < len(p) && !t.eof {
+		next, err := t.source.Peek(utf8.UTFMax)
+		if len(next) == 0 {
+			if !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+			t.eof = true
+			// A record that is not ended by a line break
+			if !t.lineStart {
+				t.endRecord()
+			}
+			break
+		}
+		// Invalid bytes are decoded as a single byte and copied as they are
+		c, size := utf8.DecodeRune(next)
+		t.scan(c)
+		t.pending = append(t.pending, next[:size]...)
+		_, _ = t.source.Discard(size) // The bytes were peeked
+	}
+
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	if n == 0 && t.eof {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// scan updates the state with the next character c of the data, recording the empty
+// field that c ends
+func (t *nullFieldTracker) scan(c rune) {
+	if t.inQuotes {
+		if c == '"' {
+			t.inQuotes, t.closedQuotes = false, true
+		}
+		return
+	}
+	closedQuotes := t.closedQuotes
+	t.closedQuotes = false
+
+	switch {
+	case c == t.delimiter:
+		if t.fieldStart {
+			t.record()
+		}
+		t.field++
+		t.fieldStart, t.lineStart = true, false
+	case c == '\n' || c == '\r':
+		if !t.lineStart {
+			t.endRecord()
+		}
+		t.fieldStart, t.lineStart = true, true
+	case c == '"' && (t.fieldStart || closedQuotes):
+		// Quoted text starts, or an escaped quote ("") continues it
+		t.inQuotes = true
+		t.fieldStart, t.lineStart = false, false
+	default:
+		t.fieldStart, t.lineStart = false, false
+	}
+}
+
+// record records the field being scanned as an unquoted empty field, unless it is in
+// the header
+func (t *nullFieldTracker) record() {
+	if t.skipHeader && t.records == 0 {
+		return
+	}
+	t.current = append(t.current, t.field)
+}
+
+// endRecord ends the record being scanned, recording its last field if it is empty
+func (t *nullFieldTracker) endRecord() {
+	if t.fieldStart {
+		t.record()
+	}
+	t.nulls = append(t.nulls, t.current)
+	t.current = nil
+	t.field = 0
+	t.records++
+}
+
+// markNulls replaces the unquoted empty fields of record, the next record parsed from
+// the data read from t, with nullFieldMarker. Records are parsed in the order they were
+// read, and csv.Reader skips empty lines like t does, so t has recorded the fields of
+// every record csv.Reader returned.
+func (t *nullFieldTracker) markNulls(record []string) {
+	if t == nil || len(t.nulls) == 0 {
+		return
+	}
+	for _, i := range t.nulls[0] {
+		if i < len(record) {
+			record[i] = nullFieldMarker
+		}
+	}
+	t.nulls = t.nulls[1:]
+}
+
+// delimitedRecordWriter writes records of delimited data like csv.Writer, writing the
+// fields it is told to quote as quoted fields even when they are empty
+type delimitedRecordWriter struct {
+	writer    *bufio.Writer
+	delimiter rune
+}
+
+// newDelimitedRecordWriter returns a writer of records separated by delimiter
+func newDelimitedRecordWriter(writer io.Writer, delimiter rune) *delimitedRecordWriter {
+	return &delimitedRecordWriter{writer: bufio.NewWriter(writer), delimiter: delimiter}
+}
+
+// write writes record, quoting the fields whose quote is true and the fields that
+// need quotes; quote may be nil
+func (w *delimitedRecordWriter) write(record []string, quote []bool) error {
+	for i, field := range record {
+		if i > 0 {
+			if _, err := w.writer.WriteRune(w.delimiter); err != nil {
+				return err
+			}
+		}
+		if !(i < len(quote) && quote[i]) && !w.needsQuotes(field) {
+			if _, err := w.writer.WriteString(field); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := w.writer.WriteString(`"` + strings.ReplaceAll(field, `"`, `""`) + `"`); err != nil {
+			return err
+		}
+	}
+	return w.writer.WriteByte('\n')
+}
+
+// needsQuotes reports whether field must be quoted, by the rules of csv.Writer
+func (w *delimitedRecordWriter) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, w.delimiter) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	first, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(first)
+}
+
+// flush writes the buffered records
+func (w *delimitedRecordWriter) flush() error {
+	return w.writer.Flush()
+}
+
+// writeQuotedEmptyData writes data in CSV or TSV format based on delimiter, writing
+// NULL as an empty field and empty strings as quoted empty fields
+func writeQuotedEmptyData(writer io.Writer, columns []string, rows *sql.Rows, delimiter rune, formatter valueFormatter) error {
+	recordWriter := newDelimitedRecordWriter(writer, delimiter)
+	if err := recordWriter.write(columns, nil); err != nil {
+		return err
+	}
+
+	values := make([]any, len(columns))
+	scanArgs := make([]any, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	quote := make([]bool, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			record[i] = formatter.format(i, value)
+			quote[i] = value != nil && record[i] == ""
+		}
+		if err := recordWriter.write(record, quote); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return recordWriter.flush()
+}
@@ -0,0 +1,163 @@
+package filesql
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullConvention(t *testing.T) {
+	t.Parallel()
+
+	// nicknames returns the nicknames of the users ordered by id, with "<NULL>" for NULL
+	const nicknames = "SELECT COALESCE(nickname, '<NULL>') FROM users ORDER BY id"
+
+	t.Run("NULL and empty strings survive a dump and reload", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id,nickname\n1,a\n"), "users", FileTypeCSV))
+		_, err := db.ExecContext(context.Background(), `INSERT INTO users (id, nickname) VALUES (2, NULL), (3, ''), (4, 'say "hi", bye')`)
+		require.NoError(t, err)
+
+		dir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, dir, NewDumpOptions().WithNullConvention(NullConventionQuotedEmpty)))
+		data, err := os.ReadFile(filepath.Join(dir, "users.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "id,nickname\n1,a\n2,\n3,\"\"\n4,\"say \"\"hi\"\", bye\"\n", string(data))
+
+		reloaded := openTestDB(t, NewBuilder().AddPath(filepath.Join(dir, "users.csv")).WithNullConvention(NullConventionQuotedEmpty))
+		assert.Equal(t, []string{"a", "<NULL>", "", `say "hi", bye`}, queryRows(t, reloaded, nicknames))
+	})
+
+	t.Run("empty fields are empty strings by default", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id,nickname\n1,\n2,\"\"\n"), "users", FileTypeCSV))
+		assert.Equal(t, []string{"", ""}, queryRows(t, db, nicknames))
+
+		dir := t.TempDir()
+		_, err := db.ExecContext(context.Background(), "UPDATE users SET nickname = NULL WHERE id = 1")
+		require.NoError(t, err)
+		require.NoError(t, DumpDatabase(db, dir))
+		data, err := os.ReadFile(filepath.Join(dir, "users.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "id,nickname\n1,\n2,\n", string(data))
+	})
+
+	t.Run("NULL values are ignored by type inference", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\tscore\n1\t\n2\t5\n3\t\"\"\n"), "scores", FileTypeTSV).
+			WithNullConvention(NullConventionQuotedEmpty))
+
+		var columnType string
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT type FROM pragma_table_info('scores') WHERE name = 'score'").Scan(&columnType))
+		assert.Equal(t, "INTEGER", columnType)
+
+		var nulls int
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT COUNT(*) FROM scores WHERE score IS NULL").Scan(&nulls))
+		assert.Equal(t, 1, nulls)
+	})
+
+	t.Run("columns of WithColumnNullValues keep NULL", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,nickname\n1,\n2,N/A\n"), "users", FileTypeCSV).
+			WithNullConvention(NullConventionQuotedEmpty).
+			WithColumnNullValues("users", "nickname"))
+		assert.Equal(t, []string{"<NULL>", "N/A"}, queryRows(t, db, nicknames))
+	})
+
+	t.Run("delimiters that are characters of text", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReaderWithOptions(strings.NewReader("id:nickname:x\n1::\"\"\n2:b:c\n"), "users", FileTypeDelimited, WithDelimiter(':')).
+			WithNullConvention(NullConventionQuotedEmpty))
+		assert.Equal(t, []string{"<NULL>", "b"}, queryRows(t, db, nicknames))
+
+		var x string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT x FROM users WHERE id = 1").Scan(&x))
+		assert.Empty(t, x)
+	})
+}
+
+func TestNullFieldTracker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		input      string
+		delimiter  rune
+		skipHeader bool
+		want       [][]int
+	}{
+		{name: "empty fields", input: "a,b,c\n,x,\n", delimiter: ',', skipHeader: true, want: [][]int{nil, {0, 2}}},
+		{name: "quoted empty fields", input: "a,b\n\"\",\"\"\n", delimiter: ',', skipHeader: true, want: [][]int{nil, nil}},
+		{name: "header fields", input: ",b\n1,\n", delimiter: ',', skipHeader: true, want: [][]int{nil, {1}}},
+		{name: "without header", input: ",b\n1,\n", delimiter: ',', want: [][]int{{0}, {1}}},
+		{name: "end of data", input: "a,b\n1,", delimiter: ',', skipHeader: true, want: [][]int{nil, {1}}},
+		{name: "CRLF", input: "a,b\r\n1,\r\n", delimiter: ',', skipHeader: true, want: [][]int{nil, {1}}},
+		{name: "empty lines", input: "a\n\n1\n", delimiter: ',', skipHeader: true, want: [][]int{nil, nil}},
+		{
+			name: "delimiters and line breaks in quoted fields", input: "a,b\n\",\n\"\"\",\n", delimiter: ',', skipHeader: true,
+			want: [][]int{nil, {1}},
+		},
+		{name: "other delimiters", input: "a;b\n;1\n", delimiter: ';', skipHeader: true, want: [][]int{nil, {0}}},
+		{name: "multi-byte delimiters", input: "a§b\n§é\n", delimiter: '§', skipHeader: true, want: [][]int{nil, {0}}},
+		{name: "invalid UTF-8", input: "a,b\n\xff,\n", delimiter: ',', skipHeader: true, want: [][]int{nil, {1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tracker := newNullFieldTracker(strings.NewReader(tt.input), tt.delimiter, tt.skipHeader)
+			got, err := io.ReadAll(tracker)
+			require.NoError(t, err)
+			assert.Equal(t, tt.input, string(got), "the data is read as it is")
+			assert.Equal(t, tt.want, tracker.nulls)
+		})
+	}
+
+	t.Run("small reads", func(t *testing.T) {
+		t.Parallel()
+
+		tracker := newNullFieldTracker(strings.NewReader("a,b\n,\n"), ',', true)
+		var got []byte
+		buf := make([]byte, 1)
+		for {
+			n, err := tracker.Read(buf)
+			got = append(got, buf[:n]...)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+		}
+		assert.Equal(t, "a,b\n,\n", string(got))
+		assert.Equal(t, [][]int{nil, {0, 1}}, tracker.nulls)
+	})
+
+	t.Run("records are marked in order", func(t *testing.T) {
+		t.Parallel()
+
+		tracker := newNullFieldTracker(strings.NewReader("a,b\n,x\n\"\",\n"), ',', true)
+		records, err := csv.NewReader(tracker).ReadAll()
+		require.NoError(t, err)
+		for _, record := range records {
+			tracker.markNulls(record)
+		}
+		assert.Equal(t, [][]string{{"a", "b"}, {nullFieldMarker, "x"}, {"", nullFieldMarker}}, records)
+	})
+}
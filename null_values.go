@@ -0,0 +1,148 @@
+package filesql
+
+import (
+	"slices"
+	"strings"
+)
+
+// columnNullValues are the values loaded as NULL in a column of a table
+type columnNullValues struct {
+	column string
+	values []string
+}
+
+// WithNullValues sets the values that are loaded as SQL NULL in every column.
+//
+// Exports mark missing values in many ways: an empty cell, "NULL", "N/A", or "-".
+// These are normally loaded as text, so "IS NULL" finds none of them, COUNT(column)
+// counts them, and a single "N/A" makes a numeric column TEXT. With WithNullValues,
+// values equal to one of values (after trimming surrounding whitespace) are loaded as
+// NULL and ignored by type inference. The comparison is case-sensitive, so list
+// "NULL" and "null" separately when both appear. Calling WithNullValues again replaces
+// the values.
+//
+// WithColumnNullValues sets other values for single columns. WithDefaultValue fills
+// empty values before they are compared with the null values.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("survey.csv"). // score: 80, N/A, 100
+//		WithNullValues("", "NULL", "N/A", "-")
+//	// SELECT COUNT(*) FROM survey WHERE score IS NULL → 1
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithNullValues(values ...string) *DBBuilder {
+	b.streamProcessor.nullValues = slices.Clone(values)
+	return b
+}
+
+// WithColumnNullValues sets the values that are loaded as SQL NULL in a column of a
+// table, in place of the values of WithNullValues.
+//
+// Use it when a token is a real value in some columns: "-" can mean missing in a
+// price column but be a valid code elsewhere. Without values, every value of the
+// column is loaded as it is, which excludes the column from WithNullValues.
+//
+// The table is the name the input is loaded as (see the table naming rules), and the
+// column is the name the column is loaded as, after WithHeaderMapping renames it; both
+// are compared case-insensitively. Setting the values of the same column again
+// replaces them.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("products.csv").
+//		WithNullValues("", "N/A").
+//		WithColumnNullValues("products", "price", "-", "N/A"). // "-" is a missing price
+//		WithColumnNullValues("products", "note")               // keep "N/A" notes as text
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithColumnNullValues(table, column string, values ...string) *DBBuilder {
+	if b.streamProcessor.columnNullValues == nil {
+		b.streamProcessor.columnNullValues = make(map[string][]columnNullValues)
+	}
+	// Build a new slice, since clones of the builder may share the current one
+	key := strings.ToLower(table)
+	columns := slices.DeleteFunc(slices.Clone(b.streamProcessor.columnNullValues[key]), func(nulls columnNullValues) bool {
+		return strings.EqualFold(nulls.column, column)
+	})
+	b.streamProcessor.columnNullValues[key] = append(columns, columnNullValues{column: column, values: slices.Clone(values)})
+	return b
+}
+
+// nullValueSets returns the values loaded as NULL in each of the columns of a table,
+// or nil when no null values are set
+func (sp *streamProcessor) nullValueSets(tableName string, columns []columnInfo) [][]string {
+	table := strings.TrimPrefix(tableName, stagingTablePrefix)
+	overrides := sp.columnNullValues[strings.ToLower(table)]
+	if len(sp.nullValues) == 0 && len(overrides) == 0 && sp.nullConvention == NullConventionNone {
+		return nil
+	}
+
+	sets := make([][]string, len(columns))
+	for i, column := range columns {
+		sets[i] = sp.nullValues
+		for _, nulls := range overrides {
+			if strings.EqualFold(nulls.column, column.Name) {
+				sets[i] = nulls.values
+				break
+			}
+		}
+		// The unquoted empty fields of NullConventionQuotedEmpty are NULL in every column
+		if sp.nullConvention == NullConventionQuotedEmpty {
+			sets[i] = append(slices.Clip(sets[i]), nullFieldMarker)
+		}
+	}
+	return sets
+}
+
+// isNullValue reports whether value is one of the null values of its column
+func isNullValue(nulls [][]string, column int, value string) bool {
+	return column < len(nulls) && slices.Contains(nulls[column], strings.TrimSpace(value))
+}
+
+// inferTypesWithoutNullValues infers the types of the columns of a chunk that hold
+// null values again, ignoring those values
+func (sp *streamProcessor) inferTypesWithoutNullValues(chunk *tableChunk) *tableChunk {
+	infos := chunk.getColumnInfo()
+	nulls := sp.nullValueSets(chunk.getTableName(), infos)
+	if nulls == nil {
+		return chunk
+	}
+
+	var inferred []columnInfo
+	for i, info := range infos {
+		values := make([]string, 0, len(chunk.getRecords()))
+		found := false
+		for _, record := range chunk.getRecords() {
+			if i >= len(record) {
+				continue
+			}
+			if isNullValue(nulls, i, record[i]) {
+				found = true
+				continue
+			}
+			values = append(values, record[i])
+		}
+		if !found {
+			continue
+		}
+		if inferred == nil {
+			// The column information may be shared with other chunks of the input
+			inferred = make([]columnInfo, len(infos))
+			copy(inferred, infos)
+		}
+		inferred[i] = newColumnInfo(info.Name, values)
+	}
+	if inferred == nil {
+		return chunk
+	}
+
+	return &tableChunk{
+		tableName:  chunk.getTableName(),
+		headers:    chunk.getHeaders(),
+		records:    chunk.getRecords(),
+		columnInfo: inferred,
+	}
+}
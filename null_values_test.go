@@ -0,0 +1,60 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithNullValues(t *testing.T) {
+	t.Parallel()
+
+	const content = "id,price,note\n1,100,N/A\n2,N/A,-\n3, NULL ,\n4,-,ok\n"
+	// query returns the NULL counts of price and note, and the declared column types
+	const query = "SELECT COUNT(*) - COUNT(price), COUNT(*) - COUNT(note), " +
+		"(SELECT group_concat(type, ',') FROM (SELECT type FROM pragma_table_info('products') ORDER BY cid)) " +
+		"FROM products"
+
+	tests := []struct {
+		name    string
+		builder *DBBuilder
+		want    string
+	}{
+		{
+			name:    "values are loaded as NULL and ignored by type inference",
+			builder: NewBuilder().WithNullValues("", "NULL", "N/A"),
+			// "-" is not a NULL value, so price stays TEXT
+			want: "2:2:INTEGER,TEXT,TEXT",
+		},
+		{
+			name: "column values replace the values of every column",
+			builder: NewBuilder().
+				WithNullValues("", "NULL", "N/A").
+				WithColumnNullValues("PRODUCTS", "Price", "-", "N/A", "NULL").
+				WithColumnNullValues("products", "note"),
+			want: "3:0:INTEGER,INTEGER,TEXT",
+		},
+		{
+			name: "full scan ignores NULL values",
+			builder: NewBuilder().
+				SetDefaultChunkSize(1).
+				WithTypeInference(TypeInferenceFullScan).
+				Apply(WithNullValues("N/A", "NULL", "-")),
+			want: "3:2:INTEGER,INTEGER,TEXT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "products.csv")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+			db := openTestDB(t, tt.builder.AddPath(path))
+			assert.Equal(t, []string{tt.want}, queryRows(t, db, query))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package filesql
+
+// minNumericTextDigits is the number of digits from which a number is kept as text.
+// Spreadsheets and JSON consumers store numbers as float64, which cannot represent
+// every integer with 17 or more digits.
+const minNumericTextDigits = 17
+
+// EnableNumericTextPreservation keeps number-like identifiers as TEXT columns.
+//
+// Type inference turns columns that contain only digits into INTEGER or REAL columns,
+// so values such as ZIP codes ("01234"), product codes ("007"), or long IDs
+// ("12345678901234567890") lose their leading zeros or precision, and are exported
+// corrupted by DumpDatabase. With this option, a numeric column is created as TEXT
+// when any of its values
+//   - has a leading zero, such as "01234" or "-007" ("0" and "0.5" are ordinary numbers), or
+//   - has 17 or more digits and no decimal point or exponent.
+//
+// Values are kept exactly as they appear in the input. The rule is applied to the
+// rows used for type inference, which are the first chunk of each input (see
+// SetDefaultChunkSize).
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("customers.csv"). // zip_code: 01234, 90210
+//		EnableNumericTextPreservation()
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableNumericTextPreservation() *DBBuilder {
+	b.streamProcessor.preserveNumericText = true
+	return b
+}
+
+// preserveNumericTextColumns changes numeric columns of a chunk that contain
+// number-like identifiers to TEXT when numeric text preservation is enabled
+func (sp *streamProcessor) preserveNumericTextColumns(chunk *tableChunk) *tableChunk {
+	if !sp.preserveNumericText {
+		return chunk
+	}
+
+	infos := chunk.getColumnInfo()
+	var preserved []columnInfo
+	for i, info := range infos {
+		if info.Type != columnTypeInteger && info.Type != columnTypeReal {
+			continue
+		}
+		if !columnHasNumericText(chunk.getRecords(), i) {
+			continue
+		}
+		if preserved == nil {
+			// The column information may be shared with other chunks of the input
+			preserved = make([]columnInfo, len(infos))
+			copy(preserved, infos)
+		}
+		preserved[i].Type = columnTypeText
+	}
+	if preserved == nil {
+		return chunk
+	}
+
+	return &tableChunk{
+		tableName:  chunk.getTableName(),
+		headers:    chunk.getHeaders(),
+		records:    chunk.getRecords(),
+		columnInfo: preserved,
+	}
+}
+
+// columnHasNumericText reports whether a value of the column at index i is a number-like identifier
+func columnHasNumericText(records []Record, i int) bool {
+	for _, record := range records {
+		if i < len(record) && isNumericText(record[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumericText reports whether value is a number that must be kept as text: an
+// integer or decimal with a leading zero, or an integer with minNumericTextDigits or
+// more digits
+func isNumericText(value string) bool {
+	if value != "" && (value[0] == '+' || value[0] == '-') {
+		value = value[1:]
+	}
+	if len(value) < 2 || value[0] < '0' || value[0] > '9' {
+		return false
+	}
+
+	digits := 0
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			// Decimals and exponents are only text when they have a leading zero, e.g. "01.5"
+			return value[0] == '0' && value[1] >= '0' && value[1] <= '9' && isFloat(value)
+		}
+		digits++
+	}
+	return value[0] == '0' || digits >= minNumericTextDigits
+}
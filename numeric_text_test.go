@@ -0,0 +1,84 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNumericText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "01234", want: true},
+		{value: "007", want: true},
+		{value: "-007", want: true},
+		{value: "01.5", want: true},
+		{value: "12345678901234567", want: true},
+		{value: "-12345678901234567890", want: true},
+		{value: "0", want: false},
+		{value: "0.5", want: false},
+		{value: "-0.25", want: false},
+		{value: "1234", want: false},
+		{value: "1234567890123456", want: false},
+		{value: "1.2345678901234567", want: false},
+		{value: "1e20", want: false},
+		{value: "0x1F", want: false},
+		{value: "", want: false},
+		{value: "abc", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, isNumericText(tt.value))
+		})
+	}
+}
+
+func TestDBBuilder_EnableNumericTextPreservation(t *testing.T) {
+	t.Parallel()
+
+	const data = "id,zip_code,account,price\n1,01234,12345678901234567890,1.5\n2,90210,12345678901234567891,2\n"
+
+	const columnTypes = "SELECT type FROM pragma_table_info('customers') ORDER BY cid"
+
+	t.Run("number-like identifiers are numeric by default", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(strings.NewReader(data), "customers", FileTypeCSV))
+		assert.Equal(t, []string{"INTEGER", "INTEGER", "REAL", "REAL"}, queryRows(t, db, columnTypes))
+	})
+
+	t.Run("number-like identifiers are kept as text and dumped unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "customers.csv")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+		db := openTestDB(t, NewBuilder().AddPath(path).EnableNumericTextPreservation())
+		assert.Equal(t, []string{"INTEGER", "TEXT", "TEXT", "REAL"}, queryRows(t, db, columnTypes))
+
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir))
+		dumped, err := os.ReadFile(filepath.Join(outputDir, "customers.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "id,zip_code,account,price\n1,01234,12345678901234567890,1.5\n2,90210,12345678901234567891,2\n", string(dumped))
+	})
+
+	t.Run("can be enabled from configuration", func(t *testing.T) {
+		t.Parallel()
+
+		builder, err := NewBuilderFromConfig(BuilderConfig{PreserveNumericText: true})
+		require.NoError(t, err)
+		db := openTestDB(t, builder.AddReader(strings.NewReader(data), "customers", FileTypeCSV))
+		assert.Equal(t, []string{"INTEGER", "TEXT", "TEXT", "REAL"}, queryRows(t, db, columnTypes))
+	})
+}
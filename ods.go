@@ -0,0 +1,416 @@
+package filesql
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// XML namespaces of the OpenDocument elements read by the ODS reader
+const (
+	odsOfficeNamespace = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	odsTableNamespace  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsTextNamespace   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+const (
+	// odsMIMEType is the content of the mimetype entry of OpenDocument spreadsheets
+	odsMIMEType = "application/vnd.oasis.opendocument.spreadsheet"
+	// odsMaxRows is the row limit of LibreOffice Calc; repeated rows beyond it are rejected
+	odsMaxRows = 1048576
+	// odsMaxColumns is the column limit of LibreOffice Calc; repeated cells beyond it are rejected
+	odsMaxColumns = 16384
+)
+
+// readODSSheets reads the worksheets of an OpenDocument spreadsheet (.ods) as created
+// by LibreOffice Calc.
+//
+// Only cell values are read: numbers, percentages, and currencies are formatted like
+// XLS numbers, dates keep their ISO 8601 form, times become "HH:MM:SS", booleans
+// become TRUE and FALSE, and formulas yield their cached results. Styles, comments,
+// and charts are ignored. Rows are returned like excelize.GetRows, without trailing
+// empty cells and rows.
+func readODSSheets(reader io.Reader) ([]xlsSheet, error) {
+	// ODS files are zip archives, which are read from the central directory at the end
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ODS data: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, newCodedError(ErrCodeEmptyData, "empty ODS file")
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, newCodedError(ErrCodeInvalidData, "failed to open ODS file: %w", err)
+	}
+	var content *zip.File
+	for _, f := range archive.File {
+		switch f.Name {
+		case "mimetype":
+			mimeType, err := readZIPEntry(f)
+			if err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(string(mimeType)) != odsMIMEType {
+				return nil, newCodedError(ErrCodeUnsupportedFormat, "not an OpenDocument spreadsheet: %s", strings.TrimSpace(string(mimeType)))
+			}
+		case "META-INF/manifest.xml":
+			manifest, err := readZIPEntry(f)
+			if err != nil {
+				return nil, err
+			}
+			if bytes.Contains(manifest, []byte("encryption-data")) {
+				return nil, newCodedError(ErrCodeUnsupportedFormat, "encrypted .ods files are not supported")
+			}
+		case "content.xml":
+			content = f
+		}
+	}
+	if content == nil {
+		return nil, newCodedError(ErrCodeInvalidData, "content.xml not found in ODS file")
+	}
+
+	entry, err := content.Open()
+	if err != nil {
+		return nil, newCodedError(ErrCodeInvalidData, "failed to open content.xml in ODS file: %w", err)
+	}
+	defer entry.Close()
+	return decodeODSContent(entry)
+}
+
+// readZIPEntry reads a small entry of a zip archive
+func readZIPEntry(f *zip.File) ([]byte, error) {
+	entry, err := f.Open()
+	if err != nil {
+		return nil, newCodedError(ErrCodeInvalidData, "failed to open %s in ODS file: %w", f.Name, err)
+	}
+	defer entry.Close()
+
+	data, err := io.ReadAll(entry)
+	if err != nil {
+		return nil, newCodedError(ErrCodeInvalidData, "failed to read %s in ODS file: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// decodeODSContent reads the sheets of the content.xml document of an ODS file.
+// Rows inside header-row and row-group elements belong to the sheet like other rows.
+func decodeODSContent(reader io.Reader) ([]xlsSheet, error) {
+	decoder := xml.NewDecoder(reader)
+	var (
+		sheets []xlsSheet
+		sheet  *xlsSheet
+		// emptyRows counts the empty rows that are added once a non-empty row follows
+		emptyRows int
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return sheets, nil
+		}
+		if err != nil {
+			return nil, newCodedError(ErrCodeInvalidData, "malformed ODS content: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsTableNamespace {
+				continue
+			}
+			switch {
+			case t.Name.Local == "table":
+				sheet = &xlsSheet{name: odsAttr(t, odsTableNamespace, "name")}
+				emptyRows = 0
+			case t.Name.Local == "table-row" && sheet != nil:
+				cells, repeat, err := decodeODSRow(decoder, t)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read sheet %s: %w", sheet.name, err)
+				}
+				if len(cells) == 0 {
+					emptyRows += repeat
+					continue
+				}
+				if len(sheet.rows)+emptyRows+repeat > odsMaxRows {
+					return nil, newCodedError(ErrCodeInvalidData, "sheet %s has more than %d rows", sheet.name, odsMaxRows)
+				}
+				for range emptyRows {
+					sheet.rows = append(sheet.rows, nil)
+				}
+				emptyRows = 0
+				for range repeat {
+					sheet.rows = append(sheet.rows, slices.Clone(cells))
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space == odsTableNamespace && t.Name.Local == "table" && sheet != nil {
+				sheets = append(sheets, *sheet)
+				sheet = nil
+			}
+		}
+	}
+}
+
+// decodeODSRow reads the cells of the table-row element start and returns them without
+// trailing empty cells, together with the number of times the row is repeated
+func decodeODSRow(decoder *xml.Decoder, start xml.StartElement) ([]string, int, error) {
+	repeat := odsRepeat(start, "number-rows-repeated")
+	var (
+		cells []string
+		// emptyCells counts the empty cells that are added once a non-empty cell follows
+		emptyCells int
+	)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, 0, newCodedError(ErrCodeInvalidData, "malformed ODS row: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != odsTableNamespace || (t.Name.Local != "table-cell" && t.Name.Local != "covered-table-cell") {
+				if err := decoder.Skip(); err != nil {
+					return nil, 0, newCodedError(ErrCodeInvalidData, "malformed ODS row: %w", err)
+				}
+				continue
+			}
+			value, cellRepeat, err := decodeODSCell(decoder, t)
+			if err != nil {
+				return nil, 0, err
+			}
+			if value == "" {
+				emptyCells += cellRepeat
+				continue
+			}
+			if len(cells)+emptyCells+cellRepeat > odsMaxColumns {
+				return nil, 0, newCodedError(ErrCodeInvalidData, "row has more than %d columns", odsMaxColumns)
+			}
+			cells = append(cells, make([]string, emptyCells)...)
+			emptyCells = 0
+			for range cellRepeat {
+				cells = append(cells, value)
+			}
+		case xml.EndElement:
+			return cells, repeat, nil
+		}
+	}
+}
+
+// decodeODSCell reads the value of the table-cell element start and returns it together
+// with the number of times the cell is repeated
+func decodeODSCell(decoder *xml.Decoder, start xml.StartElement) (string, int, error) {
+	repeat := odsRepeat(start, "number-columns-repeated")
+	var paragraphs []string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", 0, newCodedError(ErrCodeInvalidData, "malformed ODS cell: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			// Comments (office:annotation) and other content are not cell values
+			if t.Name.Space != odsTextNamespace || (t.Name.Local != "p" && t.Name.Local != "h") {
+				if err := decoder.Skip(); err != nil {
+					return "", 0, newCodedError(ErrCodeInvalidData, "malformed ODS cell: %w", err)
+				}
+				continue
+			}
+			paragraph, err := decodeODSParagraph(decoder)
+			if err != nil {
+				return "", 0, err
+			}
+			paragraphs = append(paragraphs, paragraph)
+		case xml.EndElement:
+			return odsCellValue(start, strings.Join(paragraphs, "\n")), repeat, nil
+		}
+	}
+}
+
+// decodeODSParagraph reads the text of a paragraph up to its end element
+func decodeODSParagraph(decoder *xml.Decoder) (string, error) {
+	var (
+		text  strings.Builder
+		depth int
+	)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", newCodedError(ErrCodeInvalidData, "malformed ODS text: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			if t.Name.Space == odsTextNamespace {
+				switch t.Name.Local {
+				case "s": // Spaces, which XML would collapse
+					count, err := strconv.Atoi(odsAttr(t, odsTextNamespace, "c"))
+					if err != nil || count < 1 {
+						count = 1
+					}
+					text.WriteString(strings.Repeat(" ", min(count, odsMaxColumns)))
+				case "tab":
+					text.WriteByte('\t')
+				case "line-break":
+					text.WriteByte('\n')
+				case "note": // Footnotes are not part of the value
+					if err := decoder.Skip(); err != nil {
+						return "", newCodedError(ErrCodeInvalidData, "malformed ODS text: %w", err)
+					}
+					continue
+				}
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return text.String(), nil
+			}
+			depth--
+		}
+	}
+}
+
+// odsCellValue returns the value of a cell from its value attributes, or its text
+// for strings and cells without a type
+func odsCellValue(cell xml.StartElement, text string) string {
+	switch odsAttr(cell, odsOfficeNamespace, "value-type") {
+	case "float", "percentage", "currency":
+		value := odsAttr(cell, odsOfficeNamespace, "value")
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return formatXLSNumber(number)
+		}
+	case "date":
+		if value := odsAttr(cell, odsOfficeNamespace, "date-value"); value != "" {
+			return value
+		}
+	case "time":
+		if value := odsAttr(cell, odsOfficeNamespace, "time-value"); value != "" {
+			return formatODSTime(value)
+		}
+	case "boolean":
+		switch odsAttr(cell, odsOfficeNamespace, "boolean-value") {
+		case "true":
+			return "TRUE"
+		case "false":
+			return "FALSE"
+		}
+	}
+	return text
+}
+
+// formatODSTime formats an ISO 8601 duration such as PT13H45M00S as 13:45:00.
+// Other values are returned unchanged.
+func formatODSTime(value string) string {
+	rest, ok := strings.CutPrefix(value, "PT")
+	if !ok {
+		return value
+	}
+	hours, rest, ok := strings.Cut(rest, "H")
+	if !ok {
+		return value
+	}
+	minutes, rest, ok := strings.Cut(rest, "M")
+	if !ok {
+		return value
+	}
+	seconds, rest, ok := strings.Cut(rest, "S")
+	if !ok || rest != "" {
+		return value
+	}
+	h, hErr := strconv.Atoi(hours)
+	m, mErr := strconv.Atoi(minutes)
+	if _, err := strconv.ParseFloat(seconds, 64); hErr != nil || mErr != nil || err != nil {
+		return value
+	}
+	if whole, _, _ := strings.Cut(seconds, "."); len(whole) == 1 {
+		seconds = "0" + seconds
+	}
+	return fmt.Sprintf("%02d:%02d:%s", h, m, seconds)
+}
+
+// odsAttr returns the value of the attribute local in namespace space of element
+func odsAttr(element xml.StartElement, space, local string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// odsRepeat returns the repeat count in the table attribute local of element, 1 if unset
+func odsRepeat(element xml.StartElement, local string) int {
+	repeat, err := strconv.Atoi(odsAttr(element, odsTableNamespace, local))
+	if err != nil || repeat < 1 {
+		return 1
+	}
+	return repeat
+}
+
+// parseODS parses an OpenDocument spreadsheet with compression support.
+// Only the first sheet is parsed; use filesql.Open() for the 1-sheet-1-table approach.
+func (f *file) parseODS() (*table, error) {
+	reader, closer, err := f.openReader()
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	sheets, err := readODSSheets(reader)
+	if err != nil {
+		return nil, err
+	}
+	headers, records, err := firstSheetTable(sheets, "ODS")
+	if err == nil {
+		err = validateColumnNames(headers)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, f.path)
+	}
+	return newTable(tableFromFilePath(f.path), headers, records), nil
+}
+
+// parseODSStream parses OpenDocument spreadsheet data from reader.
+// Only the first sheet is processed (streaming parser limitation).
+func (p *streamingParser) parseODSStream(reader io.Reader) (*table, error) {
+	sheets, err := readODSSheets(reader)
+	if err != nil {
+		return nil, err
+	}
+	headers, records, err := firstSheetTable(sheets, "ODS")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validateHeader(headers); err != nil {
+		return nil, err
+	}
+	return newTable(p.tableName, headers, records), nil
+}
+
+// processODSInChunks processes the first sheet of OpenDocument spreadsheet data in chunks.
+// The spreadsheet is read into memory as a whole because zip requires random access.
+func (p *streamingParser) processODSInChunks(reader io.Reader, processor chunkProcessor) error {
+	if p.memoryLimit != nil && p.memoryLimit.CheckMemoryUsage() == MemoryStatusExceeded {
+		return p.memoryLimit.CreateMemoryError("ODS chunk processing")
+	}
+
+	sheets, err := readODSSheets(reader)
+	if err != nil {
+		return err
+	}
+	headers, records, err := firstSheetTable(sheets, "ODS")
+	if err != nil {
+		return err
+	}
+	if err := p.validateHeader(headers); err != nil {
+		return err
+	}
+	return p.processSheetInChunks(headers, records, processor)
+}
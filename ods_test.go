@@ -0,0 +1,163 @@
+package filesql
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadODSSheets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reads values of every sheet", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := os.Open(filepath.Join("testdata", "ods", "sample.ods"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		sheets, err := readODSSheets(f)
+		require.NoError(t, err)
+
+		require.Len(t, sheets, 3)
+		assert.Equal(t, "products", sheets[0].name)
+		assert.Equal(t, [][]string{
+			{"id", "price", "name", "in_stock", "added", "opens", "note"},
+			{"1", "1.25", "Green  Apple", "TRUE", "2024-03-04", "09:30:00", "line1\nline2"},
+			{"2", "0.5", "日本のりんご"},
+			nil,
+			nil,
+			{"3", "0.00001", "same", "same"},
+		}, sheets[0].rows, "comments are skipped and repeated empty rows at the end are dropped")
+		assert.Equal(t, "empty", sheets[1].name)
+		assert.Empty(t, sheets[1].rows)
+		assert.Equal(t, "summary", sheets[2].name)
+		assert.Equal(t, [][]string{{"total"}, {"42"}}, sheets[2].rows)
+	})
+
+	t.Run("rejects data that is not an ods file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := readODSSheets(bytes.NewReader([]byte("id,name\n1,alice\n")))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidData, ErrorCodeOf(err))
+
+		_, err = readODSSheets(bytes.NewReader(nil))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeEmptyData, ErrorCodeOf(err))
+
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		w, err := archive.Create("mimetype")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("application/vnd.oasis.opendocument.text"))
+		require.NoError(t, err)
+		require.NoError(t, archive.Close())
+		_, err = readODSSheets(bytes.NewReader(buf.Bytes()))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeUnsupportedFormat, ErrorCodeOf(err))
+	})
+
+	t.Run("rejects repeated cells beyond the column limit", func(t *testing.T) {
+		t.Parallel()
+
+		content := `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+			`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"><office:body><office:spreadsheet>` +
+			`<table:table table:name="huge"><table:table-row><table:table-cell table:number-columns-repeated="100000" ` +
+			`office:value-type="float" office:value="1"/></table:table-row></table:table></office:spreadsheet></office:body></office:document-content>`
+		_, err := decodeODSContent(bytes.NewReader([]byte(content)))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidData, ErrorCodeOf(err))
+	})
+}
+
+func TestFormatODSTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "PT09H30M00S", want: "09:30:00"},
+		{value: "PT13H05M07.5S", want: "13:05:07.5"},
+		{value: "PT1H2M3S", want: "01:02:03"},
+		{value: "PT36H00M00S", want: "36:00:00"},
+		{value: "P1DT2H", want: "P1DT2H"},
+		{value: "09:30", want: "09:30"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatODSTime(tt.value), tt.value)
+	}
+}
+
+func TestOpenODS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("each sheet becomes a table", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "ods", "sample.ods")))
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"sample_products", "sample_summary"}, tables)
+
+		var (
+			name  string
+			price float64
+		)
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT name, price FROM sample_products WHERE id = 2").Scan(&name, &price))
+		assert.Equal(t, "日本のりんご", name)
+		assert.InDelta(t, 0.5, price, 0)
+
+		var priceType string
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT type FROM pragma_table_info('sample_products') WHERE name = 'price'").Scan(&priceType))
+		assert.Equal(t, "REAL", priceType)
+	})
+
+	t.Run("compressed file", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := os.ReadFile(filepath.Join("testdata", "ods", "sample.ods"))
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err = gz.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+
+		path := filepath.Join(t.TempDir(), "sample.ods.gz")
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+
+		db := openTestDB(t, NewBuilder().AddPath(path))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM sample_summary").Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("reader input loads the first sheet", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := os.ReadFile(filepath.Join("testdata", "ods", "sample.ods"))
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddReader(bytes.NewReader(data), "products", FileTypeODS).
+			SetDefaultChunkSize(2))
+
+		var ids string
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT GROUP_CONCAT(id) FROM products WHERE id <> ''").Scan(&ids))
+		assert.Equal(t, "1,2,3", ids)
+	})
+}
@@ -0,0 +1,121 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// DirOption configures which files of a directory OpenDir loads.
+type DirOption func(*dirFilter)
+
+// WithInclude loads only the files of the directory that match one of patterns.
+//
+// Patterns use the syntax of path.Match and are matched against the file name and
+// against the slash-separated path relative to the directory, so "*.csv" matches
+// CSV files at any depth and "2024/*.csv" only those in the 2024 subdirectory.
+// Files must still have a supported extension.
+func WithInclude(patterns ...string) DirOption {
+	return func(f *dirFilter) {
+		f.include = append(f.include, patterns...)
+	}
+}
+
+// WithExclude skips the files and subdirectories of the directory that match one of
+// patterns, even if they match WithInclude. Patterns are matched like WithInclude.
+func WithExclude(patterns ...string) DirOption {
+	return func(f *dirFilter) {
+		f.exclude = append(f.exclude, patterns...)
+	}
+}
+
+// OpenDir creates an SQL database from the supported files in dir and its
+// subdirectories, selected by include and exclude patterns.
+//
+// It covers the common case of loading everything in a directory except a few files,
+// without listing the files first:
+//
+//	db, err := filesql.OpenDir(ctx, "./data",
+//		filesql.WithInclude("*.csv", "*.tsv"),
+//		filesql.WithExclude("*_backup*", "tmp"))
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+//
+// Without options, OpenDir loads the same files as OpenContext(ctx, dir). It fails
+// with an error with ErrCodeInvalidConfig if dir is not a directory or a pattern is
+// malformed, and with ErrCodeUnsupportedFormat if no supported file matches.
+func OpenDir(ctx context.Context, dir string, opts ...DirOption) (*sql.DB, error) {
+	var filter dirFilter
+	for _, opt := range opts {
+		opt(&filter)
+	}
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		return nil, newCodedError(ErrCodeInvalidConfig, "not a directory: %s", dir)
+	}
+
+	builder := NewBuilder().AddPath(dir)
+	builder.fileProcessor.filter = filter
+
+	validatedBuilder, err := builder.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return validatedBuilder.Open(ctx)
+}
+
+// dirFilter selects the files found by walking directories
+type dirFilter struct {
+	// include are the patterns of the files to load; empty loads every file
+	include []string
+	// exclude are the patterns of the files and directories to skip
+	exclude []string
+}
+
+// validate checks that the patterns are well-formed
+func (f dirFilter) validate() error {
+	for _, pattern := range append(append([]string(nil), f.include...), f.exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return newCodedError(ErrCodeInvalidConfig, "invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// includes reports whether the file at filePath, found by walking dir, is loaded
+func (f dirFilter) includes(dir, filePath string) bool {
+	if f.excludes(dir, filePath) {
+		return false
+	}
+	return len(f.include) == 0 || matchesAny(f.include, dir, filePath)
+}
+
+// excludes reports whether the file or directory at filePath, found by walking dir, is skipped
+func (f dirFilter) excludes(dir, filePath string) bool {
+	return matchesAny(f.exclude, dir, filePath)
+}
+
+// matchesAny reports whether one of patterns matches the name or the path relative to
+// dir of filePath
+func matchesAny(patterns []string, dir, filePath string) bool {
+	name := filepath.Base(filePath)
+	relative := name
+	if rel, err := filepath.Rel(dir, filePath); err == nil {
+		relative = filepath.ToSlash(rel)
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, relative); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenDir(t *testing.T) {
+	t.Parallel()
+
+	newDir := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		files := map[string]string{
+			"users.csv":                               "id\n1\n",
+			"users_backup.csv":                        "id\n1\n",
+			"orders.tsv":                              "id\n1\n",
+			"notes.txt":                               "not loaded",
+			filepath.Join("2024", "sales.csv"):        "id\n1\n",
+			filepath.Join("old_backup", "stock.csv"):  "id\n1\n",
+			filepath.Join("2024", "events.ltsv"):      "id:1\n",
+			filepath.Join("2024", "deep", "logs.csv"): "id\n1\n",
+		}
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+		return dir
+	}
+	tablesOf := func(t *testing.T, dir string, opts ...DirOption) []string {
+		t.Helper()
+		db, err := OpenDir(context.Background(), dir, opts...)
+		require.NoError(t, err)
+		defer db.Close()
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		return tables
+	}
+
+	t.Run("without options every supported file is loaded", func(t *testing.T) {
+		t.Parallel()
+
+		assert.ElementsMatch(t,
+			[]string{"users", "users_backup", "orders", "sales", "stock", "events", "logs"},
+			tablesOf(t, newDir(t)))
+	})
+
+	t.Run("include and exclude by file name", func(t *testing.T) {
+		t.Parallel()
+
+		assert.ElementsMatch(t,
+			[]string{"users", "sales", "logs"},
+			tablesOf(t, newDir(t), WithInclude("*.csv"), WithExclude("*_backup*")))
+	})
+
+	t.Run("patterns match relative paths", func(t *testing.T) {
+		t.Parallel()
+
+		assert.ElementsMatch(t,
+			[]string{"sales", "events"},
+			tablesOf(t, newDir(t), WithInclude("2024/*")))
+	})
+
+	t.Run("excluded directories are skipped", func(t *testing.T) {
+		t.Parallel()
+
+		assert.ElementsMatch(t,
+			[]string{"users", "users_backup", "orders"},
+			tablesOf(t, newDir(t), WithExclude("2024", "old_*")))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newDir(t)
+		tests := []struct {
+			name string
+			dir  string
+			opts []DirOption
+			code ErrorCode
+		}{
+			{name: "malformed pattern", dir: dir, opts: []DirOption{WithInclude("[")}, code: ErrCodeInvalidConfig},
+			{name: "not a directory", dir: filepath.Join(dir, "users.csv"), code: ErrCodeInvalidConfig},
+			{name: "nothing matches", dir: dir, opts: []DirOption{WithInclude("*.parquet")}, code: ErrCodeUnsupportedFormat},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := OpenDir(context.Background(), tt.dir, tt.opts...)
+				require.Error(t, err)
+				assert.Equal(t, tt.code, ErrorCodeOf(err))
+			})
+		}
+	})
+}
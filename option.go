@@ -0,0 +1,164 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Option configures how inputs are loaded, shared by OpenWithOptions and DBBuilder.Apply.
+//
+// Options mirror builder methods of the same name, so a simple call can take the one
+// option it needs without switching to the builder, and a set of options can be kept
+// in a slice and applied to both:
+//
+//	options := []filesql.Option{
+//		filesql.WithDelimiter(';'),
+//		filesql.WithHeaderDetection(),
+//	}
+//	db, err := filesql.OpenWithOptions(ctx, []string{"sales.csv"}, options...)
+//
+//	builder := filesql.NewBuilder().AddPath("exports/").Apply(options...)
+//
+// Any other builder method can be used as an option through OptionFunc.
+type Option interface {
+	applyOption(b *DBBuilder)
+}
+
+// OptionFunc is an Option that configures the builder with a function, so that every
+// builder method is available as an option:
+//
+//	window := filesql.OptionFunc(func(b *filesql.DBBuilder) {
+//		b.WithTimeWindow("access_log", "timestamp", from, to)
+//	})
+type OptionFunc func(b *DBBuilder)
+
+// applyOption calls f with the builder
+func (f OptionFunc) applyOption(b *DBBuilder) {
+	if f != nil {
+		f(b)
+	}
+}
+
+// applyOption makes an InputOption set the default of every input of the builder.
+// Options given to AddPathWithOptions and AddReaderWithOptions take precedence.
+func (o InputOption) applyOption(b *DBBuilder) {
+	b.inputOptions = append(b.inputOptions, o)
+}
+
+// WithDelimiterDetection is the Option form of DBBuilder.WithDelimiterDetection.
+func WithDelimiterDetection() Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithDelimiterDetection() })
+}
+
+// WithHeaderDetection is the Option form of DBBuilder.WithHeaderDetection.
+func WithHeaderDetection() Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithHeaderDetection() })
+}
+
+// WithEncodingDetection is the Option form of DBBuilder.WithEncodingDetection.
+func WithEncodingDetection() Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithEncodingDetection() })
+}
+
+// EnableNumericTextPreservation is the Option form of DBBuilder.EnableNumericTextPreservation,
+// which keeps type inference from turning number-like identifiers into numbers.
+func EnableNumericTextPreservation() Option {
+	return OptionFunc(func(b *DBBuilder) { b.EnableNumericTextPreservation() })
+}
+
+// WithTypeInference is the Option form of DBBuilder.WithTypeInference.
+func WithTypeInference(mode TypeInference) Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithTypeInference(mode) })
+}
+
+// EnableEmptyNumericAsNull is the Option form of DBBuilder.EnableEmptyNumericAsNull.
+func EnableEmptyNumericAsNull() Option {
+	return OptionFunc(func(b *DBBuilder) { b.EnableEmptyNumericAsNull() })
+}
+
+// WithNullValues is the Option form of DBBuilder.WithNullValues.
+func WithNullValues(values ...string) Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithNullValues(values...) })
+}
+
+// WithNullConvention is the Option form of DBBuilder.WithNullConvention.
+func WithNullConvention(convention NullConvention) Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithNullConvention(convention) })
+}
+
+// WithEmptyFilePolicy is the Option form of DBBuilder.WithEmptyFilePolicy.
+func WithEmptyFilePolicy(policy EmptyFilePolicy) Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithEmptyFilePolicy(policy) })
+}
+
+// WithBestEffort is the Option form of DBBuilder.WithBestEffort.
+func WithBestEffort() Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithBestEffort() })
+}
+
+// WithTableName is the Option form of DBBuilder.WithTableName.
+func WithTableName(path, name string) Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithTableName(path, name) })
+}
+
+// WithTableCollisionPolicy is the Option form of DBBuilder.WithTableCollisionPolicy.
+func WithTableCollisionPolicy(policy TableCollisionPolicy) Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithTableCollisionPolicy(policy) })
+}
+
+// WithPersistentDB is the Option form of DBBuilder.WithPersistentDB.
+func WithPersistentDB(path string) Option {
+	return OptionFunc(func(b *DBBuilder) { b.WithPersistentDB(path) })
+}
+
+// EnableWatch is the Option form of DBBuilder.EnableWatch.
+func EnableWatch(handler func(WatchEvent)) Option {
+	return OptionFunc(func(b *DBBuilder) { b.EnableWatch(handler) })
+}
+
+// EnableAutoSave is the Option form of DBBuilder.EnableAutoSave.
+func EnableAutoSave(outputDir string, options ...DumpOptions) Option {
+	return OptionFunc(func(b *DBBuilder) { b.EnableAutoSave(outputDir, options...) })
+}
+
+// EnableAutoSaveOnCommit is the Option form of DBBuilder.EnableAutoSaveOnCommit.
+func EnableAutoSaveOnCommit(outputDir string, options ...DumpOptions) Option {
+	return OptionFunc(func(b *DBBuilder) { b.EnableAutoSaveOnCommit(outputDir, options...) })
+}
+
+// Apply configures the builder with options, in order.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("sales.csv").
+//		Apply(filesql.WithDelimiter(';'), filesql.EnableAutoSave("./backup"))
+//
+// Returns self for chaining.
+func (b *DBBuilder) Apply(options ...Option) *DBBuilder {
+	for _, option := range options {
+		if option != nil {
+			option.applyOption(b)
+		}
+	}
+	return b
+}
+
+// OpenWithOptions is like OpenContext but configures the load with options.
+//
+// It is equivalent to building the paths with NewBuilder().AddPaths(paths...).Apply(options...):
+//
+//	db, err := filesql.OpenWithOptions(ctx, []string{"sales.csv"},
+//		filesql.WithDelimiter(';'),
+//		filesql.EnableAutoSave("./backup"))
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+func OpenWithOptions(ctx context.Context, paths []string, options ...Option) (*sql.DB, error) {
+	validatedBuilder, err := NewBuilder().AddPaths(paths...).Apply(options...).Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return validatedBuilder.Open(ctx)
+}
@@ -0,0 +1,96 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenWithOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("options configure the load like builder methods", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "sales.csv")
+		require.NoError(t, os.WriteFile(path, []byte("zip;amount\n01234;100\n90210;250\n"), 0600))
+		backup := filepath.Join(dir, "backup")
+		require.NoError(t, os.Mkdir(backup, 0750))
+
+		ctx := context.Background()
+		db, err := OpenWithOptions(ctx, []string{path},
+			WithDelimiter(';'),
+			EnableNumericTextPreservation(),
+			EnableAutoSave(backup),
+		)
+		require.NoError(t, err)
+
+		var zip string
+		var total int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT MIN(zip), SUM(amount) FROM sales").Scan(&zip, &total))
+		assert.Equal(t, "01234", zip)
+		assert.Equal(t, 350, total)
+		require.NoError(t, db.Close())
+
+		_, err = os.Stat(filepath.Join(backup, "sales.csv"))
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid options fail like the builder", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := OpenWithOptions(context.Background(), []string{filepath.Join("testdata", "sample.csv")}, WithDelimiter('"'))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
+
+func TestDBBuilder_Apply(t *testing.T) {
+	t.Parallel()
+
+	t.Run("an input option applied to the builder is the default of every input", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("a|b\n1|2\n"), "piped", FileTypeCSV).
+			AddReaderWithOptions(strings.NewReader("a;b\n1;2\n"), "semicolons", FileTypeCSV, WithDelimiter(';')).
+			Apply(WithDelimiter('|')))
+
+		for _, table := range []string{"piped", "semicolons"} {
+			columns, err := getSQLiteTableColumns(db, table)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b"}, columns, table)
+		}
+	})
+
+	t.Run("option funcs call builder methods and nil options are ignored", func(t *testing.T) {
+		t.Parallel()
+
+		builder := NewBuilder().Apply(
+			nil,
+			WithHeaderDetection(),
+			WithDelimiterDetection(),
+			EnableAutoSaveOnCommit(""),
+			OptionFunc(func(b *DBBuilder) { b.SetDefaultChunkSize(10) }),
+		)
+		assert.True(t, builder.streamProcessor.detectHeader)
+		assert.True(t, builder.streamProcessor.detectDelimiter)
+		require.NotNil(t, builder.autoSaveConfig)
+		assert.Equal(t, autoSaveOnCommit, builder.autoSaveConfig.timing)
+		assert.Equal(t, 10, builder.defaultChunkSize)
+	})
+
+	t.Run("options applied to a clone do not affect the original", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewBuilder().AddPath(filepath.Join("testdata", "sample.csv"))
+		base.Clone().Apply(WithDelimiter(';'))
+		assert.Empty(t, base.inputOptions)
+	})
+}
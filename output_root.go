@@ -0,0 +1,78 @@
+package filesql
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithOutputRoot restricts output files to root and its subdirectories.
+//
+// Use it when the output directory (or table names, which become file names) may come
+// from user input. DumpDatabase and auto-save then refuse to write outside root and
+// return an error wrapping ErrOutputPathNotAllowed. Paths are compared after resolving
+// "..", and symbolic links of existing directories, so neither "../../etc" nor a link
+// pointing out of root escapes the sandbox.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().WithOutputRoot("/srv/exports")
+//	err := filesql.DumpDatabase(db, filepath.Join("/srv/exports", userDir), options)
+//
+// An empty root disables the restriction (default).
+func (o DumpOptions) WithOutputRoot(root string) DumpOptions {
+	o.OutputRoot = root
+	return o
+}
+
+// checkOutputPath returns an error wrapping ErrOutputPathNotAllowed if path is not
+// root itself or inside root. An empty root allows every path.
+func checkOutputPath(root, path string) error {
+	if root == "" {
+		return nil
+	}
+
+	resolvedRoot, err := resolvePath(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output root %s: %w", root, err)
+	}
+	resolvedPath, err := resolvePath(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output path %s: %w", path, err)
+	}
+
+	rel, err := filepath.Rel(resolvedRoot, resolvedPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return fmt.Errorf("%w: %s is outside of %s", ErrOutputPathNotAllowed, path, root)
+	}
+	return nil
+}
+
+// resolvePath returns the absolute, cleaned form of path with symbolic links of its
+// longest existing prefix resolved. The remaining components need not exist yet.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	existing := abs
+	var rest []string
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return abs, nil
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+}
@@ -0,0 +1,92 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOptions_WithOutputRoot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// setup prepares db and returns the output root and the dump directory
+		setup   func(t *testing.T, db *sql.DB) (root, outputDir string)
+		wantErr bool
+	}{
+		{
+			name: "subdirectory of the root is allowed",
+			setup: func(t *testing.T, _ *sql.DB) (string, string) {
+				root := t.TempDir()
+				return root, filepath.Join(root, "tenant", "exports")
+			},
+		},
+		{
+			name: "path traversal is rejected",
+			setup: func(t *testing.T, _ *sql.DB) (string, string) {
+				root := filepath.Join(t.TempDir(), "root")
+				return root, filepath.Join(root, "..", "escaped")
+			},
+			wantErr: true,
+		},
+		{
+			name: "table names cannot escape the root",
+			setup: func(t *testing.T, db *sql.DB) (string, string) {
+				_, err := db.ExecContext(context.Background(), `ALTER TABLE sample RENAME TO "../evil"`)
+				require.NoError(t, err)
+				root := t.TempDir()
+				return root, root
+			},
+			wantErr: true,
+		},
+		{
+			name: "symbolic links out of the root are rejected",
+			setup: func(t *testing.T, _ *sql.DB) (string, string) {
+				root := t.TempDir()
+				link := filepath.Join(root, "link")
+				if err := os.Symlink(t.TempDir(), link); err != nil {
+					t.Skipf("symbolic links are not supported: %v", err)
+				}
+				return root, link
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+			root, outputDir := tt.setup(t, db)
+
+			err := DumpDatabase(db, outputDir, NewDumpOptions().WithOutputRoot(root))
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrOutputPathNotAllowed)
+				assert.Equal(t, ErrCodeOutputPathNotAllowed, ErrorCodeOf(err))
+				assert.NoFileExists(t, filepath.Join(outputDir, "sample.csv"))
+				return
+			}
+			require.NoError(t, err)
+			assert.FileExists(t, filepath.Join(outputDir, "sample.csv"))
+		})
+	}
+
+	t.Run("auto-save outside the root fails at build time", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		_, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "a", FileTypeCSV).
+			EnableAutoSave(filepath.Join(root, ".."), NewDumpOptions().WithOutputRoot(root)).
+			Build(context.Background())
+		require.ErrorIs(t, err, ErrOutputPathNotAllowed)
+	})
+}
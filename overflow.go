@@ -0,0 +1,155 @@
+package filesql
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// maxSQLiteColumns is the SQLITE_MAX_COLUMN limit compiled into the SQLite driver.
+	// It cannot be raised at runtime.
+	maxSQLiteColumns = 2000
+	// overflowColumnName is the JSON column that receives columns beyond maxSQLiteColumns
+	overflowColumnName = "_overflow"
+	// maxSQLiteLength is the SQLITE_MAX_LENGTH limit (in bytes) for a single value
+	maxSQLiteLength = 1_000_000_000
+)
+
+// EnableColumnOverflow stores columns beyond SQLite's column limit in a JSON column.
+//
+// SQLite supports at most 2000 columns per table. By default, loading a wider file
+// fails with ErrTooManyColumns. With column overflow enabled, the first 1999 columns
+// are loaded as usual and the remaining ones are stored as a JSON object in a TEXT
+// column named "_overflow", which can be queried with SQLite's JSON functions:
+//
+//	SELECT id, json_extract(_overflow, '$.col_2500') FROM wide_table
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableColumnOverflow() *DBBuilder {
+	b.streamProcessor.columnOverflow = true
+	return b
+}
+
+// fitColumns checks a chunk against SQLite's column limit. When column overflow is
+// enabled, columns beyond the limit are folded into a JSON overflow column.
+func (sp *streamProcessor) fitColumns(chunk *tableChunk) (*tableChunk, error) {
+	headers := chunk.getHeaders()
+	if len(headers) <= maxSQLiteColumns {
+		return chunk, nil
+	}
+	if !sp.columnOverflow {
+		return nil, fmt.Errorf("%w: table %s has %d columns, SQLite supports at most %d (use EnableColumnOverflow to store the rest as JSON)",
+			ErrTooManyColumns, chunk.getTableName(), len(headers), maxSQLiteColumns)
+	}
+
+	// Keep room for the overflow column itself
+	keep := maxSQLiteColumns - 1
+	for _, name := range headers[:keep] {
+		if name == overflowColumnName {
+			return nil, fmt.Errorf("table %s already has a column named %s", chunk.getTableName(), overflowColumnName)
+		}
+	}
+
+	newHeaders := make(header, 0, maxSQLiteColumns)
+	newHeaders = append(newHeaders, headers[:keep]...)
+	newHeaders = append(newHeaders, overflowColumnName)
+
+	infos := chunk.getColumnInfo()
+	newColumnInfo := make([]columnInfo, 0, maxSQLiteColumns)
+	if len(infos) >= keep {
+		newColumnInfo = append(newColumnInfo, infos[:keep]...)
+	}
+	newColumnInfo = append(newColumnInfo, newColumnInfoWithType(overflowColumnName, columnTypeText))
+
+	records := make([]Record, 0, len(chunk.getRecords()))
+	for _, record := range chunk.getRecords() {
+		overflow := make(map[string]string, len(headers)-keep)
+		for i := keep; i < len(headers) && i < len(record); i++ {
+			overflow[headers[i]] = record[i]
+		}
+		encoded, err := json.Marshal(overflow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode overflow columns: %w", err)
+		}
+
+		newRecord := make(Record, 0, maxSQLiteColumns)
+		if len(record) > keep {
+			newRecord = append(newRecord, record[:keep]...)
+		} else {
+			newRecord = append(newRecord, record...)
+			for len(newRecord) < keep {
+				newRecord = append(newRecord, "")
+			}
+		}
+		newRecord = append(newRecord, string(encoded))
+		records = append(records, newRecord)
+	}
+
+	return &tableChunk{
+		tableName:  chunk.getTableName(),
+		headers:    newHeaders,
+		records:    records,
+		columnInfo: newColumnInfo,
+	}, nil
+}
+
+// EnableCellTruncation truncates values that exceed SQLite's maximum value size
+// instead of failing the load.
+//
+// SQLite stores at most 1,000,000,000 bytes in a single value. By default, a larger
+// value fails the load with ErrCellTooLarge, identifying the table, record, and column.
+// With truncation enabled, such values are cut to the limit (on a UTF-8 character boundary).
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableCellTruncation() *DBBuilder {
+	b.streamProcessor.truncateCells = true
+	return b
+}
+
+// cellSizeLimit returns the maximum number of bytes allowed in a single value
+func (sp *streamProcessor) cellSizeLimit() int {
+	if sp.maxCellBytes > 0 {
+		return sp.maxCellBytes
+	}
+	return maxSQLiteLength
+}
+
+// fitCellSizes checks every value of a chunk against SQLite's value size limit.
+// firstRecord is the 1-based number of the chunk's first record within its input.
+// When truncation is enabled, oversized values are truncated in place.
+func (sp *streamProcessor) fitCellSizes(chunk *tableChunk, firstRecord int) error {
+	limit := sp.cellSizeLimit()
+	headers := chunk.getHeaders()
+
+	for i, record := range chunk.getRecords() {
+		for j, value := range record {
+			if len(value) <= limit {
+				continue
+			}
+
+			column := ""
+			if j < len(headers) {
+				column = headers[j]
+			}
+			if !sp.truncateCells {
+				return fmt.Errorf("%w: table %s, record %d, column %s: value is %d bytes, SQLite supports at most %d (use EnableCellTruncation to truncate)",
+					ErrCellTooLarge, chunk.getTableName(), firstRecord+i, column, len(value), limit)
+			}
+			record[j] = truncateUTF8(value, limit)
+		}
+	}
+	return nil
+}
+
+// truncateUTF8 shortens s to at most limit bytes without splitting a UTF-8 character
+func truncateUTF8(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -0,0 +1,134 @@
+package filesql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wideCSV returns CSV data with the given number of columns and one data row
+func wideCSV(columns int) string {
+	headers := make([]string, columns)
+	values := make([]string, columns)
+	for i := range columns {
+		headers[i] = fmt.Sprintf("col_%d", i+1)
+		values[i] = fmt.Sprintf("%d", i+1)
+	}
+	return strings.Join(headers, ",") + "\n" + strings.Join(values, ",") + "\n"
+}
+
+func TestDBBuilder_EnableColumnOverflow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("too many columns fails with clear error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := tryOpenTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(wideCSV(maxSQLiteColumns+5)), "wide", FileTypeCSV))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTooManyColumns)
+		assert.Contains(t, err.Error(), "EnableColumnOverflow")
+	})
+
+	t.Run("extra columns are stored as JSON", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(wideCSV(maxSQLiteColumns+5)), "wide", FileTypeCSV).
+			EnableColumnOverflow())
+
+		columns, err := getSQLiteTableColumns(db, "wide")
+		require.NoError(t, err)
+		require.Len(t, columns, maxSQLiteColumns)
+		assert.Equal(t, overflowColumnName, columns[len(columns)-1])
+
+		var first int
+		var lastRegular string
+		var overflowValue string
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT col_1, col_1999, json_extract(_overflow, '$.col_2005') FROM wide`).Scan(&first, &lastRegular, &overflowValue))
+		assert.Equal(t, 1, first)
+		assert.Equal(t, "1999", lastRegular)
+		assert.Equal(t, "2005", overflowValue)
+	})
+
+	t.Run("narrow tables are unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		chunk := &tableChunk{tableName: "t", headers: header{"a"}, records: []Record{{"1"}}}
+		sp := newStreamProcessor(DefaultChunkSize)
+		sp.columnOverflow = true
+		fitted, err := sp.fitColumns(chunk)
+		require.NoError(t, err)
+		assert.Same(t, chunk, fitted)
+	})
+}
+
+func TestDBBuilder_EnableCellTruncation(t *testing.T) {
+	t.Parallel()
+
+	data := "id,note\n1,short\n2,this value is too long\n"
+
+	t.Run("oversized value fails with location", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader(data), "notes", FileTypeCSV).
+			Build(ctx)
+		require.NoError(t, err)
+		validatedBuilder.streamProcessor.maxCellBytes = 10
+
+		_, err = validatedBuilder.Open(ctx)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrCellTooLarge)
+		assert.Contains(t, err.Error(), "table notes, record 2, column note")
+	})
+
+	t.Run("oversized value is truncated when enabled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader(data), "notes", FileTypeCSV).
+			EnableCellTruncation().
+			Build(ctx)
+		require.NoError(t, err)
+		validatedBuilder.streamProcessor.maxCellBytes = 10
+
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+		defer db.Close()
+
+		var note string
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT note FROM notes WHERE id = 2").Scan(&note))
+		assert.Equal(t, "this value", note)
+	})
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		limit int
+		want  string
+	}{
+		{name: "shorter than limit is unchanged", input: "abc", limit: 5, want: "abc"},
+		{name: "ascii is cut at limit", input: "abcdef", limit: 4, want: "abcd"},
+		{name: "multibyte character is not split", input: "aあい", limit: 5, want: "aあ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, truncateUTF8(tt.input, tt.limit))
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package filesql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// pageSubqueryAlias is the alias of the paginated query in the queries that read its pages
+const pageSubqueryAlias = metadataTablePrefix + "page"
+
+// PageOptions configure Paginate. The zero value pages with LIMIT and OFFSET from the
+// first row.
+type PageOptions struct {
+	// Args are the arguments of the placeholders of the query
+	Args []any
+	// KeyColumn is a column of the query's result whose values are unique and not
+	// NULL. When set, pages are read in the order of the column by seeking past the
+	// last key of the previous page instead of skipping rows with OFFSET, so deep
+	// pages are as fast as the first one when the column is indexed, and rows inserted
+	// or deleted between pages do not shift rows into the next page. Rows whose key is
+	// NULL are skipped.
+	KeyColumn string
+	// After is the Cursor of a page returned earlier for the same query and options.
+	// Pagination resumes after that page, so a web service can hand the cursor to a
+	// client and continue in the next request.
+	After string
+}
+
+// Page is a page of the rows of a query, returned by Pager.
+type Page struct {
+	// Columns are the column names of the query's result
+	Columns []string
+	// Rows are the rows of the page. Each row holds one value per column, as
+	// database/sql scans it into an any: int64, float64, string, []byte, or nil.
+	Rows [][]any
+	// Number is the position of the page, starting at 1
+	Number int
+	// Cursor resumes pagination after this page when passed as PageOptions.After. It
+	// is empty on the last page.
+	Cursor string
+}
+
+// Pager reads the rows of a query page by page. It is returned by Paginate.
+//
+// A Pager holds no connection or open rows between pages, so it can be kept while
+// other queries run and dropped without being closed. It is not safe for concurrent use.
+type Pager struct {
+	db        *sql.DB
+	query     string
+	args      []any
+	pageSize  int
+	columns   []string
+	keyColumn string
+	keyIndex  int
+
+	// number is the number of pages read so far, including those skipped by After
+	number int
+	// offset is the number of rows read so far without KeyColumn
+	offset int64
+	// after is the key of the last row read so far with KeyColumn; nil before the first page
+	after any
+	// done is true once the last page was read
+	done bool
+}
+
+// Paginate returns a Pager that reads the result of query in pages of pageSize rows,
+// so dashboards and APIs can serve large results without loading every row into memory
+// or writing LIMIT and OFFSET clauses by hand.
+//
+// query is a single SELECT statement, which may end with a semicolon or a -- comment.
+// By default, pages are read by wrapping it with LIMIT and OFFSET, which keeps the
+// order of the query, so give it an ORDER BY clause for pages that do not change
+// between calls. With PageOptions.KeyColumn, pages are read in the order of that column
+// by keyset pagination instead, which stays fast on deep pages and is not affected by
+// rows inserted before the current page. Each page carries a Cursor that resumes
+// pagination in a later call through PageOptions.After.
+//
+// Example:
+//
+//	pager, err := filesql.Paginate(ctx, db,
+//		"SELECT id, name FROM users WHERE active = ?", 100,
+//		filesql.PageOptions{Args: []any{1}, KeyColumn: "id"})
+//	if err != nil {
+//		return err
+//	}
+//	for page, err := range pager.Pages(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		render(page.Rows)
+//	}
+//
+// Paginate runs the query once without reading rows, to check it and read its columns.
+// It returns an error with ErrCodeInvalidConfig if pageSize is not positive, if query
+// is empty, if KeyColumn is not a column of the result, or if After is not a cursor of
+// the same kind of pagination.
+func Paginate(ctx context.Context, db *sql.DB, query string, pageSize int, options PageOptions) (*Pager, error) {
+	if pageSize <= 0 {
+		return nil, newCodedError(ErrCodeInvalidConfig, "page size must be positive: %d", pageSize)
+	}
+	query = strings.TrimRight(strings.TrimSpace(query), "; \t\r\n")
+	if query == "" {
+		return nil, newCodedError(ErrCodeInvalidConfig, "query cannot be empty")
+	}
+
+	p := &Pager{db: db, query: query, args: options.Args, pageSize: pageSize, keyIndex: -1}
+	columns, err := p.queryColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.columns = columns
+	if options.KeyColumn != "" {
+		for i, column := range columns {
+			if strings.EqualFold(column, options.KeyColumn) {
+				p.keyColumn, p.keyIndex = column, i
+				break
+			}
+		}
+		if p.keyIndex < 0 {
+			return nil, newCodedError(ErrCodeInvalidConfig, "key column %s is not a column of the query", options.KeyColumn)
+		}
+	}
+	if options.After != "" {
+		if err := p.resume(options.After); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// queryColumns returns the column names of the result of the query
+func (p *Pager) queryColumns(ctx context.Context) ([]string, error) {
+	rows, err := p.db.QueryContext(ctx, p.from()+" LIMIT 0", p.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to paginate query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to paginate query: %w", err)
+	}
+	return columns, rows.Err()
+}
+
+// Next reads the next page. It returns a nil page once every row was read; the first
+// page is returned even when the query has no rows, so its columns are known.
+func (p *Pager) Next(ctx context.Context) (*Page, error) {
+	if p.done {
+		return nil, nil
+	}
+
+	rows, err := p.db.QueryContext(ctx, p.pageQuery(), p.pageArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %w", p.number+1, err)
+	}
+	defer rows.Close()
+
+	var values [][]any
+	for rows.Next() {
+		row := make([]any, len(p.columns))
+		dest := make([]any, len(row))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", p.number+1, err)
+		}
+		values = append(values, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %w", p.number+1, err)
+	}
+
+	// One row more than a page is read to know whether another page follows
+	if len(values) > p.pageSize {
+		values = values[:p.pageSize]
+	} else {
+		p.done = true
+	}
+	if len(values) == 0 && p.number > 0 {
+		return nil, nil
+	}
+
+	p.number++
+	p.offset += int64(len(values))
+	if p.keyIndex >= 0 && len(values) > 0 {
+		p.after = values[len(values)-1][p.keyIndex]
+	}
+	page := &Page{Columns: p.columns, Rows: values, Number: p.number}
+	if !p.done {
+		if page.Cursor, err = p.cursor(); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// Pages returns an iterator over the remaining pages, for use with range. Iteration
+// stops after the last page or after yielding an error.
+func (p *Pager) Pages(ctx context.Context) iter.Seq2[*Page, error] {
+	return func(yield func(*Page, error) bool) {
+		for {
+			page, err := p.Next(ctx)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if page == nil || !yield(page, nil) {
+				return
+			}
+		}
+	}
+}
+
+// from returns the statement that selects every row of the query. The closing
+// parenthesis starts a new line, so a -- comment at the end of the query does not
+// comment it out.
+func (p *Pager) from() string {
+	return fmt.Sprintf("SELECT * FROM (%s\n) AS %s", p.query, quoteIdentifier(pageSubqueryAlias)) //nolint:gosec // The query is provided by the caller
+}
+
+// pageQuery returns the statement that reads the next page and one more row
+func (p *Pager) pageQuery() string {
+	from := p.from()
+	if p.keyIndex < 0 {
+		return from + " LIMIT ? OFFSET ?"
+	}
+	key := quoteIdentifier(p.keyColumn)
+	if p.after == nil {
+		return fmt.Sprintf("%s WHERE %s IS NOT NULL ORDER BY %s LIMIT ?", from, key, key)
+	}
+	return fmt.Sprintf("%s WHERE %s > ? ORDER BY %s LIMIT ?", from, key, key)
+}
+
+// pageArgs returns the arguments of pageQuery
+func (p *Pager) pageArgs() []any {
+	args := append([]any{}, p.args...)
+	if p.keyIndex < 0 {
+		return append(args, p.pageSize+1, p.offset)
+	}
+	if p.after != nil {
+		args = append(args, p.after)
+	}
+	return append(args, p.pageSize+1)
+}
+
+// pageCursor is the position a Page.Cursor resumes pagination at
+type pageCursor struct {
+	// Number is the number of pages read
+	Number int `json:"n"`
+	// Offset is the number of rows read without KeyColumn
+	Offset int64 `json:"o,omitempty"`
+	// KeyType and Key are the type and value of the last key read with KeyColumn
+	KeyType string          `json:"t,omitempty"`
+	Key     json.RawMessage `json:"k,omitempty"`
+}
+
+// Key types of pageCursor
+const (
+	pageKeyInteger = "integer"
+	pageKeyReal    = "real"
+	pageKeyText    = "text"
+	pageKeyBlob    = "blob"
+)
+
+// cursor returns the cursor that resumes pagination after the pages read so far
+func (p *Pager) cursor() (string, error) {
+	position := pageCursor{Number: p.number, Offset: p.offset}
+	if p.keyIndex >= 0 {
+		position.Offset = 0
+		switch p.after.(type) {
+		case int64:
+			position.KeyType = pageKeyInteger
+		case float64:
+			position.KeyType = pageKeyReal
+		case string:
+			position.KeyType = pageKeyText
+		case []byte:
+			position.KeyType = pageKeyBlob
+		default:
+			return "", newCodedError(ErrCodeInvalidData,
+				"cannot paginate by key column %s: unsupported value type %T", p.keyColumn, p.after)
+		}
+		key, err := json.Marshal(p.after)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode page cursor: %w", err)
+		}
+		position.Key = key
+	}
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode page cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// resume moves the pager to the position of cursor
+func (p *Pager) resume(cursor string) error {
+	invalid := newCodedError(ErrCodeInvalidConfig, "invalid page cursor: %s", cursor)
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return invalid
+	}
+	var position pageCursor
+	if err := json.Unmarshal(data, &position); err != nil || position.Number < 1 || position.Offset < 0 {
+		return invalid
+	}
+	if (position.KeyType != "") != (p.keyIndex >= 0) {
+		return newCodedError(ErrCodeInvalidConfig, "page cursor does not match the key column of the pagination")
+	}
+
+	p.number = position.Number
+	p.offset = position.Offset
+	if p.keyIndex < 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(position.Key))
+	decoder.UseNumber()
+	var key any
+	if err := decoder.Decode(&key); err != nil {
+		return invalid
+	}
+	switch position.KeyType {
+	case pageKeyInteger, pageKeyReal:
+		number, ok := key.(json.Number)
+		if !ok {
+			return invalid
+		}
+		if position.KeyType == pageKeyInteger {
+			p.after, err = number.Int64()
+		} else {
+			p.after, err = number.Float64()
+		}
+		if err != nil {
+			return invalid
+		}
+	case pageKeyText:
+		text, ok := key.(string)
+		if !ok {
+			return invalid
+		}
+		p.after = text
+	case pageKeyBlob:
+		text, ok := key.(string)
+		if !ok {
+			return invalid
+		}
+		if p.after, err = base64.StdEncoding.DecodeString(text); err != nil {
+			return invalid
+		}
+	default:
+		return invalid
+	}
+	return nil
+}
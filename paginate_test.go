@@ -0,0 +1,229 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	// open loads a table of n users with ids 1 to n
+	open := func(t *testing.T, n int) *sql.DB {
+		t.Helper()
+
+		var data strings.Builder
+		data.WriteString("id,name\n")
+		for i := 1; i <= n; i++ {
+			fmt.Fprintf(&data, "%d,user%d\n", i, i)
+		}
+		return openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(data.String()), "users", FileTypeCSV))
+	}
+	// ids returns the ids of the rows of every page
+	ids := func(t *testing.T, pager *Pager) [][]int64 {
+		t.Helper()
+
+		var pages [][]int64
+		for page, err := range pager.Pages(context.Background()) {
+			require.NoError(t, err)
+			var pageIDs []int64
+			for _, row := range page.Rows {
+				pageIDs = append(pageIDs, row[0].(int64))
+			}
+			pages = append(pages, pageIDs)
+		}
+		return pages
+	}
+
+	t.Run("pages with LIMIT and OFFSET", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 5)
+		pager, err := Paginate(context.Background(), db, "SELECT id, name FROM users ORDER BY id;", 2, PageOptions{})
+		require.NoError(t, err)
+
+		page, err := pager.Next(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name"}, page.Columns)
+		assert.Equal(t, [][]any{{int64(1), "user1"}, {int64(2), "user2"}}, page.Rows)
+		assert.Equal(t, 1, page.Number)
+		assert.NotEmpty(t, page.Cursor)
+
+		assert.Equal(t, [][]int64{{3, 4}, {5}}, ids(t, pager))
+		page, err = pager.Next(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, page, "no page follows the last one")
+	})
+
+	t.Run("pages by key column", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 5)
+		pager, err := Paginate(context.Background(), db, "SELECT id FROM users WHERE id <> ?", 2,
+			PageOptions{Args: []any{3}, KeyColumn: "ID"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]int64{{1, 2}, {4, 5}}, ids(t, pager))
+	})
+
+	t.Run("rows inserted before the current page do not shift pages by key", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 4)
+		pager, err := Paginate(context.Background(), db, "SELECT id FROM users", 2, PageOptions{KeyColumn: "id"})
+		require.NoError(t, err)
+		_, err = pager.Next(context.Background())
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(context.Background(), "INSERT INTO users (id, name) VALUES (0, 'user0')")
+		require.NoError(t, err)
+		assert.Equal(t, [][]int64{{3, 4}}, ids(t, pager))
+	})
+
+	t.Run("cursors resume pagination", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 5)
+		for _, options := range []PageOptions{{}, {KeyColumn: "id"}} {
+			pager, err := Paginate(context.Background(), db, "SELECT id FROM users ORDER BY id", 2, options)
+			require.NoError(t, err)
+			page, err := pager.Next(context.Background())
+			require.NoError(t, err)
+
+			options.After = page.Cursor
+			resumed, err := Paginate(context.Background(), db, "SELECT id FROM users ORDER BY id", 2, options)
+			require.NoError(t, err)
+			page, err = resumed.Next(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 2, page.Number)
+			assert.Equal(t, [][]any{{int64(3)}, {int64(4)}}, page.Rows)
+			assert.Equal(t, [][]int64{{5}}, ids(t, resumed))
+		}
+	})
+
+	t.Run("cursors of text keys resume pagination", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 3)
+		pager, err := Paginate(context.Background(), db, "SELECT name FROM users", 1, PageOptions{KeyColumn: "name"})
+		require.NoError(t, err)
+		page, err := pager.Next(context.Background())
+		require.NoError(t, err)
+
+		resumed, err := Paginate(context.Background(), db, "SELECT name FROM users", 1,
+			PageOptions{KeyColumn: "name", After: page.Cursor})
+		require.NoError(t, err)
+		page, err = resumed.Next(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, [][]any{{"user2"}}, page.Rows)
+	})
+
+	t.Run("the last page has no cursor", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 2)
+		pager, err := Paginate(context.Background(), db, "SELECT id FROM users", 2, PageOptions{})
+		require.NoError(t, err)
+		page, err := pager.Next(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, page.Rows, 2)
+		assert.Empty(t, page.Cursor)
+	})
+
+	t.Run("the first page of an empty result is returned", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 2)
+		pager, err := Paginate(context.Background(), db, "SELECT id, name FROM users WHERE id > 10", 2, PageOptions{})
+		require.NoError(t, err)
+		page, err := pager.Next(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, page)
+		assert.Equal(t, []string{"id", "name"}, page.Columns)
+		assert.Empty(t, page.Rows)
+
+		page, err = pager.Next(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, page)
+	})
+
+	endingTests := []struct {
+		name    string
+		query   string
+		options PageOptions
+	}{
+		{name: "a trailing semicolon", query: "SELECT id FROM users ORDER BY id ; \n"},
+		{name: "a trailing comment", query: "SELECT id FROM users ORDER BY id -- every user"},
+		{name: "a trailing comment with a key column", query: "SELECT id FROM users -- every user", options: PageOptions{KeyColumn: "id"}},
+		{name: "a semicolon in a trailing comment", query: "SELECT id FROM users ORDER BY id -- every user;"},
+	}
+	for _, tt := range endingTests {
+		t.Run("queries ending with "+tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pager, err := Paginate(context.Background(), open(t, 3), tt.query, 2, tt.options)
+			require.NoError(t, err)
+			assert.Equal(t, [][]int64{{1, 2}, {3}}, ids(t, pager))
+		})
+	}
+
+	t.Run("invalid options are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 3)
+		tests := []struct {
+			name     string
+			query    string
+			pageSize int
+			options  PageOptions
+		}{
+			{name: "zero page size", query: "SELECT id FROM users", pageSize: 0},
+			{name: "empty query", query: " ; ", pageSize: 1},
+			{name: "unknown key column", query: "SELECT id FROM users", pageSize: 1, options: PageOptions{KeyColumn: "missing"}},
+			{name: "malformed cursor", query: "SELECT id FROM users", pageSize: 1, options: PageOptions{After: "not a cursor"}},
+		}
+		for _, tt := range tests {
+			_, err := Paginate(context.Background(), db, tt.query, tt.pageSize, tt.options)
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err), tt.name)
+		}
+
+		pager, err := Paginate(context.Background(), db, "SELECT id FROM users", 1, PageOptions{})
+		require.NoError(t, err)
+		page, err := pager.Next(context.Background())
+		require.NoError(t, err)
+		_, err = Paginate(context.Background(), db, "SELECT id FROM users", 1, PageOptions{KeyColumn: "id", After: page.Cursor})
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err), "an offset cursor cannot resume pagination by key")
+	})
+
+	t.Run("invalid queries fail", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 1)
+		_, err := Paginate(context.Background(), db, "SELECT missing FROM users", 1, PageOptions{})
+		require.Error(t, err)
+	})
+
+	t.Run("cancelled contexts stop iteration", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t, 3)
+		pager, err := Paginate(context.Background(), db, "SELECT id FROM users", 1, PageOptions{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var errs []error
+		for page, err := range pager.Pages(ctx) {
+			assert.Nil(t, page)
+			errs = append(errs, err)
+		}
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], context.Canceled)
+	})
+}
@@ -0,0 +1,98 @@
+package filesql
+
+import (
+	"strings"
+
+	"github.com/apache/arrow/go/v18/parquet"
+	"github.com/apache/arrow/go/v18/parquet/compress"
+)
+
+// ParquetCompression is the codec Parquet files compress their column data with.
+type ParquetCompression int
+
+const (
+	// ParquetUncompressed writes uncompressed column data (default)
+	ParquetUncompressed ParquetCompression = iota
+	// ParquetSnappy compresses column data with Snappy, which is fast and widely supported
+	ParquetSnappy
+	// ParquetGzip compresses column data with gzip
+	ParquetGzip
+	// ParquetZSTD compresses column data with Zstandard, which gives small files at good speed
+	ParquetZSTD
+	// ParquetBrotli compresses column data with Brotli
+	ParquetBrotli
+	// ParquetLZ4 compresses column data with LZ4 (the LZ4_RAW codec of the Parquet format)
+	ParquetLZ4
+)
+
+// String returns the string representation of ParquetCompression
+func (c ParquetCompression) String() string {
+	switch c {
+	case ParquetUncompressed:
+		return "none"
+	case ParquetSnappy:
+		return "snappy"
+	case ParquetGzip:
+		return "gzip"
+	case ParquetZSTD:
+		return "zstd"
+	case ParquetBrotli:
+		return "brotli"
+	case ParquetLZ4:
+		return "lz4"
+	default:
+		return "none"
+	}
+}
+
+// codec returns the Parquet codec of the compression
+func (c ParquetCompression) codec() compress.Compression {
+	switch c {
+	case ParquetSnappy:
+		return compress.Codecs.Snappy
+	case ParquetGzip:
+		return compress.Codecs.Gzip
+	case ParquetZSTD:
+		return compress.Codecs.Zstd
+	case ParquetBrotli:
+		return compress.Codecs.Brotli
+	case ParquetLZ4:
+		return compress.Codecs.Lz4Raw
+	default:
+		return compress.Codecs.Uncompressed
+	}
+}
+
+// writerProperties returns the properties of Parquet writers that use the compression
+func (c ParquetCompression) writerProperties() *parquet.WriterProperties {
+	return parquet.NewWriterProperties(parquet.WithCompression(c.codec()))
+}
+
+// WithParquetCompression sets the codec Parquet output compresses its column data with.
+//
+// Parquet files are compressed internally, column by column, so they cannot be
+// wrapped in gzip or another external compression (see WithCompression). By default
+// the column data is not compressed. Choose a codec to make archival exports smaller;
+// filesql and other Parquet readers decompress every codec transparently, and the
+// file extension stays .parquet. Other output formats ignore the option.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().
+//		WithFormat(filesql.OutputFormatParquet).
+//		WithParquetCompression(filesql.ParquetZSTD)
+//	err := filesql.DumpDatabase(db, "./archive", options)
+func (o DumpOptions) WithParquetCompression(compression ParquetCompression) DumpOptions {
+	o.ParquetCompression = compression
+	return o
+}
+
+// parseParquetCompression converts a codec name into a ParquetCompression
+func parseParquetCompression(name string) (ParquetCompression, error) {
+	for _, compression := range []ParquetCompression{ParquetUncompressed, ParquetSnappy, ParquetGzip, ParquetZSTD, ParquetBrotli, ParquetLZ4} {
+		if strings.EqualFold(name, compression.String()) {
+			return compression, nil
+		}
+	}
+	return ParquetUncompressed, newCodedError(ErrCodeInvalidConfig, "unsupported Parquet compression: %s", name)
+}
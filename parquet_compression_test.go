@@ -0,0 +1,112 @@
+package filesql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v18/parquet/compress"
+	pqfile "github.com/apache/arrow/go/v18/parquet/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parquetCodec returns the codec of the first column chunk of a Parquet file
+func parquetCodec(t *testing.T, path string) compress.Compression {
+	t.Helper()
+
+	reader, err := pqfile.OpenParquetFile(path, false)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	rowGroup := reader.MetaData().RowGroup(0)
+	column, err := rowGroup.ColumnChunk(0)
+	require.NoError(t, err)
+	return column.Compression()
+}
+
+func TestWithParquetCompression(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		compression ParquetCompression
+		codec       compress.Compression
+	}{
+		{compression: ParquetUncompressed, codec: compress.Codecs.Uncompressed},
+		{compression: ParquetSnappy, codec: compress.Codecs.Snappy},
+		{compression: ParquetGzip, codec: compress.Codecs.Gzip},
+		{compression: ParquetZSTD, codec: compress.Codecs.Zstd},
+		{compression: ParquetBrotli, codec: compress.Codecs.Brotli},
+		{compression: ParquetLZ4, codec: compress.Codecs.Lz4Raw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.compression.String(), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			db := openTestDB(t, NewBuilder().
+				AddReader(strings.NewReader("name,price\napple,100\nbanana,50\n"), "products", FileTypeCSV))
+
+			outputDir := t.TempDir()
+			options := NewDumpOptions().
+				WithFormat(OutputFormatParquet).
+				WithParquetCompression(tt.compression)
+			require.NoError(t, DumpDatabase(db, outputDir, options))
+
+			path := filepath.Join(outputDir, "products.parquet")
+			assert.Equal(t, tt.codec, parquetCodec(t, path))
+
+			// The compressed file loads again
+			reloaded := openTestDB(t, NewBuilder().AddPath(path))
+			var name string
+			require.NoError(t, reloaded.QueryRowContext(ctx, "SELECT name FROM products WHERE price = '50'").Scan(&name))
+			assert.Equal(t, "banana", name)
+		})
+	}
+
+	t.Run("configuration files set the codec", func(t *testing.T) {
+		t.Parallel()
+
+		options, err := AutoSaveSettings{Format: "parquet", ParquetCompression: "ZSTD"}.dumpOptions()
+		require.NoError(t, err)
+		assert.Equal(t, ParquetZSTD, options.ParquetCompression)
+	})
+
+	t.Run("external compression is still rejected", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+
+		options := NewDumpOptions().
+			WithFormat(OutputFormatParquet).
+			WithCompression(CompressionGZ).
+			WithParquetCompression(ParquetSnappy)
+		err := DumpDatabase(db, t.TempDir(), options)
+		require.ErrorContains(t, err, "WithParquetCompression")
+	})
+}
+
+func TestParquetCompressionFilesAreSmaller(t *testing.T) {
+	t.Parallel()
+
+	var data strings.Builder
+	data.WriteString("id,status\n")
+	for i := range 5000 {
+		fmt.Fprintf(&data, "%d,order %d was delivered to the customer on time\n", i, i)
+	}
+	db := openTestDB(t, NewBuilder().AddReader(strings.NewReader(data.String()), "orders", FileTypeCSV))
+
+	sizes := make(map[ParquetCompression]int64)
+	for _, compression := range []ParquetCompression{ParquetUncompressed, ParquetZSTD} {
+		outputDir := t.TempDir()
+		options := NewDumpOptions().WithFormat(OutputFormatParquet).WithParquetCompression(compression)
+		require.NoError(t, DumpDatabase(db, outputDir, options))
+		info, err := os.Stat(filepath.Join(outputDir, "orders.parquet"))
+		require.NoError(t, err)
+		sizes[compression] = info.Size()
+	}
+	assert.Less(t, sizes[ParquetZSTD], sizes[ParquetUncompressed])
+}
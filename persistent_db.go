@@ -0,0 +1,284 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// persistentSourcesTable records the files a persistent database was loaded from
+const persistentSourcesTable = metadataTablePrefix + "persistent_sources"
+
+// persistentDBBusyTimeout is how long, in milliseconds, a connection to a persistent
+// database waits for another connection that locks it
+const persistentDBBusyTimeout = 5000
+
+// WithPersistentDB loads the inputs into the SQLite database file at path instead of
+// an in-memory database.
+//
+// The data lives on disk rather than in memory, so datasets larger than RAM can be
+// loaded, and the database outlives the process. When Open finds a database at path
+// that was loaded from the same files, with the same sizes and modification times, it
+// opens that database without loading the files again. Otherwise, for instance when a
+// file changed or an input was added, the database file is replaced and the inputs are
+// loaded into it. Inputs other than files, such as readers, fs.FS, and S3 objects,
+// cannot be checked, so they always reload the database.
+//
+// The check covers the input files only: after changing load options such as the
+// delimiter or the column types, remove the database file to reload it. Changes made
+// through SQL are written to the database file, and are kept when it is opened again.
+//
+// Open fails with ErrCodeInvalidConfig if path is a file that is not a database
+// created by WithPersistentDB, so an existing database is never replaced. Build fails
+// with ErrCodeInvalidConfig when WithPersistentDB is combined with EnableLazyLoading,
+// and StartLoad fails with ErrCodeInvalidConfig; use Open instead.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("logs/").
+//		WithPersistentDB("/var/cache/myapp/logs.db")
+//	// The first Open loads logs/; later ones reuse the database until a file changes
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithPersistentDB(path string) *DBBuilder {
+	b.persistentDB = path
+	return b
+}
+
+// validatePersistentDB checks that the persistent database can be used with the other options
+func (b *DBBuilder) validatePersistentDB() error {
+	if b.persistentDB == "" {
+		return nil
+	}
+	if b.lazyLoading {
+		return newCodedError(ErrCodeInvalidConfig, "WithPersistentDB cannot be combined with EnableLazyLoading")
+	}
+	if info, err := os.Stat(b.persistentDB); err == nil && info.IsDir() {
+		return newCodedError(ErrCodeInvalidConfig, "persistent database path is a directory: %s", b.persistentDB)
+	}
+	if _, err := os.Stat(filepath.Dir(b.persistentDB)); err != nil {
+		return newCodedError(ErrCodeInvalidConfig, "directory of persistent database does not exist: %s", b.persistentDB)
+	}
+	return nil
+}
+
+// persistentSource is a file a persistent database was loaded from
+type persistentSource struct {
+	// path is the absolute path of the file
+	path string
+	// size is the size of the file in bytes
+	size int64
+	// modTime is the modification time of the file in Unix nanoseconds
+	modTime int64
+}
+
+// persistentSources returns the files the inputs are loaded from, or nil if an input
+// cannot be checked for changes
+func (b *DBBuilder) persistentSources() ([]persistentSource, error) {
+	if len(b.readers) > 0 {
+		return nil, nil
+	}
+	return statPersistentSources(b.collectedPaths)
+}
+
+// statPersistentSources returns the files at paths as they are now
+func statPersistentSources(paths []string) ([]persistentSource, error) {
+	sources := make([]persistentSource, 0, len(paths))
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		sources = append(sources, persistentSource{path: absPath, size: info.Size(), modTime: info.ModTime().UnixNano()})
+	}
+	return sources, nil
+}
+
+// checkPersistentDB reports whether the persistent database was loaded from the
+// current input files, which it returns. Otherwise it removes the database file, so
+// the inputs are loaded into a new one. Without WithPersistentDB, it reports false.
+func (b *DBBuilder) checkPersistentDB(ctx context.Context) (bool, []persistentSource, error) {
+	if b.persistentDB == "" {
+		return false, nil, nil
+	}
+	sources, err := b.persistentSources()
+	if err != nil {
+		return false, nil, err
+	}
+
+	path := b.persistentDB
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, sources, nil
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to stat persistent database %s: %w", path, err)
+	}
+	if info.Size() > 0 {
+		recorded, err := readPersistentSources(ctx, path)
+		if err != nil {
+			return false, nil, err
+		}
+		if sources != nil && samePersistentSources(recorded, sources) {
+			return true, sources, nil
+		}
+	}
+	return false, sources, removePersistentDB(path)
+}
+
+// loadPersistentInputs loads every input into db like loadInputs and, with
+// WithPersistentDB, records that db was loaded from sources
+func (b *DBBuilder) loadPersistentInputs(ctx context.Context, db *sql.DB, sources []persistentSource) error {
+	if b.persistentDB == "" {
+		return b.loadInputs(ctx, db, b.streamProcessor)
+	}
+	if err := createPersistentSourcesTable(ctx, db); err != nil {
+		return err
+	}
+	if err := b.loadInputs(ctx, db, b.streamProcessor); err != nil {
+		return err
+	}
+	return recordPersistentSources(ctx, db, sources)
+}
+
+// readPersistentSources returns the files the persistent database at path was loaded
+// from. It fails if the file is not a database created by WithPersistentDB.
+func readPersistentSources(ctx context.Context, path string) (map[string]persistentSource, error) {
+	db, err := sql.Open("sqlite", persistentDBDSN(path)+"&mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open persistent database %s: %w", path, err)
+	}
+	defer db.Close()
+
+	notPersistentDB := newCodedError(ErrCodeInvalidConfig,
+		"%s is not a database created by WithPersistentDB; remove it or choose another path", path)
+	exists, err := tableExists(ctx, db, persistentSourcesTable)
+	if err != nil || !exists {
+		return nil, notPersistentDB
+	}
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT path, size, mod_time FROM %s", //nolint:gosec // Table name is a constant
+		quoteIdentifier(persistentSourcesTable)))
+	if err != nil {
+		return nil, notPersistentDB
+	}
+	defer rows.Close()
+
+	recorded := make(map[string]persistentSource)
+	for rows.Next() {
+		var source persistentSource
+		if err := rows.Scan(&source.path, &source.size, &source.modTime); err != nil {
+			return nil, fmt.Errorf("failed to read persistent database %s: %w", path, err)
+		}
+		recorded[source.path] = source
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read persistent database %s: %w", path, err)
+	}
+	return recorded, nil
+}
+
+// samePersistentSources reports whether sources are the recorded files, unchanged
+func samePersistentSources(recorded map[string]persistentSource, sources []persistentSource) bool {
+	if len(recorded) == 0 || len(recorded) != len(sources) {
+		return false
+	}
+	for _, source := range sources {
+		if recorded[source.path] != source {
+			return false
+		}
+	}
+	return true
+}
+
+// removePersistentDB removes the persistent database at path with its journal files
+func removePersistentDB(path string) error {
+	for _, name := range []string{path, path + "-wal", path + "-shm", path + "-journal"} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove persistent database %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// createPersistentSourcesTable creates the empty table of the files the persistent
+// database is loaded from. It is filled once loading has finished, so a database whose
+// load was interrupted is loaded again.
+func createPersistentSourcesTable(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		"CREATE TABLE IF NOT EXISTS %s (path TEXT PRIMARY KEY, size INTEGER NOT NULL, mod_time INTEGER NOT NULL)",
+		quoteIdentifier(persistentSourcesTable))); err != nil {
+		return fmt.Errorf("failed to create persistent sources table: %w", err)
+	}
+	return nil
+}
+
+// recordPersistentSources records the files the persistent database was loaded from
+func recordPersistentSources(ctx context.Context, db *sql.DB, sources []persistentSource) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after Commit is a no-op
+
+	insert := fmt.Sprintf("INSERT OR REPLACE INTO %s (path, size, mod_time) VALUES (?, ?, ?)", //nolint:gosec // Table name is a constant
+		quoteIdentifier(persistentSourcesTable))
+	for _, source := range sources {
+		if _, err := tx.ExecContext(ctx, insert, source.path, source.size, source.modTime); err != nil {
+			return fmt.Errorf("failed to record persistent sources: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// persistentDBDSN returns the URI of the SQLite database file at path
+func persistentDBDSN(path string) string {
+	return sqliteFileURI(path, fmt.Sprintf("_pragma=busy_timeout(%d)", persistentDBBusyTimeout))
+}
+
+// sqliteFileURI returns the URI of the SQLite database file at path with the query parameters query
+func sqliteFileURI(path, query string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	uri := url.URL{Scheme: "file", Path: filepath.ToSlash(absPath), RawQuery: query}
+	if filepath.VolumeName(absPath) != "" {
+		uri.Path = "/" + uri.Path // file:///C:/data.db
+	}
+	return uri.String()
+}
+
+// newDatabaseConnector opens the database file of WithPersistentDB, or creates a new
+// in-memory database
+func (b *DBBuilder) newDatabaseConnector(references []attachedReference) (*memoryConnector, error) {
+	if b.persistentDB != "" {
+		return newPersistentConnector(b.persistentDB, references...)
+	}
+	return newMemoryConnector(references...)
+}
+
+// newPersistentConnector opens the SQLite database file at path, creating it if it
+// does not exist. The database uses write-ahead logging, so queries do not wait for
+// writes, and its connections have the reference data attached.
+func newPersistentConnector(path string, references ...attachedReference) (*memoryConnector, error) {
+	dsn := persistentDBDSN(path) + "&_pragma=journal_mode(WAL)"
+	sqliteDriver, err := registeredSQLiteDriver()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open persistent database: %w", err)
+	}
+	anchor, err := sqliteDriver.Open(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open persistent database %s: %w", path, err)
+	}
+	return &memoryConnector{dsn: dsn, anchor: anchor, references: references, driver: sqliteDriver}, nil
+}
@@ -0,0 +1,139 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithPersistentDB(t *testing.T) {
+	t.Parallel()
+
+	const names = "SELECT name FROM users ORDER BY id"
+
+	t.Run("an unchanged database is reused", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		csvPath := filepath.Join(dir, "users.csv")
+		dbPath := filepath.Join(dir, "cache.db")
+		require.NoError(t, os.WriteFile(csvPath, []byte("id,name\n1,alice\n2,bob\n"), 0600))
+
+		db, err := tryOpenTestDB(t, NewBuilder().AddPath(csvPath).WithPersistentDB(dbPath))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "bob"}, queryRows(t, db, names))
+		// The change is only kept if the database is not loaded again
+		_, err = db.ExecContext(context.Background(), "UPDATE users SET name = 'carol' WHERE id = 2")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+		assert.FileExists(t, dbPath)
+
+		db, err = tryOpenTestDB(t, NewBuilder().AddPath(csvPath).WithPersistentDB(dbPath))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "carol"}, queryRows(t, db, names))
+		require.NoError(t, db.Close())
+	})
+
+	t.Run("changed files reload the database", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		csvPath := filepath.Join(dir, "users.csv")
+		dbPath := filepath.Join(dir, "cache.db")
+		require.NoError(t, os.WriteFile(csvPath, []byte("id,name\n1,alice\n"), 0600))
+		db, err := tryOpenTestDB(t, NewBuilder().AddPath(csvPath).WithPersistentDB(dbPath))
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		require.NoError(t, os.WriteFile(csvPath, []byte("id,name\n1,alice\n2,dave\n"), 0600))
+		db, err = tryOpenTestDB(t, NewBuilder().AddPath(csvPath).WithPersistentDB(dbPath))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "dave"}, queryRows(t, db, names))
+		require.NoError(t, db.Close())
+
+		// An added input reloads the database too
+		otherPath := filepath.Join(dir, "orders.csv")
+		require.NoError(t, os.WriteFile(otherPath, []byte("id\n1\n"), 0600))
+		db, err = tryOpenTestDB(t, NewBuilder().AddPaths(csvPath, otherPath).WithPersistentDB(dbPath))
+		require.NoError(t, err)
+		defer db.Close()
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"users", "orders"}, tables)
+	})
+
+	t.Run("readers always reload the database", func(t *testing.T) {
+		t.Parallel()
+
+		dbPath := filepath.Join(t.TempDir(), "cache.db")
+		for _, name := range []string{"alice", "erin"} {
+			db, err := tryOpenTestDB(t, NewBuilder().
+				AddReader(strings.NewReader("id,name\n1,"+name+"\n"), "users", FileTypeCSV).
+				WithPersistentDB(dbPath))
+			require.NoError(t, err)
+			assert.Equal(t, []string{name}, queryRows(t, db, names))
+			require.NoError(t, db.Close())
+		}
+	})
+
+	t.Run("other files are not replaced", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		dbPath := filepath.Join(dir, "notes.db")
+		require.NoError(t, os.WriteFile(dbPath, []byte("important notes"), 0600))
+
+		_, err := tryOpenTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")).WithPersistentDB(dbPath))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+		content, err := os.ReadFile(dbPath) //nolint:gosec // Test file
+		require.NoError(t, err)
+		assert.Equal(t, "important notes", string(content))
+	})
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "lazy loading cannot be combined",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().
+					AddPath(filepath.Join("testdata", "sample.csv")).
+					WithPersistentDB(filepath.Join(t.TempDir(), "cache.db")).
+					EnableLazyLoading()
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name: "directories are rejected",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")).WithPersistentDB(t.TempDir())
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name: "the parent directory must exist",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().
+					AddPath(filepath.Join("testdata", "sample.csv")).
+					WithPersistentDB(filepath.Join(t.TempDir(), "missing", "cache.db"))
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+	})
+
+	t.Run("background loads are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		validatedBuilder, err := NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			WithPersistentDB(filepath.Join(t.TempDir(), "cache.db")).
+			Build(context.Background())
+		require.NoError(t, err)
+		_, err = validatedBuilder.StartLoad(context.Background())
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
@@ -0,0 +1,166 @@
+package filesql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// DefaultMaxIdleConns is the default number of idle connections kept by a database,
+// the same as database/sql's default
+const DefaultMaxIdleConns = 2
+
+// WithMaxOpenConns limits the number of connections the database opens at once.
+//
+// A database is a single in-memory SQLite database that every connection of the
+// returned *sql.DB shares. Each connection is a separate SQLite connection, so
+// queries from several goroutines run in parallel. A statement that writes a table
+// waits until other connections have finished reading it, and reads of a table wait
+// until the transaction that writes it ends, so no connection sees uncommitted rows.
+// A goroutine that writes a table while it still reads the rows of a query on that
+// table from another connection therefore waits forever; run both statements in one
+// transaction or on one db.Conn instead. A limit of 0 means no limit (default). With
+// a limit of 1, statements from different goroutines run one after another; then a
+// statement run while rows of another statement are open waits forever, as with any
+// *sql.DB limited to one connection.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("data.csv").
+//		WithMaxOpenConns(8) // At most 8 queries run at once
+//
+// The limit is applied with sql.DB.SetMaxOpenConns and can also be changed on the
+// returned *sql.DB. Build fails with an error with ErrCodeInvalidConfig if n is negative.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithMaxOpenConns(n int) *DBBuilder {
+	b.maxOpenConns = n
+	return b
+}
+
+// WithMaxIdleConns sets the number of idle connections the database keeps open for
+// reuse; the default is DefaultMaxIdleConns.
+//
+// Opening a connection to the in-memory database is cheap, so the default suits most
+// applications. Servers that run many short queries in parallel can keep as many
+// idle connections as WithMaxOpenConns allows. Closing idle connections never loses
+// data: the database lives until the *sql.DB is closed.
+//
+// The limit is applied with sql.DB.SetMaxIdleConns and can also be changed on the
+// returned *sql.DB. Build fails with an error with ErrCodeInvalidConfig if n is negative.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithMaxIdleConns(n int) *DBBuilder {
+	b.maxIdleConns = n
+	return b
+}
+
+// validatePoolSize checks the connection limits
+func validatePoolSize(maxOpenConns, maxIdleConns int) error {
+	if maxOpenConns < 0 {
+		return newCodedError(ErrCodeInvalidConfig, "maximum number of open connections must not be negative: %d", maxOpenConns)
+	}
+	if maxIdleConns < 0 {
+		return newCodedError(ErrCodeInvalidConfig, "maximum number of idle connections must not be negative: %d", maxIdleConns)
+	}
+	return nil
+}
+
+// configurePool applies the connection limits to db
+func (b *DBBuilder) configurePool(db *sql.DB) {
+	db.SetMaxOpenConns(b.maxOpenConns)
+	db.SetMaxIdleConns(b.maxIdleConns)
+}
+
+// memoryConnector opens connections to a named, shared-cache in-memory database.
+//
+// Every connection sees the same database. The anchor connection is opened first and
+// closed last, because SQLite drops an in-memory database when its last connection
+// closes. In shared-cache mode, SQLite locks the tables a connection reads or writes
+// until its transaction ends; a statement that needs a table locked by another
+// connection waits for the lock with sqlite3_unlock_notify, which the driver does on
+// SQLITE_LOCKED_SHAREDCACHE. Connections never read uncommitted data, so queries,
+// rollbacks, and auto-save only see committed rows.
+// newPersistentConnector opens a database file with the same connector.
+type memoryConnector struct {
+	// dsn is the URI of the database
+	dsn string
+	// anchor keeps the database alive until Close
+	anchor driver.Conn
+	// references are attached to every connection returned by Connect
+	references []attachedReference
+	// driver opens the connections
+	driver driver.Driver
+	// lazy loads tables on demand; nil without EnableLazyLoading
+	lazy *lazyTables
+	// watcher reloads tables whose files change; nil without EnableWatch
+	watcher *tableWatcher
+}
+
+// newMemoryConnector creates a new, empty in-memory database whose connections have
+// the reference data attached
+func newMemoryConnector(references ...attachedReference) (*memoryConnector, error) {
+	dsn := fmt.Sprintf("file:filesql-%s?mode=memory&cache=shared", rand.Text())
+	sqliteDriver, err := registeredSQLiteDriver()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create in-memory database: %w", err)
+	}
+	anchor, err := sqliteDriver.Open(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create in-memory database: %w", err)
+	}
+	return &memoryConnector{dsn: dsn, anchor: anchor, references: references, driver: sqliteDriver}, nil
+}
+
+// registeredSQLiteDriver returns the driver registered as "sqlite". Unlike a new
+// sqlite.Driver, it adds the functions registered with sqlite.RegisterFunction, such
+// as the approximate aggregates, to its connections.
+func registeredSQLiteDriver() (driver.Driver, error) {
+	db, err := sql.Open("sqlite", "") // Does not connect
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return db.Driver(), nil
+}
+
+// Connect implements driver.Connector. With lazy loading, the connection loads the
+// tables its statements reference.
+func (c *memoryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connect(ctx)
+	if err != nil || c.lazy == nil {
+		return conn, err
+	}
+	return &lazyConnection{conn: conn, tables: c.lazy}, nil
+}
+
+// connect opens a connection to the database
+func (c *memoryConnector) connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachReferences(ctx, conn, c.references); err != nil {
+		_ = conn.Close() // Ignore close error during error handling
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Driver implements driver.Connector
+func (c *memoryConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close implements io.Closer, which sql.DB.Close calls after closing its connections.
+// Closing the anchor connection drops the database.
+func (c *memoryConnector) Close() error {
+	_ = c.watcher.stop() // Only notification errors; the database is closed either way
+	if c.lazy != nil {
+		_ = c.lazy.close() // The connections only load tables; closing the anchor drops the database
+	}
+	return c.anchor.Close()
+}
@@ -0,0 +1,215 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createCountingFileSystem is a memoryFileSystem that counts how often each file is created
+type createCountingFileSystem struct {
+	*memoryFileSystem
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *createCountingFileSystem) Create(name string) (io.WriteCloser, error) {
+	c.mu.Lock()
+	c.counts[name]++
+	c.mu.Unlock()
+	return c.memoryFileSystem.Create(name)
+}
+
+func (c *createCountingFileSystem) creates(name string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[name]
+}
+
+func TestDBBuilder_ConnectionPool(t *testing.T) {
+	t.Parallel()
+
+	users := func() *strings.Reader {
+		var sb strings.Builder
+		sb.WriteString("id,name\n")
+		for i := range 100 {
+			fmt.Fprintf(&sb, "%d,user%d\n", i, i)
+		}
+		return strings.NewReader(sb.String())
+	}
+
+	t.Run("parallel queries see the same database", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(users(), "users", FileTypeCSV).WithMaxOpenConns(8))
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 32)
+		for i := range 32 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if i%4 == 0 {
+					_, err := db.ExecContext(context.Background(), "INSERT INTO users VALUES (?, 'new')", 1000+i)
+					errs <- err
+					return
+				}
+				var count int
+				errs <- db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM users WHERE id < 100").Scan(&count)
+				if count != 100 {
+					errs <- fmt.Errorf("expected 100 users, got %d", count)
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM users").Scan(&count))
+		assert.Equal(t, 108, count)
+	})
+
+	t.Run("statements can run in a transaction while its rows are being read", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(users(), "users", FileTypeCSV))
+		ctx := context.Background()
+
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		rows, err := tx.QueryContext(ctx, "SELECT id FROM users WHERE id < 3 ORDER BY id")
+		require.NoError(t, err)
+		var ids []int
+		for rows.Next() {
+			var id int
+			require.NoError(t, rows.Scan(&id))
+			ids = append(ids, id)
+			_, err := tx.ExecContext(ctx, "UPDATE users SET name = 'seen' WHERE id = ?", id)
+			require.NoError(t, err)
+		}
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+		require.NoError(t, tx.Commit())
+		assert.Equal(t, []int{0, 1, 2}, ids)
+
+		var seen int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE name = 'seen'").Scan(&seen))
+		assert.Equal(t, 3, seen)
+	})
+
+	t.Run("other connections do not see rows of a transaction that is rolled back", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(users(), "users", FileTypeCSV))
+		ctx := context.Background()
+
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(ctx, "INSERT INTO users VALUES (1000, 'draft')")
+		require.NoError(t, err)
+
+		type result struct {
+			count int
+			err   error
+		}
+		counted := make(chan result, 1)
+		go func() {
+			var r result
+			r.err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&r.count)
+			counted <- r
+		}()
+
+		// The count waits until the transaction ends instead of reading its row
+		select {
+		case r := <-counted:
+			t.Fatalf("count read before the transaction ended: %d, %v", r.count, r.err)
+		case <-time.After(200 * time.Millisecond):
+		}
+		require.NoError(t, tx.Rollback())
+		r := <-counted
+		require.NoError(t, r.err)
+		assert.Equal(t, 100, r.count)
+	})
+
+	t.Run("data survives closing idle connections", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(users(), "users", FileTypeCSV).WithMaxIdleConns(0))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM users").Scan(&count))
+		assert.Equal(t, 100, count)
+		assert.Equal(t, 0, db.Stats().Idle)
+	})
+
+	t.Run("databases are independent", func(t *testing.T) {
+		t.Parallel()
+
+		first := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV))
+		second := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id\n1\n2\n"), "users", FileTypeCSV))
+
+		var count int
+		require.NoError(t, first.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM users").Scan(&count))
+		assert.Equal(t, 1, count)
+		require.NoError(t, second.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM users").Scan(&count))
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("limits are applied to the database", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(users(), "users", FileTypeCSV).WithMaxOpenConns(3))
+		assert.Equal(t, 3, db.Stats().MaxOpenConnections)
+	})
+
+	t.Run("auto-save on close runs once for all connections", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := &createCountingFileSystem{memoryFileSystem: newMemoryFileSystem(), counts: make(map[string]int)}
+		validatedBuilder, err := NewBuilder().
+			AddReader(users(), "users", FileTypeCSV).
+			EnableAutoSave("backup").
+			WithFileSystem(fsys).
+			WithMaxIdleConns(4).
+			Build(context.Background())
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(context.Background())
+		require.NoError(t, err)
+
+		// Hold several connections at once so the pool keeps them open
+		var conns []*sql.Conn
+		for range 4 {
+			conn, err := db.Conn(context.Background())
+			require.NoError(t, err)
+			conns = append(conns, conn)
+		}
+		for _, conn := range conns {
+			require.NoError(t, conn.Close())
+		}
+
+		require.NoError(t, db.Close())
+		assert.Equal(t, 1, fsys.creates(filepath.Join("backup", "users.csv")))
+	})
+
+	t.Run("negative limits are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		for _, builder := range []*DBBuilder{NewBuilder().WithMaxOpenConns(-1), NewBuilder().WithMaxIdleConns(-1)} {
+			_, err := builder.AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).Build(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package filesql
+
+import "time"
+
+// Progress is a snapshot of a load, reported to the handler set with WithProgressHandler.
+type Progress struct {
+	// CurrentInput is the file path (or reader table name) being loaded; empty once
+	// every input is loaded
+	CurrentInput string
+	// CompletedInputs is the number of inputs that have been fully loaded
+	CompletedInputs int
+	// TotalInputs is the number of file paths and reader inputs to load
+	TotalInputs int
+	// BytesRead is the number of (decompressed) bytes read from inputs so far
+	BytesRead int64
+	// TotalBytes is the total size of the inputs, or zero if it is unknown because an
+	// input is a reader or a compressed file
+	TotalBytes int64
+	// RowsInserted is the number of rows inserted so far across all tables
+	RowsInserted int64
+	// Elapsed is the time since loading started
+	Elapsed time.Duration
+	// ETA is the estimated time until loading finishes, extrapolated from the bytes
+	// read so far; zero if TotalBytes is unknown or nothing has been read yet
+	ETA time.Duration
+}
+
+// WithProgressHandler sets a function that is called with the progress of loading.
+//
+// Loading multi-GB files can take minutes. With a progress handler, CLI tools can
+// show a progress bar with the current file, bytes read, rows inserted, and the
+// estimated time remaining instead of an unresponsive pause.
+//
+// The handler is called by Open and StartLoad when an input starts loading, after
+// every chunk of rows (see SetDefaultChunkSize), and when an input is loaded; the
+// last call reports every input as completed. It is called from the loading
+// goroutine, so it should return quickly. Files deferred with EnableLazyLoading are
+// not reported.
+//
+// Example:
+//
+//	builder.WithProgressHandler(func(p filesql.Progress) {
+//		fmt.Fprintf(os.Stderr, "\r%s: %d/%d bytes, %d rows, %s left",
+//			p.CurrentInput, p.BytesRead, p.TotalBytes, p.RowsInserted, p.ETA.Round(time.Second))
+//	})
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithProgressHandler(handler func(Progress)) *DBBuilder {
+	b.progressHandler = handler
+	return b
+}
+
+// progressReporter is a loadObserver that reports the progress of a load to a handler.
+// It forwards every notification to next, so it can be combined with other observers.
+type progressReporter struct {
+	next      loadObserver
+	clock     Clock
+	handler   func(Progress)
+	startedAt time.Time
+	progress  Progress
+}
+
+// newProgressReporter creates a reporter for a load of the given inputs that forwards
+// notifications to next (may be nil). totalBytes is zero if it is unknown.
+func newProgressReporter(next loadObserver, clock Clock, handler func(Progress), totalInputs int, totalBytes int64) *progressReporter {
+	if clock == nil {
+		clock = NewSystemClock()
+	}
+	return &progressReporter{
+		next:      next,
+		clock:     clock,
+		handler:   handler,
+		startedAt: clock.Now(),
+		progress:  Progress{TotalInputs: totalInputs, TotalBytes: totalBytes},
+	}
+}
+
+// report calls the handler with the current progress
+func (r *progressReporter) report() {
+	p := r.progress
+	p.Elapsed = r.clock.Now().Sub(r.startedAt)
+	if p.TotalBytes > 0 && p.BytesRead > 0 && p.BytesRead < p.TotalBytes {
+		p.ETA = time.Duration(float64(p.Elapsed) * float64(p.TotalBytes-p.BytesRead) / float64(p.BytesRead))
+	}
+	r.handler(p)
+}
+
+// inputStarted implements loadObserver
+func (r *progressReporter) inputStarted(input string) {
+	r.progress.CurrentInput = input
+	r.report()
+	if r.next != nil {
+		r.next.inputStarted(input)
+	}
+}
+
+// bytesRead implements loadObserver. Bytes are reported with the next chunk of rows.
+func (r *progressReporter) bytesRead(n int) {
+	r.progress.BytesRead += int64(n)
+	if r.next != nil {
+		r.next.bytesRead(n)
+	}
+}
+
+// rowsInserted implements loadObserver
+func (r *progressReporter) rowsInserted(table string, rows int) {
+	r.progress.RowsInserted += int64(rows)
+	r.report()
+	if r.next != nil {
+		r.next.rowsInserted(table, rows)
+	}
+}
+
+// tableLoaded implements loadObserver
+func (r *progressReporter) tableLoaded(table string) {
+	if r.next != nil {
+		r.next.tableLoaded(table)
+	}
+}
+
+// inputFinished implements loadObserver
+func (r *progressReporter) inputFinished(input string) {
+	r.progress.CompletedInputs++
+	if r.progress.CompletedInputs == r.progress.TotalInputs {
+		r.progress.CurrentInput = ""
+	}
+	r.report()
+	if r.next != nil {
+		r.next.inputFinished(input)
+	}
+}
+
+// inputSizes returns the total size of the file paths and readers to load, or zero
+// if the size of an input is unknown
+func (sp *streamProcessor) inputSizes(paths []string, readers []readerInput) int64 {
+	if len(readers) > 0 {
+		return 0
+	}
+	var total int64
+	for _, path := range paths {
+		if newFile(path).isCompressed() {
+			return 0
+		}
+		file, err := sp.fileSystem().Open(path)
+		if err != nil {
+			return 0
+		}
+		info, err := file.Stat()
+		_ = file.Close() // Ignore close error; the file was only inspected
+		if err != nil {
+			return 0
+		}
+		total += info.Size()
+	}
+	return total
+}
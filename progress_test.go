@@ -0,0 +1,92 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProgressHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("progress of every input is reported", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		orders := filepath.Join(dir, "orders.csv")
+		users := filepath.Join(dir, "users.csv")
+		require.NoError(t, os.WriteFile(orders, []byte("id,total\n1,10\n2,20\n3,30\n4,40\n"), 0600))
+		require.NoError(t, os.WriteFile(users, []byte("id,name\n1,alice\n"), 0600))
+
+		// Every report takes one second, so the elapsed time and ETA are deterministic
+		clock := newFakeClock()
+		var reports []Progress
+		openTestDB(t, NewBuilder().
+			AddPaths(orders, users).
+			SetDefaultChunkSize(2).
+			WithClock(clock).
+			WithProgressHandler(func(p Progress) {
+				reports = append(reports, p)
+				clock.advance(time.Second)
+			}))
+
+		require.NotEmpty(t, reports)
+		first := reports[0]
+		assert.Equal(t, orders, first.CurrentInput)
+		assert.Equal(t, 2, first.TotalInputs)
+		assert.Equal(t, int64(45), first.TotalBytes)
+		assert.Zero(t, first.ETA, "nothing has been read yet")
+
+		var estimated bool
+		for i, p := range reports {
+			assert.Equal(t, time.Duration(i)*time.Second, p.Elapsed)
+			if p.ETA > 0 {
+				estimated = true
+				expected := time.Duration(float64(p.Elapsed) * float64(p.TotalBytes-p.BytesRead) / float64(p.BytesRead))
+				assert.Equal(t, expected, p.ETA)
+			}
+			if i > 0 {
+				assert.GreaterOrEqual(t, p.RowsInserted, reports[i-1].RowsInserted)
+			}
+		}
+		assert.True(t, estimated)
+
+		last := reports[len(reports)-1]
+		assert.Equal(t, Progress{
+			CompletedInputs: 2,
+			TotalInputs:     2,
+			BytesRead:       45,
+			TotalBytes:      45,
+			RowsInserted:    5,
+			Elapsed:         time.Duration(len(reports)-1) * time.Second,
+		}, last)
+	})
+
+	t.Run("the total size of readers is unknown", func(t *testing.T) {
+		t.Parallel()
+
+		var last Progress
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n2\n"), "numbers", FileTypeCSV).
+			WithProgressHandler(func(p Progress) { last = p }).
+			Build(context.Background())
+		require.NoError(t, err)
+		job, err := validatedBuilder.StartLoad(context.Background())
+		require.NoError(t, err)
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+
+		assert.Equal(t, 1, last.CompletedInputs)
+		assert.Equal(t, int64(2), last.RowsInserted)
+		assert.Equal(t, int64(7), last.BytesRead)
+		assert.Zero(t, last.TotalBytes)
+		assert.Zero(t, last.ETA)
+	})
+}
@@ -0,0 +1,233 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// sqliteTimeFormat is the format the SQLite driver writes time.Time values in
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// QueryAs runs query with args and returns its rows as values of T, so callers do not
+// write a Scan loop for every query.
+//
+// When T is a struct, or a pointer to a struct, each column is stored in the field
+// that the column names. A field is named by its `db:"name"` tag, or else by its name,
+// ignoring case and underscores, so the column user_id fills the field UserID. Fields
+// of embedded structs are matched like fields of T, fields tagged `db:"-"` are ignored,
+// and fields without a column keep their zero value. Any other T, such as string or
+// int64, receives the single column of the query.
+//
+// Values are converted the way database/sql converts them, which covers the types
+// inferred for file columns: INTEGER and REAL columns fill numeric, string, and bool
+// fields, and TEXT columns fill numeric fields when their text is a number. TEXT
+// columns holding dates in one of the formats type inference recognizes fill
+// time.Time fields. NULL fills pointer fields with nil and sql.Scanner fields such as
+// sql.NullString; other fields cannot hold NULL, so QueryAs fails on it.
+//
+// Example:
+//
+//	type User struct {
+//		ID        int64     `db:"id"`
+//		Name      string    `db:"name"`
+//		Email     *string   `db:"email"` // nil when NULL
+//		CreatedAt time.Time `db:"created_at"`
+//	}
+//
+//	users, err := filesql.QueryAs[User](ctx, db, "SELECT * FROM users WHERE id > ?", 100)
+//	if err != nil {
+//		return err
+//	}
+//
+//	names, err := filesql.QueryAs[string](ctx, db, "SELECT name FROM users")
+//
+// QueryAs returns an error with ErrCodeInvalidConfig if a column has no field in T,
+// or if T is not a struct and the query does not have exactly one column.
+func QueryAs[T any](ctx context.Context, db *sql.DB, query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	target := reflect.TypeFor[T]()
+	fields, err := columnFields(target, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]T, 0)
+	dest := make([]any, len(columns))
+	for rows.Next() {
+		var result T
+		value := reflect.ValueOf(&result).Elem()
+		if fields != nil && target.Kind() == reflect.Pointer {
+			value.Set(reflect.New(target.Elem()))
+			value = value.Elem()
+		}
+		for i := range columns {
+			field := value
+			if fields != nil {
+				field = value.FieldByIndex(fields[i])
+			}
+			dest[i] = scanDestination(field)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row %d: %w", len(results)+1, err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	return results, nil
+}
+
+// columnFields returns the index of the field of target each column is stored in, or
+// nil if target is not a struct and receives the single column
+func columnFields(target reflect.Type, columns []string) ([][]int, error) {
+	structType := target
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct || structType == reflect.TypeFor[time.Time]() || reflect.PointerTo(structType).Implements(reflect.TypeFor[sql.Scanner]()) {
+		if len(columns) != 1 {
+			return nil, newCodedError(ErrCodeInvalidConfig,
+				"cannot scan %d columns into %s: a type other than a struct receives a single column", len(columns), target)
+		}
+		return nil, nil
+	}
+
+	tagged := make(map[string][]int)
+	named := make(map[string][]int)
+	collectStructFields(structType, nil, tagged, named)
+	fields := make([][]int, len(columns))
+	for i, column := range columns {
+		index, ok := tagged[strings.ToLower(column)]
+		if !ok {
+			index, ok = named[normalizeFieldName(column)]
+		}
+		if !ok {
+			return nil, newCodedError(ErrCodeInvalidConfig, "column %s has no field in %s", column, target)
+		}
+		fields[i] = index
+	}
+	return fields, nil
+}
+
+// collectStructFields records the exported fields of structType by their tag and by
+// their normalized name. Fields of structType take precedence over fields of its
+// embedded structs.
+func collectStructFields(structType reflect.Type, parent []int, tagged, named map[string][]int) {
+	var embedded []reflect.StructField
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		tag, hasTag := field.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		// The exported fields of an embedded struct are promoted even if its type is not exported
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeFor[time.Time]() {
+			embedded = append(embedded, field)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		index := append(append([]int{}, parent...), i)
+		if hasTag && tag != "" {
+			if _, ok := tagged[strings.ToLower(tag)]; !ok {
+				tagged[strings.ToLower(tag)] = index
+			}
+			continue
+		}
+		if _, ok := named[normalizeFieldName(field.Name)]; !ok {
+			named[normalizeFieldName(field.Name)] = index
+		}
+	}
+	for _, field := range embedded {
+		collectStructFields(field.Type, append(append([]int{}, parent...), field.Index...), tagged, named)
+	}
+}
+
+// normalizeFieldName returns name in lower case without underscores, so the column
+// user_id and the field UserID have the same name
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// scanDestination returns the value rows.Scan stores a column in to fill field
+func scanDestination(field reflect.Value) any {
+	switch {
+	case field.Type() == reflect.TypeFor[time.Time]():
+		return &timeScanner{field: field}
+	case field.Kind() == reflect.Pointer && field.Type().Elem() == reflect.TypeFor[time.Time]():
+		return &timeScanner{field: field}
+	default:
+		return field.Addr().Interface()
+	}
+}
+
+// timeScanner fills a time.Time or *time.Time field from a time or the text of a date
+type timeScanner struct {
+	field reflect.Value
+}
+
+// Scan implements sql.Scanner
+func (s *timeScanner) Scan(src any) error {
+	if src == nil {
+		if s.field.Kind() == reflect.Pointer {
+			s.field.SetZero()
+			return nil
+		}
+		return errors.New("converting NULL to time.Time is unsupported")
+	}
+
+	var t time.Time
+	switch v := src.(type) {
+	case time.Time:
+		t = v
+	case string:
+		parsed, err := parseTimeText(v)
+		if err != nil {
+			return err
+		}
+		t = parsed
+	case []byte:
+		parsed, err := parseTimeText(string(v))
+		if err != nil {
+			return err
+		}
+		t = parsed
+	default:
+		return fmt.Errorf("converting %T to time.Time is unsupported", src)
+	}
+
+	if s.field.Kind() == reflect.Pointer {
+		s.field.Set(reflect.ValueOf(&t))
+	} else {
+		s.field.Set(reflect.ValueOf(t))
+	}
+	return nil
+}
+
+// parseTimeText parses a date in a format of type inference or of the SQLite driver
+func parseTimeText(text string) (time.Time, error) {
+	if t, ok := parseDatetime(text); ok {
+		return t, nil
+	}
+	if t, err := time.Parse(sqliteTimeFormat, strings.TrimSpace(text)); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("converting %q to time.Time: unsupported date format", text)
+}
@@ -0,0 +1,190 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryAs(t *testing.T) {
+	t.Parallel()
+
+	// open loads users with inferred INTEGER, TEXT, REAL, and datetime columns
+	open := func(t *testing.T) *sql.DB {
+		t.Helper()
+
+		data := "id,name,email,score,joined_at,zip\n" +
+			"1,alice,alice@example.com,9.5,2024-01-02,12345\n" +
+			"2,bob,,7,2024-02-03 04:05:06,98765\n"
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(data), "users", FileTypeCSV))
+
+		_, err := db.ExecContext(context.Background(), "UPDATE users SET email = NULL WHERE email = ''")
+		require.NoError(t, err)
+		return db
+	}
+
+	type user struct {
+		ID       int64     `db:"id"`
+		Name     string    `db:"name"`
+		Email    *string   `db:"email"`
+		Score    float64   `db:"score"`
+		JoinedAt time.Time `db:"joined_at"`
+	}
+
+	t.Run("rows are scanned into structs by tag", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t)
+		users, err := QueryAs[user](context.Background(), db, "SELECT id, name, email, score, joined_at FROM users WHERE id >= ? ORDER BY id", 1)
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+
+		email := "alice@example.com"
+		assert.Equal(t, user{
+			ID: 1, Name: "alice", Email: &email, Score: 9.5,
+			JoinedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		}, users[0])
+		assert.Nil(t, users[1].Email, "NULL becomes a nil pointer")
+		assert.Equal(t, time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC), users[1].JoinedAt)
+	})
+
+	t.Run("untagged fields match columns ignoring case and underscores", func(t *testing.T) {
+		t.Parallel()
+
+		type base struct {
+			ID int
+		}
+		type row struct {
+			base
+			JoinedAt *time.Time
+			Ignored  string `db:"-"`
+			internal string
+		}
+		db := open(t)
+		rows, err := QueryAs[*row](context.Background(), db, "SELECT id, joined_at FROM users ORDER BY id")
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, 2, rows[1].ID)
+		require.NotNil(t, rows[1].JoinedAt)
+		assert.Equal(t, 2024, rows[1].JoinedAt.Year())
+		assert.Empty(t, rows[1].Ignored)
+		assert.Empty(t, rows[1].internal)
+	})
+
+	t.Run("values are converted between inferred column types", func(t *testing.T) {
+		t.Parallel()
+
+		type row struct {
+			ID    string         `db:"id"`
+			Score int            `db:"score"`
+			Zip   uint32         `db:"zip"`
+			Email sql.NullString `db:"email"`
+			Flag  bool           `db:"flag"`
+		}
+		db := open(t)
+		rows, err := QueryAs[row](context.Background(), db, "SELECT id, score, zip, email, id = 2 AS flag FROM users WHERE id = 2")
+		require.NoError(t, err)
+		assert.Equal(t, []row{{ID: "2", Score: 7, Zip: 98765, Email: sql.NullString{}, Flag: true}}, rows)
+	})
+
+	t.Run("single columns are scanned into other types", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t)
+		names, err := QueryAs[string](context.Background(), db, "SELECT name FROM users ORDER BY id")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice", "bob"}, names)
+
+		emails, err := QueryAs[*string](context.Background(), db, "SELECT email FROM users ORDER BY id")
+		require.NoError(t, err)
+		require.Len(t, emails, 2)
+		assert.Nil(t, emails[1])
+
+		dates, err := QueryAs[time.Time](context.Background(), db, "SELECT joined_at FROM users ORDER BY id")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), dates[0])
+	})
+
+	t.Run("no rows return an empty slice", func(t *testing.T) {
+		t.Parallel()
+
+		db := open(t)
+		users, err := QueryAs[user](context.Background(), db, "SELECT id, name FROM users WHERE id > 10")
+		require.NoError(t, err)
+		assert.NotNil(t, users)
+		assert.Empty(t, users)
+	})
+
+	type idRow struct {
+		ID int64 `db:"id"`
+	}
+	type emailRow struct {
+		Email string `db:"email"`
+	}
+	type nameRow struct {
+		Name int `db:"name"`
+	}
+	errorTests := []struct {
+		name     string
+		query    func(db *sql.DB) error
+		wantCode ErrorCode
+		wantMsg  string
+	}{
+		{
+			name: "columns without a struct field are rejected",
+			query: func(db *sql.DB) error {
+				_, err := QueryAs[idRow](context.Background(), db, "SELECT id, name FROM users")
+				return err
+			},
+			wantCode: ErrCodeInvalidConfig,
+		},
+		{
+			name: "several columns into a single value are rejected",
+			query: func(db *sql.DB) error {
+				_, err := QueryAs[string](context.Background(), db, "SELECT id, name FROM users")
+				return err
+			},
+			wantCode: ErrCodeInvalidConfig,
+		},
+		{
+			name: "NULL into a non-pointer field fails",
+			query: func(db *sql.DB) error {
+				_, err := QueryAs[emailRow](context.Background(), db, "SELECT email FROM users ORDER BY id")
+				return err
+			},
+			wantMsg: "row 2",
+		},
+		{
+			name: "text that is not a number fails",
+			query: func(db *sql.DB) error {
+				_, err := QueryAs[nameRow](context.Background(), db, "SELECT name FROM users")
+				return err
+			},
+		},
+		{
+			name: "invalid queries fail",
+			query: func(db *sql.DB) error {
+				_, err := QueryAs[user](context.Background(), db, "SELECT * FROM missing")
+				return err
+			},
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.query(open(t))
+			require.Error(t, err)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, ErrorCodeOf(err))
+			}
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
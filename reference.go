@@ -0,0 +1,315 @@
+package filesql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+
+	"modernc.org/sqlite"
+)
+
+// ReferenceData is a read-only dataset, such as country codes or holidays embedded in
+// a binary, that is parsed once and attached to every database opened with it.
+//
+// Loading files is the slow part of opening a database. CLI tools and servers that
+// open many databases with the same reference tables can create one ReferenceData per
+// process instead of adding the files to every builder: its files are parsed the
+// first time a database is opened with it, and later databases attach the parsed
+// tables without reading the files again. The tables are shared by every database and
+// cannot be modified.
+//
+// Example:
+//
+//	//go:embed reference
+//	var referenceFS embed.FS
+//
+//	// Parsed once, on the first Open
+//	var reference = filesql.NewReferenceData("ref", referenceFS)
+//
+//	func run(ctx context.Context, path string) error {
+//		validatedBuilder, err := filesql.NewBuilder().
+//			AddPath(path).
+//			WithReferenceData(reference).
+//			Build(ctx)
+//		// ...
+//		// SELECT o.*, c.name FROM orders o JOIN countries c ON o.country = c.code
+//	}
+//
+// A ReferenceData is safe for concurrent use.
+type ReferenceData struct {
+	name       string
+	filesystem fs.FS
+
+	mu sync.Mutex
+	// uri opens the parsed tables read-only; empty until loaded
+	uri string
+	// holder keeps the parsed tables in memory until Close
+	holder driver.Conn
+	// tables are the names of the parsed tables
+	tables []string
+	// closed is true once Close has been called
+	closed bool
+}
+
+// NewReferenceData returns reference data that loads the supported files of
+// filesystem, like AddFS does, when it is first used.
+//
+// Databases opened with it attach the tables as the schema name, so they can be
+// queried as name.table, or just as table when no loaded table has the same name.
+// The name must be a valid SQL identifier other than main and temp, and different
+// from the names of the other reference data of a builder.
+func NewReferenceData(name string, filesystem fs.FS) *ReferenceData {
+	return &ReferenceData{name: name, filesystem: filesystem}
+}
+
+// Name returns the schema name the tables are attached as.
+func (r *ReferenceData) Name() string {
+	return r.name
+}
+
+// Load parses the files unless they have already been parsed. Databases load the
+// reference data on Open, so calling Load is only needed to report errors in the files
+// early, for example at startup. A failed load is retried by the next call.
+func (r *ReferenceData) Load(ctx context.Context) error {
+	_, err := r.load(ctx)
+	return err
+}
+
+// Tables returns the names of the tables, sorted by name, loading the files first if
+// needed.
+func (r *ReferenceData) Tables(ctx context.Context) ([]string, error) {
+	if _, err := r.load(ctx); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.tables...), nil
+}
+
+// Close releases the parsed tables. Close the databases opened with the reference
+// data first; databases cannot be opened with it afterwards.
+func (r *ReferenceData) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.holder == nil {
+		return nil
+	}
+	return r.holder.Close()
+}
+
+// load parses the files into a memdb database once and returns its read-only URI
+func (r *ReferenceData) load(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return "", ErrReferenceDataClosed
+	}
+	if r.uri != "" {
+		return r.uri, nil
+	}
+
+	validatedBuilder, err := NewBuilder().AddFS(r.filesystem).Build(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load reference data %s: %w", r.name, err)
+	}
+	db, err := validatedBuilder.Open(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load reference data %s: %w", r.name, err)
+	}
+	defer db.Close()
+
+	// The memdb VFS shares a database between the connections of the process that
+	// open the same name, and lets them open it read-only, unlike shared-cache
+	// in-memory databases
+	name := "/filesql-reference-" + rand.Text()
+	holder, err := (&sqlite.Driver{}).Open("file:" + name + "?vfs=memdb")
+	if err != nil {
+		return "", fmt.Errorf("failed to create reference data %s: %w", r.name, err)
+	}
+
+	tables, err := copyReferenceTables(ctx, db, "file:"+name+"?vfs=memdb")
+	if err != nil {
+		_ = holder.Close() // Ignore close error during error handling
+		return "", fmt.Errorf("failed to create reference data %s: %w", r.name, err)
+	}
+
+	r.holder = holder
+	r.tables = tables
+	r.uri = "file:" + name + "?vfs=memdb&mode=ro"
+	return r.uri, nil
+}
+
+// copyReferenceTables copies the tables of db, without the internal filesql tables,
+// into the empty database at uri and returns their names
+func copyReferenceTables(ctx context.Context, db *sql.DB, uri string) ([]string, error) {
+	tables, err := listUserTables(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := queryStrings(ctx, db,
+		`SELECT name FROM sqlite_master WHERE type='table' AND name LIKE '\_filesql\_%' ESCAPE '\'`)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range metadata {
+		if _, err := db.ExecContext(ctx, "DROP TABLE "+quoteIdentifier(table)); err != nil {
+			return nil, fmt.Errorf("failed to drop table %s: %w", table, err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", uri); err != nil {
+		return nil, fmt.Errorf("failed to copy tables: %w", err)
+	}
+	return tables, nil
+}
+
+// listUserTables returns the names of the tables of db, without the internal tables
+func listUserTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	return queryStrings(ctx, db,
+		`SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' AND name NOT LIKE '\_filesql\_%' ESCAPE '\' ORDER BY name`)
+}
+
+// queryStrings returns the first column of every row of query
+func queryStrings(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// WithReferenceData attaches read-only reference tables to the database (see
+// ReferenceData). It can be called several times with reference data of different
+// names.
+//
+// Open fails with an error with ErrCodeDuplicateTable if a loaded table has the same
+// name as a reference table, or two reference data have a table with the same name,
+// because the unqualified name would silently refer to one of them. Build fails with
+// an error with ErrCodeInvalidConfig if a name is not a valid schema name.
+//
+// Reference tables are not written by DumpDatabase or auto-save.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithReferenceData(reference *ReferenceData) *DBBuilder {
+	b.references = append(b.references, reference)
+	return b
+}
+
+// validateReferenceData checks the schema names of the reference data
+func validateReferenceData(references []*ReferenceData) error {
+	seen := make(map[string]bool, len(references))
+	for _, reference := range references {
+		if reference == nil {
+			return newCodedError(ErrCodeInvalidConfig, "reference data cannot be nil")
+		}
+		name := strings.ToLower(reference.name)
+		if !isIdentifier(reference.name) || name == "main" || name == "temp" {
+			return newCodedError(ErrCodeInvalidConfig, "invalid reference data name: %q", reference.name)
+		}
+		if seen[name] {
+			return newCodedError(ErrCodeInvalidConfig, "duplicate reference data name: %s", reference.name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// isIdentifier reports whether name is a plain SQL identifier
+func isIdentifier(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// attachedReference is reference data loaded for a database
+type attachedReference struct {
+	name string
+	uri  string
+}
+
+// loadReferences loads the reference data and returns what to attach to each connection
+func loadReferences(ctx context.Context, references []*ReferenceData) ([]attachedReference, error) {
+	attached := make([]attachedReference, 0, len(references))
+	for _, reference := range references {
+		uri, err := reference.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		attached = append(attached, attachedReference{name: reference.name, uri: uri})
+	}
+	return attached, nil
+}
+
+// checkReferenceTables fails if a table name is used by more than one of the loaded
+// tables and the reference data
+func checkReferenceTables(ctx context.Context, db *sql.DB, references []*ReferenceData) error {
+	if len(references) == 0 {
+		return nil
+	}
+	tables, err := listUserTables(ctx, db)
+	if err != nil {
+		return err
+	}
+	owners := make(map[string]string, len(tables))
+	for _, table := range tables {
+		owners[strings.ToLower(table)] = "the loaded inputs"
+	}
+	for _, reference := range references {
+		referenceTables, err := reference.Tables(ctx)
+		if err != nil {
+			return err
+		}
+		for _, table := range referenceTables {
+			if owner, ok := owners[strings.ToLower(table)]; ok {
+				return newCodedError(ErrCodeDuplicateTable,
+					"table '%s' exists in both %s and reference data %s", table, owner, reference.name)
+			}
+			owners[strings.ToLower(table)] = "reference data " + reference.name
+		}
+	}
+	return nil
+}
+
+// attachReferences attaches the reference data to a new connection
+func attachReferences(ctx context.Context, conn driver.Conn, references []attachedReference) error {
+	if len(references) == 0 {
+		return nil
+	}
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		return errors.New("connection does not support attaching reference data")
+	}
+	for _, reference := range references {
+		query := "ATTACH DATABASE ? AS " + quoteIdentifier(reference.name)
+		if _, err := execer.ExecContext(ctx, query, []driver.NamedValue{{Ordinal: 1, Value: reference.uri}}); err != nil {
+			return fmt.Errorf("failed to attach reference data %s: %w", reference.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package filesql
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFS counts the files opened from a filesystem
+type countingFS struct {
+	fs.FS
+	opened atomic.Int64
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	file, err := c.FS.Open(name)
+	if err == nil && !strings.HasSuffix(name, ".") {
+		c.opened.Add(1)
+	}
+	return file, err
+}
+
+func TestReferenceData(t *testing.T) {
+	t.Parallel()
+
+	newReference := func(t *testing.T) (*ReferenceData, *countingFS) {
+		t.Helper()
+		filesystem := &countingFS{FS: fstest.MapFS{
+			"countries.csv": {Data: []byte("code,name\nJP,Japan\nFR,France\n")},
+		}}
+		reference := NewReferenceData("ref", filesystem)
+		t.Cleanup(func() { _ = reference.Close() })
+		return reference, filesystem
+	}
+	orders := func() *DBBuilder {
+		return NewBuilder().AddReader(strings.NewReader("id,country\n1,JP\n2,FR\n"), "orders", FileTypeCSV)
+	}
+
+	t.Run("files are parsed once and shared by every database", func(t *testing.T) {
+		t.Parallel()
+
+		reference, filesystem := newReference(t)
+		for range 3 {
+			db := openTestDB(t, orders().WithReferenceData(reference))
+
+			var name string
+			require.NoError(t, db.QueryRowContext(context.Background(),
+				"SELECT c.name FROM orders o JOIN countries c ON o.country = c.code WHERE o.id = 2").Scan(&name))
+			assert.Equal(t, "France", name)
+		}
+		assert.Equal(t, int64(1), filesystem.opened.Load())
+
+		tables, err := reference.Tables(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"countries"}, tables)
+	})
+
+	t.Run("tables can be qualified with the reference name on every connection", func(t *testing.T) {
+		t.Parallel()
+
+		reference, _ := newReference(t)
+		db := openTestDB(t, orders().WithReferenceData(reference))
+
+		// Reading countries while the rows of orders are open uses a second connection
+		rows, err := db.QueryContext(context.Background(), "SELECT country FROM orders ORDER BY id")
+		require.NoError(t, err)
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var code, name string
+			require.NoError(t, rows.Scan(&code))
+			require.NoError(t, db.QueryRowContext(context.Background(),
+				"SELECT name FROM ref.countries WHERE code = ?", code).Scan(&name))
+			names = append(names, name)
+		}
+		require.NoError(t, rows.Err())
+		assert.Equal(t, []string{"Japan", "France"}, names)
+	})
+
+	t.Run("reference tables are read-only and not dumped", func(t *testing.T) {
+		t.Parallel()
+
+		reference, _ := newReference(t)
+		db := openTestDB(t, orders().WithReferenceData(reference))
+
+		_, err := db.ExecContext(context.Background(), "INSERT INTO countries VALUES ('DE', 'Germany')")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "readonly")
+
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir))
+		entries, err := os.ReadDir(outputDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "orders.csv", entries[0].Name())
+	})
+
+	withReferenceName := func(name string) func(*testing.T) *DBBuilder {
+		return func(*testing.T) *DBBuilder {
+			return orders().WithReferenceData(NewReferenceData(name, fstest.MapFS{}))
+		}
+	}
+	runLoadTests(t, []loadTest{
+		{
+			name: "a loaded table with the name of a reference table fails",
+			builder: func(t *testing.T) *DBBuilder {
+				reference, _ := newReference(t)
+				return NewBuilder().
+					AddReader(strings.NewReader("code\nJP\n"), "countries", FileTypeCSV).
+					WithReferenceData(reference)
+			},
+			wantErr: ErrCodeDuplicateTable,
+		},
+		{
+			name:    "an empty name is rejected",
+			builder: withReferenceName(""),
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name:    "the name main is rejected",
+			builder: withReferenceName("main"),
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name:    "the name TEMP is rejected",
+			builder: withReferenceName("TEMP"),
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name:    "the name 1ref is rejected",
+			builder: withReferenceName("1ref"),
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name:    "the name my-ref is rejected",
+			builder: withReferenceName("my-ref"),
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name: "names cannot be used twice",
+			builder: func(t *testing.T) *DBBuilder {
+				reference, _ := newReference(t)
+				return orders().WithReferenceData(reference).WithReferenceData(NewReferenceData("REF", fstest.MapFS{}))
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+	})
+
+	t.Run("closed reference data cannot be used", func(t *testing.T) {
+		t.Parallel()
+
+		reference, _ := newReference(t)
+		require.NoError(t, reference.Load(context.Background()))
+		require.NoError(t, reference.Close())
+
+		_, err := tryOpenTestDB(t, orders().WithReferenceData(reference))
+		require.ErrorIs(t, err, ErrReferenceDataClosed)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+
+	t.Run("load errors are reported and retried", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		reference := NewReferenceData("ref", os.DirFS(dir))
+		defer reference.Close()
+		require.Error(t, reference.Load(context.Background()))
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "holidays.csv"), []byte("date\n2026-01-01\n"), 0600))
+		require.NoError(t, reference.Load(context.Background()))
+		tables, err := reference.Tables(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"holidays"}, tables)
+	})
+}
@@ -0,0 +1,159 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Reload reads the files of table again and replaces the content of the table in db,
+// so long-running services pick up changed files without opening the database again.
+//
+// db must have been opened by this builder. The files are loaded the way Open loaded
+// them, with the same options, into a separate database first; the table is then
+// replaced in one transaction, so a file that fails to load leaves the table as it
+// was. For a table of AddPathsAsTable or TableCollisionMerge, every file of the table
+// is read again, and for the table of a workbook sheet, the workbook is.
+//
+// The rows of the table are replaced by the rows of the files, and its columns by
+// the columns of the files, so changes made through SQL are lost, and indexes and
+// triggers created on the table are dropped. Derived tables and validation rules are
+// not applied again. With WithPersistentDB, the database records the reloaded files,
+// so the next Open reuses it.
+//
+// Example:
+//
+//	validatedBuilder, err := filesql.NewBuilder().AddPath("config/").Build(ctx)
+//	// ...
+//	db, err := validatedBuilder.Open(ctx)
+//	// ...
+//	// config/users.csv was edited
+//	if err := validatedBuilder.Reload(ctx, db, "users"); err != nil {
+//		return err
+//	}
+//
+// Reload returns an error with ErrCodeInvalidConfig if table does not exist or was
+// not loaded from a file, such as a reader or a table of an archive, or if lazy
+// loading is enabled, and an error with ErrCodeLoadFailed if the files fail to load.
+func (b *DBBuilder) Reload(ctx context.Context, db *sql.DB, table string) error {
+	if b.lazyLoading {
+		return newCodedError(ErrCodeInvalidConfig, "Reload cannot be used with EnableLazyLoading")
+	}
+	name, err := existingTableName(ctx, db, table)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return newCodedError(ErrCodeInvalidConfig, "cannot reload table %s: table does not exist", table)
+	}
+	paths := b.tableSourceFiles(name)
+	if len(paths) == 0 {
+		return newCodedError(ErrCodeInvalidConfig, "cannot reload table %s: table was not loaded from a file", name)
+	}
+
+	if err := b.reloadTable(ctx, db, name, paths); err != nil {
+		return withErrorCode(fmt.Errorf("failed to reload table %s: %w", name, err), ErrCodeLoadFailed)
+	}
+	return nil
+}
+
+// tableSourceFiles returns the collected paths table is loaded from
+func (b *DBBuilder) tableSourceFiles(table string) []string {
+	var paths []string
+	for _, path := range b.collectedPaths {
+		baseName := b.streamProcessor.fileTableName(path)
+		if !isWorkbook(newFile(path).getFileType().baseType()) {
+			if strings.EqualFold(baseName, table) {
+				paths = append(paths, path)
+			}
+			continue
+		}
+		// Each Excel or ODS sheet becomes a "<file>_<sheet>" table
+		if prefix := sanitizeTableName(baseName) + "_"; len(table) > len(prefix) && strings.EqualFold(table[:len(prefix)], prefix) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// reloadTable loads paths into a new in-memory database and replaces table of db with
+// the table of the same name loaded from them
+func (b *DBBuilder) reloadTable(ctx context.Context, db *sql.DB, table string, paths []string) error {
+	memory, err := newMemoryConnector()
+	if err != nil {
+		return err
+	}
+	source := sql.OpenDB(memory)
+	defer source.Close()
+
+	sp := *b.streamProcessor
+	sp.observer = nil
+	sp.bestEffort = false
+	sp.stageTables = false
+	if err := sp.streamAllFilesToDatabase(ctx, source, paths); err != nil {
+		return err
+	}
+	sourceTable, err := existingTableName(ctx, source, table)
+	if err != nil {
+		return err
+	}
+	if sourceTable == "" {
+		return fmt.Errorf("table %s was not loaded from %s", table, strings.Join(paths, ", "))
+	}
+
+	// The table is copied under another name, then swapped in, so queries see either
+	// the old or the new rows
+	staging := stagingTablePrefix + table
+	if err := copyTable(ctx, source, sourceTable, db, staging); err != nil {
+		return err
+	}
+	if err := replaceTable(ctx, db, staging, table); err != nil {
+		_, _ = db.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdentifier(staging)) // Ignore drop error during error handling
+		return err
+	}
+
+	if b.persistentDB == "" {
+		return nil
+	}
+	sources, err := statPersistentSources(paths)
+	if err != nil {
+		return err
+	}
+	return recordPersistentSources(ctx, db, sources)
+}
+
+// replaceTable replaces table of db with table staging in one transaction
+func replaceTable(ctx context.Context, db *sql.DB, staging, table string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after Commit is a no-op
+
+	if _, err := tx.ExecContext(ctx, "DROP TABLE "+quoteIdentifier(table)); err != nil { //nolint:gosec // Table name is quoted
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(staging), quoteIdentifier(table))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// existingTableName returns the name of the table of db named table, ignoring case
+// like SQLite does, or an empty string if db has no such table
+func existingTableName(ctx context.Context, db *sql.DB, table string) (string, error) {
+	var name string
+	err := db.QueryRowContext(ctx,
+		`SELECT name FROM sqlite_master WHERE type='table' AND name=? COLLATE NOCASE`, table,
+	).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check table existence: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,133 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_Reload(t *testing.T) {
+	t.Parallel()
+
+	// writeFiles writes files into a new directory and returns it
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+		}
+		return dir
+	}
+	open := func(t *testing.T, builder *DBBuilder) (*DBBuilder, *sql.DB) {
+		t.Helper()
+
+		validatedBuilder, err := builder.Build(context.Background())
+		require.NoError(t, err)
+		return validatedBuilder, openTestDB(t, validatedBuilder)
+	}
+	count := func(t *testing.T, db *sql.DB, table string) int {
+		t.Helper()
+
+		var n int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM "+quoteIdentifier(table)).Scan(&n))
+		return n
+	}
+
+	t.Run("the table gets the rows and columns of the changed file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t, map[string]string{
+			"users.csv":  "id,name\n1,alice\n",
+			"orders.csv": "id\n1\n",
+		})
+		validatedBuilder, db := open(t, NewBuilder().AddPath(dir))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name,role\n1,alice,admin\n2,bob,user\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv"), []byte("id\n1\n2\n"), 0600))
+
+		require.NoError(t, validatedBuilder.Reload(context.Background(), db, "USERS"))
+		assert.Equal(t, 2, count(t, db, "users"))
+		var role string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT role FROM users WHERE id = 2").Scan(&role))
+		assert.Equal(t, "user", role)
+		assert.Equal(t, 1, count(t, db, "orders"), "other tables are not reloaded")
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"users", "orders"}, tables)
+	})
+
+	t.Run("every file of a table is read again", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t, map[string]string{
+			"events_2023.csv": "id\n1\n",
+			"events_2024.csv": "id\n2\n",
+		})
+		validatedBuilder, db := open(t, NewBuilder().AddPathsAsTable("events", filepath.Join(dir, "events_*.csv")))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "events_2024.csv"), []byte("id\n2\n3\n"), 0600))
+
+		require.NoError(t, validatedBuilder.Reload(context.Background(), db, "events"))
+		assert.Equal(t, 3, count(t, db, "events"))
+	})
+
+	t.Run("a file that fails to load leaves the table unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t, map[string]string{"users.csv": "id,name\n1,alice\n"})
+		validatedBuilder, db := open(t, NewBuilder().AddPath(dir))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,id\n1,2\n"), 0600))
+
+		err := validatedBuilder.Reload(context.Background(), db, "users")
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeDuplicateColumn, ErrorCodeOf(err))
+		assert.Equal(t, 1, count(t, db, "users"))
+		var n int
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT COUNT(*) FROM sqlite_master WHERE name LIKE '\\_filesql\\_loading\\_%' ESCAPE '\\'").Scan(&n))
+		assert.Zero(t, n)
+	})
+
+	t.Run("a persistent database records the reloaded file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t, map[string]string{"users.csv": "id,name\n1,alice\n"})
+		builder := func() *DBBuilder {
+			return NewBuilder().AddPath(filepath.Join(dir, "users.csv")).WithPersistentDB(filepath.Join(dir, "cache.db"))
+		}
+		validatedBuilder, db := open(t, builder())
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name\n1,alice\n2,bob\n"), 0600))
+		require.NoError(t, validatedBuilder.Reload(context.Background(), db, "users"))
+		// The change is only kept if the next Open reuses the database
+		_, err := db.ExecContext(context.Background(), "DELETE FROM users WHERE id = 1")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		_, db = open(t, builder())
+		assert.Equal(t, 1, count(t, db, "users"))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		validatedBuilder, db := open(t, NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			AddReader(strings.NewReader("id\n1\n"), "uploads", FileTypeCSV))
+
+		err := validatedBuilder.Reload(context.Background(), db, "missing")
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+
+		err = validatedBuilder.Reload(context.Background(), db, "uploads")
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+		assert.Contains(t, err.Error(), "was not loaded from a file")
+
+		lazyBuilder, lazyDB := open(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")).EnableLazyLoading())
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(lazyBuilder.Reload(context.Background(), lazyDB, "sample")))
+	})
+}
@@ -0,0 +1,135 @@
+package filesql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// remoteInput is a file downloaded while loading, such as an S3 object or a URL
+type remoteInput struct {
+	// uri names the input in errors and load warnings, such as s3://bucket/key.csv
+	uri string
+	// name is the file name that determines the table name and file type
+	name string
+	// err is an error in how the input was added, reported by Build
+	err error
+	// s3 is the object of an S3 input
+	s3 *s3Object
+	// gcs is the object or prefix of a Cloud Storage input
+	gcs *gcsObject
+	// http is the file of a URL input
+	http *httpObject
+	// options are the input options set with AddPathWithOptions
+	options inputOptions
+}
+
+// remoteReaders returns the reader inputs of the remote inputs. Downloads start when
+// the inputs are loaded; Cloud Storage prefixes are listed now. origins holds the
+// index of the remote input each reader input comes from.
+func (b *DBBuilder) remoteReaders(ctx context.Context) (readers []readerInput, origins []int, err error) {
+	remotes, origins, err := b.expandGCSPrefixes(ctx, b.remotes)
+	if err != nil {
+		return nil, nil, err
+	}
+	readers = make([]readerInput, 0, len(remotes))
+	for _, remote := range remotes {
+		if remote.err != nil {
+			return nil, nil, remote.err
+		}
+		if !isSupportedFile(remote.name) {
+			return nil, nil, newCodedError(ErrCodeUnsupportedFormat, "unsupported file type: %s", remote.uri)
+		}
+
+		var open func(ctx context.Context) (io.ReadCloser, error)
+		switch {
+		case remote.s3 != nil:
+			object, config := *remote.s3, b.s3Config
+			open = func(ctx context.Context) (io.ReadCloser, error) {
+				return object.get(ctx, config)
+			}
+		case remote.gcs != nil:
+			object, client := *remote.gcs, b.gcsClient()
+			open = func(ctx context.Context) (io.ReadCloser, error) {
+				return object.get(ctx, client)
+			}
+		case remote.http != nil:
+			object, header := *remote.http, b.httpHeader.Clone()
+			open = func(ctx context.Context) (io.ReadCloser, error) {
+				return object.get(ctx, header)
+			}
+		default:
+			return nil, nil, fmt.Errorf("unknown remote input %s", remote.uri)
+		}
+
+		readers = append(readers, readerInput{
+			reader:    &remoteReader{uri: remote.uri, open: open},
+			tableName: tableFromFilePath(remote.name),
+			fileType:  newFile(remote.name).getFileType(),
+			source:    remote.uri,
+			options:   remote.options,
+		})
+	}
+	return readers, origins, nil
+}
+
+// remoteReader downloads a remote input once start is called
+type remoteReader struct {
+	uri  string
+	open func(ctx context.Context) (io.ReadCloser, error)
+	body io.ReadCloser
+}
+
+// start starts the download with the context of the load
+func (r *remoteReader) start(ctx context.Context) error {
+	if r.body != nil {
+		return nil
+	}
+	body, err := r.open(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", r.uri, err)
+	}
+	r.body = body
+	return nil
+}
+
+// Read implements io.Reader
+func (r *remoteReader) Read(p []byte) (int, error) {
+	if r.body == nil {
+		return 0, fmt.Errorf("download of %s has not started", r.uri)
+	}
+	return r.body.Read(p)
+}
+
+// Close implements io.Closer
+func (r *remoteReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
+
+// checkResponse returns an error with a code matching the status of a failed response,
+// including the start of its body, which usually explains the failure
+func checkResponse(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	_ = response.Body.Close() // Ignore close error during error handling
+	message := strings.TrimSpace(string(detail))
+	if message != "" {
+		message = ": " + message
+	}
+
+	code := ErrCodeLoadFailed
+	switch response.StatusCode {
+	case http.StatusNotFound:
+		code = ErrCodePathNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		code = ErrCodePermissionDenied
+	}
+	return newCodedError(code, "%s%s", response.Status, message)
+}
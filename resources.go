@@ -0,0 +1,96 @@
+package filesql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// buildResources holds the inputs that Build opens, such as the files of filesystems
+// added with AddFS. Open and StartLoad claim them and release them once they are
+// loaded. Inputs that are never claimed are released when the context passed to Build
+// is done, so a validated builder that is never opened does not keep files open.
+type buildResources struct {
+	mu      sync.Mutex
+	closers []io.Closer
+	claimed bool
+	// err is the reason the inputs were released before they were claimed
+	err  error
+	stop func() bool
+}
+
+// newBuildResources tracks the inputs opened by Build; readers added with AddReader
+// belong to the caller and are not tracked
+func newBuildResources(inputs ...[]readerInput) *buildResources {
+	r := &buildResources{}
+	for _, readers := range inputs {
+		for i := range readers {
+			if closer, ok := readers[i].closer(); ok {
+				r.closers = append(r.closers, closer)
+			}
+		}
+	}
+	return r
+}
+
+// bind releases the inputs when ctx is done before they are claimed
+func (r *buildResources) bind(ctx context.Context) {
+	if r == nil || len(r.closers) == 0 {
+		return
+	}
+	r.stop = context.AfterFunc(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if r.claimed || r.err != nil {
+			return
+		}
+		r.err = fmt.Errorf("inputs opened by Build were released: %w", context.Cause(ctx))
+		r.closeAll()
+	})
+}
+
+// claim hands the inputs over to a load, which must release them when it is done.
+// It returns an error if the inputs were already released.
+func (r *buildResources) claim() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	r.claimed = true
+	if r.stop != nil {
+		r.stop()
+	}
+	return nil
+}
+
+// release closes the inputs that are still open
+func (r *buildResources) release() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop != nil {
+		r.stop()
+	}
+	if r.err == nil && len(r.closers) > 0 {
+		r.err = newCodedError(ErrCodeInvalidConfig, "inputs opened by Build were already loaded; call Build again to load them again")
+	}
+	r.closeAll()
+}
+
+// closeAll closes every tracked input; r.mu must be held
+func (r *buildResources) closeAll() {
+	for _, closer := range r.closers {
+		_ = closer.Close() // Ignore close error; the input is no longer read
+	}
+	r.closers = nil
+}
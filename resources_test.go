@@ -0,0 +1,146 @@
+package filesql
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trackingFS is a filesystem that counts the files that are open
+type trackingFS struct {
+	fstest.MapFS
+
+	mu   sync.Mutex
+	open int
+}
+
+func (f *trackingFS) Open(name string) (fs.File, error) {
+	file, err := f.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.open++
+	return &trackedFile{File: file, fs: f}, nil
+}
+
+func (f *trackingFS) openFiles() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.open
+}
+
+type trackedFile struct {
+	fs.File
+
+	fs     *trackingFS
+	closed bool
+}
+
+func (f *trackedFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		f.fs.open--
+	}
+	return f.File.Close()
+}
+
+func TestBuildResources(t *testing.T) {
+	t.Parallel()
+
+	newFS := func() *trackingFS {
+		return &trackingFS{MapFS: fstest.MapFS{
+			"users.csv":  &fstest.MapFile{Data: []byte("id,name\n1,alice\n")},
+			"orders.csv": &fstest.MapFile{Data: []byte("id,total\n1,50\n")},
+		}}
+	}
+
+	t.Run("files opened by Build are closed once Open has loaded them", func(t *testing.T) {
+		t.Parallel()
+
+		filesystem := newFS()
+		validatedBuilder, err := NewBuilder().AddFS(filesystem).Build(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, filesystem.openFiles())
+
+		db, err := validatedBuilder.Open(context.Background())
+		require.NoError(t, err)
+		defer db.Close()
+		assert.Equal(t, 0, filesystem.openFiles())
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM users JOIN orders USING (id)").Scan(&count))
+		assert.Equal(t, 1, count)
+
+		// The files were consumed, so they cannot be loaded again
+		_, err = validatedBuilder.Open(context.Background())
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+
+	t.Run("files are closed when the context of Build is done", func(t *testing.T) {
+		t.Parallel()
+
+		filesystem := newFS()
+		ctx, cancel := context.WithCancel(context.Background())
+		validatedBuilder, err := NewBuilder().AddFS(filesystem).Build(ctx)
+		require.NoError(t, err)
+		cancel()
+
+		require.Eventually(t, func() bool { return filesystem.openFiles() == 0 }, time.Second, 10*time.Millisecond)
+		_, err = validatedBuilder.Open(context.Background())
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, ErrCodeCancelled, ErrorCodeOf(err))
+	})
+
+	t.Run("a started load is not affected by the context of Build", func(t *testing.T) {
+		t.Parallel()
+
+		filesystem := newFS()
+		ctx, cancel := context.WithCancel(context.Background())
+		validatedBuilder, err := NewBuilder().AddFS(filesystem).Build(ctx)
+		require.NoError(t, err)
+
+		job, err := validatedBuilder.StartLoad(context.Background())
+		require.NoError(t, err)
+		cancel()
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+		assert.Equal(t, 0, filesystem.openFiles())
+	})
+
+	t.Run("files are closed when Build fails", func(t *testing.T) {
+		t.Parallel()
+
+		filesystem := newFS()
+		_, err := NewBuilder().
+			AddFS(filesystem).
+			AddReader(strings.NewReader(""), "empty", FileTypeCSV).
+			Build(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, 0, filesystem.openFiles())
+	})
+
+	t.Run("builders with only paths can be opened repeatedly", func(t *testing.T) {
+		t.Parallel()
+
+		validatedBuilder, err := NewBuilder().AddPath("testdata/sample.csv").Build(context.Background())
+		require.NoError(t, err)
+		for range 2 {
+			db, err := validatedBuilder.Open(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, db.Close())
+		}
+	})
+}
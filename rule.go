@@ -0,0 +1,316 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// violationsTableName is the metadata table that stores data validation violations
+const violationsTableName = "_filesql_violations"
+
+// ruleKind identifies the check performed by a Rule
+type ruleKind int
+
+const (
+	// ruleNotNull requires a non-empty value
+	ruleNotNull ruleKind = iota
+	// ruleUnique requires values to be distinct
+	ruleUnique
+	// ruleRegex requires values to match a regular expression
+	ruleRegex
+	// ruleRange requires numeric values within bounds
+	ruleRange
+	// ruleReferences requires values to exist in another table
+	ruleReferences
+)
+
+// String returns the rule name used in violation reports
+func (k ruleKind) String() string {
+	switch k {
+	case ruleNotNull:
+		return "not_null"
+	case ruleUnique:
+		return "unique"
+	case ruleRegex:
+		return "regex"
+	case ruleRange:
+		return "range"
+	case ruleReferences:
+		return "references"
+	default:
+		return "unknown"
+	}
+}
+
+// Rule is a data quality check applied to a column by WithValidation.
+// Create rules with NotNull, Unique, MatchRegex, InRange, and References.
+type Rule struct {
+	kind   ruleKind
+	column string
+	// pattern is the regular expression for ruleRegex
+	pattern string
+	// minValue and maxValue are the inclusive bounds for ruleRange
+	minValue float64
+	maxValue float64
+	// refTable and refColumn are the referenced column for ruleReferences
+	refTable  string
+	refColumn string
+}
+
+// NotNull reports rows where column is NULL or empty.
+func NotNull(column string) Rule {
+	return Rule{kind: ruleNotNull, column: column}
+}
+
+// Unique reports rows whose non-empty column value appears more than once.
+func Unique(column string) Rule {
+	return Rule{kind: ruleUnique, column: column}
+}
+
+// MatchRegex reports rows whose non-empty column value does not match pattern.
+// The pattern uses Go regexp syntax and is validated by Build.
+func MatchRegex(column, pattern string) Rule {
+	return Rule{kind: ruleRegex, column: column, pattern: pattern}
+}
+
+// InRange reports rows whose non-empty column value is not a number between min and max (inclusive).
+func InRange(column string, minValue, maxValue float64) Rule {
+	return Rule{kind: ruleRange, column: column, minValue: minValue, maxValue: maxValue}
+}
+
+// References reports rows whose non-empty column value does not exist in refTable.refColumn.
+func References(column, refTable, refColumn string) Rule {
+	return Rule{kind: ruleReferences, column: column, refTable: refTable, refColumn: refColumn}
+}
+
+// Violation is a single row that failed a validation rule.
+type Violation struct {
+	// Table is the validated table
+	Table string
+	// Column is the validated column
+	Column string
+	// Rule is the name of the failed rule (not_null, unique, regex, range, references)
+	Rule string
+	// RowID is the SQLite rowid of the offending row
+	RowID int64
+	// Value is the offending value
+	Value string
+}
+
+// tableRules holds the rules configured for a table
+type tableRules struct {
+	table string
+	rules []Rule
+}
+
+// WithValidation registers data quality rules for a table.
+//
+// Rules are evaluated after all inputs (and derived tables) have been loaded.
+// Every violation is recorded in the "_filesql_violations" table, which can be
+// queried with SQL or read with GetViolations. By default violations do not fail
+// the load; call EnableStrictValidation to make Open return ErrValidationFailed instead.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("users.csv").
+//		AddPath("orders.csv").
+//		WithValidation("users",
+//			filesql.NotNull("id"),
+//			filesql.Unique("id"),
+//			filesql.MatchRegex("email", `^[^@]+@[^@]+$`),
+//			filesql.InRange("age", 0, 150)).
+//		WithValidation("orders", filesql.References("user_id", "users", "id"))
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithValidation(table string, rules ...Rule) *DBBuilder {
+	b.validationRules = append(b.validationRules, tableRules{table: table, rules: rules})
+	return b
+}
+
+// EnableStrictValidation makes Open fail with ErrValidationFailed when any rule
+// registered with WithValidation is violated.
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableStrictValidation() *DBBuilder {
+	b.strictValidation = true
+	return b
+}
+
+// GetViolations returns the validation violations recorded while loading db.
+// It returns an empty slice when no rules were configured or all rows passed.
+func GetViolations(db *sql.DB) ([]Violation, error) {
+	ctx := context.Background()
+	violations := make([]Violation, 0)
+
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+		violationsTableName,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check violations table: %w", err)
+	}
+	if exists == 0 {
+		return violations, nil
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`SELECT table_name, column_name, rule, row_id, value FROM "%s" ORDER BY rowid`,
+		violationsTableName,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query violations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v Violation
+		if err := rows.Scan(&v.Table, &v.Column, &v.Rule, &v.RowID, &v.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan violation: %w", err)
+		}
+		violations = append(violations, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read violations: %w", err)
+	}
+	return violations, nil
+}
+
+// validate checks that a rule is well-formed
+func (r Rule) validate() error {
+	if strings.TrimSpace(r.column) == "" {
+		return fmt.Errorf("%s rule requires a column name", r.kind)
+	}
+	switch r.kind {
+	case ruleRegex:
+		if _, err := regexp.Compile(r.pattern); err != nil {
+			return fmt.Errorf("invalid regex for column %s: %w", r.column, err)
+		}
+	case ruleRange:
+		if r.minValue > r.maxValue {
+			return fmt.Errorf("invalid range for column %s: min %g is greater than max %g", r.column, r.minValue, r.maxValue)
+		}
+	case ruleReferences:
+		if strings.TrimSpace(r.refTable) == "" || strings.TrimSpace(r.refColumn) == "" {
+			return fmt.Errorf("references rule for column %s requires a table and column", r.column)
+		}
+	case ruleNotNull, ruleUnique:
+	}
+	return nil
+}
+
+// runValidation evaluates every configured rule and records the violations
+func (b *DBBuilder) runValidation(ctx context.Context, db *sql.DB) error {
+	if len(b.validationRules) == 0 {
+		return nil
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (table_name TEXT, column_name TEXT, rule TEXT, row_id INTEGER, value TEXT)`,
+		violationsTableName,
+	)); err != nil {
+		return fmt.Errorf("failed to create violations table: %w", err)
+	}
+
+	insertQuery := fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`INSERT INTO "%s" (table_name, column_name, rule, row_id, value) VALUES (?, ?, ?, ?, ?)`,
+		violationsTableName,
+	)
+
+	total := 0
+	for _, tr := range b.validationRules {
+		for _, rule := range tr.rules {
+			violations, err := rule.evaluate(ctx, db, tr.table)
+			if err != nil {
+				return fmt.Errorf("failed to evaluate %s rule on %s.%s: %w", rule.kind, tr.table, rule.column, err)
+			}
+			for _, v := range violations {
+				if _, err := db.ExecContext(ctx, insertQuery, v.Table, v.Column, v.Rule, v.RowID, v.Value); err != nil {
+					return fmt.Errorf("failed to record violation: %w", err)
+				}
+			}
+			total += len(violations)
+		}
+	}
+
+	if b.strictValidation && total > 0 {
+		return fmt.Errorf("%w: %d violation(s), see %s", ErrValidationFailed, total, violationsTableName)
+	}
+	return nil
+}
+
+// evaluate returns the rows of table that violate the rule
+func (r Rule) evaluate(ctx context.Context, db *sql.DB, table string) ([]Violation, error) {
+	var query string
+	switch r.kind {
+	case ruleNotNull:
+		query = fmt.Sprintf(`SELECT rowid, "%s" FROM "%s" WHERE "%s" IS NULL OR "%s" = ''`,
+			r.column, table, r.column, r.column)
+	case ruleUnique:
+		query = fmt.Sprintf(`SELECT rowid, "%s" FROM "%s" WHERE "%s" != '' AND "%s" IN (SELECT "%s" FROM "%s" GROUP BY "%s" HAVING COUNT(*) > 1)`,
+			r.column, table, r.column, r.column, r.column, table, r.column)
+	case ruleReferences:
+		query = fmt.Sprintf(`SELECT rowid, "%s" FROM "%s" WHERE "%s" IS NOT NULL AND "%s" != '' AND "%s" NOT IN (SELECT "%s" FROM "%s" WHERE "%s" IS NOT NULL)`,
+			r.column, table, r.column, r.column, r.column, r.refColumn, r.refTable, r.refColumn)
+	case ruleRegex, ruleRange:
+		// SQLite has no built-in REGEXP and CAST silently accepts malformed numbers,
+		// so these rules are evaluated in Go
+		query = fmt.Sprintf(`SELECT rowid, "%s" FROM "%s" WHERE "%s" IS NOT NULL AND "%s" != ''`,
+			r.column, table, r.column, r.column)
+	default:
+		return nil, errors.New("unknown rule")
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pattern *regexp.Regexp
+	if r.kind == ruleRegex {
+		pattern = regexp.MustCompile(r.pattern) // Validated by Build
+	}
+
+	violations := make([]Violation, 0)
+	for rows.Next() {
+		var rowID int64
+		var value sql.NullString
+		if err := rows.Scan(&rowID, &value); err != nil {
+			return nil, err
+		}
+		if !r.violatedBy(value.String, pattern) {
+			continue
+		}
+		violations = append(violations, Violation{
+			Table:  table,
+			Column: r.column,
+			Rule:   r.kind.String(),
+			RowID:  rowID,
+			Value:  value.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return violations, nil
+}
+
+// violatedBy reports whether a value selected by the rule query violates the rule
+func (r Rule) violatedBy(value string, pattern *regexp.Regexp) bool {
+	switch r.kind {
+	case ruleRegex:
+		return !pattern.MatchString(value)
+	case ruleRange:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return err != nil || number < r.minValue || number > r.maxValue
+	default:
+		// The SQL query already selected only violating rows
+		return true
+	}
+}
@@ -0,0 +1,106 @@
+package filesql
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithValidation(t *testing.T) {
+	t.Parallel()
+
+	usersCSV := "id,email,age\n1,alice@example.com,30\n2,bob,200\n2,,abc\n"
+	ordersCSV := "id,user_id\n10,1\n11,9\n"
+
+	t.Run("violations are recorded without failing the load", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(usersCSV), "users", FileTypeCSV).
+			AddReader(strings.NewReader(ordersCSV), "orders", FileTypeCSV).
+			WithValidation("users",
+				NotNull("email"),
+				Unique("id"),
+				MatchRegex("email", `^[^@]+@[^@]+$`),
+				InRange("age", 0, 150)).
+			WithValidation("orders", References("user_id", "users", "id")))
+
+		violations, err := GetViolations(db)
+		require.NoError(t, err)
+		assert.Equal(t, []Violation{
+			{Table: "users", Column: "email", Rule: "not_null", RowID: 3, Value: ""},
+			{Table: "users", Column: "id", Rule: "unique", RowID: 2, Value: "2"},
+			{Table: "users", Column: "id", Rule: "unique", RowID: 3, Value: "2"},
+			{Table: "users", Column: "email", Rule: "regex", RowID: 2, Value: "bob"},
+			{Table: "users", Column: "age", Rule: "range", RowID: 2, Value: "200"},
+			{Table: "users", Column: "age", Rule: "range", RowID: 3, Value: "abc"},
+			{Table: "orders", Column: "user_id", Rule: "references", RowID: 2, Value: "9"},
+		}, violations)
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM _filesql_violations WHERE rule = 'range'").Scan(&count))
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("strict validation fails the load", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := tryOpenTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(usersCSV), "users", FileTypeCSV).
+			WithValidation("users", Unique("id")).
+			EnableStrictValidation())
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrValidationFailed))
+	})
+
+	t.Run("strict validation passes clean data", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n2\n"), "users", FileTypeCSV).
+			WithValidation("users", NotNull("id"), Unique("id"), InRange("id", 1, 2)).
+			EnableStrictValidation())
+
+		violations, err := GetViolations(db)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("invalid rules are rejected at build time", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name string
+			rule Rule
+		}{
+			{name: "empty column", rule: NotNull("")},
+			{name: "invalid regex", rule: MatchRegex("email", "(")},
+			{name: "inverted range", rule: InRange("age", 10, 1)},
+			{name: "missing reference", rule: References("user_id", "", "id")},
+		}
+
+		for _, tt := range tests {
+			_, err := NewBuilder().
+				AddPath(filepath.Join("testdata", "sample.csv")).
+				WithValidation("sample", tt.rule).
+				Build(context.Background())
+			assert.Error(t, err, tt.name)
+		}
+	})
+
+	t.Run("database without rules has no violations", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+
+		violations, err := GetViolations(db)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+}
@@ -0,0 +1,280 @@
+package filesql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3URIScheme is the prefix of S3 URIs accepted by AddPath
+const s3URIScheme = "s3://"
+
+// emptyPayloadHash is the SHA-256 hash of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Config configures how objects added with AddS3 or as s3:// paths are downloaded.
+// Empty fields fall back to the standard AWS environment variables.
+type S3Config struct {
+	// Region is the region of the buckets, such as "eu-west-1". Defaults to
+	// AWS_REGION, then AWS_DEFAULT_REGION, then "us-east-1".
+	Region string
+	// AccessKeyID and SecretAccessKey are the credentials requests are signed with.
+	// They default to AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY. Without
+	// credentials, objects are requested anonymously, which works for public buckets.
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is the token of temporary credentials. Defaults to
+	// AWS_SESSION_TOKEN when the credentials come from the environment.
+	SessionToken string
+	// Endpoint is the URL of an S3-compatible service, such as
+	// "http://localhost:9000" for MinIO. Objects are then addressed path-style, as
+	// Endpoint/bucket/key.
+	Endpoint string
+	// HTTPClient sends the requests; nil uses http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// WithS3Config sets how S3 objects are downloaded (see AddS3).
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("s3://analytics/events/2024-06.csv.gz").
+//		WithS3Config(filesql.S3Config{Region: "eu-west-1"})
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithS3Config(config S3Config) *DBBuilder {
+	b.s3Config = config
+	return b
+}
+
+// AddS3 adds an object of Amazon S3 or an S3-compatible service.
+//
+// The object is downloaded while the database is opened and streamed through the
+// same decompression and parsing as local files, so large CSV, TSV, and LTSV objects
+// are never held in memory as a whole. The key determines the table name and file
+// type like a file path does: "events/2024-06.csv.gz" becomes the table "2024-06".
+// Paths passed to AddPath, AddPaths, Open, and OpenContext can also be S3 URIs of
+// the form "s3://bucket/key".
+//
+// Credentials and the region are set with WithS3Config or taken from the AWS
+// environment variables. Open fails with an error with ErrCodePathNotFound if the
+// object does not exist and with ErrCodePermissionDenied if access is denied.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddS3("analytics", "events/2024-06.csv.gz").
+//		WithS3Config(filesql.S3Config{Region: "eu-west-1"})
+//
+// Returns self for chaining.
+func (b *DBBuilder) AddS3(bucket, key string) *DBBuilder {
+	b.remotes = append(b.remotes, newS3Input(bucket, key))
+	b.inputOrder = append(b.inputOrder, inputRemote)
+	return b
+}
+
+// isS3URI reports whether path is an S3 URI
+func isS3URI(path string) bool {
+	return strings.HasPrefix(path, s3URIScheme)
+}
+
+// newS3InputFromURI returns the remote input of an s3://bucket/key URI
+func newS3InputFromURI(uri string) remoteInput {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(uri, s3URIScheme), "/")
+	input := newS3Input(bucket, key)
+	input.uri = uri
+	return input
+}
+
+// newS3Input returns the remote input of an S3 object
+func newS3Input(bucket, key string) remoteInput {
+	uri := s3URIScheme + bucket + "/" + key
+	input := remoteInput{uri: uri, name: key, s3: &s3Object{bucket: bucket, key: key}}
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		input.err = newCodedError(ErrCodeInvalidConfig, "invalid S3 object %s: bucket and key are required", uri)
+	}
+	return input
+}
+
+// s3Object is an object in a bucket
+type s3Object struct {
+	bucket string
+	key    string
+}
+
+// get starts downloading the object
+func (o s3Object) get(ctx context.Context, config S3Config) (io.ReadCloser, error) {
+	config = config.withDefaults()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, o.url(config), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if config.AccessKeyID != "" {
+		signS3Request(request, config, time.Now())
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(response); err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// url returns the URL of the object. Buckets are addressed virtual-hosted style on
+// AWS, except for bucket names with dots, which do not match the TLS certificate, and
+// path-style on custom endpoints.
+func (o s3Object) url(config S3Config) string {
+	key := escapeS3Path(o.key)
+	if config.Endpoint != "" {
+		return strings.TrimSuffix(config.Endpoint, "/") + "/" + escapeS3Path(o.bucket) + "/" + key
+	}
+	if strings.Contains(o.bucket, ".") {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", config.Region, o.bucket, key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", o.bucket, config.Region, key)
+}
+
+// withDefaults fills empty fields from the environment
+func (c S3Config) withDefaults() S3Config {
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_REGION")
+	}
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	if c.AccessKeyID == "" && c.SecretAccessKey == "" {
+		c.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		c.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if c.SessionToken == "" {
+			c.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+	}
+	return c
+}
+
+// escapeS3Path escapes a bucket name or key for the path of a request, keeping slashes
+func escapeS3Path(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escapeS3(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escapeS3 percent-encodes every byte except the unreserved characters of RFC 3986,
+// as AWS Signature Version 4 requires
+func escapeS3(s string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+// signS3Request signs a request without a body with AWS Signature Version 4. The Host,
+// Range, and x-amz-* headers are signed.
+func signS3Request(request *http.Request, config S3Config, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + config.Region + "/s3/aws4_request"
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	if config.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", config.SessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretAccessKey), date)
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns the query parameters sorted and encoded for signing
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, escapeS3(name)+"="+escapeS3(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// hexSHA256 returns the hex-encoded SHA-256 hash of s
+func hexSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
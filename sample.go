@@ -0,0 +1,181 @@
+package filesql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GenerateSample writes a small, shuffled, anonymized sample of a table to outputPath.
+//
+// Use it to share reproducible bug reports without leaking production data.
+// Rows are picked at random, so the sample follows the value distribution of the
+// full table. Values in anonymizeColumns are masked character by character:
+// digits become other digits and letters become other letters of the same case,
+// while punctuation and length are kept. Masking is consistent within one call,
+// so equal values stay equal and joins, GROUP BY, and cardinality are preserved.
+//
+// The output format is chosen from the extension of outputPath (.csv, .tsv, or .ltsv,
+// optionally followed by .gz, .bz2, .xz, or .zst). Values are written like DumpDatabase
+// writes them: BLOB values as their text, and NULL as an empty field that is not masked.
+//
+// Example:
+//
+//	// Write 100 random rows of "users", masking names and emails
+//	err := filesql.GenerateSample(ctx, db, "users", 100, "users_sample.csv", "name", "email")
+func GenerateSample(ctx context.Context, db *sql.DB, table string, n int, outputPath string, anonymizeColumns ...string) error {
+	if n <= 0 {
+		return errors.New("sample size must be greater than 0")
+	}
+
+	columns, err := getSQLiteTableColumns(db, table)
+	if err != nil {
+		return fmt.Errorf("failed to get columns for table %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("table %s does not exist", table)
+	}
+
+	masked := make([]bool, len(columns))
+	for _, name := range anonymizeColumns {
+		found := false
+		for i, col := range columns {
+			if col == name {
+				masked[i] = true
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("column %s does not exist in table %s", name, table)
+		}
+	}
+
+	factory := NewCompressionFactory()
+	fileType := factory.GetBaseFileType(outputPath)
+	if fileType != FileTypeCSV && fileType != FileTypeTSV && fileType != FileTypeLTSV {
+		return fmt.Errorf("%w: sample output must be CSV, TSV, or LTSV: %s", ErrUnsupportedFormat, outputPath)
+	}
+
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate masking key: %w", err)
+	}
+
+	query := fmt.Sprintf("SELECT * FROM `%s` ORDER BY random() LIMIT ?", table) //nolint:gosec // Table name is checked against database metadata above
+	rows, err := db.QueryContext(ctx, query, n)
+	if err != nil {
+		return fmt.Errorf("failed to sample table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	values := make([]any, len(columns))
+	scanArgs := make([]any, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	formatter := newValueFormatter(columns, NewDumpOptions())
+	records := make([][]string, 0, n)
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+		record := make([]string, len(columns))
+		for i, value := range values {
+			if blob, ok := value.([]byte); ok {
+				value = string(blob)
+			}
+			record[i] = formatter.format(i, value)
+			if masked[i] && value != nil {
+				record[i] = maskValue(key, record[i])
+			}
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	writer, closeWriter, err := factory.CreateWriterForFile(outputPath, factory.DetectCompressionType(outputPath))
+	if err != nil {
+		return err
+	}
+
+	if err := writeSampleRecords(writer, fileType, columns, records); err != nil {
+		_ = closeWriter() // Ignore close error during error handling
+		return fmt.Errorf("failed to write sample %s: %w", outputPath, err)
+	}
+	return closeWriter()
+}
+
+// writeSampleRecords writes records in the given text format
+func writeSampleRecords(writer io.Writer, fileType FileType, columns []string, records [][]string) error {
+	if fileType == FileTypeLTSV {
+		for _, record := range records {
+			parts := make([]string, len(columns))
+			for i, col := range columns {
+				parts[i] = col + ":" + record[i]
+			}
+			if _, err := io.WriteString(writer, strings.Join(parts, "\t")+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	if fileType == FileTypeTSV {
+		csvWriter.Comma = tsvDelimiter
+	}
+	if err := csvWriter.Write(columns); err != nil {
+		return err
+	}
+	if err := csvWriter.WriteAll(records); err != nil {
+		return err
+	}
+	return csvWriter.Error()
+}
+
+// maskValue replaces letters and digits of value with pseudo-random characters of the
+// same class. The replacement is derived from key and value, so equal inputs produce
+// equal outputs for the same key.
+func maskValue(key []byte, value string) string {
+	if value == "" {
+		return value
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	digest := mac.Sum(nil)
+
+	var masked strings.Builder
+	for i, r := range []rune(value) {
+		b := digest[i%len(digest)] ^ byte(i/len(digest))
+		switch {
+		case r >= '0' && r <= '9':
+			masked.WriteRune('0' + rune(b%10))
+		case r >= 'a' && r <= 'z':
+			masked.WriteRune('a' + rune(b%26))
+		case r >= 'A' && r <= 'Z':
+			masked.WriteRune('A' + rune(b%26))
+		case r > 127:
+			// Non-ASCII letters (e.g. names in other scripts) are replaced with ASCII letters
+			masked.WriteRune('a' + rune(b%26))
+		default:
+			masked.WriteRune(r)
+		}
+	}
+	return masked.String()
+}
@@ -0,0 +1,131 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSample(t *testing.T) {
+	t.Parallel()
+
+	openUsers := func(t *testing.T) *sql.DB {
+		t.Helper()
+		data := "id,name,email\n1,Alice,alice@example.com\n2,Bob,bob@example.com\n3,Alice,alice@example.com\n4,Carol,carol@example.com\n"
+		return openTestDB(t, NewBuilder().AddReader(strings.NewReader(data), "users", FileTypeCSV))
+	}
+
+	t.Run("writes masked sample that can be loaded again", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openUsers(t)
+		outputPath := filepath.Join(t.TempDir(), "sample", "users.csv")
+		require.NoError(t, GenerateSample(ctx, db, "users", 10, outputPath, "name", "email"))
+
+		sampleDB := openTestDB(t, NewBuilder().AddPath(outputPath))
+
+		var count, distinctNames int
+		require.NoError(t, sampleDB.QueryRowContext(ctx, "SELECT COUNT(*), COUNT(DISTINCT name) FROM users").Scan(&count, &distinctNames))
+		assert.Equal(t, 4, count)
+		assert.Equal(t, 3, distinctNames, "equal values must stay equal after masking")
+
+		var leaked int
+		require.NoError(t, sampleDB.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM users WHERE name IN ('Alice', 'Bob', 'Carol')").Scan(&leaked))
+		assert.Equal(t, 0, leaked)
+
+		var idSum int
+		require.NoError(t, sampleDB.QueryRowContext(ctx, "SELECT SUM(id) FROM users").Scan(&idSum))
+		assert.Equal(t, 10, idSum, "unmasked columns must be kept")
+	})
+
+	t.Run("limits the number of rows", func(t *testing.T) {
+		t.Parallel()
+
+		db := openUsers(t)
+		outputPath := filepath.Join(t.TempDir(), "users.tsv")
+		require.NoError(t, GenerateSample(context.Background(), db, "users", 2, outputPath))
+
+		data, err := os.ReadFile(outputPath) //nolint:gosec // Test file path
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "id\tname\temail", lines[0])
+	})
+
+	t.Run("BLOB values are written as text and NULL as an empty field", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openUsers(t)
+		_, err := db.ExecContext(ctx, "CREATE TABLE files (id INTEGER, data BLOB, note TEXT); INSERT INTO files VALUES (1, X'6869', NULL)")
+		require.NoError(t, err)
+
+		outputPath := filepath.Join(t.TempDir(), "files.csv")
+		require.NoError(t, GenerateSample(ctx, db, "files", 1, outputPath, "note"))
+
+		data, err := os.ReadFile(outputPath) //nolint:gosec // Test file path
+		require.NoError(t, err)
+		assert.Equal(t, "id,data,note\n1,hi,\n", string(data))
+	})
+
+	errorTests := []struct {
+		name        string
+		table       string
+		rows        int
+		file        string
+		maskColumns []string
+		wantErr     error
+	}{
+		{name: "rows must be positive", table: "users", rows: 0, file: "a.csv"},
+		{name: "table must exist", table: "missing", rows: 1, file: "b.csv"},
+		{name: "masked columns must exist", table: "users", rows: 1, file: "c.csv", maskColumns: []string{"unknown"}},
+		{name: "format must be supported", table: "users", rows: 1, file: "d.parquet", wantErr: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := GenerateSample(context.Background(), openUsers(t), tt.table, tt.rows, filepath.Join(t.TempDir(), tt.file), tt.maskColumns...)
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("test-key")
+
+	t.Run("keeps length, character classes, and punctuation", func(t *testing.T) {
+		t.Parallel()
+
+		masked := maskValue(key, "Ab-12@x.io")
+		require.Len(t, masked, len("Ab-12@x.io"))
+		assert.Regexp(t, `^[A-Z][a-z]-[0-9]{2}@[a-z]\.[a-z]{2}$`, masked)
+	})
+
+	t.Run("is deterministic for the same key", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, maskValue(key, "Alice"), maskValue(key, "Alice"))
+		assert.NotEqual(t, maskValue(key, "Alice"), maskValue([]byte("other-key"), "Alice"))
+	})
+
+	t.Run("empty value stays empty", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, maskValue(key, ""))
+	})
+}
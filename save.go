@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"modernc.org/sqlite"
 )
@@ -38,6 +40,12 @@ const (
 	OutputFormatParquet
 	// OutputFormatXLSX represents Excel XLSX output format
 	OutputFormatXLSX
+	// OutputFormatJSONL represents JSON Lines output format, one JSON object per row
+	OutputFormatJSONL
+	// OutputFormatSSV represents semicolon-separated values output format
+	OutputFormatSSV
+	// OutputFormatPSV represents pipe-separated values output format
+	OutputFormatPSV
 )
 
 // String returns the string representation of OutputFormat
@@ -53,6 +61,12 @@ func (f OutputFormat) String() string {
 		return "parquet"
 	case OutputFormatXLSX:
 		return "xlsx"
+	case OutputFormatJSONL:
+		return "jsonl"
+	case OutputFormatSSV:
+		return "ssv"
+	case OutputFormatPSV:
+		return "psv"
 	default:
 		return "csv"
 	}
@@ -71,6 +85,12 @@ func (f OutputFormat) Extension() string {
 		return ".parquet"
 	case OutputFormatXLSX:
 		return ".xlsx"
+	case OutputFormatJSONL:
+		return ".jsonl"
+	case OutputFormatSSV:
+		return ".ssv"
+	case OutputFormatPSV:
+		return ".psv"
 	default:
 		return ".csv"
 	}
@@ -90,6 +110,10 @@ const (
 	CompressionXZ
 	// CompressionZSTD represents zstd compression
 	CompressionZSTD
+	// CompressionLZ4 represents lz4 compression
+	CompressionLZ4
+	// CompressionBR represents brotli compression
+	CompressionBR
 )
 
 // string constants for compression types
@@ -98,6 +122,8 @@ const (
 	compressionBZ2Str  = "bz2"
 	compressionXZStr   = "xz"
 	compressionZSTDStr = "zstd"
+	compressionLZ4Str  = "lz4"
+	compressionBRStr   = "br"
 )
 
 // String returns the string representation of CompressionType
@@ -113,6 +139,10 @@ func (c CompressionType) String() string {
 		return compressionXZStr
 	case CompressionZSTD:
 		return compressionZSTDStr
+	case CompressionLZ4:
+		return compressionLZ4Str
+	case CompressionBR:
+		return compressionBRStr
 	default:
 		return "none"
 	}
@@ -131,6 +161,10 @@ func (c CompressionType) Extension() string {
 		return ".xz"
 	case CompressionZSTD:
 		return ".zst"
+	case CompressionLZ4:
+		return ".lz4"
+	case CompressionBR:
+		return ".br"
 	default:
 		return ""
 	}
@@ -150,13 +184,52 @@ type DumpOptions struct {
 	Format OutputFormat
 	// Compression specifies the compression type
 	Compression CompressionType
+	// TableCompression maps table names to compression types overriding Compression (see WithTableCompression)
+	TableCompression map[string]CompressionType
+	// ParquetCompression is the codec of Parquet column data (see WithParquetCompression)
+	ParquetCompression ParquetCompression
+	// OutputRoot, if set, is the only directory tree output files may be written to
+	OutputRoot string
+	// FloatFormat, if set, is the fmt format of floating-point values (see WithFloatFormat)
+	FloatFormat string
+	// ColumnFloatFormats maps column names to float formats overriding FloatFormat
+	ColumnFloatFormats map[string]string
+	// SafeJSONIntegers writes integers beyond 2^53-1 as JSON strings (see WithSafeJSONIntegers)
+	SafeJSONIntegers bool
+	// NullConvention tells NULL and empty strings apart in delimited files (see WithNullConvention)
+	NullConvention NullConvention
+	// DowncastHandler, if set, is called with values whose text is ambiguous or lossy (see WithDowncastHandler)
+	DowncastHandler func(DowncastIssue)
+	// SchemaComments starts CSV and TSV files with comments describing the table (see WithSchemaComments)
+	SchemaComments bool
+	// Workbook, if set, is the name of the Excel workbook every table is written to as a sheet (see WithWorkbook)
+	Workbook string
+	// EmptyTables controls how tables without rows are exported (see WithEmptyTablePolicy)
+	EmptyTables EmptyTablePolicy
+	// Tables, if set, are the only tables that are exported (see WithTables)
+	Tables []string
+	// ExcludeTables holds SQL LIKE patterns of tables that are not exported (see WithExcludedTables)
+	ExcludeTables []string
+	// AtomicWrites writes each file to a temporary file and renames it into place (see WithAtomicWrites)
+	AtomicWrites bool
+	// LockFile holds an advisory lock on the output directory while dumping (see WithLockFile)
+	LockFile bool
+	// FileSystem, if set, is the file system output files are written to (see WithFileSystem)
+	FileSystem FileSystem
+
+	// changed, if not nil, holds the lower-case names of the tables changed since the
+	// previous auto-save, which are the only tables auto-save writes
+	changed map[string]bool
+	// schemaComments are the comment lines written before the header of the table
+	// being dumped, set by withSchemaCommentLines
+	schemaComments []string
 }
 
 // NewDumpOptions creates default export options (CSV, no compression).
 //
 // Modify with:
 //   - WithFormat(): Change file format (CSV, TSV, LTSV)
-//   - WithCompression(): Add compression (GZ, BZ2, XZ, ZSTD)
+//   - WithCompression(): Add compression (GZ, BZ2, XZ, ZSTD, LZ4, BR)
 func NewDumpOptions() DumpOptions {
 	return DumpOptions{
 		Format:      OutputFormatCSV,
@@ -171,6 +244,9 @@ func NewDumpOptions() DumpOptions {
 //   - OutputFormatTSV: Tab-separated values
 //   - OutputFormatLTSV: Labeled tab-separated values
 //   - OutputFormatParquet: Apache Parquet columnar format
+//   - OutputFormatJSONL: JSON Lines, one object per row
+//   - OutputFormatSSV: Semicolon-separated values
+//   - OutputFormatPSV: Pipe-separated values
 func (o DumpOptions) WithFormat(format OutputFormat) DumpOptions {
 	o.Format = format
 	return o
@@ -184,6 +260,10 @@ func (o DumpOptions) WithFormat(format OutputFormat) DumpOptions {
 //   - CompressionBZ2: Bzip2 compression (.bz2)
 //   - CompressionXZ: XZ compression (.xz)
 //   - CompressionZSTD: Zstandard compression (.zst)
+//   - CompressionLZ4: LZ4 compression (.lz4)
+//   - CompressionBR: Brotli compression (.br)
+//
+// Parquet output cannot be compressed externally; use WithParquetCompression instead.
 func (o DumpOptions) WithCompression(compression CompressionType) DumpOptions {
 	o.Compression = compression
 	return o
@@ -216,21 +296,57 @@ type autoSaveConfig struct {
 	outputDir string
 	// options contains dump options for formatting
 	options DumpOptions
+	// timeout bounds how long a single auto-save may take; 0 means no limit
+	timeout time.Duration
+	// clock measures the timeout; nil uses the system clock
+	clock Clock
 }
 
-// autoSaveConnector implements driver.Connector interface with auto-save support
+// autoSaveConnector implements driver.Connector interface with auto-save support.
+//
+// Saves read the database through the anchor connection of the in-memory database.
+// Like every connection, it does not read uncommitted data, so a save waits for a
+// transaction that is still open on another connection and never persists it.
 type autoSaveConnector struct {
-	sqliteConn     driver.Conn
+	memory         *memoryConnector
 	autoSaveConfig *autoSaveConfig
 	originalPaths  []string
+	// writeBack are the tables written back into the writable filesystems they were loaded from
+	writeBack []writeBackTarget
+	// periodic saves the database on a timer and after changed rows; nil without
+	// WithAutoSaveInterval and WithAutoSaveRowThreshold
+	periodic *periodicAutoSave
+	// changed records the tables changed since the previous save, which are the only
+	// tables a save writes
+	changed *changedTables
+
+	// saveMu serializes saves and guards anchorDB
+	saveMu sync.Mutex
+	// anchorDB reads the database through the anchor connection; nil once closed
+	anchorDB *sql.DB
+}
+
+// newAutoSaveConnector returns a connector to memory that saves with config. An output
+// directory may not hold the tables yet, so the first save into it writes all tables.
+func newAutoSaveConnector(memory *memoryConnector, config *autoSaveConfig, originalPaths []string) *autoSaveConnector {
+	return &autoSaveConnector{
+		memory:         memory,
+		autoSaveConfig: config,
+		originalPaths:  originalPaths,
+		changed:        newChangedTables(config.outputDir != ""),
+		anchorDB:       sql.OpenDB(&directConnector{conn: memory.anchor}),
+	}
 }
 
 // Connect implements driver.Connector interface
-func (c *autoSaveConnector) Connect(_ context.Context) (driver.Conn, error) {
+func (c *autoSaveConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.memory.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return &autoSaveConnection{
-		conn:           c.sqliteConn,
-		autoSaveConfig: c.autoSaveConfig,
-		originalPaths:  c.originalPaths,
+		conn:      conn,
+		connector: c,
 	}, nil
 }
 
@@ -239,28 +355,71 @@ func (c *autoSaveConnector) Driver() driver.Driver {
 	return &sqlite.Driver{}
 }
 
+// Close implements io.Closer, which sql.DB.Close calls after closing its connections.
+// It stops periodic saving, saves the database when auto-save on close is configured,
+// and drops the database. It returns the error of the save and of the last periodic save.
+func (c *autoSaveConnector) Close() error {
+	_ = c.memory.watcher.stop() // Stop reloading tables before the final save
+	periodicErr := c.periodic.stop()
+
+	var saveErr error
+	if c.autoSaveConfig.enabled && c.autoSaveConfig.timing == autoSaveOnClose {
+		if err := c.save(context.Background()); err != nil {
+			saveErr = fmt.Errorf("auto-save failed: %w", err)
+		}
+	}
+
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	closeErr := c.anchorDB.Close()
+	c.anchorDB = nil
+	if err := c.memory.Close(); err != nil && closeErr == nil {
+		closeErr = err
+	}
+	if closeErr != nil {
+		closeErr = fmt.Errorf("failed to close connection: %w", closeErr)
+	}
+	return errors.Join(saveErr, periodicErr, closeErr)
+}
+
+// start starts tracking the changed tables and periodic saving once the inputs are
+// loaded. It does nothing on a nil receiver, so callers need not check whether
+// auto-save is enabled.
+func (c *autoSaveConnector) start(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	if err := c.startTrackingChanges(ctx); err != nil {
+		return err
+	}
+	return c.periodic.start(ctx)
+}
+
+// save saves the database with the configured settings
+func (c *autoSaveConnector) save(ctx context.Context) error {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	return c.saveLocked(ctx)
+}
+
 // autoSaveConnection wraps a database connection with auto-save functionality
 type autoSaveConnection struct {
-	conn           driver.Conn
-	autoSaveConfig *autoSaveConfig
-	originalPaths  []string
+	conn driver.Conn
+	// connector saves the database
+	connector *autoSaveConnector
+	// inTransaction is true between BeginTx and the end of the transaction
+	inTransaction bool
+	// changes is the number of rows the connection changed when they were last counted
+	// for WithAutoSaveRowThreshold; valid once baselined is true
+	changes   int64
+	baselined bool
+	// pending are the tables changed by the open transaction, recorded on commit
+	pending tableChanges
 }
 
-// Close implements driver.Conn interface with auto-save on close
+// Close implements driver.Conn interface. Auto-save on close runs once, when the
+// database is closed (see autoSaveConnector.Close), not for every connection.
 func (c *autoSaveConnection) Close() error {
-	// Perform auto-save if configured for close timing
-	if c.autoSaveConfig != nil && c.autoSaveConfig.enabled && c.autoSaveConfig.timing == autoSaveOnClose {
-		if err := c.performAutoSave(); err != nil {
-			// Close the underlying connection first to avoid resource leaks
-			closeErr := c.conn.Close()
-			// Return the auto-save error as it's more important for the user
-			if closeErr != nil {
-				return fmt.Errorf("auto-save failed: %w (also failed to close connection: %w)", err, closeErr)
-			}
-			return fmt.Errorf("auto-save failed: %w", err)
-		}
-	}
-
 	return c.conn.Close()
 }
 
@@ -276,6 +435,7 @@ func (c *autoSaveConnection) BeginTx(ctx context.Context, opts driver.TxOptions)
 		if err != nil {
 			return nil, err
 		}
+		c.inTransaction = true
 		return &autoSaveTransaction{
 			tx:   tx,
 			conn: c,
@@ -287,19 +447,137 @@ func (c *autoSaveConnection) BeginTx(ctx context.Context, opts driver.TxOptions)
 	if err != nil {
 		return nil, err
 	}
+	c.inTransaction = true
 	return &autoSaveTransaction{
 		tx:   tx,
 		conn: c,
 	}, nil
 }
 
-// Prepare implements driver.Conn interface
+// Prepare implements driver.Conn interface. Statements record the tables they change
+// and, with WithAutoSaveRowThreshold, count the rows they change.
 func (c *autoSaveConnection) Prepare(query string) (driver.Stmt, error) {
-	return c.conn.Prepare(query)
+	stmt, err := c.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &autoSaveStmt{Stmt: stmt, conn: c, query: query}, nil
 }
 
-// ExecContext implements driver.ExecerContext interface
+// ExecContext implements driver.ExecerContext interface. It records the tables the
+// statement changed and, with WithAutoSaveRowThreshold, counts the rows it changed.
 func (c *autoSaveConnection) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.baselineChanges(ctx); err != nil {
+		return nil, err
+	}
+	result, err := c.execSavingSchemaChanges(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.countModifiedRows(ctx); err != nil {
+		return nil, fmt.Errorf("statement executed successfully, but auto-save failed: %w", err)
+	}
+	return result, nil
+}
+
+// execSavingSchemaChanges executes a statement.
+//
+// With auto-save on commit, a statement executed outside of a transaction commits
+// implicitly. When such a statement changes the schema (CREATE, ALTER, or DROP), the
+// database is saved as if a transaction had been committed, so new tables and
+// columns are persisted without waiting for the next explicit commit. Statements in
+// a transaction begun with a BEGIN statement are not saved, since the transaction may
+// still be rolled back.
+func (c *autoSaveConnection) execSavingSchemaChanges(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !c.savesSchemaChanges() {
+		return c.execRecordingChanges(ctx, query, args)
+	}
+
+	before, err := c.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.execRecordingChanges(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	after, err := c.schemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if after != before && !c.transactionOpen(ctx) {
+		if err := c.connector.save(ctx); err != nil {
+			return nil, fmt.Errorf("statement executed successfully, but auto-save failed: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// execRecordingChanges executes a statement and records the tables it changed
+func (c *autoSaveConnection) execRecordingChanges(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	changes := c.statementChanges(ctx, query, args)
+	result, err := c.execContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	c.recordChanges(ctx, changes)
+	return result, nil
+}
+
+// savesSchemaChanges reports whether schema changes outside of transactions trigger auto-save
+func (c *autoSaveConnection) savesSchemaChanges() bool {
+	config := c.connector.autoSaveConfig
+	return config.enabled && config.timing == autoSaveOnCommit && !c.inTransaction
+}
+
+// transactionOpen reports whether a transaction is open on the connection, including
+// one begun by a BEGIN statement instead of BeginTx. Saving inside such a transaction
+// would wait forever for the connection's own locks. The driver does not expose
+// SQLite's autocommit state, but SQLite refuses to begin a transaction inside another
+// one, so a failing BEGIN means a transaction is open, or that the state is unknown and
+// saving is not safe either.
+func (c *autoSaveConnection) transactionOpen(ctx context.Context) bool {
+	if _, err := c.execContext(ctx, "BEGIN", nil); err != nil {
+		return true
+	}
+	// The probe must not leave its empty transaction open, even if ctx is done by now
+	if _, err := c.execContext(context.WithoutCancel(ctx), "ROLLBACK", nil); err != nil {
+		return true
+	}
+	return false
+}
+
+// schemaVersion returns SQLite's schema version, which changes with every DDL statement
+func (c *autoSaveConnection) schemaVersion(ctx context.Context) (int64, error) {
+	version, err := c.queryInt64(ctx, "PRAGMA schema_version")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// queryInt64 runs a query that returns a single integer
+func (c *autoSaveConnection) queryInt64(ctx context.Context, query string) (int64, error) {
+	rows, err := c.queryContext(ctx, query, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	values := make([]driver.Value, len(rows.Columns()))
+	if err := rows.Next(values); err != nil {
+		return 0, err
+	}
+	value, ok := values[0].(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected value %v", values[0])
+	}
+	return value, nil
+}
+
+// execContext executes a statement on the wrapped connection
+func (c *autoSaveConnection) execContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
 	if execer, ok := c.conn.(driver.ExecerContext); ok {
 		return execer.ExecContext(ctx, query, args)
 	}
@@ -314,8 +592,20 @@ func (c *autoSaveConnection) ExecContext(ctx context.Context, query string, args
 	return nil, driver.ErrSkip
 }
 
-// QueryContext implements driver.QueryerContext interface
+// QueryContext implements driver.QueryerContext interface. It records the tables
+// changed by statements such as INSERT ... RETURNING.
 func (c *autoSaveConnection) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	changes := c.statementChanges(ctx, query, args)
+	rows, err := c.queryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	c.recordChanges(ctx, changes)
+	return rows, nil
+}
+
+// queryContext runs a query on the wrapped connection
+func (c *autoSaveConnection) queryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
 	if queryer, ok := c.conn.(driver.QueryerContext); ok {
 		return queryer.QueryContext(ctx, query, args)
 	}
@@ -338,61 +628,113 @@ type autoSaveTransaction struct {
 
 // Commit implements driver.Tx interface with auto-save on commit
 func (t *autoSaveTransaction) Commit() error {
+	t.conn.inTransaction = false
+
 	// First commit the underlying transaction
 	if err := t.tx.Commit(); err != nil {
+		t.conn.pending = tableChanges{}
 		return err
 	}
+	t.conn.commitChanges()
 
 	// Perform auto-save if configured for commit timing
-	if t.conn.autoSaveConfig != nil && t.conn.autoSaveConfig.enabled && t.conn.autoSaveConfig.timing == autoSaveOnCommit {
-		if err := t.conn.performAutoSave(); err != nil {
+	if config := t.conn.connector.autoSaveConfig; config.enabled && config.timing == autoSaveOnCommit {
+		// The save persists the rows changed by the transaction, so they are not counted
+		t.conn.baselined = false
+		if err := t.conn.connector.save(context.Background()); err != nil {
 			// Auto-save failed, but the transaction was already committed
 			// Return the auto-save error to notify the user
 			return fmt.Errorf("transaction committed successfully, but auto-save failed: %w", err)
 		}
+		return nil
 	}
 
+	if err := t.conn.countModifiedRows(context.Background()); err != nil {
+		return fmt.Errorf("transaction committed successfully, but auto-save failed: %w", err)
+	}
 	return nil
 }
 
 // Rollback implements driver.Tx interface
 func (t *autoSaveTransaction) Rollback() error {
+	t.conn.inTransaction = false
+	// Rows and tables of the rolled back transaction are not counted
+	t.conn.baselined = false
+	t.conn.pending = tableChanges{}
 	return t.tx.Rollback()
 }
 
-// performAutoSave executes automatic saving using the configured settings
-func (c *autoSaveConnection) performAutoSave() error {
+// saveLocked executes automatic saving using the configured settings.
+// The save stops when ctx is done or the auto-save timeout expires.
+// The caller must hold saveMu.
+func (c *autoSaveConnector) saveLocked(ctx context.Context) error {
 	if c.autoSaveConfig == nil || !c.autoSaveConfig.enabled {
 		return nil // No auto-save configured
 	}
+	if c.anchorDB == nil {
+		return sql.ErrConnDone
+	}
+	outputDir := c.autoSaveConfig.outputDir
+	if outputDir == "" && len(c.originalPaths) == 0 && len(c.writeBack) == 0 {
+		return errors.New("no original paths available for overwrite")
+	}
+	// Rows and tables changed from now on may not be in the save, so only these are discounted
+	pendingRows := c.periodic.pendingRows()
+	changed, generation := c.changed.snapshot()
+	if changed != nil && len(changed) == 0 {
+		c.periodic.rowsSaved(pendingRows)
+		return nil // No table changed since the previous save
+	}
+	options := c.autoSaveConfig.options
+	options.changed = changed
 
-	// Create a temporary SQL DB to use DumpDatabase function
-	tempDB := sql.OpenDB(&directConnector{conn: c.conn})
+	if c.autoSaveConfig.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withClockTimeout(ctx, c.autoSaveConfig.clock, c.autoSaveConfig.timeout)
+		defer cancel()
+	}
 
-	outputDir := c.autoSaveConfig.outputDir
+	var err error
 	if outputDir == "" {
 		// Overwrite mode - save to original file locations
-		return c.overwriteOriginalFiles(tempDB)
+		err = c.overwriteOriginalFiles(ctx, c.anchorDB, options)
+	} else {
+		err = dumpSQLiteDatabase(ctx, c.anchorDB, outputDir, options)
 	}
+	if err != nil {
+		return c.autoSaveError(err)
+	}
+	c.periodic.rowsSaved(pendingRows)
+	c.changed.saved(generation)
+	return nil
+}
 
-	// Use the configured DumpOptions directly
-	dumpOptions := c.autoSaveConfig.options
-
-	// Use the existing DumpDatabase method
-	return DumpDatabase(tempDB, outputDir, dumpOptions)
+// autoSaveError marks errors caused by the auto-save timeout with ErrAutoSaveTimeout
+func (c *autoSaveConnector) autoSaveError(err error) error {
+	if err != nil && c.autoSaveConfig.timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s: %w", ErrAutoSaveTimeout, c.autoSaveConfig.timeout, err)
+	}
+	return err
 }
 
-// overwriteOriginalFiles saves each table back to its original file location
-func (c *autoSaveConnection) overwriteOriginalFiles(db *sql.DB) error {
-	if len(c.originalPaths) == 0 {
-		return errors.New("no original paths available for overwrite")
+// overwriteOriginalFiles saves each table back to its original file location.
+// Tables loaded from writable filesystems are written back into them.
+func (c *autoSaveConnector) overwriteOriginalFiles(ctx context.Context, db *sql.DB, options DumpOptions) error {
+	for _, target := range c.writeBack {
+		// The cause tells an expired auto-save timeout apart from a cancellation
+		if err := context.Cause(ctx); err != nil {
+			return err
+		}
+		if err := target.writeBack(ctx, db, options); err != nil {
+			return err
+		}
 	}
 
 	// For now, use the first original path's directory as output
 	// This is a simplified implementation
 	if len(c.originalPaths) > 0 {
 		outputDir := filepath.Dir(c.originalPaths[0])
-		return DumpDatabase(db, outputDir, c.autoSaveConfig.options)
+		return dumpSQLiteDatabase(ctx, db, outputDir, excludeWriteBackTables(options, c.writeBack))
 	}
 
 	return nil
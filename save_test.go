@@ -1,9 +1,16 @@
 package filesql
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOutputFormat_String(t *testing.T) {
@@ -348,3 +355,207 @@ func TestDumpOptions_ChainedMethods(t *testing.T) {
 	got := options.FileExtension()
 	assert.Equal(t, expectedExt, got, "Chained options FileExtension() should work")
 }
+
+func TestDBBuilder_SetAutoSaveTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("expired timeout aborts the save on close", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outputDir := t.TempDir()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "a", FileTypeCSV).
+			AddReader(strings.NewReader("id\n2\n"), "b", FileTypeCSV).
+			EnableAutoSave(outputDir).
+			SetAutoSaveTimeout(time.Nanosecond).
+			Build(ctx)
+		require.NoError(t, err)
+
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+
+		err = db.Close()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrAutoSaveTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, ErrCodeSaveTimeout, ErrorCodeOf(err))
+
+		entries, err := os.ReadDir(outputDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("save within the timeout succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outputDir := t.TempDir()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "a", FileTypeCSV).
+			EnableAutoSave(outputDir).
+			SetAutoSaveTimeout(time.Minute).
+			Build(ctx)
+		require.NoError(t, err)
+
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+		assert.FileExists(t, filepath.Join(outputDir, "a.csv"))
+	})
+}
+
+func TestDumpSQLiteDatabase_Interrupted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	validatedBuilder, err := NewBuilder().
+		AddReader(strings.NewReader("id\n1\n"), "a", FileTypeCSV).
+		AddReader(strings.NewReader("id\n2\n"), "b", FileTypeCSV).
+		Build(ctx)
+	require.NoError(t, err)
+	db, err := validatedBuilder.Open(ctx)
+	require.NoError(t, err)
+	defer db.Close()
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	outputDir := t.TempDir()
+	err = dumpSQLiteDatabase(cancelled, db, outputDir, NewDumpOptions())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Contains(t, err.Error(), "dump interrupted after 0 of 2 tables")
+	assert.NoFileExists(t, filepath.Join(outputDir, "a.csv"))
+}
+
+func TestAutoSave_SchemaChanges(t *testing.T) {
+	t.Parallel()
+
+	readOutput := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path) //nolint:gosec // Test file path is safe
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("schema changes outside transactions are saved on commit timing", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outputDir := t.TempDir()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id,name\n1,alice\n"), "users", FileTypeCSV).
+			EnableAutoSaveOnCommit(outputDir).
+			Build(ctx)
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+		defer db.Close()
+
+		_, err = db.ExecContext(ctx, "ALTER TABLE users ADD COLUMN city TEXT")
+		require.NoError(t, err)
+		assert.Equal(t, "id,name,city\n1,alice,\n", readOutput(t, filepath.Join(outputDir, "users.csv")))
+
+		_, err = db.ExecContext(ctx, "CREATE TABLE notes (id INTEGER, body TEXT)")
+		require.NoError(t, err)
+		assert.Equal(t, "id,body\n", readOutput(t, filepath.Join(outputDir, "notes.csv")))
+
+		// Data changes outside transactions keep waiting for the next commit
+		_, err = db.ExecContext(ctx, "INSERT INTO notes VALUES (1, 'hello')")
+		require.NoError(t, err)
+		assert.Equal(t, "id,body\n", readOutput(t, filepath.Join(outputDir, "notes.csv")))
+	})
+
+	t.Run("schema changes inside a transaction are saved when it commits", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outputDir := t.TempDir()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+			EnableAutoSaveOnCommit(outputDir).
+			Build(ctx)
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+		defer db.Close()
+
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(ctx, "CREATE TABLE notes (body TEXT)")
+		require.NoError(t, err)
+		_, err = tx.ExecContext(ctx, "INSERT INTO notes VALUES ('hello')")
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(outputDir, "notes.csv"))
+
+		require.NoError(t, tx.Commit())
+		assert.Equal(t, "body\nhello\n", readOutput(t, filepath.Join(outputDir, "notes.csv")))
+
+		tx, err = db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(ctx, "CREATE TABLE drafts (body TEXT)")
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+		assert.NoFileExists(t, filepath.Join(outputDir, "drafts.csv"))
+	})
+
+	t.Run("schema changes after a BEGIN statement are not saved until the transaction ends", func(t *testing.T) {
+		t.Parallel()
+
+		// A save inside the open transaction would wait for its locks forever
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		outputDir := t.TempDir()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+			EnableAutoSaveOnCommit(outputDir).
+			Build(ctx)
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+		defer db.Close()
+		conn, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.ExecContext(ctx, "BEGIN; CREATE TABLE notes (body TEXT)")
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(outputDir, "notes.csv"))
+		_, err = conn.ExecContext(ctx, "ROLLBACK")
+		require.NoError(t, err)
+
+		// Once the transaction ended, schema changes are saved again
+		_, err = conn.ExecContext(ctx, "CREATE TABLE drafts (body TEXT)")
+		require.NoError(t, err)
+		assert.Equal(t, "body\n", readOutput(t, filepath.Join(outputDir, "drafts.csv")))
+		assert.NoFileExists(t, filepath.Join(outputDir, "notes.csv"))
+
+		_, err = db.ExecContext(ctx, "BEGIN; CREATE TABLE memos (body TEXT)")
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(outputDir, "memos.csv"))
+	})
+
+	t.Run("new tables and columns are saved on close", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outputDir := t.TempDir()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+			EnableAutoSave(outputDir).
+			Build(ctx)
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, "ALTER TABLE users ADD COLUMN name TEXT DEFAULT 'unknown'")
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, "CREATE TABLE notes AS SELECT id, name FROM users")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		assert.Equal(t, "id,name\n1,unknown\n", readOutput(t, filepath.Join(outputDir, "users.csv")))
+		assert.Equal(t, "id,name\n1,unknown\n", readOutput(t, filepath.Join(outputDir, "notes.csv")))
+	})
+}
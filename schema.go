@@ -0,0 +1,235 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DatabaseSchema describes the tables and columns of a database.
+// It is the format written by ExportSchema and read by CheckSchema.
+type DatabaseSchema struct {
+	// Tables contains every user table, sorted by name
+	Tables []TableSchema `json:"tables"`
+}
+
+// TableSchema describes a single table.
+type TableSchema struct {
+	// Name is the table name
+	Name string `json:"name"`
+	// Columns contains the table columns in declaration order
+	Columns []ColumnSchema `json:"columns"`
+}
+
+// ColumnSchema describes a single column.
+type ColumnSchema struct {
+	// Name is the column name
+	Name string `json:"name"`
+	// Type is the declared SQLite column type (e.g. TEXT, INTEGER, REAL)
+	Type string `json:"type"`
+}
+
+// SchemaDrift is the structured difference between a baseline schema and the current schema.
+type SchemaDrift struct {
+	// AddedTables lists tables that exist now but not in the baseline
+	AddedTables []string `json:"added_tables"`
+	// RemovedTables lists tables that exist in the baseline but not now
+	RemovedTables []string `json:"removed_tables"`
+	// AddedColumns lists columns that exist now but not in the baseline
+	AddedColumns []ColumnDrift `json:"added_columns"`
+	// RemovedColumns lists columns that exist in the baseline but not now
+	RemovedColumns []ColumnDrift `json:"removed_columns"`
+	// RetypedColumns lists columns whose type differs from the baseline
+	RetypedColumns []ColumnDrift `json:"retyped_columns"`
+}
+
+// ColumnDrift describes a single column-level change.
+type ColumnDrift struct {
+	// Table is the table containing the column
+	Table string `json:"table"`
+	// Column is the column name
+	Column string `json:"column"`
+	// BaselineType is the column type in the baseline (empty for added columns)
+	BaselineType string `json:"baseline_type"`
+	// CurrentType is the current column type (empty for removed columns)
+	CurrentType string `json:"current_type"`
+}
+
+// HasDrift reports whether any difference was found.
+func (d *SchemaDrift) HasDrift() bool {
+	return len(d.AddedTables) > 0 || len(d.RemovedTables) > 0 ||
+		len(d.AddedColumns) > 0 || len(d.RemovedColumns) > 0 || len(d.RetypedColumns) > 0
+}
+
+// GetSchema returns the schema of all user tables in db.
+func GetSchema(db *sql.DB) (*DatabaseSchema, error) {
+	tableNames, err := getSQLiteTableNames(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table names: %w", err)
+	}
+	sort.Strings(tableNames)
+
+	schema := &DatabaseSchema{Tables: make([]TableSchema, 0, len(tableNames))}
+	for _, tableName := range tableNames {
+		columns, err := getSQLiteColumnSchemas(db, tableName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get columns for table %s: %w", tableName, err)
+		}
+		schema.Tables = append(schema.Tables, TableSchema{Name: tableName, Columns: columns})
+	}
+	return schema, nil
+}
+
+// ExportSchema writes the schema of all user tables in db to path as JSON.
+// The file can later be used as the baseline for CheckSchema.
+//
+// Example:
+//
+//	// First run: record the expected schema
+//	err := filesql.ExportSchema(db, "schema.json")
+func ExportSchema(db *sql.DB, path string) error {
+	schema, err := GetSchema(db)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create schema directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write schema file %s: %w", path, err)
+	}
+	return nil
+}
+
+// CheckSchema compares the current schema of db against a baseline written by ExportSchema.
+//
+// Use it in scheduled pipelines to detect upstream format changes:
+//
+//	drift, err := filesql.CheckSchema(db, "schema.json")
+//	if err != nil {
+//		return err
+//	}
+//	if drift.HasDrift() {
+//		return fmt.Errorf("upstream schema changed: %+v", drift)
+//	}
+func CheckSchema(db *sql.DB, baselinePath string) (*SchemaDrift, error) {
+	data, err := os.ReadFile(baselinePath) //nolint:gosec // Baseline path is provided by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema baseline %s: %w", baselinePath, err)
+	}
+
+	var baseline DatabaseSchema
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to decode schema baseline %s: %w", baselinePath, err)
+	}
+
+	current, err := GetSchema(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareSchemas(&baseline, current), nil
+}
+
+// compareSchemas returns the drift from baseline to current
+func compareSchemas(baseline, current *DatabaseSchema) *SchemaDrift {
+	drift := &SchemaDrift{}
+
+	baselineTables := make(map[string]TableSchema, len(baseline.Tables))
+	for _, table := range baseline.Tables {
+		baselineTables[table.Name] = table
+	}
+	currentTables := make(map[string]TableSchema, len(current.Tables))
+	for _, table := range current.Tables {
+		currentTables[table.Name] = table
+	}
+
+	for _, table := range baseline.Tables {
+		if _, ok := currentTables[table.Name]; !ok {
+			drift.RemovedTables = append(drift.RemovedTables, table.Name)
+		}
+	}
+
+	for _, table := range current.Tables {
+		baseTable, ok := baselineTables[table.Name]
+		if !ok {
+			drift.AddedTables = append(drift.AddedTables, table.Name)
+			continue
+		}
+
+		baseColumns := make(map[string]string, len(baseTable.Columns))
+		for _, col := range baseTable.Columns {
+			baseColumns[col.Name] = col.Type
+		}
+		currentColumns := make(map[string]bool, len(table.Columns))
+
+		for _, col := range table.Columns {
+			currentColumns[col.Name] = true
+			baseType, ok := baseColumns[col.Name]
+			switch {
+			case !ok:
+				drift.AddedColumns = append(drift.AddedColumns, ColumnDrift{
+					Table: table.Name, Column: col.Name, CurrentType: col.Type,
+				})
+			case baseType != col.Type:
+				drift.RetypedColumns = append(drift.RetypedColumns, ColumnDrift{
+					Table: table.Name, Column: col.Name, BaselineType: baseType, CurrentType: col.Type,
+				})
+			}
+		}
+
+		for _, col := range baseTable.Columns {
+			if !currentColumns[col.Name] {
+				drift.RemovedColumns = append(drift.RemovedColumns, ColumnDrift{
+					Table: table.Name, Column: col.Name, BaselineType: col.Type,
+				})
+			}
+		}
+	}
+
+	sort.Strings(drift.RemovedTables)
+	return drift
+}
+
+// getSQLiteColumnSchemas retrieves column names and declared types for a specific table
+func getSQLiteColumnSchemas(db *sql.DB, tableName string) ([]ColumnSchema, error) {
+	ctx := context.Background()
+	query := fmt.Sprintf("PRAGMA table_info(`%s`)", tableName)
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []ColumnSchema
+	for rows.Next() {
+		var cid int
+		var name, dataType string
+		var notNull, dfltValue, pk any
+
+		if err := rows.Scan(&cid, &name, &dataType, &notNull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		columns = append(columns, ColumnSchema{Name: name, Type: dataType})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("table has no columns")
+	}
+
+	return columns, nil
+}
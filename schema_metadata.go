@@ -0,0 +1,348 @@
+package filesql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// schemaMetadataTableName is the metadata table that stores where every column was loaded from
+const schemaMetadataTableName = "_filesql_schema"
+
+// schemaCommentPrefix starts every line of the schema comments of dumped CSV and TSV files
+const schemaCommentPrefix = "-- "
+
+// schemaCommentStart is the first line of the schema comments, which the CSV and TSV
+// loaders look for to skip the comments of dumped files
+const schemaCommentStart = schemaCommentPrefix + "table: "
+
+// schemaCommentSource and schemaCommentColumn start the lines that follow schemaCommentStart
+const (
+	schemaCommentSource = schemaCommentPrefix + "source: "
+	schemaCommentColumn = schemaCommentPrefix + "column: "
+)
+
+// ColumnMetadata describes where a loaded column came from.
+type ColumnMetadata struct {
+	// Table is the table name
+	Table string `json:"table"`
+	// Column is the column name
+	Column string `json:"column"`
+	// Header is the header of the column in the input before header mapping; empty
+	// for inputs without a header row
+	Header string `json:"header"`
+	// Type is the column type inferred when the column was loaded
+	Type string `json:"type"`
+	// Source is the file path, or the table name for reader inputs, the table was loaded from
+	Source string `json:"source"`
+}
+
+// EnableSchemaMetadata records the source file, original header, and inferred type
+// of every loaded column.
+//
+// The metadata is stored in the "_filesql_schema" table, which is excluded from
+// DumpDatabase and auto-save, and is read with GetSchemaMetadata. Dumps written with
+// DumpOptions.WithSchemaComments include it, so exported files describe themselves.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("users.csv").
+//		WithHeaderMapping(map[string]string{"User Name": "name"}).
+//		EnableSchemaMetadata()
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableSchemaMetadata() *DBBuilder {
+	b.schemaMetadata = true
+	return b
+}
+
+// GetSchemaMetadata returns the metadata of the columns loaded into db, in load order.
+//
+// The metadata is recorded once while inputs are loaded by Open or StartLoad with
+// EnableSchemaMetadata; without it, the result is empty. Tables and columns created
+// by SQL statements afterwards have no metadata.
+//
+// Example:
+//
+//	columns, err := filesql.GetSchemaMetadata(db)
+//	if err != nil {
+//		return err
+//	}
+//	for _, column := range columns {
+//		fmt.Printf("%s.%s %s from %q in %s\n", column.Table, column.Column, column.Type, column.Header, column.Source)
+//	}
+func GetSchemaMetadata(db *sql.DB) ([]ColumnMetadata, error) {
+	return readSchemaMetadata(context.Background(), db, "")
+}
+
+// readSchemaMetadata reads the column metadata of table, or of every table if table is empty
+func readSchemaMetadata(ctx context.Context, db *sql.DB, table string) ([]ColumnMetadata, error) {
+	columns := make([]ColumnMetadata, 0)
+
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+		schemaMetadataTableName,
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check schema metadata table: %w", err)
+	}
+	if exists == 0 {
+		return columns, nil
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`SELECT table_name, column_name, header, type, source FROM "%s" WHERE ? = '' OR table_name = ? COLLATE NOCASE ORDER BY rowid`,
+		schemaMetadataTableName,
+	), table, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema metadata: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column ColumnMetadata
+		if err := rows.Scan(&column.Table, &column.Column, &column.Header, &column.Type, &column.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan schema metadata: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema metadata: %w", err)
+	}
+	return columns, nil
+}
+
+// loadedTable is a table and the input it was loaded from
+type loadedTable struct {
+	table string
+	input string
+}
+
+// schemaMetadataRecorder is a loadObserver that records the tables loaded from every input.
+// It forwards every notification to next, so it can be combined with other observers.
+type schemaMetadataRecorder struct {
+	next loadObserver
+	// headerMapping renames source column names, so its inverse gives the original headers
+	headerMapping map[string]string
+	// headerless reports whether an input has no header row
+	headerless func(input string) bool
+	tables     []loadedTable
+	input      string
+}
+
+// newSchemaMetadataRecorder creates a recorder that forwards notifications to next (may be nil)
+func newSchemaMetadataRecorder(next loadObserver, headerMapping map[string]string, headerless func(input string) bool) *schemaMetadataRecorder {
+	return &schemaMetadataRecorder{next: next, headerMapping: headerMapping, headerless: headerless}
+}
+
+// inputStarted implements loadObserver
+func (r *schemaMetadataRecorder) inputStarted(input string) {
+	r.input = input
+	if r.next != nil {
+		r.next.inputStarted(input)
+	}
+}
+
+// bytesRead implements loadObserver
+func (r *schemaMetadataRecorder) bytesRead(n int) {
+	if r.next != nil {
+		r.next.bytesRead(n)
+	}
+}
+
+// rowsInserted implements loadObserver
+func (r *schemaMetadataRecorder) rowsInserted(table string, rows int) {
+	if r.next != nil {
+		r.next.rowsInserted(table, rows)
+	}
+}
+
+// tableLoaded implements loadObserver
+func (r *schemaMetadataRecorder) tableLoaded(table string) {
+	r.tables = append(r.tables, loadedTable{table: table, input: r.input})
+	if r.next != nil {
+		r.next.tableLoaded(table)
+	}
+}
+
+// inputFinished implements loadObserver
+func (r *schemaMetadataRecorder) inputFinished(input string) {
+	if r.next != nil {
+		r.next.inputFinished(input)
+	}
+}
+
+// header returns the header a column was loaded from
+func (r *schemaMetadataRecorder) header(input, column string) string {
+	if r.headerless(input) {
+		return ""
+	}
+	// Several headers may be mapped to the same column; the alphabetically first is reported
+	header := column
+	for from, to := range r.headerMapping {
+		if to == column && (header == column || from < header) {
+			header = from
+		}
+	}
+	return header
+}
+
+// save stores the metadata of the columns of the recorded tables in the schema metadata table
+func (r *schemaMetadataRecorder) save(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (table_name TEXT, column_name TEXT, header TEXT, type TEXT, source TEXT)`,
+		schemaMetadataTableName,
+	)); err != nil {
+		return fmt.Errorf("failed to create schema metadata table: %w", err)
+	}
+
+	insertQuery := fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`INSERT INTO "%s" (table_name, column_name, header, type, source) VALUES (?, ?, ?, ?, ?)`,
+		schemaMetadataTableName,
+	)
+	for _, t := range r.tables {
+		columns, err := tableColumnTypes(ctx, db, t.table)
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			if _, err := db.ExecContext(ctx, insertQuery,
+				t.table, column.name, r.header(t.input, column.name), column.declType, t.input); err != nil {
+				return fmt.Errorf("failed to record schema metadata: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// headerlessInputs returns whether the file path or reader input named input has no header row
+func (b *DBBuilder) headerlessInputs() func(input string) bool {
+	headerless := make(map[string]bool)
+	for path, options := range b.streamProcessor.fileOptions {
+		headerless[path] = options.headerless()
+	}
+	for _, input := range b.readers {
+		headerless[input.tableName] = input.options.headerless()
+	}
+	return func(input string) bool {
+		return headerless[input]
+	}
+}
+
+// WithSchemaComments starts every CSV and TSV file with comment lines describing
+// the table: its name, the file it was loaded from, and the type and original header
+// of every column.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().WithSchemaComments()
+//	err := filesql.DumpDatabase(db, "./output", options)
+//
+// writes users.csv as
+//
+//	-- table: users
+//	-- source: data/users.csv
+//	-- column: id INTEGER
+//	-- column: name TEXT (header: User Name)
+//	id,name
+//	1,alice
+//
+// The source and headers are known for tables loaded with EnableSchemaMetadata only;
+// other tables list their columns and types. When a CSV or TSV file starts with a
+// "-- table: " line, filesql skips it and the "-- source: " and "-- column: " lines
+// that follow it while loading the file, so dumped files can be loaded again; any
+// other line, such as a data row starting with "-- ", ends the comments. Other
+// formats are written without comments.
+func (o DumpOptions) WithSchemaComments() DumpOptions {
+	o.SchemaComments = true
+	return o
+}
+
+// withSchemaCommentLines returns the options with the schema comments of a table
+// whose data columns are columns, if the format writes them
+func (o DumpOptions) withSchemaCommentLines(ctx context.Context, db *sql.DB, tableName string, columns []string) (DumpOptions, error) {
+	if !o.SchemaComments || (o.Format != OutputFormatCSV && o.Format != OutputFormatTSV) {
+		return o, nil
+	}
+	types, err := tableColumnTypes(ctx, db, tableName)
+	if err != nil {
+		return o, err
+	}
+	metadata, err := readSchemaMetadata(ctx, db, tableName)
+	if err != nil {
+		return o, err
+	}
+
+	lines := []string{schemaCommentStart + tableName}
+	if len(metadata) > 0 {
+		lines = append(lines, schemaCommentSource+metadata[0].Source)
+	}
+	for _, column := range columns {
+		line := schemaCommentColumn + column
+		if t, ok := findDeclaredColumn(types, column); ok && t.declType != "" {
+			line += " " + t.declType
+		}
+		for _, m := range metadata {
+			if m.Column == column && m.Header != "" && m.Header != column {
+				line += " (header: " + m.Header + ")"
+			}
+		}
+		lines = append(lines, line)
+	}
+	// A line break in a name would end the comment and start a data row
+	for i, line := range lines {
+		lines[i] = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(line)
+	}
+	o.schemaComments = lines
+	return o, nil
+}
+
+// writeSchemaComments writes the schema comment lines, if any, to writer
+func writeSchemaComments(writer io.Writer, lines []string) error {
+	for _, line := range lines {
+		if _, err := io.WriteString(writer, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipSchemaComments skips the schema comments at the start of reader, if any, and
+// returns the reader of the rest of the data and the number of bytes skipped. Only
+// the lines written by WithSchemaComments are skipped, and only after a table line.
+func skipSchemaComments(reader io.Reader) (io.Reader, int64, error) {
+	buffered, ok := reader.(*bufio.Reader)
+	if !ok {
+		buffered = bufio.NewReader(reader)
+	}
+
+	var skipped int64
+	for {
+		// The source and column prefixes have the same length, longer than the table prefix
+		peeked, err := buffered.Peek(len(schemaCommentColumn))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, 0, err
+		}
+		prefix := string(peeked)
+		isComment := strings.HasPrefix(prefix, schemaCommentStart)
+		if skipped > 0 {
+			isComment = prefix == schemaCommentSource || prefix == schemaCommentColumn
+		}
+		if !isComment {
+			return buffered, skipped, nil
+		}
+		line, err := buffered.ReadString('\n')
+		skipped += int64(len(line))
+		if errors.Is(err, io.EOF) {
+			return buffered, skipped, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaMetadata(t *testing.T) {
+	t.Parallel()
+
+	t.Run("columns are recorded with their source, header, and type", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		usersPath := filepath.Join(dir, "users.csv")
+		require.NoError(t, os.WriteFile(usersPath, []byte("id,User Name\n1,alice\n"), 0600))
+
+		db := openTestDB(t, NewBuilder().
+			AddPath(usersPath).
+			AddReaderWithOptions(strings.NewReader("1.5,x\n"), "samples", FileTypeCSV, WithNoHeader()).
+			WithHeaderMapping(map[string]string{"User Name": "name"}).
+			EnableSchemaMetadata())
+
+		columns, err := GetSchemaMetadata(db)
+		require.NoError(t, err)
+		assert.Equal(t, []ColumnMetadata{
+			{Table: "users", Column: "id", Header: "id", Type: "INTEGER", Source: usersPath},
+			{Table: "users", Column: "name", Header: "User Name", Type: "TEXT", Source: usersPath},
+			{Table: "samples", Column: "column1", Header: "", Type: "REAL", Source: "samples"},
+			{Table: "samples", Column: "column2", Header: "", Type: "TEXT", Source: "samples"},
+		}, columns)
+	})
+
+	t.Run("nothing is recorded by default", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV))
+		columns, err := GetSchemaMetadata(db)
+		require.NoError(t, err)
+		assert.Empty(t, columns)
+	})
+}
+
+func TestDumpOptions_WithSchemaComments(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dumped files describe their schema and load again", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,User Name\n1,alice\n2,bob\n"), "users", FileTypeCSV).
+			WithHeaderMapping(map[string]string{"User Name": "name"}).
+			EnableSchemaMetadata())
+		_, err := db.ExecContext(context.Background(), "CREATE TABLE notes AS SELECT name AS memo FROM users")
+		require.NoError(t, err)
+
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir, NewDumpOptions().WithSchemaComments()))
+
+		users, err := os.ReadFile(filepath.Join(outputDir, "users.csv")) //nolint:gosec // The path is created by the test
+		require.NoError(t, err)
+		assert.Equal(t, "-- table: users\n-- source: users\n-- column: id INTEGER\n-- column: name TEXT (header: User Name)\n"+
+			"id,name\n1,alice\n2,bob\n", string(users))
+		notes, err := os.ReadFile(filepath.Join(outputDir, "notes.csv")) //nolint:gosec // The path is created by the test
+		require.NoError(t, err)
+		assert.Equal(t, "-- table: notes\n-- column: memo TEXT\nmemo\nalice\nbob\n", string(notes), "tables without metadata list their types")
+
+		reloaded := openTestDB(t, NewBuilder().AddPath(filepath.Join(outputDir, "users.csv")))
+		var count int
+		var name string
+		require.NoError(t, reloaded.QueryRowContext(context.Background(), "SELECT COUNT(*), MAX(name) FROM users").Scan(&count, &name))
+		assert.Equal(t, 2, count)
+		assert.Equal(t, "bob", name)
+	})
+
+	formatTests := []struct {
+		format OutputFormat
+		file   string
+		want   string
+	}{
+		{format: OutputFormatTSV, file: "users.tsv", want: "-- table: users\n-- column: id INTEGER\nid\n1\n"},
+		{format: OutputFormatJSONL, file: "users.jsonl", want: "{\"id\":1}\n"},
+	}
+
+	for _, tt := range formatTests {
+		t.Run("comments of "+tt.file, func(t *testing.T) {
+			t.Parallel()
+
+			db := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV))
+			outputDir := t.TempDir()
+			require.NoError(t, DumpDatabase(db, outputDir, NewDumpOptions().WithSchemaComments().WithFormat(tt.format)))
+
+			data, err := os.ReadFile(filepath.Join(outputDir, tt.file)) //nolint:gosec // The path is created by the test
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+
+	t.Run("sidecar indexes skip the comments", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "events.csv")
+		require.NoError(t, os.WriteFile(path, []byte("-- table: events\n-- column: id INTEGER\nid\n1\n2\n3\n"), 0600))
+
+		for range 2 { // The first open writes the index, the second one reads it
+			db := openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex().SetDefaultChunkSize(2))
+			var ids string
+			require.NoError(t, db.QueryRowContext(context.Background(), "SELECT GROUP_CONCAT(id) FROM events").Scan(&ids))
+			assert.Equal(t, "1,2,3", ids)
+		}
+	})
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "comments are only skipped after a table line",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().AddReader(strings.NewReader("-- note\n1\n"), "notes", FileTypeCSV)
+			},
+			want: map[string][]string{`SELECT "-- note" FROM notes`: {"1"}},
+		},
+		{
+			name: "only the lines of schema comments are skipped",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().AddReader(
+					strings.NewReader("-- table: notes\n-- column: memo TEXT\n-- memo\n-- first\n-- source: second\n"), "notes", FileTypeCSV)
+			},
+			want: map[string][]string{`SELECT "-- memo" FROM notes ORDER BY rowid`: {"-- first", "-- source: second"}},
+		},
+	})
+}
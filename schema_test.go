@@ -0,0 +1,97 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSchema(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no drift against own baseline", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+		baseline := filepath.Join(t.TempDir(), "schema", "baseline.json")
+		require.NoError(t, ExportSchema(db, baseline))
+
+		drift, err := CheckSchema(db, baseline)
+		require.NoError(t, err)
+		assert.False(t, drift.HasDrift())
+	})
+
+	t.Run("detects added, removed and retyped columns and tables", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,name,age\n1,Alice,30\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id,amount\n1,100\n"), "orders", FileTypeCSV))
+
+		baseline := filepath.Join(t.TempDir(), "baseline.json")
+		require.NoError(t, ExportSchema(db, baseline))
+
+		newDB := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,name,age,email\nA1,Alice,30,a@example.com\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("sku\nX\n"), "products", FileTypeCSV))
+
+		drift, err := CheckSchema(newDB, baseline)
+		require.NoError(t, err)
+		assert.True(t, drift.HasDrift())
+		assert.Equal(t, []string{"products"}, drift.AddedTables)
+		assert.Equal(t, []string{"orders"}, drift.RemovedTables)
+		assert.Equal(t, []ColumnDrift{{Table: "users", Column: "email", CurrentType: "TEXT"}}, drift.AddedColumns)
+		assert.Empty(t, drift.RemovedColumns)
+		assert.Equal(t, []ColumnDrift{{Table: "users", Column: "id", BaselineType: "INTEGER", CurrentType: "TEXT"}}, drift.RetypedColumns)
+	})
+
+	errorTests := []struct {
+		name string
+		// baseline returns the path of the baseline
+		baseline func(t *testing.T) string
+	}{
+		{
+			name: "missing baseline returns error",
+			baseline: func(t *testing.T) string {
+				return filepath.Join(t.TempDir(), "missing.json")
+			},
+		},
+		{
+			name: "invalid baseline returns error",
+			baseline: func(t *testing.T) string {
+				baseline := filepath.Join(t.TempDir(), "broken.json")
+				require.NoError(t, os.WriteFile(baseline, []byte("{not json"), 0600))
+				return baseline
+			},
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+			_, err := CheckSchema(db, tt.baseline(t))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCompareSchemas_RemovedColumn(t *testing.T) {
+	t.Parallel()
+
+	baseline := &DatabaseSchema{Tables: []TableSchema{
+		{Name: "t", Columns: []ColumnSchema{{Name: "a", Type: "TEXT"}, {Name: "b", Type: "REAL"}}},
+	}}
+	current := &DatabaseSchema{Tables: []TableSchema{
+		{Name: "t", Columns: []ColumnSchema{{Name: "a", Type: "TEXT"}}},
+	}}
+
+	drift := compareSchemas(baseline, current)
+	assert.Equal(t, []ColumnDrift{{Table: "t", Column: "b", BaselineType: "REAL"}}, drift.RemovedColumns)
+	assert.True(t, drift.HasDrift())
+}
@@ -0,0 +1,53 @@
+package filesql
+
+import (
+	"slices"
+	"strings"
+)
+
+// WithTables exports only the named tables instead of every table of the database.
+//
+// After loading a directory of many files and modifying a few of them, export just
+// the modified tables to save time and disk. Table names are compared
+// case-insensitively, like SQLite table names. Calling it again adds more tables.
+// Tables excluded with WithExcludedTables are not exported even if they are named.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().WithTables("users", "orders")
+//	err := filesql.DumpDatabase(db, "./output", options)
+//
+// DumpDatabase fails with ErrCodeNoTables if a named table is not in the database,
+// so a misspelled name is not silently skipped.
+func (o DumpOptions) WithTables(tables ...string) DumpOptions {
+	// Copy the slice so that options derived from the same base do not share it
+	selected := make([]string, 0, len(o.Tables)+len(tables))
+	selected = append(selected, o.Tables...)
+	selected = append(selected, tables...)
+	o.Tables = selected
+	return o
+}
+
+// selectsTable reports whether the table is exported, which is every table unless
+// tables were named with WithTables or auto-save writes only changed tables
+func (o DumpOptions) selectsTable(tableName string) bool {
+	if o.changed != nil && !o.changed[strings.ToLower(tableName)] {
+		return false
+	}
+	return len(o.Tables) == 0 || slices.ContainsFunc(o.Tables, func(name string) bool {
+		return strings.EqualFold(name, tableName)
+	})
+}
+
+// missingTable returns the first table named with WithTables that is not one of
+// tableNames
+func (o DumpOptions) missingTable(tableNames []string) (string, bool) {
+	for _, name := range o.Tables {
+		if !slices.ContainsFunc(tableNames, func(tableName string) bool {
+			return strings.EqualFold(name, tableName)
+		}) {
+			return name, true
+		}
+	}
+	return "", false
+}
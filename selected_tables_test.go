@@ -0,0 +1,88 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOptions_WithTables(t *testing.T) {
+	t.Parallel()
+
+	// openTables opens a directory with users, orders, and countries
+	openTables := func(t *testing.T) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id\n1\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv"), []byte("id\n1\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "countries.csv"), []byte("code\njp\n"), 0600))
+		return dir
+	}
+	tests := []struct {
+		name    string
+		options DumpOptions
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "only the named tables are exported",
+			options: NewDumpOptions().WithTables("USERS").WithTables("orders"),
+			want:    []string{"orders.csv", "users.csv"},
+		},
+		{
+			name:    "excluded tables are not exported even if named",
+			options: NewDumpOptions().WithTables("users", "orders").WithExcludedTables("ord%"),
+			want:    []string{"users.csv"},
+		},
+		{
+			name:    "unknown tables are an error",
+			options: NewDumpOptions().WithTables("users", "oders"),
+			wantErr: `"oders"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := openTestDB(t, NewBuilder().AddPath(openTables(t)))
+			output := t.TempDir()
+			err := DumpDatabase(db, output, tt.options)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, ErrCodeNoTables, ErrorCodeOf(err))
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			entries, err := os.ReadDir(output)
+			require.NoError(t, err)
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+
+	t.Run("derived options do not share tables", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewDumpOptions().WithTables("USERS")
+		_ = base.WithTables("orders")
+		assert.Equal(t, []string{"USERS"}, base.Tables)
+	})
+
+	t.Run("configuration", func(t *testing.T) {
+		t.Parallel()
+
+		options, err := AutoSaveSettings{Tables: []string{"users"}}.dumpOptions()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"users"}, options.Tables)
+	})
+}
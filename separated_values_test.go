@@ -0,0 +1,85 @@
+package filesql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeparatedValuesFiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("file types are detected from the extension", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			path string
+			want FileType
+			ext  string
+		}{
+			{path: "prices.ssv", want: FileTypeSSV, ext: ".ssv"},
+			{path: "prices.SSV", want: FileTypeSSV, ext: ".ssv"},
+			{path: "prices.ssv.gz", want: FileTypeSSVGZ, ext: ".ssv.gz"},
+			{path: "prices.ssv.br", want: FileTypeSSVBR, ext: ".ssv.br"},
+			{path: "orders.psv", want: FileTypePSV, ext: ".psv"},
+			{path: "orders.psv.zst", want: FileTypePSVZSTD, ext: ".psv.zst"},
+			{path: "orders.psv.lz4", want: FileTypePSVLZ4, ext: ".psv.lz4"},
+		}
+		for _, tt := range tests {
+			fileType := detectFileType(tt.path)
+			assert.Equal(t, tt.want, fileType, tt.path)
+			assert.True(t, isSupportedFile(tt.path), tt.path)
+			assert.Equal(t, tt.ext, fileType.extension(), tt.path)
+		}
+		assert.Equal(t, FileTypeSSV, FileTypeSSVXZ.baseType())
+		assert.Equal(t, FileTypePSV, FileTypePSVBZ2.baseType())
+	})
+
+	t.Run("files are split on semicolons and pipes", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "prices.ssv"), []byte("id;price\n1;2,5\n2;3,0\n"), 0600))
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		_, err := gz.Write([]byte("id|note\n1|a,b\n"))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.psv.gz"), compressed.Bytes(), 0600))
+
+		db := openTestDB(t, NewBuilder().
+			AddPath(dir).
+			AddReader(strings.NewReader("code;name\njp;Japan\n"), "countries", FileTypeSSV))
+
+		var price, note, name string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT price FROM prices WHERE id = 1").Scan(&price))
+		assert.Equal(t, "2,5", price, "commas are not delimiters")
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT note FROM notes").Scan(&note))
+		assert.Equal(t, "a,b", note)
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT name FROM countries").Scan(&name))
+		assert.Equal(t, "Japan", name)
+	})
+
+	t.Run("tables are dumped with semicolons and pipes", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().AddReader(strings.NewReader("id,note\n1,a;b|c\n"), "notes", FileTypeCSV))
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir, NewDumpOptions().WithFormat(OutputFormatSSV)))
+		require.NoError(t, DumpDatabase(db, outputDir, NewDumpOptions().WithFormat(OutputFormatPSV)))
+
+		ssv, err := os.ReadFile(filepath.Join(outputDir, "notes.ssv")) //nolint:gosec // The path is created by the test
+		require.NoError(t, err)
+		assert.Equal(t, "id;note\n1;\"a;b|c\"\n", string(ssv))
+		psv, err := os.ReadFile(filepath.Join(outputDir, "notes.psv")) //nolint:gosec // The path is created by the test
+		require.NoError(t, err)
+		assert.Equal(t, "id|note\n1|\"a;b|c\"\n", string(psv))
+	})
+}
@@ -0,0 +1,411 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"modernc.org/sqlite"
+)
+
+// sessionCallFunction is the SQL function that calls the functions of a session.
+// Calls of session functions are rewritten to it with the session ID and the
+// function name as the first two arguments.
+const sessionCallFunction = "_filesql_session_call"
+
+// SessionFunction is a scalar SQL function registered with Session.RegisterFunction.
+//
+// args are the SQL arguments as int64, float64, string, []byte, or nil. The result
+// must be one of those types, a bool, or another integer or float type.
+type SessionFunction func(args []any) (any, error)
+
+// sessionRegistry is the registration of sessionCallFunction together with the open
+// sessions it calls, by ID.
+type sessionRegistry struct {
+	mu       sync.RWMutex
+	sessions map[int64]*Session
+	nextID   atomic.Int64
+}
+
+// sessionCalls is the only global state of sessions. The functions and views of a
+// session belong to the session and its connection; the registry only maps the IDs
+// that rewrite passes to sessionCallFunction back to the sessions.
+//
+// It is global because of the driver: modernc.org/sqlite has no API to create a
+// function on a single connection, so sessionCallFunction is registered on the
+// "sqlite" driver for every connection, and it is called without a handle to its
+// connection. The driver's registry is also not safe to change while connections are
+// opened, so the function is registered once, when the package is initialized.
+var sessionCalls = registerSessionCalls()
+
+// registerSessionCalls registers sessionCallFunction on the "sqlite" driver
+func registerSessionCalls() *sessionRegistry {
+	registry := &sessionRegistry{sessions: make(map[int64]*Session)}
+	sqlite.MustRegisterFunction(sessionCallFunction, &sqlite.FunctionImpl{
+		NArgs:  -1,
+		Scalar: registry.call,
+	})
+	return registry
+}
+
+// add registers session under a new ID and returns the ID
+func (r *sessionRegistry) add(session *Session) int64 {
+	id := r.nextID.Add(1)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[id] = session
+	return id
+}
+
+// remove unregisters the session with id
+func (r *sessionRegistry) remove(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+}
+
+// session returns the session with id, or nil if it is closed
+func (r *sessionRegistry) session(id int64) *Session {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sessions[id]
+}
+
+// Session is a connection of a database reserved for one client, with temporary
+// views and SQL functions that other sessions cannot see.
+//
+// All connections of a database opened by filesql share the same tables, so two
+// clients that define a helper view or function with the same name would overwrite
+// each other's. Objects declared through a session belong to that session only:
+// temporary views live on the session's own connection, and session functions are
+// only called by statements run through the session. Two sessions can define
+// different functions or views with the same name at the same time.
+//
+// A session is used by one goroutine at a time, like a *sql.Conn. Close it to drop
+// its temporary objects and return the connection to the pool.
+//
+// Example:
+//
+//	session, err := filesql.NewSession(ctx, db)
+//	if err != nil {
+//		return err
+//	}
+//	defer session.Close()
+//
+//	err = session.RegisterFunction("tenant_price", func(args []any) (any, error) {
+//		price, _ := args[0].(float64)
+//		return price * tenant.Markup, nil
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	err = session.CreateTempView(ctx, "priced", "SELECT name, tenant_price(price) AS price FROM products")
+//	if err != nil {
+//		return err
+//	}
+//	rows, err := session.QueryContext(ctx, "SELECT * FROM priced ORDER BY price")
+type Session struct {
+	// id identifies the session in calls of sessionCallFunction
+	id int64
+	// conn is the connection reserved for the session
+	conn *sql.Conn
+	// tempObjects are the temporary objects that existed before the session started
+	tempObjects map[string]bool
+	// mu guards functions, which statements of the session's connection call
+	mu sync.RWMutex
+	// functions maps lowercase function names to the functions of the session
+	functions map[string]SessionFunction
+}
+
+// NewSession reserves a connection of db for a new session.
+//
+// db must be opened by filesql (or use the "sqlite" driver of modernc.org/sqlite),
+// which provides session functions to its connections.
+func NewSession(ctx context.Context, db *sql.DB) (*Session, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve a connection for the session: %w", err)
+	}
+	tempObjects, err := listTempObjects(ctx, conn)
+	if err != nil {
+		_ = conn.Close() // Ignore close error during error handling
+		return nil, err
+	}
+
+	session := &Session{
+		conn:        conn,
+		tempObjects: tempObjects,
+		functions:   make(map[string]SessionFunction),
+	}
+	session.id = sessionCalls.add(session)
+	return session, nil
+}
+
+// RegisterFunction makes fn callable as the SQL function name in the statements and
+// temporary views of the session. Function names are case-insensitive; registering a
+// name again replaces the function, and a function named like a built-in SQLite
+// function replaces the built-in in the session's statements.
+//
+// Returns an error with ErrCodeInvalidConfig if name is not a valid identifier or fn is nil.
+func (s *Session) RegisterFunction(name string, fn SessionFunction) error {
+	if !isSessionFunctionName(name) {
+		return newCodedError(ErrCodeInvalidConfig, "invalid session function name: %q", name)
+	}
+	if fn == nil {
+		return newCodedError(ErrCodeInvalidConfig, "session function %s must not be nil", name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.functions[strings.ToLower(name)] = fn
+	return nil
+}
+
+// isSessionFunctionName reports whether name can be used for a session function: an
+// ASCII letter or underscore followed by ASCII letters, digits, or underscores
+func isSessionFunctionName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// function returns the session function with the lowercase name, or nil
+func (s *Session) function(name string) SessionFunction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.functions[name]
+}
+
+// CreateTempView creates a view that only the session can query. The view is dropped
+// when the session is closed. query may call the functions of the session.
+func (s *Session) CreateTempView(ctx context.Context, name, query string) error {
+	statement := fmt.Sprintf("CREATE TEMP VIEW %s AS %s", quoteIdentifier(name), s.rewrite(query))
+	if _, err := s.conn.ExecContext(ctx, statement); err != nil {
+		return fmt.Errorf("failed to create temporary view %s: %w", name, err)
+	}
+	return nil
+}
+
+// ExecContext executes a statement on the session's connection.
+func (s *Session) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.conn.ExecContext(ctx, s.rewrite(query), args...)
+}
+
+// QueryContext runs a query on the session's connection.
+func (s *Session) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return s.conn.QueryContext(ctx, s.rewrite(query), args...)
+}
+
+// QueryRowContext runs a query that returns at most one row on the session's connection.
+func (s *Session) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return s.conn.QueryRowContext(ctx, s.rewrite(query), args...)
+}
+
+// Close drops the temporary objects created during the session, unregisters its
+// functions, and returns the connection to the pool.
+func (s *Session) Close() error {
+	sessionCalls.remove(s.id)
+
+	dropErr := s.dropTempObjects(context.Background())
+	if dropErr != nil {
+		// The connection still holds the session's objects; discard it instead of reusing it
+		_ = s.conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	if err := s.conn.Close(); err != nil {
+		return err
+	}
+	return dropErr
+}
+
+// dropTempObjects drops the temporary views and tables created during the session
+func (s *Session) dropTempObjects(ctx context.Context) error {
+	current, err := listTempObjects(ctx, s.conn)
+	if err != nil {
+		return err
+	}
+	for object := range current {
+		if s.tempObjects[object] {
+			continue
+		}
+		objectType, name, _ := strings.Cut(object, " ")
+		if _, err := s.conn.ExecContext(ctx, fmt.Sprintf("DROP %s IF EXISTS temp.%s", objectType, quoteIdentifier(name))); err != nil {
+			return fmt.Errorf("failed to drop temporary %s %s: %w", strings.ToLower(objectType), name, err)
+		}
+	}
+	return nil
+}
+
+// listTempObjects returns the temporary views and tables of conn as "TYPE name"
+func listTempObjects(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT type, name FROM sqlite_temp_master WHERE type IN ('view', 'table')")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list temporary objects: %w", err)
+	}
+	defer rows.Close()
+
+	objects := make(map[string]bool)
+	for rows.Next() {
+		var objectType, name string
+		if err := rows.Scan(&objectType, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan temporary object: %w", err)
+		}
+		objects[strings.ToUpper(objectType)+" "+name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read temporary objects: %w", err)
+	}
+	return objects, nil
+}
+
+// rewrite replaces calls of session functions in query with calls of sessionCallFunction.
+// String literals, quoted identifiers, and comments are left unchanged.
+func (s *Session) rewrite(query string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.functions) == 0 {
+		return query
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := skipQuoted(query, i, c)
+			out.WriteString(query[i:end])
+			i = end
+		case c == '[':
+			end := strings.IndexByte(query[i:], ']')
+			if end < 0 {
+				end = len(query) - i - 1
+			}
+			out.WriteString(query[i : i+end+1])
+			i += end + 1
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			out.WriteString(query[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i - 4
+			}
+			out.WriteString(query[i : i+end+4])
+			i += end + 4
+		case isIdentifierStart(c):
+			end := i + 1
+			for end < len(query) && isIdentifierPart(query[end]) {
+				end++
+			}
+			word := query[i:end]
+			open := end
+			for open < len(query) && (query[open] == ' ' || query[open] == '\t' || query[open] == '\n' || query[open] == '\r') {
+				open++
+			}
+			_, isFunction := s.functions[strings.ToLower(word)]
+			if !isFunction || open >= len(query) || query[open] != '(' || (i > 0 && query[i-1] == '.') {
+				out.WriteString(word)
+				i = end
+				continue
+			}
+			fmt.Fprintf(&out, "%s(%d, '%s'", sessionCallFunction, s.id, strings.ToLower(word))
+			next := open + 1
+			for next < len(query) && (query[next] == ' ' || query[next] == '\t' || query[next] == '\n' || query[next] == '\r') {
+				next++
+			}
+			if next >= len(query) || query[next] != ')' {
+				out.WriteString(", ")
+			}
+			i = open + 1
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// skipQuoted returns the index after the quoted string or identifier that starts at start.
+// A doubled quote character inside it is an escaped quote.
+func skipQuoted(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(query)
+}
+
+// isIdentifierStart reports whether c can start an unquoted SQL identifier
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// isIdentifierPart reports whether c can continue an unquoted SQL identifier
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9') || c == '$'
+}
+
+// call implements sessionCallFunction
+func (r *sessionRegistry) call(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("%s needs a session ID and a function name", sessionCallFunction)
+	}
+	id, _ := args[0].(int64)
+	name, _ := args[1].(string)
+
+	var fn SessionFunction
+	if session := r.session(id); session != nil {
+		fn = session.function(name)
+	}
+	if fn == nil {
+		return nil, fmt.Errorf("no such function: %s", name)
+	}
+
+	values := make([]any, len(args)-2)
+	for i, arg := range args[2:] {
+		values[i] = arg
+	}
+	result, err := fn(values)
+	if err != nil {
+		return nil, err
+	}
+	return sessionFunctionResult(result)
+}
+
+// sessionFunctionResult converts the result of a session function into a SQLite value
+func sessionFunctionResult(result any) (driver.Value, error) {
+	switch v := result.(type) {
+	case nil, int64, float64, string, []byte:
+		return v, nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	default:
+		return nil, fmt.Errorf("unsupported session function result type %T", result)
+	}
+}
@@ -0,0 +1,179 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSessionTestDB opens a database with a products table
+func openSessionTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	return openTestDB(t, NewBuilder().
+		AddReader(strings.NewReader("name,price\napple,100\nbanana,50\n"), "products", FileTypeCSV))
+}
+
+// markup returns a session function that multiplies its argument by factor
+func markup(factor float64) SessionFunction {
+	return func(args []any) (any, error) {
+		switch v := args[0].(type) {
+		case int64:
+			return float64(v) * factor, nil
+		case float64:
+			return v * factor, nil
+		default:
+			return nil, errors.New("price must be a number")
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sessions use their own functions and views with the same name", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openSessionTestDB(t)
+
+		first, err := NewSession(ctx, db)
+		require.NoError(t, err)
+		defer first.Close()
+		second, err := NewSession(ctx, db)
+		require.NoError(t, err)
+		defer second.Close()
+
+		require.NoError(t, first.RegisterFunction("tenant_price", markup(2)))
+		require.NoError(t, second.RegisterFunction("TENANT_PRICE", markup(10)))
+		require.NoError(t, first.CreateTempView(ctx, "priced", "SELECT name, tenant_price(price) AS price FROM products"))
+		require.NoError(t, second.CreateTempView(ctx, "priced", "SELECT name, Tenant_Price (price) AS price FROM products"))
+
+		var price float64
+		require.NoError(t, first.QueryRowContext(ctx, "SELECT price FROM priced WHERE name = 'apple'").Scan(&price))
+		assert.InDelta(t, 200.0, price, 0.001)
+		require.NoError(t, second.QueryRowContext(ctx, "SELECT price FROM priced WHERE name = 'apple'").Scan(&price))
+		assert.InDelta(t, 1000.0, price, 0.001)
+
+		// Neither the view nor the function is visible outside the sessions
+		_, err = db.ExecContext(ctx, "SELECT * FROM priced")
+		require.Error(t, err)
+		_, err = db.ExecContext(ctx, "SELECT tenant_price(price) FROM products")
+		require.Error(t, err)
+	})
+
+	t.Run("function errors are returned by the query", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		session, err := NewSession(ctx, openSessionTestDB(t))
+		require.NoError(t, err)
+		defer session.Close()
+
+		require.NoError(t, session.RegisterFunction("tenant_price", markup(2)))
+		var price float64
+		err = session.QueryRowContext(ctx, "SELECT tenant_price(name) FROM products").Scan(&price)
+		require.ErrorContains(t, err, "price must be a number")
+	})
+
+	t.Run("close drops the temporary objects of the session", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openSessionTestDB(t)
+		db.SetMaxOpenConns(1)
+
+		session, err := NewSession(ctx, db)
+		require.NoError(t, err)
+		require.NoError(t, session.CreateTempView(ctx, "cheap", "SELECT * FROM products WHERE price < 80"))
+		_, err = session.ExecContext(ctx, "CREATE TEMP TABLE scratch (id INTEGER)")
+		require.NoError(t, err)
+		require.NoError(t, session.Close())
+
+		// The only connection of the pool no longer has the objects
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_temp_master").Scan(&count))
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("functions of a closed session cannot be called", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openSessionTestDB(t)
+		session, err := NewSession(ctx, db)
+		require.NoError(t, err)
+		require.NoError(t, session.RegisterFunction("tenant_price", markup(2)))
+		call := session.rewrite("SELECT tenant_price(price) FROM products")
+		require.NoError(t, session.Close())
+
+		_, err = db.ExecContext(ctx, call)
+		require.ErrorContains(t, err, "no such function: tenant_price")
+	})
+
+	registerTests := []struct {
+		name     string
+		function string
+		fn       SessionFunction
+		wantErr  bool
+	}{
+		{name: "name with a space", function: "drop table", fn: markup(1), wantErr: true},
+		{name: "empty name", function: "", fn: markup(1), wantErr: true},
+		{name: "name starting with a digit", function: "1f", fn: markup(1), wantErr: true},
+		{name: "name starting with an underscore", function: "_f1", fn: markup(1)},
+		{name: "nil function", function: "f", wantErr: true},
+	}
+	for _, tt := range registerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			session, err := NewSession(context.Background(), openSessionTestDB(t))
+			require.NoError(t, err)
+			defer session.Close()
+
+			err = session.RegisterFunction(tt.function, tt.fn)
+			if tt.wantErr {
+				assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSessionRewrite(t *testing.T) {
+	t.Parallel()
+
+	session := &Session{id: 7, functions: map[string]SessionFunction{"f": markup(1), "g": markup(1)}}
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "call", query: "SELECT f(a, 1)", want: "SELECT _filesql_session_call(7, 'f', a, 1)"},
+		{name: "call without arguments", query: "SELECT G( )", want: "SELECT _filesql_session_call(7, 'g' )"},
+		{name: "nested calls", query: "SELECT f(g(x))", want: "SELECT _filesql_session_call(7, 'f', _filesql_session_call(7, 'g', x))"},
+		{name: "string literal", query: "SELECT 'f(x)', f", want: "SELECT 'f(x)', f"},
+		{name: "quoted identifier", query: `SELECT "f"(x), [f](y)`, want: `SELECT "f"(x), [f](y)`},
+		{name: "comments", query: "SELECT 1 -- f(x)\n/* g(y) */", want: "SELECT 1 -- f(x)\n/* g(y) */"},
+		{name: "qualified name", query: "SELECT t.f(x), ff(x)", want: "SELECT t.f(x), ff(x)"},
+		{name: "escaped quotes in string literal", query: "SELECT 'it''s f(x)', f(1)", want: "SELECT 'it''s f(x)', _filesql_session_call(7, 'f', 1)"},
+		{name: "escaped quotes in quoted identifier", query: `SELECT "a""f(x)" FROM t`, want: `SELECT "a""f(x)" FROM t`},
+		{name: "backquoted identifier", query: "SELECT `f`(x), `g(y)`", want: "SELECT `f`(x), `g(y)`"},
+		{name: "call after comment", query: "SELECT /* f(x) */ f(x) -- g(y)\n, g(y)", want: "SELECT /* f(x) */ _filesql_session_call(7, 'f', x) -- g(y)\n, _filesql_session_call(7, 'g', y)"},
+		{name: "unterminated comment", query: "SELECT 1 /* f(x)", want: "SELECT 1 /* f(x)"},
+		{name: "unterminated string literal", query: "SELECT 'f(x)", want: "SELECT 'f(x)"},
+		{name: "names containing the function name", query: "SELECT f_1(x), x$f(y), _f(z)", want: "SELECT f_1(x), x$f(y), _f(z)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, session.rewrite(tt.query))
+		})
+	}
+}
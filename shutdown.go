@@ -0,0 +1,77 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// NotifyShutdown returns a context that is cancelled when the process receives
+// SIGINT (Ctrl+C) or SIGTERM.
+//
+// Pass the context to Open, StartLoad, and QueryContext so that in-flight loads and
+// queries stop as soon as the user interrupts the program. After the first signal,
+// the default signal handling is restored, so a second Ctrl+C terminates the
+// process immediately. Call stop to release the signal handler when done.
+//
+// Together with CloseWithTimeout, it gives CLI tools a bounded, signal-safe shutdown:
+//
+//	ctx, stop := filesql.NotifyShutdown(context.Background())
+//	defer stop()
+//
+//	db, err := validatedBuilder.Open(ctx) // Interrupted by Ctrl+C
+//	if err != nil {
+//		return err
+//	}
+//	defer func() {
+//		// Auto-save runs here; give up after 10 seconds
+//		if err := filesql.CloseWithTimeout(db, 10*time.Second); err != nil {
+//			fmt.Fprintln(os.Stderr, err)
+//		}
+//	}()
+//
+//	rows, err := db.QueryContext(ctx, query) // Interrupted by Ctrl+C
+func NotifyShutdown(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	ctx, stop = signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		// Restore the default behavior so that a second signal terminates the process
+		stop()
+	}()
+	return ctx, stop
+}
+
+// CloseWithTimeout closes db and waits at most timeout for it to finish.
+//
+// Closing a database with auto-save enabled writes every table, which can take long
+// for large datasets. If the close does not finish in time, CloseWithTimeout returns
+// an error wrapping ErrAutoSaveTimeout and context.DeadlineExceeded while the close
+// continues in the background; files that were not written by then may be missing
+// or incomplete. To abort the save itself at a deadline, configure the builder with
+// SetAutoSaveTimeout.
+//
+// A timeout of 0 or less waits until the close finishes.
+func CloseWithTimeout(db *sql.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return db.Close()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- db.Close()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w: database did not close within %s: %w", ErrAutoSaveTimeout, timeout, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,103 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingConn is a driver connection whose Close blocks until release is closed
+type blockingConn struct {
+	release chan struct{}
+}
+
+func (c *blockingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *blockingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+func (c *blockingConn) Close() error {
+	<-c.release
+	return nil
+}
+
+func TestNotifyShutdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cancelling the parent cancels the context", func(t *testing.T) {
+		t.Parallel()
+
+		parent, cancel := context.WithCancel(context.Background())
+		ctx, stop := NotifyShutdown(parent)
+		defer stop()
+
+		cancel()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("context was not cancelled")
+		}
+	})
+
+	t.Run("cancelled context stops loading", func(t *testing.T) {
+		t.Parallel()
+
+		parent, cancel := context.WithCancel(context.Background())
+		ctx, stop := NotifyShutdown(parent)
+		defer stop()
+		cancel()
+
+		validatedBuilder, err := NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			Build(context.Background())
+		require.NoError(t, err)
+
+		_, err = validatedBuilder.Open(ctx)
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeCancelled, ErrorCodeOf(err))
+	})
+}
+
+func TestCloseWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("close within the timeout runs auto-save", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outputDir := t.TempDir()
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "a", FileTypeCSV).
+			EnableAutoSave(outputDir).
+			Build(ctx)
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, CloseWithTimeout(db, time.Minute))
+		assert.FileExists(t, filepath.Join(outputDir, "a.csv"))
+	})
+
+	t.Run("slow close returns a timeout error", func(t *testing.T) {
+		t.Parallel()
+
+		conn := &blockingConn{release: make(chan struct{})}
+		defer close(conn.release)
+
+		db := sql.OpenDB(&directConnector{conn: conn})
+		// Return a connection to the idle pool so that db.Close has to close it
+		sqlConn, err := db.Conn(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, sqlConn.Close())
+
+		err = CloseWithTimeout(db, 10*time.Millisecond)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrAutoSaveTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,360 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"runtime"
+)
+
+// sidecarIndexSuffix is appended to the path of a file to name its sidecar index
+const sidecarIndexSuffix = ".filesql-index"
+
+// sidecarIndexVersion is the version of the sidecar index format; indexes of other
+// versions are rebuilt
+const sidecarIndexVersion = 1
+
+// WithSidecarIndex speeds up repeated opens of large CSV and TSV files with an index
+// file written next to them.
+//
+// The first time an uncompressed CSV or TSV file path is loaded, a small index named
+// after the file with the suffix ".filesql-index" (for example "sales.csv.filesql-index")
+// is written next to it, through the FileSystem of the builder. It records the header,
+// the inferred column types, the number of rows, and the byte offsets of every chunk
+// of rows. Later opens of the unchanged file skip type inference and parse the chunks
+// in parallel, one per CPU, while rows are still inserted in file order.
+//
+// An index is used only while the size and modification time of the file match the
+// ones it was written for; otherwise it is rebuilt. Compressed files, files of
+// filesystems added with AddFS, and readers are loaded as without this option. When
+// the index cannot be written, for example in a read-only directory, the file is still
+// loaded and the failure is recorded as a load warning (see GetLoadWarnings).
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("events.csv"). // 5 GB
+//		WithSidecarIndex()
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithSidecarIndex() *DBBuilder {
+	b.streamProcessor.sidecarIndex = true
+	return b
+}
+
+// sidecarIndex is the content of a sidecar index file
+type sidecarIndex struct {
+	// Version is sidecarIndexVersion
+	Version int `json:"version"`
+	// Size is the size of the indexed file in bytes
+	Size int64 `json:"size"`
+	// ModTime is the modification time of the indexed file in Unix nanoseconds
+	ModTime int64 `json:"mod_time"`
+	// Delimiter is the delimiter the file was parsed with
+	Delimiter string `json:"delimiter"`
+	// Header is the header record
+	Header []string `json:"header"`
+	// Types are the inferred types of the columns
+	Types []string `json:"types"`
+	// Rows is the number of records after the header
+	Rows int `json:"rows"`
+	// Offsets are the byte offsets of the first record of every chunk
+	Offsets []int64 `json:"offsets"`
+}
+
+// sidecarLoad is the sidecar index state of a file being loaded
+type sidecarLoad struct {
+	// index is a valid index of the file, which is then read in parallel; nil reads the
+	// file sequentially and records a new index into recorded
+	index *sidecarIndex
+	// recorded is the index recorded while the file is read sequentially
+	recorded *sidecarIndex
+	// openAt opens the file for reading from a byte offset
+	openAt func(offset int64) (io.ReadCloser, error)
+	// bytesRead reports the bytes of the file that have been loaded; may be nil
+	bytesRead func(n int)
+}
+
+// sidecarColumnTypes maps column types to their names in sidecar indexes
+func sidecarColumnTypes() map[columnType]string {
+	return map[columnType]string{
+		columnTypeText:     "text",
+		columnTypeInteger:  "integer",
+		columnTypeReal:     "real",
+		columnTypeDatetime: "datetime",
+	}
+}
+
+// usesSidecarIndex reports whether filePath is loaded with a sidecar index
+func (sp *streamProcessor) usesSidecarIndex(filePath string) bool {
+	options := sp.fileOptions[filePath]
+	if !sp.sidecarIndex || sp.detectHeader || sp.detectEncoding || sp.nullConvention != NullConventionNone ||
+		options.headerless() || options.encoding != "" {
+		return false
+	}
+	fileType := newFile(filePath).getFileType()
+	return fileType == FileTypeCSV || fileType == FileTypeTSV || fileType == FileTypeSSV || fileType == FileTypePSV
+}
+
+// prepareSidecarLoad returns the sidecar index state for loading filePath, using its
+// index if the index matches the file
+func (sp *streamProcessor) prepareSidecarLoad(filePath string, info fs.FileInfo) *sidecarLoad {
+	load := &sidecarLoad{
+		openAt: sp.openAt(filePath),
+		bytesRead: func(n int) {
+			if sp.observer != nil {
+				sp.observer.bytesRead(n)
+			}
+		},
+	}
+	if index := sp.readSidecarIndex(filePath, info); index != nil {
+		load.index = index
+		return load
+	}
+	load.recorded = &sidecarIndex{
+		Version: sidecarIndexVersion,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+	}
+	return load
+}
+
+// readSidecarIndex returns the sidecar index of filePath, or nil if there is none or
+// it does not describe the file as it is
+func (sp *streamProcessor) readSidecarIndex(filePath string, info fs.FileInfo) *sidecarIndex {
+	file, err := sp.fileSystem().Open(filePath + sidecarIndexSuffix)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var index sidecarIndex
+	if err := json.NewDecoder(file).Decode(&index); err != nil {
+		return nil
+	}
+	if !index.matches(info, sp.fileDelimiter(filePath), sp.detectDelimiter && sp.fileOptions[filePath].delimiter == 0) {
+		return nil
+	}
+	return &index
+}
+
+// matches reports whether the index describes a file with info, parsed with the
+// expected delimiter unless the delimiter is detected
+func (s *sidecarIndex) matches(info fs.FileInfo, expected rune, detectDelimiter bool) bool {
+	if s.Version != sidecarIndexVersion || s.Size != info.Size() || s.ModTime != info.ModTime().UnixNano() {
+		return false
+	}
+	delimiter := []rune(s.Delimiter)
+	if len(delimiter) != 1 || (!detectDelimiter && delimiter[0] != expected) {
+		return false
+	}
+	if len(s.Header) == 0 || len(s.Types) != len(s.Header) || (s.Rows > 0) != (len(s.Offsets) > 0) {
+		return false
+	}
+	for i, offset := range s.Offsets {
+		if offset <= 0 || offset >= s.Size || (i > 0 && offset <= s.Offsets[i-1]) {
+			return false
+		}
+	}
+	_, err := s.columnInfo()
+	return err == nil
+}
+
+// columnInfo returns the columns of the index
+func (s *sidecarIndex) columnInfo() ([]columnInfo, error) {
+	names := make(map[string]columnType)
+	for columnType, name := range sidecarColumnTypes() {
+		names[name] = columnType
+	}
+	columns := make([]columnInfo, len(s.Header))
+	for i, name := range s.Header {
+		columnType, ok := names[s.Types[i]]
+		if !ok {
+			return nil, fmt.Errorf("unknown column type %q", s.Types[i])
+		}
+		columns[i] = newColumnInfoWithType(name, columnType)
+	}
+	return columns, nil
+}
+
+// recordColumns records the columns of the file once their types are inferred
+func (s *sidecarIndex) recordColumns(columns columnInfoList) {
+	names := sidecarColumnTypes()
+	s.Types = make([]string, len(columns))
+	for i, column := range columns {
+		s.Types[i] = names[column.Type]
+	}
+}
+
+// defaultDelimiter returns the delimiter of a CSV, TSV, SSV, or PSV file type
+func defaultDelimiter(fileType FileType) rune {
+	switch fileType.baseType() {
+	case FileTypeTSV:
+		return tsvDelimiter
+	case FileTypeSSV:
+		return ssvDelimiter
+	case FileTypePSV:
+		return psvDelimiter
+	default:
+		return csvDelimiter
+	}
+}
+
+// openAt returns a function that opens filePath for reading from an offset
+func (sp *streamProcessor) openAt(filePath string) func(offset int64) (io.ReadCloser, error) {
+	return func(offset int64) (io.ReadCloser, error) {
+		file, err := sp.fileSystem().Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if seeker, ok := file.(io.Seeker); ok {
+			_, err = seeker.Seek(offset, io.SeekStart)
+		} else {
+			_, err = io.CopyN(io.Discard, file, offset)
+		}
+		if err != nil {
+			_ = file.Close() // Ignore close error during error handling
+			return nil, fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+		}
+		return file, nil
+	}
+}
+
+// saveSidecarIndex writes the index recorded while loading filePath. Failures are
+// recorded as load warnings, because the file itself was loaded.
+func (sp *streamProcessor) saveSidecarIndex(ctx context.Context, db *sql.DB, filePath string, load *sidecarLoad) error {
+	if load.recorded == nil || len(load.recorded.Header) == 0 {
+		return nil
+	}
+	if err := writeSidecarIndex(sp.fileSystem(), filePath+sidecarIndexSuffix, load.recorded); err != nil {
+		return recordLoadWarning(ctx, db, sp.fileTableName(filePath),
+			fmt.Sprintf("failed to write sidecar index %s: %v", filePath+sidecarIndexSuffix, err))
+	}
+	return nil
+}
+
+// writeSidecarIndex writes index to path
+func writeSidecarIndex(fileSystem FileSystem, path string, index *sidecarIndex) (err error) {
+	file, err := fileSystem.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	return json.NewEncoder(file).Encode(index)
+}
+
+// parsedRange is the result of parsing the records of one chunk of an indexed file
+type parsedRange struct {
+	records []Record
+	// size is the number of bytes the chunk spans
+	size int64
+	err  error
+}
+
+// processIndexedInChunks parses the chunks of an indexed file in parallel and passes
+// them to processor in file order
+func (p *streamingParser) processIndexedInChunks(processor chunkProcessor, expected rune, fileTypeName string) error {
+	index := p.sidecar.index
+	delimiter := []rune(index.Delimiter)[0]
+	if delimiter != expected && p.delimiterDetected != nil {
+		if err := p.delimiterDetected(delimiter, expected); err != nil {
+			return err
+		}
+	}
+	// Options that drop columns may differ from when the index was written
+	if err := p.validateHeader(index.Header); err != nil {
+		return err
+	}
+	header := newHeader(index.Header)
+	columns, err := index.columnInfo()
+	if err != nil {
+		return err
+	}
+	if len(index.Offsets) == 0 {
+		return processor(&tableChunk{tableName: p.tableName, headers: header, columnInfo: columns})
+	}
+
+	results := make([]chan parsedRange, len(index.Offsets))
+	for i := range results {
+		results[i] = make(chan parsedRange, 1)
+	}
+	// Parsing runs at most one chunk per CPU ahead of inserting
+	slots := make(chan struct{}, min(runtime.GOMAXPROCS(0), len(index.Offsets)))
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for i := range index.Offsets {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func() { results[i] <- p.parseRange(i, delimiter, len(header)) }()
+		}
+	}()
+
+	rows := 0
+	for i := range results {
+		parsed := <-results[i]
+		if parsed.err != nil {
+			return fmt.Errorf("failed to read %s record: %w", fileTypeName, parsed.err)
+		}
+		rows += len(parsed.records)
+		chunk := &tableChunk{tableName: p.tableName, headers: header, records: parsed.records, columnInfo: columns}
+		if err := processor(chunk); err != nil {
+			return fmt.Errorf("chunk processor error: %w", err)
+		}
+		if p.sidecar.bytesRead != nil {
+			p.sidecar.bytesRead(int(parsed.size))
+		}
+		<-slots
+	}
+	if rows != index.Rows {
+		return fmt.Errorf("sidecar index is out of date: expected %d rows, read %d", index.Rows, rows)
+	}
+	return nil
+}
+
+// parseRange parses the records of the i-th chunk of an indexed file
+func (p *streamingParser) parseRange(i int, delimiter rune, fields int) parsedRange {
+	index := p.sidecar.index
+	start, end := index.Offsets[i], index.Size
+	if i+1 < len(index.Offsets) {
+		end = index.Offsets[i+1]
+	}
+	size := end - start
+	if i == 0 {
+		size = end // The header is loaded with the first chunk
+	}
+
+	file, err := p.sidecar.openAt(start)
+	if err != nil {
+		return parsedRange{err: err}
+	}
+	defer file.Close()
+
+	csvReader := csv.NewReader(io.LimitReader(file, end-start))
+	csvReader.Comma = delimiter
+	csvReader.FieldsPerRecord = fields
+	var records []Record
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return parsedRange{err: err}
+		}
+		records = append(records, newRecord(record))
+	}
+	return parsedRange{records: records, size: size}
+}
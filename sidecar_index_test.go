@@ -0,0 +1,156 @@
+package filesql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithSidecarIndex(t *testing.T) {
+	t.Parallel()
+
+	writeCSV := func(t *testing.T, rows int) string {
+		t.Helper()
+		var content strings.Builder
+		content.WriteString("id,name,score\n")
+		for i := 1; i <= rows; i++ {
+			// Quoted values with newlines must not confuse the offsets
+			fmt.Fprintf(&content, "%d,\"user %d\nline two\",%d.5\n", i, i, i)
+		}
+		path := filepath.Join(t.TempDir(), "events.csv")
+		require.NoError(t, os.WriteFile(path, []byte(content.String()), 0600))
+		return path
+	}
+	// chunkRows sets the number of rows the stream processor parses per chunk
+	chunkRows := func(builder *DBBuilder, rows int) *DBBuilder {
+		builder.streamProcessor.chunkSize = rows
+		return builder
+	}
+	readIndex := func(t *testing.T, path string) sidecarIndex {
+		t.Helper()
+		data, err := os.ReadFile(path + sidecarIndexSuffix)
+		require.NoError(t, err)
+		var index sidecarIndex
+		require.NoError(t, json.Unmarshal(data, &index))
+		return index
+	}
+	const events = "SELECT id, name, score, typeof(score) FROM events ORDER BY rowid"
+
+	t.Run("first open writes the index", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeCSV(t, 10)
+		openTestDB(t, chunkRows(NewBuilder().AddPath(path).WithSidecarIndex(), 3))
+
+		index := readIndex(t, path)
+		assert.Equal(t, []string{"id", "name", "score"}, index.Header)
+		assert.Equal(t, []string{"integer", "text", "real"}, index.Types)
+		assert.Equal(t, ",", index.Delimiter)
+		assert.Equal(t, 10, index.Rows)
+		assert.Len(t, index.Offsets, 4)
+	})
+
+	t.Run("later opens load the same rows in file order", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeCSV(t, 500)
+		want := queryRows(t, openTestDB(t, NewBuilder().AddPath(path)), events)
+
+		first := openTestDB(t, chunkRows(NewBuilder().AddPath(path).WithSidecarIndex(), 7))
+		assert.Equal(t, want, queryRows(t, first, events))
+		require.FileExists(t, path+sidecarIndexSuffix)
+
+		second := openTestDB(t, chunkRows(NewBuilder().AddPath(path).WithSidecarIndex(), 7))
+		assert.Equal(t, want, queryRows(t, second, events))
+	})
+
+	t.Run("later opens skip type inference", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeCSV(t, 5)
+		openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex())
+
+		// An index that says score is text proves the types are taken from it
+		index := readIndex(t, path)
+		index.Types[2] = "text"
+		data, err := json.Marshal(index)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path+sidecarIndexSuffix, data, 0600))
+
+		var scoreType string
+		db := openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex())
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT typeof(score) FROM events LIMIT 1").Scan(&scoreType))
+		assert.Equal(t, "text", scoreType)
+	})
+
+	t.Run("changed files rebuild the index", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeCSV(t, 5)
+		openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex())
+
+		require.NoError(t, os.WriteFile(path, []byte("id,name,score\n1,only,2\n"), 0600))
+		later := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(path, later, later))
+
+		db := openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex())
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM events").Scan(&count))
+		assert.Equal(t, 1, count)
+		assert.Equal(t, 1, readIndex(t, path).Rows)
+	})
+
+	t.Run("header-only files are indexed", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "events.csv")
+		require.NoError(t, os.WriteFile(path, []byte("id,name,score\n"), 0600))
+		openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex())
+		assert.Equal(t, 0, readIndex(t, path).Rows)
+
+		db := openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex())
+		columns, err := getSQLiteTableColumns(db, "events")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "name", "score"}, columns)
+	})
+
+	t.Run("compressed files are not indexed", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "sample.csv.gz")
+		data, err := os.ReadFile(filepath.Join("testdata", "sample.csv.gz"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		openTestDB(t, NewBuilder().AddPath(path).WithSidecarIndex())
+		assert.NoFileExists(t, path+sidecarIndexSuffix)
+	})
+
+	t.Run("an index that cannot be written is a load warning", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeCSV(t, 3)
+		errReadOnly := errors.New("read-only file system")
+		db := openTestDB(t, NewBuilder().
+			AddPath(path).
+			WithFileSystem(failingFileSystem{FileSystem: NewOSFileSystem(), writeErr: errReadOnly}).
+			WithSidecarIndex())
+
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "events", warnings[0].Table)
+		assert.Contains(t, warnings[0].Message, "read-only file system")
+		assert.NoFileExists(t, path+sidecarIndexSuffix)
+	})
+}
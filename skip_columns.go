@@ -0,0 +1,174 @@
+package filesql
+
+import (
+	"strings"
+)
+
+// WithIgnoreEmptyHeaders drops columns whose header is empty at load time.
+//
+// Spreadsheets often contain trailing columns without a header, for example cells
+// that were formatted or once had notes in them. They normally become columns with
+// an empty name, and two of them make the load fail with a duplicate column error.
+// With this option, every column whose header is empty or only whitespace is dropped,
+// together with its values. The option applies to every input.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("report.xlsx").
+//		WithIgnoreEmptyHeaders()
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithIgnoreEmptyHeaders() *DBBuilder {
+	b.streamProcessor.ignoreEmptyHeaders = true
+	return b
+}
+
+// WithSkipColumns drops the named columns at load time.
+//
+// Names are source column names, before WithHeaderMapping renames them, and are
+// compared case-insensitively, like SQLite column names. They apply to every input;
+// inputs without such a column are not affected. Calling WithSkipColumns several
+// times adds to the list.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("export.csv").
+//		WithSkipColumns("notes", "_c12")
+//
+// A load fails with an error with ErrCodeInvalidConfig if no column of a table is left.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithSkipColumns(names ...string) *DBBuilder {
+	b.streamProcessor.skipColumns = append(b.streamProcessor.skipColumns, names...)
+	return b
+}
+
+// WithDroppedColumns drops the named columns of one table at load time, so their
+// values never enter the database.
+//
+// Use it to keep personal data such as e-mail addresses or IP addresses out of the
+// database when only the other columns are needed for analysis. Unlike a column
+// removed with SQL after loading, a dropped column is never stored, so it cannot leak
+// into queries, dumps, auto-saved files, or snapshots.
+//
+// The table is the name the input is loaded as (see the table naming rules), compared
+// case-insensitively; columns are source column names, before WithHeaderMapping renames
+// them, also compared case-insensitively. Columns that the table does not have are
+// ignored. Calling WithDroppedColumns several times adds to the columns of the table.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPaths("users.csv", "access_log.tsv").
+//		WithDroppedColumns("users", "email", "phone").
+//		WithDroppedColumns("access_log", "ip_address")
+//
+// A load fails with an error with ErrCodeInvalidConfig if no column of a table is left.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithDroppedColumns(table string, columns ...string) *DBBuilder {
+	if b.streamProcessor.droppedColumns == nil {
+		b.streamProcessor.droppedColumns = make(map[string][]string)
+	}
+	key := strings.ToLower(table)
+	b.streamProcessor.droppedColumns[key] = append(b.streamProcessor.droppedColumns[key], columns...)
+	return b
+}
+
+// dropsColumn reports whether the column with the given header of table is dropped at load time
+func (sp *streamProcessor) dropsColumn(table, name string) bool {
+	if sp.ignoreEmptyHeaders && strings.TrimSpace(name) == "" {
+		return true
+	}
+	for _, skip := range sp.skipColumns {
+		if strings.EqualFold(skip, name) {
+			return true
+		}
+	}
+	for _, dropped := range sp.droppedColumns[strings.ToLower(table)] {
+		if strings.EqualFold(dropped, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// columnDropper returns a function reporting whether a column of the table loaded as
+// loadingName is dropped at load time, or nil when no column of it is dropped
+func (sp *streamProcessor) columnDropper(loadingName string) func(name string) bool {
+	table := strings.TrimPrefix(loadingName, stagingTablePrefix)
+	if !sp.ignoreEmptyHeaders && len(sp.skipColumns) == 0 && len(sp.droppedColumns[strings.ToLower(table)]) == 0 {
+		return nil
+	}
+	return func(name string) bool {
+		return sp.dropsColumn(table, name)
+	}
+}
+
+// dropColumns removes the columns of a chunk that are dropped at load time
+func (sp *streamProcessor) dropColumns(chunk *tableChunk) (*tableChunk, error) {
+	dropsColumn := sp.columnDropper(chunk.getTableName())
+	if dropsColumn == nil {
+		return chunk, nil
+	}
+
+	headers := chunk.getHeaders()
+	kept := make([]int, 0, len(headers))
+	for i, name := range headers {
+		if !dropsColumn(name) {
+			kept = append(kept, i)
+		}
+	}
+	if len(kept) == len(headers) {
+		return chunk, nil
+	}
+	if len(kept) == 0 {
+		return nil, newCodedError(ErrCodeInvalidConfig, "table %s has no columns left after skipping columns", chunk.getTableName())
+	}
+
+	infos := chunk.getColumnInfo()
+	newHeaders := make(header, len(kept))
+	newColumnInfo := make([]columnInfo, 0, len(kept))
+	for j, i := range kept {
+		newHeaders[j] = headers[i]
+		if i < len(infos) {
+			newColumnInfo = append(newColumnInfo, infos[i])
+		}
+	}
+
+	records := make([]Record, len(chunk.getRecords()))
+	for r, record := range chunk.getRecords() {
+		newRecord := make(Record, len(kept))
+		for j, i := range kept {
+			if i < len(record) {
+				newRecord[j] = record[i]
+			}
+		}
+		records[r] = newRecord
+	}
+
+	return &tableChunk{
+		tableName:  chunk.getTableName(),
+		headers:    newHeaders,
+		records:    records,
+		columnInfo: newColumnInfo,
+	}, nil
+}
+
+// validateHeader checks the header for duplicate column names. Columns that are
+// dropped at load time are not checked, so several empty headers are allowed with
+// WithIgnoreEmptyHeaders.
+func (p *streamingParser) validateHeader(columns []string) error {
+	if p.dropsColumn == nil {
+		return validateColumnNames(columns)
+	}
+	kept := make([]string, 0, len(columns))
+	for _, name := range columns {
+		if !p.dropsColumn(name) {
+			kept = append(kept, name)
+		}
+	}
+	return validateColumnNames(kept)
+}
@@ -0,0 +1,198 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestDBBuilder_WithIgnoreEmptyHeaders(t *testing.T) {
+	t.Parallel()
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "trailing unnamed columns are dropped",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,name,, \n1,Gina,x,y\n"), "users", FileTypeCSV).
+					WithIgnoreEmptyHeaders()
+			},
+			want: map[string][]string{
+				"SELECT name FROM pragma_table_info('users')": {"id", "name"},
+				"SELECT name FROM users":                      {"Gina"},
+			},
+		},
+		{
+			name: "several unnamed columns fail without the option",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().AddReader(strings.NewReader("id,,\n1,x,y\n"), "users", FileTypeCSV)
+			},
+			wantErr: ErrCodeDuplicateColumn,
+		},
+		{
+			name: "unnamed columns of excel sheets are dropped",
+			builder: func(t *testing.T) *DBBuilder {
+				f := excelize.NewFile()
+				defer f.Close()
+				require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"id", "name", "", ""}))
+				require.NoError(t, f.SetSheetRow("Sheet1", "A2", &[]any{1, "Gina", "junk", "more junk"}))
+				path := filepath.Join(t.TempDir(), "report.xlsx")
+				require.NoError(t, f.SaveAs(path))
+				return NewBuilder().AddPath(path).WithIgnoreEmptyHeaders()
+			},
+			want: map[string][]string{
+				"SELECT name FROM pragma_table_info('report_Sheet1')": {"id", "name"},
+			},
+		},
+		{
+			name: "a header-only file keeps its named columns",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,name,,\n"), "users", FileTypeCSV).
+					WithIgnoreEmptyHeaders()
+			},
+			want: map[string][]string{
+				"SELECT name FROM pragma_table_info('users')": {"id", "name"},
+			},
+		},
+	})
+}
+
+func TestDBBuilder_WithSkipColumns(t *testing.T) {
+	t.Parallel()
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "named columns are dropped case-insensitively",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,Notes,name,_c12\n1,call back,Gina,\n"), "users", FileTypeCSV).
+					AddReader(strings.NewReader("id,total\n1,100\n"), "orders", FileTypeCSV).
+					WithSkipColumns("notes").
+					WithSkipColumns("_c12")
+			},
+			want: map[string][]string{
+				"SELECT name FROM pragma_table_info('users')":  {"id", "name"},
+				"SELECT name FROM pragma_table_info('orders')": {"id", "total"},
+			},
+		},
+		{
+			name: "skipped columns are matched before header mapping",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("fld_1,fld_2\n1,x\n"), "users", FileTypeCSV).
+					WithHeaderMapping(map[string]string{"fld_1": "id", "fld_2": "notes"}).
+					WithSkipColumns("fld_2")
+			},
+			want: map[string][]string{
+				"SELECT name FROM pragma_table_info('users')": {"id"},
+			},
+		},
+		{
+			name: "skipping a duplicated column resolves the duplicate",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,notes,notes\n1,a,b\n"), "users", FileTypeCSV).
+					WithSkipColumns("notes")
+			},
+			want: map[string][]string{
+				"SELECT name FROM pragma_table_info('users')": {"id"},
+			},
+		},
+		{
+			name: "skipping every column fails",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("notes\nx\n"), "users", FileTypeCSV).
+					WithSkipColumns("notes")
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+	})
+
+	t.Run("clones do not share the list", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewBuilder().WithSkipColumns("a")
+		clone := base.Clone().WithSkipColumns("b")
+		base.WithSkipColumns("c")
+
+		assert.Equal(t, []string{"a", "b"}, clone.streamProcessor.skipColumns)
+		assert.Equal(t, []string{"a", "c"}, base.streamProcessor.skipColumns)
+	})
+}
+
+func TestDBBuilder_WithDroppedColumns(t *testing.T) {
+	t.Parallel()
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "columns are dropped from the named table only",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddReader(strings.NewReader("id,Email,ip_address,name\n1,gina@example.com,192.0.2.1,Gina\n"), "users", FileTypeCSV).
+					AddReader(strings.NewReader("id,email\n1,support@example.com\n"), "contacts", FileTypeCSV).
+					WithDroppedColumns("USERS", "email").
+					WithDroppedColumns("users", "ip_address", "missing")
+			},
+			want: map[string][]string{
+				"SELECT name FROM pragma_table_info('users')":    {"id", "name"},
+				"SELECT name FROM pragma_table_info('contacts')": {"id", "email"},
+			},
+		},
+	})
+
+	t.Run("dropped values never reach the database", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id,email\n1,gina@example.com\n"), "users", FileTypeCSV).
+			WithDroppedColumns("users", "email"))
+		assert.Equal(t, []string{"1"}, queryRows(t, db, "SELECT * FROM users"))
+
+		require.NoError(t, DumpDatabase(db, dir))
+		data, err := os.ReadFile(filepath.Join(dir, "users.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, "id\n1\n", string(data))
+	})
+
+	t.Run("columns are dropped from staged background loads and header-only files", func(t *testing.T) {
+		t.Parallel()
+
+		validatedBuilder, err := NewBuilder().
+			AddReader(strings.NewReader("id,email\n1,gina@example.com\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id,email\n"), "empty", FileTypeCSV).
+			WithDroppedColumns("users", "email").
+			WithDroppedColumns("empty", "email").
+			Build(context.Background())
+		require.NoError(t, err)
+		job, err := validatedBuilder.StartLoad(context.Background())
+		require.NoError(t, err)
+		db, err := job.Wait()
+		require.NoError(t, err)
+		defer db.Close()
+
+		for _, table := range []string{"users", "empty"} {
+			columns, err := getSQLiteTableColumns(db, table)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"id"}, columns, table)
+		}
+	})
+
+	t.Run("clones do not share the columns", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewBuilder().WithDroppedColumns("users", "email")
+		clone := base.Clone().WithDroppedColumns("users", "phone")
+
+		assert.Equal(t, []string{"email", "phone"}, clone.streamProcessor.droppedColumns["users"])
+		assert.Equal(t, []string{"email"}, base.streamProcessor.droppedColumns["users"])
+	})
+}
@@ -0,0 +1,272 @@
+package filesql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SnapshotVersion is the snapshot format version written by SaveSnapshot.
+//
+// A snapshot starts with a fixed magic string and this version number, followed by
+// the SQL statements that recreate the database. OpenSnapshot migrates snapshots
+// written in older format versions and rejects newer ones with an error wrapping
+// ErrSnapshotVersion.
+const SnapshotVersion uint32 = 1
+
+// snapshotMagic identifies a filesql snapshot
+const snapshotMagic = "FILESQL-SNAPSHOT"
+
+// snapshotHeaderSize is the size of the magic string and the format version
+const snapshotHeaderSize = len(snapshotMagic) + 4
+
+// SaveSnapshot writes the complete contents of db to w as a versioned snapshot.
+//
+// Unlike DumpDatabase, a snapshot preserves the whole database exactly, including
+// column types, indexes, views, and tables created with SQL, and is restored with
+// OpenSnapshot without parsing the original files again.
+//
+// Example:
+//
+//	f, err := os.Create("cache.snapshot")
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close()
+//	if err := filesql.SaveSnapshot(ctx, db, f); err != nil {
+//		return err
+//	}
+func SaveSnapshot(ctx context.Context, db *sql.DB, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, snapshotHeaderSize)
+	copy(header, snapshotMagic)
+	binary.BigEndian.PutUint32(header[len(snapshotMagic):], SnapshotVersion)
+	if _, err := bw.Write(header); err != nil {
+		return fmt.Errorf("failed to write snapshot header: %w", err)
+	}
+
+	statements, err := snapshotSchema(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		if err := writeSnapshotStatement(bw, stmt.sql); err != nil {
+			return err
+		}
+		if stmt.kind != "table" {
+			continue
+		}
+		if err := writeSnapshotRows(ctx, db, bw, stmt.name); err != nil {
+			return err
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// OpenSnapshot restores a database from a snapshot written by SaveSnapshot.
+//
+// Snapshots written by older filesql versions are migrated to the current format.
+// Snapshots written in a newer format than this filesql supports fail with an error
+// wrapping ErrSnapshotVersion (code ErrCodeSnapshotVersion); upgrade filesql to read
+// them. Data that is not a snapshot fails with ErrCodeUnsupportedFormat.
+//
+// The returned database is in memory and has no auto-save configured.
+//
+// Example:
+//
+//	f, err := os.Open("cache.snapshot")
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close()
+//	db, err := filesql.OpenSnapshot(ctx, f)
+//	if errors.Is(err, filesql.ErrSnapshotVersion) {
+//		// Rebuild the cache from the original files
+//	}
+func OpenSnapshot(ctx context.Context, r io.Reader) (*sql.DB, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if len(data) < snapshotHeaderSize || !bytes.Equal(data[:len(snapshotMagic)], []byte(snapshotMagic)) {
+		return nil, newCodedError(ErrCodeUnsupportedFormat, "data is not a filesql snapshot")
+	}
+	version := binary.BigEndian.Uint32(data[len(snapshotMagic):snapshotHeaderSize])
+
+	payload, err := migrateSnapshot(version, data[snapshotHeaderSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := NewBuilder().createInMemoryDatabase(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := restoreSnapshot(ctx, db, payload); err != nil {
+		_ = db.Close() // Ignore close error
+		return nil, err
+	}
+	return db, nil
+}
+
+// snapshotObject is a schema object in sqlite_master
+type snapshotObject struct {
+	kind string
+	name string
+	sql  string
+}
+
+// snapshotSchema returns the schema objects of db, tables first, in creation order
+func snapshotSchema(ctx context.Context, db *sql.DB) ([]snapshotObject, error) {
+	rows, err := db.QueryContext(ctx, `SELECT type, name, sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		ORDER BY CASE type WHEN 'table' THEN 0 ELSE 1 END, rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database schema: %w", err)
+	}
+	defer rows.Close()
+
+	var objects []snapshotObject
+	for rows.Next() {
+		var object snapshotObject
+		if err := rows.Scan(&object.kind, &object.name, &object.sql); err != nil {
+			return nil, fmt.Errorf("failed to read database schema: %w", err)
+		}
+		objects = append(objects, object)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read database schema: %w", err)
+	}
+	return objects, nil
+}
+
+// writeSnapshotRows writes one INSERT statement per row of the table.
+// Values are encoded with SQLite's quote() so that every type round-trips exactly.
+func writeSnapshotRows(ctx context.Context, db *sql.DB, w io.Writer, tableName string) error {
+	quotedTable := quoteIdentifier(tableName)
+
+	columnRows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT 0", quotedTable)) //nolint:gosec // Table name is quoted
+	if err != nil {
+		return fmt.Errorf("failed to read columns of table %s: %w", tableName, err)
+	}
+	columns, err := columnRows.Columns()
+	_ = columnRows.Close() // Ignore close error
+	if err != nil {
+		return fmt.Errorf("failed to read columns of table %s: %w", tableName, err)
+	}
+
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = "quote(" + quoteIdentifier(column) + ")"
+	}
+	query := fmt.Sprintf( //nolint:gosec // Identifiers are quoted
+		"SELECT 'INSERT INTO ' || %s || ' VALUES(' || %s || ')' FROM %s",
+		quoteLiteral(quotedTable), strings.Join(values, " || ',' || "), quotedTable,
+	)
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to read table %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			return fmt.Errorf("failed to read table %s: %w", tableName, err)
+		}
+		if err := writeSnapshotStatement(w, stmt); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// writeSnapshotStatement writes a length-prefixed SQL statement
+func writeSnapshotStatement(w io.Writer, stmt string) error {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(stmt))) //nolint:gosec // Statements are far smaller than 4 GB
+	if _, err := w.Write(length[:]); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if _, err := io.WriteString(w, stmt); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// restoreSnapshot executes the length-prefixed statements of a current-version payload
+func restoreSnapshot(ctx context.Context, db *sql.DB, payload []byte) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after commit is a no-op
+
+	for len(payload) > 0 {
+		if len(payload) < 4 {
+			return newCodedError(ErrCodeInvalidData, "snapshot is truncated")
+		}
+		length := binary.BigEndian.Uint32(payload)
+		payload = payload[4:]
+		if uint64(length) > uint64(len(payload)) {
+			return newCodedError(ErrCodeInvalidData, "snapshot is truncated")
+		}
+		stmt := string(payload[:length])
+		payload = payload[length:]
+
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return newCodedError(ErrCodeInvalidData, "failed to restore snapshot: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+	return nil
+}
+
+// quoteIdentifier quotes a SQLite identifier
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteLiteral quotes a SQLite string literal
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// migrateSnapshot converts the payload of a snapshot in the given format version
+// into a payload of the current SnapshotVersion.
+//
+// When the format changes, increment SnapshotVersion and add a case that upgrades the
+// previous version by one step; older snapshots are upgraded step by step.
+func migrateSnapshot(version uint32, payload []byte) ([]byte, error) {
+	if version == 0 || version > SnapshotVersion {
+		return nil, newCodedError(ErrCodeSnapshotVersion, "%w: snapshot format version %d, supported versions 1 to %d",
+			ErrSnapshotVersion, version, SnapshotVersion)
+	}
+
+	for ; version < SnapshotVersion; version++ {
+		switch version { //nolint:gocritic // Upgrade steps are added here as the format evolves
+		default:
+			return nil, newCodedError(ErrCodeSnapshotVersion, "%w: no migration from snapshot format version %d",
+				ErrSnapshotVersion, version)
+		}
+	}
+	return payload, nil
+}
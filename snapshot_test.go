@@ -0,0 +1,147 @@
+package filesql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	openSample := func(t *testing.T, builder *DBBuilder) []byte {
+		t.Helper()
+
+		ctx := context.Background()
+		db := openTestDB(t, builder.AddPath(filepath.Join("testdata", "sample.csv")))
+
+		_, err := db.ExecContext(ctx, "CREATE INDEX idx_sample_name ON sample(name)")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, SaveSnapshot(ctx, db, &buf))
+		return buf.Bytes()
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		data := openSample(t, NewBuilder())
+		assert.Equal(t, snapshotMagic, string(data[:len(snapshotMagic)]))
+		assert.Equal(t, SnapshotVersion, binary.BigEndian.Uint32(data[len(snapshotMagic):snapshotHeaderSize]))
+
+		db, err := OpenSnapshot(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		defer db.Close()
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sample").Scan(&count))
+		assert.Positive(t, count)
+
+		var indexes int
+		require.NoError(t, db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM sqlite_master WHERE type='index' AND name='idx_sample_name'").Scan(&indexes))
+		assert.Equal(t, 1, indexes)
+	})
+
+	t.Run("values round trip exactly", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		source, err := NewBuilder().createInMemoryDatabase(nil)
+		require.NoError(t, err)
+		defer source.Close()
+		_, err = source.ExecContext(ctx, `CREATE TABLE "odd ""name""" (t TEXT, i INTEGER, r REAL, b BLOB, n TEXT)`)
+		require.NoError(t, err)
+		_, err = source.ExecContext(ctx, `INSERT INTO "odd ""name""" VALUES (?, ?, ?, ?, NULL)`,
+			"it's \"quoted\"\nmultiline", int64(9007199254740993), 0.1+0.2, []byte{0x00, 0xff})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, SaveSnapshot(ctx, source, &buf))
+		db, err := OpenSnapshot(ctx, &buf)
+		require.NoError(t, err)
+		defer db.Close()
+
+		var (
+			text    string
+			integer int64
+			real    float64
+			blob    []byte
+			null    sql.NullString
+		)
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT t, i, r, b, n FROM "odd ""name"""`).
+			Scan(&text, &integer, &real, &blob, &null))
+		assert.Equal(t, "it's \"quoted\"\nmultiline", text)
+		assert.Equal(t, int64(9007199254740993), integer)
+		assert.InDelta(t, 0.1+0.2, real, 0)
+		assert.Equal(t, []byte{0x00, 0xff}, blob)
+		assert.False(t, null.Valid)
+	})
+
+	t.Run("auto-save database", func(t *testing.T) {
+		t.Parallel()
+
+		data := openSample(t, NewBuilder().EnableAutoSave(t.TempDir()))
+		db, err := OpenSnapshot(context.Background(), bytes.NewReader(data))
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+	})
+
+	rejectTests := []struct {
+		name string
+		// data returns the data given to OpenSnapshot
+		data     func(t *testing.T) []byte
+		wantCode ErrorCode
+	}{
+		{
+			name: "newer format version is rejected",
+			data: func(t *testing.T) []byte {
+				data := openSample(t, NewBuilder())
+				binary.BigEndian.PutUint32(data[len(snapshotMagic):], SnapshotVersion+1)
+				return data
+			},
+			wantCode: ErrCodeSnapshotVersion,
+		},
+		{
+			name: "data that is not a snapshot is rejected",
+			data: func(*testing.T) []byte {
+				return []byte("id,name\n1,alice\n")
+			},
+			wantCode: ErrCodeUnsupportedFormat,
+		},
+		{
+			name: "corrupt database is rejected",
+			data: func(t *testing.T) []byte {
+				data := openSample(t, NewBuilder())
+				corrupt := append([]byte(nil), data[:snapshotHeaderSize]...)
+				return append(corrupt, bytes.Repeat([]byte{0xff}, 4096)...)
+			},
+			wantCode: ErrCodeInvalidData,
+		},
+	}
+
+	for _, tt := range rejectTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := OpenSnapshot(context.Background(), bytes.NewReader(tt.data(t)))
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, ErrorCodeOf(err))
+		})
+	}
+
+	t.Run("version zero is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := migrateSnapshot(0, nil)
+		require.ErrorIs(t, err, ErrSnapshotVersion)
+	})
+}
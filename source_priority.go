@@ -0,0 +1,171 @@
+package filesql
+
+import (
+	"fmt"
+	"io"
+)
+
+// inputKind identifies the builder method an input was added with
+type inputKind int
+
+const (
+	// inputPath is a file or directory added with AddPath or AddPaths
+	inputPath inputKind = iota
+	// inputFS is a filesystem added with AddFS
+	inputFS
+	// inputReader is a reader added with AddReader
+	inputReader
+	// inputRemote is a remote file added with AddS3, AddURL, or as a URI with AddPath or AddPaths
+	inputRemote
+)
+
+// WithSourcePriority lets inputs added later replace inputs added earlier that
+// produce the same table, instead of failing with a duplicate table error.
+//
+// This enables layered datasets, such as defaults embedded in the binary with a
+// local directory of overrides on top:
+//
+//	//go:embed defaults
+//	var defaults embed.FS
+//
+//	builder := filesql.NewBuilder().
+//		AddFS(defaults).        // countries.csv, currencies.csv
+//		AddPath("./overrides"). // currencies.csv replaces the embedded one
+//		WithSourcePriority()
+//
+// Priority follows the order of the AddPath, AddPaths, AddFS, AddReader, AddS3, and AddURL calls,
+// whatever kind of input they add; every file found in a directory or filesystem has
+// the priority of that directory or filesystem. Inputs are compared by the table name
+// derived from their file name (an Excel workbook replaces a workbook with the same
+// file name as a whole). Replaced inputs are not read, and each replacement is
+// recorded as a load warning (see GetLoadWarnings). Files with the same table name
+// in one directory are still loaded as without this option.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithSourcePriority() *DBBuilder {
+	b.sourcePriority = true
+	return b
+}
+
+// replacedInput is an input that was not loaded because an input added later
+// produces the same table
+type replacedInput struct {
+	// table is the table both inputs produce
+	table string
+	// input describes the replaced input
+	input string
+	// replacedBy describes the input the table is loaded from
+	replacedBy string
+}
+
+// rankedInput is a collected path or a reader with the position of the builder call
+// that added it
+type rankedInput struct {
+	rank  int
+	table string
+	// path is the collected path; empty for readers
+	path string
+	// reader is the reader input; nil for paths
+	reader *readerInput
+}
+
+// describe returns the name of the input used in load warnings
+func (r rankedInput) describe() string {
+	if r.reader == nil {
+		return r.path
+	}
+	if r.reader.source != "" {
+		return r.reader.source
+	}
+	return "reader for table " + r.reader.tableName
+}
+
+// inputRanks returns the positions among all added inputs of the inputs of kind,
+// in the order they were added
+func (b *DBBuilder) inputRanks(kind inputKind) []int {
+	var ranks []int
+	for rank, k := range b.inputOrder {
+		if k == kind {
+			ranks = append(ranks, rank)
+		}
+	}
+	return ranks
+}
+
+// applySourcePriority keeps, for every table, only the inputs of the builder call
+// added last among those producing it. paths are the collected paths with the index
+// of the input path they were found through; readers are the reader inputs, those
+// added with AddReader first, then those of each filesystem in fsReaders, then the
+// remote inputs in remoteReaders with the index of the remote input they come from
+// in remoteOrigins.
+func (b *DBBuilder) applySourcePriority(collected *collectedFiles, readers []readerInput, fsReaders [][]readerInput, remoteReaders []readerInput, remoteOrigins []int) ([]readerInput, error) {
+	pathRanks := b.inputRanks(inputPath)
+	readerRanks := b.inputRanks(inputReader)
+	fsRanks := b.inputRanks(inputFS)
+	remoteRanks := b.inputRanks(inputRemote)
+
+	var inputs []rankedInput
+	for i, path := range collected.paths {
+		inputs = append(inputs, rankedInput{rank: rankAt(pathRanks, collected.origins[i]), table: b.streamProcessor.fileTableName(path), path: path})
+	}
+	for i := range readers {
+		inputs = append(inputs, rankedInput{rank: rankAt(readerRanks, i), table: readers[i].tableName, reader: &readers[i]})
+	}
+	for i, group := range fsReaders {
+		for j := range group {
+			inputs = append(inputs, rankedInput{rank: rankAt(fsRanks, i), table: group[j].tableName, reader: &group[j]})
+		}
+	}
+	for i := range remoteReaders {
+		inputs = append(inputs, rankedInput{rank: rankAt(remoteRanks, remoteOrigins[i]), table: remoteReaders[i].tableName, reader: &remoteReaders[i]})
+	}
+
+	winners := make(map[string]rankedInput)
+	for _, input := range inputs {
+		if winner, ok := winners[input.table]; !ok || input.rank > winner.rank {
+			winners[input.table] = input
+		}
+	}
+
+	var paths []string
+	var kept []readerInput
+	for _, input := range inputs {
+		winner := winners[input.table]
+		if input.rank < winner.rank {
+			b.replacedInputs = append(b.replacedInputs, replacedInput{table: input.table, input: input.describe(), replacedBy: winner.describe()})
+			if closer, ok := input.reader.closer(); ok {
+				if err := closer.Close(); err != nil {
+					return nil, fmt.Errorf("failed to close replaced input %s: %w", input.describe(), err)
+				}
+			}
+			continue
+		}
+		if input.reader == nil {
+			paths = append(paths, input.path)
+		} else {
+			kept = append(kept, *input.reader)
+		}
+	}
+	b.collectedPaths = paths
+	return kept, nil
+}
+
+// rankAt returns ranks[i], or -1 for an input that was not added through a builder
+// method, which any added input replaces
+func rankAt(ranks []int, i int) int {
+	if i < len(ranks) {
+		return ranks[i]
+	}
+	return -1
+}
+
+// closer returns the reader of a reader input as an io.Closer if it is one. Files of
+// filesystems are opened by Build, so replaced ones must be closed; readers added
+// with AddReader belong to the caller and are never closed.
+func (r *readerInput) closer() (io.Closer, bool) {
+	if r == nil || r.source == "" {
+		return nil, false
+	}
+	closer, ok := r.reader.(io.Closer)
+	return closer, ok
+}
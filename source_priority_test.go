@@ -0,0 +1,87 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithSourcePriority(t *testing.T) {
+	t.Parallel()
+
+	defaults := func() fstest.MapFS {
+		return fstest.MapFS{
+			"countries.csv":  {Data: []byte("code,name\nJP,Japan\n")},
+			"currencies.csv": {Data: []byte("code,rate\nJPY,100\n")},
+		}
+	}
+
+	t.Run("a local directory overrides embedded defaults", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		override := filepath.Join(dir, "currencies.csv")
+		require.NoError(t, os.WriteFile(override, []byte("code,rate\nJPY,150\n"), 0600))
+
+		db := openTestDB(t, NewBuilder().AddFS(defaults()).AddPath(dir).WithSourcePriority())
+
+		assert.Equal(t, []string{"150"}, queryRows(t, db, "SELECT rate FROM currencies"))
+		assert.Equal(t, []string{"Japan"}, queryRows(t, db, "SELECT name FROM countries"))
+
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.Equal(t, []LoadWarning{{
+			Table:   "currencies",
+			Message: "skipped currencies.csv, which is replaced by " + override,
+		}}, warnings)
+	})
+
+	t.Run("priority follows the order inputs were added", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "currencies.csv")
+		require.NoError(t, os.WriteFile(path, []byte("code,rate\nJPY,150\n"), 0600))
+
+		db := openTestDB(t, NewBuilder().
+			AddPath(path).
+			AddFS(defaults()).
+			AddReader(strings.NewReader("code,name\nFR,France\n"), "countries", FileTypeCSV).
+			WithSourcePriority())
+
+		assert.Equal(t, []string{"100"}, queryRows(t, db, "SELECT rate FROM currencies"))
+		assert.Equal(t, []string{"France"}, queryRows(t, db, "SELECT name FROM countries"))
+	})
+
+	t.Run("later readers replace earlier readers", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id\n2\n"), "users", FileTypeCSV).
+			WithSourcePriority())
+		assert.Equal(t, []string{"2"}, queryRows(t, db, "SELECT id FROM users"))
+
+		warnings, err := GetLoadWarnings(db)
+		require.NoError(t, err)
+		assert.Equal(t, []LoadWarning{{
+			Table:   "users",
+			Message: "skipped reader for table users, which is replaced by reader for table users",
+		}}, warnings)
+	})
+
+	t.Run("overlapping sources fail without the option", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := tryOpenTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id\n2\n"), "users", FileTypeCSV))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeDuplicateTable, ErrorCodeOf(err))
+	})
+}
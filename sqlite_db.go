@@ -0,0 +1,164 @@
+package filesql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sqliteHeader is the header every SQLite database file starts with
+const sqliteHeader = "SQLite format 3\x00"
+
+// sqliteDatabase is an existing SQLite database attached to the database
+type sqliteDatabase struct {
+	// path is the path of the database file
+	path string
+	// name is the schema name the database is attached as
+	name string
+}
+
+// AddSQLiteDB attaches the existing SQLite database file at path, so queries can join
+// the loaded tables with its tables without exporting them first.
+//
+// The database is attached read-only as the schema named after the file without its
+// extension: the tables of reference.db are queried as reference.table, or just as
+// table when no loaded table has the same name. Use AddSQLiteDBAs to choose the schema
+// name, for example when the file name is not a valid SQL identifier.
+//
+// Build fails with ErrCodePathNotFound if the file does not exist, and with
+// ErrCodeInvalidConfig if it is not a SQLite database or its schema name is invalid or
+// used by another attached database or reference data. Open fails with
+// ErrCodeDuplicateTable if a loaded table has the same name as one of its tables,
+// because the unqualified name would silently refer to the loaded table. Attached
+// tables are not written by DumpDatabase or auto-save.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("orders.csv").
+//		AddSQLiteDB("reference.db")
+//	// SELECT o.id, c.name FROM orders o JOIN reference.countries c ON o.country = c.code
+//
+// Returns self for chaining.
+func (b *DBBuilder) AddSQLiteDB(path string) *DBBuilder {
+	base := filepath.Base(path)
+	return b.AddSQLiteDBAs(path, strings.TrimSuffix(base, filepath.Ext(base)))
+}
+
+// AddSQLiteDBAs attaches the existing SQLite database file at path as the schema name,
+// like AddSQLiteDB. The name must be a valid SQL identifier other than main and temp.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("orders.csv").
+//		AddSQLiteDBAs("master-data-2024.sqlite", "master")
+//	// SELECT * FROM master.customers
+//
+// Returns self for chaining.
+func (b *DBBuilder) AddSQLiteDBAs(path, name string) *DBBuilder {
+	b.sqliteDBs = append(b.sqliteDBs, sqliteDatabase{path: path, name: name})
+	return b
+}
+
+// validateSQLiteDBs checks that the attached SQLite databases exist and that their
+// schema names are unique among them and the reference data
+func validateSQLiteDBs(databases []sqliteDatabase, references []*ReferenceData) error {
+	seen := make(map[string]bool, len(databases)+len(references))
+	for _, reference := range references {
+		seen[strings.ToLower(reference.name)] = true
+	}
+	for _, database := range databases {
+		name := strings.ToLower(database.name)
+		if !isIdentifier(database.name) || name == "main" || name == "temp" {
+			return newCodedError(ErrCodeInvalidConfig, "invalid schema name of SQLite database %s: %q", database.path, database.name)
+		}
+		if seen[name] {
+			return newCodedError(ErrCodeInvalidConfig, "duplicate schema name of SQLite database %s: %s", database.path, database.name)
+		}
+		seen[name] = true
+		if err := checkSQLiteFile(database.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSQLiteFile fails if path is not a SQLite database file
+func checkSQLiteFile(path string) error {
+	f, err := os.Open(path) //nolint:gosec // File path is provided by the user
+	if errors.Is(err, fs.ErrNotExist) {
+		return newCodedError(ErrCodePathNotFound, "SQLite database does not exist: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	defer f.Close()
+
+	header := make([]byte, len(sqliteHeader))
+	n, err := io.ReadFull(f, header)
+	if n == 0 && errors.Is(err, io.EOF) {
+		return nil // SQLite treats an empty file as an empty database
+	}
+	if err != nil || !bytes.Equal(header, []byte(sqliteHeader)) {
+		return newCodedError(ErrCodeInvalidConfig, "not a SQLite database: %s", path)
+	}
+	return nil
+}
+
+// attachedSQLiteDBs returns the attached SQLite databases to attach to each connection
+func (b *DBBuilder) attachedSQLiteDBs() []attachedReference {
+	attached := make([]attachedReference, 0, len(b.sqliteDBs))
+	for _, database := range b.sqliteDBs {
+		attached = append(attached, attachedReference{name: database.name, uri: sqliteFileURI(database.path, "mode=ro")})
+	}
+	return attached
+}
+
+// checkSQLiteDBTables fails if a table of an attached SQLite database has the name of a
+// loaded table, a reference table, or a table of another attached SQLite database
+func checkSQLiteDBTables(ctx context.Context, db *sql.DB, databases []sqliteDatabase, references []*ReferenceData) error {
+	if len(databases) == 0 {
+		return nil
+	}
+	tables, err := listUserTables(ctx, db)
+	if err != nil {
+		return err
+	}
+	owners := make(map[string]string, len(tables))
+	for _, table := range tables {
+		owners[strings.ToLower(table)] = "the loaded inputs"
+	}
+	for _, reference := range references {
+		referenceTables, err := reference.Tables(ctx)
+		if err != nil {
+			return err
+		}
+		for _, table := range referenceTables {
+			owners[strings.ToLower(table)] = "reference data " + reference.name
+		}
+	}
+	for _, database := range databases {
+		databaseTables, err := queryStrings(ctx, db, fmt.Sprintf( //nolint:gosec // Schema name is quoted
+			`SELECT name FROM %s.sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%%' AND name NOT LIKE '\_filesql\_%%' ESCAPE '\'`,
+			quoteIdentifier(database.name)))
+		if err != nil {
+			return fmt.Errorf("failed to read SQLite database %s: %w", database.path, err)
+		}
+		for _, table := range databaseTables {
+			if owner, ok := owners[strings.ToLower(table)]; ok {
+				return newCodedError(ErrCodeDuplicateTable,
+					"table '%s' exists in both %s and SQLite database %s", table, owner, database.path)
+			}
+			owners[strings.ToLower(table)] = "SQLite database " + database.path
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_AddSQLiteDB(t *testing.T) {
+	t.Parallel()
+
+	// writeDatabase creates a SQLite database with a countries table at name in dir
+	writeDatabase := func(t *testing.T, dir, name string) string {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		db, err := sql.Open("sqlite", path)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.ExecContext(context.Background(), `CREATE TABLE countries (code TEXT, name TEXT);
+			INSERT INTO countries VALUES ('JP', 'Japan'), ('FR', 'France')`)
+		require.NoError(t, err)
+		return path
+	}
+	// writeOrders writes orders.csv into dir
+	writeOrders := func(t *testing.T, dir string) string {
+		t.Helper()
+
+		path := filepath.Join(dir, "orders.csv")
+		require.NoError(t, os.WriteFile(path, []byte("id,country\n1,JP\n2,FR\n3,JP\n"), 0600))
+		return path
+	}
+
+	t.Run("loaded tables join the tables of the database", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		db := openTestDB(t, NewBuilder().AddPath(writeOrders(t, dir)).AddSQLiteDB(writeDatabase(t, dir, "reference.db")))
+
+		var name string
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT c.name FROM orders o JOIN reference.countries c ON o.country = c.code WHERE o.id = 2").Scan(&name))
+		assert.Equal(t, "France", name)
+		var n int
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT COUNT(*) FROM orders o JOIN countries c ON o.country = c.code WHERE c.name = 'Japan'").Scan(&n))
+		assert.Equal(t, 2, n)
+
+		// The database is attached read-only and is not a loaded table
+		_, err := db.ExecContext(context.Background(), "INSERT INTO reference.countries VALUES ('DE', 'Germany')")
+		require.Error(t, err)
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders"}, tables)
+	})
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "the schema name can be chosen",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := t.TempDir()
+				return NewBuilder().AddPath(writeOrders(t, dir)).AddSQLiteDBAs(writeDatabase(t, dir, "master-data.sqlite"), "master")
+			},
+			want: map[string][]string{"SELECT COUNT(*) FROM master.countries": {"2"}},
+		},
+		{
+			name: "tables of the same name fail Open",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := t.TempDir()
+				csvPath := filepath.Join(dir, "countries.csv")
+				require.NoError(t, os.WriteFile(csvPath, []byte("code\nJP\n"), 0600))
+				return NewBuilder().AddPath(csvPath).AddSQLiteDB(writeDatabase(t, dir, "reference.db"))
+			},
+			wantErr: ErrCodeDuplicateTable,
+		},
+		{
+			name: "a missing database fails the build",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := t.TempDir()
+				return NewBuilder().AddPath(writeOrders(t, dir)).AddSQLiteDB(filepath.Join(dir, "missing.db"))
+			},
+			wantErr: ErrCodePathNotFound,
+		},
+		{
+			name: "files other than SQLite databases fail the build",
+			builder: func(t *testing.T) *DBBuilder {
+				orders := writeOrders(t, t.TempDir())
+				return NewBuilder().AddPath(orders).AddSQLiteDB(orders)
+			},
+			wantErr:    ErrCodeInvalidConfig,
+			wantErrMsg: "not a SQLite database",
+		},
+		{
+			name: "schema names must be identifiers",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := t.TempDir()
+				return NewBuilder().AddPath(writeOrders(t, dir)).AddSQLiteDBAs(writeDatabase(t, dir, "reference.db"), "my-ref")
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name: "schema names cannot be main",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := t.TempDir()
+				return NewBuilder().AddPath(writeOrders(t, dir)).AddSQLiteDBAs(writeDatabase(t, dir, "reference.db"), "main")
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name: "schema names cannot be used twice",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := t.TempDir()
+				reference := writeDatabase(t, dir, "reference.db")
+				return NewBuilder().AddPath(writeOrders(t, dir)).AddSQLiteDB(reference).AddSQLiteDBAs(reference, "Reference")
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+	})
+}
@@ -11,10 +11,13 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/andybalholm/brotli"
 	"github.com/apache/arrow/go/v18/arrow/array"
+	"github.com/apache/arrow/go/v18/parquet"
 	pqfile "github.com/apache/arrow/go/v18/parquet/file"
 	"github.com/apache/arrow/go/v18/parquet/pqarrow"
 	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 	"github.com/ulikunitz/xz"
 	"github.com/xuri/excelize/v2"
 )
@@ -54,6 +57,10 @@ func (p *streamingParser) parseFromReader(reader io.Reader) (*table, error) {
 	if closeFunc != nil {
 		defer handleCloseError(closeFunc)
 	}
+	decompressedReader, err = p.decodeText(decompressedReader)
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse based on base file type
 	baseType := p.fileType.baseType()
@@ -62,12 +69,28 @@ func (p *streamingParser) parseFromReader(reader io.Reader) (*table, error) {
 		return p.parseCSVStream(decompressedReader)
 	case FileTypeTSV:
 		return p.parseTSVStream(decompressedReader)
+	case FileTypeSSV:
+		return p.parseDelimitedStream(decompressedReader, ssvDelimiter, "SSV")
+	case FileTypePSV:
+		return p.parseDelimitedStream(decompressedReader, psvDelimiter, "PSV")
+	case FileTypeDelimited:
+		return p.parseDelimitedStream(decompressedReader, csvDelimiter, "delimited")
 	case FileTypeLTSV:
 		return p.parseLTSVStream(decompressedReader)
 	case FileTypeParquet:
 		return p.parseParquetStream(decompressedReader)
 	case FileTypeXLSX:
 		return p.parseXLSXStream(decompressedReader)
+	case FileTypeXLS:
+		return p.parseXLSStream(decompressedReader)
+	case FileTypeODS:
+		return p.parseODSStream(decompressedReader)
+	case FileTypeJSONL:
+		return p.parseJSONLStream(decompressedReader)
+	case FileTypeZIP:
+		return p.parseZIPStream(decompressedReader)
+	case FileTypeTAR:
+		return p.parseTARStream(decompressedReader)
 	default:
 		return nil, errors.New("unsupported file type")
 	}
@@ -76,31 +99,37 @@ func (p *streamingParser) parseFromReader(reader io.Reader) (*table, error) {
 // createDecompressedReader creates appropriate reader based on compression type
 func (p *streamingParser) createDecompressedReader(reader io.Reader) (io.Reader, func() error, error) {
 	switch p.fileType {
-	case FileTypeCSVGZ, FileTypeTSVGZ, FileTypeLTSVGZ, FileTypeXLSXGZ:
+	case FileTypeCSVGZ, FileTypeTSVGZ, FileTypeSSVGZ, FileTypePSVGZ, FileTypeLTSVGZ, FileTypeXLSXGZ, FileTypeXLSGZ, FileTypeODSGZ, FileTypeJSONLGZ, FileTypeTARGZ:
 		gzReader, err := gzip.NewReader(reader)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create gzip reader: %w", err)
 		}
 		return gzReader, gzReader.Close, nil
 
-	case FileTypeCSVBZ2, FileTypeTSVBZ2, FileTypeLTSVBZ2, FileTypeXLSXBZ2:
+	case FileTypeCSVBZ2, FileTypeTSVBZ2, FileTypeSSVBZ2, FileTypePSVBZ2, FileTypeLTSVBZ2, FileTypeXLSXBZ2, FileTypeXLSBZ2, FileTypeODSBZ2, FileTypeJSONLBZ2, FileTypeTARBZ2:
 		bz2Reader := bzip2.NewReader(reader)
 		return bz2Reader, nil, nil
 
-	case FileTypeCSVXZ, FileTypeTSVXZ, FileTypeLTSVXZ, FileTypeXLSXXZ:
+	case FileTypeCSVXZ, FileTypeTSVXZ, FileTypeSSVXZ, FileTypePSVXZ, FileTypeLTSVXZ, FileTypeXLSXXZ, FileTypeXLSXZ, FileTypeODSXZ, FileTypeJSONLXZ, FileTypeTARXZ:
 		xzReader, err := xz.NewReader(reader)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create xz reader: %w", err)
 		}
 		return xzReader, nil, nil
 
-	case FileTypeCSVZSTD, FileTypeTSVZSTD, FileTypeLTSVZSTD, FileTypeXLSXZSTD:
+	case FileTypeCSVZSTD, FileTypeTSVZSTD, FileTypeSSVZSTD, FileTypePSVZSTD, FileTypeLTSVZSTD, FileTypeXLSXZSTD, FileTypeXLSZSTD, FileTypeODSZSTD, FileTypeJSONLZSTD, FileTypeTARZSTD:
 		decoder, err := zstd.NewReader(reader)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create zstd reader: %w", err)
 		}
 		return decoder, func() error { decoder.Close(); return nil }, nil
 
+	case FileTypeCSVLZ4, FileTypeTSVLZ4, FileTypeSSVLZ4, FileTypePSVLZ4, FileTypeLTSVLZ4, FileTypeXLSXLZ4, FileTypeXLSLZ4, FileTypeODSLZ4, FileTypeJSONLLZ4, FileTypeTARLZ4:
+		return lz4.NewReader(reader), nil, nil
+
+	case FileTypeCSVBR, FileTypeTSVBR, FileTypeSSVBR, FileTypePSVBR, FileTypeLTSVBR, FileTypeXLSXBR, FileTypeXLSBR, FileTypeODSBR, FileTypeJSONLBR, FileTypeTARBR:
+		return brotli.NewReader(reader), nil, nil
+
 	default:
 		// No compression
 		return reader, nil, nil
@@ -109,20 +138,42 @@ func (p *streamingParser) createDecompressedReader(reader io.Reader) (io.Reader,
 
 // parseDelimitedStream parses CSV or TSV data from reader using streaming approach
 func (p *streamingParser) parseDelimitedStream(reader io.Reader, delimiter rune, fileTypeName string) (*table, error) {
+	if p.delimiter != 0 {
+		delimiter = p.delimiter
+	}
+	reader, _, err := skipSchemaComments(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fileTypeName, err)
+	}
+	var nulls *nullFieldTracker
+	if p.nullConvention == NullConventionQuotedEmpty {
+		nulls = newNullFieldTracker(reader, delimiter, !p.noHeader)
+		reader = nulls
+	}
 	csvReader := csv.NewReader(reader)
 	csvReader.Comma = delimiter
 	records, err := csvReader.ReadAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s: %w", fileTypeName, err)
 	}
+	for _, record := range records {
+		nulls.markNulls(record)
+	}
 
 	if len(records) == 0 {
 		return nil, fmt.Errorf("empty %s data", fileTypeName)
 	}
 
+	if p.noHeader {
+		names, err := p.headerlessColumnNames(len(records[0]))
+		if err != nil {
+			return nil, err
+		}
+		records = append([][]string{names}, records...)
+	}
 	header := newHeader(records[0])
 	// Check for duplicate column names
-	if err := validateColumnNames(records[0]); err != nil {
+	if err := p.validateHeader(records[0]); err != nil {
 		return nil, err
 	}
 
@@ -220,6 +271,10 @@ func (p *streamingParser) ProcessInChunks(reader io.Reader, processor chunkProce
 	if closeFunc != nil {
 		defer handleCloseError(closeFunc)
 	}
+	decompressedReader, err = p.decodeText(decompressedReader)
+	if err != nil {
+		return err
+	}
 
 	// Parse based on base file type
 	baseType := p.fileType.baseType()
@@ -228,12 +283,28 @@ func (p *streamingParser) ProcessInChunks(reader io.Reader, processor chunkProce
 		return p.processCSVInChunks(decompressedReader, processor)
 	case FileTypeTSV:
 		return p.processTSVInChunks(decompressedReader, processor)
+	case FileTypeSSV:
+		return p.processDelimitedInChunks(decompressedReader, processor, ssvDelimiter, "SSV")
+	case FileTypePSV:
+		return p.processDelimitedInChunks(decompressedReader, processor, psvDelimiter, "PSV")
+	case FileTypeDelimited:
+		return p.processDelimitedInChunks(decompressedReader, processor, csvDelimiter, "delimited")
 	case FileTypeLTSV:
 		return p.processLTSVInChunks(decompressedReader, processor)
 	case FileTypeParquet:
 		return p.processParquetInChunks(decompressedReader, processor)
 	case FileTypeXLSX:
 		return p.processXLSXInChunks(decompressedReader, processor)
+	case FileTypeXLS:
+		return p.processXLSInChunks(decompressedReader, processor)
+	case FileTypeODS:
+		return p.processODSInChunks(decompressedReader, processor)
+	case FileTypeJSONL:
+		return p.processJSONLInChunks(decompressedReader, processor)
+	case FileTypeZIP:
+		return p.processZIPInChunks(decompressedReader, processor)
+	case FileTypeTAR:
+		return p.processTARInChunks(decompressedReader, processor)
 	default:
 		return errors.New("unsupported file type for chunked processing")
 	}
@@ -241,6 +312,41 @@ func (p *streamingParser) ProcessInChunks(reader io.Reader, processor chunkProce
 
 // processDelimitedInChunks processes CSV or TSV data in chunks based on delimiter
 func (p *streamingParser) processDelimitedInChunks(reader io.Reader, processor chunkProcessor, delimiter rune, fileTypeName string) error {
+	if p.delimiter != 0 {
+		delimiter = p.delimiter
+	}
+	if p.sidecar != nil && p.sidecar.index != nil {
+		return p.processIndexedInChunks(processor, delimiter, fileTypeName)
+	}
+	var recorded *sidecarIndex
+	if p.sidecar != nil {
+		recorded = p.sidecar.recorded
+	}
+	// The offsets of the rows in the file include the skipped schema comments
+	reader, skipped, err := skipSchemaComments(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read %s data: %w", fileTypeName, err)
+	}
+
+	// A delimiter set with WithDelimiter is never replaced by a detected one
+	if p.detectDelimiter && p.delimiter == 0 {
+		sampled, detected, err := sniffDelimiter(reader, delimiter)
+		if err != nil {
+			return err
+		}
+		if detected != delimiter && p.delimiterDetected != nil {
+			if err := p.delimiterDetected(detected, delimiter); err != nil {
+				return err
+			}
+		}
+		reader, delimiter = sampled, detected
+	}
+	var nulls *nullFieldTracker
+	if p.nullConvention == NullConventionQuotedEmpty {
+		nulls = newNullFieldTracker(reader, delimiter, !p.noHeader)
+		reader = nulls
+	}
+
 	csvReader := csv.NewReader(reader)
 	if delimiter != csvDelimiter {
 		csvReader.Comma = delimiter
@@ -254,11 +360,44 @@ func (p *streamingParser) processDelimitedInChunks(reader io.Reader, processor c
 		}
 		return fmt.Errorf("failed to read %s header: %w", fileTypeName, err)
 	}
+	nulls.markNulls(headerrecord)
+
+	// Rows read while detecting the header that are loaded as data
+	var pending [][]string
+	if p.noHeader {
+		names, err := p.headerlessColumnNames(len(headerrecord))
+		if err != nil {
+			return err
+		}
+		pending = append(pending, headerrecord)
+		headerrecord = names
+	} else if p.headerDetected != nil {
+		second, err := csvReader.Read()
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read %s record: %w", fileTypeName, err)
+		}
+		nulls.markNulls(second)
+		isHeader := looksLikeHeader(headerrecord, second)
+		if err := p.headerDetected(isHeader, len(headerrecord)); err != nil {
+			return err
+		}
+		if !isHeader {
+			pending = append(pending, headerrecord)
+			headerrecord = generatedColumnNames(len(headerrecord))
+		}
+		if second != nil {
+			pending = append(pending, second)
+		}
+	}
 
 	// Validate header for duplicates
-	if err := validateColumnNames(headerrecord); err != nil {
+	if err := p.validateHeader(headerrecord); err != nil {
 		return err
 	}
+	if recorded != nil {
+		recorded.Delimiter = string(delimiter)
+		recorded.Header = headerrecord
+	}
 
 	header := newHeader(headerrecord)
 	var columnInfo columnInfoList
@@ -272,14 +411,25 @@ func (p *streamingParser) processDelimitedInChunks(reader io.Reader, processor c
 	}
 
 	for {
-		record, err := csvReader.Read()
-		if err != nil {
+		offset := skipped + csvReader.InputOffset()
+		var record []string
+		if len(pending) > 0 {
+			record, pending = pending[0], pending[1:]
+		} else if record, err = csvReader.Read(); err != nil {
 			if err == io.EOF {
 				break
 			}
 			return fmt.Errorf("failed to read %s record: %w", fileTypeName, err)
+		} else {
+			nulls.markNulls(record)
 		}
 
+		if recorded != nil {
+			if len(chunkrecords) == 0 {
+				recorded.Offsets = append(recorded.Offsets, offset)
+			}
+			recorded.Rows++
+		}
 		chunkrecords = append(chunkrecords, newRecord(record))
 
 		// Collect values for type inference (only on first chunk)
@@ -299,6 +449,9 @@ func (p *streamingParser) processDelimitedInChunks(reader io.Reader, processor c
 			// Infer column types on first chunk
 			if len(columnInfo) == 0 {
 				columnInfo = newColumnInfoListFromValues(header, columnValues)
+				if recorded != nil {
+					recorded.recordColumns(columnInfo)
+				}
 			}
 
 			chunk := &tableChunk{
@@ -318,11 +471,15 @@ func (p *streamingParser) processDelimitedInChunks(reader io.Reader, processor c
 		}
 	}
 
-	// Process remaining records
-	if len(chunkrecords) > 0 {
+	// Process remaining records. A header-only input is processed as a chunk without
+	// records so that the table is created with its columns.
+	if len(chunkrecords) > 0 || len(columnInfo) == 0 {
 		// Infer column types if we haven't yet (small dataset)
 		if len(columnInfo) == 0 {
 			columnInfo = newColumnInfoListFromValues(header, columnValues)
+			if recorded != nil {
+				recorded.recordColumns(columnInfo)
+			}
 		}
 
 		chunk := &tableChunk{
@@ -564,21 +721,38 @@ func (p *streamingParser) parseParquetStream(reader io.Reader) (*table, error) {
 
 // processParquetInChunks processes Parquet data in chunks
 func (p *streamingParser) processParquetInChunks(reader io.Reader, processor chunkProcessor) error {
-	// Read all data into memory (Parquet requires random access)
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return fmt.Errorf("failed to read parquet data: %w", err)
-	}
+	// Parquet requires random access: spool to a temporary file if configured,
+	// otherwise read all data into memory
+	var source parquet.ReaderAtSeeker
+	if p.tempDir != "" {
+		tempFile, cleanup, err := spoolToTempFile(p.tempDir, reader)
+		if err != nil {
+			return fmt.Errorf("failed to spool parquet data: %w", err)
+		}
+		defer cleanup()
 
-	if len(data) == 0 {
-		return errors.New("empty parquet file")
-	}
+		info, err := tempFile.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat parquet data: %w", err)
+		}
+		if info.Size() == 0 {
+			return errors.New("empty parquet file")
+		}
+		source = tempFile
+	} else {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read parquet data: %w", err)
+		}
 
-	// Create a bytes reader for the parquet data
-	bytesReader := &bytesReaderAt{data: data}
+		if len(data) == 0 {
+			return errors.New("empty parquet file")
+		}
+		source = &bytesReaderAt{data: data}
+	}
 
-	// Create parquet file reader from bytes
-	pqReader, err := pqfile.NewParquetReader(bytesReader)
+	// Create parquet file reader
+	pqReader, err := pqfile.NewParquetReader(source)
 	if err != nil {
 		return fmt.Errorf("failed to create parquet reader from bytes: %w", err)
 	}
@@ -598,10 +772,6 @@ func (p *streamingParser) processParquetInChunks(reader io.Reader, processor chu
 	}
 	defer table.Release()
 
-	if table.NumRows() == 0 {
-		return errors.New("no records found in parquet stream")
-	}
-
 	// Initialize header from table schema
 	schema := table.Schema()
 	headerSlice := make(header, schema.NumFields())
@@ -623,6 +793,20 @@ func (p *streamingParser) processParquetInChunks(reader io.Reader, processor chu
 		chunkSize = DefaultRowsPerChunk
 	}
 
+	// A Parquet file without rows is processed as a chunk without records so that
+	// the table is created with the columns of the schema
+	if table.NumRows() == 0 {
+		chunk := &tableChunk{
+			tableName:  p.tableName,
+			headers:    headerSlice,
+			columnInfo: columnInfoList,
+		}
+		if err := processor(chunk); err != nil {
+			return fmt.Errorf("chunk processor error: %w", err)
+		}
+		return nil
+	}
+
 	tableReader := array.NewTableReader(table, int64(chunkSize))
 	defer tableReader.Release()
 
@@ -730,7 +914,7 @@ func (p *streamingParser) parseXLSXStream(reader io.Reader) (*table, error) {
 		}
 		if first {
 			// Duplicate header check (parity with CSV/TSV)
-			if err := validateColumnNames(row); err != nil {
+			if err := p.validateHeader(row); err != nil {
 				return nil, err
 			}
 			headers = newHeader(row)
@@ -755,7 +939,7 @@ func (p *streamingParser) processXLSXInChunks(reader io.Reader, processor chunkP
 	}
 
 	// Open XLSX file from reader
-	xlsxFile, err := excelize.OpenReader(reader)
+	xlsxFile, err := excelize.OpenReader(reader, xlsxOpenOptions(p.keepInMemory)...)
 	if err != nil {
 		return fmt.Errorf("failed to open XLSX file: %w", err)
 	}
@@ -839,7 +1023,7 @@ func (p *streamingParser) processXLSXInChunks(reader io.Reader, processor chunkP
 
 		if first {
 			// Validate headers for duplicates
-			if err := validateColumnNames(row); err != nil {
+			if err := p.validateHeader(row); err != nil {
 				return err
 			}
 			headers = newHeader(row)
@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 
 	"github.com/xuri/excelize/v2"
@@ -17,6 +16,93 @@ import (
 // streamProcessor handles streaming operations for database loading
 type streamProcessor struct {
 	chunkSize int
+	// observer is notified about loading progress; nil disables notifications
+	observer loadObserver
+	// stageTables loads each table under a staging name and renames it once complete
+	stageTables bool
+	// columnOverflow stores columns beyond SQLite's column limit in a JSON column
+	columnOverflow bool
+	// truncateCells truncates values larger than SQLite's value size limit
+	truncateCells bool
+	// maxCellBytes overrides SQLite's value size limit; 0 uses maxSQLiteLength
+	maxCellBytes int
+	// detectExcelTables splits Excel sheets into one table per blank-row separated region
+	detectExcelTables bool
+	// headerMapping renames source column names to the mapped names at load time
+	headerMapping map[string]string
+	// memoryLimit fails the load when heap usage exceeds it; nil disables the check
+	memoryLimit *MemoryLimit
+	// tempDir is the directory for temporary files; empty buffers inputs in memory
+	tempDir string
+	// noTempFiles forbids temporary files; large Excel worksheets are unzipped in memory
+	noTempFiles bool
+	// preserveNumericText creates numeric columns holding number-like identifiers as TEXT
+	preserveNumericText bool
+	// inputFS opens file path inputs; nil uses the operating system
+	inputFS FileSystem
+	// detectDelimiter detects the delimiter of CSV and TSV inputs from their content
+	detectDelimiter bool
+	// fileOptions maps the collected file paths to their input options, such as WithDelimiter
+	fileOptions map[string]inputOptions
+	// detectEncoding detects the character encoding of text inputs without an encoding
+	detectEncoding bool
+	// detectHeader decides whether the first row of CSV and TSV inputs is a header
+	detectHeader bool
+	// ignoreEmptyHeaders drops columns whose header is empty
+	ignoreEmptyHeaders bool
+	// skipColumns are the source names of columns that are dropped, compared case-insensitively
+	skipColumns []string
+	// droppedColumns maps lowercase table names to the source names of their columns that are dropped
+	droppedColumns map[string][]string
+	// defaultValues maps lowercase table names to the values loaded into their columns where values are missing
+	defaultValues map[string][]columnDefault
+	// timeWindows maps lowercase table names to the time windows their rows must fall into
+	timeWindows map[string][]timeWindow
+	// sidecarIndex loads CSV and TSV file paths with a sidecar index, writing it if needed
+	sidecarIndex bool
+	// typeInference selects how column types are inferred
+	typeInference TypeInference
+	// emptyNumericAsNull loads empty values of INTEGER and REAL columns as NULL
+	emptyNumericAsNull bool
+	// nullValues are the values loaded as NULL in every column
+	nullValues []string
+	// nullConvention tells NULL and empty strings apart in delimited inputs
+	nullConvention NullConvention
+	// columnNullValues maps lowercase table names to the values loaded as NULL in their columns
+	columnNullValues map[string][]columnNullValues
+	// emptyFileTables are the zero-byte files found in directories that are loaded as tables without rows
+	emptyFileTables map[string]bool
+	// bestEffort skips inputs that fail to load instead of failing the load
+	bestEffort bool
+	// tableNames maps collected file paths to the names of their tables set with WithTableName
+	tableNames map[string]string
+	// tableCollision controls what happens when an input produces a table that already exists
+	tableCollision TableCollisionPolicy
+	// unionTables are the lowercase names of the tables of AddPathsAsTable
+	unionTables map[string]bool
+	// firstChunkOnly stops loading each table after its first chunk of rows, for previews
+	firstChunkOnly bool
+}
+
+// errFirstChunkLoaded stops the parser once the first chunk of a table is loaded with firstChunkOnly
+var errFirstChunkLoaded = errors.New("first chunk loaded")
+
+// stagingTablePrefix is prepended to table names while they are being loaded in staging mode
+const stagingTablePrefix = "_filesql_loading_"
+
+// loadObserver receives notifications while inputs are streamed into the database.
+// Implementations must be safe for use from the loading goroutine.
+type loadObserver interface {
+	// inputStarted is called before a file path or reader input starts loading
+	inputStarted(input string)
+	// bytesRead is called after n bytes have been read from the current input
+	bytesRead(n int)
+	// rowsInserted is called after a chunk of rows has been inserted into table
+	rowsInserted(table string, rows int)
+	// tableLoaded is called once table has been completely loaded
+	tableLoaded(table string)
+	// inputFinished is called after a file path or reader input has been loaded
+	inputFinished(input string)
 }
 
 // newStreamProcessor creates a new stream processor instance
@@ -26,45 +112,142 @@ func newStreamProcessor(chunkSize int) *streamProcessor {
 	}
 }
 
-// streamAllFilesToDatabase streams all collected file paths to the database
+// withObserver returns a copy of the stream processor that reports to observer
+func (sp *streamProcessor) withObserver(observer loadObserver) *streamProcessor {
+	clone := *sp
+	clone.observer = observer
+	return &clone
+}
+
+// withStaging returns a copy of the stream processor that publishes tables only once
+// they are completely loaded, so concurrent readers never observe partial tables
+func (sp *streamProcessor) withStaging() *streamProcessor {
+	clone := *sp
+	clone.stageTables = true
+	return &clone
+}
+
+// loadingTableName returns the name a table is created under while it is being loaded
+func (sp *streamProcessor) loadingTableName(tableName string) string {
+	if !sp.stageTables {
+		return tableName
+	}
+	return stagingTablePrefix + tableName
+}
+
+// publishTable renames a staged table to its final name once loading has finished, or
+// appends its rows to the table of that name with TableCollisionMerge and AddPathsAsTable
+func (sp *streamProcessor) publishTable(ctx context.Context, db *sql.DB, loadingName, tableName string) error {
+	if loadingName == tableName {
+		return nil
+	}
+	if sp.mergesInto(tableName) {
+		exists, err := tableExists(ctx, db, tableName)
+		if err != nil {
+			return err
+		}
+		if exists && sp.isUnionTable(tableName) {
+			if err := checkUnionColumns(ctx, db, loadingName, tableName); err != nil {
+				return err
+			}
+		}
+		if exists {
+			return appendTable(ctx, db, loadingName, tableName)
+		}
+	}
+	query := fmt.Sprintf(`ALTER TABLE "%s" RENAME TO "%s"`, loadingName, tableName)
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to publish table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// streamAllFilesToDatabase streams all collected file paths to the database. Every
+// path is tried; the errors of the paths that fail are returned joined (see WithBestEffort).
 func (sp *streamProcessor) streamAllFilesToDatabase(ctx context.Context, db *sql.DB, collectedPaths []string) error {
+	loader := sp.newInputLoader(db)
 	for _, path := range collectedPaths {
-		if err := sp.streamFileToDatabase(ctx, db, path); err != nil {
-			return fmt.Errorf("failed to stream file %s: %w", path, err)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if sp.observer != nil {
+			sp.observer.inputStarted(path)
+		}
+		if err := loader.load(ctx, path, sp.fileTableName(path),
+			func() error { return sp.streamFileToDatabase(ctx, db, path) },
+			func(err error) error { return fmt.Errorf("failed to stream file %s: %w", path, err) },
+		); err != nil {
+			return err
+		}
+		if sp.observer != nil {
+			sp.observer.inputFinished(path)
 		}
 	}
-	return nil
+	return loader.err()
 }
 
-// streamAllReadersToDatabase streams all reader inputs to the database
+// streamAllReadersToDatabase streams all reader inputs to the database. Every input is
+// tried; the errors of the inputs that fail are returned joined (see WithBestEffort).
 func (sp *streamProcessor) streamAllReadersToDatabase(ctx context.Context, db *sql.DB, readers []readerInput) error {
+	loader := sp.newInputLoader(db)
 	for _, readerInput := range readers {
-		if err := sp.streamReaderToDatabase(ctx, db, readerInput); err != nil {
-			return fmt.Errorf("failed to stream reader input for table '%s': %w", readerInput.tableName, err)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if sp.observer != nil {
+			sp.observer.inputStarted(readerInput.tableName)
+		}
+		if err := loader.load(ctx, readerInput.tableName, readerInput.tableName,
+			func() error { return sp.streamInputToDatabase(ctx, db, readerInput) },
+			func(err error) error {
+				return fmt.Errorf("failed to stream reader input for table '%s': %w", readerInput.tableName, err)
+			},
+		); err != nil {
+			return err
+		}
+		if sp.observer != nil {
+			sp.observer.inputFinished(readerInput.tableName)
 		}
 	}
-	return nil
+	return loader.err()
+}
+
+// streamInputToDatabase streams a reader input, downloading remote inputs with the
+// context of the load and closing their connection afterwards
+func (sp *streamProcessor) streamInputToDatabase(ctx context.Context, db *sql.DB, readerInput readerInput) error {
+	if remote, ok := readerInput.reader.(*remoteReader); ok {
+		if err := remote.start(ctx); err != nil {
+			return err
+		}
+		defer remote.Close()
+	}
+	readerInput.reader = sp.countBytes(readerInput.reader)
+	return sp.streamReaderToDatabase(ctx, db, readerInput)
 }
 
 // streamFileToDatabase streams data from a file path directly to SQLite database using chunked processing
 func (sp *streamProcessor) streamFileToDatabase(ctx context.Context, db *sql.DB, filePath string) error {
 	// Check if file is supported
 	if !isSupportedFile(filePath) {
-		return fmt.Errorf("unsupported file type: %s", filePath)
+		return newCodedError(ErrCodeUnsupportedFormat, "unsupported file type: %s", filePath)
 	}
 
 	// Open the file and create a reader
-	file, err := os.Open(filePath) //nolint:gosec // File path is validated and comes from user input
+	file, err := sp.fileSystem().Open(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
 	// Check if file is empty before processing
-	if fileInfo, err := file.Stat(); err != nil {
+	fileInfo, err := file.Stat()
+	if err != nil {
 		return fmt.Errorf("failed to get file info for %s: %w", filePath, err)
 	} else if fileInfo.Size() == 0 {
-		return errors.New("file is empty")
+		if sp.emptyFileTables[filePath] {
+			return sp.createEmptyFileTable(ctx, db, filePath)
+		}
+		return newCodedError(ErrCodeEmptyData, "file is empty")
 	}
 
 	// Create file model to determine type and table name
@@ -85,18 +268,32 @@ func (sp *streamProcessor) streamFileToDatabase(ctx context.Context, db *sql.DB,
 		}
 	}()
 
-	// Handle XLSX files specially - each sheet becomes a separate table
-	if baseFileType == FileTypeXLSX {
-		return sp.streamXLSXFileToDatabase(ctx, db, reader, filePath)
+	reader = sp.countBytes(reader)
+
+	// Handle workbooks specially - each sheet becomes a separate table
+	if isWorkbook(baseFileType) {
+		return sp.streamWorkbookToDatabase(ctx, db, reader, filePath, baseFileType)
+	}
+	// Each data file of an archive becomes a separate table
+	if isArchive(baseFileType) {
+		return sp.streamArchiveToDatabase(ctx, db, reader, filePath)
 	}
 
 	// Create reader input for streaming
 	readerInput := readerInput{
 		reader:    reader, // Use decompressed reader
-		tableName: tableFromFilePath(filePath),
+		tableName: sp.fileTableName(filePath),
 		fileType:  baseFileType,
+		options:   sp.fileOptions[filePath],
 	}
-	return sp.streamReaderToDatabase(ctx, db, readerInput)
+	if !sp.usesSidecarIndex(filePath) {
+		return sp.streamReaderToDatabase(ctx, db, readerInput)
+	}
+	readerInput.sidecar = sp.prepareSidecarLoad(filePath, fileInfo)
+	if err := sp.streamReaderToDatabase(ctx, db, readerInput); err != nil {
+		return err
+	}
+	return sp.saveSidecarIndex(ctx, db, filePath, readerInput.sidecar)
 }
 
 // streamReaderToDatabase streams data from io.Reader directly to SQLite database
@@ -107,28 +304,62 @@ func (sp *streamProcessor) streamReaderToDatabase(ctx context.Context, db *sql.D
 	}
 
 	// Check if table already exists to avoid duplicates
-	var tableExists int
-	err := db.QueryRowContext(ctx,
-		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
-		input.tableName,
-	).Scan(&tableExists)
+	tableName, loadingName, err := sp.claimTable(ctx, db, input.tableName)
 	if err != nil {
-		return fmt.Errorf("failed to check table existence: %w", err)
-	}
-
-	if tableExists > 0 {
-		return fmt.Errorf("table '%s' already exists from another file, duplicate table names are not allowed", input.tableName)
+		return err
 	}
+	input.tableName = loadingName
 
 	// Create streaming parser for chunked processing
 	parser := newStreamingParser(input.fileType, input.tableName, sp.chunkSize)
+	parser.tempDir = sp.tempDir
+	parser.keepInMemory = sp.keepInMemory()
+	parser.detectDelimiter = sp.detectDelimiter
+	parser.delimiter = input.options.delimiter
+	parser.encoding, _ = lookupEncoding(input.options.encoding) // Validated by Build
+	parser.detectEncoding = sp.detectEncoding
+	parser.encodingDetected = func(name string) error {
+		return recordLoadWarning(ctx, db, tableName, fmt.Sprintf("detected %s encoding, transcoded to UTF-8", name))
+	}
+	parser.noHeader = input.options.headerless()
+	parser.nullConvention = sp.nullConvention
+	parser.columnNames = input.options.columnNames
+	parser.dropsColumn = sp.columnDropper(input.tableName)
+	parser.sidecar = input.sidecar
+	parser.delimiterDetected = func(detected, expected rune) error {
+		return recordLoadWarning(ctx, db, tableName, fmt.Sprintf("detected %s delimiter instead of the %s delimiter of the file type",
+			delimiterName(detected), delimiterName(expected)))
+	}
+	if sp.detectHeader {
+		parser.headerDetected = func(isHeader bool, columns int) error {
+			return recordLoadWarning(ctx, db, tableName, headerDecision(isHeader, columns))
+		}
+	}
 
 	// Initialize the table schema (we need to peek at the first chunk to get headers)
 	var tableCreated bool
 	var insertStmt *sql.Stmt
+	var tableColumns []columnInfo
+	var scan *typeScan
+	recordNumber := 1
 
 	// Process data in chunks
 	err = parser.ProcessInChunks(input.reader, func(chunk *tableChunk) error {
+		chunk, err := sp.prepareChunk(chunk, recordNumber)
+		if err != nil {
+			return err
+		}
+		recordNumber += len(chunk.getRecords())
+
+		// With a full scan, load TEXT columns and convert them once every value is known
+		if sp.typeInference == TypeInferenceFullScan {
+			if scan == nil {
+				scan = newTypeScan(chunk)
+			}
+			scan.add(chunk, sp.nullValueSets(chunk.getTableName(), chunk.getColumnInfo()))
+			chunk = withTextColumns(chunk)
+		}
+
 		// Create table on first chunk
 		if !tableCreated {
 			if err := sp.createTableFromChunk(ctx, db, chunk); err != nil {
@@ -142,23 +373,35 @@ func (sp *streamProcessor) streamReaderToDatabase(ctx context.Context, db *sql.D
 				return fmt.Errorf("failed to prepare insert statement: %w", err)
 			}
 
+			tableColumns = chunk.getColumnInfo()
 			tableCreated = true
 		}
 
 		// Insert chunk data
-		if err := sp.insertChunkData(ctx, insertStmt, chunk); err != nil {
+		if err := sp.insertChunkData(ctx, insertStmt, chunk, tableColumns); err != nil {
 			return fmt.Errorf("failed to insert chunk data: %w", err)
 		}
+		if sp.observer != nil {
+			sp.observer.rowsInserted(chunk.getTableName(), len(chunk.getRecords()))
+		}
+		if sp.firstChunkOnly {
+			return errFirstChunkLoaded
+		}
 
 		return nil
 	})
+	if errors.Is(err, errFirstChunkLoaded) {
+		err = nil
+	}
 
 	// Handle header-only files: if no data chunks were processed, create empty table
 	if !tableCreated {
 		if err != nil {
 			// Preserve certain parsing errors that should not be converted to empty tables
 			if strings.Contains(err.Error(), "duplicate column name") ||
-				strings.Contains(err.Error(), "parse error") {
+				strings.Contains(err.Error(), "parse error") ||
+				errors.Is(err, ErrTooManyColumns) || errors.Is(err, ErrCellTooLarge) ||
+				errors.Is(err, ErrMemoryLimit) || ErrorCodeOf(err) == ErrCodeInvalidConfig {
 				return err
 			}
 			// For completely empty files (only newlines), propagate error instead of creating empty table
@@ -187,24 +430,60 @@ func (sp *streamProcessor) streamReaderToDatabase(ctx context.Context, db *sql.D
 		return fmt.Errorf("streaming processing failed: %w", err)
 	}
 
+	if scan != nil {
+		if err := sp.retypeTable(ctx, db, input.tableName, scan); err != nil {
+			return err
+		}
+	}
+	if err := sp.publishTable(ctx, db, input.tableName, tableName); err != nil {
+		return err
+	}
+	if sp.observer != nil {
+		sp.observer.tableLoaded(tableName)
+	}
 	return nil
 }
 
-// createTableFromChunk creates a SQLite table from a tableChunk
-func (sp *streamProcessor) createTableFromChunk(ctx context.Context, db *sql.DB, chunk *tableChunk) error {
-	columnInfo := chunk.getColumnInfo()
-	columns := make([]string, 0, len(columnInfo))
-	for _, col := range columnInfo {
-		columns = append(columns, fmt.Sprintf(`"%s" %s`, col.Name, col.Type.string()))
+// prepareChunk applies column dropping and renaming, null values, time windows, SQLite limit handling, and
+// type inference settings to a parsed chunk
+// before it is written to the database. firstRecord is the 1-based number of the
+// chunk's first record within its input.
+func (sp *streamProcessor) prepareChunk(chunk *tableChunk, firstRecord int) (*tableChunk, error) {
+	if sp.memoryLimit != nil && sp.memoryLimit.CheckMemoryUsage() == MemoryStatusExceeded {
+		return nil, fmt.Errorf("%w: %w", ErrMemoryLimit, sp.memoryLimit.CreateMemoryError("loading table "+chunk.getTableName()))
 	}
 
-	query := fmt.Sprintf(
-		`CREATE TABLE IF NOT EXISTS "%s" (%s)`,
-		chunk.getTableName(),
-		strings.Join(columns, ", "),
-	)
+	chunk, err := sp.dropColumns(chunk)
+	if err != nil {
+		return nil, err
+	}
+	chunk, err = sp.renameColumns(chunk)
+	if err != nil {
+		return nil, err
+	}
+	chunk = sp.fillDefaultValues(chunk)
+	chunk = sp.inferTypesWithoutNullValues(chunk)
+	chunk, err = sp.filterTimeWindows(chunk)
+	if err != nil {
+		return nil, err
+	}
+	chunk, err = sp.fitColumns(chunk)
+	if err != nil {
+		return nil, err
+	}
+	chunk = sp.preserveNumericTextColumns(chunk)
+	if sp.typeInference == TypeInferenceNone {
+		chunk = withTextColumns(chunk)
+	}
+	if err := sp.fitCellSizes(chunk, firstRecord); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
 
-	_, err := db.ExecContext(ctx, query)
+// createTableFromChunk creates a SQLite table from a tableChunk
+func (sp *streamProcessor) createTableFromChunk(ctx context.Context, db *sql.DB, chunk *tableChunk) error {
+	_, err := db.ExecContext(ctx, createTableQuery(chunk.getTableName(), chunk.getColumnInfo()))
 	return err
 }
 
@@ -225,15 +504,12 @@ func (sp *streamProcessor) prepareInsertStatement(ctx context.Context, db *sql.D
 	return db.PrepareContext(ctx, query)
 }
 
-// insertChunkData inserts a chunk's worth of data using a prepared statement
-func (sp *streamProcessor) insertChunkData(ctx context.Context, stmt *sql.Stmt, chunk *tableChunk) error {
+// insertChunkData inserts a chunk's worth of data using a prepared statement.
+// columns are the columns of the table the statement inserts into.
+func (sp *streamProcessor) insertChunkData(ctx context.Context, stmt *sql.Stmt, chunk *tableChunk, columns []columnInfo) error {
+	nulls := sp.nullValueSets(chunk.getTableName(), columns)
 	for _, record := range chunk.getRecords() {
-		values := make([]any, len(record))
-		for i, value := range record {
-			values[i] = value
-		}
-
-		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+		if _, err := stmt.ExecContext(ctx, sp.insertValues(record, columns, nulls)...); err != nil {
 			return fmt.Errorf("failed to insert record: %w", err)
 		}
 	}
@@ -245,6 +521,7 @@ func (sp *streamProcessor) insertChunkData(ctx context.Context, stmt *sql.Stmt,
 func (sp *streamProcessor) createEmptyTable(ctx context.Context, db *sql.DB, input readerInput) error {
 	// Parse just the header to get column information
 	tempParser := newStreamingParser(input.fileType, input.tableName, 1)
+	tempParser.dropsColumn = sp.columnDropper(input.tableName)
 	tempTable, err := tempParser.parseFromReader(input.reader)
 	if err != nil {
 		// Check if this is a parsing error we should preserve (like duplicate columns)
@@ -273,20 +550,17 @@ func (sp *streamProcessor) createEmptyTable(ctx context.Context, db *sql.DB, inp
 		}
 	}
 
-	// Create the table
-	columns := make([]string, 0, len(columnInfoList))
-	for _, col := range columnInfoList {
-		columns = append(columns, fmt.Sprintf(`"%s" %s`, col.Name, col.Type.string()))
+	chunk, err := sp.prepareChunk(&tableChunk{
+		tableName:  input.tableName,
+		headers:    headers,
+		columnInfo: columnInfoList,
+	}, 1)
+	if err != nil {
+		return err
 	}
 
-	query := fmt.Sprintf(
-		`CREATE TABLE IF NOT EXISTS "%s" (%s)`,
-		input.tableName,
-		strings.Join(columns, ", "),
-	)
-
-	_, err = db.ExecContext(ctx, query)
-	if err != nil {
+	// Create the table
+	if err := sp.createTableFromChunk(ctx, db, chunk); err != nil {
 		return fmt.Errorf("failed to create empty table: %w", err)
 	}
 
@@ -310,7 +584,7 @@ func (sp *streamProcessor) createTableFromHeaders(ctx context.Context, db *sql.D
 }
 
 // createDecompressedReader creates a reader that handles compression
-func (sp *streamProcessor) createDecompressedReader(file *os.File, filePath string) (io.Reader, func() error, error) {
+func (sp *streamProcessor) createDecompressedReader(file io.Reader, filePath string) (io.Reader, func() error, error) {
 	factory := NewCompressionFactory()
 	handler := factory.CreateHandlerForFile(filePath)
 
@@ -323,6 +597,24 @@ func (sp *streamProcessor) createDecompressedReader(file *os.File, filePath stri
 	return reader, cleanup, nil
 }
 
+// isWorkbook reports whether files of the base file type are workbooks with a table per sheet
+func isWorkbook(fileType FileType) bool {
+	return fileType == FileTypeXLSX || fileType == FileTypeXLS || fileType == FileTypeODS
+}
+
+// streamWorkbookToDatabase creates the tables of the sheets of the workbook (XLSX, XLS,
+// or ODS) at filePath
+func (sp *streamProcessor) streamWorkbookToDatabase(ctx context.Context, db *sql.DB, reader io.Reader, filePath string, fileType FileType) error {
+	switch fileType {
+	case FileTypeXLS:
+		return sp.streamXLSFileToDatabase(ctx, db, reader, filePath)
+	case FileTypeODS:
+		return sp.streamODSFileToDatabase(ctx, db, reader, filePath)
+	default:
+		return sp.streamXLSXFileToDatabase(ctx, db, reader, filePath)
+	}
+}
+
 // streamXLSXFileToDatabase handles XLSX files by creating separate tables for each sheet
 func (sp *streamProcessor) streamXLSXFileToDatabase(ctx context.Context, db *sql.DB, reader io.Reader, filePath string) error {
 	// Read all data into memory (XLSX requires random access)
@@ -336,7 +628,7 @@ func (sp *streamProcessor) streamXLSXFileToDatabase(ctx context.Context, db *sql
 	}
 
 	// Open XLSX file from bytes
-	xlsxFile, err := excelize.OpenReader(bytes.NewReader(data))
+	xlsxFile, err := excelize.OpenReader(bytes.NewReader(data), xlsxOpenOptions(sp.keepInMemory())...)
 	if err != nil {
 		return fmt.Errorf("failed to open XLSX file: %w", err)
 	}
@@ -351,7 +643,7 @@ func (sp *streamProcessor) streamXLSXFileToDatabase(ctx context.Context, db *sql
 	}
 
 	// Base table name from file path (sanitize to ensure a valid identifier)
-	baseTableName := sanitizeTableName(tableFromFilePath(filePath))
+	baseTableName := sanitizeTableName(sp.fileTableName(filePath))
 
 	// Process each sheet as a separate table
 	for _, sheetName := range sheetNames {
@@ -360,58 +652,122 @@ func (sp *streamProcessor) streamXLSXFileToDatabase(ctx context.Context, db *sql
 			return fmt.Errorf("failed to read sheet %s: %w", sheetName, err)
 		}
 
-		// Skip empty sheets
-		if len(rows) == 0 {
-			continue
+		if err := sp.loadSheet(ctx, db, baseTableName, sheetName, rows); err != nil {
+			return err
 		}
+	}
 
-		// Create table name: filename_sheetname
-		tableName := fmt.Sprintf("%s_%s", baseTableName, sanitizeTableName(sheetName))
+	return nil
+}
 
-		// Check if table already exists
-		var tableExists int
-		err = db.QueryRowContext(ctx,
-			`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
-			tableName,
-		).Scan(&tableExists)
-		if err != nil {
-			return fmt.Errorf("failed to check table existence: %w", err)
-		}
+// streamXLSFileToDatabase handles legacy Excel (.xls) files by creating separate tables for each sheet
+func (sp *streamProcessor) streamXLSFileToDatabase(ctx context.Context, db *sql.DB, reader io.Reader, filePath string) error {
+	sheets, err := readXLSSheets(reader)
+	if err != nil {
+		return err
+	}
+	return sp.loadSheets(ctx, db, filePath, sheets, "XLS")
+}
 
-		if tableExists > 0 {
-			return fmt.Errorf("table '%s' already exists from another file, duplicate table names are not allowed", tableName)
-		}
+// streamODSFileToDatabase handles OpenDocument spreadsheets (.ods) by creating separate tables for each sheet
+func (sp *streamProcessor) streamODSFileToDatabase(ctx context.Context, db *sql.DB, reader io.Reader, filePath string) error {
+	sheets, err := readODSSheets(reader)
+	if err != nil {
+		return err
+	}
+	return sp.loadSheets(ctx, db, filePath, sheets, "ODS")
+}
 
-		// Convert XLSX rows to table headers and records
-		headers, records := convertXLSXRowsToTable(rows)
+// loadSheets creates the tables of the sheets read from the workbook in format (XLS or ODS) at filePath
+func (sp *streamProcessor) loadSheets(ctx context.Context, db *sql.DB, filePath string, sheets []xlsSheet, format string) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("no sheets found in %s file", format)
+	}
 
-		// Create table chunk for processing
-		columnInfo := inferColumnsInfo(headers, records)
-		chunk := &tableChunk{
-			tableName:  tableName,
-			headers:    headers,
-			records:    records,
-			columnInfo: columnInfo,
-		}
+	// Base table name from file path (sanitize to ensure a valid identifier)
+	baseTableName := sanitizeTableName(sp.fileTableName(filePath))
 
-		// Create table and insert data
-		if err := sp.createTableFromChunk(ctx, db, chunk); err != nil {
-			return fmt.Errorf("failed to create table for sheet %s: %w", sheetName, err)
+	for _, sheet := range sheets {
+		if err := sp.loadSheet(ctx, db, baseTableName, sheet.name, sheet.rows); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		// Prepare and execute insert statement
-		insertStmt, err := sp.prepareInsertStatement(ctx, db, chunk)
-		if err != nil {
-			return fmt.Errorf("failed to prepare insert statement for sheet %s: %w", sheetName, err)
-		}
-		defer func() {
-			_ = insertStmt.Close() // Ignore close error
-		}()
+// loadSheet creates the "<file>_<sheet>" table (or one table per region with Excel
+// table detection) from the rows of a sheet. Empty sheets are skipped.
+func (sp *streamProcessor) loadSheet(ctx context.Context, db *sql.DB, baseTableName, sheetName string, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// Create table name: filename_sheetname
+	tableName := sheetTableName(baseTableName, sheetName)
 
-		if err := sp.insertChunkData(ctx, insertStmt, chunk); err != nil {
-			return fmt.Errorf("failed to insert data for sheet %s: %w", sheetName, err)
+	regions := [][][]string{rows}
+	if sp.detectExcelTables {
+		regions = splitXLSXRegions(rows)
+	}
+	for i, region := range regions {
+		regionTableName := tableName
+		if len(regions) > 1 {
+			regionTableName = fmt.Sprintf("%s_%d", tableName, i+1)
+		}
+		if err := sp.loadXLSXRows(ctx, db, sheetName, regionTableName, region); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+// loadXLSXRows creates a table from the rows of a sheet (or a region of a sheet).
+// The first row is used as the header.
+func (sp *streamProcessor) loadXLSXRows(ctx context.Context, db *sql.DB, sheetName, tableName string, rows [][]string) error {
+	// Check if table already exists
+	tableName, loadingName, err := sp.claimTable(ctx, db, tableName)
+	if err != nil {
+		return err
+	}
 
+	// Convert XLSX rows to table headers and records
+	headers, records := convertXLSXRowsToTable(rows)
+
+	// Create table chunk for processing
+	columnInfo := inferColumnsInfo(headers, records)
+	chunk, err := sp.prepareChunk(&tableChunk{
+		tableName:  loadingName,
+		headers:    headers,
+		records:    records,
+		columnInfo: columnInfo,
+	}, 1)
+	if err != nil {
+		return err
+	}
+
+	// Create table and insert data
+	if err := sp.createTableFromChunk(ctx, db, chunk); err != nil {
+		return fmt.Errorf("failed to create table for sheet %s: %w", sheetName, err)
+	}
+
+	// Prepare and execute insert statement
+	insertStmt, err := sp.prepareInsertStatement(ctx, db, chunk)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert statement for sheet %s: %w", sheetName, err)
+	}
+	defer func() {
+		_ = insertStmt.Close() // Ignore close error
+	}()
+
+	if err := sp.insertChunkData(ctx, insertStmt, chunk, chunk.getColumnInfo()); err != nil {
+		return fmt.Errorf("failed to insert data for sheet %s: %w", sheetName, err)
+	}
+	if err := sp.publishTable(ctx, db, chunk.getTableName(), tableName); err != nil {
+		return err
+	}
+	if sp.observer != nil {
+		sp.observer.rowsInserted(tableName, len(chunk.getRecords()))
+		sp.observer.tableLoaded(tableName)
+	}
 	return nil
 }
@@ -0,0 +1,263 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// surrogateKeysTableName is the metadata table that records the generated key columns
+const surrogateKeysTableName = "_filesql_surrogate_keys"
+
+// surrogateKeySuffix is appended to a column name to name its key column
+const surrogateKeySuffix = "_key"
+
+// KeyColumn is a column of a loaded table, used by WithSurrogateKey.
+type KeyColumn struct {
+	// Table is the name of the table
+	Table string
+	// Column is the name of the column
+	Column string
+}
+
+// surrogateKey is a mapping table shared by the key columns of several tables
+type surrogateKey struct {
+	// mappingTable is the name of the table that maps values to keys
+	mappingTable string
+	// columns are the text columns that get a key column
+	columns []KeyColumn
+}
+
+// WithSurrogateKey generates integer keys for text columns that are joined on.
+//
+// Joining large tables on long text values, such as UUIDs or product codes, is slow
+// in SQLite because every comparison reads and compares whole strings. With this
+// option, once all inputs are loaded, every distinct value of the columns is stored
+// once in mappingTable with an integer key, and each column gets an indexed INTEGER
+// column named after it with the suffix "_key" holding the key of its value. Values
+// that are equal in two columns get the same key, so joins can compare small integers
+// instead:
+//
+//	builder := filesql.NewBuilder().
+//		AddPaths("customers.csv", "orders.csv").
+//		WithSurrogateKey("customer_keys",
+//			filesql.KeyColumn{Table: "customers", Column: "customer_code"},
+//			filesql.KeyColumn{Table: "orders", Column: "customer_code"})
+//
+//	// SELECT c.name, SUM(o.total) FROM orders o
+//	// JOIN customers c ON o.customer_code_key = c.customer_code_key GROUP BY c.name
+//
+// mappingTable has the columns id (the key) and value. NULL values get a NULL key.
+// Triggers keep the keys of inserted and updated rows up to date. The key columns and
+// mapping tables are not written by DumpDatabase or auto-save, so saved files keep
+// their original columns.
+//
+// Build fails with an error with ErrCodeInvalidConfig if mappingTable or a column is
+// empty. Open fails with an error with ErrCodeInvalidConfig if a table or column does
+// not exist, and with ErrCodeDuplicateColumn if a table already has the key column.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithSurrogateKey(mappingTable string, columns ...KeyColumn) *DBBuilder {
+	b.surrogateKeys = append(b.surrogateKeys, surrogateKey{mappingTable: mappingTable, columns: columns})
+	return b
+}
+
+// validateSurrogateKeys checks the configured surrogate keys
+func validateSurrogateKeys(keys []surrogateKey) error {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		name := strings.ToLower(key.mappingTable)
+		if strings.TrimSpace(key.mappingTable) == "" {
+			return newCodedError(ErrCodeInvalidConfig, "surrogate key mapping table name cannot be empty")
+		}
+		if strings.HasPrefix(name, metadataTablePrefix) {
+			return newCodedError(ErrCodeInvalidConfig, "surrogate key mapping table name cannot start with %s: %s", metadataTablePrefix, key.mappingTable)
+		}
+		if seen[name] {
+			return newCodedError(ErrCodeInvalidConfig, "duplicate surrogate key mapping table: %s", key.mappingTable)
+		}
+		seen[name] = true
+		if len(key.columns) == 0 {
+			return newCodedError(ErrCodeInvalidConfig, "surrogate key %s has no columns", key.mappingTable)
+		}
+		for _, column := range key.columns {
+			if strings.TrimSpace(column.Table) == "" || strings.TrimSpace(column.Column) == "" {
+				return newCodedError(ErrCodeInvalidConfig, "surrogate key %s has a column without a table or column name", key.mappingTable)
+			}
+		}
+	}
+	return nil
+}
+
+// createSurrogateKeys creates the mapping tables and key columns of every configured surrogate key
+func (b *DBBuilder) createSurrogateKeys(ctx context.Context, db *sql.DB) error {
+	if len(b.surrogateKeys) == 0 {
+		return nil
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS "%s" (mapping_table TEXT, source_table TEXT, source_column TEXT, key_column TEXT)`,
+		surrogateKeysTableName,
+	)); err != nil {
+		return fmt.Errorf("failed to create surrogate keys table: %w", err)
+	}
+
+	for _, key := range b.surrogateKeys {
+		if err := createSurrogateKey(ctx, db, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createSurrogateKey fills the mapping table of key and adds a key column to each of its columns
+func createSurrogateKey(ctx context.Context, db *sql.DB, key surrogateKey) error {
+	for _, column := range key.columns {
+		columns, err := getSQLiteTableColumns(db, column.Table)
+		if err != nil {
+			return fmt.Errorf("failed to get columns for table %s: %w", column.Table, err)
+		}
+		if len(columns) == 0 {
+			return newCodedError(ErrCodeInvalidConfig, "table %s of surrogate key %s not found", column.Table, key.mappingTable)
+		}
+		if !containsFold(columns, column.Column) {
+			return newCodedError(ErrCodeInvalidConfig, "column %s.%s of surrogate key %s not found", column.Table, column.Column, key.mappingTable)
+		}
+		if containsFold(columns, column.Column+surrogateKeySuffix) {
+			return newCodedError(ErrCodeDuplicateColumn, "table %s already has a column %s", column.Table, column.Column+surrogateKeySuffix)
+		}
+	}
+
+	mapping := quoteIdentifier(key.mappingTable)
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE %s (id INTEGER PRIMARY KEY, value TEXT NOT NULL UNIQUE)`, mapping,
+	)); err != nil {
+		return fmt.Errorf("failed to create surrogate key table %s: %w", key.mappingTable, err)
+	}
+
+	for _, column := range key.columns {
+		table := quoteIdentifier(column.Table)
+		source := quoteIdentifier(column.Column)
+		keyColumn := column.Column + surrogateKeySuffix
+		target := quoteIdentifier(keyColumn)
+
+		statements := []string{
+			fmt.Sprintf(`INSERT OR IGNORE INTO %s (value) SELECT %s FROM %s WHERE %s IS NOT NULL ORDER BY rowid`,
+				mapping, source, table, source),
+			fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s INTEGER`, table, target),
+			fmt.Sprintf(`UPDATE %s SET %s = (SELECT id FROM %s WHERE value = %s.%s)`,
+				table, target, mapping, table, source),
+			fmt.Sprintf(`CREATE INDEX %s ON %s (%s)`,
+				quoteIdentifier(metadataTablePrefix+"idx_"+column.Table+"_"+keyColumn), table, target),
+		}
+		// Triggers assign keys to rows inserted or changed after loading
+		for _, event := range []string{"INSERT", "UPDATE OF " + source} {
+			name := metadataTablePrefix + "key_" + column.Table + "_" + column.Column + "_" + strings.ToLower(strings.Fields(event)[0])
+			statements = append(statements, fmt.Sprintf(
+				`CREATE TRIGGER %s AFTER %s ON %s BEGIN
+					INSERT OR IGNORE INTO %s (value) SELECT NEW.%s WHERE NEW.%s IS NOT NULL;
+					UPDATE %s SET %s = (SELECT id FROM %s WHERE value = NEW.%s) WHERE rowid = NEW.rowid;
+				END`,
+				quoteIdentifier(name), event, table,
+				mapping, source, source,
+				table, target, mapping, source))
+		}
+		statements = append(statements, fmt.Sprintf( //nolint:gosec // Table name is a constant
+			`INSERT INTO "%s" (mapping_table, source_table, source_column, key_column) VALUES (%s, %s, %s, %s)`,
+			surrogateKeysTableName, quoteLiteral(key.mappingTable), quoteLiteral(column.Table),
+			quoteLiteral(column.Column), quoteLiteral(keyColumn)))
+
+		for _, statement := range statements {
+			if _, err := db.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("failed to create surrogate key %s for %s.%s: %w", key.mappingTable, column.Table, column.Column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// containsFold reports whether names contains name, compared case-insensitively like SQLite identifiers
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// generatedKeys holds the surrogate key mapping tables and key columns of a database,
+// which are not written by dumps
+type generatedKeys struct {
+	// tables are the lowercase names of the mapping tables
+	tables map[string]bool
+	// columns maps lowercase table names to the lowercase names of their key columns
+	columns map[string]map[string]bool
+}
+
+// readGeneratedKeys returns the surrogate keys recorded in db
+func readGeneratedKeys(ctx context.Context, db *sql.DB) (generatedKeys, error) {
+	keys := generatedKeys{tables: make(map[string]bool), columns: make(map[string]map[string]bool)}
+
+	var exists int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`,
+		surrogateKeysTableName,
+	).Scan(&exists); err != nil {
+		return keys, fmt.Errorf("failed to check surrogate keys table: %w", err)
+	}
+	if exists == 0 {
+		return keys, nil
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf( //nolint:gosec // Table name is a constant
+		`SELECT mapping_table, source_table, key_column FROM "%s"`, surrogateKeysTableName))
+	if err != nil {
+		return keys, fmt.Errorf("failed to query surrogate keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mappingTable, table, keyColumn string
+		if err := rows.Scan(&mappingTable, &table, &keyColumn); err != nil {
+			return keys, fmt.Errorf("failed to scan surrogate key: %w", err)
+		}
+		keys.tables[strings.ToLower(mappingTable)] = true
+		table = strings.ToLower(table)
+		if keys.columns[table] == nil {
+			keys.columns[table] = make(map[string]bool)
+		}
+		keys.columns[table][strings.ToLower(keyColumn)] = true
+	}
+	return keys, rows.Err()
+}
+
+// isMappingTable reports whether table is a surrogate key mapping table
+func (k generatedKeys) isMappingTable(table string) bool {
+	return k.tables[strings.ToLower(table)]
+}
+
+// dataColumns returns the columns of table without its key columns
+func (k generatedKeys) dataColumns(table string, columns []string) []string {
+	generated := k.columns[strings.ToLower(table)]
+	if len(generated) == 0 {
+		return columns
+	}
+	kept := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if !generated[strings.ToLower(column)] {
+			kept = append(kept, column)
+		}
+	}
+	return kept
+}
+
+// selectColumnsQuery returns a query that reads the columns of table
+func selectColumnsQuery(table string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	return "SELECT " + strings.Join(quoted, ", ") + " FROM " + quoteIdentifier(table)
+}
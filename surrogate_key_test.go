@@ -0,0 +1,142 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithSurrogateKey(t *testing.T) {
+	t.Parallel()
+
+	customersCSV := "customer_code,name\nCUST-0001-AAAA,Gina\nCUST-0002-BBBB,Hiro\n"
+	ordersCSV := "id,customer_code,total\n1,CUST-0002-BBBB,100\n2,CUST-0001-AAAA,50\n3,CUST-0002-BBBB,70\n4,,10\n"
+
+	withKeys := func(builder *DBBuilder) *DBBuilder {
+		return builder.
+			AddReader(strings.NewReader(customersCSV), "customers", FileTypeCSV).
+			AddReader(strings.NewReader(ordersCSV), "orders", FileTypeCSV).
+			WithSurrogateKey("customer_keys",
+				KeyColumn{Table: "customers", Column: "customer_code"},
+				KeyColumn{Table: "orders", Column: "customer_code"})
+	}
+
+	t.Run("equal values get the same key in every table", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, withKeys(NewBuilder()))
+
+		assert.Equal(t, []string{"Gina:50", "Hiro:170"}, queryRows(t, db, `
+			SELECT c.name, SUM(o.total) FROM orders o
+			JOIN customers c ON o.customer_code_key = c.customer_code_key
+			GROUP BY c.name ORDER BY c.name`))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM customer_keys").Scan(&count))
+		assert.Equal(t, 3, count, "empty values are values too")
+
+		_, err := db.ExecContext(context.Background(), "INSERT INTO orders (id, customer_code, total) VALUES (5, NULL, 1)")
+		require.NoError(t, err)
+		var key sql.NullInt64
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT customer_code_key FROM orders WHERE id = 5").Scan(&key))
+		assert.False(t, key.Valid)
+	})
+
+	t.Run("joins on the key column use an index", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, withKeys(NewBuilder()))
+
+		plan := queryRows(t, db, "EXPLAIN QUERY PLAN SELECT * FROM orders WHERE customer_code_key = 1")
+		assert.Contains(t, strings.Join(plan, "\n"), "USING INDEX")
+	})
+
+	t.Run("inserted and updated rows get keys", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, withKeys(NewBuilder()))
+		ctx := context.Background()
+
+		_, err := db.ExecContext(ctx, "INSERT INTO customers (customer_code, name) VALUES ('CUST-0003-CCCC', 'Ines')")
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, "UPDATE orders SET customer_code = 'CUST-0003-CCCC' WHERE id = 4")
+		require.NoError(t, err)
+
+		var name string
+		require.NoError(t, db.QueryRowContext(ctx, `
+			SELECT c.name FROM orders o JOIN customers c ON o.customer_code_key = c.customer_code_key
+			WHERE o.id = 4`).Scan(&name))
+		assert.Equal(t, "Ines", name)
+	})
+
+	t.Run("key columns and mapping tables are not dumped", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, withKeys(NewBuilder()))
+
+		dir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, dir))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.Equal(t, []string{"customers.csv", "orders.csv"}, names)
+
+		data, err := os.ReadFile(filepath.Join(dir, "customers.csv"))
+		require.NoError(t, err)
+		assert.Equal(t, customersCSV, string(data))
+	})
+
+	t.Run("invalid configurations are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name    string
+			builder *DBBuilder
+			code    ErrorCode
+		}{
+			{
+				name:    "empty mapping table",
+				builder: withKeys(NewBuilder()).WithSurrogateKey("", KeyColumn{Table: "orders", Column: "id"}),
+				code:    ErrCodeInvalidConfig,
+			},
+			{
+				name:    "no columns",
+				builder: withKeys(NewBuilder()).WithSurrogateKey("other_keys"),
+				code:    ErrCodeInvalidConfig,
+			},
+			{
+				name:    "unknown table",
+				builder: withKeys(NewBuilder()).WithSurrogateKey("other_keys", KeyColumn{Table: "missing", Column: "id"}),
+				code:    ErrCodeInvalidConfig,
+			},
+			{
+				name:    "unknown column",
+				builder: withKeys(NewBuilder()).WithSurrogateKey("other_keys", KeyColumn{Table: "orders", Column: "missing"}),
+				code:    ErrCodeInvalidConfig,
+			},
+			{
+				name:    "key column exists",
+				builder: withKeys(NewBuilder()).WithSurrogateKey("other_keys", KeyColumn{Table: "orders", Column: "customer_code"}),
+				code:    ErrCodeDuplicateColumn,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := tryOpenTestDB(t, tt.builder)
+				require.Error(t, err)
+				assert.Equal(t, tt.code, ErrorCodeOf(err))
+			})
+		}
+	})
+}
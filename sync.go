@@ -0,0 +1,460 @@
+package filesql
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of SyncOptions
+const (
+	// DefaultSyncPollInterval is how often SyncTable checks the file and the table
+	DefaultSyncPollInterval = time.Second
+	// DefaultSyncDebounce is how long a table must stay unchanged before SyncTable writes it
+	DefaultSyncDebounce = time.Second
+)
+
+// SyncOptions configure SyncTable. The zero value uses the defaults.
+type SyncOptions struct {
+	// PollInterval is how often the file and the table are checked for changes.
+	// 0 uses DefaultSyncPollInterval.
+	PollInterval time.Duration
+	// Debounce is how long the table must stay unchanged before it is written to the
+	// file, so a burst of statements results in one write. 0 uses DefaultSyncDebounce.
+	Debounce time.Duration
+	// LoadOptions configure how the file is loaded, for example WithDelimiter for a
+	// file that was added with a custom delimiter.
+	LoadOptions []Option
+	// Clock measures the poll interval and the debounce. nil uses the system clock.
+	Clock Clock
+	// OnError is called with the error of a failed reload or write. The sync keeps
+	// running and retries at the next poll.
+	OnError func(error)
+}
+
+// TableSync keeps a table and a file in sync. It is returned by SyncTable.
+type TableSync struct {
+	db      *sql.DB
+	path    string
+	table   string
+	options SyncOptions
+
+	mu sync.Mutex
+	// stopTimer cancels the pending poll
+	stopTimer func() bool
+	// closed is true once Close was called
+	closed bool
+	// modTime and size describe the file as it was last loaded or written
+	modTime time.Time
+	size    int64
+	// synced is the content hash of the table as it was last loaded or written
+	synced [sha256.Size]byte
+	// pending is the content hash of the table at the previous poll when it differs from synced
+	pending [sha256.Size]byte
+	// changedAt is when the table last changed; zero when the table is in sync
+	changedAt time.Time
+	// err is the error of the previous poll
+	err error
+}
+
+// SyncTable keeps table and the file at path in sync, for a spreadsheet-like live
+// editing experience over a file through SQL. This mode is experimental.
+//
+// Changes flow both ways:
+//   - When the file changes on disk, for example because it was saved from a text
+//     editor or a spreadsheet application, the table is reloaded from it.
+//   - When the table changes, for example through UPDATE or INSERT statements, it is
+//     written back to the file in the format and compression of the file. The write
+//     is debounced: it happens once the table stayed unchanged for
+//     SyncOptions.Debounce, and goes to a temporary file that replaces the file once
+//     it is complete, so readers never see a partly written file.
+//
+// Both sides are polled every SyncOptions.PollInterval. Changes of the table are
+// detected by hashing its content, which reads the whole table on every poll; sync
+// tables of a size a person edits rather than large data sets. When both sides
+// changed since the last sync, the file wins and the pending changes of the table are
+// discarded.
+//
+// If table does not exist, it is loaded from the file first. Otherwise the table is
+// assumed to hold the content of the file, as it does right after the file was
+// loaded with AddPath. CSV, TSV, LTSV, JSONL, and Parquet files, optionally
+// compressed, can be synced.
+//
+// Example:
+//
+//	db, err := filesql.Open("inventory.csv")
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+//
+//	tableSync, err := filesql.SyncTable(ctx, db, "inventory.csv", "inventory", filesql.SyncOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	defer tableSync.Close()
+//
+//	// Written to inventory.csv about a second later
+//	_, err = db.ExecContext(ctx, "UPDATE inventory SET stock = stock - 1 WHERE id = ?", id)
+//
+// Call Close before closing db to write pending changes. SyncTable returns an error
+// with ErrCodeInvalidConfig if the file type cannot be synced or an interval is
+// negative, and an error with ErrCodePathNotFound if the file does not exist.
+func SyncTable(ctx context.Context, db *sql.DB, path, table string, options SyncOptions) (*TableSync, error) {
+	if err := validateSyncOptions(path, table, options); err != nil {
+		return nil, err
+	}
+	if options.PollInterval == 0 {
+		options.PollInterval = DefaultSyncPollInterval
+	}
+	if options.Debounce == 0 {
+		options.Debounce = DefaultSyncDebounce
+	}
+	if options.Clock == nil {
+		options.Clock = NewSystemClock()
+	}
+
+	s := &TableSync{db: db, path: path, table: table, options: options}
+	exists, err := s.tableExists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if err := s.markFileSynced(); err != nil {
+			return nil, err
+		}
+		if s.synced, err = s.tableHash(ctx); err != nil {
+			return nil, err
+		}
+	} else if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopTimer = options.Clock.AfterFunc(options.PollInterval, s.poll)
+	return s, nil
+}
+
+// validateSyncOptions checks that path and table can be synced with options
+func validateSyncOptions(path, table string, options SyncOptions) error {
+	if table == "" {
+		return newCodedError(ErrCodeInvalidConfig, "table name for syncing %s must not be empty", path)
+	}
+	if !canWriteBack(newFile(path).getFileType()) {
+		return newCodedError(ErrCodeInvalidConfig,
+			"%s cannot be synced: only CSV, TSV, LTSV, JSONL, and Parquet files can be synced", path)
+	}
+	if options.PollInterval < 0 || options.Debounce < 0 {
+		return newCodedError(ErrCodeInvalidConfig,
+			"sync poll interval and debounce must not be negative: %s, %s", options.PollInterval, options.Debounce)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return withErrorCode(fmt.Errorf("failed to sync %s: %w", path, err), ErrCodePathNotFound)
+	}
+	if info.IsDir() {
+		return newCodedError(ErrCodeInvalidConfig, "%s cannot be synced: it is a directory", path)
+	}
+	return nil
+}
+
+// Flush writes pending changes of the table to the file right away, without waiting
+// for the debounce. If the file changed since the last sync, the table is reloaded
+// from it instead.
+func (s *TableSync) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked(ctx)
+}
+
+// Close stops syncing and writes pending changes of the table to the file, like
+// Flush. Close does not close the database.
+func (s *TableSync) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return s.err
+	}
+	s.closed = true
+	if s.stopTimer != nil {
+		s.stopTimer()
+	}
+	s.err = s.flushLocked(context.Background())
+	return s.err
+}
+
+// poll reloads the table if the file changed, writes the table if it changed and
+// stayed unchanged for the debounce, and schedules the next poll
+func (s *TableSync) poll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	s.err = s.sync(context.Background())
+	if s.err != nil && s.options.OnError != nil {
+		s.options.OnError(s.err)
+	}
+	s.stopTimer = s.options.Clock.AfterFunc(s.options.PollInterval, s.poll)
+}
+
+// sync compares both sides with their state at the last sync and brings them back in sync
+func (s *TableSync) sync(ctx context.Context) error {
+	fileChanged, err := s.fileChanged()
+	if err != nil {
+		return err
+	}
+	if fileChanged {
+		return s.reload(ctx)
+	}
+
+	hash, err := s.tableHash(ctx)
+	if err != nil {
+		return err
+	}
+	now := s.options.Clock.Now()
+	switch {
+	case hash == s.synced:
+		s.changedAt = time.Time{}
+	case s.changedAt.IsZero() || hash != s.pending:
+		s.pending = hash
+		s.changedAt = now
+	}
+	if s.changedAt.IsZero() || now.Sub(s.changedAt) < s.options.Debounce {
+		return nil
+	}
+	return s.write(ctx, hash)
+}
+
+// flushLocked writes the table if it differs from the file. A file that changed since
+// the last sync wins and is reloaded instead.
+func (s *TableSync) flushLocked(ctx context.Context) error {
+	fileChanged, err := s.fileChanged()
+	if err != nil {
+		return err
+	}
+	if fileChanged {
+		return s.reload(ctx)
+	}
+
+	hash, err := s.tableHash(ctx)
+	if err != nil {
+		return err
+	}
+	if hash == s.synced {
+		return nil
+	}
+	return s.write(ctx, hash)
+}
+
+// write writes the table to a temporary file next to the file and renames it over the
+// file. hash is the content hash of the written table.
+func (s *TableSync) write(ctx context.Context, hash [sha256.Size]byte) error {
+	dir, base := filepath.Split(s.path)
+	// The temporary file keeps the name of the file as its suffix, so it is written
+	// in the same format and compression
+	tempName := ".filesql-sync-" + rand.Text() + "-" + base
+	target := writeBackTarget{
+		fsys: NewWritableFS(os.DirFS(filepath.Clean(dir+".")), func(name string) (io.WriteCloser, error) {
+			return os.Create(filepath.Join(dir, filepath.FromSlash(name))) //nolint:gosec // The name is created above
+		}),
+		name:  tempName,
+		table: s.table,
+	}
+	tempPath := filepath.Join(dir, tempName)
+	if err := target.writeBack(ctx, s.db, NewDumpOptions()); err != nil {
+		_ = os.Remove(tempPath) // Ignore remove error during error handling
+		return withErrorCode(fmt.Errorf("failed to sync table %s to %s: %w", s.table, s.path, err), ErrCodeDumpFailed)
+	}
+	if err := os.Rename(tempPath, s.path); err != nil {
+		_ = os.Remove(tempPath) // Ignore remove error during error handling
+		return withErrorCode(fmt.Errorf("failed to sync table %s to %s: %w", s.table, s.path, err), ErrCodeDumpFailed)
+	}
+
+	if err := s.markFileSynced(); err != nil {
+		return err
+	}
+	s.synced = hash
+	s.changedAt = time.Time{}
+	return nil
+}
+
+// reload replaces the table with the content of the file
+func (s *TableSync) reload(ctx context.Context) error {
+	// Record the file before loading it, so a change while it is loaded is seen by the next poll
+	if err := s.markFileSynced(); err != nil {
+		return err
+	}
+
+	source, sourceTable, err := openFileTable(ctx, s.path, s.options.LoadOptions)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	if err := copyTable(ctx, source, sourceTable, s.db, s.table); err != nil {
+		return fmt.Errorf("failed to reload table %s from %s: %w", s.table, s.path, err)
+	}
+	if s.synced, err = s.tableHash(ctx); err != nil {
+		return err
+	}
+	s.changedAt = time.Time{}
+	return nil
+}
+
+// fileChanged reports whether the file changed since it was last loaded or written
+func (s *TableSync) fileChanged() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, withErrorCode(fmt.Errorf("failed to sync %s: %w", s.path, err), ErrCodePathNotFound)
+	}
+	return !info.ModTime().Equal(s.modTime) || info.Size() != s.size, nil
+}
+
+// markFileSynced records the current state of the file as loaded or written
+func (s *TableSync) markFileSynced() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return withErrorCode(fmt.Errorf("failed to sync %s: %w", s.path, err), ErrCodePathNotFound)
+	}
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return nil
+}
+
+// tableExists reports whether the table exists in the database
+func (s *TableSync) tableExists(ctx context.Context) (bool, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ? COLLATE NOCASE", s.table,
+	).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check table %s: %w", s.table, err)
+	}
+	return count > 0, nil
+}
+
+// tableHash returns a hash of the columns and rows of the table
+func (s *TableSync) tableHash(ctx context.Context) ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	rows, err := s.db.QueryContext(ctx, "SELECT * FROM "+quoteIdentifier(s.table)) //nolint:gosec // Table name is quoted
+	if err != nil {
+		return hash, fmt.Errorf("failed to read table %s: %w", s.table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return hash, err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%q\x1e", columns)
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return hash, fmt.Errorf("failed to read table %s: %w", s.table, err)
+		}
+		for _, value := range values {
+			fmt.Fprintf(h, "%T:%q\x1f", value, fmt.Sprint(value))
+		}
+		h.Write([]byte{'\x1e'})
+	}
+	if err := rows.Err(); err != nil {
+		return hash, fmt.Errorf("failed to read table %s: %w", s.table, err)
+	}
+	copy(hash[:], h.Sum(nil))
+	return hash, nil
+}
+
+// openFileTable loads the file at path into a database of its own and returns the
+// database and the name of the table of the file
+func openFileTable(ctx context.Context, path string, options []Option) (*sql.DB, string, error) {
+	validatedBuilder, err := NewBuilder().AddPath(path).Apply(options...).Build(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	db, err := validatedBuilder.Open(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return db, TableNameFromPath(path), nil
+}
+
+// copyTable replaces table target of db with table source of sourceDB in one transaction
+func copyTable(ctx context.Context, sourceDB *sql.DB, source string, db *sql.DB, target string) error {
+	columns, err := tableColumnTypes(ctx, sourceDB, source)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("table %s was not loaded", source)
+	}
+	definitions := make([]string, len(columns))
+	for i, column := range columns {
+		definitions[i] = column.definition()
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after Commit is a no-op
+
+	if _, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdentifier(target)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(target), strings.Join(definitions, ", "))); err != nil {
+		return err
+	}
+	if err := insertTableRows(ctx, sourceDB, source, tx, quoteIdentifier(target), len(columns)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertTableRows inserts every row of table source of sourceDB into the table with
+// the quoted name target through tx. Both tables have the given number of columns in
+// the same order.
+func insertTableRows(ctx context.Context, sourceDB *sql.DB, source string, tx *sql.Tx, target string, columns int) error {
+	rows, err := sourceDB.QueryContext(ctx, "SELECT * FROM "+quoteIdentifier(source)) //nolint:gosec // Table name is quoted
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", columns), ", ")
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", target, placeholders)) //nolint:gosec // Identifiers are quoted
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	values := make([]any, columns)
+	pointers := make([]any, columns)
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		if _, err := insert.ExecContext(ctx, values...); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,163 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncTable(t *testing.T) {
+	t.Parallel()
+
+	// setup writes content to a file named name and opens a database with the file loaded
+	setup := func(t *testing.T, name, content string) (string, *sql.DB) {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path, openTestDB(t, NewBuilder().AddPath(path))
+	}
+	readFile := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path) //nolint:gosec // Test file
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("table changes are written to the file after the debounce", func(t *testing.T) {
+		t.Parallel()
+
+		path, db := setup(t, "inventory.csv", "id,stock\n1,10\n2,5\n")
+		clock := newFakeClock()
+		tableSync, err := SyncTable(context.Background(), db, path, "inventory", SyncOptions{
+			PollInterval: time.Second,
+			Debounce:     2 * time.Second,
+			Clock:        clock,
+		})
+		require.NoError(t, err)
+		defer tableSync.Close()
+
+		_, err = db.ExecContext(context.Background(), "UPDATE inventory SET stock = stock - 1 WHERE id = 1")
+		require.NoError(t, err)
+		clock.advance(time.Second)
+		_, err = db.ExecContext(context.Background(), "UPDATE inventory SET stock = stock - 1 WHERE id = 2")
+		require.NoError(t, err)
+		clock.advance(time.Second)
+		clock.advance(time.Second)
+		assert.Equal(t, "id,stock\n1,10\n2,5\n", readFile(t, path), "the table changed within the debounce")
+
+		clock.advance(time.Second)
+		assert.Equal(t, "id,stock\n1,9\n2,4\n", readFile(t, path))
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "no temporary file is left behind")
+	})
+
+	t.Run("file changes reload the table", func(t *testing.T) {
+		t.Parallel()
+
+		path, db := setup(t, "inventory.tsv", "id\tstock\n1\t10\n")
+		clock := newFakeClock()
+		tableSync, err := SyncTable(context.Background(), db, path, "inventory", SyncOptions{Clock: clock})
+		require.NoError(t, err)
+		defer tableSync.Close()
+
+		require.NoError(t, os.WriteFile(path, []byte("id\tstock\tnote\n1\t7\trestocked\n2\t3\tnew\n"), 0600))
+		clock.advance(DefaultSyncPollInterval)
+
+		var stock int
+		var note string
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT SUM(stock), MAX(note) FROM inventory").Scan(&stock, &note))
+		assert.Equal(t, 10, stock)
+		assert.Equal(t, "restocked", note)
+
+		// The reloaded table is in sync, so nothing is written back
+		clock.advance(10 * DefaultSyncPollInterval)
+		assert.Equal(t, "id\tstock\tnote\n1\t7\trestocked\n2\t3\tnew\n", readFile(t, path))
+	})
+
+	t.Run("the file wins when both sides changed", func(t *testing.T) {
+		t.Parallel()
+
+		path, db := setup(t, "notes.csv", "id,text\n1,draft\n")
+		clock := newFakeClock()
+		tableSync, err := SyncTable(context.Background(), db, path, "notes", SyncOptions{Clock: clock})
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(context.Background(), "UPDATE notes SET text = 'from sql'")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte("id,text\n1,from editor\n"), 0600))
+		require.NoError(t, tableSync.Close())
+
+		var text string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT text FROM notes").Scan(&text))
+		assert.Equal(t, "from editor", text)
+		assert.Equal(t, "id,text\n1,from editor\n", readFile(t, path))
+	})
+
+	t.Run("flush and close write pending changes", func(t *testing.T) {
+		t.Parallel()
+
+		path, db := setup(t, "events.jsonl", `{"id":1,"kind":"click"}`+"\n")
+		tableSync, err := SyncTable(context.Background(), db, path, "events", SyncOptions{Clock: newFakeClock()})
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(context.Background(), "INSERT INTO events VALUES (2, 'view')")
+		require.NoError(t, err)
+		require.NoError(t, tableSync.Flush(context.Background()))
+		assert.Equal(t, `{"id":1,"kind":"click"}`+"\n"+`{"id":2,"kind":"view"}`+"\n", readFile(t, path))
+
+		_, err = db.ExecContext(context.Background(), "DELETE FROM events WHERE id = 1")
+		require.NoError(t, err)
+		require.NoError(t, tableSync.Close())
+		assert.Equal(t, `{"id":2,"kind":"view"}`+"\n", readFile(t, path))
+	})
+
+	t.Run("a missing table is loaded from the file", func(t *testing.T) {
+		t.Parallel()
+
+		other, db := setup(t, "other.csv", "id\n1\n")
+		path := filepath.Join(filepath.Dir(other), "scores.csv")
+		require.NoError(t, os.WriteFile(path, []byte("name;score\nalice;90\nbob;80\n"), 0600))
+
+		tableSync, err := SyncTable(context.Background(), db, path, "scores", SyncOptions{
+			LoadOptions: []Option{WithDelimiter(';')},
+			Clock:       newFakeClock(),
+		})
+		require.NoError(t, err)
+		defer tableSync.Close()
+
+		var total int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT SUM(score) FROM scores").Scan(&total))
+		assert.Equal(t, 170, total)
+	})
+
+	t.Run("invalid arguments are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		path, db := setup(t, "data.csv", "id\n1\n")
+		tests := []struct {
+			path    string
+			table   string
+			options SyncOptions
+			code    ErrorCode
+		}{
+			{path: path, table: "", code: ErrCodeInvalidConfig},
+			{path: filepath.Join(filepath.Dir(path), "book.xlsx"), table: "book", code: ErrCodeInvalidConfig},
+			{path: path, table: "data", options: SyncOptions{Debounce: -time.Second}, code: ErrCodeInvalidConfig},
+			{path: filepath.Join(filepath.Dir(path), "missing.csv"), table: "missing", code: ErrCodePathNotFound},
+		}
+		for _, tt := range tests {
+			_, err := SyncTable(context.Background(), db, tt.path, tt.table, tt.options)
+			assert.Equal(t, tt.code, ErrorCodeOf(err), tt.path)
+		}
+	})
+}
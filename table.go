@@ -1,6 +1,7 @@
 package filesql
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 )
@@ -68,11 +69,107 @@ func (t *table) equal(t2 *table) bool {
 	return true
 }
 
+// TableNameFromPath returns the name of the table filesql creates for the file at
+// path, so applications can show or check table names before loading.
+//
+// The name is the file name without its compression and format extensions:
+//
+//	filesql.TableNameFromPath("/data/users.csv")     // "users"
+//	filesql.TableNameFromPath("logs/access.tsv.gz")  // "access"
+//	filesql.TableNameFromPath("report.2024.parquet") // "report.2024"
+//
+// Excel workbooks create one table per sheet instead; see SheetTableName.
+func TableNameFromPath(path string) string {
+	return tableFromFilePath(path)
+}
+
+// SheetTableName returns the name of the table filesql creates for a sheet of the
+// Excel workbook at path, "<file>_<sheet>" with characters other than letters, digits,
+// and underscores removed:
+//
+//	filesql.SheetTableName("sales.xlsx", "Q1 2024") // "sales_Q1_2024"
+//
+// With WithExcelTableDetection, a sheet with several tables creates the tables
+// "<file>_<sheet>_1", "<file>_<sheet>_2", and so on.
+func SheetTableName(path, sheet string) string {
+	return sheetTableName(sanitizeTableName(tableFromFilePath(path)), sheet)
+}
+
+// sheetTableName returns the table name of a sheet of the workbook whose sanitized table name is baseTableName
+func sheetTableName(baseTableName, sheet string) string {
+	return baseTableName + "_" + sanitizeTableName(sheet)
+}
+
+// TableNameCollisions returns the paths that would create the same table, keyed by the
+// table name of the first of them. How such paths are loaded together is set with
+// WithTableCollisionPolicy, and WithTableName renames them. Table names are compared
+// case-insensitively, like SQLite does. Paths without collisions are not returned.
+//
+// Example:
+//
+//	collisions := filesql.TableNameCollisions("2023/sales.csv", "2024/sales.csv", "users.csv")
+//	// map[sales:[2023/sales.csv 2024/sales.csv]]
+func TableNameCollisions(paths ...string) map[string][]string {
+	groups := make(map[string][]string)
+	var order []string
+	for _, path := range paths {
+		key := strings.ToLower(tableFromFilePath(path))
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], path)
+	}
+
+	collisions := make(map[string][]string)
+	for _, key := range order {
+		if group := groups[key]; len(group) > 1 {
+			collisions[tableFromFilePath(group[0])] = group
+		}
+	}
+	return collisions
+}
+
+// ResolveTableNames returns a distinct table name for each path, in the order of paths.
+// The first path with a table name keeps it, and later paths with the same name get
+// the suffixes "_2", "_3", and so on, skipping names that other paths already use.
+// Use the names with AddReader to load files that would otherwise collide:
+//
+//	paths := []string{"2023/sales.csv", "2024/sales.csv"}
+//	for i, name := range filesql.ResolveTableNames(paths...) { // "sales", "sales_2"
+//		f, err := os.Open(paths[i])
+//		// ...
+//		builder.AddReader(f, name, filesql.FileTypeCSV)
+//	}
+func ResolveTableNames(paths ...string) []string {
+	taken := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		taken[strings.ToLower(tableFromFilePath(path))] = true
+	}
+
+	used := make(map[string]bool, len(paths))
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		name := tableFromFilePath(path)
+		if used[strings.ToLower(name)] {
+			base := name
+			for n := 2; ; n++ {
+				name = fmt.Sprintf("%s_%d", base, n)
+				if !taken[strings.ToLower(name)] && !used[strings.ToLower(name)] {
+					break
+				}
+			}
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
 // tableFromFilePath creates table name from file path
 func tableFromFilePath(filePath string) string {
 	fileName := filepath.Base(filePath)
 	// Remove compression extensions first
-	for _, ext := range []string{extGZ, extBZ2, extXZ, extZSTD} {
+	for _, ext := range []string{extGZ, extBZ2, extXZ, extZSTD, extLZ4, extBR} {
 		if strings.HasSuffix(fileName, ext) {
 			fileName = strings.TrimSuffix(fileName, ext)
 			break
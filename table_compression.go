@@ -0,0 +1,42 @@
+package filesql
+
+import (
+	"maps"
+	"strings"
+)
+
+// WithTableCompression sets the compression of the files of individual tables,
+// overriding WithCompression for those tables.
+//
+// A single dump can then compress huge fact tables while small lookup tables stay
+// plain files that are easy to inspect. Table names are compared case-insensitively,
+// like SQLite table names. Tables that are not in compressions use the compression
+// set with WithCompression, and each file gets the extension of its compression.
+//
+// Example:
+//
+//	options := filesql.NewDumpOptions().
+//		WithTableCompression(map[string]filesql.CompressionType{
+//			"sales_facts": filesql.CompressionZSTD,
+//		})
+//	// Writes sales_facts.csv.zst next to countries.csv
+//	err := filesql.DumpDatabase(db, "./output", options)
+//
+// Like WithCompression, it cannot compress Parquet output; use WithParquetCompression
+// instead.
+func (o DumpOptions) WithTableCompression(compressions map[string]CompressionType) DumpOptions {
+	// Copy the map so that later changes by the caller do not affect the options
+	o.TableCompression = maps.Clone(compressions)
+	return o
+}
+
+// forTable returns the options used to export table, with the compression of the table
+func (o DumpOptions) forTable(table string) DumpOptions {
+	for name, compression := range o.TableCompression {
+		if strings.EqualFold(name, table) {
+			o.Compression = compression
+			break
+		}
+	}
+	return o
+}
@@ -0,0 +1,55 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpOptions_WithTableCompression(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tables are compressed with their own compression", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "sales.csv"), []byte("id,amount\n1,10\n2,20\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "countries.csv"), []byte("code\njp\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id\n1\n"), 0600))
+		db := openTestDB(t, NewBuilder().AddPath(dir))
+
+		compressions := map[string]CompressionType{"SALES": CompressionZSTD, "countries": CompressionNone}
+		options := NewDumpOptions().
+			WithCompression(CompressionGZ).
+			WithTableCompression(compressions)
+		compressions["users"] = CompressionBZ2 // Changes after the call do not affect the options
+
+		output := t.TempDir()
+		require.NoError(t, DumpDatabase(db, output, options))
+		entries, err := os.ReadDir(output)
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.ElementsMatch(t, []string{"sales.csv.zst", "countries.csv", "users.csv.gz"}, names)
+
+		// The files are readable in their formats
+		dumped := openTestDB(t, NewBuilder().AddPath(output))
+		assert.Equal(t, []string{"30"}, queryRows(t, dumped, "SELECT SUM(amount) FROM sales"))
+	})
+
+	t.Run("configuration", func(t *testing.T) {
+		t.Parallel()
+
+		options, err := AutoSaveSettings{TableCompression: map[string]string{"sales": "zstd"}}.dumpOptions()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]CompressionType{"sales": CompressionZSTD}, options.TableCompression)
+
+		_, err = AutoSaveSettings{TableCompression: map[string]string{"sales": "rar"}}.dumpOptions()
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
@@ -0,0 +1,275 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// TableCollisionPolicy controls what happens when an input produces a table that another
+// input already produced, such as data/users.csv and backup/users.csv.
+type TableCollisionPolicy int
+
+const (
+	// TableCollisionDefault loads one of the files that produce the same table: an
+	// uncompressed file rather than compressed ones, and otherwise the file found last.
+	// Other inputs, such as readers, fail the load with ErrCodeDuplicateTable (default).
+	TableCollisionDefault TableCollisionPolicy = iota
+	// TableCollisionError fails Build with ErrCodeDuplicateTable naming the files that
+	// produce the same table, and Open for the other inputs that do
+	TableCollisionError
+	// TableCollisionSuffix loads the later input as a table named with the suffix "_2",
+	// "_3", and so on, and records a load warning
+	TableCollisionSuffix
+	// TableCollisionMerge appends the rows of the later input to the existing table.
+	// Columns are matched by name, and columns the table does not have are added to it.
+	TableCollisionMerge
+)
+
+// String returns the string representation of TableCollisionPolicy
+func (p TableCollisionPolicy) String() string {
+	switch p {
+	case TableCollisionDefault:
+		return "default"
+	case TableCollisionError:
+		return "error"
+	case TableCollisionSuffix:
+		return "suffix"
+	case TableCollisionMerge:
+		return "merge"
+	default:
+		return "default"
+	}
+}
+
+// parseTableCollisionPolicy converts a policy name into a TableCollisionPolicy
+func parseTableCollisionPolicy(name string) (TableCollisionPolicy, error) {
+	for _, policy := range []TableCollisionPolicy{TableCollisionDefault, TableCollisionError, TableCollisionSuffix, TableCollisionMerge} {
+		if strings.EqualFold(name, policy.String()) {
+			return policy, nil
+		}
+	}
+	return TableCollisionDefault, newCodedError(ErrCodeInvalidConfig, "unsupported table collision policy: %s", name)
+}
+
+// WithTableName loads the file at path as the table name instead of the table named
+// after the file (see TableNameFromPath).
+//
+// The path is the path of a file added with AddPath, AddPaths, or AddGlob, or of a
+// file found in an added directory; paths are compared after making them absolute.
+// For an Excel or ODS workbook, name replaces the file name in the names of the sheet
+// tables, and for a zip or tar archive with a single data file, it is the name of the
+// table of that file. Build fails with ErrCodeInvalidConfig if no loaded file has the
+// path, or if name is empty or starts with "_filesql_". Setting the name of the same
+// path again replaces it.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPaths("data/users.csv", "backup/users.csv").
+//		WithTableName("backup/users.csv", "users_backup")
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithTableName(path, name string) *DBBuilder {
+	if b.tableNames == nil {
+		b.tableNames = make(map[string]string)
+	}
+	b.tableNames[path] = name
+	return b
+}
+
+// WithTableCollisionPolicy sets what happens when several inputs produce the same table
+// name, such as data/users.csv and backup/users.csv.
+//
+// By default (TableCollisionDefault) only one of the files is loaded, without notice.
+// TableCollisionError fails Build with ErrCodeDuplicateTable naming the files, so they
+// can be renamed with WithTableName. TableCollisionSuffix loads each later input as a
+// table with a numbered suffix ("users_2") and records a load warning naming the table
+// it collided with (see GetLoadWarnings). TableCollisionMerge appends the rows of every
+// input to one table, which suits the same export split into several files.
+//
+// The policies other than the default apply to every kind of input, including readers
+// and the sheets of workbooks, in the order the inputs are loaded. WithSourcePriority,
+// when used, first keeps only the inputs added last among those producing a table. An
+// uncompressed file and a compressed copy of it in the same directory, such as
+// users.csv and users.csv.gz, are not a collision: only the uncompressed file is loaded.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("exports/"). // exports/2023/events.csv, exports/2024/events.csv
+//		WithTableCollisionPolicy(filesql.TableCollisionMerge)
+//	// SELECT COUNT(*) FROM events → the rows of both files
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithTableCollisionPolicy(policy TableCollisionPolicy) *DBBuilder {
+	b.streamProcessor.tableCollision = policy
+	return b
+}
+
+// resolveTableNames maps the collected paths to the table names set with WithTableName
+// and AddPathsAsTable and, with TableCollisionError, fails if collected files produce the same table
+func (b *DBBuilder) resolveTableNames(collected *collectedFiles) error {
+	if err := b.mapTableNames(collected); err != nil {
+		return err
+	}
+	if err := b.mapUnionTables(collected); err != nil {
+		return err
+	}
+	if b.streamProcessor.tableCollision != TableCollisionError {
+		return nil
+	}
+
+	first := make(map[string]string)
+	for _, path := range b.fileProcessor.deduplicateCompressedCopies(collected.paths) {
+		table := b.streamProcessor.fileTableName(path)
+		if b.streamProcessor.isUnionTable(table) {
+			continue
+		}
+		if other, ok := first[strings.ToLower(table)]; ok {
+			return newCodedError(ErrCodeDuplicateTable, "files %s and %s both produce table '%s', duplicate table names are not allowed", other, path, table)
+		}
+		first[strings.ToLower(table)] = path
+	}
+	return nil
+}
+
+// mapTableNames maps the collected paths to the table names set with WithTableName
+func (b *DBBuilder) mapTableNames(collected *collectedFiles) error {
+	if len(b.tableNames) == 0 {
+		b.streamProcessor.tableNames = nil
+		return nil
+	}
+
+	byLocation := make(map[string]string, len(collected.paths))
+	for _, path := range collected.paths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			byLocation[absPath] = path
+		}
+	}
+	names := make(map[string]string, len(b.tableNames))
+	for path, name := range b.tableNames {
+		if strings.TrimSpace(name) == "" {
+			return newCodedError(ErrCodeInvalidConfig, "table name for %s cannot be empty", path)
+		}
+		if strings.HasPrefix(name, metadataTablePrefix) {
+			return newCodedError(ErrCodeInvalidConfig, "table name for %s cannot start with %s: %s", path, metadataTablePrefix, name)
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+		}
+		collectedPath, ok := byLocation[absPath]
+		if !ok {
+			return newCodedError(ErrCodeInvalidConfig, "cannot name table %s: %s is not a loaded file", name, path)
+		}
+		names[collectedPath] = name
+	}
+	b.streamProcessor.tableNames = names
+	return nil
+}
+
+// fileTableName returns the name of the table of the file at filePath: the name set with
+// WithTableName, or the name derived from the file name
+func (sp *streamProcessor) fileTableName(filePath string) string {
+	if name, ok := sp.tableNames[filePath]; ok {
+		return name
+	}
+	return tableFromFilePath(filePath)
+}
+
+// claimTable returns the name an input producing tableName is loaded as, and the name
+// it is created under while it is loaded. It fails with ErrCodeDuplicateTable if the
+// table already exists, unless the collision policy gives another table name or merges
+// the rows into the existing table.
+func (sp *streamProcessor) claimTable(ctx context.Context, db *sql.DB, tableName string) (string, string, error) {
+	exists, err := tableExists(ctx, db, tableName)
+	if err != nil {
+		return "", "", err
+	}
+	if !exists {
+		return tableName, sp.loadingTableName(tableName), nil
+	}
+	if sp.isUnionTable(tableName) {
+		// The rows are loaded on their own, then appended by publishTable
+		return tableName, stagingTablePrefix + tableName, nil
+	}
+
+	switch sp.tableCollision {
+	case TableCollisionSuffix:
+		for n := 2; ; n++ {
+			name := fmt.Sprintf("%s_%d", tableName, n)
+			exists, err := tableExists(ctx, db, name)
+			if err != nil {
+				return "", "", err
+			}
+			if !exists {
+				message := fmt.Sprintf("loaded as %s, since table %s already exists", name, tableName)
+				if err := recordLoadWarning(ctx, db, name, message); err != nil {
+					return "", "", err
+				}
+				return name, sp.loadingTableName(name), nil
+			}
+		}
+	case TableCollisionMerge:
+		// The rows are loaded on their own, then appended by publishTable
+		return tableName, stagingTablePrefix + tableName, nil
+	default:
+		return "", "", newCodedError(ErrCodeDuplicateTable, "table '%s' already exists from another file, duplicate table names are not allowed", tableName)
+	}
+}
+
+// tableExists reports whether db has a table named tableName, ignoring case like SQLite does
+func tableExists(ctx context.Context, db *sql.DB, tableName string) (bool, error) {
+	var count int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=? COLLATE NOCASE`,
+		tableName,
+	).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check table existence: %w", err)
+	}
+	return count > 0, nil
+}
+
+// appendTable appends the rows of table source to table target and drops source.
+// Columns are matched by name, ignoring case, and the columns of source that target
+// does not have are added to target.
+func appendTable(ctx context.Context, db *sql.DB, source, target string) error {
+	sourceColumns, err := tableColumnTypes(ctx, db, source)
+	if err != nil {
+		return err
+	}
+	targetColumns, err := tableColumnTypes(ctx, db, target)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // Rollback after Commit is a no-op
+
+	names := make([]string, len(sourceColumns))
+	for i, column := range sourceColumns {
+		names[i] = quoteIdentifier(column.name)
+		if _, ok := findDeclaredColumn(targetColumns, column.name); ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", //nolint:gosec // Identifiers are quoted
+			quoteIdentifier(target), column.definition())); err != nil {
+			return fmt.Errorf("failed to merge rows into table %s: %w", target, err)
+		}
+	}
+	columnList := strings.Join(names, ", ")
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", //nolint:gosec // Identifiers are quoted
+		quoteIdentifier(target), columnList, columnList, quoteIdentifier(source))); err != nil {
+		return fmt.Errorf("failed to merge rows into table %s: %w", target, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DROP TABLE "+quoteIdentifier(source)); err != nil {
+		return fmt.Errorf("failed to merge rows into table %s: %w", target, err)
+	}
+	return tx.Commit()
+}
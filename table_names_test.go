@@ -0,0 +1,207 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_WithTableName(t *testing.T) {
+	t.Parallel()
+
+	// writeFiles writes data/users.csv and backup/users.csv into a new directory
+	writeFiles := func(t *testing.T) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		for name, content := range map[string]string{
+			filepath.Join("data", "users.csv"):   "id,name\n1,alice\n2,bob\n",
+			filepath.Join("backup", "users.csv"): "id,name\n1,alice\n",
+		} {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+		return dir
+	}
+
+	t.Run("files are loaded as the given tables", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t)
+		db := openTestDB(t, NewBuilder().
+			AddPath(dir).
+			WithTableName(filepath.Join(dir, "backup", "users.csv"), "users_backup"))
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"users", "users_backup"}, tables)
+		assert.Equal(t, []string{"2:1"}, queryRows(t, db,
+			"SELECT (SELECT COUNT(*) FROM users), (SELECT COUNT(*) FROM users_backup)"))
+	})
+
+	t.Run("workbooks name their sheet tables after the given name", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join("testdata", "excel", "sample.xlsx")
+		db := openTestDB(t, NewBuilder().AddPath(path).WithTableName(path, "book"))
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		require.NotEmpty(t, tables)
+		for _, table := range tables {
+			assert.True(t, strings.HasPrefix(table, "book_"), table)
+		}
+	})
+
+	errorTests := []struct {
+		name      string
+		file      string
+		tableName string
+		wantMsg   string
+	}{
+		{
+			name:      "file that is not loaded",
+			file:      "missing.csv",
+			tableName: "x",
+			wantMsg:   "is not a loaded file",
+		},
+		{name: "empty table name", file: filepath.Join("data", "users.csv"), tableName: ""},
+		{name: "reserved table name", file: filepath.Join("data", "users.csv"), tableName: "_filesql_users"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeFiles(t)
+			_, err := NewBuilder().AddPath(dir).WithTableName(filepath.Join(dir, tt.file), tt.tableName).Build(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestDBBuilder_WithTableCollisionPolicy(t *testing.T) {
+	t.Parallel()
+
+	// writeFiles writes two events.csv files with different columns into subdirectories of a new directory
+	writeFiles := func(t *testing.T) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		for name, content := range map[string]string{
+			filepath.Join("2023", "events.csv"): "id,name\n1,signup\n2,login\n",
+			filepath.Join("2024", "events.csv"): "id,name,source\n3,logout,web\n",
+		} {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+		return dir
+	}
+
+	tests := []struct {
+		name     string
+		policy   TableCollisionPolicy
+		tables   []string
+		query    string
+		rows     []string
+		warnings []LoadWarning
+	}{
+		{
+			name:   "by default one of the files is loaded",
+			policy: TableCollisionDefault,
+			tables: []string{"events"},
+		},
+		{
+			name:     "the suffix policy loads later files as numbered tables",
+			policy:   TableCollisionSuffix,
+			tables:   []string{"events", "events_2"},
+			query:    "SELECT source FROM events_2",
+			rows:     []string{"web"},
+			warnings: []LoadWarning{{Table: "events_2", Message: "loaded as events_2, since table events already exists"}},
+		},
+		{
+			name:   "the merge policy appends the rows of every file",
+			policy: TableCollisionMerge,
+			tables: []string{"events"},
+			query:  "SELECT name, source FROM events ORDER BY id",
+			rows:   []string{"signup:NULL", "login:NULL", "logout:web"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := openTestDB(t, NewBuilder().AddPath(writeFiles(t)).WithTableCollisionPolicy(tt.policy))
+
+			tables, err := getSQLiteTableNames(db)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.tables, tables)
+			if tt.query != "" {
+				assert.Equal(t, tt.rows, queryRows(t, db, tt.query))
+			}
+			warnings, err := GetLoadWarnings(db)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.warnings, warnings)
+		})
+	}
+
+	t.Run("the error policy names both files", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeFiles(t)
+		_, err := tryOpenTestDB(t, NewBuilder().AddPath(dir).WithTableCollisionPolicy(TableCollisionError))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeDuplicateTable, ErrorCodeOf(err))
+		assert.Contains(t, err.Error(), filepath.Join(dir, "2023", "events.csv"))
+		assert.Contains(t, err.Error(), filepath.Join(dir, "2024", "events.csv"))
+	})
+
+	t.Run("the policy applies to readers", func(t *testing.T) {
+		t.Parallel()
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id\n2\n"), "users", FileTypeCSV).
+			WithTableCollisionPolicy(TableCollisionMerge))
+		assert.Equal(t, []string{"2"}, queryRows(t, db, "SELECT COUNT(*) FROM users"))
+	})
+
+	t.Run("compressed copies are not collisions", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id\n1\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv.gz"), gzipData(t, "id\n1\n"), 0600))
+		db := openTestDB(t, NewBuilder().AddPath(dir).WithTableCollisionPolicy(TableCollisionMerge))
+		assert.Equal(t, []string{"1"}, queryRows(t, db, "SELECT COUNT(*) FROM users"))
+	})
+}
+
+func TestParseTableCollisionPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []TableCollisionPolicy{TableCollisionDefault, TableCollisionError, TableCollisionSuffix, TableCollisionMerge} {
+		t.Run(policy.String(), func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := parseTableCollisionPolicy(strings.ToUpper(policy.String()))
+			require.NoError(t, err)
+			assert.Equal(t, policy, parsed)
+		})
+	}
+
+	t.Run("unknown policy", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parseTableCollisionPolicy("rename")
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
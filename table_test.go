@@ -1,10 +1,14 @@
 package filesql
 
 import (
+	"context"
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 )
 
 func TestNewTable(t *testing.T) {
@@ -145,3 +149,96 @@ func TestTableFromFilePath_Additional(t *testing.T) {
 		})
 	}
 }
+
+func TestTableNameFromPath(t *testing.T) {
+	t.Parallel()
+
+	t.Run("matches the table created for the file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "users.csv")
+		require.NoError(t, os.WriteFile(path, []byte("id\n1\n"), 0600))
+
+		db, err := Open(path)
+		require.NoError(t, err)
+		defer db.Close()
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Equal(t, []string{TableNameFromPath(path)}, tables)
+		assert.Equal(t, "access.log", TableNameFromPath(filepath.Join("logs", "access.log.tsv.gz")))
+	})
+
+	t.Run("sheet tables", func(t *testing.T) {
+		t.Parallel()
+
+		f := excelize.NewFile()
+		defer f.Close()
+		_, err := f.NewSheet("Q1 2024")
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow("Q1 2024", "A1", &[]any{"id"}))
+		require.NoError(t, f.SetSheetRow("Q1 2024", "A2", &[]any{1}))
+		path := filepath.Join(t.TempDir(), "sales-report.xlsx")
+		require.NoError(t, f.SaveAs(path))
+
+		validatedBuilder, err := NewBuilder().AddPath(path).Build(context.Background())
+		require.NoError(t, err)
+		db, err := validatedBuilder.Open(context.Background())
+		require.NoError(t, err)
+		defer db.Close()
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Equal(t, "sales_report_Q1_2024", SheetTableName(path, "Q1 2024"))
+		assert.Contains(t, tables, SheetTableName(path, "Q1 2024"))
+	})
+}
+
+func TestTableNameCollisions(t *testing.T) {
+	t.Parallel()
+
+	collisions := TableNameCollisions(
+		filepath.Join("2023", "sales.csv"),
+		"users.csv",
+		filepath.Join("2024", "Sales.tsv.gz"),
+		filepath.Join("archive", "users.parquet"),
+		"orders.csv",
+	)
+	assert.Equal(t, map[string][]string{
+		"sales": {filepath.Join("2023", "sales.csv"), filepath.Join("2024", "Sales.tsv.gz")},
+		"users": {"users.csv", filepath.Join("archive", "users.parquet")},
+	}, collisions)
+
+	assert.Empty(t, TableNameCollisions("a.csv", "b.csv"))
+}
+
+func TestResolveTableNames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "distinct names are kept",
+			paths: []string{"users.csv", "orders.tsv"},
+			want:  []string{"users", "orders"},
+		},
+		{
+			name:  "later paths get suffixes",
+			paths: []string{filepath.Join("a", "sales.csv"), filepath.Join("b", "sales.csv"), filepath.Join("c", "SALES.csv")},
+			want:  []string{"sales", "sales_2", "SALES_3"},
+		},
+		{
+			name:  "suffixes skip names of other paths",
+			paths: []string{filepath.Join("a", "sales.csv"), filepath.Join("b", "sales.csv"), "sales_2.csv"},
+			want:  []string{"sales", "sales_3", "sales_2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, ResolveTableNames(tt.paths...))
+		})
+	}
+}
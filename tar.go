@@ -0,0 +1,126 @@
+package filesql
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+)
+
+// nextTARDataFile advances the tar archive to its next data file (see isArchiveDataFile)
+// and returns the header, or io.EOF at the end of the archive
+func nextTARDataFile(archive *tar.Reader) (*tar.Header, error) {
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, newCodedError(ErrCodeInvalidData, "failed to read tar archive: %w", err)
+		}
+		if header.FileInfo().Mode().IsRegular() && isArchiveDataFile(header.Name) {
+			return header, nil
+		}
+	}
+}
+
+// openTARDataFile reads the single data file in a tar archive and returns it with its
+// file type. Archives with no or several data files are rejected, since a reader
+// input is loaded as a single table. The data file is read into memory to check
+// that no other data file follows it.
+func openTARDataFile(reader io.Reader) (io.ReadCloser, FileType, error) {
+	archive := tar.NewReader(reader)
+	header, err := nextTARDataFile(archive)
+	if errors.Is(err, io.EOF) {
+		return nil, FileTypeUnsupported, newCodedError(ErrCodeEmptyData, "no supported data file found in tar archive")
+	}
+	if err != nil {
+		return nil, FileTypeUnsupported, err
+	}
+	data, err := io.ReadAll(archive)
+	if err != nil {
+		return nil, FileTypeUnsupported, newCodedError(ErrCodeInvalidData, "failed to read %s in tar archive: %w", header.Name, err)
+	}
+
+	names := []string{header.Name}
+	for {
+		next, err := nextTARDataFile(archive)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, FileTypeUnsupported, err
+		}
+		names = append(names, next.Name)
+	}
+	if len(names) > 1 {
+		return nil, FileTypeUnsupported, newCodedError(ErrCodeUnsupportedFormat,
+			"tar archive contains %d data files (%s); only archives with a single data file can be loaded as one table",
+			len(names), strings.Join(names, ", "))
+	}
+	return io.NopCloser(bytes.NewReader(data)), detectFileType(header.Name), nil
+}
+
+// streamTARFileToDatabase loads each data file of the tar archive at filePath as a
+// table named after the file. The archive is read sequentially, so it is never held
+// in memory as a whole.
+func (sp *streamProcessor) streamTARFileToDatabase(ctx context.Context, db *sql.DB, reader io.Reader, filePath string) error {
+	archive := tar.NewReader(reader)
+	loaded := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := nextTARDataFile(archive)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := sp.streamArchiveEntryToDatabase(ctx, db, archive, filePath, header.Name); err != nil {
+			return err
+		}
+		loaded++
+	}
+	if loaded == 0 {
+		return newCodedError(ErrCodeEmptyData, "no supported data file found in tar archive")
+	}
+	return nil
+}
+
+// parseTARStream parses the single data file in a tar archive
+func (p *streamingParser) parseTARStream(reader io.Reader) (*table, error) {
+	entry, fileType, err := openTARDataFile(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+
+	return p.zipEntryParser(fileType).parseFromReader(entry)
+}
+
+// processTARInChunks processes the single data file in a tar archive in chunks
+func (p *streamingParser) processTARInChunks(reader io.Reader, processor chunkProcessor) error {
+	entry, fileType, err := openTARDataFile(reader)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+
+	return p.zipEntryParser(fileType).ProcessInChunks(entry, processor)
+}
+
+// parseTAR parses the single data file in a tar archive
+func (f *file) parseTAR() (*table, error) {
+	reader, closer, err := f.openReader()
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	return newStreamingParser(FileTypeTAR, tableFromFilePath(f.path), 0).parseTARStream(reader)
+}
@@ -0,0 +1,153 @@
+package filesql
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTARData builds a tar archive from entry names and contents, in order. Names ending
+// with a slash are directories.
+func newTARData(t *testing.T, entries ...[2]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry[0], Mode: 0o600, Size: int64(len(entry[1])), Typeflag: tar.TypeReg}
+		if entry[0][len(entry[0])-1] == '/' {
+			header = &tar.Header{Name: entry[0], Mode: 0o700, Typeflag: tar.TypeDir}
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(entry[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestOpenTARDataFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ignores directories, resource forks, and hidden files", func(t *testing.T) {
+		t.Parallel()
+
+		data := newTARData(t,
+			[2]string{"./export/", ""},
+			[2]string{"./export/._sales.csv", "resource fork"},
+			[2]string{"./export/readme.txt", "not data"},
+			[2]string{"./export/sales.tsv", "id\tamount\n1\t100\n"},
+		)
+
+		entry, fileType, err := openTARDataFile(bytes.NewReader(data))
+		require.NoError(t, err)
+		defer entry.Close()
+		assert.Equal(t, FileTypeTSV, fileType)
+	})
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantCode ErrorCode
+		wantMsg  string
+	}{
+		{
+			name:     "rejects archives with several data files",
+			data:     newTARData(t, [2]string{"a.csv", "id\n1\n"}, [2]string{"b.tsv", "id\n2\n"}),
+			wantCode: ErrCodeUnsupportedFormat,
+			wantMsg:  "a.csv, b.tsv",
+		},
+		{
+			name:     "rejects archives without data files",
+			data:     newTARData(t, [2]string{"readme.txt", "nothing here"}, [2]string{"nested.tar.gz", "tar"}),
+			wantCode: ErrCodeEmptyData,
+		},
+		{
+			name:     "rejects data that is not a tar archive",
+			data:     []byte("id,name\n1,alice\n"),
+			wantCode: ErrCodeInvalidData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := openTARDataFile(bytes.NewReader(tt.data))
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, ErrorCodeOf(err))
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestTARLoading(t *testing.T) {
+	t.Parallel()
+
+	// writeTAR writes data to name in a new directory and returns its path
+	writeTAR := func(t *testing.T, name string, data []byte) string {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+	runLoadTests(t, []loadTest{
+		{
+			name: "loads each data file of a tar.gz file as a table",
+			builder: func(t *testing.T) *DBBuilder {
+				data := newTARData(t,
+					[2]string{"drop/", ""},
+					[2]string{"drop/orders.csv", "id,amount\n1,100\n2,250\n"},
+					[2]string{"drop/2024/", ""},
+					[2]string{"drop/2024/events.jsonl.gz", string(gzipData(t, `{"id":1,"kind":"click"}`+"\n"))},
+					[2]string{"drop/2024/.hidden.csv", "id\n1\n"},
+				)
+				return NewBuilder().AddPath(writeTAR(t, "drop.tar.gz", gzipData(t, string(data))))
+			},
+			tables: []string{"events", "orders"},
+			want: map[string][]string{
+				"SELECT SUM(amount) FROM orders":       {"350"},
+				"SELECT kind FROM events WHERE id = 1": {"click"},
+			},
+		},
+		{
+			name: "loads tgz files in directories",
+			builder: func(t *testing.T) *DBBuilder {
+				data := gzipData(t, string(newTARData(t, [2]string{"users.csv", "id,name\n1,alice\n"})))
+				return NewBuilder().AddPath(filepath.Dir(writeTAR(t, "drop.tgz", data)))
+			},
+			want: map[string][]string{"SELECT name FROM users": {"alice"}},
+		},
+		{
+			name: "loads a tar.gz file from a reader",
+			builder: func(t *testing.T) *DBBuilder {
+				data := gzipData(t, string(newTARData(t, [2]string{"data/people.csv", "id,name\n1,alice\n"})))
+				return NewBuilder().AddReader(bytes.NewReader(data), "people", FileTypeTARGZ)
+			},
+			want: map[string][]string{"SELECT name FROM people WHERE id = 1": {"alice"}},
+		},
+		{
+			name: "data files with the same table name fail",
+			builder: func(t *testing.T) *DBBuilder {
+				data := newTARData(t,
+					[2]string{"2023/orders.csv", "id\n1\n"},
+					[2]string{"2024/orders.csv", "id\n2\n"},
+				)
+				return NewBuilder().AddPath(writeTAR(t, "drop.tar", data))
+			},
+			wantErr: ErrCodeDuplicateTable,
+		},
+		{
+			name: "archives without data files fail",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().AddPath(writeTAR(t, "drop.tar", newTARData(t, [2]string{"readme.txt", "nothing"})))
+			},
+			wantErr: ErrCodeEmptyData,
+		},
+	})
+}
@@ -0,0 +1,90 @@
+package filesql
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WithTempDir sets the directory for temporary files created while loading.
+//
+// Parquet inputs need random access, so they are normally buffered in memory. With a
+// temporary directory, they are spooled to a file in dir instead, which is removed as
+// soon as the input is loaded. XLSX inputs are not affected: the Excel library still
+// unzips very large worksheets (over 16 MB of XML) to the OS temporary directory
+// rather than holding them in memory.
+//
+// This is useful in containers whose default temporary directory (os.TempDir) is a
+// small tmpfs. The directory must exist; Build returns an error otherwise.
+//
+// Example:
+//
+//	builder.WithTempDir("/var/lib/myapp/tmp")
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithTempDir(dir string) *DBBuilder {
+	b.streamProcessor.tempDir = dir
+	return b
+}
+
+// DisableTempFiles guarantees that loading never writes temporary files.
+//
+// filesql streams fs.FS, path, and reader inputs directly into the database, but the
+// Excel library unzips very large worksheets (over 16 MB of XML) to the OS temporary
+// directory. With DisableTempFiles, worksheets are always unzipped in memory, so AddFS
+// works on read-only container filesystems without a writable /tmp. Parquet inputs
+// are buffered in memory as well.
+//
+// It cannot be combined with WithTempDir; Build returns an error if both are set.
+//
+// Example:
+//
+//	validatedBuilder, err := filesql.NewBuilder().
+//		AddFS(embeddedFiles).
+//		DisableTempFiles().
+//		Build(ctx)
+//
+// Returns self for chaining.
+func (b *DBBuilder) DisableTempFiles() *DBBuilder {
+	b.streamProcessor.noTempFiles = true
+	return b
+}
+
+// keepInMemory reports whether intermediate data must not be unzipped to the OS temporary directory
+func (sp *streamProcessor) keepInMemory() bool {
+	return sp.noTempFiles
+}
+
+// spoolToTempFile copies reader into a new temporary file in dir and rewinds it.
+// The returned cleanup function closes and removes the file.
+func spoolToTempFile(dir string, reader io.Reader) (*os.File, func(), error) {
+	tempFile, err := os.CreateTemp(dir, "filesql-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	cleanup := func() {
+		_ = tempFile.Close()           // Ignore close error
+		_ = os.Remove(tempFile.Name()) // Ignore remove error
+	}
+
+	if _, err := io.Copy(tempFile, reader); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to rewind temporary file: %w", err)
+	}
+	return tempFile, cleanup, nil
+}
+
+// xlsxOpenOptions returns the excelize options for opening workbooks.
+// With keepInMemory, worksheets are never unzipped to the OS temporary directory.
+func xlsxOpenOptions(keepInMemory bool) []excelize.Options {
+	if !keepInMemory {
+		return nil
+	}
+	return []excelize.Options{{UnzipXMLSizeLimit: excelize.UnzipSizeLimit}}
+}
@@ -0,0 +1,158 @@
+package filesql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// eofHookReader calls onEOF when the wrapped reader is exhausted
+type eofHookReader struct {
+	reader io.Reader
+	onEOF  func()
+}
+
+func (r *eofHookReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if errors.Is(err, io.EOF) && r.onEOF != nil {
+		r.onEOF()
+		r.onEOF = nil
+	}
+	return n, err
+}
+
+func TestDBBuilder_WithTempDir(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parquet input is spooled to the temporary directory", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		outputDir := t.TempDir()
+		sourceDB := openTestDB(t, NewBuilder().AddPath(filepath.Join("testdata", "sample.csv")))
+		require.NoError(t, DumpDatabase(sourceDB, outputDir, NewDumpOptions().WithFormat(OutputFormatParquet)))
+
+		data, err := os.ReadFile(filepath.Join(outputDir, "sample.parquet")) //nolint:gosec // Test file path
+		require.NoError(t, err)
+
+		tempDir := t.TempDir()
+		var spooled []os.DirEntry
+		reader := &eofHookReader{
+			reader: bytes.NewReader(data),
+			onEOF: func() {
+				spooled, _ = os.ReadDir(tempDir) //nolint:errcheck // Checked by the assertion below
+			},
+		}
+
+		db := openTestDB(t, NewBuilder().
+			AddReader(reader, "sample", FileTypeParquet).
+			WithTempDir(tempDir))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sample").Scan(&count))
+		assert.Positive(t, count)
+
+		assert.Len(t, spooled, 1, "parquet data should be spooled to the temporary directory")
+		remaining, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		assert.Empty(t, remaining, "temporary files should be removed after loading")
+	})
+
+	t.Run("xlsx input loads with a temporary directory", func(t *testing.T) {
+		t.Parallel()
+
+		tempDir := t.TempDir()
+		builder := NewBuilder().
+			AddPath(filepath.Join("testdata", "excel", "sample.xlsx")).
+			WithTempDir(tempDir)
+		assert.False(t, builder.streamProcessor.keepInMemory(), "large worksheets may still be unzipped to disk")
+
+		db := openTestDB(t, builder)
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.NotEmpty(t, tables)
+
+		remaining, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+	})
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "missing directory is rejected at build time",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().
+					AddPath(filepath.Join("testdata", "sample.csv")).
+					WithTempDir(filepath.Join(t.TempDir(), "missing"))
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name: "file is rejected at build time",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().
+					AddPath(filepath.Join("testdata", "sample.csv")).
+					WithTempDir(filepath.Join("testdata", "sample.csv"))
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name: "cannot be combined with DisableTempFiles",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().
+					AddPath(filepath.Join("testdata", "sample.csv")).
+					WithTempDir(t.TempDir()).
+					DisableTempFiles()
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+	})
+}
+
+func TestDBBuilder_DisableTempFiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fs.FS inputs load without temporary files", func(t *testing.T) {
+		t.Parallel()
+
+		xlsxData, err := os.ReadFile(filepath.Join("testdata", "excel", "sample.xlsx"))
+		require.NoError(t, err)
+		mockFS := fstest.MapFS{
+			"users.csv":   &fstest.MapFile{Data: []byte("id,name\n1,alice\n2,bob\n")},
+			"sample.xlsx": &fstest.MapFile{Data: xlsxData},
+		}
+
+		ctx := context.Background()
+		builder := NewBuilder().AddFS(mockFS).DisableTempFiles()
+		assert.True(t, builder.streamProcessor.keepInMemory())
+
+		db := openTestDB(t, builder)
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count))
+		assert.Equal(t, 2, count)
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.Greater(t, len(tables), 1, "XLSX sheets should be loaded")
+	})
+
+	t.Run("excel worksheets are unzipped in memory", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, xlsxOpenOptions(false))
+		options := xlsxOpenOptions(true)
+		require.Len(t, options, 1)
+		assert.Equal(t, int64(excelize.UnzipSizeLimit), options[0].UnzipXMLSizeLimit)
+	})
+}
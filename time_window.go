@@ -0,0 +1,118 @@
+package filesql
+
+import (
+	"strings"
+	"time"
+)
+
+// timeWindow keeps the rows of a table whose time column falls into [from, to)
+type timeWindow struct {
+	// column is the name of the time column, compared case-insensitively
+	column string
+	// from is the inclusive start of the window; the zero time means no lower bound
+	from time.Time
+	// to is the exclusive end of the window; the zero time means no upper bound
+	to time.Time
+}
+
+// WithTimeWindow discards the rows of table whose column is outside the window
+// from (inclusive) to to (exclusive) while the table is loaded.
+//
+// Rows are dropped chunk by chunk as they are streamed, so loading a year of logs
+// for a one-week investigation only keeps that week in memory and in the database.
+// A zero from or to leaves that side of the window open. The column is matched
+// case-insensitively after header mapping, and its values are parsed with the
+// datetime formats of type inference (such as "2024-01-02", "2024-01-02 15:04:05",
+// and RFC 3339); values without a time zone are taken as UTC. Rows whose value is
+// empty or not a datetime are discarded too.
+//
+// Example:
+//
+//	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+//	builder := filesql.NewBuilder().
+//		AddPath("access_log.csv.gz").
+//		WithTimeWindow("access_log", "timestamp", from, from.AddDate(0, 0, 7))
+//
+// Build fails with ErrCodeInvalidConfig if from is not before to, and a load fails
+// with ErrCodeInvalidConfig if the table has no such column.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithTimeWindow(table, column string, from, to time.Time) *DBBuilder {
+	if b.streamProcessor.timeWindows == nil {
+		b.streamProcessor.timeWindows = make(map[string][]timeWindow)
+	}
+	key := strings.ToLower(table)
+	b.streamProcessor.timeWindows[key] = append(b.streamProcessor.timeWindows[key], timeWindow{column: column, from: from, to: to})
+	return b
+}
+
+// validateTimeWindows checks that every time window names a column and is not empty
+func validateTimeWindows(windows map[string][]timeWindow) error {
+	for table, tableWindows := range windows {
+		for _, window := range tableWindows {
+			if window.column == "" {
+				return newCodedError(ErrCodeInvalidConfig, "time window of table %s needs a column", table)
+			}
+			if !window.from.IsZero() && !window.to.IsZero() && !window.from.Before(window.to) {
+				return newCodedError(ErrCodeInvalidConfig, "time window of %s.%s must start before it ends", table, window.column)
+			}
+		}
+	}
+	return nil
+}
+
+// contains reports whether value is a datetime inside the window
+func (w timeWindow) contains(value string) bool {
+	t, ok := parseDatetime(value)
+	if !ok {
+		return false
+	}
+	if !w.from.IsZero() && t.Before(w.from) {
+		return false
+	}
+	return w.to.IsZero() || t.Before(w.to)
+}
+
+// filterTimeWindows removes the records of a chunk that are outside the time windows of its table
+func (sp *streamProcessor) filterTimeWindows(chunk *tableChunk) (*tableChunk, error) {
+	table := strings.TrimPrefix(chunk.getTableName(), stagingTablePrefix)
+	windows := sp.timeWindows[strings.ToLower(table)]
+	if len(windows) == 0 {
+		return chunk, nil
+	}
+
+	indexes := make([]int, len(windows))
+	for i, window := range windows {
+		indexes[i] = -1
+		for j, name := range chunk.getHeaders() {
+			if strings.EqualFold(name, window.column) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, newCodedError(ErrCodeInvalidConfig, "time window column %s not found in table %s", window.column, table)
+		}
+	}
+
+	records := make([]Record, 0, len(chunk.getRecords()))
+	for _, record := range chunk.getRecords() {
+		kept := true
+		for i, window := range windows {
+			if indexes[i] >= len(record) || !window.contains(record[indexes[i]]) {
+				kept = false
+				break
+			}
+		}
+		if kept {
+			records = append(records, record)
+		}
+	}
+
+	return &tableChunk{
+		tableName:  chunk.getTableName(),
+		headers:    chunk.getHeaders(),
+		records:    records,
+		columnInfo: chunk.getColumnInfo(),
+	}, nil
+}
@@ -0,0 +1,111 @@
+package filesql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeWindowContains(t *testing.T) {
+	t.Parallel()
+
+	window := timeWindow{
+		column: "ts",
+		from:   time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		to:     time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "start of the window is inside", value: "2024-03-04", want: true},
+		{name: "datetime inside the window", value: "2024-03-10 23:59:59", want: true},
+		{name: "time zone is taken into account", value: "2024-03-11T08:00:00+09:00", want: true},
+		{name: "end of the window is outside", value: "2024-03-11T00:00:00Z", want: false},
+		{name: "before the window", value: "2024-03-03 23:59:59", want: false},
+		{name: "empty value", value: "", want: false},
+		{name: "not a datetime", value: "yesterday", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, window.contains(tt.value))
+		})
+	}
+
+	t.Run("a zero bound leaves the window open", func(t *testing.T) {
+		t.Parallel()
+
+		open := timeWindow{column: "ts", from: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)}
+		assert.True(t, open.contains("2099-01-01"))
+		assert.False(t, open.contains("2024-03-03"))
+	})
+}
+
+func TestDBBuilder_WithTimeWindow(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	logs := "Timestamp,status\n" +
+		"2024-03-01 10:00:00,200\n" +
+		"2024-03-04 00:00:00,500\n" +
+		"2024-03-08 12:30:00,404\n" +
+		"2024-03-11 00:00:00,200\n" +
+		",200\n"
+
+	t.Run("rows outside the window are discarded while loading", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "access_log.csv")
+		require.NoError(t, os.WriteFile(path, []byte(logs), 0600))
+
+		db := openTestDB(t, NewBuilder().
+			AddPath(path).
+			SetDefaultChunkSize(2).
+			WithTimeWindow("ACCESS_LOG", "timestamp", from, to))
+
+		assert.Equal(t, []string{"500", "404"}, queryRows(t, db, "SELECT status FROM access_log ORDER BY Timestamp"))
+	})
+
+	t.Run("a table without rows in the window is created empty", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(logs), "access_log", FileTypeCSV).
+			WithTimeWindow("access_log", "Timestamp", time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM access_log").Scan(&count))
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("a missing column fails the load", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := tryOpenTestDB(t, NewBuilder().
+			AddReader(strings.NewReader(logs), "access_log", FileTypeCSV).
+			WithTimeWindow("access_log", "created_at", from, to))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+
+	t.Run("an empty window fails the build", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewBuilder().
+			AddReader(strings.NewReader(logs), "access_log", FileTypeCSV).
+			WithTimeWindow("access_log", "Timestamp", to, from).
+			Build(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
@@ -0,0 +1,242 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// TypeInference selects how the SQLite types of loaded columns are chosen.
+type TypeInference int
+
+const (
+	// TypeInferenceSample infers column types from the first chunk of each input (default).
+	// It is fast, but a column whose first rows are all numbers is created as INTEGER or
+	// REAL even if later rows hold text.
+	TypeInferenceSample TypeInference = iota
+	// TypeInferenceFullScan infers column types from every row of each input. Inputs are
+	// loaded into TEXT columns first and converted once all rows have been read.
+	TypeInferenceFullScan
+	// TypeInferenceNone creates every column as TEXT
+	TypeInferenceNone
+)
+
+// typingTablePrefix is prepended to table names while they are converted to their inferred types
+const typingTablePrefix = "_filesql_typing_"
+
+// String returns the string representation of TypeInference
+func (t TypeInference) String() string {
+	switch t {
+	case TypeInferenceSample:
+		return "sample"
+	case TypeInferenceFullScan:
+		return "full_scan"
+	case TypeInferenceNone:
+		return "none"
+	default:
+		return "sample"
+	}
+}
+
+// parseTypeInference converts a mode name into a TypeInference
+func parseTypeInference(name string) (TypeInference, error) {
+	for _, mode := range []TypeInference{TypeInferenceSample, TypeInferenceFullScan, TypeInferenceNone} {
+		if strings.EqualFold(name, mode.String()) {
+			return mode, nil
+		}
+	}
+	return TypeInferenceSample, newCodedError(ErrCodeInvalidConfig, "unsupported type inference: %s", name)
+}
+
+// WithTypeInference sets how column types are inferred.
+//
+// Columns are created as INTEGER, REAL, or TEXT so that numeric comparisons, ORDER BY,
+// and aggregations work without CAST. By default (TypeInferenceSample) types are
+// inferred from the first chunk of each input, which holds DefaultRowsPerChunk rows.
+// TypeInferenceFullScan looks at every row, which is slower but never creates a
+// numeric column for data whose text values only appear late in the file.
+// TypeInferenceNone keeps every column as TEXT, as the files themselves do.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("orders.csv"). // order_no: 1, 2, ..., then "A-1001" in row 50000
+//		WithTypeInference(filesql.TypeInferenceFullScan)
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithTypeInference(mode TypeInference) *DBBuilder {
+	b.streamProcessor.typeInference = mode
+	return b
+}
+
+// EnableEmptyNumericAsNull loads the empty values of INTEGER and REAL columns as NULL.
+//
+// Without this option, an empty value is stored as an empty string, which SQLite
+// treats as 0 in arithmetic, so AVG and comparisons count missing numbers as zero.
+// With it, aggregates skip missing values and "IS NULL" finds them. TEXT columns keep
+// their empty strings.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("scores.csv"). // score: 80, (empty), 100
+//		EnableEmptyNumericAsNull()
+//	// SELECT AVG(score) FROM scores → 90
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableEmptyNumericAsNull() *DBBuilder {
+	b.streamProcessor.emptyNumericAsNull = true
+	return b
+}
+
+// withTextColumns returns the chunk with every column typed as TEXT
+func withTextColumns(chunk *tableChunk) *tableChunk {
+	infos := make([]columnInfo, len(chunk.getColumnInfo()))
+	for i, info := range chunk.getColumnInfo() {
+		infos[i] = newColumnInfoWithType(info.Name, columnTypeText)
+	}
+	return &tableChunk{
+		tableName:  chunk.getTableName(),
+		headers:    chunk.getHeaders(),
+		records:    chunk.getRecords(),
+		columnInfo: infos,
+	}
+}
+
+// typeScan collects the value types of every column of an input for TypeInferenceFullScan
+type typeScan struct {
+	// names are the column names
+	names []string
+	// counts are the number of non-empty values of each type per column
+	counts []map[columnType]int
+	// nonEmpty is the number of non-empty values per column
+	nonEmpty []int
+	// numericText reports per column whether a value must stay TEXT to keep its digits
+	numericText []bool
+}
+
+// newTypeScan returns a scan for the columns of chunk
+func newTypeScan(chunk *tableChunk) *typeScan {
+	infos := chunk.getColumnInfo()
+	scan := &typeScan{
+		names:       make([]string, len(infos)),
+		counts:      make([]map[columnType]int, len(infos)),
+		nonEmpty:    make([]int, len(infos)),
+		numericText: make([]bool, len(infos)),
+	}
+	for i, info := range infos {
+		scan.names[i] = info.Name
+		scan.counts[i] = make(map[columnType]int)
+	}
+	return scan
+}
+
+// add counts the value types of the records of chunk, skipping the null values of
+// each column
+func (s *typeScan) add(chunk *tableChunk, nulls [][]string) {
+	for _, record := range chunk.getRecords() {
+		for i := range s.names {
+			if i >= len(record) {
+				break
+			}
+			value := strings.TrimSpace(record[i])
+			if value == "" || isNullValue(nulls, i, value) {
+				continue
+			}
+			s.nonEmpty[i]++
+			s.counts[i][classifyValue(value)]++
+			if !s.numericText[i] && isNumericText(record[i]) {
+				s.numericText[i] = true
+			}
+		}
+	}
+}
+
+// columns returns the columns with the types inferred from all scanned values
+func (s *typeScan) columns(preserveNumericText bool) []columnInfo {
+	infos := make([]columnInfo, len(s.names))
+	for i, name := range s.names {
+		columnType := columnTypeText
+		if s.nonEmpty[i] > 0 {
+			columnType = selectColumnType(s.counts[i], s.nonEmpty[i])
+		}
+		if preserveNumericText && s.numericText[i] && (columnType == columnTypeInteger || columnType == columnTypeReal) {
+			columnType = columnTypeText
+		}
+		infos[i] = newColumnInfoWithType(name, columnType)
+	}
+	return infos
+}
+
+// retypeTable converts the TEXT columns of a table loaded with TypeInferenceFullScan
+// to the types inferred from all of its values
+func (sp *streamProcessor) retypeTable(ctx context.Context, db *sql.DB, tableName string, scan *typeScan) error {
+	columns := scan.columns(sp.preserveNumericText)
+	retyped := false
+	for _, column := range columns {
+		if column.Type.string() != sqlTypeText {
+			retyped = true
+			break
+		}
+	}
+	if !retyped {
+		return nil
+	}
+
+	typingName := typingTablePrefix + tableName
+	selects := make([]string, len(columns))
+	for i, column := range columns {
+		selects[i] = quoteIdentifier(column.Name)
+		if sp.emptyNumericAsNull && column.Type.string() != sqlTypeText {
+			selects[i] = fmt.Sprintf("CASE WHEN TRIM(%[1]s) = '' THEN NULL ELSE %[1]s END", selects[i])
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to convert column types of table %s: %w", tableName, err)
+	}
+	defer func() { _ = tx.Rollback() }() // No-op after Commit
+
+	statements := []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteIdentifier(typingName)),
+		createTableQuery(typingName, columns),
+		fmt.Sprintf("INSERT INTO %s SELECT %s FROM %s", quoteIdentifier(typingName), strings.Join(selects, ", "), quoteIdentifier(tableName)), //nolint:gosec // Identifiers are quoted
+		fmt.Sprintf("DROP TABLE %s", quoteIdentifier(tableName)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteIdentifier(typingName), quoteIdentifier(tableName)),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to convert column types of table %s: %w", tableName, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// createTableQuery returns the CREATE TABLE statement of a table with columns
+func createTableQuery(tableName string, columns []columnInfo) string {
+	definitions := make([]string, len(columns))
+	for i, column := range columns {
+		definitions[i] = fmt.Sprintf(`"%s" %s`, column.Name, column.Type.string())
+	}
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s" (%s)`, tableName, strings.Join(definitions, ", "))
+}
+
+// insertValues returns the values of a record to insert, with the null values of each
+// column (see WithNullValues) and, when EnableEmptyNumericAsNull is set, empty values
+// of INTEGER and REAL columns replaced by NULL
+func (sp *streamProcessor) insertValues(record Record, columns []columnInfo, nulls [][]string) []any {
+	values := make([]any, len(record))
+	for i, value := range record {
+		values[i] = value
+		if isNullValue(nulls, i, value) {
+			values[i] = nil
+			continue
+		}
+		if sp.emptyNumericAsNull && i < len(columns) && columns[i].Type.string() != sqlTypeText && strings.TrimSpace(value) == "" {
+			values[i] = nil
+		}
+	}
+	return values
+}
@@ -0,0 +1,101 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypeInference(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []TypeInference{TypeInferenceSample, TypeInferenceFullScan, TypeInferenceNone} {
+		parsed, err := parseTypeInference(strings.ToUpper(mode.String()))
+		require.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+	}
+
+	_, err := parseTypeInference("guess")
+	assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+}
+
+func TestDBBuilder_WithTypeInference(t *testing.T) {
+	t.Parallel()
+
+	// The text value of order_no only appears after the first chunk
+	orders := "order_no,amount,note\n1,10.5,a\n2,3,b\n3,7,c\nA-4,2.25,d\n"
+
+	// builder loads orders in chunks of two rows
+	builder := func(options ...Option) func(*testing.T) *DBBuilder {
+		return func(*testing.T) *DBBuilder {
+			builder := NewBuilder().
+				AddReader(strings.NewReader(orders), "orders", FileTypeCSV).
+				Apply(options...)
+			builder.streamProcessor.chunkSize = 2
+			return builder
+		}
+	}
+	const types = "SELECT name || ' ' || type FROM pragma_table_info('orders') ORDER BY cid"
+	runLoadTests(t, []loadTest{
+		{
+			name:    "sample infers types from the first chunk",
+			builder: builder(),
+			want:    map[string][]string{types: {"order_no INTEGER", "amount REAL", "note TEXT"}},
+		},
+		{
+			name:    "full scan infers types from every row",
+			builder: builder(WithTypeInference(TypeInferenceFullScan)),
+			want: map[string][]string{
+				types: {"order_no TEXT", "amount REAL", "note TEXT"},
+				"SELECT SUM(amount) FROM orders WHERE amount > 2.5": {"20.5"},
+			},
+		},
+		{
+			name:    "none keeps every column as TEXT",
+			builder: builder(WithTypeInference(TypeInferenceNone)),
+			want:    map[string][]string{types: {"order_no TEXT", "amount TEXT", "note TEXT"}},
+		},
+	})
+}
+
+func TestDBBuilder_EnableEmptyNumericAsNull(t *testing.T) {
+	t.Parallel()
+
+	scores := "name,score\nalice,80\nbob,\ncarol,100\ndave, \n"
+
+	for _, mode := range []TypeInference{TypeInferenceSample, TypeInferenceFullScan} {
+		t.Run(mode.String(), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			db := openTestDB(t, NewBuilder().
+				AddReader(strings.NewReader(scores), "scores", FileTypeCSV).
+				WithTypeInference(mode).
+				EnableEmptyNumericAsNull())
+
+			var average float64
+			var missing int
+			require.NoError(t, db.QueryRowContext(ctx,
+				"SELECT AVG(score), SUM(score IS NULL) FROM scores").Scan(&average, &missing))
+			assert.InDelta(t, 90.0, average, 0.001)
+			assert.Equal(t, 2, missing)
+		})
+	}
+
+	t.Run("text columns keep empty strings", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("name,note\nalice,\n"), "notes", FileTypeCSV).
+			EnableEmptyNumericAsNull())
+
+		var note sql.NullString
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT note FROM notes").Scan(&note))
+		assert.True(t, note.Valid)
+	})
+}
@@ -44,6 +44,10 @@ const (
 	csvDelimiter = ','
 	// tsvDelimiter is the delimiter for TSV files
 	tsvDelimiter = '\t'
+	// ssvDelimiter is the delimiter for SSV files
+	ssvDelimiter = ';'
+	// psvDelimiter is the delimiter for PSV files
+	psvDelimiter = '|'
 )
 
 // TableName represents a table name with validation
@@ -401,15 +405,22 @@ const (
 
 // isDatetime checks if a string value represents a datetime with optimized pattern matching
 func isDatetime(value string) bool {
+	_, ok := parseDatetime(value)
+	return ok
+}
+
+// parseDatetime parses a value in one of the datetime formats recognized by type
+// inference. Values without a time zone are parsed as UTC.
+func parseDatetime(value string) (time.Time, bool) {
 	value = strings.TrimSpace(value)
 	if value == "" {
-		return false
+		return time.Time{}, false
 	}
 
 	// Quick length-based filtering to avoid regex on obviously non-datetime values
 	valueLen := len(value)
 	if valueLen < MinDatetimeLength || valueLen > MaxDatetimeLength {
-		return false
+		return time.Time{}, false
 	}
 
 	// Quick character check - datetime must contain at least one digit and separator
@@ -426,7 +437,7 @@ func isDatetime(value string) bool {
 		}
 	}
 	if !hasDigit || !hasSeparator {
-		return false
+		return time.Time{}, false
 	}
 
 	// Test patterns with early termination
@@ -434,14 +445,14 @@ func isDatetime(value string) bool {
 		if dp.pattern.MatchString(value) {
 			// Try each format for this pattern
 			for _, format := range dp.formats {
-				if _, err := time.Parse(format, value); err == nil {
-					return true
+				if t, err := time.Parse(format, value); err == nil {
+					return t, true
 				}
 			}
 		}
 	}
 
-	return false
+	return time.Time{}, false
 }
 
 // inferColumnType infers the SQL column type from a slice of string values with optimized sampling
@@ -0,0 +1,133 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+)
+
+// AddPathsAsTable loads the files of paths into the single table table, appending their
+// rows in the order of the files.
+//
+// Log and event exports are often partitioned into files that share a schema, such as
+// events_2023.csv and events_2024.csv. AddPathsAsTable loads them as one table, so
+// queries do not need a UNION ALL over a table per file. Paths are files, directories,
+// or glob patterns (see AddGlob), and every file found through them is appended.
+//
+// The files must have the same columns, compared by name ignoring case and order; Open
+// fails with ErrCodeInvalidData naming the file whose columns differ. Column types are
+// inferred from the first file. Build fails with ErrCodeInvalidConfig for S3, Cloud
+// Storage, and URL inputs, and with ErrCodeDuplicateTable if another file produces the
+// table, unless WithTableCollisionPolicy(TableCollisionMerge) is used.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPathsAsTable("events", "events_2023.csv", "events_2024.csv").
+//		AddPathsAsTable("access", "logs/**/access-*.tsv.gz")
+//	// SELECT COUNT(*) FROM events → the rows of both files
+//
+// Returns self for chaining.
+func (b *DBBuilder) AddPathsAsTable(table string, paths ...string) *DBBuilder {
+	for _, path := range paths {
+		added, remotes := len(b.paths), len(b.remotes)
+		b.AddPath(path)
+
+		if len(b.remotes) > remotes && b.remotes[remotes].err == nil {
+			b.remotes[remotes].err = newCodedError(ErrCodeInvalidConfig, "AddPathsAsTable loads local files only: %s", path)
+		}
+		if len(b.paths) > added {
+			if b.pathTables == nil {
+				b.pathTables = make(map[int]string)
+			}
+			b.pathTables[added] = table
+		}
+	}
+	return b
+}
+
+// mapUnionTables maps the collected paths found through paths added with
+// AddPathsAsTable to their table, and fails if another file produces one of the tables
+func (b *DBBuilder) mapUnionTables(collected *collectedFiles) error {
+	sp := b.streamProcessor
+	sp.unionTables = nil
+	if len(b.pathTables) == 0 {
+		return nil
+	}
+
+	for _, table := range b.pathTables {
+		if strings.TrimSpace(table) == "" {
+			return newCodedError(ErrCodeInvalidConfig, "table name of AddPathsAsTable cannot be empty")
+		}
+		if strings.HasPrefix(table, metadataTablePrefix) {
+			return newCodedError(ErrCodeInvalidConfig, "table name of AddPathsAsTable cannot start with %s: %s", metadataTablePrefix, table)
+		}
+	}
+
+	if sp.tableNames == nil {
+		sp.tableNames = make(map[string]string)
+	}
+	sp.unionTables = make(map[string]bool)
+	var others []string
+	for i, path := range collected.paths {
+		table, ok := b.pathTables[collected.origins[i]]
+		if !ok {
+			others = append(others, path)
+			continue
+		}
+		if _, named := sp.tableNames[path]; !named {
+			sp.tableNames[path] = table
+		}
+		sp.unionTables[strings.ToLower(table)] = true
+	}
+	if sp.tableCollision == TableCollisionMerge {
+		return nil
+	}
+	for _, path := range others {
+		if table := sp.fileTableName(path); sp.unionTables[strings.ToLower(table)] {
+			return newCodedError(ErrCodeDuplicateTable, "file %s produces table '%s' of AddPathsAsTable, duplicate table names are not allowed", path, table)
+		}
+	}
+	return nil
+}
+
+// isUnionTable reports whether table is loaded from the files of AddPathsAsTable
+func (sp *streamProcessor) isUnionTable(table string) bool {
+	return sp.unionTables[strings.ToLower(table)]
+}
+
+// mergesInto reports whether the rows of an input producing table are appended to the
+// table when it already exists
+func (sp *streamProcessor) mergesInto(table string) bool {
+	return sp.tableCollision == TableCollisionMerge || sp.isUnionTable(table)
+}
+
+// checkUnionColumns fails if the columns of table source, the rows of a file of
+// AddPathsAsTable, differ from the columns of the table target
+func checkUnionColumns(ctx context.Context, db *sql.DB, source, target string) error {
+	sourceColumns, err := tableColumnTypes(ctx, db, source)
+	if err != nil {
+		return err
+	}
+	targetColumns, err := tableColumnTypes(ctx, db, target)
+	if err != nil {
+		return err
+	}
+	sourceNames, targetNames := columnNameSet(sourceColumns), columnNameSet(targetColumns)
+	if !slices.Equal(sourceNames, targetNames) {
+		return newCodedError(ErrCodeInvalidData, "cannot append to table %s: columns (%s) differ from the columns of the table (%s)",
+			target, strings.Join(sourceNames, ", "), strings.Join(targetNames, ", "))
+	}
+	return nil
+}
+
+// columnNameSet returns the lowercase names of columns, sorted
+func columnNameSet(columns []declaredColumn) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = strings.ToLower(column.name)
+	}
+	slices.Sort(names)
+	return names
+}
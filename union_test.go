@@ -0,0 +1,101 @@
+package filesql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_AddPathsAsTable(t *testing.T) {
+	t.Parallel()
+
+	// writeFiles writes files into a new directory and returns it
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+
+		dir := t.TempDir()
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+		return dir
+	}
+	events := map[string]string{
+		"events_2023.csv": "id,name\n1,signup\n2,login\n",
+		"events_2024.csv": "name,id\nlogout,3\n",
+	}
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "the rows of every file are appended",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := writeFiles(t, events)
+				return NewBuilder().
+					AddPathsAsTable("events", filepath.Join(dir, "events_2023.csv"), filepath.Join(dir, "events_2024.csv"))
+			},
+			tables: []string{"events"},
+			want:   map[string][]string{"SELECT name FROM events ORDER BY id": {"signup", "login", "logout"}},
+		},
+		{
+			name: "patterns and directories are expanded",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := writeFiles(t, map[string]string{
+					filepath.Join("2023", "events.csv"): "id\n1\n",
+					filepath.Join("2024", "events.csv"): "id\n2\n",
+					"other.csv":                         "id\n3\n",
+				})
+				return NewBuilder().
+					AddPathsAsTable("events", filepath.Join(dir, "**", "events.csv")).
+					AddPath(filepath.Join(dir, "other.csv"))
+			},
+			want: map[string][]string{
+				"SELECT COUNT(*) FROM events": {"2"},
+				"SELECT COUNT(*) FROM other":  {"1"},
+			},
+		},
+		{
+			name: "files with other columns fail the load",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := writeFiles(t, map[string]string{
+					"events_2023.csv": "id,name\n1,signup\n",
+					"events_2024.csv": "id,name,source\n2,login,web\n",
+				})
+				return NewBuilder().AddPathsAsTable("events", filepath.Join(dir, "events_*.csv"))
+			},
+			wantErr:    ErrCodeInvalidData,
+			wantErrMsg: "columns (id, name, source) differ",
+		},
+		{
+			name: "tables of other inputs cannot have the same name",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := writeFiles(t, map[string]string{
+					"events_2023.csv": "id\n1\n",
+					"events.csv":      "id\n2\n",
+				})
+				return NewBuilder().
+					AddPathsAsTable("events", filepath.Join(dir, "events_2023.csv")).
+					AddPath(filepath.Join(dir, "events.csv"))
+			},
+			wantErr: ErrCodeDuplicateTable,
+		},
+		{
+			name: "the table name cannot be empty",
+			builder: func(t *testing.T) *DBBuilder {
+				dir := writeFiles(t, events)
+				return NewBuilder().AddPathsAsTable("", filepath.Join(dir, "events_2023.csv"))
+			},
+			wantErr: ErrCodeInvalidConfig,
+		},
+		{
+			name: "URLs are rejected",
+			builder: func(*testing.T) *DBBuilder {
+				return NewBuilder().AddPathsAsTable("events", "https://example.com/events.csv")
+			},
+			wantErr:    ErrCodeInvalidConfig,
+			wantErrMsg: "loads local files only",
+		},
+	})
+}
@@ -0,0 +1,120 @@
+package filesql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddURL(t *testing.T) {
+	t.Parallel()
+
+	// newServer serves files by path to requests with the bearer token "secret"
+	newServer := func(t *testing.T) *httptest.Server {
+		t.Helper()
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		_, err := writer.Write([]byte("id,amount\n1,100\n2,250\n"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		files := map[string][]byte{
+			"/exports/users.csv":     []byte("id,name\n1,Alice\n2,Bob\n"),
+			"/exports/sales.csv.gz":  compressed.Bytes(),
+			"/exports/orders.tsv":    []byte("id\tstatus\n1\tshipped\n"),
+			"/exports/protected.csv": []byte("id\n1\n"),
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/exports/protected.csv" && r.Header.Get("Authorization") != "Bearer secret" {
+				http.Error(w, "missing token", http.StatusUnauthorized)
+				return
+			}
+			body, ok := files[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(body)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	t.Run("URLs are loaded with Open, AddPath, and AddURL", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t)
+		db, err := Open(server.URL+"/exports/users.csv", server.URL+"/exports/sales.csv.gz?day=2024-06-01")
+		require.NoError(t, err)
+		defer db.Close()
+
+		var name string
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT name FROM users WHERE id = 2").Scan(&name))
+		assert.Equal(t, "Bob", name)
+		var total int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT SUM(amount) FROM sales").Scan(&total))
+		assert.Equal(t, 350, total)
+
+		db2 := openTestDB(t, NewBuilder().
+			AddURL(server.URL+"/exports/orders.tsv").
+			AddURL(server.URL+"/exports/protected.csv").
+			WithHTTPHeader("Authorization", "Bearer secret"))
+
+		tables, err := getSQLiteTableNames(db2)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"orders", "protected"}, tables)
+	})
+
+	t.Run("download errors have codes", func(t *testing.T) {
+		t.Parallel()
+
+		server := newServer(t)
+		tests := []struct {
+			name string
+			url  string
+			code ErrorCode
+		}{
+			{name: "not found", url: server.URL + "/exports/missing.csv", code: ErrCodePathNotFound},
+			{name: "unauthorized", url: server.URL + "/exports/protected.csv", code: ErrCodePermissionDenied},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := Open(tt.url)
+				require.Error(t, err)
+				assert.Equal(t, tt.code, ErrorCodeOf(err))
+				assert.Contains(t, err.Error(), tt.url)
+			})
+		}
+	})
+
+	t.Run("invalid URLs fail Build", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name string
+			url  string
+			code ErrorCode
+		}{
+			{name: "no file name", url: "https://example.com/exports/", code: ErrCodeInvalidConfig},
+			{name: "no host", url: "https:///users.csv", code: ErrCodeInvalidConfig},
+			{name: "other scheme", url: "ftp://example.com/users.csv", code: ErrCodeInvalidConfig},
+			{name: "unsupported extension", url: "https://example.com/index.html", code: ErrCodeUnsupportedFormat},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := NewBuilder().AddURL(tt.url).Build(context.Background())
+				require.Error(t, err)
+				assert.Equal(t, tt.code, ErrorCodeOf(err))
+			})
+		}
+	})
+}
@@ -20,13 +20,13 @@ func newValidator() *validator {
 // validatePath validates a single file or directory path
 func (v *validator) validatePath(path string) error {
 	if strings.TrimSpace(path) == "" {
-		return errors.New("path cannot be empty")
+		return newCodedError(ErrCodeInvalidConfig, "path cannot be empty")
 	}
 
 	info, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return fmt.Errorf("failed to load file: path does not exist: %s", path)
+			return newCodedError(ErrCodePathNotFound, "failed to load file: path does not exist: %s", path)
 		}
 		return fmt.Errorf("failed to stat path %s: %w", path, err)
 	}
@@ -34,7 +34,7 @@ func (v *validator) validatePath(path string) error {
 	// For files, check if they are supported
 	if !info.IsDir() {
 		if !isSupportedFile(path) {
-			return fmt.Errorf("unsupported file type: %s", path)
+			return newCodedError(ErrCodeUnsupportedFormat, "unsupported file type: %s", path)
 		}
 	}
 
@@ -44,13 +44,13 @@ func (v *validator) validatePath(path string) error {
 // validateReader validates a reader input
 func (v *validator) validateReader(reader any, tableName string, fileType FileType) error {
 	if reader == nil {
-		return errors.New("reader cannot be nil")
+		return newCodedError(ErrCodeInvalidConfig, "reader cannot be nil")
 	}
 	if tableName == "" {
-		return errors.New("table name must be specified for reader input")
+		return newCodedError(ErrCodeInvalidConfig, "table name must be specified for reader input")
 	}
 	if fileType == FileTypeUnsupported {
-		return errors.New("file type must be specified for reader input")
+		return newCodedError(ErrCodeUnsupportedFormat, "file type must be specified for reader input")
 	}
 
 	// For specific readers where we can safely peek without consuming, validate empty content
@@ -58,13 +58,19 @@ func (v *validator) validateReader(reader any, tableName string, fileType FileTy
 	if stringReader, ok := reader.(*strings.Reader); ok && stringReader.Len() == 0 {
 		switch fileType.baseType() {
 		case FileTypeCSV:
-			return errors.New("empty CSV data")
+			return newCodedError(ErrCodeEmptyData, "empty CSV data")
 		case FileTypeTSV:
-			return errors.New("empty TSV data")
+			return newCodedError(ErrCodeEmptyData, "empty TSV data")
+		case FileTypeSSV:
+			return newCodedError(ErrCodeEmptyData, "empty SSV data")
+		case FileTypePSV:
+			return newCodedError(ErrCodeEmptyData, "empty PSV data")
 		case FileTypeLTSV:
-			return errors.New("empty LTSV data")
+			return newCodedError(ErrCodeEmptyData, "empty LTSV data")
+		case FileTypeJSONL:
+			return newCodedError(ErrCodeEmptyData, "empty JSONL data")
 		default:
-			return errors.New("reader contains no data")
+			return newCodedError(ErrCodeEmptyData, "reader contains no data")
 		}
 	}
 
@@ -85,17 +91,40 @@ func (v *validator) validateAutoSaveConfig(config *autoSaveConfig) error {
 		return nil // Disabled config is valid
 	}
 
+	if err := config.options.validateFloatFormats(); err != nil {
+		return fmt.Errorf("invalid auto-save options: %w", err)
+	}
+
 	// Validate output directory if specified
 	if config.outputDir != "" {
 		// Check if parent directory exists for non-empty output directory
 		if err := v.validateOutputDirectory(config.outputDir); err != nil {
 			return fmt.Errorf("invalid auto-save output directory: %w", err)
 		}
+		if err := checkOutputPath(config.options.OutputRoot, config.outputDir); err != nil {
+			return fmt.Errorf("invalid auto-save output directory: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// validateTempDir validates that the temporary directory exists if specified
+func (v *validator) validateTempDir(dir string) error {
+	if dir == "" {
+		return nil // The temporary directory is optional
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return newCodedError(ErrCodeInvalidConfig, "invalid temporary directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return newCodedError(ErrCodeInvalidConfig, "invalid temporary directory %s: not a directory", dir)
+	}
+	return nil
+}
+
 // validateOutputDirectory validates that the output directory can be created/accessed
 func (v *validator) validateOutputDirectory(outputDir string) error {
 	// For overwrite mode (empty outputDir), no validation needed
@@ -129,18 +158,54 @@ func (v *validator) validateFinalState(collectedPaths []string, readers []reader
 		}
 
 		if hasDirectories {
-			return errors.New("no supported files found in directory")
+			return newCodedError(ErrCodeUnsupportedFormat, "no supported files found in directory")
 		}
-		return errors.New("no valid input files found")
+		return newCodedError(ErrCodeInvalidConfig, "no valid input files found")
 	}
 
 	return nil
 }
 
+// validateDerivedTable validates a derived table configuration
+func (v *validator) validateDerivedTable(derived derivedTable) error {
+	if strings.TrimSpace(derived.name) == "" {
+		return errors.New("derived table name cannot be empty")
+	}
+	if strings.HasPrefix(derived.name, metadataTablePrefix) {
+		return fmt.Errorf("derived table name cannot start with %s: %s", metadataTablePrefix, derived.name)
+	}
+	if strings.TrimSpace(derived.query) == "" {
+		return fmt.Errorf("query for derived table %s cannot be empty", derived.name)
+	}
+	return nil
+}
+
+// validateTableRules validates the data quality rules configured for a table
+func (v *validator) validateTableRules(tr tableRules) error {
+	if strings.TrimSpace(tr.table) == "" {
+		return errors.New("validation table name cannot be empty")
+	}
+	for _, rule := range tr.rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid validation rule for table %s: %w", tr.table, err)
+		}
+	}
+	return nil
+}
+
+// validateBuilt checks that a builder was returned by Build, which validates a copy of
+// the builder it is called on
+func (v *validator) validateBuilt(validated bool) error {
+	if !validated {
+		return newCodedError(ErrCodeInvalidConfig, "builder is not validated: call Open on the builder returned by Build()")
+	}
+	return nil
+}
+
 // validateInputsAvailable checks if any valid inputs are available for database creation
 func (v *validator) validateInputsAvailable(collectedPaths []string, readers []readerInput) error {
 	if len(collectedPaths) == 0 && len(readers) == 0 {
-		return errors.New("no valid input files found, did you call Build()?")
+		return newCodedError(ErrCodeInvalidConfig, "no valid input files found, did you call Build()?")
 	}
 	return nil
 }
@@ -0,0 +1,270 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce is how long EnableWatch waits after the last change of a file
+// before it reloads the tables of the file, so a file written in several steps is
+// loaded once
+const DefaultWatchDebounce = 100 * time.Millisecond
+
+// WatchEvent reports a table that EnableWatch reloaded because its files changed.
+type WatchEvent struct {
+	// Table is the name of the reloaded table
+	Table string
+	// Paths are the changed files of the table
+	Paths []string
+	// Err is the error of a failed reload, which leaves the table as it was; nil if
+	// the table was reloaded
+	Err error
+}
+
+// EnableWatch watches the input files and reloads the tables of a file when it
+// changes, which turns the database into a live view over a data directory.
+//
+// The directories of the collected files are watched with the file system
+// notifications of the operating system, so changes are seen right away without
+// polling. A table is reloaded with Reload once its files stayed unchanged for
+// DefaultWatchDebounce, so a file that is written in several steps, or saved by an
+// editor that replaces it, is loaded once. Files created after Build, and inputs other
+// than files, are not watched. Watching starts when Open returns and stops when the
+// database is closed.
+//
+// handler, when not nil, is called after every reload with the table and the changed
+// files, and with the error of a reload that failed, for example because a file was
+// saved half-way; the table is left as it was and reloaded on the next change.
+// handler is called from a goroutine of the watcher, one event at a time.
+//
+// Example:
+//
+//	builder := filesql.NewBuilder().
+//		AddPath("dashboards/data/").
+//		EnableWatch(func(event filesql.WatchEvent) {
+//			if event.Err != nil {
+//				log.Printf("reload of %s failed: %v", event.Table, event.Err)
+//				return
+//			}
+//			log.Printf("reloaded %s", event.Table)
+//		})
+//
+// Build fails with ErrCodeInvalidConfig when EnableWatch is combined with
+// EnableLazyLoading, and StartLoad does not watch the files.
+//
+// Returns self for chaining.
+func (b *DBBuilder) EnableWatch(handler func(WatchEvent)) *DBBuilder {
+	b.watch = true
+	b.watchHandler = handler
+	return b
+}
+
+// validateWatch checks that watching can be used with the other options
+func (b *DBBuilder) validateWatch() error {
+	if b.watch && b.lazyLoading {
+		return newCodedError(ErrCodeInvalidConfig, "EnableWatch cannot be combined with EnableLazyLoading")
+	}
+	return nil
+}
+
+// tableWatcher reloads the tables of a database when their files change
+type tableWatcher struct {
+	builder *DBBuilder
+	handler func(WatchEvent)
+	clock   Clock
+
+	// watcher delivers the notifications of the watched directories
+	watcher *fsnotify.Watcher
+	// files maps the absolute paths of the collected files to the collected paths
+	files map[string]string
+	// db is the database whose tables are reloaded
+	db *sql.DB
+	// ctx is cancelled when watching stops, which cancels a running reload
+	ctx    context.Context
+	cancel context.CancelFunc
+	// done is closed when the goroutine reading the notifications returns
+	done chan struct{}
+
+	// reloadMu serializes reloads
+	reloadMu sync.Mutex
+
+	mu sync.Mutex
+	// changed are the collected paths that changed since the last reload
+	changed map[string]bool
+	// stopTimer cancels the pending reload
+	stopTimer func() bool
+	// stopped is true once watching stopped
+	stopped bool
+}
+
+// newTableWatcher returns the watcher of the builder's files, or nil without EnableWatch
+func (b *DBBuilder) newTableWatcher() *tableWatcher {
+	if !b.watch {
+		return nil
+	}
+	clock := b.clock
+	if clock == nil {
+		clock = NewSystemClock()
+	}
+	return &tableWatcher{builder: b, handler: b.watchHandler, clock: clock, changed: make(map[string]bool)}
+}
+
+// start starts watching the files and reloading the tables of db. It does nothing on
+// a nil receiver, so callers need not check whether watching is enabled.
+func (w *tableWatcher) start(db *sql.DB) error {
+	if w == nil {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch files: %w", err)
+	}
+
+	w.files = make(map[string]string, len(w.builder.collectedPaths))
+	for _, path := range w.builder.collectedPaths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			_ = watcher.Close() // Ignore close error during error handling
+			return fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+		}
+		w.files[absPath] = path
+	}
+	// Directories are watched rather than files, so files replaced by a rename, as
+	// many editors save them, are still watched
+	watched := make(map[string]bool)
+	for absPath := range w.files {
+		dir := filepath.Dir(absPath)
+		if watched[dir] {
+			continue
+		}
+		watched[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close() // Ignore close error during error handling
+			return fmt.Errorf("failed to watch directory %s: %w", dir, err)
+		}
+	}
+
+	w.watcher = watcher
+	w.db = db
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.done = make(chan struct{})
+	go w.run()
+	return nil
+}
+
+// run reads the notifications until the watcher is closed
+func (w *tableWatcher) run() {
+	defer close(w.done)
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			if path, ok := w.files[filepath.Clean(event.Name)]; ok {
+				w.fileChanged(path)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.notify(WatchEvent{Err: fmt.Errorf("failed to watch files: %w", err)})
+		}
+	}
+}
+
+// fileChanged records a change of the file at path and postpones the reload
+func (w *tableWatcher) fileChanged(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped {
+		return
+	}
+	w.changed[path] = true
+	if w.stopTimer != nil {
+		w.stopTimer()
+	}
+	w.stopTimer = w.clock.AfterFunc(DefaultWatchDebounce, w.reload)
+}
+
+// reload reloads the tables of the changed files
+func (w *tableWatcher) reload() {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	w.mu.Lock()
+	if w.stopped || len(w.changed) == 0 {
+		w.mu.Unlock()
+		return
+	}
+	changed := w.changed
+	w.changed = make(map[string]bool)
+	w.mu.Unlock()
+
+	tables, err := listUserTables(w.ctx, w.db)
+	if err != nil {
+		w.notify(WatchEvent{Err: err})
+		return
+	}
+	for _, table := range tables {
+		var paths []string
+		for _, path := range w.builder.tableSourceFiles(table) {
+			if changed[path] {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		slices.Sort(paths)
+		err := w.builder.Reload(w.ctx, w.db, table)
+		if w.ctx.Err() != nil {
+			return
+		}
+		w.notify(WatchEvent{Table: table, Paths: paths, Err: err})
+	}
+}
+
+// notify calls the handler with event
+func (w *tableWatcher) notify(event WatchEvent) {
+	if w.handler != nil {
+		w.handler(event)
+	}
+}
+
+// stop stops watching and waits for a running reload. It does nothing on a nil
+// receiver or a watcher that was not started, and can be called more than once.
+func (w *tableWatcher) stop() error {
+	if w == nil || w.watcher == nil {
+		return nil
+	}
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return nil
+	}
+	w.stopped = true
+	if w.stopTimer != nil {
+		w.stopTimer()
+	}
+	w.mu.Unlock()
+
+	w.cancel()
+	err := w.watcher.Close()
+	<-w.done
+	// Wait for a reload that already started
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+	return err
+}
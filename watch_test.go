@@ -0,0 +1,117 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBBuilder_EnableWatch(t *testing.T) {
+	t.Parallel()
+
+	// open writes files into a new directory and opens it with watching enabled; the
+	// events of the handler are sent to the returned channel
+	open := func(t *testing.T, clock *fakeClock, files map[string]string) (string, *sql.DB, chan WatchEvent) {
+		t.Helper()
+
+		dir := t.TempDir()
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+		}
+		events := make(chan WatchEvent, 10)
+		db := openTestDB(t, NewBuilder().
+			AddPath(dir).
+			WithClock(clock).
+			EnableWatch(func(event WatchEvent) { events <- event }))
+		return dir, db, events
+	}
+	count := func(t *testing.T, db *sql.DB, table string) int {
+		t.Helper()
+
+		var n int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM "+quoteIdentifier(table)).Scan(&n))
+		return n
+	}
+	// waitEvent advances the clock past the debounce until the handler reports an event
+	// that satisfies done. A file written in place can be seen half-written first.
+	waitEvent := func(t *testing.T, clock *fakeClock, events chan WatchEvent, done func(WatchEvent) bool) WatchEvent {
+		t.Helper()
+
+		var event WatchEvent
+		require.Eventually(t, func() bool {
+			clock.advance(DefaultWatchDebounce)
+			for {
+				select {
+				case event = <-events:
+					if done(event) {
+						return true
+					}
+				default:
+					return false
+				}
+			}
+		}, 5*time.Second, 10*time.Millisecond)
+		return event
+	}
+	reloaded := func(event WatchEvent) bool { return event.Err == nil }
+
+	t.Run("a changed file reloads its table", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		dir, db, events := open(t, clock, map[string]string{
+			"users.csv":  "id\n1\n",
+			"orders.csv": "id\n1\n",
+		})
+		path := filepath.Join(dir, "users.csv")
+		require.NoError(t, os.WriteFile(path, []byte("id\n1\n2\n"), 0600))
+
+		event := waitEvent(t, clock, events, reloaded)
+		assert.Equal(t, "users", event.Table)
+		assert.Equal(t, []string{path}, event.Paths)
+		assert.Equal(t, 2, count(t, db, "users"))
+		assert.Equal(t, 1, count(t, db, "orders"))
+	})
+
+	t.Run("a file replaced by a rename reloads its table", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		dir, db, events := open(t, clock, map[string]string{"users.csv": "id\n1\n"})
+		temp := filepath.Join(dir, "users.csv.tmp")
+		require.NoError(t, os.WriteFile(temp, []byte("id\n1\n2\n3\n"), 0600))
+		require.NoError(t, os.Rename(temp, filepath.Join(dir, "users.csv")))
+
+		waitEvent(t, clock, events, reloaded)
+		assert.Equal(t, 3, count(t, db, "users"))
+	})
+
+	t.Run("a failed reload is reported and leaves the table", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		dir, db, events := open(t, clock, map[string]string{"users.csv": "id,name\n1,alice\n"})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,id\n1,2\n"), 0600))
+
+		event := waitEvent(t, clock, events, func(event WatchEvent) bool { return event.Err != nil })
+		assert.Equal(t, "users", event.Table)
+		assert.Equal(t, 1, count(t, db, "users"))
+	})
+
+	t.Run("lazy loading cannot be watched", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewBuilder().
+			AddPath(filepath.Join("testdata", "sample.csv")).
+			EnableLazyLoading().
+			EnableWatch(nil).
+			Build(context.Background())
+		assert.Equal(t, ErrCodeInvalidConfig, ErrorCodeOf(err))
+	})
+}
@@ -0,0 +1,133 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WithWorkbook writes every table as a sheet of a single Excel workbook named name
+// instead of one file per table. It also sets the format to OutputFormatXLSX.
+//
+// Loading a workbook creates one table per sheet, named after the file and the sheet
+// ("sales.xlsx" with the sheet "Q1" becomes the table "sales_Q1"). WithWorkbook
+// reverses this naming: a table whose name starts with the table name of the workbook
+// and an underscore is written to the sheet named by the rest of its name, and other
+// tables to sheets named like the table. Loading the workbook again therefore gives
+// the tables their original names.
+//
+// Example:
+//
+//	db, err := filesql.Open("sales.xlsx") // tables sales_Q1 and sales_Q2
+//	// ... modify the tables ...
+//	options := filesql.NewDumpOptions().WithWorkbook("sales")
+//	err = filesql.DumpDatabase(db, "./output", options) // output/sales.xlsx with sheets Q1 and Q2
+//
+// Excel limits sheet names to 31 characters, and two tables must not map to the same
+// sheet; DumpDatabase fails otherwise. An empty name writes one file per table again.
+// Auto-save rewrites the whole workbook when any of its tables changed.
+func (o DumpOptions) WithWorkbook(name string) DumpOptions {
+	o.Workbook = name
+	if name != "" {
+		o.Format = OutputFormatXLSX
+	}
+	return o
+}
+
+// workbookSheetName returns the sheet of the workbook that tableName is written to
+func (o DumpOptions) workbookSheetName(tableName string) string {
+	prefix := sanitizeTableName(o.Workbook) + "_"
+	if len(tableName) > len(prefix) && strings.EqualFold(tableName[:len(prefix)], prefix) {
+		return tableName[len(prefix):]
+	}
+	return tableName
+}
+
+// dumpSQLiteWorkbook exports tableNames as the sheets of one workbook without their
+// surrogate key columns. The workbook is written once every sheet is complete.
+func dumpSQLiteWorkbook(ctx context.Context, db *sql.DB, tableNames []string, outputDir, tempDir string, options DumpOptions, keys generatedKeys) error {
+	if options.Format != OutputFormatXLSX {
+		return newCodedError(ErrCodeInvalidConfig, "workbook output requires the xlsx format, not %s", options.Format)
+	}
+	outputPath := filepath.Join(outputDir, options.Workbook+options.FileExtension())
+	if err := checkOutputPath(options.OutputRoot, outputPath); err != nil {
+		return err
+	}
+
+	f := excelize.NewFile()
+	defer func() {
+		_ = f.Close() // Ignore close error
+	}()
+
+	var sheets []string
+	for i, tableName := range tableNames {
+		if err := context.Cause(ctx); err != nil {
+			return fmt.Errorf("dump interrupted after %d of %d sheets, workbook not written: %w", i, len(tableNames), err)
+		}
+		sheetName := options.workbookSheetName(tableName)
+		if slices.ContainsFunc(sheets, func(name string) bool { return strings.EqualFold(name, sheetName) }) {
+			return fmt.Errorf("failed to export table %s: sheet %s is already written", tableName, sheetName)
+		}
+		added, err := addWorkbookSheet(ctx, db, f, tableName, sheetName, len(sheets) == 0, options, keys)
+		if err != nil {
+			if ctxErr := context.Cause(ctx); ctxErr != nil {
+				return fmt.Errorf("dump interrupted after %d of %d sheets, workbook not written: %w", i, len(tableNames), ctxErr)
+			}
+			return fmt.Errorf("failed to export table %s: %w", tableName, err)
+		}
+		if added {
+			sheets = append(sheets, sheetName)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil
+	}
+
+	return options.writeTableFile(tempDir, outputPath, func(path string) error {
+		return writeFile(options.fileSystem(), path, options.Compression, func(writer io.Writer) error {
+			if err := f.Write(writer); err != nil {
+				return fmt.Errorf("failed to save Excel file: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+// addWorkbookSheet writes a table into the sheet sheetName of f, renaming the default
+// sheet of the new workbook for the first sheet. It reports false if the table is skipped.
+func addWorkbookSheet(ctx context.Context, db *sql.DB, f *excelize.File, tableName, sheetName string, first bool, options DumpOptions, keys generatedKeys) (bool, error) {
+	skip, err := options.skipsTable(ctx, db, tableName)
+	if err != nil || skip {
+		return false, err
+	}
+
+	columns, err := getSQLiteTableColumns(db, tableName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get columns for table %s: %w", tableName, err)
+	}
+	columns = keys.dataColumns(tableName, columns)
+	rows, err := db.QueryContext(ctx, selectColumnsQuery(tableName, columns))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if first {
+		err = f.SetSheetName(f.GetSheetName(0), sheetName)
+	} else {
+		_, err = f.NewSheet(sheetName)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create sheet %s: %w", sheetName, err)
+	}
+	if err := writeXLSXSheet(f, sheetName, columns, rows, newValueFormatter(columns, options)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,134 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestDumpOptions_WithWorkbook(t *testing.T) {
+	t.Parallel()
+
+	// writeWorkbook writes a workbook with the sheets Q1 and Q2 to path
+	writeWorkbook := func(t *testing.T, path string) {
+		t.Helper()
+
+		f := excelize.NewFile()
+		defer f.Close()
+		require.NoError(t, f.SetSheetName("Sheet1", "Q1"))
+		require.NoError(t, f.SetSheetRow("Q1", "A1", &[]any{"id", "amount"}))
+		require.NoError(t, f.SetSheetRow("Q1", "A2", &[]any{1, 100}))
+		_, err := f.NewSheet("Q2")
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow("Q2", "A1", &[]any{"id", "amount"}))
+		require.NoError(t, f.SetSheetRow("Q2", "A2", &[]any{2, 200}))
+		require.NoError(t, f.SaveAs(path))
+	}
+	tables := func(t *testing.T, db *sql.DB) []string {
+		t.Helper()
+
+		names, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		return names
+	}
+
+	t.Run("tables round-trip through one workbook", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "sales.xlsx")
+		writeWorkbook(t, path)
+		db := openTestDB(t, NewBuilder().
+			AddPath(path).
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV))
+		_, err := db.ExecContext(context.Background(), "UPDATE sales_Q2 SET amount = 250")
+		require.NoError(t, err)
+
+		outputDir := t.TempDir()
+		require.NoError(t, DumpDatabase(db, outputDir, NewDumpOptions().WithWorkbook("sales")))
+		entries, err := os.ReadDir(outputDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "sales.xlsx", entries[0].Name())
+
+		f, err := excelize.OpenFile(filepath.Join(outputDir, "sales.xlsx"))
+		require.NoError(t, err)
+		defer f.Close()
+		assert.Equal(t, []string{"Q1", "Q2", "users"}, f.GetSheetList())
+
+		reloaded := openTestDB(t, NewBuilder().AddPath(filepath.Join(outputDir, "sales.xlsx")))
+		assert.Equal(t, []string{"sales_Q1", "sales_Q2", "sales_users"}, tables(t, reloaded))
+		var amount int
+		require.NoError(t, reloaded.QueryRowContext(context.Background(), "SELECT amount FROM sales_Q2").Scan(&amount))
+		assert.Equal(t, 250, amount)
+	})
+
+	errorTests := []struct {
+		name     string
+		builder  *DBBuilder
+		options  DumpOptions
+		wantCode ErrorCode
+		wantMsg  string
+	}{
+		{
+			name: "tables writing the same sheet fail",
+			builder: NewBuilder().
+				AddReader(strings.NewReader("id\n1\n"), "Q1", FileTypeCSV).
+				AddReader(strings.NewReader("id\n2\n"), "sales_q1", FileTypeCSV),
+			options: NewDumpOptions().WithWorkbook("sales"),
+			wantMsg: "sheet q1 is already written",
+		},
+		{
+			name:     "workbooks need the xlsx format",
+			builder:  NewBuilder().AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV),
+			options:  NewDumpOptions().WithWorkbook("book").WithFormat(OutputFormatCSV),
+			wantCode: ErrCodeInvalidConfig,
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := DumpDatabase(openTestDB(t, tt.builder), t.TempDir(), tt.options)
+			require.Error(t, err)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, ErrorCodeOf(err))
+			}
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+
+	t.Run("auto-save rewrites the whole workbook", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := newMemoryFileSystem()
+		autoSave := NewDumpOptions().WithWorkbook("book")
+		db := openTestDB(t, NewBuilder().
+			AddReader(strings.NewReader("id\n1\n"), "users", FileTypeCSV).
+			AddReader(strings.NewReader("id\n1\n"), "orders", FileTypeCSV).
+			EnableAutoSaveOnCommit("backup", autoSave).
+			WithFileSystem(fsys))
+
+		for range 2 { // The second save changes only orders, but the workbook holds users, too
+			tx, err := db.BeginTx(context.Background(), nil)
+			require.NoError(t, err)
+			_, err = tx.ExecContext(context.Background(), "INSERT INTO orders VALUES (2)")
+			require.NoError(t, err)
+			require.NoError(t, tx.Commit())
+
+			content, ok := fsys.content(filepath.Join("backup", "book.xlsx"))
+			require.True(t, ok)
+			f, err := excelize.OpenReader(strings.NewReader(content))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"orders", "users"}, f.GetSheetList())
+			require.NoError(t, f.Close())
+		}
+	})
+}
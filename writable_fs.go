@@ -0,0 +1,194 @@
+package filesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// WritableFS is an fs.FS that auto-save can write files back into.
+//
+// When a filesystem added with AddFS implements WritableFS and auto-save overwrites
+// the original files (EnableAutoSave or EnableAutoSaveOnCommit with an empty output
+// directory), each table loaded from a CSV, TSV, LTSV, or Parquet file of the
+// filesystem is written back to the file it was read from, in the same format and
+// compression. This lets custom storage layers, such as a database-backed or remote
+// filesystem, round-trip their data without a temporary directory. Excel workbooks are
+// not written back.
+//
+// Use NewWritableFS to combine a read-only fs.FS with a function that writes files.
+type WritableFS interface {
+	fs.FS
+	// Create creates or truncates the named file for writing. The name is an fs.FS
+	// name: a slash-separated path without a leading slash, such as "data/users.csv".
+	// Errors returned by Write or Close of the file fail the save.
+	Create(name string) (io.WriteCloser, error)
+}
+
+// NewWritableFS returns a WritableFS that reads files from fsys and creates files with
+// create.
+//
+// Example:
+//
+//	root := "/srv/data"
+//	writable := filesql.NewWritableFS(os.DirFS(root), func(name string) (io.WriteCloser, error) {
+//		return os.Create(filepath.Join(root, filepath.FromSlash(name)))
+//	})
+//	builder := filesql.NewBuilder().
+//		AddFS(writable).
+//		EnableAutoSave("") // Write changed tables back into writable
+func NewWritableFS(fsys fs.FS, create func(name string) (io.WriteCloser, error)) WritableFS {
+	return writableFS{FS: fsys, create: create}
+}
+
+// writableFS implements WritableFS for NewWritableFS
+type writableFS struct {
+	fs.FS
+	create func(name string) (io.WriteCloser, error)
+}
+
+// Create implements WritableFS
+func (w writableFS) Create(name string) (io.WriteCloser, error) {
+	return w.create(name)
+}
+
+// writeBackTarget is a table that auto-save writes back into the filesystem it was loaded from
+type writeBackTarget struct {
+	// fsys is the filesystem the table was loaded from
+	fsys WritableFS
+	// name is the name of the file in fsys
+	name string
+	// table is the name of the table
+	table string
+}
+
+// collectWriteBackTargets returns the tables loaded from writable filesystems
+func (b *DBBuilder) collectWriteBackTargets() []writeBackTarget {
+	var targets []writeBackTarget
+	for _, reader := range b.readers {
+		if reader.writeBack == nil || !canWriteBack(reader.fileType) {
+			continue
+		}
+		targets = append(targets, writeBackTarget{fsys: reader.writeBack, name: reader.source, table: reader.tableName})
+	}
+	return targets
+}
+
+// canWriteBack reports whether a file of fileType holds a single table that can be written back
+func canWriteBack(fileType FileType) bool {
+	switch fileType.baseType() {
+	case FileTypeCSV, FileTypeTSV, FileTypeSSV, FileTypePSV, FileTypeLTSV, FileTypeParquet, FileTypeJSONL:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeBack writes the table back to its file, keeping the format and compression of
+// the file and the other dump options. Tables that were dropped are skipped.
+func (t writeBackTarget) writeBack(ctx context.Context, db *sql.DB, options DumpOptions) error {
+	skip, err := options.skipsTable(ctx, db, t.table)
+	if err != nil {
+		return err
+	}
+	if skip || !options.selectsTable(t.table) || options.excludesTable(t.table) {
+		return nil
+	}
+
+	columns, err := getSQLiteTableColumns(db, t.table)
+	if err != nil {
+		return fmt.Errorf("failed to get columns for table %s: %w", t.table, err)
+	}
+	if len(columns) == 0 {
+		return nil // The table was dropped
+	}
+	keys, err := readGeneratedKeys(ctx, db)
+	if err != nil {
+		return err
+	}
+	columns = keys.dataColumns(t.table, columns)
+	rows, err := db.QueryContext(ctx, selectColumnsQuery(t.table, columns))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	fileType := newFile(t.name).getFileType()
+	switch fileType.baseType() {
+	case FileTypeTSV:
+		options.Format = OutputFormatTSV
+	case FileTypeSSV:
+		options.Format = OutputFormatSSV
+	case FileTypePSV:
+		options.Format = OutputFormatPSV
+	case FileTypeLTSV:
+		options.Format = OutputFormatLTSV
+	case FileTypeJSONL:
+		options.Format = OutputFormatJSONL
+	case FileTypeParquet:
+		options.Format = OutputFormatParquet
+	default:
+		options.Format = OutputFormatCSV
+	}
+	options.Compression = NewCompressionFactory().DetectCompressionType(t.name)
+	options.FileSystem = writeBackFileSystem{fsys: t.fsys}
+	options, err = options.withSchemaCommentLines(ctx, db, t.table, columns)
+	if err != nil {
+		return err
+	}
+	if err := writeSQLiteTableData(t.name, columns, rows, options, nil); err != nil {
+		return fmt.Errorf("failed to write back table %s to %s: %w", t.table, t.name, err)
+	}
+	return nil
+}
+
+// excludeWriteBackTables returns options that also exclude the written back tables,
+// so they are not written to the directory of the original paths as well
+func excludeWriteBackTables(options DumpOptions, targets []writeBackTarget) DumpOptions {
+	patterns := make([]string, 0, len(targets))
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	for _, target := range targets {
+		patterns = append(patterns, escaper.Replace(target.table))
+	}
+	return options.WithExcludedTables(patterns...)
+}
+
+// writeBackFileSystem is the FileSystem that table files are written back through.
+// Writing a table file only creates the file.
+type writeBackFileSystem struct {
+	fsys WritableFS
+}
+
+// Open implements FileSystem
+func (w writeBackFileSystem) Open(name string) (fs.File, error) {
+	return w.fsys.Open(name)
+}
+
+// Create implements FileSystem
+func (w writeBackFileSystem) Create(name string) (io.WriteCloser, error) {
+	return w.fsys.Create(name)
+}
+
+// CreateNew implements FileSystem
+func (w writeBackFileSystem) CreateNew(name string) (io.WriteCloser, error) {
+	return nil, &fs.PathError{Op: "create", Path: name, Err: errors.ErrUnsupported}
+}
+
+// MkdirAll implements FileSystem
+func (w writeBackFileSystem) MkdirAll(path string, _ fs.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: path, Err: errors.ErrUnsupported}
+}
+
+// Rename implements FileSystem
+func (w writeBackFileSystem) Rename(oldpath, _ string) error {
+	return &fs.PathError{Op: "rename", Path: oldpath, Err: errors.ErrUnsupported}
+}
+
+// Remove implements FileSystem
+func (w writeBackFileSystem) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: errors.ErrUnsupported}
+}
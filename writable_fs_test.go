@@ -0,0 +1,154 @@
+package filesql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWritableFS is a WritableFS that keeps files in a fstest.MapFS
+type memoryWritableFS struct {
+	mu    sync.Mutex
+	files fstest.MapFS
+}
+
+// file returns the content of the named file
+func (m *memoryWritableFS) file(name string) []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files[name].Data
+}
+
+// writable returns m as a WritableFS created with NewWritableFS
+func (m *memoryWritableFS) writable() WritableFS {
+	return NewWritableFS(m.files, func(name string) (io.WriteCloser, error) {
+		return &memoryFile{fsys: m, name: name}, nil
+	})
+}
+
+// memoryFile stores its content in the filesystem when closed
+type memoryFile struct {
+	bytes.Buffer
+	fsys *memoryWritableFS
+	name string
+}
+
+func (f *memoryFile) Close() error {
+	f.fsys.mu.Lock()
+	defer f.fsys.mu.Unlock()
+	f.fsys.files[f.name] = &fstest.MapFile{Data: f.Bytes()}
+	return nil
+}
+
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestWritableFS(t *testing.T) {
+	t.Parallel()
+
+	newFS := func(t *testing.T) *memoryWritableFS {
+		t.Helper()
+		return &memoryWritableFS{files: fstest.MapFS{
+			"data/users.csv": {Data: []byte("id,name\n1,Gina\n")},
+			"scores.tsv.gz":  {Data: gzipData(t, "id\tscore\n1\t10\n")},
+		}}
+	}
+	openDB := func(t *testing.T, builder *DBBuilder) *DBBuilder {
+		t.Helper()
+		validatedBuilder, err := builder.Build(context.Background())
+		require.NoError(t, err)
+		return validatedBuilder
+	}
+
+	t.Run("auto-save writes tables back in their format and compression", func(t *testing.T) {
+		t.Parallel()
+
+		memory := newFS(t)
+		db, err := openDB(t, NewBuilder().AddFS(memory.writable()).EnableAutoSave("")).Open(context.Background())
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(context.Background(), "INSERT INTO users VALUES (2, 'Hiro')")
+		require.NoError(t, err)
+		_, err = db.ExecContext(context.Background(), "UPDATE scores SET score = 20")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		assert.Equal(t, "id,name\n1,Gina\n2,Hiro\n", string(memory.file("data/users.csv")))
+
+		reader, err := gzip.NewReader(bytes.NewReader(memory.file("scores.tsv.gz")))
+		require.NoError(t, err)
+		scores, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "id\tscore\n1\t20\n", string(scores))
+	})
+
+	t.Run("written back tables are not saved next to the original paths", func(t *testing.T) {
+		t.Parallel()
+
+		memory := newFS(t)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv"), []byte("id\n1\n"), 0600))
+
+		db, err := openDB(t, NewBuilder().
+			AddPath(filepath.Join(dir, "orders.csv")).
+			AddFS(memory.writable()).
+			EnableAutoSaveOnCommit("")).Open(context.Background())
+		require.NoError(t, err)
+
+		tx, err := db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		_, err = tx.ExecContext(context.Background(), "DELETE FROM users")
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		require.NoError(t, db.Close())
+
+		assert.Equal(t, "id,name\n", string(memory.file("data/users.csv")))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "orders.csv", entries[0].Name())
+	})
+
+	t.Run("dropped and excluded tables are not written back", func(t *testing.T) {
+		t.Parallel()
+
+		memory := newFS(t)
+		original := memory.file("scores.tsv.gz")
+		db, err := openDB(t, NewBuilder().
+			AddFS(memory.writable()).
+			EnableAutoSave("", NewDumpOptions().WithExcludedTables("scores"))).Open(context.Background())
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(context.Background(), "DROP TABLE users")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		assert.Equal(t, "id,name\n1,Gina\n", string(memory.file("data/users.csv")))
+		assert.Equal(t, original, memory.file("scores.tsv.gz"))
+	})
+
+	t.Run("read-only filesystems cannot be overwritten", func(t *testing.T) {
+		t.Parallel()
+
+		memory := newFS(t)
+		db, err := openDB(t, NewBuilder().AddFS(memory.files).EnableAutoSave("")).Open(context.Background())
+		require.NoError(t, err)
+		require.Error(t, db.Close())
+	})
+}
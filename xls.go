@@ -0,0 +1,601 @@
+package filesql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// BIFF8 record types used by the legacy Excel (.xls) reader
+const (
+	xlsRecordFormula    = 0x0006
+	xlsRecordEOF        = 0x000A
+	xlsRecordFilePass   = 0x002F
+	xlsRecordContinue   = 0x003C
+	xlsRecordBoundSheet = 0x0085
+	xlsRecordMulRK      = 0x00BD
+	xlsRecordSST        = 0x00FC
+	xlsRecordLabelSST   = 0x00FD
+	xlsRecordNumber     = 0x0203
+	xlsRecordLabel      = 0x0204
+	xlsRecordBoolErr    = 0x0205
+	xlsRecordString     = 0x0207
+	xlsRecordRK         = 0x027E
+	xlsRecordBOF        = 0x0809
+)
+
+// xlsBIFF8Version is the BOF version of Excel 97-2003 workbooks
+const xlsBIFF8Version = 0x0600
+
+// xlsSheet is a worksheet read from a legacy Excel workbook
+type xlsSheet struct {
+	name string
+	rows [][]string
+}
+
+// xlsRecord is a BIFF record; data includes the data of following CONTINUE records,
+// whose start offsets within data are listed in continues
+type xlsRecord struct {
+	id        uint16
+	data      []byte
+	continues []int
+}
+
+// readXLSSheets reads the worksheets of a legacy Excel (BIFF8, Excel 97-2003) workbook.
+//
+// Only cell values are read: numbers are formatted without number formats (dates
+// appear as Excel serial numbers), formulas yield their cached results, and
+// formatting, charts, and macros are ignored. Rows are returned like
+// excelize.GetRows, without trailing empty cells.
+func readXLSSheets(reader io.Reader) ([]xlsSheet, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLS data: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, newCodedError(ErrCodeEmptyData, "empty XLS file")
+	}
+
+	stream, err := xlsWorkbookStream(data)
+	if err != nil {
+		return nil, err
+	}
+
+	globals, err := readXLSRecords(stream, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(globals) == 0 || globals[0].id != xlsRecordBOF || len(globals[0].data) < 2 ||
+		binary.LittleEndian.Uint16(globals[0].data) != xlsBIFF8Version {
+		return nil, newCodedError(ErrCodeUnsupportedFormat, "only Excel 97-2003 (BIFF8) .xls files are supported")
+	}
+
+	type sheetEntry struct {
+		name   string
+		offset uint32
+	}
+	var (
+		entries       []sheetEntry
+		sharedStrings []string
+	)
+	for _, record := range globals {
+		switch record.id {
+		case xlsRecordFilePass:
+			return nil, newCodedError(ErrCodeUnsupportedFormat, "encrypted .xls files are not supported")
+		case xlsRecordBoundSheet:
+			if len(record.data) < 8 {
+				return nil, newCodedError(ErrCodeInvalidData, "malformed XLS sheet record")
+			}
+			// Only worksheets (type 0) contain cells; skip charts and macro sheets
+			if record.data[5] != 0 {
+				continue
+			}
+			name, _, err := decodeXLSString(record.data[6:], 1)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, sheetEntry{name: name, offset: binary.LittleEndian.Uint32(record.data)})
+		case xlsRecordSST:
+			if sharedStrings, err = decodeXLSSharedStrings(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sheets := make([]xlsSheet, 0, len(entries))
+	for _, entry := range entries {
+		records, err := readXLSRecords(stream, int(entry.offset))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sheet %s: %w", entry.name, err)
+		}
+		rows, err := xlsSheetRows(records, sharedStrings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sheet %s: %w", entry.name, err)
+		}
+		sheets = append(sheets, xlsSheet{name: entry.name, rows: rows})
+	}
+	return sheets, nil
+}
+
+// xlsHeaderSize is the size of the header of a compound document
+const xlsHeaderSize = 512
+
+// checkXLSHeader checks that the sectors the compound document header declares fit in
+// data. mscfb sizes its tables from these counts before reading the sectors, so a
+// malformed header would otherwise make it allocate gigabytes.
+func checkXLSHeader(data []byte) error {
+	if len(data) < xlsHeaderSize {
+		return newCodedError(ErrCodeInvalidData, "not a valid .xls file: truncated header")
+	}
+	shift := binary.LittleEndian.Uint16(data[30:32])
+	if shift != 9 && shift != 12 {
+		return newCodedError(ErrCodeInvalidData, "not a valid .xls file: invalid sector size")
+	}
+	sectors := uint64(len(data)) >> shift
+	counts := []struct {
+		name  string
+		count uint32
+	}{
+		{name: "directory", count: binary.LittleEndian.Uint32(data[40:44])},
+		{name: "FAT", count: binary.LittleEndian.Uint32(data[44:48])},
+		{name: "mini FAT", count: binary.LittleEndian.Uint32(data[64:68])},
+		{name: "DIFAT", count: binary.LittleEndian.Uint32(data[72:76])},
+	}
+	for _, c := range counts {
+		if uint64(c.count) > sectors {
+			return newCodedError(ErrCodeInvalidData, "not a valid .xls file: %d %s sectors do not fit in %d bytes", c.count, c.name, len(data))
+		}
+	}
+	return nil
+}
+
+// xlsWorkbookStream extracts the BIFF workbook stream from the compound document
+func xlsWorkbookStream(data []byte) ([]byte, error) {
+	if err := checkXLSHeader(data); err != nil {
+		return nil, err
+	}
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return nil, newCodedError(ErrCodeInvalidData, "not a valid .xls file: %w", err)
+	}
+
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		switch entry.Name {
+		case "Workbook":
+			if entry.Size < 0 || entry.Size > int64(len(data)) {
+				return nil, newCodedError(ErrCodeInvalidData, "not a valid .xls file: workbook stream of %d bytes does not fit in %d bytes", entry.Size, len(data))
+			}
+			stream := make([]byte, entry.Size)
+			if _, err := io.ReadFull(entry, stream); err != nil {
+				return nil, fmt.Errorf("failed to read XLS workbook stream: %w", err)
+			}
+			return stream, nil
+		case "Book":
+			return nil, newCodedError(ErrCodeUnsupportedFormat, "only Excel 97-2003 (BIFF8) .xls files are supported")
+		}
+	}
+	return nil, newCodedError(ErrCodeInvalidData, "no workbook found in .xls file")
+}
+
+// readXLSRecords reads BIFF records from offset up to and including the next EOF record.
+// CONTINUE records are merged into the record they continue.
+func readXLSRecords(stream []byte, offset int) ([]xlsRecord, error) {
+	var records []xlsRecord
+	for offset+4 <= len(stream) {
+		id := binary.LittleEndian.Uint16(stream[offset:])
+		length := int(binary.LittleEndian.Uint16(stream[offset+2:]))
+		offset += 4
+		if offset+length > len(stream) {
+			return nil, newCodedError(ErrCodeInvalidData, "truncated XLS record")
+		}
+		data := stream[offset : offset+length]
+		offset += length
+
+		if id == xlsRecordContinue && len(records) > 0 {
+			last := &records[len(records)-1]
+			last.continues = append(last.continues, len(last.data))
+			last.data = append(last.data, data...)
+			continue
+		}
+		records = append(records, xlsRecord{id: id, data: append([]byte(nil), data...)})
+		if id == xlsRecordEOF {
+			return records, nil
+		}
+	}
+	return nil, newCodedError(ErrCodeInvalidData, "missing XLS end of file record")
+}
+
+// xlsStringReader decodes Unicode strings from record data whose character arrays
+// may be split across CONTINUE records
+type xlsStringReader struct {
+	data      []byte
+	continues []int
+	pos       int
+}
+
+// next returns the next n bytes
+func (r *xlsStringReader) next(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, errXLSTruncated
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// nextBoundary returns the start of the next CONTINUE record at or after the current position
+func (r *xlsStringReader) nextBoundary() int {
+	for _, boundary := range r.continues {
+		if boundary >= r.pos {
+			return boundary
+		}
+	}
+	return len(r.data)
+}
+
+// readString reads an XLUnicodeRichExtendedString (or an XLUnicodeString when the
+// rich and extended flags are not set) whose character count has lengthSize bytes
+func (r *xlsStringReader) readString(lengthSize int) (string, error) {
+	lengthBytes, err := r.next(lengthSize)
+	if err != nil {
+		return "", err
+	}
+	count := int(lengthBytes[0])
+	if lengthSize == 2 {
+		count = int(binary.LittleEndian.Uint16(lengthBytes))
+	}
+	flags, err := r.next(1)
+	if err != nil {
+		return "", err
+	}
+	highByte := flags[0]&0x01 != 0
+
+	var runs, extSize int
+	if flags[0]&0x08 != 0 {
+		b, err := r.next(2)
+		if err != nil {
+			return "", err
+		}
+		runs = int(binary.LittleEndian.Uint16(b))
+	}
+	if flags[0]&0x04 != 0 {
+		b, err := r.next(4)
+		if err != nil {
+			return "", err
+		}
+		extSize = int(binary.LittleEndian.Uint32(b))
+	}
+
+	units := make([]uint16, 0, count)
+	for len(units) < count {
+		charSize := 1
+		if highByte {
+			charSize = 2
+		}
+		available := (r.nextBoundary() - r.pos) / charSize
+		if available == 0 {
+			// The character array continues in the next record, which starts with a new flags byte
+			if r.pos == len(r.data) || r.pos != r.nextBoundary() {
+				return "", errXLSTruncated
+			}
+			flags, err := r.next(1)
+			if err != nil {
+				return "", err
+			}
+			highByte = flags[0]&0x01 != 0
+			continue
+		}
+		n := min(count-len(units), available)
+		chars, err := r.next(n * charSize)
+		if err != nil {
+			return "", err
+		}
+		for i := range n {
+			if highByte {
+				units = append(units, binary.LittleEndian.Uint16(chars[i*2:]))
+			} else {
+				units = append(units, uint16(chars[i]))
+			}
+		}
+	}
+
+	// Skip formatting runs and phonetic data
+	if _, err := r.next(runs*4 + extSize); err != nil {
+		return "", err
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// decodeXLSString decodes a string at the start of data
+func decodeXLSString(data []byte, lengthSize int) (string, int, error) {
+	r := &xlsStringReader{data: data}
+	s, err := r.readString(lengthSize)
+	if err != nil {
+		return "", 0, newCodedError(ErrCodeInvalidData, "malformed XLS string: %w", err)
+	}
+	return s, r.pos, nil
+}
+
+// decodeXLSSharedStrings decodes the shared string table
+func decodeXLSSharedStrings(record xlsRecord) ([]string, error) {
+	if len(record.data) < 8 {
+		return nil, newCodedError(ErrCodeInvalidData, "malformed XLS shared string table")
+	}
+	unique := int(binary.LittleEndian.Uint32(record.data[4:]))
+	r := &xlsStringReader{data: record.data, continues: record.continues, pos: 8}
+
+	sharedStrings := make([]string, 0, min(unique, len(record.data)))
+	for range unique {
+		s, err := r.readString(2)
+		if err != nil {
+			return nil, newCodedError(ErrCodeInvalidData, "malformed XLS shared string table: %w", err)
+		}
+		sharedStrings = append(sharedStrings, s)
+	}
+	return sharedStrings, nil
+}
+
+// xlsSheetRows converts the cell records of a worksheet into rows
+func xlsSheetRows(records []xlsRecord, sharedStrings []string) ([][]string, error) {
+	var rows [][]string
+	setCell := func(row, col int, value string) {
+		if value == "" {
+			return
+		}
+		for len(rows) <= row {
+			rows = append(rows, nil)
+		}
+		for len(rows[row]) <= col {
+			rows[row] = append(rows[row], "")
+		}
+		rows[row][col] = value
+	}
+	// setCellOf sets the cell addressed by the row and column at the start of a cell record
+	setCellOf := func(data []byte, value string) {
+		row, col := xlsCellPosition(data)
+		setCell(row, col, value)
+	}
+
+	// pendingFormula is the cell whose string result follows in a STRING record
+	pendingRow, pendingCol := -1, -1
+	for _, record := range records {
+		data := record.data
+		// Cell records start with the row, the column, and the XF index
+		if record.id != xlsRecordString && len(data) < 6 {
+			continue
+		}
+
+		switch record.id {
+		case xlsRecordLabelSST:
+			if len(data) < 10 {
+				return nil, newCodedError(ErrCodeInvalidData, "malformed XLS cell record")
+			}
+			index := int(binary.LittleEndian.Uint32(data[6:]))
+			if index >= len(sharedStrings) {
+				return nil, newCodedError(ErrCodeInvalidData, "XLS shared string index %d out of range", index)
+			}
+			setCellOf(data, sharedStrings[index])
+		case xlsRecordLabel:
+			s, _, err := decodeXLSString(data[6:], 2)
+			if err != nil {
+				return nil, err
+			}
+			setCellOf(data, s)
+		case xlsRecordNumber:
+			if len(data) < 14 {
+				return nil, newCodedError(ErrCodeInvalidData, "malformed XLS cell record")
+			}
+			setCellOf(data, formatXLSNumber(math.Float64frombits(binary.LittleEndian.Uint64(data[6:]))))
+		case xlsRecordRK:
+			if len(data) < 10 {
+				return nil, newCodedError(ErrCodeInvalidData, "malformed XLS cell record")
+			}
+			setCellOf(data, formatXLSNumber(decodeXLSRK(binary.LittleEndian.Uint32(data[6:]))))
+		case xlsRecordMulRK:
+			row := int(binary.LittleEndian.Uint16(data))
+			col := int(binary.LittleEndian.Uint16(data[2:]))
+			// Each value is an XF index (2 bytes) and an RK number (4 bytes); the last column index ends the record
+			for offset := 4; offset+6 <= len(data)-2; offset += 6 {
+				setCell(row, col, formatXLSNumber(decodeXLSRK(binary.LittleEndian.Uint32(data[offset+2:]))))
+				col++
+			}
+		case xlsRecordBoolErr:
+			if len(data) < 8 {
+				return nil, newCodedError(ErrCodeInvalidData, "malformed XLS cell record")
+			}
+			setCellOf(data, formatXLSBoolErr(data[6], data[7] != 0))
+		case xlsRecordFormula:
+			if len(data) < 14 {
+				return nil, newCodedError(ErrCodeInvalidData, "malformed XLS cell record")
+			}
+			row, col := xlsCellPosition(data)
+			result := data[6:14]
+			if binary.LittleEndian.Uint16(result[6:]) != 0xFFFF {
+				setCell(row, col, formatXLSNumber(math.Float64frombits(binary.LittleEndian.Uint64(result))))
+				continue
+			}
+			switch result[0] {
+			case 0: // String result in the following STRING record
+				pendingRow, pendingCol = row, col
+			case 1:
+				setCell(row, col, formatXLSBoolErr(result[2], false))
+			case 2:
+				setCell(row, col, formatXLSBoolErr(result[2], true))
+			}
+		case xlsRecordString:
+			if pendingRow < 0 {
+				continue
+			}
+			s, _, err := decodeXLSString(data, 2)
+			if err != nil {
+				return nil, err
+			}
+			setCell(pendingRow, pendingCol, s)
+			pendingRow, pendingCol = -1, -1
+		}
+	}
+	return rows, nil
+}
+
+// xlsCellPosition returns the zero-based row and column of a cell record
+func xlsCellPosition(data []byte) (int, int) {
+	return int(binary.LittleEndian.Uint16(data)), int(binary.LittleEndian.Uint16(data[2:]))
+}
+
+// decodeXLSRK decodes an RK number, a compressed integer or float
+func decodeXLSRK(rk uint32) float64 {
+	var value float64
+	if rk&0x02 != 0 {
+		value = float64(int32(rk) >> 2) //nolint:gosec // RK integers are signed 30-bit values
+	} else {
+		value = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		value /= 100
+	}
+	return value
+}
+
+// formatXLSNumber formats a number without exponent or trailing zeros
+func formatXLSNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatXLSBoolErr formats a boolean or an error value as Excel displays it
+func formatXLSBoolErr(value byte, isError bool) string {
+	if !isError {
+		if value != 0 {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	switch value {
+	case 0x00:
+		return "#NULL!"
+	case 0x07:
+		return "#DIV/0!"
+	case 0x0F:
+		return "#VALUE!"
+	case 0x17:
+		return "#REF!"
+	case 0x1D:
+		return "#NAME?"
+	case 0x24:
+		return "#NUM!"
+	case 0x2A:
+		return "#N/A"
+	default:
+		return "#" + strings.ToUpper(strconv.FormatUint(uint64(value), 16))
+	}
+}
+
+// firstSheetTable converts the first worksheet of a workbook in format (XLS or ODS)
+// into headers and records. Leading empty rows are skipped and the first remaining
+// row is the header. Callers check the header for duplicate column names.
+func firstSheetTable(sheets []xlsSheet, format string) (header, []Record, error) {
+	if len(sheets) == 0 {
+		return nil, nil, fmt.Errorf("no sheets found in %s file", format)
+	}
+
+	// Process only the first sheet, like XLSX readers
+	sheet := sheets[0]
+	rows := sheet.rows
+	for len(rows) > 0 && len(rows[0]) == 0 {
+		rows = rows[1:]
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("sheet %s is empty in %s file", sheet.name, format)
+	}
+	headers, records := convertXLSXRowsToTable(rows)
+	return headers, records, nil
+}
+
+// parseXLS parses a legacy Excel file with compression support.
+// Only the first sheet is parsed; use filesql.Open() for the 1-sheet-1-table approach.
+func (f *file) parseXLS() (*table, error) {
+	reader, closer, err := f.openReader()
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	sheets, err := readXLSSheets(reader)
+	if err != nil {
+		return nil, err
+	}
+	headers, records, err := firstSheetTable(sheets, "XLS")
+	if err == nil {
+		err = validateColumnNames(headers)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, f.path)
+	}
+	return newTable(tableFromFilePath(f.path), headers, records), nil
+}
+
+// parseXLSStream parses legacy Excel data from reader.
+// Only the first sheet is processed (streaming parser limitation).
+func (p *streamingParser) parseXLSStream(reader io.Reader) (*table, error) {
+	sheets, err := readXLSSheets(reader)
+	if err != nil {
+		return nil, err
+	}
+	headers, records, err := firstSheetTable(sheets, "XLS")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validateHeader(headers); err != nil {
+		return nil, err
+	}
+	return newTable(p.tableName, headers, records), nil
+}
+
+// processXLSInChunks processes the first sheet of legacy Excel data in chunks.
+// The workbook is read into memory as a whole because BIFF requires random access.
+func (p *streamingParser) processXLSInChunks(reader io.Reader, processor chunkProcessor) error {
+	if p.memoryLimit != nil && p.memoryLimit.CheckMemoryUsage() == MemoryStatusExceeded {
+		return p.memoryLimit.CreateMemoryError("XLS chunk processing")
+	}
+
+	sheets, err := readXLSSheets(reader)
+	if err != nil {
+		return err
+	}
+	headers, records, err := firstSheetTable(sheets, "XLS")
+	if err != nil {
+		return err
+	}
+	if err := p.validateHeader(headers); err != nil {
+		return err
+	}
+	return p.processSheetInChunks(headers, records, processor)
+}
+
+// processSheetInChunks passes the records of a sheet read as a whole to processor in chunks
+func (p *streamingParser) processSheetInChunks(headers header, records []Record, processor chunkProcessor) error {
+	chunkSize := p.chunkSize.Int()
+	if chunkSize <= 0 {
+		chunkSize = DefaultRowsPerChunk
+	}
+	columnInfo := inferColumnsInfo(headers, records)
+	for start := 0; start < len(records); start += chunkSize {
+		end := min(start+chunkSize, len(records))
+		chunk := &tableChunk{
+			tableName:  p.tableName,
+			headers:    headers,
+			records:    records[start:end],
+			columnInfo: columnInfo,
+		}
+		if err := processor(chunk); err != nil {
+			return fmt.Errorf("chunk processor error: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package filesql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadXLSSheets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reads values of every worksheet", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := os.Open(filepath.Join("testdata", "excel", "legacy.xls"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		sheets, err := readXLSSheets(f)
+		require.NoError(t, err)
+
+		// The chart sheet is skipped
+		require.Len(t, sheets, 3)
+		assert.Equal(t, "products", sheets[0].name)
+		assert.Equal(t, [][]string{
+			{"id", "price", "qty", "name", "in_stock", "note"},
+			{"1", "1.25", "10", "Apple", "TRUE", "fresh"},
+			{"2", "3.5", "-5", "日本のりんご", "FALSE", "42.5"},
+			{"3", "0.001", "#DIV/0!", "Label cell", "", "TRUE"},
+		}, sheets[0].rows)
+		assert.Equal(t, "empty", sheets[1].name)
+		assert.Empty(t, sheets[1].rows)
+		assert.Equal(t, "summary", sheets[2].name)
+		assert.Equal(t, [][]string{{"total", "note"}, {"42", `it's "quoted"`}}, sheets[2].rows)
+	})
+
+	t.Run("rejects data that is not an xls file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := readXLSSheets(bytes.NewReader([]byte("id,name\n1,alice\n")))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidData, ErrorCodeOf(err))
+
+		_, err = readXLSSheets(bytes.NewReader(nil))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeEmptyData, ErrorCodeOf(err))
+	})
+
+	t.Run("rejects headers that declare more sectors than the file holds", func(t *testing.T) {
+		t.Parallel()
+
+		legacy, err := os.ReadFile(filepath.Join("testdata", "excel", "legacy.xls"))
+		require.NoError(t, err)
+
+		tests := []struct {
+			name   string
+			offset int
+			value  uint32
+		}{
+			// Passes the DIFAT checks of mscfb, which then allocates 4 GiB for the DIFAT
+			{name: "FAT and DIFAT sectors", offset: 44, value: 0x3FFFFFFF},
+			{name: "directory sectors", offset: 40, value: 0xFFFFFFFF},
+			{name: "mini FAT sectors", offset: 64, value: 0x10000000},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				data := bytes.Clone(legacy)
+				binary.LittleEndian.PutUint32(data[tt.offset:], tt.value)
+				if tt.offset == 44 {
+					binary.LittleEndian.PutUint32(data[72:], tt.value/127)
+				}
+
+				_, err := readXLSSheets(bytes.NewReader(data))
+				require.Error(t, err)
+				assert.Equal(t, ErrCodeInvalidData, ErrorCodeOf(err))
+				assert.Contains(t, err.Error(), "do not fit")
+			})
+		}
+	})
+
+	t.Run("rejects headers with an invalid sector size", func(t *testing.T) {
+		t.Parallel()
+
+		legacy, err := os.ReadFile(filepath.Join("testdata", "excel", "legacy.xls"))
+		require.NoError(t, err)
+		data := bytes.Clone(legacy)
+		binary.LittleEndian.PutUint16(data[30:], 30)
+
+		_, err = readXLSSheets(bytes.NewReader(data))
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeInvalidData, ErrorCodeOf(err))
+	})
+}
+
+func TestDecodeXLSRK(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rk   uint32
+		want float64
+	}{
+		{name: "integer", rk: 42<<2 | 0x2, want: 42},
+		{name: "negative integer", rk: 0xFFFFFFEE, want: -5},
+		{name: "integer divided by 100", rk: 350<<2 | 0x3, want: 3.5},
+		{name: "float", rk: 0x3FF40000, want: 1.25},
+		{name: "float divided by 100", rk: 0x40590000 | 0x1, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.InDelta(t, tt.want, decodeXLSRK(tt.rk), 1e-12)
+		})
+	}
+}
+
+func TestXLSStringReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("character array continued with a different width", func(t *testing.T) {
+		t.Parallel()
+
+		// "abcd" with 2 compressed characters in the first record and 2 UTF-16 characters in the next
+		data := []byte{4, 0, 0, 'a', 'b', 1, 'c', 0, 'd', 0}
+		r := &xlsStringReader{data: data, continues: []int{5}}
+		s, err := r.readString(2)
+		require.NoError(t, err)
+		assert.Equal(t, "abcd", s)
+		assert.Equal(t, len(data), r.pos)
+	})
+
+	t.Run("rich text and phonetic data are skipped", func(t *testing.T) {
+		t.Parallel()
+
+		data := []byte{2, 0, 0x0C}
+		data = binary.LittleEndian.AppendUint16(data, 1)
+		data = binary.LittleEndian.AppendUint32(data, 3)
+		data = append(data, 'o', 'k')
+		data = append(data, 0, 0, 0, 0) // One formatting run
+		data = append(data, 9, 9, 9)    // Phonetic data
+		data = append(data, 1, 0, 0, 'x')
+
+		r := &xlsStringReader{data: data}
+		s, err := r.readString(2)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", s)
+		s, err = r.readString(2)
+		require.NoError(t, err)
+		assert.Equal(t, "x", s)
+	})
+
+	t.Run("truncated string", func(t *testing.T) {
+		t.Parallel()
+
+		r := &xlsStringReader{data: []byte{5, 0, 0, 'a'}}
+		_, err := r.readString(2)
+		require.ErrorIs(t, err, errXLSTruncated)
+	})
+}
+
+func TestOpenXLS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("each worksheet becomes a table", func(t *testing.T) {
+		t.Parallel()
+
+		db, err := Open(filepath.Join("testdata", "excel", "legacy.xls"))
+		require.NoError(t, err)
+		defer db.Close()
+
+		tables, err := getSQLiteTableNames(db)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"legacy_products", "legacy_summary"}, tables)
+
+		var (
+			name  string
+			price float64
+		)
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT name, price FROM legacy_products WHERE id = 2").Scan(&name, &price))
+		assert.Equal(t, "日本のりんご", name)
+		assert.InDelta(t, 3.5, price, 0)
+
+		var priceType string
+		require.NoError(t, db.QueryRowContext(context.Background(),
+			"SELECT type FROM pragma_table_info('legacy_products') WHERE name = 'price'").Scan(&priceType))
+		assert.Equal(t, "REAL", priceType)
+	})
+
+	t.Run("compressed file", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := os.ReadFile(filepath.Join("testdata", "excel", "legacy.xls"))
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err = gz.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+
+		path := filepath.Join(t.TempDir(), "legacy.xls.gz")
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+
+		db, err := Open(path)
+		require.NoError(t, err)
+		defer db.Close()
+
+		var count int
+		require.NoError(t, db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM legacy_products").Scan(&count))
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("reader input loads the first worksheet", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := os.ReadFile(filepath.Join("testdata", "excel", "legacy.xls"))
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		db := openTestDB(t, NewBuilder().
+			AddReader(bytes.NewReader(data), "products", FileTypeXLS).
+			SetDefaultChunkSize(2))
+
+		var count int
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products").Scan(&count))
+		assert.Equal(t, 3, count)
+	})
+}
@@ -0,0 +1,83 @@
+package filesql
+
+import "strings"
+
+// WithExcelTableDetection splits Excel sheets that contain several tables.
+//
+// Some sheets hold multiple logical tables separated by blank rows. With detection
+// enabled, every block of consecutive non-blank rows becomes its own SQL table,
+// using the first row of the block as its header. Leading empty columns of a block
+// are ignored, so tables that start at column B or C are detected as well.
+//
+// When a sheet contains more than one block, the tables are named
+// "<file>_<sheet>_1", "<file>_<sheet>_2", and so on. A sheet with a single block
+// keeps the usual "<file>_<sheet>" name.
+//
+// Detection applies to Excel files added with AddPath.
+//
+// Returns self for chaining.
+func (b *DBBuilder) WithExcelTableDetection() *DBBuilder {
+	b.streamProcessor.detectExcelTables = true
+	return b
+}
+
+// splitXLSXRegions splits sheet rows into blocks separated by blank rows
+func splitXLSXRegions(rows [][]string) [][][]string {
+	regions := make([][][]string, 0)
+	var current [][]string
+
+	for _, row := range rows {
+		if isBlankXLSXRow(row) {
+			if len(current) > 0 {
+				regions = append(regions, trimLeadingEmptyColumns(current))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, row)
+	}
+	if len(current) > 0 {
+		regions = append(regions, trimLeadingEmptyColumns(current))
+	}
+
+	return regions
+}
+
+// isBlankXLSXRow reports whether every cell of row is empty
+func isBlankXLSXRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// trimLeadingEmptyColumns removes columns that are empty in every row of a region
+// from the left side of the region
+func trimLeadingEmptyColumns(rows [][]string) [][]string {
+	offset := -1
+	for _, row := range rows {
+		for i, cell := range row {
+			if strings.TrimSpace(cell) != "" {
+				if offset == -1 || i < offset {
+					offset = i
+				}
+				break
+			}
+		}
+	}
+	if offset <= 0 {
+		return rows
+	}
+
+	trimmed := make([][]string, len(rows))
+	for i, row := range rows {
+		if len(row) > offset {
+			trimmed[i] = row[offset:]
+		} else {
+			trimmed[i] = []string{}
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,71 @@
+package filesql
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestDBBuilder_WithExcelTableDetection(t *testing.T) {
+	t.Parallel()
+
+	// writeReport creates a workbook whose "Report" sheet has two tables separated by a blank row
+	writeReport := func(t *testing.T) string {
+		t.Helper()
+
+		f := excelize.NewFile()
+		defer f.Close()
+		require.NoError(t, f.SetSheetName("Sheet1", "Report"))
+		require.NoError(t, f.SetSheetRow("Report", "A1", &[]any{"id", "name"}))
+		require.NoError(t, f.SetSheetRow("Report", "A2", &[]any{1, "Alice"}))
+		require.NoError(t, f.SetSheetRow("Report", "A3", &[]any{2, "Bob"}))
+		require.NoError(t, f.SetSheetRow("Report", "B5", &[]any{"region", "sales"}))
+		require.NoError(t, f.SetSheetRow("Report", "B6", &[]any{"east", 100}))
+
+		path := filepath.Join(t.TempDir(), "book.xlsx")
+		require.NoError(t, f.SaveAs(path))
+		return path
+	}
+
+	runLoadTests(t, []loadTest{
+		{
+			name: "each region becomes a table",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().AddPath(writeReport(t)).WithExcelTableDetection()
+			},
+			tables: []string{"book_Report_1", "book_Report_2"},
+			want: map[string][]string{
+				"SELECT COUNT(*) FROM book_Report_1":                    {"2"},
+				"SELECT sales FROM book_Report_2 WHERE region = 'east'": {"100"},
+			},
+		},
+		{
+			name: "without detection the sheet is one table",
+			builder: func(t *testing.T) *DBBuilder {
+				return NewBuilder().AddPath(writeReport(t))
+			},
+			tables: []string{"book_Report"},
+		},
+	})
+}
+
+func TestSplitXLSXRegions(t *testing.T) {
+	t.Parallel()
+
+	rows := [][]string{
+		{},
+		{"a", "b"},
+		{"1", "2"},
+		{"", " "},
+		{"", "c"},
+		{"", "3"},
+	}
+
+	assert.Equal(t, [][][]string{
+		{{"a", "b"}, {"1", "2"}},
+		{{"c"}, {"3"}},
+	}, splitXLSXRegions(rows))
+}
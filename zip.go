@@ -0,0 +1,149 @@
+package filesql
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readZIPDataFiles reads a zip archive and returns its data files (see isArchiveDataFile).
+// Archives without data files are rejected.
+func readZIPDataFiles(reader io.Reader) ([]*zip.File, error) {
+	// Zip archives are read from the central directory at the end, which requires random access
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip data: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, newCodedError(ErrCodeEmptyData, "empty zip file")
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, newCodedError(ErrCodeInvalidData, "failed to open zip archive: %w", err)
+	}
+
+	var dataFiles []*zip.File
+	for _, f := range archive.File {
+		if !f.FileInfo().IsDir() && isArchiveDataFile(f.Name) {
+			dataFiles = append(dataFiles, f)
+		}
+	}
+	if len(dataFiles) == 0 {
+		return nil, newCodedError(ErrCodeEmptyData, "no supported data file found in zip archive")
+	}
+	return dataFiles, nil
+}
+
+// openZIPDataFile opens the single data file in a zip archive and returns it with its file type.
+//
+// Directories, macOS resource forks (__MACOSX), hidden files, and files of unsupported
+// types are ignored, so the CSV bundles exported by Apple Numbers and Google Sheets
+// load transparently. Archives with no or several data files are rejected, since a
+// reader input is loaded as a single table.
+func openZIPDataFile(reader io.Reader) (io.ReadCloser, FileType, error) {
+	dataFiles, err := readZIPDataFiles(reader)
+	if err != nil {
+		return nil, FileTypeUnsupported, err
+	}
+	if len(dataFiles) > 1 {
+		names := make([]string, len(dataFiles))
+		for i, f := range dataFiles {
+			names[i] = f.Name
+		}
+		return nil, FileTypeUnsupported, newCodedError(ErrCodeUnsupportedFormat,
+			"zip archive contains %d data files (%s); only archives with a single data file can be loaded as one table",
+			len(dataFiles), strings.Join(names, ", "))
+	}
+
+	entry, err := dataFiles[0].Open()
+	if err != nil {
+		return nil, FileTypeUnsupported, newCodedError(ErrCodeInvalidData, "failed to open %s in zip archive: %w", dataFiles[0].Name, err)
+	}
+	return entry, detectFileType(dataFiles[0].Name), nil
+}
+
+// zipEntryParser returns a parser for the data file in a zip or tar archive
+func (p *streamingParser) zipEntryParser(fileType FileType) *streamingParser {
+	entryParser := *p
+	entryParser.fileType = fileType
+	return &entryParser
+}
+
+// parseZIPStream parses the single data file in a zip archive
+func (p *streamingParser) parseZIPStream(reader io.Reader) (*table, error) {
+	entry, fileType, err := openZIPDataFile(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+
+	return p.zipEntryParser(fileType).parseFromReader(entry)
+}
+
+// processZIPInChunks processes the single data file in a zip archive in chunks
+func (p *streamingParser) processZIPInChunks(reader io.Reader, processor chunkProcessor) error {
+	entry, fileType, err := openZIPDataFile(reader)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+
+	return p.zipEntryParser(fileType).ProcessInChunks(entry, processor)
+}
+
+// streamZIPFileToDatabase loads the zip archive at filePath. An archive with a single
+// data file is loaded as a table named after the archive; otherwise each data file is
+// loaded as a table named after the file.
+func (sp *streamProcessor) streamZIPFileToDatabase(ctx context.Context, db *sql.DB, reader io.Reader, filePath string) error {
+	dataFiles, err := readZIPDataFiles(reader)
+	if err != nil {
+		return err
+	}
+
+	if len(dataFiles) == 1 {
+		entry, err := dataFiles[0].Open()
+		if err != nil {
+			return newCodedError(ErrCodeInvalidData, "failed to open %s in zip archive: %w", dataFiles[0].Name, err)
+		}
+		defer entry.Close()
+
+		return sp.streamReaderToDatabase(ctx, db, readerInput{
+			reader:    entry,
+			tableName: sp.fileTableName(filePath),
+			fileType:  detectFileType(dataFiles[0].Name),
+			options:   sp.fileOptions[filePath],
+		})
+	}
+
+	for _, f := range dataFiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry, err := f.Open()
+		if err != nil {
+			return newCodedError(ErrCodeInvalidData, "failed to open %s in zip archive: %w", f.Name, err)
+		}
+		err = sp.streamArchiveEntryToDatabase(ctx, db, entry, filePath, f.Name)
+		_ = entry.Close() // Ignore close error of the read-only entry
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseZIP parses the single data file in a zip archive
+func (f *file) parseZIP() (*table, error) {
+	reader, closer, err := f.openReader()
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	return newStreamingParser(FileTypeZIP, tableFromFilePath(f.path), 0).parseZIPStream(reader)
+}
@@ -0,0 +1,158 @@
+package filesql
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// newZIPData builds a zip archive from entry names and contents, in order
+func newZIPData(t *testing.T, entries ...[2]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := zw.Create(entry[0])
+		require.NoError(t, err)
+		_, err = w.Write([]byte(entry[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestOpenZIPDataFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ignores directories, resource forks, and hidden files", func(t *testing.T) {
+		t.Parallel()
+
+		data := newZIPData(t,
+			[2]string{"export/", ""},
+			[2]string{"__MACOSX/export/._sales.csv", "resource fork"},
+			[2]string{"export/.DS_Store", "finder"},
+			[2]string{"export/readme.txt", "not data"},
+			[2]string{"export/sales.csv", "id,amount\n1,100\n"},
+		)
+
+		entry, fileType, err := openZIPDataFile(bytes.NewReader(data))
+		require.NoError(t, err)
+		defer entry.Close()
+		assert.Equal(t, FileTypeCSV, fileType)
+	})
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantCode ErrorCode
+		wantMsg  string
+	}{
+		{
+			name:     "rejects archives with several data files",
+			data:     newZIPData(t, [2]string{"a.csv", "id\n1\n"}, [2]string{"b.tsv", "id\n2\n"}),
+			wantCode: ErrCodeUnsupportedFormat,
+			wantMsg:  "a.csv, b.tsv",
+		},
+		{
+			name:     "rejects archives without data files",
+			data:     newZIPData(t, [2]string{"readme.txt", "nothing here"}, [2]string{"nested.zip", "zip"}),
+			wantCode: ErrCodeEmptyData,
+		},
+		{
+			name:     "rejects data that is not a zip archive",
+			data:     []byte("id,name\n1,alice\n"),
+			wantCode: ErrCodeInvalidData,
+		},
+		{
+			name:     "rejects empty data",
+			wantCode: ErrCodeEmptyData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := openZIPDataFile(bytes.NewReader(tt.data))
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, ErrorCodeOf(err))
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestZIPLoading(t *testing.T) {
+	t.Parallel()
+
+	// writeZIP writes data to name in a new directory and returns its path
+	writeZIP := func(t *testing.T, name string, data []byte) string {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+	runLoadTests(t, []loadTest{
+		{
+			name: "loads the csv in a zip file as a table named after the archive",
+			builder: func(t *testing.T) *DBBuilder {
+				data := newZIPData(t,
+					[2]string{"__MACOSX/._Sheet 1.csv", "resource fork"},
+					[2]string{"Sheet 1.csv", "id,amount\n1,100\n2,250\n"},
+				)
+				return NewBuilder().AddPath(writeZIP(t, "sales.zip", data))
+			},
+			want: map[string][]string{"SELECT SUM(amount) FROM sales": {"350"}},
+		},
+		{
+			name: "loads each data file of a zip file with several data files as a table",
+			builder: func(t *testing.T) *DBBuilder {
+				workbook := excelize.NewFile()
+				require.NoError(t, workbook.SetSheetRow("Sheet1", "A1", &[]any{"region"}))
+				require.NoError(t, workbook.SetSheetRow("Sheet1", "A2", &[]any{"west"}))
+				xlsx, err := workbook.WriteToBuffer()
+				require.NoError(t, err)
+				require.NoError(t, workbook.Close())
+
+				data := newZIPData(t,
+					[2]string{"drop/orders.csv", "id,amount\n1,100\n2,250\n"},
+					[2]string{"drop/2024/customers.tsv", "id\tname\n1\talice\n"},
+					[2]string{"drop/2024/report.xlsx", xlsx.String()},
+					[2]string{"drop/readme.txt", "not data"},
+					[2]string{"__MACOSX/drop/._orders.csv", "resource fork"},
+				)
+				return NewBuilder().AddPath(writeZIP(t, "drop.zip", data))
+			},
+			tables: []string{"customers", "orders", "report_Sheet1"},
+			want: map[string][]string{
+				"SELECT c.name FROM orders o JOIN customers c ON o.id = c.id": {"alice"},
+			},
+		},
+		{
+			name: "loads a zip file from a reader",
+			builder: func(t *testing.T) *DBBuilder {
+				data := newZIPData(t, [2]string{"data.tsv", "id\tname\n1\talice\n"})
+				return NewBuilder().AddReader(bytes.NewReader(data), "people", FileTypeZIP)
+			},
+			want: map[string][]string{"SELECT name FROM people WHERE id = 1": {"alice"}},
+		},
+	})
+
+	t.Run("parses the data file of a zip file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "export.zip")
+		require.NoError(t, os.WriteFile(path, newZIPData(t, [2]string{"export.csv", "id,name\n1,alice\n"}), 0o600))
+
+		tbl, err := newFile(path).toTable()
+		require.NoError(t, err)
+		assert.Equal(t, "export", tbl.getName())
+		assert.Len(t, tbl.getRecords(), 1)
+	})
+}